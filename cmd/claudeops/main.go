@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,15 +11,32 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/joestump/claude-ops/internal/certs"
 	"github.com/joestump/claude-ops/internal/config"
 	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/digest"
+	"github.com/joestump/claude-ops/internal/doctor"
+	"github.com/joestump/claude-ops/internal/extension"
+	"github.com/joestump/claude-ops/internal/heartbeat"
 	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/kb"
 	"github.com/joestump/claude-ops/internal/mcp"
+	"github.com/joestump/claude-ops/internal/offload"
+	"github.com/joestump/claude-ops/internal/paging"
+	"github.com/joestump/claude-ops/internal/policy"
+	"github.com/joestump/claude-ops/internal/promote"
+	"github.com/joestump/claude-ops/internal/pulse"
+	"github.com/joestump/claude-ops/internal/remediation"
+	"github.com/joestump/claude-ops/internal/report"
+	"github.com/joestump/claude-ops/internal/scheduler"
+	"github.com/joestump/claude-ops/internal/seal"
 	"github.com/joestump/claude-ops/internal/session"
+	"github.com/joestump/claude-ops/internal/tickets"
 	"github.com/joestump/claude-ops/internal/web"
 )
 
@@ -31,15 +49,24 @@ func main() {
 	}
 
 	// Register flags with defaults matching the original entrypoint.sh values.
+	// Paths default to the container layout inside Docker and to per-user
+	// directories elsewhere.
+	paths := config.DefaultPaths()
+	// state-dir is persistent so subcommands can open the same database.
+	rootCmd.PersistentFlags().String("state-dir", paths.StateDir, "directory for persistent state")
 	f := rootCmd.Flags()
 	f.Int("interval", 3600, "seconds between health-check sessions")
-	f.String("prompt", "/app/prompts/tier1-observe.md", "path to the prompt file")
+	f.String("schedule", "", `cron expression for health-check sessions, e.g. "*/30 * * * *" (overrides --interval)`)
+	f.Int("jitter", 0, "delay each scheduled session by a random 0 to N seconds")
+	f.Int("interval-min", 0, "shortest adaptive interval in seconds, reached while runs find problems (0 uses --interval)")
+	f.Int("interval-max", 0, "longest adaptive interval in seconds, reached while runs find everything healthy (0 disables the adaptive interval)")
+	f.Int("adaptive-runs", 3, "consecutive healthy scheduled runs before the adaptive interval is stretched")
+	f.String("prompt", paths.Prompt("tier1-observe.md"), "path to the prompt file")
 	f.String("tier1-model", "haiku", "Claude model for Tier 1 (observe)")
 	f.String("tier2-model", "sonnet", "Claude model for Tier 2 (investigate)")
 	f.String("tier3-model", "opus", "Claude model for Tier 3 (remediate)")
-	f.String("state-dir", "/state", "directory for persistent state")
-	f.String("results-dir", "/results", "directory for session logs")
-	f.String("repos-dir", "/repos", "directory for cloned repositories")
+	f.String("results-dir", paths.ResultsDir, "directory for session logs")
+	f.String("repos-dir", paths.ReposDir, "directory for cloned repositories")
 	// Governing: SPEC-0010 REQ-5 "Tool filtering via --allowedTools"
 	// Governing: ADR-0023 "AllowedTools-Based Tier Enforcement" — Tier 1 allowed tools (observe-only, no Write/Edit)
 	// WebSearch and WebFetch are read-only research tools included at all tiers (ADR-0023).
@@ -52,19 +79,37 @@ func main() {
 	f.Bool("dry-run", false, "skip actual remediation actions")
 	f.Bool("verbose", false, "enable verbose Claude CLI output")
 	f.String("apprise-urls", "", "Apprise notification URLs")
-	f.String("mcp-config", "/app/.claude/mcp.json", "path to MCP config file")
+	f.String("mcp-config", filepath.Join(paths.AppDir, ".claude", "mcp.json"), "path to MCP config file")
 	f.Int("dashboard-port", 8080, "HTTP port for the dashboard")
+	f.String("dashboard-socket", "", "serve the dashboard on this Unix socket instead of the TCP port")
+	f.Int("agent-port", 8081, "loopback-only port for the agent's callbacks into the dashboard API (0 uses the dashboard's own listener)")
 	f.Int("max-tier", 3, "maximum escalation tier (1-3)")
-	f.String("tier2-prompt", "/app/prompts/tier2-investigate.md", "path to Tier 2 prompt file")
-	f.String("tier3-prompt", "/app/prompts/tier3-remediate.md", "path to Tier 3 prompt file")
+	f.Bool("no-tier-skip", false, "escalate to the next tier even when the agent asks to skip one (Tier 1 straight to Tier 3)")
+	f.String("tier2-prompt", paths.Prompt("tier2-investigate.md"), "path to Tier 2 prompt file")
+	f.String("tier2-prompt-rules", "db-investigate.md=database|postgres|mysql|mariadb|redis|mongo|sqlite|deadlock;network-investigate.md=dns|nxdomain|name resolution|network|unreachable|no route to host|wireguard",
+		"specialized Tier 2 prompts selected from the handoff, as prompt=keyword|keyword;... (prompts relative to the Tier 2 prompt's directory; empty disables)")
+	f.String("tier3-prompt", paths.Prompt("tier3-remediate.md"), "path to Tier 3 prompt file")
 	f.Int("memory-budget", 2000, "max tokens for memory context injection")
+	f.Int("memory-tier1-per-service", 3, "max memories injected per service into Tier 1 sessions (0 = no cap)")
+	f.Bool("memory-verified-only", false, "only inject operator-verified memories (unverified memories are otherwise injected after verified ones)")
+	f.Int("memory-tombstone-days", 30, "days a deleted or rejected memory blocks the agent from re-learning it (0 disables)")
+	f.String("memory-categories", "timing,dependency,behavior,remediation,maintenance", "allowed memory categories (comma-separated); misspellings are normalized to the closest (empty allows any)")
+	f.Bool("memory-strict", false, "reject agent memories whose category matches no allowed category, recording them as parse warnings")
+	f.Int("memory-suggest-interval", 0, "hours between suggesting memories from recurring events for review (0 disables)")
+	f.Int("memory-suggest-count", 3, "similar warning or critical events for a service that make a recurring pattern")
+	f.Int("memory-suggest-days", 7, "days of events to look for recurring patterns in")
+	f.Float64("memory-reinforce", 0.1, "confidence a memory gains when the agent observes it again")
+	f.Float64("memory-contradict", 0.1, "confidence a memory loses when the agent contradicts it")
+	f.Float64("memory-decay", 0.1, "confidence a memory not updated in 30 days loses each time memories decay")
+	f.String("memory-scoring", "", "per-category scoring overrides (category:decay=0.2,reinforce=0.05;...)")
 	f.String("browser-allowed-origins", "", "comma-separated allowed origins for browser navigation")
+	f.String("browser-cdp-url", "", "DevTools endpoint of the browser sidecar (e.g. http://chrome:9222), checked by doctor --network")
 	f.String("summary-model", "claude-haiku-4-5-20251001", "Anthropic model ID for session summary generation (must be a full model ID, e.g. claude-haiku-4-5-20251001)")
 	// Governing: SPEC-0025 REQ "Webhook Model Configuration"
 	f.String("webhook-model", "claude-haiku-4-5-20251001", "Anthropic model ID for webhook alert synthesis (must be a full model ID)")
 	f.String("webhook-system-prompt", "", "custom system prompt for webhook alert synthesis (overrides default)")
 	// Governing: ADR-0030, SPEC-0031 REQ-4 — path to JSON Schema for structured output
-	f.String("schema-path", "/app/schemas/agent-response.json", "path to the JSON Schema file for structured output (CLAUDEOPS_SCHEMA_PATH)")
+	f.String("schema-path", filepath.Join(paths.AppDir, "schemas", "agent-response.json"), "path to the JSON Schema file for structured output (CLAUDEOPS_SCHEMA_PATH)")
 	// Governing: SPEC-0024 REQ-11 (Per-Tier Tool Enforcement for Chat Sessions), ADR-0023
 	// Per-tier defaults match ADR-0023 "Concrete Patterns Per Tier" section.
 	f.String("tier1-allowed-tools", "", "comma-separated allowed tools for Tier 1 (overrides allowed-tools)")
@@ -75,6 +120,89 @@ func main() {
 	// Governing: ADR-0023 Tier 3 — full remediation (Ansible/Helm permitted, only catastrophic ops blocked)
 	f.String("tier3-allowed-tools", "Bash,Read,Write,Edit,Grep,Glob,Task,WebFetch,WebSearch", "comma-separated allowed tools for Tier 3 (overrides allowed-tools)")
 	f.String("tier3-disallowed-tools", "Bash(rm -rf /:*),Bash(docker system prune:*),Bash(git push --force:*),Bash(gh pr merge:*)", "comma-separated disallowed tool patterns for Tier 3 (overrides disallowed-tools)")
+	// Proxmox VE integration — inventory in Tier 1 context, guarded guest power actions.
+	f.String("proxmox-url", "", "Proxmox VE API base URL, e.g. https://pve.local:8006 (empty disables the integration)")
+	f.String("proxmox-token-id", "", "Proxmox API token ID (user@realm!tokenid)")
+	f.String("proxmox-token-secret", "", "Proxmox API token secret (prefer CLAUDEOPS_PROXMOX_TOKEN_SECRET)")
+	f.Bool("proxmox-insecure-tls", false, "skip TLS verification for the Proxmox API (self-signed certificates)")
+	// Log source integration — error logs for affected services attached to escalation context.
+	f.String("log-source", "", "log backend for pre-fetching error logs on escalation: loki or journald (empty disables)")
+	f.String("loki-url", "", "Loki base URL, e.g. http://loki:3100")
+	f.String("loki-selector", `{container="$service"} |~ "(?i)(error|fatal|panic|exception)"`, "LogQL query for a service's error logs ($service is replaced)")
+	f.String("journald-unit", "$service", "journalctl unit or FIELD=value match for a service ($service is replaced)")
+	f.Int("log-lookback", 30, "minutes of logs to pre-fetch")
+	f.Int("log-lines", 40, "max log lines pre-fetched per service")
+	// Knowledge base — runbook articles compiled from memories with the summary model.
+	f.Int("kb-interval", 0, "hours between knowledge base runbook regenerations (0 disables)")
+	f.String("kb-export-dir", "", "directory to write runbook articles to as <service>.md; committed and pushed if it is a git checkout")
+	// Tier 0 pulse — cheap native probes between sessions.
+	f.String("pulse-targets", "", "comma-separated service=url probes for Tier 0, e.g. jellyfin=http://jellyfin:8096,postgres=tcp://db:5432 (empty disables)")
+	f.Int("pulse-interval", 120, "seconds between Tier 0 probe rounds")
+	f.Int("pulse-threshold", 2, "consecutive failed probes before triggering a Tier 1 session")
+	// Post-remediation verification — confirms a Tier 3 fix held.
+	f.Int("verify-delay", 15, "minutes after a Tier 3 remediation to run a verification session (0 disables)")
+	f.String("verify-model", "haiku", "Claude model for verification sessions")
+	f.String("verify-prompt", paths.Prompt("verify.md"), "path to the verification prompt file")
+	// Self-test drills — a synthetic failing canary run through the full pipeline.
+	f.Int("selftest-interval", 0, "hours between self-test drills (0 disables scheduled drills)")
+	f.Float64("confirm-cost-threshold", 1.0, "estimated USD cost above which a dashboard run needs confirmation (0 disables)")
+	f.Int("stream-drop-warn", 5, "warn on the session page when more than this many stream events were dropped as unknown")
+	f.Bool("strip-thinking", false, "leave extended thinking blocks out of stored session logs")
+	f.Bool("no-log-compression", false, "keep session logs as plain NDJSON instead of gzipping them when the session ends")
+	f.Int("shutdown-grace", 60, "seconds to wait on shutdown for the in-flight session tier to finish")
+	f.Bool("demo", false, "replay canned sessions instead of invoking the Claude CLI (no API key needed)")
+	f.String("policy-file", "", "YAML file of CEL policy rules evaluated before escalations and cooldown actions")
+	f.String("encryption-key", "", "Key (32 bytes, base64 or hex) for encrypting session responses, summaries, prompts, and logs at rest (prefer CLAUDEOPS_ENCRYPTION_KEY)")
+	f.String("encryption-key-file", "", "File holding the key for encrypting session data at rest")
+	f.Int("offload-days", 0, "move session logs and artifacts older than this many days to S3-compatible object storage, leaving a stub locally (0 disables)")
+	f.String("s3-endpoint", "", "S3-compatible endpoint URL for offloaded logs, e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000")
+	f.String("s3-region", "us-east-1", "region used to sign object storage requests")
+	f.String("s3-bucket", "", "bucket for offloaded logs and artifacts")
+	f.String("s3-prefix", "claudeops/", "key prefix for offloaded logs and artifacts within the bucket")
+	f.String("s3-access-key", "", "object storage access key ID")
+	f.String("s3-secret-key", "", "object storage secret access key (prefer CLAUDEOPS_S3_SECRET_KEY)")
+	f.String("two-person-services", "", "comma-separated services whose Tier 3 remediation needs approval from two operators")
+	f.Int("approval-ttl", 60, "minutes a two-person approval request stays open")
+	f.String("trusted-proxies", "", "comma-separated IPs or CIDR ranges (or \"unix\" for the dashboard socket) of authenticating proxies whose Remote-User header identifies operators")
+	f.Int("escalation-cooldown", 0, "minutes after a chain escalates for a service before another chain may escalate for it (0 disables)")
+	f.Int("freshness-window", 0, "minutes a service checked healthy by a finished session is left out of the next scheduled Tier 1 run (0 disables)")
+	f.String("synthetic-pricing", "", "per-model USD per million tokens (model=input/output;...) used to estimate cost when the CLI reports zero")
+	f.Int("context-warn-percent", 80, "warn when a session's context reaches this percent of the model's context window (0 disables)")
+	f.Int("split-turns", 0, "split a Tier 3 session into a continuation session after this many turns (0 disables)")
+	f.Int("split-context-percent", 90, "split a Tier 3 session into a continuation session when its context reaches this percent of the window (0 disables)")
+	f.Int("max-splits", 2, "maximum continuation sessions per escalation chain")
+	f.Int("notify-digest-window", 0, "minutes over which warning and critical events are grouped into one notification per service (0 disables)")
+	f.String("service-aliases", "", "comma-separated alias=service pairs mapping the names agents use to a canonical service name")
+	f.String("weekly-report-day", "", "weekday on which the weekly trend report is sent through notifications, e.g. monday (empty disables)")
+	f.Int("remediation-score-hours", 6, "hours a service must stay free of critical events after a remediation for it to count as having worked (0 disables scoring)")
+	f.Int("brief-minutes", 5, "minutes the spoken status brief at /api/v1/brief is reused before it is regenerated")
+	f.String("extensions", "", "comma-separated http(s) URLs and exec:commands that receive every session lifecycle event as JSON (empty disables)")
+	f.String("pagerduty-routing-key", "", "PagerDuty Events API v2 routing key; critical events and failed remediations open incidents (empty disables)")
+	f.String("opsgenie-api-key", "", "Opsgenie API key; critical events and failed remediations open alerts (empty disables)")
+	f.String("opsgenie-url", "https://api.opsgenie.com", "Opsgenie API base URL, e.g. https://api.eu.opsgenie.com")
+	f.String("ticket-project", "", "github:owner/repo, gitea:owner/repo, or jira:PROJ to open an issue in when an escalation chain ends with a service still unhealthy (empty disables)")
+	f.String("dashboard-url", "", "External URL of the dashboard, used to link to sessions from issues")
+	f.Bool("readonly-ui", false, "serve the dashboard and API read-only: reject config changes, runs, memory edits, and chat")
+	f.String("tls-cert", "", "PEM certificate file to serve the dashboard over HTTPS")
+	f.String("tls-key", "", "PEM private key file for --tls-cert")
+	f.String("acme-domains", "", "comma-separated domains to obtain the dashboard's certificate for from an ACME CA")
+	f.String("acme-email", "", "contact email for the ACME account")
+	f.String("acme-challenge", "http-01", "ACME challenge type: http-01 or dns-01")
+	f.String("acme-dns-hook", "", "shell command that publishes and removes dns-01 TXT records")
+	f.String("acme-directory", "https://acme-v02.api.letsencrypt.org/directory", "ACME directory URL")
+	f.Int("acme-http-port", 80, "port for http-01 challenges and the redirect to HTTPS")
+	f.String("heartbeat-url", "", "healthchecks.io or Uptime Kuma push URL pinged after each scheduled check; failures ping the /fail variant (empty disables)")
+	f.String("tier1-env", "", "semicolon-separated NAME=value pairs added to the Tier 1 CLI environment; prefix a value with secret: to redact it")
+	f.String("tier2-env", "", "semicolon-separated NAME=value pairs added to the Tier 2 CLI environment; prefix a value with secret: to redact it")
+	f.String("tier3-env", "", "semicolon-separated NAME=value pairs added to the Tier 3 CLI environment, e.g. ANSIBLE_CONFIG=/repos/infra/ansible.cfg; prefix a value with secret: to redact it")
+	f.String("tier1-dir", "", "working directory for Tier 1 CLI sessions (default: the supervisor's)")
+	f.String("tier2-dir", "", "working directory for Tier 2 CLI sessions (default: the supervisor's)")
+	f.String("tier3-dir", "", "working directory for Tier 3 CLI sessions, e.g. an infrastructure repo checkout (default: the supervisor's)")
+	f.Bool("git-auto-commit", false, "commit file changes Tier 2 and 3 sessions leave in git repos to a claudeops/session-<id> branch")
+	f.Bool("git-push", false, "push auto-commit branches to origin")
+	f.Bool("git-open-pr", false, "open a pull request for each pushed auto-commit branch (GitHub via GITHUB_TOKEN, Gitea via GITEA_URL and GITEA_TOKEN)")
+	f.Bool("sandbox", false, "capture Tier 2 and 3 file changes under the repos directory as a change report to approve instead of applying them")
+	f.String("prompt-store", "file", "where prompts edited on the dashboard are saved: file (the configured prompt file) or db")
 
 	// Bind flags to viper. Viper keys use underscores (tier1_model) so they
 	// match the env var suffix after stripping the CLAUDEOPS_ prefix.
@@ -82,11 +210,16 @@ func main() {
 		_ = viper.BindPFlag(viperKey, f.Lookup(flagName))
 	}
 	bindFlag("interval", "interval")
+	bindFlag("schedule", "schedule")
+	bindFlag("jitter", "jitter")
+	bindFlag("interval_min", "interval-min")
+	bindFlag("interval_max", "interval-max")
+	bindFlag("adaptive_runs", "adaptive-runs")
 	bindFlag("prompt", "prompt")
 	bindFlag("tier1_model", "tier1-model")
 	bindFlag("tier2_model", "tier2-model")
 	bindFlag("tier3_model", "tier3-model")
-	bindFlag("state_dir", "state-dir")
+	_ = viper.BindPFlag("state_dir", rootCmd.PersistentFlags().Lookup("state-dir"))
 	bindFlag("results_dir", "results-dir")
 	bindFlag("repos_dir", "repos-dir")
 	bindFlag("allowed_tools", "allowed-tools")
@@ -96,11 +229,28 @@ func main() {
 	bindFlag("apprise_urls", "apprise-urls")
 	bindFlag("mcp_config", "mcp-config")
 	bindFlag("dashboard_port", "dashboard-port")
+	bindFlag("dashboard_socket", "dashboard-socket")
+	bindFlag("agent_port", "agent-port")
 	bindFlag("max_tier", "max-tier")
+	bindFlag("no_tier_skip", "no-tier-skip")
 	bindFlag("tier2_prompt", "tier2-prompt")
+	bindFlag("tier2_prompt_rules", "tier2-prompt-rules")
 	bindFlag("tier3_prompt", "tier3-prompt")
 	bindFlag("memory_budget", "memory-budget")
+	bindFlag("memory_tier1_per_service", "memory-tier1-per-service")
+	bindFlag("memory_verified_only", "memory-verified-only")
+	bindFlag("memory_tombstone_days", "memory-tombstone-days")
+	bindFlag("memory_categories", "memory-categories")
+	bindFlag("memory_strict", "memory-strict")
+	bindFlag("memory_suggest_interval", "memory-suggest-interval")
+	bindFlag("memory_suggest_count", "memory-suggest-count")
+	bindFlag("memory_suggest_days", "memory-suggest-days")
+	bindFlag("memory_reinforce", "memory-reinforce")
+	bindFlag("memory_contradict", "memory-contradict")
+	bindFlag("memory_decay", "memory-decay")
+	bindFlag("memory_scoring", "memory-scoring")
 	bindFlag("browser_allowed_origins", "browser-allowed-origins")
+	bindFlag("browser_cdp_url", "browser-cdp-url")
 	bindFlag("summary_model", "summary-model")
 	bindFlag("webhook_model", "webhook-model")
 	bindFlag("webhook_system_prompt", "webhook-system-prompt")
@@ -111,6 +261,83 @@ func main() {
 	bindFlag("tier3_allowed_tools", "tier3-allowed-tools")
 	bindFlag("tier3_disallowed_tools", "tier3-disallowed-tools")
 	bindFlag("schema_path", "schema-path")
+	bindFlag("proxmox_url", "proxmox-url")
+	bindFlag("proxmox_token_id", "proxmox-token-id")
+	bindFlag("proxmox_token_secret", "proxmox-token-secret")
+	bindFlag("proxmox_insecure_tls", "proxmox-insecure-tls")
+	bindFlag("log_source", "log-source")
+	bindFlag("loki_url", "loki-url")
+	bindFlag("loki_selector", "loki-selector")
+	bindFlag("journald_unit", "journald-unit")
+	bindFlag("log_lookback", "log-lookback")
+	bindFlag("log_lines", "log-lines")
+	bindFlag("kb_interval", "kb-interval")
+	bindFlag("kb_export_dir", "kb-export-dir")
+	bindFlag("pulse_targets", "pulse-targets")
+	bindFlag("pulse_interval", "pulse-interval")
+	bindFlag("pulse_threshold", "pulse-threshold")
+	bindFlag("verify_delay", "verify-delay")
+	bindFlag("verify_model", "verify-model")
+	bindFlag("verify_prompt", "verify-prompt")
+	bindFlag("selftest_interval", "selftest-interval")
+	bindFlag("confirm_cost_threshold", "confirm-cost-threshold")
+	bindFlag("stream_drop_warn", "stream-drop-warn")
+	bindFlag("strip_thinking", "strip-thinking")
+	bindFlag("no_log_compression", "no-log-compression")
+	bindFlag("shutdown_grace", "shutdown-grace")
+	bindFlag("demo", "demo")
+	bindFlag("policy_file", "policy-file")
+	bindFlag("encryption_key", "encryption-key")
+	bindFlag("encryption_key_file", "encryption-key-file")
+	bindFlag("offload_days", "offload-days")
+	bindFlag("s3_endpoint", "s3-endpoint")
+	bindFlag("s3_region", "s3-region")
+	bindFlag("s3_bucket", "s3-bucket")
+	bindFlag("s3_prefix", "s3-prefix")
+	bindFlag("s3_access_key", "s3-access-key")
+	bindFlag("s3_secret_key", "s3-secret-key")
+	bindFlag("two_person_services", "two-person-services")
+	bindFlag("approval_ttl", "approval-ttl")
+	bindFlag("trusted_proxies", "trusted-proxies")
+	bindFlag("escalation_cooldown", "escalation-cooldown")
+	bindFlag("freshness_window", "freshness-window")
+	bindFlag("synthetic_pricing", "synthetic-pricing")
+	bindFlag("context_warn_percent", "context-warn-percent")
+	bindFlag("split_turns", "split-turns")
+	bindFlag("split_context_percent", "split-context-percent")
+	bindFlag("max_splits", "max-splits")
+	bindFlag("notify_digest_window", "notify-digest-window")
+	bindFlag("service_aliases", "service-aliases")
+	bindFlag("weekly_report_day", "weekly-report-day")
+	bindFlag("remediation_score_hours", "remediation-score-hours")
+	bindFlag("brief_minutes", "brief-minutes")
+	bindFlag("extensions", "extensions")
+	bindFlag("pagerduty_routing_key", "pagerduty-routing-key")
+	bindFlag("opsgenie_api_key", "opsgenie-api-key")
+	bindFlag("opsgenie_url", "opsgenie-url")
+	bindFlag("ticket_project", "ticket-project")
+	bindFlag("dashboard_url", "dashboard-url")
+	bindFlag("readonly_ui", "readonly-ui")
+	bindFlag("tls_cert", "tls-cert")
+	bindFlag("tls_key", "tls-key")
+	bindFlag("acme_domains", "acme-domains")
+	bindFlag("acme_email", "acme-email")
+	bindFlag("acme_challenge", "acme-challenge")
+	bindFlag("acme_dns_hook", "acme-dns-hook")
+	bindFlag("acme_directory", "acme-directory")
+	bindFlag("acme_http_port", "acme-http-port")
+	bindFlag("heartbeat_url", "heartbeat-url")
+	bindFlag("tier1_env", "tier1-env")
+	bindFlag("tier2_env", "tier2-env")
+	bindFlag("tier3_env", "tier3-env")
+	bindFlag("tier1_dir", "tier1-dir")
+	bindFlag("tier2_dir", "tier2-dir")
+	bindFlag("tier3_dir", "tier3-dir")
+	bindFlag("git_auto_commit", "git-auto-commit")
+	bindFlag("git_push", "git-push")
+	bindFlag("git_open_pr", "git-open-pr")
+	bindFlag("sandbox", "sandbox")
+	bindFlag("prompt_store", "prompt-store")
 
 	// Governing: SPEC-0008 REQ-12 — environment variable compatibility (CLAUDEOPS_* prefix).
 	// Bind CLAUDEOPS_* environment variables. AutomaticEnv with the prefix
@@ -124,25 +351,219 @@ func main() {
 
 	// Governing: SPEC-0023 REQ-9 — custom MCP server removed; tools are now skill-based.
 
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Export sessions, events, memories, and logs as static files for offline analysis",
+		Args:  cobra.NoArgs,
+		RunE:  snapshot,
+	}
+	snapshotCmd.Flags().String("out", "", "directory to write the export to (created if needed; must be empty)")
+	_ = snapshotCmd.MarkFlagRequired("out")
+	rootCmd.AddCommand(snapshotCmd)
+
+	importLogsCmd := &cobra.Command{
+		Use:   "import-logs DIR",
+		Short: "Create sessions for session logs in DIR that have none, such as those written by entrypoint.sh",
+		Long: "Reconstructs a session from each run-*.log file in DIR that no session links to (timestamps,\n" +
+			"a tier guessed from the run metadata or model, and the cost and response from the result event or\n" +
+			"the logged output) and links the file to it, so history from before the Go supervisor shows up in\n" +
+			"the dashboard. Files already linked to a session are skipped, so it can be run again safely.",
+		Args: cobra.ExactArgs(1),
+		RunE: importLogs,
+	}
+	importLogsCmd.Flags().Bool("dry-run", false, "print the sessions that would be created without creating them")
+	rootCmd.AddCommand(importLogsCmd)
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check embedded assets, directories, the database, prompts, the Claude CLI, and MCP config",
+		Long: "Runs the checks a deployment needs to pass before sessions can run, reading the same\n" +
+			"CLAUDEOPS_* environment as the supervisor, and exits nonzero if any fails.",
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+	doctorCmd.Flags().Bool("assets", false, "only check what is embedded in the binary (no configuration needed)")
+	doctorCmd.Flags().Bool("network", false, "also check the Anthropic API, git provider APIs, Apprise (sends a test notification), the browser sidecar, and DNS")
+	doctorCmd.Flags().Bool("json", false, "print the results as JSON")
+	rootCmd.AddCommand(doctorCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
+// snapshot writes a static export of the database and session logs, for
+// attaching to bug reports or archiving before wiping a test instance.
+func snapshot(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	out, _ := cmd.Flags().GetString("out")
+
+	dbPath := filepath.Join(cfg.StateDir, "claudeops.db")
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("no database to export: %w", err)
+	}
+	database, err := db.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close() //nolint:errcheck
+	sealer, err := seal.FromConfig(&cfg)
+	if err != nil {
+		return err
+	}
+	if err := database.SetSealer(sealer); err != nil {
+		return err
+	}
+
+	manifest, err := web.ExportSnapshot(database, offload.FromConfig(&cfg, database), out)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d sessions, %d events, %d memories, and %d logs to %s\n",
+		manifest.Sessions, manifest.Events, manifest.Memories, manifest.Logs, out)
+	if n := len(manifest.MissingLogs); n > 0 {
+		fmt.Printf("  %d session logs were missing and not copied\n", n)
+	}
+	return nil
+}
+
+// importLogs creates sessions for session logs that have none, for history
+// from before the supervisor kept a database.
+func importLogs(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if err := os.MkdirAll(cfg.StateDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	database, err := db.Open(filepath.Join(cfg.StateDir, "claudeops.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close() //nolint:errcheck
+	sealer, err := seal.FromConfig(&cfg)
+	if err != nil {
+		return err
+	}
+	if err := database.SetSealer(sealer); err != nil {
+		return err
+	}
+
+	res, err := session.ImportLogs(database, args[0], dryRun)
+	if err != nil {
+		return err
+	}
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	for _, imp := range res.Imported {
+		s := imp.Session
+		fmt.Printf("  %s  tier %d (%s)  %s  $%.4f  %s\n", s.StartedAt, s.Tier, s.Model, s.Status, imp.CostUSD, filepath.Base(*s.LogFile))
+		redacted := 0
+		for _, n := range imp.Redactions {
+			redacted += n
+		}
+		if redacted > 0 {
+			fmt.Printf("    %d credential values redacted\n", redacted)
+		}
+	}
+	fmt.Printf("%s %d sessions from %s (%d logs already linked)\n", verb, len(res.Imported), args[0], res.Linked)
+	for _, u := range res.Unreadable {
+		fmt.Printf("  skipped %s\n", u)
+	}
+	return nil
+}
+
+// runDoctor prints each check with how to fix it, for validating an image
+// or a new deployment's mounts before starting the supervisor.
+func runDoctor(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	d := doctor.New(&cfg, web.CheckAssets)
+	var results []doctor.Result
+	if assetsOnly, _ := cmd.Flags().GetBool("assets"); assetsOnly {
+		results = d.Assets()
+	} else {
+		results = d.Run(cmd.Context())
+	}
+	if network, _ := cmd.Flags().GetBool("network"); network {
+		results = append(results, d.Network(cmd.Context())...)
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else if err := doctor.WriteTable(os.Stdout, results); err != nil {
+		return err
+	}
+	if doctor.Failed(results) {
+		cmd.SilenceUsage = true
+		return errors.New("doctor found problems")
+	}
+	return nil
+}
+
 func run(cmd *cobra.Command, args []string) error {
 	cfg := config.Load()
+	sched, err := scheduler.New(&cfg)
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	certMgr, err := certs.FromConfig(&cfg)
+	if err != nil {
+		return fmt.Errorf("invalid TLS configuration: %w", err)
+	}
 
 	fmt.Printf("Claude Ops %s starting\n", config.Version)
 	fmt.Printf("  Tier 1 model: %s\n", cfg.Tier1Model)
-	fmt.Printf("  Interval: %ds\n", cfg.Interval)
+	fmt.Printf("  Schedule: %s\n", sched)
 	fmt.Printf("  Prompt: %s\n", cfg.Prompt)
 	fmt.Printf("  State: %s\n", cfg.StateDir)
 	fmt.Printf("  Results: %s\n", cfg.ResultsDir)
 	fmt.Printf("  Repos: %s\n", cfg.ReposDir)
 	fmt.Printf("  Dry run: %t\n", cfg.DryRun)
-	fmt.Printf("  Dashboard: :%d\n", cfg.DashboardPort)
+	if cfg.PolicyFile != "" {
+		fmt.Printf("  Policy: %s\n", cfg.PolicyFile)
+	}
+	if cfg.EncryptionKey != "" || cfg.EncryptionKeyFile != "" {
+		fmt.Println("  Encryption at rest: on")
+	}
+	switch {
+	case web.SystemdActivated():
+		fmt.Println("  Dashboard: systemd socket")
+	case cfg.DashboardSocket != "":
+		fmt.Printf("  Dashboard: %s\n", cfg.DashboardSocket)
+	default:
+		fmt.Printf("  Dashboard: :%d\n", cfg.DashboardPort)
+	}
+	if cfg.AgentPort > 0 {
+		fmt.Printf("  Agent API: 127.0.0.1:%d\n", cfg.AgentPort)
+	}
+	if certMgr != nil {
+		fmt.Printf("  TLS: %s\n", certMgr)
+	}
+	if cfg.ReadOnlyUI {
+		fmt.Println("  Dashboard is read-only")
+	}
 	fmt.Println()
 
+	if err := cfg.ValidatePaths(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	guardrails, err := policy.Load(cfg.PolicyFile)
+	if err != nil {
+		return err
+	}
+	// Report problems that would otherwise surface inside the first session.
+	if problems := doctor.Problems(doctor.New(&cfg, web.CheckAssets).Run(cmd.Context())); len(problems) > 0 {
+		fmt.Println("Startup checks:")
+		_ = doctor.WriteTable(os.Stdout, problems)
+		fmt.Println()
+	}
+
 	// Ensure cooldown state file exists.
 	cooldownPath := filepath.Join(cfg.StateDir, "cooldown.json")
 	if _, err := os.Stat(cooldownPath); os.IsNotExist(err) {
@@ -165,6 +586,16 @@ func run(cmd *cobra.Command, args []string) error {
 	}
 	defer database.Close() //nolint:errcheck
 
+	// Encrypt responses, summaries, prompts, and session logs at rest when
+	// a key is configured.
+	sealer, err := seal.FromConfig(&cfg)
+	if err != nil {
+		return err
+	}
+	if err := database.SetSealer(sealer); err != nil {
+		return err
+	}
+
 	// Create SSE hub.
 	sseHub := hub.New()
 
@@ -173,17 +604,69 @@ func run(cmd *cobra.Command, args []string) error {
 	// — session manager handles scheduling, subprocess creation, and tier tracking.
 	// Governing: SPEC-0008 REQ-11 "MCP Configuration Merging"
 	// — PreSessionHook merges .claude-ops/mcp.json from repos before each session.
-	mgr := session.New(&cfg, database, sseHub, &session.CLIRunner{})
-	mgr.PreSessionHook = func() error {
-		fmt.Println("Merging MCP configurations...")
-		return mcp.MergeConfigs(cfg.MCPConfig, cfg.ReposDir)
+	var runner session.ProcessRunner = &session.CLIRunner{}
+	if cfg.Demo {
+		fmt.Println("Demo mode: replaying canned sessions instead of invoking the Claude CLI")
+		runner = session.NewDemoRunner(&cfg)
+	}
+	mgr := session.New(&cfg, database, sseHub, runner)
+	mgr.Policy = guardrails
+	mgr.Scheduler = sched
+	if !cfg.Demo {
+		mgr.PreSessionHook = func() error {
+			fmt.Println("Merging MCP configurations...")
+			return mcp.MergeConfigs(cfg.MCPConfig, cfg.ReposDir)
+		}
+	}
+	// Extensions: forward lifecycle events to external integrations.
+	ext := extension.FromConfig(&cfg)
+	if ext != nil {
+		mgr.AddHooks(ext)
+	}
+	// Paging: open PagerDuty/Opsgenie incidents and resolve them once verified.
+	pager := paging.FromConfig(&cfg, database)
+	if pager != nil {
+		mgr.AddHooks(pager)
+	}
+	// Tickets: open an issue for incidents the escalation chain left unresolved.
+	tracker, err := tickets.NewTracker(cfg.TicketProject)
+	if err != nil {
+		return err
+	}
+	var ticketOpener *session.TicketOpener
+	if tracker != nil && !cfg.DryRun {
+		ticketOpener = session.NewTicketOpener(&cfg, database, tracker)
+		mgr.AddHooks(ticketOpener)
 	}
+	// Heartbeat: dead man's switch ping after each scheduled check.
+	beat := heartbeat.FromConfig(&cfg)
+	if beat != nil {
+		mgr.AddHooks(beat)
+	}
+	mgr.DetectCLIVersion(context.Background())
+	mgr.RecoverOrphanedSessions()
+	mgr.ReportInterruptedChain()
 
 	// Governing: SPEC-0008 REQ-2 (Web Server — HTTP on configurable port, default 8080)
 	// Create and start web server (needs mgr for ad-hoc session triggers).
 	// Governing: SPEC-0023 REQ-9 — git provider registry removed; PR operations are now skill-based.
 	// Governing: SPEC-0024 REQ-5 — pass raw hub for OpenAI streaming
-	webServer := web.New(&cfg, sseHub, database, mgr, web.WithRawHub(mgr.RawHub()))
+	// Object storage for old session logs: offloads them when
+	// CLAUDEOPS_OFFLOAD_DAYS is set and reads back those already offloaded.
+	logStore := offload.FromConfig(&cfg, database)
+
+	// Notification digests: coalesce warning and critical events per
+	// service, and the notifications the agent sends.
+	dg := digest.FromConfig(&cfg, database, mgr.Notify)
+	var agentNotify func(title, body string)
+	if dg != nil {
+		agentNotify = dg.Notify
+	}
+
+	webServer := web.New(&cfg, sseHub, database, mgr, web.WithDrillTrigger(mgr.TriggerDrill), web.WithChainResume(mgr.ResumeInterruptedChain),
+		web.WithApprovals(mgr.ApproveRemediation, mgr.RejectRemediation), web.WithSimulator(mgr.Simulate),
+		web.WithSchedule(mgr.NextRun, mgr.RunNow), web.WithAdaptiveInterval(sched.AdaptiveState), web.WithLiveSession(mgr.Live), web.WithTLS(certMgr),
+		web.WithOffload(logStore), web.WithAgentNotify(agentNotify), web.WithPolicy(guardrails))
 	go func() {
 		if err := webServer.Start(); err != nil {
 			log.Printf("web server error: %v", err)
@@ -199,10 +682,79 @@ func run(cmd *cobra.Command, args []string) error {
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
 	go func() {
 		sig := <-sigCh
-		log.Printf("received %s, shutting down...", sig)
+		// Let the in-flight tier finish, up to the grace period, rather than
+		// killing a session that may be about to complete. A second signal
+		// stops it immediately.
+		mgr.Drain()
+		if mgr.IsRunning() && cfg.ShutdownGrace > 0 {
+			log.Printf("received %s, waiting up to %ds for the running session (signal again to stop it now)...", sig, cfg.ShutdownGrace)
+			select {
+			case <-time.After(time.Duration(cfg.ShutdownGrace) * time.Second):
+				log.Printf("shutdown grace period elapsed, stopping session")
+			case sig = <-sigCh:
+				log.Printf("received %s, stopping session", sig)
+			}
+		} else {
+			log.Printf("received %s, shutting down...", sig)
+		}
 		cancel()
 	}()
 
+	if ext != nil {
+		go ext.Run(ctx)
+	}
+	if pager != nil {
+		go pager.Run(ctx)
+	}
+	if ticketOpener != nil {
+		go ticketOpener.Run(ctx)
+	}
+	if beat != nil {
+		go beat.Run(ctx)
+	}
+	if certMgr != nil {
+		go certMgr.Run(ctx)
+	}
+
+	// Knowledge base: periodically compile memories into runbook articles.
+	if gen := kb.FromConfig(&cfg, database); gen != nil {
+		go gen.Run(ctx)
+	}
+
+	// Tier 0 pulse: trigger Tier 1 out-of-band when native probes fail.
+	if pm := pulse.FromConfig(&cfg, database, mgr.TriggerPulse); pm != nil {
+		go pm.Run(ctx)
+	}
+
+	if dg != nil {
+		go dg.Run(ctx)
+	}
+
+	// Weekly trend report.
+	if wr := report.FromConfig(&cfg, database, mgr.Notify); wr != nil {
+		go wr.Run(ctx)
+	}
+
+	// Memory suggestions: queue recurring event patterns for review as memories.
+	if sg := promote.FromConfig(&cfg, database); sg != nil {
+		go sg.Run(ctx)
+	}
+
+	// Log offload: move old session logs to object storage.
+	if logStore != nil {
+		go logStore.Run(ctx)
+	}
+
+	// Remediation scoring: record whether each cooldown action fixed its service.
+	if sc := remediation.FromConfig(&cfg, database); sc != nil {
+		go sc.Run(ctx)
+	}
+
+	// Self-test: periodically run a synthetic failing canary through the pipeline.
+	if cfg.SelfTestInterval > 0 {
+		go mgr.RunSelfTest(ctx)
+	}
+
 	if err := mgr.Run(ctx); err != nil {
 		return fmt.Errorf("session manager: %w", err)
 	}
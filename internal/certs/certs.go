@@ -0,0 +1,492 @@
+// Package certs provides the dashboard's TLS certificate, either loaded
+// from files or obtained from an ACME CA such as Let's Encrypt, so the
+// dashboard can serve HTTPS without a reverse proxy in front of it.
+// Certificates from files are reloaded when the files change; ACME
+// certificates are renewed before they expire.
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	// ChallengeHTTP and ChallengeDNS are the ACME challenge types supported.
+	ChallengeHTTP = "http-01"
+	ChallengeDNS  = "dns-01"
+
+	// reloadInterval is how often certificate files are checked for changes.
+	reloadInterval = time.Minute
+	// checkInterval is how often the ACME certificate is checked for
+	// renewal, and retryInterval how soon a failed issuance is retried.
+	checkInterval = 12 * time.Hour
+	retryInterval = time.Hour
+	// issueTimeout bounds one ACME issuance, including DNS propagation.
+	issueTimeout = 10 * time.Minute
+)
+
+// errNoCertificate is returned during the TLS handshake before the first
+// ACME certificate has been issued.
+var errNoCertificate = errors.New("no certificate has been issued yet")
+
+// Manager serves the current certificate to TLS handshakes.
+type Manager struct {
+	// Certificate files (CLAUDEOPS_TLS_CERT and CLAUDEOPS_TLS_KEY).
+	certFile, keyFile string
+	modTime           time.Time
+	checked           time.Time
+
+	// ACME settings.
+	domains   []string
+	email     string
+	challenge string
+	dnsHook   string
+	directory string
+	httpPort  int
+	dir       string // where the account key and certificate are kept
+
+	// tokens holds the key authorizations of pending HTTP-01 challenges,
+	// keyed by challenge path.
+	tokens sync.Map
+
+	mu   sync.Mutex
+	cert *tls.Certificate
+	now  func() time.Time
+}
+
+// FromConfig builds a Manager from the CLAUDEOPS_TLS_* and CLAUDEOPS_ACME_*
+// settings. It returns nil when TLS is not configured, and an error when the
+// settings are inconsistent or the certificate files cannot be loaded.
+func FromConfig(cfg *config.Config) (*Manager, error) {
+	certFile, keyFile := strings.TrimSpace(cfg.TLSCert), strings.TrimSpace(cfg.TLSKey)
+	var domains []string
+	for _, d := range strings.Split(cfg.ACMEDomains, ",") {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			domains = append(domains, d)
+		}
+	}
+
+	switch {
+	case certFile == "" && keyFile == "" && len(domains) == 0:
+		return nil, nil
+	case (certFile != "" || keyFile != "") && len(domains) > 0:
+		return nil, errors.New("set either a certificate and key or ACME domains, not both")
+	case len(domains) == 0:
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("a TLS certificate needs both a certificate file and a key file")
+		}
+		m := &Manager{certFile: certFile, keyFile: keyFile, now: time.Now}
+		if err := m.loadFiles(); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+
+	m := &Manager{
+		domains:   domains,
+		email:     strings.TrimSpace(cfg.ACMEEmail),
+		challenge: strings.ToLower(strings.TrimSpace(cfg.ACMEChallenge)),
+		dnsHook:   strings.TrimSpace(cfg.ACMEDNSHook),
+		directory: strings.TrimSpace(cfg.ACMEDirectory),
+		httpPort:  cfg.ACMEHTTPPort,
+		dir:       filepath.Join(cfg.StateDir, "acme"),
+		now:       time.Now,
+	}
+	if m.challenge == "" {
+		m.challenge = ChallengeHTTP
+	}
+	if m.directory == "" {
+		m.directory = acme.LetsEncryptURL
+	}
+	switch m.challenge {
+	case ChallengeHTTP:
+		if m.httpPort <= 0 {
+			return nil, errors.New("the HTTP-01 challenge needs an HTTP port")
+		}
+		for _, d := range domains {
+			if strings.HasPrefix(d, "*.") {
+				return nil, fmt.Errorf("wildcard domain %s needs the %s challenge", d, ChallengeDNS)
+			}
+		}
+	case ChallengeDNS:
+		if m.dnsHook == "" {
+			return nil, fmt.Errorf("the %s challenge needs a DNS hook command", ChallengeDNS)
+		}
+	default:
+		return nil, fmt.Errorf("unknown ACME challenge %q (want %s or %s)", m.challenge, ChallengeHTTP, ChallengeDNS)
+	}
+	if err := m.loadCached(); err != nil {
+		log.Printf("certs: ignoring cached certificate: %v", err)
+	}
+	return m, nil
+}
+
+// ACME reports whether certificates come from an ACME CA.
+func (m *Manager) ACME() bool {
+	return len(m.domains) > 0
+}
+
+// HTTPPort is the port the HTTP-01 challenge listener must run on, or 0
+// when none is needed.
+func (m *Manager) HTTPPort() int {
+	if m.ACME() && m.challenge == ChallengeHTTP {
+		return m.httpPort
+	}
+	return 0
+}
+
+// String describes where certificates come from, for the startup banner.
+func (m *Manager) String() string {
+	if m.ACME() {
+		return fmt.Sprintf("ACME %s for %s (%s)", m.challenge, strings.Join(m.domains, ", "), m.directory)
+	}
+	return "certificate " + m.certFile
+}
+
+// TLSConfig returns a server TLS configuration serving the current
+// certificate.
+func (m *Manager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: m.getCertificate,
+	}
+}
+
+func (m *Manager) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.ACME() && m.now().Sub(m.checked) >= reloadInterval {
+		m.checked = m.now()
+		if err := m.reloadFilesLocked(); err != nil {
+			log.Printf("certs: %v (keeping the loaded certificate)", err)
+		}
+	}
+	if m.cert == nil {
+		return nil, errNoCertificate
+	}
+	return m.cert, nil
+}
+
+func (m *Manager) current() *tls.Certificate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cert
+}
+
+func (m *Manager) setCert(c *tls.Certificate) {
+	m.mu.Lock()
+	m.cert = c
+	m.mu.Unlock()
+}
+
+// loadFiles loads the certificate files.
+func (m *Manager) loadFiles() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checked = m.now()
+	return m.reloadFilesLocked()
+}
+
+// reloadFilesLocked reloads the certificate files if they changed since they
+// were last loaded, such as after certbot renewed them.
+func (m *Manager) reloadFilesLocked() error {
+	var latest time.Time
+	for _, f := range []string{m.certFile, m.keyFile} {
+		info, err := os.Stat(f)
+		if err != nil {
+			return fmt.Errorf("TLS certificate: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	if m.cert != nil && !latest.After(m.modTime) {
+		return nil
+	}
+	c, err := tls.LoadX509KeyPair(m.certFile, m.keyFile)
+	if err != nil {
+		return fmt.Errorf("TLS certificate: %w", err)
+	}
+	m.cert, m.modTime = &c, latest
+	return nil
+}
+
+// HTTPHandler serves HTTP-01 challenge responses and redirects every other
+// request to the HTTPS dashboard on httpsPort.
+func (m *Manager) HTTPHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/.well-known/acme-challenge/") {
+			if resp, ok := m.tokens.Load(r.URL.Path); ok {
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = w.Write([]byte(resp.(string)))
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(httpsPort))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// Run keeps the ACME certificate issued and renewed until ctx is cancelled.
+// It does nothing for certificates loaded from files.
+func (m *Manager) Run(ctx context.Context) {
+	if !m.ACME() {
+		return
+	}
+	for {
+		wait := checkInterval
+		if m.needsRenewal(m.current()) {
+			issueCtx, cancel := context.WithTimeout(ctx, issueTimeout)
+			c, err := m.issue(issueCtx)
+			cancel()
+			switch {
+			case err != nil && ctx.Err() != nil:
+				return
+			case err != nil:
+				log.Printf("certs: obtaining a certificate for %s: %v (retrying in %s)", strings.Join(m.domains, ", "), err, retryInterval)
+				wait = retryInterval
+			default:
+				m.setCert(c)
+				log.Printf("certs: obtained a certificate for %s, valid until %s", strings.Join(m.domains, ", "), c.Leaf.NotAfter.UTC().Format(time.RFC3339))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// needsRenewal reports whether c is missing, does not cover the configured
+// domains, or is in the last third of its lifetime.
+func (m *Manager) needsRenewal(c *tls.Certificate) bool {
+	if c == nil || c.Leaf == nil {
+		return true
+	}
+	names := slices.Clone(c.Leaf.DNSNames)
+	slices.Sort(names)
+	want := slices.Clone(m.domains)
+	slices.Sort(want)
+	if !slices.Equal(names, want) {
+		return true
+	}
+	lifetime := c.Leaf.NotAfter.Sub(c.Leaf.NotBefore)
+	return m.now().After(c.Leaf.NotAfter.Add(-lifetime / 3))
+}
+
+// issue obtains a certificate for the configured domains and caches it.
+func (m *Manager) issue(ctx context.Context) (*tls.Certificate, error) {
+	accountKey, err := m.accountKey()
+	if err != nil {
+		return nil, err
+	}
+	client := &acme.Client{Key: accountKey, DirectoryURL: m.directory, UserAgent: "claude-ops"}
+	var contact []string
+	if m.email != "" {
+		contact = []string{"mailto:" + m.email}
+	}
+	if _, err := client.Register(ctx, &acme.Account{Contact: contact}, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("registering account: %w", err)
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(m.domains...))
+	if err != nil {
+		return nil, fmt.Errorf("creating order: %w", err)
+	}
+	for _, u := range order.AuthzURLs {
+		if err := m.authorize(ctx, client, u); err != nil {
+			return nil, err
+		}
+	}
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return nil, fmt.Errorf("waiting for order: %w", err)
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: m.domains}, certKey)
+	if err != nil {
+		return nil, err
+	}
+	der, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("finalizing order: %w", err)
+	}
+
+	var certPEM []byte
+	for _, b := range der {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: b})...)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	c, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(filepath.Join(m.dir, "cert.pem"), certPEM); err != nil {
+		log.Printf("certs: caching certificate: %v", err)
+	} else if err := writeFile(filepath.Join(m.dir, "key.pem"), keyPEM); err != nil {
+		log.Printf("certs: caching certificate: %v", err)
+	}
+	return c, nil
+}
+
+// authorize completes the configured challenge for one authorization.
+func (m *Manager) authorize(ctx context.Context, client *acme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("getting authorization: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == m.challenge {
+			chal = c
+			break
+		}
+	}
+	domain := authz.Identifier.Value
+	if chal == nil {
+		return fmt.Errorf("%s: the CA did not offer the %s challenge", domain, m.challenge)
+	}
+
+	switch m.challenge {
+	case ChallengeHTTP:
+		resp, err := client.HTTP01ChallengeResponse(chal.Token)
+		if err != nil {
+			return err
+		}
+		path := client.HTTP01ChallengePath(chal.Token)
+		m.tokens.Store(path, resp)
+		defer m.tokens.Delete(path)
+	case ChallengeDNS:
+		value, err := client.DNS01ChallengeRecord(chal.Token)
+		if err != nil {
+			return err
+		}
+		record := "_acme-challenge." + domain
+		if err := m.runDNSHook(ctx, "present", record, value); err != nil {
+			return fmt.Errorf("%s: DNS hook: %w", domain, err)
+		}
+		defer func() {
+			// Clean up even when ctx has expired.
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if err := m.runDNSHook(cleanupCtx, "cleanup", record, value); err != nil {
+				log.Printf("certs: %s: DNS hook cleanup: %v", domain, err)
+			}
+		}()
+	}
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return fmt.Errorf("%s: accepting challenge: %w", domain, err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("%s: %w", domain, err)
+	}
+	return nil
+}
+
+// accountKey loads the ACME account key, creating it on first use.
+func (m *Manager) accountKey() (*ecdsa.PrivateKey, error) {
+	path := filepath.Join(m.dir, "account.key")
+	if data, err := os.ReadFile(path); err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s: not a PEM key", path)
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})); err != nil {
+		return nil, fmt.Errorf("saving account key: %w", err)
+	}
+	return key, nil
+}
+
+// loadCached loads the certificate saved by the last issuance, so a restart
+// does not ask the CA for a new one.
+func (m *Manager) loadCached() error {
+	certPEM, err := os.ReadFile(filepath.Join(m.dir, "cert.pem"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(m.dir, "key.pem"))
+	if err != nil {
+		return err
+	}
+	c, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	m.setCert(c)
+	return nil
+}
+
+func parseKeyPair(certPEM, keyPEM []byte) (*tls.Certificate, error) {
+	c, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if c.Leaf == nil {
+		if c.Leaf, err = x509.ParseCertificate(c.Certificate[0]); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// writeFile writes a private file, creating its directory.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
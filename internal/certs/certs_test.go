@@ -0,0 +1,224 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+// selfSigned returns PEM certificate and key for names, valid from
+// notBefore for lifetime.
+func selfSigned(t *testing.T, names []string, notBefore time.Time, lifetime time.Duration) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(lifetime),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestFromConfigValidation(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM := selfSigned(t, []string{"ops.example.com"}, time.Now(), 90*24*time.Hour)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	_ = os.WriteFile(certFile, certPEM, 0o600)
+	_ = os.WriteFile(keyFile, keyPEM, 0o600)
+
+	if m, err := FromConfig(&config.Config{}); m != nil || err != nil {
+		t.Errorf("unconfigured: %v, %v", m, err)
+	}
+	for name, cfg := range map[string]*config.Config{
+		"cert without key":  {TLSCert: certFile},
+		"missing files":     {TLSCert: filepath.Join(dir, "nope.pem"), TLSKey: keyFile},
+		"files and ACME":    {TLSCert: certFile, TLSKey: keyFile, ACMEDomains: "ops.example.com"},
+		"unknown challenge": {ACMEDomains: "ops.example.com", ACMEChallenge: "tls-alpn-01", ACMEHTTPPort: 80},
+		"dns without hook":  {ACMEDomains: "ops.example.com", ACMEChallenge: "dns-01"},
+		"http wildcard":     {ACMEDomains: "*.example.com", ACMEHTTPPort: 80},
+		"http without port": {ACMEDomains: "ops.example.com"},
+	} {
+		if _, err := FromConfig(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	m, err := FromConfig(&config.Config{ACMEDomains: " Ops.Example.com, *.lab.example.com ", ACMEChallenge: "dns-01", ACMEDNSHook: "true", StateDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.ACME() || m.HTTPPort() != 0 || m.directory != "https://acme-v02.api.letsencrypt.org/directory" {
+		t.Errorf("dns-01 manager: %+v", m)
+	}
+	if got := m.String(); !strings.Contains(got, "ops.example.com, *.lab.example.com") {
+		t.Errorf("String() = %q", got)
+	}
+	if m, _ := FromConfig(&config.Config{ACMEDomains: "ops.example.com", ACMEHTTPPort: 8081, StateDir: dir}); m.HTTPPort() != 8081 {
+		t.Errorf("http-01 port = %d", m.HTTPPort())
+	}
+}
+
+func TestFileCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	write := func(name string, mod time.Time) {
+		certPEM, keyPEM := selfSigned(t, []string{name}, time.Now(), 90*24*time.Hour)
+		for f, data := range map[string][]byte{certFile: certPEM, keyFile: keyPEM} {
+			if err := os.WriteFile(f, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_ = os.Chtimes(f, mod, mod)
+		}
+	}
+	served := func(m *Manager) string {
+		t.Helper()
+		c, err := m.TLSConfig().GetCertificate(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(c.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.DNSNames[0]
+	}
+
+	start := time.Now()
+	write("old.example.com", start.Add(-time.Hour))
+	m, err := FromConfig(&config.Config{TLSCert: certFile, TLSKey: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := m.checked
+	m.now = func() time.Time { return clock }
+	if got := served(m); got != "old.example.com" {
+		t.Fatalf("served %s", got)
+	}
+
+	write("new.example.com", start)
+	if got := served(m); got != "old.example.com" {
+		t.Errorf("files re-read before the reload interval: %s", got)
+	}
+	clock = clock.Add(reloadInterval)
+	if got := served(m); got != "new.example.com" {
+		t.Errorf("renewed files not picked up: %s", got)
+	}
+
+	_ = os.WriteFile(keyFile, []byte("garbage"), 0o600)
+	_ = os.Chtimes(keyFile, start.Add(time.Hour), start.Add(time.Hour))
+	clock = clock.Add(reloadInterval)
+	if got := served(m); got != "new.example.com" {
+		t.Errorf("broken files replaced the loaded certificate: %s", got)
+	}
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	dir := t.TempDir()
+	m, err := FromConfig(&config.Config{ACMEDomains: "a.example.com,b.example.com", ACMEHTTPPort: 80, StateDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.current() != nil {
+		t.Fatal("certificate before any was issued")
+	}
+	if _, err := m.TLSConfig().GetCertificate(&tls.ClientHelloInfo{}); err != errNoCertificate {
+		t.Errorf("handshake before issuance: %v", err)
+	}
+
+	issued := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	certPEM, keyPEM := selfSigned(t, []string{"b.example.com", "a.example.com"}, issued, 90*24*time.Hour)
+	c, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = func() time.Time { return issued.Add(59 * 24 * time.Hour) }
+	if m.needsRenewal(c) {
+		t.Error("renewing with more than a third of the lifetime left")
+	}
+	m.now = func() time.Time { return issued.Add(61 * 24 * time.Hour) }
+	if !m.needsRenewal(c) {
+		t.Error("not renewing in the last third of the lifetime")
+	}
+	m.now = func() time.Time { return issued }
+	m.domains = []string{"a.example.com"}
+	if !m.needsRenewal(c) {
+		t.Error("not renewing after the domains changed")
+	}
+
+	// A cached certificate is served after a restart.
+	_ = writeFile(filepath.Join(dir, "acme", "cert.pem"), certPEM)
+	_ = writeFile(filepath.Join(dir, "acme", "key.pem"), keyPEM)
+	if m, _ = FromConfig(&config.Config{ACMEDomains: "a.example.com,b.example.com", ACMEHTTPPort: 80, StateDir: dir}); m.current() == nil {
+		t.Error("cached certificate not loaded")
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	m := &Manager{}
+	m.tokens.Store("/.well-known/acme-challenge/tok", "tok.thumbprint")
+	h := m.HTTPHandler(8443)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://ops.example.com/.well-known/acme-challenge/tok", nil))
+	if w.Code != 200 || w.Body.String() != "tok.thumbprint" {
+		t.Errorf("challenge: %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://ops.example.com/.well-known/acme-challenge/other", nil))
+	if w.Code != 404 {
+		t.Errorf("unknown token: %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://ops.example.com:80/sessions/7?tab=log", nil))
+	if loc := w.Header().Get("Location"); w.Code != 301 || loc != "https://ops.example.com:8443/sessions/7?tab=log" {
+		t.Errorf("redirect: %d %q", w.Code, loc)
+	}
+	w = httptest.NewRecorder()
+	m.HTTPHandler(443).ServeHTTP(w, httptest.NewRequest("GET", "http://ops.example.com/", nil))
+	if loc := w.Header().Get("Location"); loc != "https://ops.example.com/" {
+		t.Errorf("redirect to 443: %q", loc)
+	}
+}
+
+func TestDNSHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.log")
+	m := &Manager{dnsHook: `echo "$CLAUDEOPS_ACME_ACTION $CLAUDEOPS_ACME_RECORD $CLAUDEOPS_ACME_VALUE" >> ` + out}
+	if err := m.runDNSHook(context.Background(), "present", "_acme-challenge.ops.example.com", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "present _acme-challenge.ops.example.com abc\n" {
+		t.Errorf("hook saw %q", data)
+	}
+	m.dnsHook = "echo no API token >&2; exit 1"
+	if err := m.runDNSHook(context.Background(), "present", "r", "v"); err == nil || !strings.Contains(err.Error(), "no API token") {
+		t.Errorf("failing hook: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package certs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runDNSHook runs the DNS-01 hook command, which publishes ("present") or
+// removes ("cleanup") the TXT record. The hook gets the action, the record
+// name, and its value in CLAUDEOPS_ACME_ACTION, CLAUDEOPS_ACME_RECORD, and
+// CLAUDEOPS_ACME_VALUE, and should return once the record is visible to the
+// CA's resolvers.
+func (m *Manager) runDNSHook(ctx context.Context, action, record, value string) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", m.dnsHook)
+	cmd.Env = append(os.Environ(),
+		"CLAUDEOPS_ACME_ACTION="+action,
+		"CLAUDEOPS_ACME_RECORD="+record,
+		"CLAUDEOPS_ACME_VALUE="+value,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -30,10 +30,49 @@ type Config struct {
 	MCPConfig     string
 	DashboardPort int
 	MaxTier       int
+	// NoTierSkip sends an escalation the agent asked to take past the next
+	// tier (Tier 1 straight to Tier 3) to the next tier instead.
+	NoTierSkip    bool
 	Tier2Prompt   string
 	Tier3Prompt   string
+	// Tier2PromptRules selects specialized Tier 2 prompts from the handoff:
+	// "prompt.md=keyword|keyword;other.md=keyword".
+	Tier2PromptRules      string
 	MemoryBudget          int
+	// MemoryVerifiedOnly restricts memory injection to operator-verified memories.
+	MemoryVerifiedOnly    bool
+	// MemoryTier1PerService caps the memories injected per service into
+	// Tier 1 sessions (0 = no cap, limited only by MemoryBudget).
+	MemoryTier1PerService int
+	// MemoryTombstoneDays is how long a deleted or rejected memory suppresses
+	// the agent re-learning the same observation (0 disables suppression).
+	MemoryTombstoneDays   int
+	// MemoryCategories lists the allowed memory categories
+	// (comma-separated). Agent memories with a misspelled category are
+	// normalized to the closest one; MemoryStrict rejects those matching
+	// none as parse warnings instead of storing them. Empty allows any.
+	MemoryCategories      string
+	MemoryStrict          bool
+	// MemorySuggestInterval is the hours between looking for recurring
+	// events to suggest as memories (0 disables): a service's warning or
+	// critical events with similar messages at least MemorySuggestCount
+	// times in MemorySuggestDays days are queued for review as a memory.
+	MemorySuggestInterval int
+	MemorySuggestCount    int
+	MemorySuggestDays     int
+	// MemoryReinforce, MemoryContradict, and MemoryDecay are how much an
+	// observation seen again raises its memory's confidence, how much a
+	// contradicting one lowers it, and how much a memory not updated in 30
+	// days loses each time memories decay (before each escalation chain).
+	// MemoryScoring overrides them per category.
+	MemoryReinforce  float64
+	MemoryContradict float64
+	MemoryDecay      float64
+	MemoryScoring    string
 	BrowserAllowedOrigins string
+	// BrowserCDPURL is the browser sidecar's DevTools endpoint, checked by
+	// `claudeops doctor --network`.
+	BrowserCDPURL string
 	// Governing: SPEC-0021 REQ "Summarization Model"
 	SummaryModel string
 	// Governing: SPEC-0025 REQ "Webhook Model Configuration"
@@ -41,6 +80,209 @@ type Config struct {
 	WebhookSystemPrompt string
 	// Governing: ADR-0030, SPEC-0031 REQ-4 "CLI Integration" — path to JSON Schema for structured output
 	SchemaPath string
+	// Proxmox VE integration: hypervisor inventory and guarded guest power actions.
+	// Disabled when ProxmoxURL is empty.
+	ProxmoxURL         string
+	ProxmoxTokenID     string
+	ProxmoxTokenSecret string
+	ProxmoxInsecureTLS bool
+	// Log source integration: recent error logs for affected services are
+	// pre-fetched and attached to the escalation context. Disabled when
+	// LogSource is empty.
+	LogSource    string // "loki" or "journald"
+	LokiURL      string
+	LokiSelector string // LogQL query; "$service" is replaced with the service name
+	JournaldUnit string // unit or FIELD=value match; "$service" is replaced with the service name
+	LogLookback  int    // minutes
+	LogLines     int    // max lines per service
+	// Knowledge base: per-service runbook articles compiled from memories
+	// with SummaryModel. Disabled when KBInterval is 0.
+	KBInterval  int    // hours between regenerations
+	KBExportDir string // optional directory (or git checkout) the articles are written to
+	// Tier 0 pulse: native HTTP/TCP probes between sessions that trigger an
+	// out-of-band Tier 1 session on repeated failure. Disabled when
+	// PulseTargets is empty.
+	PulseTargets   string // comma-separated service=url (http, https, or tcp://host:port)
+	PulseInterval  int    // seconds
+	PulseThreshold int    // consecutive failures before triggering
+	// Post-remediation verification: an observation-only session scheduled
+	// after each Tier 3 remediation. Disabled when VerifyDelay is 0.
+	VerifyDelay  int // minutes after remediation
+	VerifyModel  string
+	VerifyPrompt string
+	// Self-test: hours between drills that run a synthetic failing canary
+	// through the escalation pipeline (0 disables scheduled drills).
+	SelfTestInterval int
+	// ConfirmCostThreshold (USD) requires a confirmation checkbox before a
+	// dashboard run whose estimated cost exceeds it (0 disables).
+	ConfirmCostThreshold float64
+	// StreamDropWarn shows a warning on the session page when more than this
+	// many stream-json events were dropped as unknown or unparseable.
+	StreamDropWarn int
+	// StripThinking leaves extended thinking blocks out of stored session logs.
+	StripThinking bool
+	// NoLogCompression keeps session logs as plain NDJSON instead of
+	// gzipping them when their session ends.
+	NoLogCompression bool
+	// ShutdownGrace is how many seconds shutdown waits for the in-flight
+	// session tier to finish before cancelling it.
+	ShutdownGrace int
+	// Demo replays canned sessions instead of invoking the Claude CLI.
+	Demo bool
+	// PolicyFile is a YAML file of CEL rules evaluated before escalations
+	// and before recording cooldown actions (empty disables policies).
+	PolicyFile string
+	// EncryptionKey (base64 or hex, 32 bytes) or EncryptionKeyFile turns on
+	// at-rest encryption of session responses, summaries, prompts, and
+	// logs.
+	EncryptionKey     string
+	EncryptionKeyFile string
+	// OffloadDays moves session logs older than this many days to
+	// S3-compatible object storage, leaving a stub in the results
+	// directory, along with the diffs and change reports the database
+	// stores for them (0 disables). S3Endpoint is the storage's base URL, e.g.
+	// https://s3.us-east-1.amazonaws.com or a MinIO server; objects are
+	// stored under S3Prefix in S3Bucket.
+	OffloadDays int
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3Prefix    string
+	S3AccessKey string
+	S3SecretKey string
+	// TwoPersonServices lists services whose Tier 3 remediation needs
+	// approval from two distinct operators (comma-separated).
+	TwoPersonServices string
+	// ApprovalTTL is how many minutes an approval request stays open.
+	ApprovalTTL int
+	// TrustedProxies lists the authenticating reverse proxies (IP
+	// addresses or CIDR ranges, comma-separated; "unix" for the dashboard's
+	// Unix socket) whose Remote-User and X-Forwarded-User headers identify
+	// the operator. Two-person approvals need such an identity.
+	TrustedProxies string
+	// EscalationCooldown is how many minutes after a chain escalates for a
+	// service that another chain's escalation for it is suppressed (0
+	// disables).
+	EscalationCooldown int
+	// FreshnessWindow is how many minutes a finished session's healthy
+	// check of a service keeps it out of the next scheduled Tier 1 run (0
+	// disables).
+	FreshnessWindow int
+	// SyntheticPricing prices sessions from token usage when the CLI reports
+	// zero cost (Claude subscription plans):
+	// "model=input/output[/cache_write/cache_read]" in USD per million tokens.
+	SyntheticPricing string
+	// ContextWarnPercent emits a warning event when a session's context
+	// reaches this share of the model's context window (0 disables).
+	ContextWarnPercent int
+	// SplitTurns and SplitContextPercent split a Tier 3 session into a
+	// continuation session once it reaches this many turns or this share of
+	// the context window (0 disables each limit). MaxSplits caps the
+	// continuation sessions per chain.
+	SplitTurns          int
+	SplitContextPercent int
+	MaxSplits           int
+	// NotifyDigestWindow groups warning and critical events per service into
+	// one notification per this many minutes; the first critical of a window
+	// is sent immediately (0 disables).
+	NotifyDigestWindow int
+	// ServiceAliases maps the names agents use for a service to its
+	// canonical name: comma-separated alias=service pairs.
+	ServiceAliases string
+	// WeeklyReportDay is the weekday ("monday") on which the weekly trend
+	// report is pushed through notifications (empty disables).
+	WeeklyReportDay string
+	// RemediationScoreHours is how long after a remediation its service must
+	// stay free of critical events for the remediation to count as having
+	// worked (0 disables scoring).
+	RemediationScoreHours int
+	// BriefMinutes is how long the spoken status brief served at
+	// /api/v1/brief is reused before it is regenerated.
+	BriefMinutes int
+	// Extensions lists targets that receive every session lifecycle event as
+	// JSON: http(s) URLs, and "exec:" commands (comma-separated).
+	Extensions string
+	// PagerDutyRoutingKey and OpsgenieAPIKey enable paging: critical events
+	// and failed remediations open incidents that are resolved once
+	// verified (empty disables each). OpsgenieURL selects the Opsgenie API
+	// region.
+	PagerDutyRoutingKey string
+	OpsgenieAPIKey      string
+	OpsgenieURL         string
+	// TicketProject is where issues are opened for unresolved incidents:
+	// github:owner/repo, gitea:owner/repo, or jira:PROJ (empty disables).
+	TicketProject string
+	// Schedule is a cron expression for scheduled runs, used instead of
+	// Interval when set. Jitter delays each scheduled run by a random 0 to
+	// Jitter seconds.
+	Schedule string
+	Jitter   int
+	// IntervalMax enables the adaptive interval: after every AdaptiveRuns
+	// consecutive scheduled runs that found everything healthy the interval
+	// doubles, up to IntervalMax seconds, and after a run that did not it
+	// halves, down to IntervalMin seconds (Interval when 0). 0 disables.
+	IntervalMin  int
+	IntervalMax  int
+	AdaptiveRuns int
+	// DashboardURL is the dashboard's external URL, used to link to
+	// sessions from outside it, such as from issues (empty links nowhere).
+	DashboardURL string
+	// ReadOnlyUI rejects every dashboard and API request that would change
+	// state (config, runs, memories, chat) and hides the controls for them.
+	ReadOnlyUI bool
+	// DashboardSocket serves the dashboard on this Unix socket instead of
+	// DashboardPort. A socket passed by systemd socket activation takes
+	// precedence over both.
+	DashboardSocket string
+	// AgentPort is a loopback-only port serving the API routes the agent
+	// calls back into (hypervisor actions and self-test drill endpoints),
+	// so they work whatever the dashboard listens on. 0 has the agent use
+	// the dashboard's own listener.
+	AgentPort int
+	// TLSCert and TLSKey are PEM files the dashboard serves HTTPS with.
+	TLSCert string
+	TLSKey  string
+	// ACMEDomains obtains the dashboard's certificate from an ACME CA for
+	// these domains (comma-separated) instead, using ACMEChallenge
+	// ("http-01", answered on ACMEHTTPPort, or "dns-01", whose TXT records
+	// ACMEDNSHook publishes). ACMEDirectory is the CA's directory URL.
+	ACMEDomains   string
+	ACMEEmail     string
+	ACMEChallenge string
+	ACMEDNSHook   string
+	ACMEDirectory string
+	ACMEHTTPPort  int
+	// HeartbeatURL is pinged after each scheduled check, healthchecks.io
+	// style or as an Uptime Kuma push monitor (empty disables).
+	HeartbeatURL string
+	// Tier1Env, Tier2Env, and Tier3Env add NAME=value pairs
+	// (semicolon-separated) to that tier's CLI environment; a value
+	// prefixed with "secret:" is redacted.
+	Tier1Env string
+	Tier2Env string
+	Tier3Env string
+	// Tier1Dir, Tier2Dir, and Tier3Dir are the working directories that
+	// tier's CLI runs in, e.g. an infrastructure repo checkout (empty uses
+	// the supervisor's own).
+	Tier1Dir string
+	Tier2Dir string
+	Tier3Dir string
+	// GitAutoCommit commits the changes a Tier 2 or Tier 3 session leaves
+	// in a git repository to a claudeops/session-<id> branch. GitPush
+	// pushes that branch to origin, and GitOpenPR opens a pull request
+	// for it.
+	GitAutoCommit bool
+	GitPush       bool
+	GitOpenPR     bool
+	// Sandbox captures the file changes Tier 2 and Tier 3 sessions make
+	// under the repos directory as a change report, applied only once an
+	// operator approves it.
+	Sandbox bool
+	// PromptStore is where prompts edited on the dashboard are saved:
+	// "file" writes the configured prompt file, "db" keeps them in the
+	// database, where they take precedence over the file. Every save is
+	// kept as a version either way.
+	PromptStore string
 }
 
 // Load reads configuration from viper, which merges flag values, env vars,
@@ -68,13 +310,112 @@ func Load() Config {
 		MCPConfig:     viper.GetString("mcp_config"),
 		DashboardPort: viper.GetInt("dashboard_port"),
 		MaxTier:       viper.GetInt("max_tier"),
+		NoTierSkip:    viper.GetBool("no_tier_skip"),
 		Tier2Prompt:   viper.GetString("tier2_prompt"),
 		Tier3Prompt:   viper.GetString("tier3_prompt"),
+		Tier2PromptRules:      viper.GetString("tier2_prompt_rules"),
 		MemoryBudget:          viper.GetInt("memory_budget"),
+		MemoryTier1PerService: viper.GetInt("memory_tier1_per_service"),
+		MemoryVerifiedOnly:    viper.GetBool("memory_verified_only"),
+		MemoryTombstoneDays:   viper.GetInt("memory_tombstone_days"),
+		MemoryCategories:      viper.GetString("memory_categories"),
+		MemoryStrict:          viper.GetBool("memory_strict"),
+		MemorySuggestInterval: viper.GetInt("memory_suggest_interval"),
+		MemorySuggestCount:    viper.GetInt("memory_suggest_count"),
+		MemorySuggestDays:     viper.GetInt("memory_suggest_days"),
+		MemoryReinforce:       viper.GetFloat64("memory_reinforce"),
+		MemoryContradict:      viper.GetFloat64("memory_contradict"),
+		MemoryDecay:           viper.GetFloat64("memory_decay"),
+		MemoryScoring:         viper.GetString("memory_scoring"),
 		BrowserAllowedOrigins: viper.GetString("browser_allowed_origins"),
+		BrowserCDPURL:         viper.GetString("browser_cdp_url"),
 		SummaryModel:          viper.GetString("summary_model"),
 		WebhookModel:          viper.GetString("webhook_model"),
 		WebhookSystemPrompt:   viper.GetString("webhook_system_prompt"),
 		SchemaPath:            viper.GetString("schema_path"),
+		ProxmoxURL:            viper.GetString("proxmox_url"),
+		ProxmoxTokenID:        viper.GetString("proxmox_token_id"),
+		ProxmoxTokenSecret:    viper.GetString("proxmox_token_secret"),
+		ProxmoxInsecureTLS:    viper.GetBool("proxmox_insecure_tls"),
+		LogSource:             viper.GetString("log_source"),
+		LokiURL:               viper.GetString("loki_url"),
+		LokiSelector:          viper.GetString("loki_selector"),
+		JournaldUnit:          viper.GetString("journald_unit"),
+		LogLookback:           viper.GetInt("log_lookback"),
+		LogLines:              viper.GetInt("log_lines"),
+		KBInterval:            viper.GetInt("kb_interval"),
+		KBExportDir:           viper.GetString("kb_export_dir"),
+		PulseTargets:          viper.GetString("pulse_targets"),
+		PulseInterval:         viper.GetInt("pulse_interval"),
+		PulseThreshold:        viper.GetInt("pulse_threshold"),
+		VerifyDelay:           viper.GetInt("verify_delay"),
+		VerifyModel:           viper.GetString("verify_model"),
+		VerifyPrompt:          viper.GetString("verify_prompt"),
+		SelfTestInterval:      viper.GetInt("selftest_interval"),
+		ConfirmCostThreshold:  viper.GetFloat64("confirm_cost_threshold"),
+		StreamDropWarn:        viper.GetInt("stream_drop_warn"),
+		StripThinking:         viper.GetBool("strip_thinking"),
+		NoLogCompression:      viper.GetBool("no_log_compression"),
+		ShutdownGrace:         viper.GetInt("shutdown_grace"),
+		Demo:                  viper.GetBool("demo"),
+		PolicyFile:            viper.GetString("policy_file"),
+		EncryptionKey:         viper.GetString("encryption_key"),
+		EncryptionKeyFile:     viper.GetString("encryption_key_file"),
+		OffloadDays:           viper.GetInt("offload_days"),
+		S3Endpoint:            viper.GetString("s3_endpoint"),
+		S3Region:              viper.GetString("s3_region"),
+		S3Bucket:              viper.GetString("s3_bucket"),
+		S3Prefix:              viper.GetString("s3_prefix"),
+		S3AccessKey:           viper.GetString("s3_access_key"),
+		S3SecretKey:           viper.GetString("s3_secret_key"),
+		TwoPersonServices:     viper.GetString("two_person_services"),
+		TrustedProxies:        viper.GetString("trusted_proxies"),
+		ApprovalTTL:           viper.GetInt("approval_ttl"),
+		EscalationCooldown:    viper.GetInt("escalation_cooldown"),
+		FreshnessWindow:       viper.GetInt("freshness_window"),
+		SyntheticPricing:      viper.GetString("synthetic_pricing"),
+		ContextWarnPercent:    viper.GetInt("context_warn_percent"),
+		SplitTurns:            viper.GetInt("split_turns"),
+		SplitContextPercent:   viper.GetInt("split_context_percent"),
+		MaxSplits:             viper.GetInt("max_splits"),
+		NotifyDigestWindow:    viper.GetInt("notify_digest_window"),
+		ServiceAliases:        viper.GetString("service_aliases"),
+		WeeklyReportDay:       viper.GetString("weekly_report_day"),
+		RemediationScoreHours: viper.GetInt("remediation_score_hours"),
+		BriefMinutes:          viper.GetInt("brief_minutes"),
+		Extensions:            viper.GetString("extensions"),
+		PagerDutyRoutingKey:   viper.GetString("pagerduty_routing_key"),
+		OpsgenieAPIKey:        viper.GetString("opsgenie_api_key"),
+		OpsgenieURL:           viper.GetString("opsgenie_url"),
+		TicketProject:         viper.GetString("ticket_project"),
+		Schedule:              viper.GetString("schedule"),
+		Jitter:                viper.GetInt("jitter"),
+		IntervalMin:           viper.GetInt("interval_min"),
+		IntervalMax:           viper.GetInt("interval_max"),
+		AdaptiveRuns:          viper.GetInt("adaptive_runs"),
+		DashboardURL:          viper.GetString("dashboard_url"),
+		ReadOnlyUI:            viper.GetBool("readonly_ui"),
+		DashboardSocket:       viper.GetString("dashboard_socket"),
+		AgentPort:             viper.GetInt("agent_port"),
+		TLSCert:               viper.GetString("tls_cert"),
+		TLSKey:                viper.GetString("tls_key"),
+		ACMEDomains:           viper.GetString("acme_domains"),
+		ACMEEmail:             viper.GetString("acme_email"),
+		ACMEChallenge:         viper.GetString("acme_challenge"),
+		ACMEDNSHook:           viper.GetString("acme_dns_hook"),
+		ACMEDirectory:         viper.GetString("acme_directory"),
+		ACMEHTTPPort:          viper.GetInt("acme_http_port"),
+		HeartbeatURL:          viper.GetString("heartbeat_url"),
+		Tier1Env:              viper.GetString("tier1_env"),
+		Tier2Env:              viper.GetString("tier2_env"),
+		Tier3Env:              viper.GetString("tier3_env"),
+		Tier1Dir:              viper.GetString("tier1_dir"),
+		Tier2Dir:              viper.GetString("tier2_dir"),
+		Tier3Dir:              viper.GetString("tier3_dir"),
+		GitAutoCommit:         viper.GetBool("git_auto_commit"),
+		GitPush:               viper.GetBool("git_push"),
+		GitOpenPR:             viper.GetBool("git_open_pr"),
+		Sandbox:               viper.GetBool("sandbox"),
+		PromptStore:           viper.GetString("prompt_store"),
 	}
 }
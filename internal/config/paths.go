@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/joestump/claude-ops/prompts"
+)
+
+// containerAppDir is where the Docker image installs prompts, schemas and
+// the MCP config.
+const containerAppDir = "/app"
+
+// appName names the per-user directories outside the container.
+const appName = "claude-ops"
+
+// Paths are the default file locations for the platform.
+type Paths struct {
+	StateDir   string
+	ResultsDir string
+	ReposDir   string
+	// AppDir holds prompts/, schemas/ and .claude/mcp.json.
+	AppDir string
+}
+
+// Prompt returns the default path of a bundled prompt file.
+func (p Paths) Prompt(name string) string {
+	return filepath.Join(p.AppDir, "prompts", name)
+}
+
+// DefaultPaths returns the default file locations. Inside the Docker image,
+// detected by its /app/prompts directory, they are the image's fixed mounts.
+// Elsewhere they follow the platform's conventions, and the bundled files
+// are looked up next to the binary before the per-user data directory.
+func DefaultPaths() Paths {
+	if isDir(filepath.Join(containerAppDir, "prompts")) {
+		return Paths{StateDir: "/state", ResultsDir: "/results", ReposDir: "/repos", AppDir: containerAppDir}
+	}
+	home, _ := os.UserHomeDir()
+	p := platformPaths(runtime.GOOS, os.Getenv, home)
+	if exe, err := os.Executable(); err == nil && isDir(filepath.Join(filepath.Dir(exe), "prompts")) {
+		p.AppDir = filepath.Dir(exe)
+	}
+	return p
+}
+
+// platformPaths returns per-user locations: %LOCALAPPDATA% on Windows,
+// ~/Library/Application Support on macOS, and the XDG state and data
+// directories elsewhere.
+func platformPaths(goos string, getenv func(string) string, home string) Paths {
+	switch goos {
+	case "windows":
+		base := getenv("LOCALAPPDATA")
+		if base == "" {
+			base = filepath.Join(home, "AppData", "Local")
+		}
+		return perUserPaths(filepath.Join(base, appName), filepath.Join(base, appName))
+	case "darwin":
+		base := filepath.Join(home, "Library", "Application Support", appName)
+		return perUserPaths(base, base)
+	default:
+		state := getenv("XDG_STATE_HOME")
+		if state == "" {
+			state = filepath.Join(home, ".local", "state")
+		}
+		data := getenv("XDG_DATA_HOME")
+		if data == "" {
+			data = filepath.Join(home, ".local", "share")
+		}
+		return perUserPaths(filepath.Join(state, appName), filepath.Join(data, appName))
+	}
+}
+
+func perUserPaths(stateBase, dataBase string) Paths {
+	return Paths{
+		StateDir:   filepath.Join(stateBase, "state"),
+		ResultsDir: filepath.Join(stateBase, "results"),
+		ReposDir:   filepath.Join(dataBase, "repos"),
+		AppDir:     dataBase,
+	}
+}
+
+// ValidatePaths creates the state, results and repos directories if needed
+// and checks that they are writable and that the prompts for the enabled
+// tiers exist or have an embedded default. All problems are reported together, each naming the setting
+// that fixes it.
+func (c *Config) ValidatePaths() error {
+	var errs []error
+	for _, d := range []struct{ path, key string }{
+		{c.StateDir, "state_dir"},
+		{c.ResultsDir, "results_dir"},
+		{c.ReposDir, "repos_dir"},
+	} {
+		if err := WritableDir(d.path); err != nil {
+			errs = append(errs, fmt.Errorf("%w (set %s)", err, Setting(d.key)))
+		}
+	}
+
+	required := []struct {
+		path, key string
+		needed    bool
+	}{
+		{c.Prompt, "prompt", true},
+		{c.Tier2Prompt, "tier2_prompt", c.MaxTier >= 2},
+		{c.Tier3Prompt, "tier3_prompt", c.MaxTier >= 3},
+		{c.VerifyPrompt, "verify_prompt", c.VerifyDelay > 0},
+	}
+	for _, p := range required {
+		if !p.needed {
+			continue
+		}
+		if _, err := os.Stat(p.path); err != nil {
+			// A missing file falls back to the prompt embedded in the binary.
+			if _, ok := prompts.Default(p.path); ok && os.IsNotExist(err) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("prompt file: %w (set %s)", err, Setting(p.key)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WritableDir creates dir if it is missing and checks that files can be
+// created in it.
+func WritableDir(dir string) error {
+	if dir == "" {
+		return errors.New("directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return fmt.Errorf("directory %q is not writable: %w", dir, err)
+	}
+	name := f.Name()
+	_ = f.Close()
+	_ = os.Remove(name)
+	return nil
+}
+
+// Setting names the flag and environment variable for a viper key.
+func Setting(key string) string {
+	return fmt.Sprintf("--%s or CLAUDEOPS_%s", strings.ReplaceAll(key, "_", "-"), strings.ToUpper(key))
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
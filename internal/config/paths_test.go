@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlatformPaths(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+	home := filepath.Join("home", "op")
+
+	tests := []struct {
+		name  string
+		goos  string
+		vars  map[string]string
+		state string
+		data  string
+	}{
+		{"linux defaults", "linux", nil,
+			filepath.Join(home, ".local", "state", "claude-ops"), filepath.Join(home, ".local", "share", "claude-ops")},
+		{"linux xdg", "linux", map[string]string{"XDG_STATE_HOME": "xs", "XDG_DATA_HOME": "xd"},
+			filepath.Join("xs", "claude-ops"), filepath.Join("xd", "claude-ops")},
+		{"macos", "darwin", nil,
+			filepath.Join(home, "Library", "Application Support", "claude-ops"), filepath.Join(home, "Library", "Application Support", "claude-ops")},
+		{"windows", "windows", map[string]string{"LOCALAPPDATA": "lad"},
+			filepath.Join("lad", "claude-ops"), filepath.Join("lad", "claude-ops")},
+		{"windows without LOCALAPPDATA", "windows", nil,
+			filepath.Join(home, "AppData", "Local", "claude-ops"), filepath.Join(home, "AppData", "Local", "claude-ops")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := platformPaths(tt.goos, env(tt.vars), home)
+			want := Paths{
+				StateDir:   filepath.Join(tt.state, "state"),
+				ResultsDir: filepath.Join(tt.state, "results"),
+				ReposDir:   filepath.Join(tt.data, "repos"),
+				AppDir:     tt.data,
+			}
+			if p != want {
+				t.Errorf("got %+v, want %+v", p, want)
+			}
+		})
+	}
+}
+
+func TestValidatePaths(t *testing.T) {
+	dir := t.TempDir()
+	prompt := filepath.Join(dir, "tier1.md")
+	if err := os.WriteFile(prompt, []byte("check"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		StateDir:   filepath.Join(dir, "state"),
+		ResultsDir: filepath.Join(dir, "nested", "results"),
+		ReposDir:   filepath.Join(dir, "repos"),
+		Prompt:     prompt,
+		MaxTier:    1,
+	}
+	if err := cfg.ValidatePaths(); err != nil {
+		t.Fatalf("ValidatePaths: %v", err)
+	}
+	for _, d := range []string{cfg.StateDir, cfg.ResultsDir, cfg.ReposDir} {
+		if !isDir(d) {
+			t.Errorf("expected %s to be created", d)
+		}
+	}
+
+	// A file in the way of a directory and a missing Tier 2 prompt are both
+	// reported, each with the setting to change.
+	cfg.ReposDir = prompt
+	cfg.MaxTier = 2
+	cfg.Tier2Prompt = filepath.Join(dir, "missing.md")
+	err := cfg.ValidatePaths()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{"CLAUDEOPS_REPOS_DIR", "--tier2-prompt or CLAUDEOPS_TIER2_PROMPT", "missing.md"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
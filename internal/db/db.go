@@ -3,10 +3,16 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io/fs"
+	"os"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/joestump/claude-ops/internal/seal"
 	"github.com/pressly/goose/v3"
 	_ "modernc.org/sqlite"
 )
@@ -15,37 +21,66 @@ import (
 // Governing: SPEC-0008 REQ-8 — SQLite State Storage (pure-Go driver via modernc.org/sqlite)
 type DB struct {
 	conn *sql.DB
+	// sealer encrypts session responses, summaries, and prompts at rest
+	// (nil stores them in plaintext).
+	sealer *seal.Sealer
 }
 
 // Session represents a Claude CLI session record.
 type Session struct {
-	ID         int64
-	Tier       int
-	Model      string
-	PromptFile string
-	Status     string // running, completed, failed, timed_out, escalated
-	StartedAt  string
-	EndedAt    *string
-	ExitCode   *int
-	LogFile    *string
-	Context    *string // JSON blob
-	Response   *string // final markdown response from Claude
-	CostUSD    *float64
-	NumTurns   *int
-	DurationMs *int64
+	ID              int64
+	Tier            int
+	Model           string
+	PromptFile      string
+	Status          string // running, completed, failed, timed_out, escalated, continued, reopened, interrupted, skipped
+	StartedAt       string
+	EndedAt         *string
+	ExitCode        *int
+	LogFile         *string
+	Context         *string // JSON blob
+	Response        *string // final markdown response from Claude
+	CostUSD         *float64
+	NumTurns        *int
+	DurationMs      *int64
 	Trigger         string  // "scheduled" or "manual"
 	PromptText      *string // custom prompt text for ad-hoc sessions
 	ParentSessionID *int64  // Governing: SPEC-0016 REQ "Database Schema for Escalation Chains" — links to parent session
 	Summary         *string // LLM-generated summary of session response — Governing: SPEC-0021 REQ "Summary Persistence"
+	Invocation      *string // JSON blob: CLI arguments, tools, redacted system prompt, and environment
+	ClientUser      *string // OpenAI "user" field sent by the chat client that triggered the session
+	ClientMetadata  *string // JSON object of string metadata sent by the chat client
+	CostSynthetic   bool    // CostUSD is estimated from token usage, not reported by the CLI
+	MaxContext      *int64  // largest context, in tokens, of any assistant turn
+	Services        *string // comma-separated services the session was scoped to
+	WorkDir         *string // directory the CLI ran in
+	GitSHA          *string // commit checked out in WorkDir when the session started, if it is a git repo
+	RequestID       *string // ID of the dashboard or API request that triggered the session
+	Outcome         *string // one of the Session* outcomes, set when the session ends
+	ChainSummary    *string // summary of the escalation chain this session is the root of
+	SkippedTiers    *string // comma-separated tiers the escalation to this session skipped, e.g. "2"
+	LogObject       *string // object storage key the log was offloaded to; LogFile is then a stub
+	ArtifactsObject *string // object storage key the session's diffs were offloaded to
 }
 
+// Session outcomes: what a finished session found and did.
+const (
+	SessionHealthy           = "healthy"
+	SessionIssuesObserved    = "issues-observed"
+	SessionRemediated        = "remediated"
+	SessionRemediationFailed = "remediation-failed"
+	SessionInconclusive      = "inconclusive"
+)
+
+// SessionOutcomes lists the session outcomes.
+var SessionOutcomes = []string{SessionHealthy, SessionIssuesObserved, SessionRemediated, SessionRemediationFailed, SessionInconclusive}
+
 // HealthCheck represents a parsed health check result.
 // Governing: SPEC-0008 REQ-9 — Health Check History (service, check_type, status, timestamp, response_time, error)
 type HealthCheck struct {
 	ID             int64
 	SessionID      *int64
 	Service        string
-	CheckType      string // http, dns, container, database, service
+	CheckType      string // http, tcp, dns, container, database, service
 	Status         string // healthy, degraded, down
 	ResponseTimeMs *int
 	ErrorDetail    *string
@@ -74,20 +109,173 @@ type Memory struct {
 	UpdatedAt   string
 	SessionID   *int64
 	Tier        int
+	// ReviewStatus is unverified, verified, or rejected. Empty means verified
+	// on insert (operator-created memories need no review).
+	ReviewStatus string
+	// DeletedAt is set when the memory has been soft-deleted.
+	DeletedAt *string
+	// TombstonedAt is set when the memory was deleted or rejected; while recent,
+	// re-learning the same observation is suppressed.
+	TombstonedAt *string
+	// SuppressedCount counts re-learn attempts suppressed by the tombstone.
+	SuppressedCount int
+}
+
+// Memory review statuses.
+const (
+	MemoryUnverified = "unverified"
+	MemoryVerified   = "verified"
+	MemoryRejected   = "rejected"
+)
+
+// ValidReviewStatus reports whether s is a known memory review status.
+func ValidReviewStatus(s string) bool {
+	return s == MemoryUnverified || s == MemoryVerified || s == MemoryRejected
+}
+
+// KBArticle is one version of a per-service runbook article compiled from
+// memories.
+type KBArticle struct {
+	ID          int64
+	Service     string
+	Version     int
+	Content     string
+	MemoryCount int
+	SourceHash  string
+	Model       string
+	CreatedAt   string
+}
+
+// Drill is a self-test run of the escalation pipeline against a synthetic
+// failing canary service.
+type Drill struct {
+	ID         int64
+	Status     string // running, passed, failed
+	SessionID  *int64 // first session of the drill's escalation chain
+	MaxTier    int    // highest tier the chain reached
+	Detected   bool   // Tier 1 escalated the canary
+	Remediated bool   // the agent restarted the canary
+	Notified   bool   // the agent's notification reached the canary's Apprise endpoint
+	Detail     *string
+	StartedAt  string
+	EndedAt    *string
+}
+
+// StreamDiagnostic counts one kind of stream-json event a session's parser
+// dropped, with the first such line as a sample.
+type StreamDiagnostic struct {
+	ID        int64
+	SessionID int64
+	EventType string // the unknown event type, or "(unparseable)"
+	Count     int
+	Sample    string
+	CreatedAt string
+}
+
+// SessionRedaction counts the credential values one redaction rule (the
+// variable a value came from) replaced in a session's output.
+type SessionRedaction struct {
+	ID        int64
+	SessionID int64
+	Rule      string
+	Count     int
+	CreatedAt string
+}
+
+// ParseWarning is text in a session's output that looked like a marker but
+// was not parsed as one.
+type ParseWarning struct {
+	ID        int64
+	SessionID int64
+	Marker    string // event, memory, or cooldown
+	Text      string // the line the marker was found on
+	Reason    string
+	CreatedAt string
+}
+
+// EscalationDecision records what the supervisor decided after a session's
+// tier finished, and the inputs it decided on.
+type EscalationDecision struct {
+	ID            int64
+	SessionID     int64
+	FromTier      int
+	RequestedTier int    // tier the agent asked for, 0 if it did not ask
+	Tier          int    // tier the chain went on to, 0 if it stopped
+	Outcome       string // see the session package's Decision* constants
+	Reason        string
+	Source        string // structured, handoff, or empty when the agent did not ask
+	Services      string // comma-separated services the escalation was for
+	Handoff       string // context handed to the next tier, or the rejected handoff
+	Cooldowns     string // JSON array of the affected services' cooldown budgets
+	ChainCostUSD  float64
+	Cost24hUSD    float64
+	DryRun        bool
+	MaxTier       int
+	CreatedAt     string
+}
+
+// ApprovalRequest is a Tier 3 remediation held until Required distinct
+// operators approve it.
+type ApprovalRequest struct {
+	ID         int64
+	SessionID  int64 // the session that asked to escalate
+	Tier       int
+	Services   string // comma-separated services that need two-person approval
+	Reason     string
+	Chain      string // JSON escalation step to run once approved
+	Required   int
+	Status     string // pending, approved, rejected, expired
+	CreatedAt  string
+	ExpiresAt  string
+	ResolvedAt *string
+	ResolvedBy *string // the approver who completed or rejected the request
+	Approvers  []string
+}
+
+// PolicyEvaluation is the outcome of one policy rule evaluated for a session.
+type PolicyEvaluation struct {
+	ID        int64
+	SessionID int64
+	Point     string // "escalation" or "cooldown"
+	Rule      string
+	Matched   bool
+	Action    string // the rule's action if it matched, "none" if not, "error" if it failed
+	Detail    string
+	CreatedAt string
+}
+
+// PromptHistory is an ad-hoc prompt previously run from the dashboard.
+type PromptHistory struct {
+	ID         int64
+	Prompt     string
+	Favorite   bool
+	UseCount   int
+	LastUsedAt string
 }
 
 // CooldownAction represents a remediation action record.
 type CooldownAction struct {
 	ID         int64
 	Service    string
-	ActionType string // restart, redeployment
+	ActionType string // restart, redeployment, vm_start, vm_stop, vm_reboot
 	Timestamp  string
 	Success    bool
 	Tier       int
 	Error      *string
 	SessionID  *int64
+	// Outcome is OutcomeEffective or OutcomeIneffective once the health
+	// signals after the action have been scored, nil until then.
+	Outcome       *string
+	OutcomeReason *string
+	ScoredAt      *string
 }
 
+// Remediation outcomes scored onto cooldown actions.
+const (
+	OutcomeEffective   = "effective"
+	OutcomeIneffective = "ineffective"
+)
+
 // Open creates a new DB connection and runs all pending migrations.
 // Governing: SPEC-0008 REQ-8 — SQLite State Storage (database init and schema migration on startup)
 // Governing: SPEC-0022 REQ "Goose Provider API Integration"
@@ -134,7 +322,12 @@ func Open(path string) (*DB, error) {
 		return nil, fmt.Errorf("apply migrations: %w", err)
 	}
 
-	return &DB{conn: conn}, nil
+	d := &DB{conn: conn}
+	if err := d.sealPromptHistory(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return d, nil
 }
 
 // Close closes the database connection.
@@ -142,6 +335,21 @@ func (d *DB) Close() error {
 	return d.conn.Close()
 }
 
+// SetSealer turns on at-rest encryption of session responses, summaries,
+// and prompts written from now on. Sealed values are opened when read, and
+// values written in plaintext before are read as they are, except the
+// prompt history, which is sealed at once as it is kept for reuse.
+func (d *DB) SetSealer(s *seal.Sealer) error {
+	d.sealer = s
+	return d.sealPromptHistory()
+}
+
+// Sealer returns the sealer set by SetSealer, for encrypting session logs
+// the same way (nil if encryption is off).
+func (d *DB) Sealer() *seal.Sealer {
+	return d.sealer
+}
+
 // Conn returns the underlying *sql.DB for use by other packages if needed.
 func (d *DB) Conn() *sql.DB {
 	return d.conn
@@ -149,18 +357,26 @@ func (d *DB) Conn() *sql.DB {
 
 // --- Session Methods ---
 
-const sessionColumns = `id, tier, model, prompt_file, status, started_at, ended_at, exit_code, log_file, context, response, cost_usd, num_turns, duration_ms, trigger, prompt_text, parent_session_id, summary`
+const sessionColumns = `id, tier, model, prompt_file, status, started_at, ended_at, exit_code, log_file, context, response, cost_usd, num_turns, duration_ms, trigger, prompt_text, parent_session_id, summary, invocation, client_user, client_metadata, cost_synthetic, max_context_tokens, services, work_dir, git_sha, request_id, outcome, chain_summary, skipped_tiers, log_object, artifacts_object`
 
-func scanSession(scanner interface{ Scan(...any) error }, s *Session) error {
-	return scanner.Scan(&s.ID, &s.Tier, &s.Model, &s.PromptFile, &s.Status, &s.StartedAt, &s.EndedAt, &s.ExitCode, &s.LogFile, &s.Context, &s.Response, &s.CostUSD, &s.NumTurns, &s.DurationMs, &s.Trigger, &s.PromptText, &s.ParentSessionID, &s.Summary)
+func (d *DB) scanSession(scanner interface{ Scan(...any) error }, s *Session) error {
+	if err := scanner.Scan(&s.ID, &s.Tier, &s.Model, &s.PromptFile, &s.Status, &s.StartedAt, &s.EndedAt, &s.ExitCode, &s.LogFile, &s.Context, &s.Response, &s.CostUSD, &s.NumTurns, &s.DurationMs, &s.Trigger, &s.PromptText, &s.ParentSessionID, &s.Summary, &s.Invocation, &s.ClientUser, &s.ClientMetadata, &s.CostSynthetic, &s.MaxContext, &s.Services, &s.WorkDir, &s.GitSHA, &s.RequestID, &s.Outcome, &s.ChainSummary, &s.SkippedTiers, &s.LogObject, &s.ArtifactsObject); err != nil {
+		return err
+	}
+	for _, p := range []*string{s.Response, s.PromptText, s.Summary, s.ChainSummary} {
+		if p != nil {
+			*p = d.sealer.OpenOr(*p)
+		}
+	}
+	return nil
 }
 
 // InsertSession creates a new session record and returns its ID.
 func (d *DB) InsertSession(s *Session) (int64, error) {
 	res, err := d.conn.Exec(
-		`INSERT INTO sessions (tier, model, prompt_file, status, started_at, ended_at, exit_code, log_file, context, trigger, prompt_text, parent_session_id)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		s.Tier, s.Model, s.PromptFile, s.Status, s.StartedAt, s.EndedAt, s.ExitCode, s.LogFile, s.Context, s.Trigger, s.PromptText, s.ParentSessionID,
+		`INSERT INTO sessions (tier, model, prompt_file, status, started_at, ended_at, exit_code, log_file, context, trigger, prompt_text, parent_session_id, work_dir, git_sha, skipped_tiers)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.Tier, s.Model, s.PromptFile, s.Status, s.StartedAt, s.EndedAt, s.ExitCode, s.LogFile, s.Context, s.Trigger, d.sealer.SealPtr(s.PromptText), s.ParentSessionID, s.WorkDir, s.GitSHA, s.SkippedTiers,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("insert session: %w", err)
@@ -196,7 +412,7 @@ func (d *DB) UpdateSessionStatus(id int64, status string) error {
 func (d *DB) UpdateSessionResult(id int64, response string, costUSD float64, numTurns int, durationMs int64) error {
 	_, err := d.conn.Exec(
 		`UPDATE sessions SET response = ?, cost_usd = ?, num_turns = ?, duration_ms = ? WHERE id = ?`,
-		response, costUSD, numTurns, durationMs, id,
+		d.sealer.Seal(response), costUSD, numTurns, durationMs, id,
 	)
 	if err != nil {
 		return fmt.Errorf("update session result %d: %w", id, err)
@@ -204,11 +420,40 @@ func (d *DB) UpdateSessionResult(id int64, response string, costUSD float64, num
 	return nil
 }
 
+// UpdateSessionCostSynthetic records whether a session's cost_usd is an
+// estimate from token usage rather than the cost the CLI reported.
+func (d *DB) UpdateSessionCostSynthetic(id int64, synthetic bool) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET cost_synthetic = ? WHERE id = ?`, synthetic, id)
+	if err != nil {
+		return fmt.Errorf("update session cost synthetic %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateSessionMaxContext stores the largest context, in tokens, the
+// session used.
+func (d *DB) UpdateSessionMaxContext(id int64, tokens int64) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET max_context_tokens = ? WHERE id = ?`, tokens, id)
+	if err != nil {
+		return fmt.Errorf("update session max context %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateSessionServices records the services a session was scoped to.
+func (d *DB) UpdateSessionServices(id int64, services []string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET services = ? WHERE id = ?`, strings.Join(services, ","), id)
+	if err != nil {
+		return fmt.Errorf("update session services %d: %w", id, err)
+	}
+	return nil
+}
+
 // GetSession retrieves a single session by ID.
 func (d *DB) GetSession(id int64) (*Session, error) {
 	s := &Session{}
 	row := d.conn.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, id)
-	if err := scanSession(row, s); err == sql.ErrNoRows {
+	if err := d.scanSession(row, s); err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
 		return nil, fmt.Errorf("get session %d: %w", id, err)
@@ -229,7 +474,7 @@ func (d *DB) ListSessions(limit, offset int) ([]Session, error) {
 	var sessions []Session
 	for rows.Next() {
 		var s Session
-		if err := scanSession(rows, &s); err != nil {
+		if err := d.scanSession(rows, &s); err != nil {
 			return nil, fmt.Errorf("scan session: %w", err)
 		}
 		sessions = append(sessions, s)
@@ -237,438 +482,4096 @@ func (d *DB) ListSessions(limit, offset int) ([]Session, error) {
 	return sessions, rows.Err()
 }
 
-// --- Health Check Methods ---
-// Governing: SPEC-0008 REQ-9 — Health Check History (store and query health check results)
-
-// InsertHealthCheck stores a health check result.
-func (d *DB) InsertHealthCheck(h *HealthCheck) (int64, error) {
-	res, err := d.conn.Exec(
-		`INSERT INTO health_checks (session_id, service, check_type, status, response_time_ms, error_detail, checked_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		h.SessionID, h.Service, h.CheckType, h.Status, h.ResponseTimeMs, h.ErrorDetail, h.CheckedAt,
-	)
-	if err != nil {
-		return 0, fmt.Errorf("insert health check: %w", err)
-	}
-	return res.LastInsertId()
-}
-
-// QueryHealthChecks returns health checks for a service within a time range,
-// ordered by checked_at descending.
-func (d *DB) QueryHealthChecks(service string, since, until string, limit int) ([]HealthCheck, error) {
+// ListSessionsByRequestID returns the sessions triggered by request
+// requestID, newest first.
+func (d *DB) ListSessionsByRequestID(requestID string) ([]Session, error) {
 	rows, err := d.conn.Query(
-		`SELECT id, session_id, service, check_type, status, response_time_ms, error_detail, checked_at
-		 FROM health_checks
-		 WHERE service = ? AND checked_at >= ? AND checked_at <= ?
-		 ORDER BY checked_at DESC LIMIT ?`,
-		service, since, until, limit,
+		`SELECT `+sessionColumns+` FROM sessions WHERE request_id = ? ORDER BY started_at DESC, id DESC`, requestID,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("query health checks: %w", err)
+		return nil, fmt.Errorf("list sessions by request: %w", err)
 	}
 	defer rows.Close() //nolint:errcheck
 
-	var checks []HealthCheck
+	var sessions []Session
 	for rows.Next() {
-		var h HealthCheck
-		if err := rows.Scan(&h.ID, &h.SessionID, &h.Service, &h.CheckType, &h.Status, &h.ResponseTimeMs, &h.ErrorDetail, &h.CheckedAt); err != nil {
-			return nil, fmt.Errorf("scan health check: %w", err)
+		var s Session
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
 		}
-		checks = append(checks, h)
+		sessions = append(sessions, s)
 	}
-	return checks, rows.Err()
+	return sessions, rows.Err()
 }
 
-// --- Event Methods ---
+// SessionFilter narrows FindSessions and CountSessions. Nil and empty
+// fields match everything. Since and Until are RFC3339 timestamps bounding
+// started_at (since inclusive, until exclusive).
+type SessionFilter struct {
+	Tier    *int
+	Status  *string
+	Trigger *string
+	Outcome *string
+	// RequestID matches the sessions triggered by that request.
+	RequestID *string
+	Since     string
+	Until     string
+	// MinCost bounds cost_usd, inclusive. Sessions without a cost do not match.
+	MinCost *float64
+}
 
-// InsertEvent stores an event record.
-func (d *DB) InsertEvent(e *Event) (int64, error) {
-	res, err := d.conn.Exec(
-		`INSERT INTO events (session_id, level, service, message, created_at)
-		 VALUES (?, ?, ?, ?, ?)`,
-		e.SessionID, e.Level, e.Service, e.Message, e.CreatedAt,
-	)
-	if err != nil {
-		return 0, fmt.Errorf("insert event: %w", err)
+func (f SessionFilter) where() (string, []any) {
+	var sb strings.Builder
+	var args []any
+	if f.Tier != nil {
+		sb.WriteString(` AND tier = ?`)
+		args = append(args, *f.Tier)
 	}
-	return res.LastInsertId()
+	if f.Status != nil {
+		sb.WriteString(` AND status = ?`)
+		args = append(args, *f.Status)
+	}
+	if f.Trigger != nil {
+		sb.WriteString(` AND trigger = ?`)
+		args = append(args, *f.Trigger)
+	}
+	if f.Outcome != nil {
+		sb.WriteString(` AND outcome = ?`)
+		args = append(args, *f.Outcome)
+	}
+	if f.RequestID != nil {
+		sb.WriteString(` AND request_id = ?`)
+		args = append(args, *f.RequestID)
+	}
+	if f.Since != "" {
+		sb.WriteString(` AND started_at >= ?`)
+		args = append(args, f.Since)
+	}
+	if f.Until != "" {
+		sb.WriteString(` AND started_at < ?`)
+		args = append(args, f.Until)
+	}
+	if f.MinCost != nil {
+		sb.WriteString(` AND cost_usd >= ?`)
+		args = append(args, *f.MinCost)
+	}
+	return sb.String(), args
 }
 
-// ListEvents returns events ordered by created_at descending, with a limit, offset,
-// and optional level/service filters.
-func (d *DB) ListEvents(limit, offset int, level, service *string) ([]Event, error) {
-	query := `SELECT id, session_id, level, service, message, created_at FROM events WHERE 1=1`
-	var args []any
-	if level != nil {
-		query += ` AND level = ?`
-		args = append(args, *level)
+// Orders FindSessions can sort by.
+const (
+	SessionSortStarted  = "started_at"
+	SessionSortCost     = "cost"
+	SessionSortDuration = "duration"
+)
+
+// sessionSortExprs maps each sort order to the expression it sorts by. A
+// session's duration is the one its result reported, or the time between
+// its start and end when it has none (failed and timed-out sessions).
+var sessionSortExprs = map[string]string{
+	SessionSortStarted:  `started_at`,
+	SessionSortCost:     `cost_usd`,
+	SessionSortDuration: `COALESCE(duration_ms, (julianday(ended_at) - julianday(started_at)) * 86400000)`,
+}
+
+// FindSessions returns the sessions matching filter ordered by sort (one of
+// the SessionSort orders, started_at when empty), descending unless asc.
+// Sessions without a value to sort by, such as running sessions when
+// sorting by cost, come last either way.
+func (d *DB) FindSessions(filter SessionFilter, sort string, asc bool, limit, offset int) ([]Session, error) {
+	if sort == "" {
+		sort = SessionSortStarted
 	}
-	if service != nil {
-		query += ` AND service = ?`
-		args = append(args, *service)
+	expr, ok := sessionSortExprs[sort]
+	if !ok {
+		return nil, fmt.Errorf("find sessions: unknown sort %q", sort)
 	}
-	query += ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	dir := ` DESC`
+	if asc {
+		dir = ` ASC`
+	}
+	where, args := filter.where()
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE 1=1` + where +
+		` ORDER BY (` + expr + `) IS NULL, ` + expr + dir + `, id` + dir + ` LIMIT ? OFFSET ?`
 	args = append(args, limit, offset)
 
 	rows, err := d.conn.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("list events: %w", err)
+		return nil, fmt.Errorf("find sessions: %w", err)
 	}
 	defer rows.Close() //nolint:errcheck
 
-	var events []Event
+	var sessions []Session
 	for rows.Next() {
-		var e Event
-		if err := rows.Scan(&e.ID, &e.SessionID, &e.Level, &e.Service, &e.Message, &e.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scan event: %w", err)
+		var s Session
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
 		}
-		events = append(events, e)
+		sessions = append(sessions, s)
 	}
-	return events, rows.Err()
+	return sessions, rows.Err()
 }
 
-// --- Cooldown Methods ---
-// Governing: SPEC-0008 REQ-8 — SQLite State Storage (cooldown enforcement via SQLite replaces cooldown.json)
+// CountSessions returns how many sessions match filter.
+func (d *DB) CountSessions(filter SessionFilter) (int, error) {
+	where, args := filter.where()
+	var n int
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM sessions WHERE 1=1`+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count sessions: %w", err)
+	}
+	return n, nil
+}
 
-// CheckCooldown returns the count of actions of the given type for a service
-// within the specified window.
-func (d *DB) CheckCooldown(service, actionType string, window time.Duration) (int, error) {
-	since := time.Now().UTC().Add(-window).Format(time.RFC3339)
-	var count int
-	err := d.conn.QueryRow(
-		`SELECT COUNT(*) FROM cooldown_actions
-		 WHERE service = ? AND action_type = ? AND timestamp > ?`,
-		service, actionType, since,
-	).Scan(&count)
+// UpdateSessionOutcome stores a finished session's outcome.
+func (d *DB) UpdateSessionOutcome(id int64, outcome string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET outcome = ? WHERE id = ?`, outcome, id)
 	if err != nil {
-		return 0, fmt.Errorf("check cooldown: %w", err)
+		return fmt.Errorf("update session outcome %d: %w", id, err)
 	}
-	return count, nil
+	return nil
 }
 
-// InsertCooldownAction records a remediation action.
-func (d *DB) InsertCooldownAction(a *CooldownAction) (int64, error) {
-	res, err := d.conn.Exec(
-		`INSERT INTO cooldown_actions (service, action_type, timestamp, success, tier, error, session_id)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		a.Service, a.ActionType, a.Timestamp, boolToInt(a.Success), a.Tier, a.Error, a.SessionID,
+// ListSessionsBetween returns sessions started in [since, until), newest
+// first. Both bounds are RFC3339 timestamps.
+func (d *DB) ListSessionsBetween(since, until string) ([]Session, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+sessionColumns+` FROM sessions WHERE started_at >= ? AND started_at < ? ORDER BY started_at DESC, id DESC`,
+		since, until,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert cooldown action: %w", err)
+		return nil, fmt.Errorf("list sessions between: %w", err)
 	}
-	return res.LastInsertId()
+	defer rows.Close() //nolint:errcheck
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
 }
 
-// --- Health Streak Methods ---
+// DailyActivity aggregates one UTC day of sessions and events.
+type DailyActivity struct {
+	Day      string // YYYY-MM-DD
+	Sessions int
+	CostUSD  float64
+	MaxLevel string // most severe event level that day: critical, warning, info, or "" when there were no events
+}
 
-// GetHealthStreak returns the consecutive healthy count for a service.
-func (d *DB) GetHealthStreak(service string) (int, error) {
-	var count int
-	err := d.conn.QueryRow(
-		`SELECT consecutive_healthy FROM service_health_streak WHERE service = ?`, service,
-	).Scan(&count)
-	if err == sql.ErrNoRows {
-		return 0, nil
+// GetDailyActivity returns per-day session counts, costs, and the most severe
+// event level for days in [since, until), oldest first. Days with neither
+// sessions nor events are omitted. Both bounds are RFC3339 timestamps.
+func (d *DB) GetDailyActivity(since, until string) ([]DailyActivity, error) {
+	days := make(map[string]*DailyActivity)
+	day := func(key string) *DailyActivity {
+		if a, ok := days[key]; ok {
+			return a
+		}
+		a := &DailyActivity{Day: key}
+		days[key] = a
+		return a
 	}
+
+	rows, err := d.conn.Query(
+		`SELECT substr(started_at, 1, 10) AS day, COUNT(*), COALESCE(SUM(cost_usd), 0)
+		 FROM sessions WHERE started_at >= ? AND started_at < ?
+		 GROUP BY day`, since, until)
 	if err != nil {
-		return 0, fmt.Errorf("get health streak: %w", err)
+		return nil, fmt.Errorf("daily session activity: %w", err)
+	}
+	for rows.Next() {
+		var key string
+		var n int
+		var cost float64
+		if err := rows.Scan(&key, &n, &cost); err != nil {
+			rows.Close() //nolint:errcheck
+			return nil, fmt.Errorf("scan daily session activity: %w", err)
+		}
+		a := day(key)
+		a.Sessions, a.CostUSD = n, cost
+	}
+	rows.Close() //nolint:errcheck
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("daily session activity: %w", err)
 	}
-	return count, nil
-}
 
-// SetHealthStreak upserts the consecutive healthy count for a service.
-func (d *DB) SetHealthStreak(service string, count int) error {
-	_, err := d.conn.Exec(
-		`INSERT INTO service_health_streak (service, consecutive_healthy, last_checked_at)
-		 VALUES (?, ?, datetime('now'))
-		 ON CONFLICT(service) DO UPDATE SET consecutive_healthy = ?, last_checked_at = datetime('now')`,
-		service, count, count,
-	)
+	rows, err = d.conn.Query(
+		`SELECT substr(created_at, 1, 10) AS day,
+		        MAX(CASE level WHEN 'critical' THEN 3 WHEN 'warning' THEN 2 ELSE 1 END)
+		 FROM events WHERE created_at >= ? AND created_at < ?
+		 GROUP BY day`, since, until)
 	if err != nil {
-		return fmt.Errorf("set health streak: %w", err)
+		return nil, fmt.Errorf("daily event activity: %w", err)
 	}
-	return nil
+	defer rows.Close() //nolint:errcheck
+	for rows.Next() {
+		var key string
+		var severity int
+		if err := rows.Scan(&key, &severity); err != nil {
+			return nil, fmt.Errorf("scan daily event activity: %w", err)
+		}
+		day(key).MaxLevel = [...]string{"", "info", "warning", "critical"}[severity]
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("daily event activity: %w", err)
+	}
+
+	out := make([]DailyActivity, 0, len(days))
+	for _, a := range days {
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Day < out[j].Day })
+	return out, nil
 }
 
-// --- Config Methods ---
+// SessionCostStats summarizes recent finished sessions for ad-hoc run estimates.
+type SessionCostStats struct {
+	Samples        int
+	AvgCostUSD     float64
+	AvgDurationMs  int64
+	AvgPromptChars float64 // mean length of custom prompts among the samples (0 if none)
+}
 
-// GetConfig returns the value for a configuration key, or the fallback if not set.
-func (d *DB) GetConfig(key, fallback string) (string, error) {
-	var value string
-	err := d.conn.QueryRow(`SELECT value FROM config WHERE key = ?`, key).Scan(&value)
-	if err == sql.ErrNoRows {
-		return fallback, nil
+// GetSessionCostStats averages cost and duration over the most recent limit
+// finished sessions with a recorded cost for tier. An empty model matches any
+// model.
+func (d *DB) GetSessionCostStats(tier int, model string, limit int) (*SessionCostStats, error) {
+	query := `SELECT cost_usd, duration_ms, prompt_text FROM sessions
+		 WHERE tier = ? AND cost_usd IS NOT NULL AND ended_at IS NOT NULL`
+	args := []any{tier}
+	if model != "" {
+		query += ` AND model = ?`
+		args = append(args, model)
 	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := d.conn.Query(query, args...)
 	if err != nil {
-		return "", fmt.Errorf("get config %q: %w", key, err)
+		return nil, fmt.Errorf("session cost stats: %w", err)
 	}
-	return value, nil
+	defer rows.Close() //nolint:errcheck
+
+	// Prompt lengths are measured here rather than in SQL because prompts
+	// may be stored encrypted.
+	var st SessionCostStats
+	var totalCost, totalDuration, totalChars float64
+	var durations, prompts int
+	for rows.Next() {
+		var cost float64
+		var duration sql.NullInt64
+		var prompt sql.NullString
+		if err := rows.Scan(&cost, &duration, &prompt); err != nil {
+			return nil, fmt.Errorf("scan session cost stats: %w", err)
+		}
+		st.Samples++
+		totalCost += cost
+		if duration.Valid {
+			totalDuration += float64(duration.Int64)
+			durations++
+		}
+		if prompt.Valid {
+			totalChars += float64(utf8.RuneCountInString(d.sealer.OpenOr(prompt.String)))
+			prompts++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("session cost stats: %w", err)
+	}
+	if st.Samples > 0 {
+		st.AvgCostUSD = totalCost / float64(st.Samples)
+	}
+	if durations > 0 {
+		st.AvgDurationMs = int64(totalDuration / float64(durations))
+	}
+	if prompts > 0 {
+		st.AvgPromptChars = totalChars / float64(prompts)
+	}
+	return &st, nil
 }
 
-// SetConfig upserts a configuration key-value pair.
-func (d *DB) SetConfig(key, value string) error {
-	_, err := d.conn.Exec(
-		`INSERT INTO config (key, value, updated_at) VALUES (?, ?, datetime('now'))
-		 ON CONFLICT(key) DO UPDATE SET value = ?, updated_at = datetime('now')`,
-		key, value, value,
-	)
+// SessionCostSince sums the cost of sessions started at or after since
+// (RFC3339).
+func (d *DB) SessionCostSince(since string) (float64, error) {
+	var total float64
+	err := d.conn.QueryRow(
+		`SELECT COALESCE(SUM(cost_usd), 0) FROM sessions WHERE started_at >= ?`, since,
+	).Scan(&total)
 	if err != nil {
-		return fmt.Errorf("set config %q: %w", key, err)
+		return 0, fmt.Errorf("session cost since %s: %w", since, err)
 	}
-	return nil
+	return total, nil
 }
 
-// ServiceStatus summarizes the current state of a service from recent health checks.
-type ServiceStatus struct {
-	Service    string
-	Status     string
-	LastCheck  *string
+// PeriodStats summarises agent behaviour over a time range.
+type PeriodStats struct {
+	Since, Until          string         // RFC3339; Since inclusive, Until exclusive
+	Sessions              int            // sessions started, excluding drills
+	Escalations           int            // sessions started by an escalation, excluding drills
+	EscalationsByService  map[string]int // escalation sessions per service they were scoped to
+	MeanCostUSD           float64        // over sessions with a recorded cost
+	MeanDurationMs        int64          // over sessions with a recorded duration
+	NewMemories           int
+	DecayedMemories       int
+	Remediations          int // remediation attempts recorded in cooldown_actions
+	RemediationsSucceeded int
+	Corrections           int // thumbs down given on sessions
+}
+
+// RemediationSuccessRate returns the fraction of remediations that
+// succeeded, or -1 when none were attempted.
+func (p *PeriodStats) RemediationSuccessRate() float64 {
+	if p.Remediations == 0 {
+		return -1
+	}
+	return float64(p.RemediationsSucceeded) / float64(p.Remediations)
+}
+
+// GetPeriodStats aggregates sessions, escalations, memories, remediations,
+// and operator corrections between since (inclusive) and until (exclusive), both RFC3339. Drill
+// sessions are excluded so canary exercises do not read as incidents.
+func (d *DB) GetPeriodStats(since, until string) (*PeriodStats, error) {
+	p := &PeriodStats{Since: since, Until: until, EscalationsByService: make(map[string]int)}
+	var avgDuration float64
+	err := d.conn.QueryRow(
+		`SELECT COUNT(*), COALESCE(SUM(CASE WHEN trigger = 'escalation' THEN 1 ELSE 0 END), 0),
+		        COALESCE(AVG(cost_usd), 0), COALESCE(AVG(duration_ms), 0)
+		 FROM sessions WHERE started_at >= ? AND started_at < ? AND COALESCE(trigger, '') != 'drill'`,
+		since, until,
+	).Scan(&p.Sessions, &p.Escalations, &p.MeanCostUSD, &avgDuration)
+	if err != nil {
+		return nil, fmt.Errorf("period session stats: %w", err)
+	}
+	p.MeanDurationMs = int64(avgDuration)
+
+	rows, err := d.conn.Query(
+		`SELECT services FROM sessions
+		 WHERE started_at >= ? AND started_at < ? AND trigger = 'escalation' AND services IS NOT NULL AND services != ''`,
+		since, until,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("period escalations: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+	for rows.Next() {
+		var services string
+		if err := rows.Scan(&services); err != nil {
+			return nil, fmt.Errorf("scan escalation services: %w", err)
+		}
+		for _, svc := range strings.Split(services, ",") {
+			p.EscalationsByService[svc]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("period escalations: %w", err)
+	}
+
+	err = d.conn.QueryRow(
+		`SELECT (SELECT COUNT(*) FROM memories WHERE created_at >= ? AND created_at < ?),
+		        (SELECT COUNT(*) FROM memories WHERE deactivated_at >= ? AND deactivated_at < ?)`,
+		since, until, since, until,
+	).Scan(&p.NewMemories, &p.DecayedMemories)
+	if err != nil {
+		return nil, fmt.Errorf("period memory stats: %w", err)
+	}
+
+	err = d.conn.QueryRow(
+		`SELECT COUNT(*), COALESCE(SUM(success), 0) FROM cooldown_actions WHERE timestamp >= ? AND timestamp < ?`,
+		since, until,
+	).Scan(&p.Remediations, &p.RemediationsSucceeded)
+	if err != nil {
+		return nil, fmt.Errorf("period remediation stats: %w", err)
+	}
+
+	err = d.conn.QueryRow(
+		`SELECT COUNT(*) FROM session_feedback WHERE rating < 0 AND created_at >= ? AND created_at < ?`,
+		since, until,
+	).Scan(&p.Corrections)
+	if err != nil {
+		return nil, fmt.Errorf("period correction stats: %w", err)
+	}
+	return p, nil
+}
+
+// --- Health Check Methods ---
+// Governing: SPEC-0008 REQ-9 — Health Check History (store and query health check results)
+
+// InsertHealthCheck stores a health check result.
+func (d *DB) InsertHealthCheck(h *HealthCheck) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO health_checks (session_id, service, check_type, status, response_time_ms, error_detail, checked_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		h.SessionID, h.Service, h.CheckType, h.Status, h.ResponseTimeMs, h.ErrorDetail, h.CheckedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert health check: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// QueryHealthChecks returns health checks for a service within a time range,
+// ordered by checked_at descending.
+func (d *DB) QueryHealthChecks(service string, since, until string, limit int) ([]HealthCheck, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, session_id, service, check_type, status, response_time_ms, error_detail, checked_at
+		 FROM health_checks
+		 WHERE service`+serviceMatch+` AND checked_at >= ? AND checked_at <= ?
+		 ORDER BY checked_at DESC LIMIT ?`,
+		service, service, since, until, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query health checks: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var checks []HealthCheck
+	for rows.Next() {
+		var h HealthCheck
+		if err := rows.Scan(&h.ID, &h.SessionID, &h.Service, &h.CheckType, &h.Status, &h.ResponseTimeMs, &h.ErrorDetail, &h.CheckedAt); err != nil {
+			return nil, fmt.Errorf("scan health check: %w", err)
+		}
+		checks = append(checks, h)
+	}
+	return checks, rows.Err()
+}
+
+// FirstHealthCheckAfter returns the earliest health check of service after
+// the given time and no later than until, skipping checks recorded by
+// excludeSession (which may be nil). Returns nil if there is none.
+func (d *DB) FirstHealthCheckAfter(service, after, until string, excludeSession *int64) (*HealthCheck, error) {
+	var h HealthCheck
+	err := d.conn.QueryRow(
+		`SELECT id, session_id, service, check_type, status, response_time_ms, error_detail, checked_at
+		 FROM health_checks
+		 WHERE service`+serviceMatch+` AND checked_at > ? AND checked_at <= ?
+		   AND (? IS NULL OR session_id IS NULL OR session_id != ?)
+		 ORDER BY checked_at, id LIMIT 1`,
+		service, service, after, until, excludeSession, excludeSession,
+	).Scan(&h.ID, &h.SessionID, &h.Service, &h.CheckType, &h.Status, &h.ResponseTimeMs, &h.ErrorDetail, &h.CheckedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("first health check after %s: %w", after, err)
+	}
+	return &h, nil
+}
+
+// ScheduledCoverage returns the services checked by the latest finished,
+// unscoped scheduled Tier 1 session, which are what a scheduled run is
+// expected to cover, and that session's ID. It returns nil and 0 when no
+// such session has reported service checks.
+func (d *DB) ScheduledCoverage() ([]string, int64, error) {
+	var id int64
+	err := d.conn.QueryRow(
+		`SELECT s.id FROM sessions s
+		 WHERE s.trigger = 'scheduled' AND s.tier = 1 AND s.services IS NULL AND s.status IN ('completed', 'escalated')
+		   AND EXISTS (SELECT 1 FROM health_checks h WHERE h.session_id = s.id AND h.check_type = 'service')
+		 ORDER BY s.started_at DESC, s.id DESC LIMIT 1`,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("scheduled coverage: %w", err)
+	}
+	rows, err := d.conn.Query(
+		`SELECT DISTINCT service FROM health_checks WHERE session_id = ? AND check_type = 'service' ORDER BY service`, id,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("scheduled coverage: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var services []string
+	for rows.Next() {
+		var svc string
+		if err := rows.Scan(&svc); err != nil {
+			return nil, 0, fmt.Errorf("scan scheduled coverage: %w", err)
+		}
+		services = append(services, svc)
+	}
+	return services, id, rows.Err()
+}
+
+// FreshServiceChecks returns, keyed by service, the service checks made by
+// finished sessions since the given time (RFC3339) that are the latest such
+// check of their service and found it healthy. A service whose latest check
+// found a problem is left out, as is one with a newer unhealthy check of any
+// type, such as a pulse probe that found it down.
+func (d *DB) FreshServiceChecks(since string) (map[string]HealthCheck, error) {
+	rows, err := d.conn.Query(
+		`SELECT h.id, h.session_id, h.service, h.check_type, h.status, h.response_time_ms, h.error_detail, h.checked_at
+		 FROM health_checks h LEFT JOIN sessions s ON s.id = h.session_id
+		 WHERE h.checked_at >= ?
+		   AND (h.status != 'healthy' OR (h.check_type = 'service' AND s.status IN ('completed', 'escalated')))
+		 ORDER BY h.checked_at DESC, h.id DESC`,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fresh service checks: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	seen := make(map[string]bool)
+	fresh := make(map[string]HealthCheck)
+	for rows.Next() {
+		var h HealthCheck
+		if err := rows.Scan(&h.ID, &h.SessionID, &h.Service, &h.CheckType, &h.Status, &h.ResponseTimeMs, &h.ErrorDetail, &h.CheckedAt); err != nil {
+			return nil, fmt.Errorf("scan health check: %w", err)
+		}
+		if seen[h.Service] {
+			continue
+		}
+		seen[h.Service] = true
+		if h.Status == "healthy" && h.CheckType == "service" {
+			fresh[h.Service] = h
+		}
+	}
+	return fresh, rows.Err()
+}
+
+// --- Event Methods ---
+
+// InsertEvent stores an event record.
+func (d *DB) InsertEvent(e *Event) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO events (session_id, level, service, message, created_at)
+		 VALUES (?, ?, ?, ?, ?)`,
+		e.SessionID, e.Level, e.Service, e.Message, e.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert event: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// EventFilter narrows ListEvents and CountEventsByLevel. Nil and empty
+// fields match everything. Since and Until are RFC3339 timestamps bounding
+// created_at (since inclusive, until exclusive).
+type EventFilter struct {
+	Level   *string
+	Service *string
+	Since   string
+	Until   string
+}
+
+// where returns the filter's SQL conditions (each prefixed with AND) and
+// their arguments. The level condition is omitted when withLevel is false.
+func (f EventFilter) where(withLevel bool) (string, []any) {
+	var sb strings.Builder
+	var args []any
+	if withLevel && f.Level != nil {
+		sb.WriteString(` AND level = ?`)
+		args = append(args, *f.Level)
+	}
+	if f.Service != nil {
+		sb.WriteString(` AND service` + serviceMatch)
+		args = append(args, *f.Service, *f.Service)
+	}
+	if f.Since != "" {
+		sb.WriteString(` AND created_at >= ?`)
+		args = append(args, f.Since)
+	}
+	if f.Until != "" {
+		sb.WriteString(` AND created_at < ?`)
+		args = append(args, f.Until)
+	}
+	return sb.String(), args
+}
+
+// ListEvents returns events ordered by created_at descending, with a limit,
+// offset, and optional filters.
+func (d *DB) ListEvents(limit, offset int, filter EventFilter) ([]Event, error) {
+	where, args := filter.where(true)
+	query := `SELECT id, session_id, level, service, message, created_at FROM events WHERE 1=1` + where +
+		` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Level, &e.Service, &e.Message, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// FirstEventAfter returns the earliest event of the given level for service
+// after the given time and no later than until, skipping events recorded by
+// excludeSession (which may be nil). Returns nil if there is none.
+func (d *DB) FirstEventAfter(service, level, after, until string, excludeSession *int64) (*Event, error) {
+	var e Event
+	err := d.conn.QueryRow(
+		`SELECT id, session_id, level, service, message, created_at
+		 FROM events
+		 WHERE service`+serviceMatch+` AND level = ? AND created_at > ? AND created_at <= ?
+		   AND (? IS NULL OR session_id IS NULL OR session_id != ?)
+		 ORDER BY created_at, id LIMIT 1`,
+		service, service, level, after, until, excludeSession, excludeSession,
+	).Scan(&e.ID, &e.SessionID, &e.Level, &e.Service, &e.Message, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("first %s event after %s: %w", level, after, err)
+	}
+	return &e, nil
+}
+
+// LatestEventID returns the highest event ID, or 0 when there are none.
+func (d *DB) LatestEventID() (int64, error) {
+	var id int64
+	if err := d.conn.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM events`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("latest event id: %w", err)
+	}
+	return id, nil
+}
+
+// ListEventsAfter returns up to limit events of the given level with IDs
+// above afterID, oldest first.
+func (d *DB) ListEventsAfter(afterID int64, level string, limit int) ([]Event, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, session_id, level, service, message, created_at FROM events
+		 WHERE id > ? AND level = ? ORDER BY id LIMIT ?`, afterID, level, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list events after: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Level, &e.Service, &e.Message, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// ListEventsAfterLevels returns up to limit events at any of levels with
+// IDs above afterID, oldest first.
+func (d *DB) ListEventsAfterLevels(afterID int64, levels []string, limit int) ([]Event, error) {
+	if len(levels) == 0 {
+		return nil, nil
+	}
+	args := []any{afterID}
+	for _, l := range levels {
+		args = append(args, l)
+	}
+	args = append(args, limit)
+	rows, err := d.conn.Query(
+		`SELECT id, session_id, level, service, message, created_at FROM events
+		 WHERE id > ? AND level IN (?`+strings.Repeat(", ?", len(levels)-1)+`) ORDER BY id LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list events after: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Level, &e.Service, &e.Message, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// CountEventsByLevel returns the number of events per level matching filter.
+// The filter's level is ignored so every level is counted.
+func (d *DB) CountEventsByLevel(filter EventFilter) (map[string]int, error) {
+	where, args := filter.where(false)
+	rows, err := d.conn.Query(`SELECT level, COUNT(*) FROM events WHERE 1=1`+where+` GROUP BY level`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count events by level: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var level string
+		var n int
+		if err := rows.Scan(&level, &n); err != nil {
+			return nil, fmt.Errorf("scan event count: %w", err)
+		}
+		counts[level] = n
+	}
+	return counts, rows.Err()
+}
+
+// ListEventServices returns the distinct services that have events, sorted.
+func (d *DB) ListEventServices() ([]string, error) {
+	rows, err := d.conn.Query(`SELECT DISTINCT service FROM events WHERE service IS NOT NULL AND service != '' ORDER BY service`)
+	if err != nil {
+		return nil, fmt.Errorf("list event services: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var services []string
+	for rows.Next() {
+		var svc string
+		if err := rows.Scan(&svc); err != nil {
+			return nil, fmt.Errorf("scan event service: %w", err)
+		}
+		services = append(services, svc)
+	}
+	return services, rows.Err()
+}
+
+// --- Cooldown Methods ---
+// Governing: SPEC-0008 REQ-8 — SQLite State Storage (cooldown enforcement via SQLite replaces cooldown.json)
+
+// CheckCooldown returns the count of actions of the given type for a service
+// within the specified window.
+func (d *DB) CheckCooldown(service, actionType string, window time.Duration) (int, error) {
+	since := time.Now().UTC().Add(-window).Format(time.RFC3339)
+	var count int
+	err := d.conn.QueryRow(
+		`SELECT COUNT(*) FROM cooldown_actions
+		 WHERE service`+serviceMatch+` AND action_type = ? AND timestamp > ?`,
+		service, service, actionType, since,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("check cooldown: %w", err)
+	}
+	return count, nil
+}
+
+// InsertCooldownAction records a remediation action.
+func (d *DB) InsertCooldownAction(a *CooldownAction) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO cooldown_actions (service, action_type, timestamp, success, tier, error, session_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.Service, a.ActionType, a.Timestamp, boolToInt(a.Success), a.Tier, a.Error, a.SessionID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert cooldown action: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+const cooldownColumns = `id, service, action_type, timestamp, success, tier, error, session_id, outcome, outcome_reason, scored_at`
+
+func scanCooldownAction(scanner interface{ Scan(...any) error }, a *CooldownAction) error {
+	return scanner.Scan(&a.ID, &a.Service, &a.ActionType, &a.Timestamp, &a.Success, &a.Tier, &a.Error, &a.SessionID, &a.Outcome, &a.OutcomeReason, &a.ScoredAt)
+}
+
+func (d *DB) queryCooldownActions(query string, args ...any) ([]CooldownAction, error) {
+	rows, err := d.conn.Query(`SELECT `+cooldownColumns+` FROM cooldown_actions `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var actions []CooldownAction
+	for rows.Next() {
+		var a CooldownAction
+		if err := scanCooldownAction(rows, &a); err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+// ListUnscoredCooldownActions returns up to limit actions with no outcome
+// yet, oldest first.
+func (d *DB) ListUnscoredCooldownActions(limit int) ([]CooldownAction, error) {
+	actions, err := d.queryCooldownActions(`WHERE outcome IS NULL ORDER BY timestamp, id LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list unscored cooldown actions: %w", err)
+	}
+	return actions, nil
+}
+
+// ListScoredCooldownActions returns the limit most recently scored actions.
+func (d *DB) ListScoredCooldownActions(limit int) ([]CooldownAction, error) {
+	actions, err := d.queryCooldownActions(`WHERE outcome IS NOT NULL ORDER BY timestamp DESC, id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list scored cooldown actions: %w", err)
+	}
+	return actions, nil
+}
+
+// ScoreCooldownAction records whether an action worked. An action is scored
+// once; later calls leave it unchanged.
+func (d *DB) ScoreCooldownAction(id int64, outcome, reason, at string) error {
+	_, err := d.conn.Exec(
+		`UPDATE cooldown_actions SET outcome = ?, outcome_reason = ?, scored_at = ? WHERE id = ? AND outcome IS NULL`,
+		outcome, reason, at, id,
+	)
+	if err != nil {
+		return fmt.Errorf("score cooldown action %d: %w", id, err)
+	}
+	return nil
+}
+
+// NextCooldownAction returns the first action on the service after the
+// given action and no later than until, or nil if there is none.
+func (d *DB) NextCooldownAction(a *CooldownAction, until string) (*CooldownAction, error) {
+	actions, err := d.queryCooldownActions(
+		`WHERE service`+serviceMatch+` AND id != ? AND timestamp >= ? AND timestamp <= ? AND (timestamp > ? OR id > ?)
+		 ORDER BY timestamp, id LIMIT 1`,
+		a.Service, a.Service, a.ID, a.Timestamp, until, a.Timestamp, a.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("next cooldown action after %d: %w", a.ID, err)
+	}
+	if len(actions) == 0 {
+		return nil, nil
+	}
+	return &actions[0], nil
+}
+
+// RemediationScore aggregates the scored outcomes of one service's actions
+// of one type.
+type RemediationScore struct {
+	Service     string
+	ActionType  string
+	Attempts    int
+	Effective   int
+	Ineffective int
+	Pending     int // not yet scored
+	LastAction  string
+}
+
+// SuccessRate returns the fraction of scored attempts that were effective,
+// or -1 when none have been scored.
+func (r *RemediationScore) SuccessRate() float64 {
+	scored := r.Effective + r.Ineffective
+	if scored == 0 {
+		return -1
+	}
+	return float64(r.Effective) / float64(scored)
+}
+
+// ListRemediationScores aggregates cooldown action outcomes per service and
+// action type.
+func (d *DB) ListRemediationScores() ([]RemediationScore, error) {
+	rows, err := d.conn.Query(`
+		SELECT service, action_type, COUNT(*),
+		       COALESCE(SUM(outcome = 'effective'), 0),
+		       COALESCE(SUM(outcome = 'ineffective'), 0),
+		       COALESCE(SUM(outcome IS NULL), 0),
+		       MAX(timestamp)
+		FROM cooldown_actions
+		GROUP BY service, action_type
+		ORDER BY service, action_type`)
+	if err != nil {
+		return nil, fmt.Errorf("list remediation scores: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var scores []RemediationScore
+	for rows.Next() {
+		var r RemediationScore
+		if err := rows.Scan(&r.Service, &r.ActionType, &r.Attempts, &r.Effective, &r.Ineffective, &r.Pending, &r.LastAction); err != nil {
+			return nil, fmt.Errorf("scan remediation score: %w", err)
+		}
+		scores = append(scores, r)
+	}
+	return scores, rows.Err()
+}
+
+// --- Health Streak Methods ---
+
+// GetHealthStreak returns the consecutive healthy count for a service.
+func (d *DB) GetHealthStreak(service string) (int, error) {
+	var count int
+	err := d.conn.QueryRow(
+		`SELECT consecutive_healthy FROM service_health_streak WHERE service = ?`, service,
+	).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get health streak: %w", err)
+	}
+	return count, nil
+}
+
+// SetHealthStreak upserts the consecutive healthy count for a service.
+func (d *DB) SetHealthStreak(service string, count int) error {
+	_, err := d.conn.Exec(
+		`INSERT INTO service_health_streak (service, consecutive_healthy, last_checked_at)
+		 VALUES (?, ?, datetime('now'))
+		 ON CONFLICT(service) DO UPDATE SET consecutive_healthy = ?, last_checked_at = datetime('now')`,
+		service, count, count,
+	)
+	if err != nil {
+		return fmt.Errorf("set health streak: %w", err)
+	}
+	return nil
+}
+
+// --- Config Methods ---
+
+// GetConfig returns the value for a configuration key, or the fallback if not set.
+func (d *DB) GetConfig(key, fallback string) (string, error) {
+	var value string
+	err := d.conn.QueryRow(`SELECT value FROM config WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return fallback, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get config %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// SetConfig upserts a configuration key-value pair.
+func (d *DB) SetConfig(key, value string) error {
+	_, err := d.conn.Exec(
+		`INSERT INTO config (key, value, updated_at) VALUES (?, ?, datetime('now'))
+		 ON CONFLICT(key) DO UPDATE SET value = ?, updated_at = datetime('now')`,
+		key, value, value,
+	)
+	if err != nil {
+		return fmt.Errorf("set config %q: %w", key, err)
+	}
+	return nil
+}
+
+// ServiceStatus summarizes the current state of a service from recent health checks.
+type ServiceStatus struct {
+	Service    string
+	Status     string
+	LastCheck  *string
 	CheckCount int
 }
 
-// ListServiceStatuses returns an aggregate status for each service based on
-// the most recent health check per service and total check count.
-func (d *DB) ListServiceStatuses() ([]ServiceStatus, error) {
-	rows, err := d.conn.Query(`
-		SELECT h.service,
-		       h.status,
-		       h.checked_at,
-		       counts.cnt
-		FROM health_checks h
-		INNER JOIN (
-			SELECT service, MAX(id) AS max_id, COUNT(*) AS cnt
-			FROM health_checks
-			GROUP BY service
-		) counts ON h.id = counts.max_id
-		ORDER BY h.service`)
+// ListServiceStatuses returns an aggregate status for each service based on
+// the most recent health check per service and total check count.
+func (d *DB) ListServiceStatuses() ([]ServiceStatus, error) {
+	rows, err := d.conn.Query(`
+		SELECT h.service,
+		       h.status,
+		       h.checked_at,
+		       counts.cnt
+		FROM health_checks h
+		INNER JOIN (
+			SELECT service, MAX(id) AS max_id, COUNT(*) AS cnt
+			FROM health_checks
+			GROUP BY service
+		) counts ON h.id = counts.max_id
+		ORDER BY h.service`)
+	if err != nil {
+		return nil, fmt.Errorf("list service statuses: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var statuses []ServiceStatus
+	for rows.Next() {
+		var s ServiceStatus
+		if err := rows.Scan(&s.Service, &s.Status, &s.LastCheck, &s.CheckCount); err != nil {
+			return nil, fmt.Errorf("scan service status: %w", err)
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, rows.Err()
+}
+
+// RecentCooldown represents an aggregated cooldown view for the dashboard.
+type RecentCooldown struct {
+	Service    string
+	ActionType string
+	Count      int
+	LastAction string
+}
+
+// ListRecentCooldowns returns cooldown action counts per service/action_type
+// within the given window.
+func (d *DB) ListRecentCooldowns(window time.Duration) ([]RecentCooldown, error) {
+	since := time.Now().UTC().Add(-window).Format(time.RFC3339)
+	rows, err := d.conn.Query(`
+		SELECT service, action_type, COUNT(*) AS cnt, MAX(timestamp) AS last_action
+		FROM cooldown_actions
+		WHERE timestamp > ?
+		GROUP BY service, action_type
+		ORDER BY last_action DESC`, since)
+	if err != nil {
+		return nil, fmt.Errorf("list recent cooldowns: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var cooldowns []RecentCooldown
+	for rows.Next() {
+		var c RecentCooldown
+		if err := rows.Scan(&c.Service, &c.ActionType, &c.Count, &c.LastAction); err != nil {
+			return nil, fmt.Errorf("scan cooldown: %w", err)
+		}
+		cooldowns = append(cooldowns, c)
+	}
+	return cooldowns, rows.Err()
+}
+
+// LatestSession returns the most recent session, or nil if none exist.
+func (d *DB) LatestSession() (*Session, error) {
+	s := &Session{}
+	row := d.conn.QueryRow(`SELECT ` + sessionColumns + ` FROM sessions ORDER BY started_at DESC LIMIT 1`)
+	if err := d.scanSession(row, s); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("latest session: %w", err)
+	}
+	return s, nil
+}
+
+// LatestEndedSession returns the most recent session that has ended, leaving
+// out drills, or nil if none exist.
+func (d *DB) LatestEndedSession() (*Session, error) {
+	s := &Session{}
+	row := d.conn.QueryRow(`SELECT ` + sessionColumns + ` FROM sessions WHERE ended_at IS NOT NULL AND trigger != 'drill' ORDER BY ended_at DESC, id DESC LIMIT 1`)
+	if err := d.scanSession(row, s); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("latest ended session: %w", err)
+	}
+	return s, nil
+}
+
+// RunningSession returns the currently-running session, or nil if none is active.
+func (d *DB) RunningSession() (*Session, error) {
+	s := &Session{}
+	row := d.conn.QueryRow(`SELECT ` + sessionColumns + ` FROM sessions WHERE status = 'running' ORDER BY started_at DESC LIMIT 1`)
+	if err := d.scanSession(row, s); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("running session: %w", err)
+	}
+	return s, nil
+}
+
+// ListRunningSessions returns every session still marked running, oldest
+// first.
+func (d *DB) ListRunningSessions() ([]Session, error) {
+	rows, err := d.conn.Query(`SELECT ` + sessionColumns + ` FROM sessions WHERE status = 'running' ORDER BY started_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list running sessions: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// GetEscalationChain walks parent_session_id links from the given session
+// to the root, then returns the chain ordered from root to leaf.
+// Governing: SPEC-0016 REQ "Database Schema for Escalation Chains" — full chain queryable
+func (d *DB) GetEscalationChain(sessionID int64) ([]Session, error) {
+	rows, err := d.conn.Query(`
+		WITH RECURSIVE chain(id) AS (
+			SELECT id FROM sessions WHERE id = ?
+			UNION ALL
+			SELECT s.parent_session_id FROM sessions s
+			JOIN chain c ON s.id = c.id
+			WHERE s.parent_session_id IS NOT NULL
+		)
+		SELECT `+sessionColumns+` FROM sessions
+		WHERE id IN (SELECT id FROM chain)
+		ORDER BY id ASC`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get escalation chain: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan escalation chain session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// GetChildSessions returns direct child sessions of the given session.
+// Governing: SPEC-0016 REQ "Dashboard Escalation Chain Display" — child link for session detail
+func (d *DB) GetChildSessions(sessionID int64) ([]Session, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+sessionColumns+` FROM sessions WHERE parent_session_id = ? ORDER BY id ASC`, sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get child sessions: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan child session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// --- Memory Methods ---
+
+// InsertMemory stores a memory record and returns its ID.
+func (d *DB) InsertMemory(m *Memory) (int64, error) {
+	status := m.ReviewStatus
+	if status == "" {
+		status = MemoryVerified
+	}
+	res, err := d.conn.Exec(
+		`INSERT INTO memories (service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.Service, m.Category, m.Observation, m.Confidence, boolToInt(m.Active), m.CreatedAt, m.UpdatedAt, m.SessionID, m.Tier, status,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert memory: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetMemory retrieves a single memory by ID. Soft-deleted memories are not returned.
+func (d *DB) GetMemory(id int64) (*Memory, error) {
+	m := &Memory{}
+	var active int
+	err := d.conn.QueryRow(
+		`SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count
+		 FROM memories WHERE id = ? AND deleted_at IS NULL`, id,
+	).Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get memory %d: %w", id, err)
+	}
+	m.Active = active == 1
+	return m, nil
+}
+
+// Governing: SPEC-0015 "Confidence Scoring" — confidence updates for reinforcement, contradiction, and operator edits
+// UpdateMemory updates a memory's observation, confidence, and active flag.
+func (d *DB) UpdateMemory(id int64, observation string, confidence float64, active bool) error {
+	_, err := d.conn.Exec(
+		`UPDATE memories SET observation = ?, confidence = ?, active = ?, updated_at = datetime('now') WHERE id = ?`,
+		observation, confidence, boolToInt(active), id,
+	)
+	if err != nil {
+		return fmt.Errorf("update memory %d: %w", id, err)
+	}
+	return nil
+}
+
+// SetMemoryReviewStatus sets a memory's review status. Rejecting a memory also
+// deactivates it; verifying reactivates it.
+func (d *DB) SetMemoryReviewStatus(id int64, status string) error {
+	if !ValidReviewStatus(status) {
+		return fmt.Errorf("invalid review status %q", status)
+	}
+	// Rejection tombstones the memory; any other status clears the tombstone.
+	var tombstone *string
+	query := `UPDATE memories SET review_status = ?, tombstoned_at = ?, updated_at = datetime('now') WHERE id = ?`
+	switch status {
+	case MemoryRejected:
+		now := time.Now().UTC().Format(time.RFC3339)
+		tombstone = &now
+		query = `UPDATE memories SET review_status = ?, tombstoned_at = ?, active = 0, updated_at = datetime('now') WHERE id = ?`
+	case MemoryVerified:
+		query = `UPDATE memories SET review_status = ?, tombstoned_at = ?, active = 1, updated_at = datetime('now') WHERE id = ?`
+	}
+	if _, err := d.conn.Exec(query, status, tombstone, id); err != nil {
+		return fmt.Errorf("set memory %d review status: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteMemory soft-deletes a memory by ID. The row is kept as a tombstone so
+// the agent cannot immediately re-learn the same observation; use PurgeMemory
+// to remove it permanently.
+func (d *DB) DeleteMemory(id int64) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := d.conn.Exec(
+		`UPDATE memories SET deleted_at = ?, tombstoned_at = ?, active = 0 WHERE id = ?`,
+		now, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("delete memory %d: %w", id, err)
+	}
+	return nil
+}
+
+// RestoreMemory clears a memory's deletion and tombstone and returns it to the
+// review queue as unverified.
+func (d *DB) RestoreMemory(id int64) error {
+	_, err := d.conn.Exec(
+		`UPDATE memories SET deleted_at = NULL, tombstoned_at = NULL, review_status = ?, active = 1, updated_at = datetime('now') WHERE id = ?`,
+		MemoryUnverified, id,
+	)
+	if err != nil {
+		return fmt.Errorf("restore memory %d: %w", id, err)
+	}
+	return nil
+}
+
+// PurgeMemory permanently removes a memory and its tombstone.
+func (d *DB) PurgeMemory(id int64) error {
+	if _, err := d.conn.Exec(`DELETE FROM memory_revisions WHERE memory_id = ?`, id); err != nil {
+		return fmt.Errorf("purge memory %d revisions: %w", id, err)
+	}
+	_, err := d.conn.Exec(`DELETE FROM memories WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("purge memory %d: %w", id, err)
+	}
+	return nil
+}
+
+// ListTombstonedMemories returns deleted and rejected memories, most recently
+// tombstoned first.
+func (d *DB) ListTombstonedMemories(limit int) ([]Memory, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count
+		 FROM memories WHERE tombstoned_at IS NOT NULL
+		 ORDER BY tombstoned_at DESC LIMIT ?`, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list tombstoned memories: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var memories []Memory
+	for rows.Next() {
+		var m Memory
+		var active int
+		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount); err != nil {
+			return nil, fmt.Errorf("scan tombstoned memory: %w", err)
+		}
+		m.Active = active == 1
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// FindTombstones returns memories for the given service (nil = general) that
+// were deleted or rejected at or after since (RFC3339).
+func (d *DB) FindTombstones(service *string, since string) ([]Memory, error) {
+	query := `SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count
+		 FROM memories WHERE tombstoned_at IS NOT NULL AND tombstoned_at >= ?`
+	args := []any{since}
+	if service != nil {
+		query += ` AND service = ?`
+		args = append(args, *service)
+	} else {
+		query += ` AND service IS NULL`
+	}
+
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("find tombstones: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var memories []Memory
+	for rows.Next() {
+		var m Memory
+		var active int
+		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount); err != nil {
+			return nil, fmt.Errorf("scan tombstone: %w", err)
+		}
+		m.Active = active == 1
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// IncrementMemorySuppressed records that a re-learn attempt was suppressed by
+// the memory's tombstone.
+func (d *DB) IncrementMemorySuppressed(id int64) error {
+	_, err := d.conn.Exec(`UPDATE memories SET suppressed_count = suppressed_count + 1 WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("increment suppressed count for memory %d: %w", id, err)
+	}
+	return nil
+}
+
+// CountMemoriesByReviewStatus counts undeleted memories with the given
+// review status (e.g. those awaiting operator review).
+func (d *DB) CountMemoriesByReviewStatus(status string) (int, error) {
+	var n int
+	err := d.conn.QueryRow(
+		`SELECT COUNT(*) FROM memories WHERE deleted_at IS NULL AND review_status = ?`, status,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count memories: %w", err)
+	}
+	return n, nil
+}
+
+// ListMemories returns memories with optional service, category, and review
+// status filters, ordered by confidence descending.
+func (d *DB) ListMemories(service *string, category *string, reviewStatus *string, limit, offset int) ([]Memory, error) {
+	query := `SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count FROM memories WHERE deleted_at IS NULL`
+	var args []any
+
+	if service != nil {
+		query += ` AND service` + serviceMatch
+		args = append(args, *service, *service)
+	}
+	if category != nil {
+		query += ` AND category = ?`
+		args = append(args, *category)
+	}
+	if reviewStatus != nil {
+		query += ` AND review_status = ?`
+		args = append(args, *reviewStatus)
+	}
+	query += ` ORDER BY confidence DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list memories: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var memories []Memory
+	for rows.Next() {
+		var m Memory
+		var active int
+		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount); err != nil {
+			return nil, fmt.Errorf("scan memory: %w", err)
+		}
+		m.Active = active == 1
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// MemoryFilter selects undeleted memories for bulk operations. Nil and
+// empty fields match every memory.
+type MemoryFilter struct {
+	Service  *string
+	Category *string
+	Active   *bool
+	// MinConfidence and MaxConfidence bound confidence, inclusive.
+	MinConfidence *float64
+	MaxConfidence *float64
+	// CreatedAfter and CreatedBefore bound created_at (RFC3339).
+	CreatedAfter  string
+	CreatedBefore string
+}
+
+// Empty reports whether the filter matches every memory.
+func (f MemoryFilter) Empty() bool {
+	return f == MemoryFilter{}
+}
+
+func (f MemoryFilter) where() (string, []any) {
+	where := ` WHERE deleted_at IS NULL`
+	var args []any
+	if f.Service != nil {
+		where += ` AND service` + serviceMatch
+		args = append(args, *f.Service, *f.Service)
+	}
+	if f.Category != nil {
+		where += ` AND category = ?`
+		args = append(args, *f.Category)
+	}
+	if f.Active != nil {
+		where += ` AND active = ?`
+		args = append(args, boolToInt(*f.Active))
+	}
+	if f.MinConfidence != nil {
+		where += ` AND confidence >= ?`
+		args = append(args, *f.MinConfidence)
+	}
+	if f.MaxConfidence != nil {
+		where += ` AND confidence <= ?`
+		args = append(args, *f.MaxConfidence)
+	}
+	if f.CreatedAfter != "" {
+		where += ` AND created_at >= ?`
+		args = append(args, f.CreatedAfter)
+	}
+	if f.CreatedBefore != "" {
+		where += ` AND created_at < ?`
+		args = append(args, f.CreatedBefore)
+	}
+	return where, args
+}
+
+// FindMemories returns the memories matching f, newest first.
+func (d *DB) FindMemories(f MemoryFilter) ([]Memory, error) {
+	where, args := f.where()
+	rows, err := d.conn.Query(
+		`SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count FROM memories`+where+` ORDER BY created_at DESC, id DESC`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find memories: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var memories []Memory
+	for rows.Next() {
+		var m Memory
+		var active int
+		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount); err != nil {
+			return nil, fmt.Errorf("scan memory: %w", err)
+		}
+		m.Active = active == 1
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// DeactivateMemories deactivates the active memories matching f and
+// returns how many were changed.
+func (d *DB) DeactivateMemories(f MemoryFilter) (int64, error) {
+	active := true
+	f.Active = &active
+	where, args := f.where()
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := d.conn.Exec(
+		`UPDATE memories SET active = 0, deactivated_at = ?, updated_at = datetime('now')`+where,
+		append([]any{now}, args...)...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("deactivate memories: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteMemories soft-deletes the memories matching f, leaving tombstones
+// as DeleteMemory does, and returns how many were deleted.
+func (d *DB) DeleteMemories(f MemoryFilter) (int64, error) {
+	where, args := f.where()
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := d.conn.Exec(
+		`UPDATE memories SET deleted_at = ?, tombstoned_at = ?, active = 0`+where,
+		append([]any{now, now}, args...)...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete memories: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// RetagMemories moves the memories matching f to category and, when
+// service is non-nil, to service ("" makes them general memories). A nil
+// category leaves it unchanged. It returns how many were changed.
+func (d *DB) RetagMemories(f MemoryFilter, category, service *string) (int64, error) {
+	set := `updated_at = datetime('now')`
+	var setArgs []any
+	if category != nil {
+		set += `, category = ?`
+		setArgs = append(setArgs, *category)
+	}
+	if service != nil {
+		var svc *string
+		if *service != "" {
+			svc = service
+		}
+		set += `, service = ?`
+		setArgs = append(setArgs, svc)
+	}
+	where, args := f.where()
+	res, err := d.conn.Exec(`UPDATE memories SET `+set+where, append(setArgs, args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("retag memories: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ReinforceMemory raises a memory's confidence because the agent observed it
+// again, and records when.
+func (d *DB) ReinforceMemory(id int64, confidence float64) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := d.conn.Exec(
+		`UPDATE memories SET confidence = ?, reinforced_at = ?, updated_at = datetime('now') WHERE id = ?`,
+		confidence, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("reinforce memory %d: %w", id, err)
+	}
+	return nil
+}
+
+// MemoryCategory is a memory category with statistics over its undeleted
+// memories. Registered is false for a category memories use that is not
+// in the registry.
+type MemoryCategory struct {
+	Name          string
+	Description   string
+	Registered    bool
+	Count         int
+	Active        int
+	AvgConfidence float64
+	// LastReinforced is when one of its memories was last reinforced
+	// (RFC3339), or nil.
+	LastReinforced *string
+}
+
+// ErrMemoryCategoryExists is returned when renaming to a category that
+// already exists; merge into it instead.
+var ErrMemoryCategoryExists = errors.New("memory category already exists")
+
+// ErrMemoryCategoryInUse is returned when deleting a category memories
+// still use.
+var ErrMemoryCategoryInUse = errors.New("memory category is in use")
+
+// ListMemoryCategories returns the registered categories and the
+// categories in use, with statistics, by name.
+func (d *DB) ListMemoryCategories() ([]MemoryCategory, error) {
+	rows, err := d.conn.Query(`
+		SELECT c.name, COALESCE(r.description, ''), r.name IS NOT NULL,
+		       COUNT(m.id), COALESCE(SUM(m.active), 0), COALESCE(AVG(m.confidence), 0), MAX(m.reinforced_at)
+		FROM (SELECT name FROM memory_categories UNION SELECT category FROM memories WHERE deleted_at IS NULL) c
+		LEFT JOIN memory_categories r ON r.name = c.name
+		LEFT JOIN memories m ON m.category = c.name AND m.deleted_at IS NULL
+		GROUP BY c.name
+		ORDER BY c.name`)
+	if err != nil {
+		return nil, fmt.Errorf("list memory categories: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var categories []MemoryCategory
+	for rows.Next() {
+		var c MemoryCategory
+		if err := rows.Scan(&c.Name, &c.Description, &c.Registered, &c.Count, &c.Active, &c.AvgConfidence, &c.LastReinforced); err != nil {
+			return nil, fmt.Errorf("scan memory category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// RegisteredMemoryCategories returns the names of the registered
+// categories.
+func (d *DB) RegisteredMemoryCategories() ([]string, error) {
+	rows, err := d.conn.Query(`SELECT name FROM memory_categories ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list registered memory categories: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan memory category: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// UpsertMemoryCategory registers a category, or updates its description.
+func (d *DB) UpsertMemoryCategory(name, description string) error {
+	_, err := d.conn.Exec(
+		`INSERT INTO memory_categories (name, description, created_at) VALUES (?, ?, ?)
+		 ON CONFLICT(name) DO UPDATE SET description = excluded.description`,
+		name, description, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("upsert memory category %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteMemoryCategory removes a category from the registry. It fails with
+// ErrMemoryCategoryInUse while undeleted memories use it.
+func (d *DB) DeleteMemoryCategory(name string) error {
+	var n int
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM memories WHERE category = ? AND deleted_at IS NULL`, name).Scan(&n); err != nil {
+		return fmt.Errorf("count memories in category %s: %w", name, err)
+	}
+	if n > 0 {
+		return ErrMemoryCategoryInUse
+	}
+	if _, err := d.conn.Exec(`DELETE FROM memory_categories WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("delete memory category %s: %w", name, err)
+	}
+	return nil
+}
+
+// RenameMemoryCategory renames a category in the registry and on every
+// memory filed under it, tombstones included, and returns how many memories
+// moved. It fails with ErrMemoryCategoryExists if to is already in use.
+func (d *DB) RenameMemoryCategory(from, to string) (int64, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("rename memory category: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var exists bool
+	if err := tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM memory_categories WHERE name = ?) OR EXISTS (SELECT 1 FROM memories WHERE category = ?)`, to, to,
+	).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("rename memory category: %w", err)
+	}
+	if exists {
+		return 0, ErrMemoryCategoryExists
+	}
+	n, err := moveMemoryCategories(tx, []string{from}, to)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`UPDATE memory_categories SET name = ? WHERE name = ?`, to, from); err != nil {
+		return 0, fmt.Errorf("rename memory category %s: %w", from, err)
+	}
+	return n, tx.Commit()
+}
+
+// MergeMemoryCategories moves every memory filed under the from categories,
+// tombstones included, to into, registers into if needed, and removes the
+// from categories from the registry. It returns how many memories moved.
+func (d *DB) MergeMemoryCategories(from []string, into string) (int64, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("merge memory categories: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	n, err := moveMemoryCategories(tx, from, into)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO memory_categories (name, created_at) VALUES (?, ?) ON CONFLICT(name) DO NOTHING`,
+		into, time.Now().UTC().Format(time.RFC3339),
+	); err != nil {
+		return 0, fmt.Errorf("register memory category %s: %w", into, err)
+	}
+	for _, c := range from {
+		if c == into {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM memory_categories WHERE name = ?`, c); err != nil {
+			return 0, fmt.Errorf("unregister memory category %s: %w", c, err)
+		}
+	}
+	return n, tx.Commit()
+}
+
+// moveMemoryCategories refiles the memories in the from categories under to.
+func moveMemoryCategories(tx *sql.Tx, from []string, to string) (int64, error) {
+	var moved int64
+	for _, c := range from {
+		if c == to {
+			continue
+		}
+		res, err := tx.Exec(`UPDATE memories SET category = ?, updated_at = datetime('now') WHERE category = ?`, to, c)
+		if err != nil {
+			return 0, fmt.Errorf("move memories from %s to %s: %w", c, to, err)
+		}
+		n, _ := res.RowsAffected()
+		moved += n
+	}
+	return moved, nil
+}
+
+// GetActiveMemories returns active, non-rejected memories with confidence >= 0.3,
+// verified memories first, then by confidence descending. When verifiedOnly
+// is set, unverified memories are excluded.
+func (d *DB) GetActiveMemories(limit int, verifiedOnly bool) ([]Memory, error) {
+	query := `SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count
+		 FROM memories WHERE active = 1 AND confidence >= 0.3 AND review_status != 'rejected'`
+	if verifiedOnly {
+		query += ` AND review_status = 'verified'`
+	}
+	query += ` ORDER BY review_status = 'verified' DESC, confidence DESC LIMIT ?`
+	rows, err := d.conn.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get active memories: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var memories []Memory
+	for rows.Next() {
+		var m Memory
+		var active int
+		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount); err != nil {
+			return nil, fmt.Errorf("scan active memory: %w", err)
+		}
+		m.Active = active == 1
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// GetActiveMemoriesByService returns the memories GetActiveMemories would,
+// at most perService for each service and for the general, service-less
+// ones, in the same order. When services is non-empty, only the memories
+// of those services (matched case-insensitively) and the general ones are
+// returned. The limit applies per service, so a service whose memories
+// rank low overall still gets its best.
+func (d *DB) GetActiveMemoriesByService(services []string, perService int, verifiedOnly bool) ([]Memory, error) {
+	where := `active = 1 AND confidence >= 0.3 AND review_status != 'rejected'`
+	var args []any
+	if verifiedOnly {
+		where += ` AND review_status = 'verified'`
+	}
+	if len(services) > 0 {
+		where += ` AND (service IS NULL OR lower(service) IN (?` + strings.Repeat(", ?", len(services)-1) + `))`
+		for _, svc := range services {
+			args = append(args, strings.ToLower(strings.TrimSpace(svc)))
+		}
+	}
+	args = append(args, perService)
+	rows, err := d.conn.Query(
+		`SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count
+		 FROM (SELECT *, ROW_NUMBER() OVER (
+		         PARTITION BY lower(service) ORDER BY review_status = 'verified' DESC, confidence DESC, id) AS rank
+		       FROM memories WHERE `+where+`)
+		 WHERE rank <= ?
+		 ORDER BY review_status = 'verified' DESC, confidence DESC, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get active memories by service: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var memories []Memory
+	for rows.Next() {
+		var m Memory
+		var active int
+		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount); err != nil {
+			return nil, fmt.Errorf("scan active memory: %w", err)
+		}
+		m.Active = active == 1
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// Governing: SPEC-0015 "Memory Reinforcement", "Memory Contradiction" — finds match for reinforce/contradict logic
+// FindSimilarMemory finds an existing memory matching the given service and category.
+func (d *DB) FindSimilarMemory(service *string, category string) (*Memory, error) {
+	var query string
+	var args []any
+
+	if service != nil {
+		query = `SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count
+			 FROM memories WHERE service = ? AND category = ? AND deleted_at IS NULL ORDER BY confidence DESC LIMIT 1`
+		args = []any{*service, category}
+	} else {
+		query = `SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count
+			 FROM memories WHERE service IS NULL AND category = ? AND deleted_at IS NULL ORDER BY confidence DESC LIMIT 1`
+		args = []any{category}
+	}
+
+	m := &Memory{}
+	var active int
+	err := d.conn.QueryRow(query, args...).Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find similar memory: %w", err)
+	}
+	m.Active = active == 1
+	return m, nil
+}
+
+// Governing: SPEC-0015 "Staleness Decay" — reduces confidence after grace period, deactivates below 0.3
+// DecayStaleMemories reduces confidence for memories not updated within graceDays,
+// then deactivates any that fall below 0.3, recording when.
+func (d *DB) DecayStaleMemories(graceDays int, decayRate float64) error {
+	return d.DecayMemories(graceDays, MemoryScore{Decay: decayRate}, nil)
+}
+
+// DecayMemories is DecayStaleMemories with the scoring parameters of each
+// memory's category: byCategory where it has an entry, def otherwise. Each
+// decayed memory gets a revision recording the parameters used.
+func (d *DB) DecayMemories(graceDays int, def MemoryScore, byCategory map[string]MemoryScore) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	cutoff := time.Now().UTC().AddDate(0, 0, -graceDays).Format(time.RFC3339)
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("decay stale memories: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	decay := func(score MemoryScore, where string, args ...any) error {
+		if score.Decay == 0 {
+			return nil
+		}
+		args = append([]any{cutoff}, args...)
+		if _, err := tx.Exec(
+			`INSERT INTO memory_revisions (memory_id, change, confidence_before, confidence_after, reinforce, contradict, decay, created_at)
+			 SELECT id, ?, confidence, confidence - ?, ?, ?, ?, ? FROM memories WHERE active = 1 AND updated_at < ? AND `+where,
+			append([]any{MemoryDecayed, score.Decay, score.Reinforce, score.Contradict, score.Decay, now}, args...)...,
+		); err != nil {
+			return fmt.Errorf("record memory decay: %w", err)
+		}
+		if _, err := tx.Exec(
+			`UPDATE memories SET confidence = confidence - ? WHERE active = 1 AND updated_at < ? AND `+where,
+			append([]any{score.Decay}, args...)...,
+		); err != nil {
+			return fmt.Errorf("decay stale memories: %w", err)
+		}
+		return nil
+	}
+
+	categories := make([]string, 0, len(byCategory))
+	for c := range byCategory {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	others := "1 = 1"
+	var args []any
+	if len(categories) > 0 {
+		others = "category NOT IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(categories)), ", ") + ")"
+	}
+	for _, c := range categories {
+		if err := decay(byCategory[c], "category = ?", c); err != nil {
+			return err
+		}
+		args = append(args, c)
+	}
+	if err := decay(def, others, args...); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE memories SET active = 0, deactivated_at = ? WHERE active = 1 AND confidence < 0.3`, now); err != nil {
+		return fmt.Errorf("deactivate low-confidence memories: %w", err)
+	}
+	return tx.Commit()
+}
+
+// MemoryScore is the confidence scoring model for a memory category: how
+// much an observation seen again raises its memory's confidence, how much a
+// contradicting one lowers it, and how much a stale memory loses each time
+// memories decay.
+type MemoryScore struct {
+	Reinforce  float64 `json:"reinforce"`
+	Contradict float64 `json:"contradict"`
+	Decay      float64 `json:"decay"`
+}
+
+// Memory revision changes.
+const (
+	MemoryCreated      = "created"
+	MemoryReinforced   = "reinforced"
+	MemoryContradicted = "contradicted"
+	MemoryDecayed      = "decayed"
+)
+
+// MemoryRevision is one confidence change the scoring model made to a
+// memory, with the parameters in effect.
+type MemoryRevision struct {
+	ID               int64
+	MemoryID         int64
+	SessionID        *int64
+	Change           string
+	ConfidenceBefore *float64 // nil when the memory was created
+	ConfidenceAfter  float64
+	Score            MemoryScore
+	CreatedAt        string
+}
+
+// InsertMemoryRevision records a confidence change to a memory.
+func (d *DB) InsertMemoryRevision(r *MemoryRevision) error {
+	if r.CreatedAt == "" {
+		r.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	res, err := d.conn.Exec(
+		`INSERT INTO memory_revisions (memory_id, session_id, change, confidence_before, confidence_after, reinforce, contradict, decay, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.MemoryID, r.SessionID, r.Change, r.ConfidenceBefore, r.ConfidenceAfter, r.Score.Reinforce, r.Score.Contradict, r.Score.Decay, r.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert memory revision for %d: %w", r.MemoryID, err)
+	}
+	r.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// ListMemoryRevisions returns a memory's revisions, oldest first.
+func (d *DB) ListMemoryRevisions(memoryID int64) ([]MemoryRevision, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, memory_id, session_id, change, confidence_before, confidence_after, reinforce, contradict, decay, created_at
+		 FROM memory_revisions WHERE memory_id = ? ORDER BY id`, memoryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list memory revisions for %d: %w", memoryID, err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var revisions []MemoryRevision
+	for rows.Next() {
+		var r MemoryRevision
+		if err := rows.Scan(&r.ID, &r.MemoryID, &r.SessionID, &r.Change, &r.ConfidenceBefore, &r.ConfidenceAfter, &r.Score.Reinforce, &r.Score.Contradict, &r.Score.Decay, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan memory revision: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// UpdateSessionLogFile points a session at its log's new path, after the
+// log was compressed.
+func (d *DB) UpdateSessionLogFile(id int64, logFile string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET log_file = ? WHERE id = ?`, logFile, id)
+	if err != nil {
+		return fmt.Errorf("update session log file %d: %w", id, err)
+	}
+	return nil
+}
+
+// ListSessionsToOffload returns ended sessions after afterID, oldest first,
+// that ended before endedBefore and whose logs are still on local disk.
+func (d *DB) ListSessionsToOffload(endedBefore string, afterID int64, limit int) ([]Session, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+sessionColumns+` FROM sessions
+		WHERE id > ? AND ended_at IS NOT NULL AND ended_at < ? AND status != 'running'
+		AND log_file IS NOT NULL AND log_file != '' AND log_object IS NULL
+		ORDER BY id LIMIT ?`,
+		afterID, endedBefore, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions to offload: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// ListSessionsToOffloadArtifacts returns ended sessions after afterID,
+// oldest first, that ended before endedBefore and still store a commit diff
+// or the files of a decided change report.
+func (d *DB) ListSessionsToOffloadArtifacts(endedBefore string, afterID int64, limit int) ([]Session, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+sessionColumns+` FROM sessions s
+		WHERE id > ? AND ended_at IS NOT NULL AND ended_at < ? AND status != 'running' AND artifacts_object IS NULL
+		AND (EXISTS (SELECT 1 FROM session_commits c WHERE c.session_id = s.id AND c.diff != '')
+			OR EXISTS (SELECT 1 FROM change_reports r WHERE r.session_id = s.id AND r.status != ?))
+		ORDER BY id LIMIT ?`,
+		afterID, endedBefore, ChangesPending, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions to offload artifacts: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// ListSessionLogFiles returns the log file paths of all sessions that have
+// one.
+func (d *DB) ListSessionLogFiles() ([]string, error) {
+	rows, err := d.conn.Query(`SELECT log_file FROM sessions WHERE log_file IS NOT NULL AND log_file != ''`)
+	if err != nil {
+		return nil, fmt.Errorf("list session log files: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("scan log file: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, rows.Err()
+}
+
+// SetSessionLogObject records the object storage key a session's log was
+// offloaded to.
+func (d *DB) SetSessionLogObject(id int64, key string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET log_object = ? WHERE id = ?`, key, id)
+	if err != nil {
+		return fmt.Errorf("set session log object %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateSessionSummary stores an LLM-generated summary for a session.
+// Governing: SPEC-0021 REQ "Session Summary Generation"
+func (d *DB) UpdateSessionSummary(id int64, summary string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET summary = ? WHERE id = ?`, d.sealer.Seal(summary), id)
+	if err != nil {
+		return fmt.Errorf("update session summary %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateSessionChainSummary stores the summary of the escalation chain
+// rooted at session id.
+func (d *DB) UpdateSessionChainSummary(id int64, summary string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET chain_summary = ? WHERE id = ?`, d.sealer.Seal(summary), id)
+	if err != nil {
+		return fmt.Errorf("update session chain summary %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateSessionInvocation stores how a session's CLI process was started.
+func (d *DB) UpdateSessionInvocation(id int64, invocation string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET invocation = ? WHERE id = ?`, invocation, id)
+	if err != nil {
+		return fmt.Errorf("update session invocation %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateSessionRequestID stores the ID of the request that triggered a
+// session.
+func (d *DB) UpdateSessionRequestID(id int64, requestID string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET request_id = ? WHERE id = ?`, requestID, id)
+	if err != nil {
+		return fmt.Errorf("update session request ID %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateSessionClient stores the user and metadata a chat client sent with
+// the request that triggered a session. Nil values are stored as NULL.
+func (d *DB) UpdateSessionClient(id int64, user, metadata *string) error {
+	_, err := d.conn.Exec(`UPDATE sessions SET client_user = ?, client_metadata = ? WHERE id = ?`, user, metadata, id)
+	if err != nil {
+		return fmt.Errorf("update session client %d: %w", id, err)
+	}
+	return nil
+}
+
+// DashboardStats holds aggregate metrics for the TL;DR dashboard HUD.
+// Governing: SPEC-0021 REQ "Dashboard Stats HUD"
+type DashboardStats struct {
+	TotalRuns      int
+	Escalations    int     // sessions where parent_session_id IS NOT NULL
+	Remediations   int     // sessions where tier = 3
+	SuccessRate    float64 // root sessions whose chain ended healthy or remediated / total root sessions (0–1)
+	TotalCostUSD   float64 // SUM(cost_usd)
+	ActiveMemories int     // COUNT WHERE active=1
+	CriticalEvents int     // level='critical' in last 24h
+	Redactions     int     // credential values redacted from session output in last 24h
+	AvgDurationMs  int64   // AVG(duration_ms) non-null sessions
+}
+
+// GetDashboardStats returns aggregate metrics for the TL;DR dashboard.
+// Governing: SPEC-0021 REQ "Dashboard Stats HUD"
+func (d *DB) GetDashboardStats() (*DashboardStats, error) {
+	s := &DashboardStats{}
+
+	// 1. Total root sessions. Scheduled runs skipped because their checks
+	// were fresh did not run.
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM sessions WHERE parent_session_id IS NULL AND status != 'skipped'`).Scan(&s.TotalRuns); err != nil {
+		return nil, fmt.Errorf("dashboard stats total runs: %w", err)
+	}
+
+	// 2. Escalations (child sessions).
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM sessions WHERE parent_session_id IS NOT NULL`).Scan(&s.Escalations); err != nil {
+		return nil, fmt.Errorf("dashboard stats escalations: %w", err)
+	}
+
+	// 3. Remediations (tier 3 sessions).
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM sessions WHERE tier = 3`).Scan(&s.Remediations); err != nil {
+		return nil, fmt.Errorf("dashboard stats remediations: %w", err)
+	}
+
+	// 4. Success rate: root sessions whose chain tip ended healthy or
+	// remediated / total root. A session without an outcome counts by its
+	// status.
+	var succeededRoots int
+	if err := d.conn.QueryRow(`
+		WITH RECURSIVE chain(root, id) AS (
+			SELECT id, id FROM sessions WHERE parent_session_id IS NULL AND status != 'skipped'
+			UNION ALL
+			SELECT chain.root, s.id FROM sessions s JOIN chain ON s.parent_session_id = chain.id
+		)
+		SELECT COUNT(DISTINCT chain.root) FROM chain JOIN sessions s ON s.id = chain.id
+		WHERE NOT EXISTS (SELECT 1 FROM sessions k WHERE k.parent_session_id = s.id)
+		  AND COALESCE(s.outcome, CASE WHEN s.status = 'completed' THEN 'healthy' END) IN (?, ?)`,
+		SessionHealthy, SessionRemediated,
+	).Scan(&succeededRoots); err != nil {
+		return nil, fmt.Errorf("dashboard stats succeeded roots: %w", err)
+	}
+	if s.TotalRuns > 0 {
+		s.SuccessRate = float64(succeededRoots) / float64(s.TotalRuns)
+	}
+
+	// 5. Total cost and average duration.
+	var totalCost sql.NullFloat64
+	var avgDuration sql.NullFloat64
+	if err := d.conn.QueryRow(`SELECT COALESCE(SUM(cost_usd), 0), COALESCE(AVG(duration_ms), 0) FROM sessions`).Scan(&totalCost, &avgDuration); err != nil {
+		return nil, fmt.Errorf("dashboard stats cost/duration: %w", err)
+	}
+	s.TotalCostUSD = totalCost.Float64
+	s.AvgDurationMs = int64(avgDuration.Float64)
+
+	// 6. Active memories.
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM memories WHERE active = 1`).Scan(&s.ActiveMemories); err != nil {
+		return nil, fmt.Errorf("dashboard stats active memories: %w", err)
+	}
+
+	// 7. Critical events in last 24h.
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM events WHERE level = 'critical' AND created_at > datetime('now', '-1 day')`).Scan(&s.CriticalEvents); err != nil {
+		return nil, fmt.Errorf("dashboard stats critical events: %w", err)
+	}
+
+	// 8. Redactions in last 24h.
+	if err := d.conn.QueryRow(`SELECT COALESCE(SUM(count), 0) FROM session_redactions WHERE created_at > ?`,
+		time.Now().Add(-24*time.Hour).UTC().Format(time.RFC3339)).Scan(&s.Redactions); err != nil {
+		return nil, fmt.Errorf("dashboard stats redactions: %w", err)
+	}
+
+	return s, nil
+}
+
+// --- Knowledge Base Methods ---
+
+const kbArticleColumns = `id, service, version, content, memory_count, source_hash, model, created_at`
+
+func scanKBArticle(scanner interface{ Scan(...any) error }, a *KBArticle) error {
+	return scanner.Scan(&a.ID, &a.Service, &a.Version, &a.Content, &a.MemoryCount, &a.SourceHash, &a.Model, &a.CreatedAt)
+}
+
+// InsertKBArticle stores a new article version for a.Service. The version is
+// assigned as one past the service's latest version and written back to a.
+func (d *DB) InsertKBArticle(a *KBArticle) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO kb_articles (service, version, content, memory_count, source_hash, model, created_at)
+		 VALUES (?, (SELECT COALESCE(MAX(version), 0) + 1 FROM kb_articles WHERE service = ?), ?, ?, ?, ?, ?)`,
+		a.Service, a.Service, a.Content, a.MemoryCount, a.SourceHash, a.Model, a.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert kb article: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := d.conn.QueryRow(`SELECT version FROM kb_articles WHERE id = ?`, id).Scan(&a.Version); err != nil {
+		return 0, fmt.Errorf("read kb article version: %w", err)
+	}
+	return id, nil
+}
+
+// GetKBArticle returns the given version of a service's article, or the latest
+// version when version is 0. Returns nil if no such article exists.
+func (d *DB) GetKBArticle(service string, version int) (*KBArticle, error) {
+	query := `SELECT ` + kbArticleColumns + ` FROM kb_articles WHERE service = ?`
+	args := []any{service}
+	if version > 0 {
+		query += ` AND version = ?`
+		args = append(args, version)
+	}
+	query += ` ORDER BY version DESC LIMIT 1`
+
+	var a KBArticle
+	err := scanKBArticle(d.conn.QueryRow(query, args...), &a)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get kb article %s: %w", service, err)
+	}
+	return &a, nil
+}
+
+// ListKBArticles returns the latest article version for every service,
+// ordered by service name.
+func (d *DB) ListKBArticles() ([]KBArticle, error) {
+	rows, err := d.conn.Query(
+		`SELECT ` + kbArticleColumns + ` FROM kb_articles k
+		 WHERE version = (SELECT MAX(version) FROM kb_articles WHERE service = k.service)
+		 ORDER BY service`)
+	if err != nil {
+		return nil, fmt.Errorf("list kb articles: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var articles []KBArticle
+	for rows.Next() {
+		var a KBArticle
+		if err := scanKBArticle(rows, &a); err != nil {
+			return nil, fmt.Errorf("scan kb article: %w", err)
+		}
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// ListKBArticleVersions returns every version of a service's article, newest
+// first.
+func (d *DB) ListKBArticleVersions(service string) ([]KBArticle, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+kbArticleColumns+` FROM kb_articles WHERE service = ? ORDER BY version DESC`, service)
+	if err != nil {
+		return nil, fmt.Errorf("list kb article versions: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var articles []KBArticle
+	for rows.Next() {
+		var a KBArticle
+		if err := scanKBArticle(rows, &a); err != nil {
+			return nil, fmt.Errorf("scan kb article: %w", err)
+		}
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+const drillColumns = `id, status, session_id, max_tier, detected, remediated, notified, detail, started_at, ended_at`
+
+func scanDrill(scanner interface{ Scan(...any) error }, d *Drill) error {
+	return scanner.Scan(&d.ID, &d.Status, &d.SessionID, &d.MaxTier, &d.Detected, &d.Remediated, &d.Notified, &d.Detail, &d.StartedAt, &d.EndedAt)
+}
+
+// InsertDrill records the start of a self-test drill.
+func (d *DB) InsertDrill(dr *Drill) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO drills (status, session_id, max_tier, detected, remediated, notified, detail, started_at, ended_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		dr.Status, dr.SessionID, dr.MaxTier, dr.Detected, dr.Remediated, dr.Notified, dr.Detail, dr.StartedAt, dr.EndedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert drill: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// UpdateDrill writes back every mutable field of a drill.
+func (d *DB) UpdateDrill(dr *Drill) error {
+	_, err := d.conn.Exec(
+		`UPDATE drills SET status = ?, session_id = ?, max_tier = ?, detected = ?, remediated = ?, notified = ?, detail = ?, ended_at = ?
+		 WHERE id = ?`,
+		dr.Status, dr.SessionID, dr.MaxTier, dr.Detected, dr.Remediated, dr.Notified, dr.Detail, dr.EndedAt, dr.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update drill %d: %w", dr.ID, err)
+	}
+	return nil
+}
+
+// GetDrill returns a drill by ID, or nil if it does not exist.
+func (d *DB) GetDrill(id int64) (*Drill, error) {
+	var dr Drill
+	err := scanDrill(d.conn.QueryRow(`SELECT `+drillColumns+` FROM drills WHERE id = ?`, id), &dr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get drill %d: %w", id, err)
+	}
+	return &dr, nil
+}
+
+// GetRunningDrill returns the most recent drill still in progress, or nil.
+func (d *DB) GetRunningDrill() (*Drill, error) {
+	var dr Drill
+	err := scanDrill(d.conn.QueryRow(
+		`SELECT `+drillColumns+` FROM drills WHERE status = 'running' ORDER BY id DESC LIMIT 1`), &dr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get running drill: %w", err)
+	}
+	return &dr, nil
+}
+
+// ListDrills returns the most recent drills, newest first.
+func (d *DB) ListDrills(limit int) ([]Drill, error) {
+	rows, err := d.conn.Query(`SELECT `+drillColumns+` FROM drills ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list drills: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var drills []Drill
+	for rows.Next() {
+		var dr Drill
+		if err := scanDrill(rows, &dr); err != nil {
+			return nil, fmt.Errorf("scan drill: %w", err)
+		}
+		drills = append(drills, dr)
+	}
+	return drills, rows.Err()
+}
+
+const promptHistoryColumns = `id, prompt, favorite, use_count, last_used_at`
+
+func (d *DB) scanPromptHistory(scanner interface{ Scan(...any) error }, p *PromptHistory) error {
+	if err := scanner.Scan(&p.ID, &p.Prompt, &p.Favorite, &p.UseCount, &p.LastUsedAt); err != nil {
+		return err
+	}
+	p.Prompt = d.sealer.OpenOr(p.Prompt)
+	return nil
+}
+
+// sealPromptHistory hashes the prompts stored without a hash and, with a
+// sealer, seals and rehashes those stored in plaintext, as prompts written
+// before migration 00045 or before encryption was turned on are. A prompt
+// sealed with another key keeps its hash.
+func (d *DB) sealPromptHistory() error {
+	query := `SELECT id, prompt FROM prompt_history WHERE prompt_hash IS NULL`
+	if d.sealer != nil {
+		query += ` OR prompt NOT LIKE 'sealed:v1:%'`
+	}
+	rows, err := d.conn.Query(query)
+	if err != nil {
+		return fmt.Errorf("seal prompt history: %w", err)
+	}
+	type row struct {
+		id     int64
+		prompt string
+	}
+	var pending []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.prompt); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("seal prompt history: %w", err)
+		}
+		pending = append(pending, r)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("seal prompt history: %w", err)
+	}
+	for _, r := range pending {
+		prompt, hash := r.prompt, d.sealer.Hash(r.prompt)
+		if !seal.IsSealed(prompt) {
+			prompt = d.sealer.Seal(prompt)
+		}
+		// The same prompt may have been recorded again under the new hash,
+		// e.g. while the key was not set; the two rows are merged.
+		if _, err := d.conn.Exec(
+			`UPDATE prompt_history SET
+			   use_count = use_count + (SELECT use_count FROM prompt_history WHERE id = ?1),
+			   favorite = MAX(favorite, (SELECT favorite FROM prompt_history WHERE id = ?1)),
+			   last_used_at = MAX(last_used_at, (SELECT last_used_at FROM prompt_history WHERE id = ?1))
+			 WHERE prompt_hash = ?2 AND id != ?1`, r.id, hash,
+		); err != nil {
+			return fmt.Errorf("seal prompt history %d: %w", r.id, err)
+		}
+		if _, err := d.conn.Exec(
+			`DELETE FROM prompt_history WHERE id = ?1 AND EXISTS (SELECT 1 FROM prompt_history WHERE prompt_hash = ?2 AND id != ?1)`,
+			r.id, hash,
+		); err != nil {
+			return fmt.Errorf("seal prompt history %d: %w", r.id, err)
+		}
+		if _, err := d.conn.Exec(`UPDATE prompt_history SET prompt = ?, prompt_hash = ? WHERE id = ?`, prompt, hash, r.id); err != nil {
+			return fmt.Errorf("seal prompt history %d: %w", r.id, err)
+		}
+	}
+	return nil
+}
+
+// RecordPrompt records a use of prompt, bumping its use count if it was run
+// before. A favorite stays a favorite; favorite=true marks it as one. Only
+// the keep most recently used non-favorite prompts are retained.
+func (d *DB) RecordPrompt(prompt string, favorite bool, keep int) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := d.conn.Exec(
+		`INSERT INTO prompt_history (prompt, prompt_hash, favorite, use_count, last_used_at) VALUES (?, ?, ?, 1, ?)
+		 ON CONFLICT(prompt_hash) DO UPDATE SET
+		   use_count = use_count + 1,
+		   favorite = MAX(favorite, excluded.favorite),
+		   last_used_at = excluded.last_used_at`,
+		d.sealer.Seal(prompt), d.sealer.Hash(prompt), favorite, now,
+	); err != nil {
+		return fmt.Errorf("record prompt: %w", err)
+	}
+	if _, err := d.conn.Exec(
+		`DELETE FROM prompt_history WHERE favorite = 0 AND id NOT IN (
+		   SELECT id FROM prompt_history WHERE favorite = 0 ORDER BY last_used_at DESC, id DESC LIMIT ?)`,
+		keep,
+	); err != nil {
+		return fmt.Errorf("prune prompt history: %w", err)
+	}
+	return nil
+}
+
+// ListPromptHistory returns favorites first, then the most recently used
+// prompts.
+func (d *DB) ListPromptHistory(limit int) ([]PromptHistory, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+promptHistoryColumns+` FROM prompt_history
+		 ORDER BY favorite DESC, last_used_at DESC, id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list prompt history: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var prompts []PromptHistory
+	for rows.Next() {
+		var p PromptHistory
+		if err := d.scanPromptHistory(rows, &p); err != nil {
+			return nil, fmt.Errorf("scan prompt history: %w", err)
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts, rows.Err()
+}
+
+// GetPromptHistory returns a prompt history entry by ID, or nil if it does
+// not exist.
+func (d *DB) GetPromptHistory(id int64) (*PromptHistory, error) {
+	var p PromptHistory
+	err := d.scanPromptHistory(d.conn.QueryRow(`SELECT `+promptHistoryColumns+` FROM prompt_history WHERE id = ?`, id), &p)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get prompt history %d: %w", id, err)
+	}
+	return &p, nil
+}
+
+// SetPromptFavorite marks or unmarks a prompt as a favorite.
+func (d *DB) SetPromptFavorite(id int64, favorite bool) error {
+	if _, err := d.conn.Exec(`UPDATE prompt_history SET favorite = ? WHERE id = ?`, favorite, id); err != nil {
+		return fmt.Errorf("set prompt favorite %d: %w", id, err)
+	}
+	return nil
+}
+
+// DeletePromptHistory removes a prompt from the history.
+func (d *DB) DeletePromptHistory(id int64) error {
+	if _, err := d.conn.Exec(`DELETE FROM prompt_history WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete prompt history %d: %w", id, err)
+	}
+	return nil
+}
+
+// InsertStreamDiagnostic records dropped stream events for a session.
+func (d *DB) InsertStreamDiagnostic(sd *StreamDiagnostic) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO stream_diagnostics (session_id, event_type, count, sample, created_at) VALUES (?, ?, ?, ?, ?)`,
+		sd.SessionID, sd.EventType, sd.Count, sd.Sample, sd.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert stream diagnostic: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ListStreamDiagnostics returns a session's dropped stream events, most
+// frequent first.
+func (d *DB) ListStreamDiagnostics(sessionID int64) ([]StreamDiagnostic, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, session_id, event_type, count, sample, created_at FROM stream_diagnostics
+		 WHERE session_id = ? ORDER BY count DESC, event_type`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list stream diagnostics: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []StreamDiagnostic
+	for rows.Next() {
+		var sd StreamDiagnostic
+		if err := rows.Scan(&sd.ID, &sd.SessionID, &sd.EventType, &sd.Count, &sd.Sample, &sd.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan stream diagnostic: %w", err)
+		}
+		out = append(out, sd)
+	}
+	return out, rows.Err()
+}
+
+// InsertSessionRedaction records a rule's redactions for a session.
+func (d *DB) InsertSessionRedaction(sr *SessionRedaction) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO session_redactions (session_id, rule, count, created_at) VALUES (?, ?, ?, ?)`,
+		sr.SessionID, sr.Rule, sr.Count, sr.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert session redaction: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ListSessionRedactions returns a session's redactions by rule, most
+// frequent first.
+func (d *DB) ListSessionRedactions(sessionID int64) ([]SessionRedaction, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, session_id, rule, count, created_at FROM session_redactions
+		 WHERE session_id = ? ORDER BY count DESC, rule`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list session redactions: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []SessionRedaction
+	for rows.Next() {
+		var sr SessionRedaction
+		if err := rows.Scan(&sr.ID, &sr.SessionID, &sr.Rule, &sr.Count, &sr.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan session redaction: %w", err)
+		}
+		out = append(out, sr)
+	}
+	return out, rows.Err()
+}
+
+// CountSessionRedactions returns the total redactions of each of the given
+// sessions that had any.
+func (d *DB) CountSessionRedactions(sessionIDs []int64) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	if len(sessionIDs) == 0 {
+		return counts, nil
+	}
+	args := make([]any, len(sessionIDs))
+	for i, id := range sessionIDs {
+		args[i] = id
+	}
+	rows, err := d.conn.Query(
+		`SELECT session_id, SUM(count) FROM session_redactions
+		 WHERE session_id IN (?`+strings.Repeat(", ?", len(sessionIDs)-1)+`) GROUP BY session_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count session redactions: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scan session redaction count: %w", err)
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// InsertParseWarning records a marker a session's output got wrong.
+func (d *DB) InsertParseWarning(pw *ParseWarning) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO parse_warnings (session_id, marker, text, reason, created_at) VALUES (?, ?, ?, ?, ?)`,
+		pw.SessionID, pw.Marker, pw.Text, pw.Reason, pw.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert parse warning: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ListParseWarnings returns a session's parse warnings in the order they
+// were found.
+func (d *DB) ListParseWarnings(sessionID int64) ([]ParseWarning, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, session_id, marker, text, reason, created_at FROM parse_warnings
+		 WHERE session_id = ? ORDER BY id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list parse warnings: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []ParseWarning
+	for rows.Next() {
+		var pw ParseWarning
+		if err := rows.Scan(&pw.ID, &pw.SessionID, &pw.Marker, &pw.Text, &pw.Reason, &pw.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan parse warning: %w", err)
+		}
+		out = append(out, pw)
+	}
+	return out, rows.Err()
+}
+
+// CountParseWarnings returns the number of parse warnings of each of the
+// given sessions that has any.
+func (d *DB) CountParseWarnings(sessionIDs []int64) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	if len(sessionIDs) == 0 {
+		return counts, nil
+	}
+	args := make([]any, len(sessionIDs))
+	for i, id := range sessionIDs {
+		args[i] = id
+	}
+	rows, err := d.conn.Query(
+		`SELECT session_id, COUNT(*) FROM parse_warnings
+		 WHERE session_id IN (?`+strings.Repeat(", ?", len(sessionIDs)-1)+`) GROUP BY session_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count parse warnings: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scan parse warning count: %w", err)
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// InsertPolicyEvaluation records the outcome of a policy rule.
+func (d *DB) InsertPolicyEvaluation(pe *PolicyEvaluation) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO policy_evaluations (session_id, point, rule, matched, action, detail, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		pe.SessionID, pe.Point, pe.Rule, pe.Matched, pe.Action, pe.Detail, pe.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert policy evaluation: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ListPolicyEvaluations returns a session's policy evaluations in the order
+// they ran.
+func (d *DB) ListPolicyEvaluations(sessionID int64) ([]PolicyEvaluation, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, session_id, point, rule, matched, action, detail, created_at FROM policy_evaluations
+		 WHERE session_id = ? ORDER BY id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list policy evaluations: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []PolicyEvaluation
+	for rows.Next() {
+		var pe PolicyEvaluation
+		if err := rows.Scan(&pe.ID, &pe.SessionID, &pe.Point, &pe.Rule, &pe.Matched, &pe.Action, &pe.Detail, &pe.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan policy evaluation: %w", err)
+		}
+		out = append(out, pe)
+	}
+	return out, rows.Err()
+}
+
+const escalationDecisionColumns = `id, session_id, from_tier, requested_tier, tier, outcome, reason, source, services, handoff, cooldowns, chain_cost_usd, cost_24h_usd, dry_run, max_tier, created_at`
+
+// InsertEscalationDecision records the escalation decision made after a
+// session.
+func (d *DB) InsertEscalationDecision(ed *EscalationDecision) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO escalation_decisions (session_id, from_tier, requested_tier, tier, outcome, reason, source, services, handoff, cooldowns, chain_cost_usd, cost_24h_usd, dry_run, max_tier, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		ed.SessionID, ed.FromTier, ed.RequestedTier, ed.Tier, ed.Outcome, ed.Reason, ed.Source, ed.Services, ed.Handoff,
+		ed.Cooldowns, ed.ChainCostUSD, ed.Cost24hUSD, ed.DryRun, ed.MaxTier, ed.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert escalation decision: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetEscalationDecision returns the escalation decision made after a
+// session, or nil if none was recorded.
+func (d *DB) GetEscalationDecision(sessionID int64) (*EscalationDecision, error) {
+	var ed EscalationDecision
+	err := d.conn.QueryRow(
+		`SELECT `+escalationDecisionColumns+` FROM escalation_decisions WHERE session_id = ? ORDER BY id DESC LIMIT 1`, sessionID,
+	).Scan(&ed.ID, &ed.SessionID, &ed.FromTier, &ed.RequestedTier, &ed.Tier, &ed.Outcome, &ed.Reason, &ed.Source, &ed.Services,
+		&ed.Handoff, &ed.Cooldowns, &ed.ChainCostUSD, &ed.Cost24hUSD, &ed.DryRun, &ed.MaxTier, &ed.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get escalation decision: %w", err)
+	}
+	return &ed, nil
+}
+
+// ListEscalationsSince returns the decisions that escalated a chain to its
+// next tier at or after since (RFC3339), newest first.
+func (d *DB) ListEscalationsSince(since string) ([]EscalationDecision, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+escalationDecisionColumns+` FROM escalation_decisions
+		 WHERE outcome = 'escalated' AND created_at >= ? ORDER BY created_at DESC, id DESC`, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list escalations: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []EscalationDecision
+	for rows.Next() {
+		var ed EscalationDecision
+		if err := rows.Scan(&ed.ID, &ed.SessionID, &ed.FromTier, &ed.RequestedTier, &ed.Tier, &ed.Outcome, &ed.Reason, &ed.Source, &ed.Services,
+			&ed.Handoff, &ed.Cooldowns, &ed.ChainCostUSD, &ed.Cost24hUSD, &ed.DryRun, &ed.MaxTier, &ed.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan escalation: %w", err)
+		}
+		out = append(out, ed)
+	}
+	return out, rows.Err()
+}
+
+// ErrApprovalClosed is returned when approving a request that is no longer
+// pending.
+var ErrApprovalClosed = errors.New("approval request is no longer pending")
+
+const approvalRequestColumns = `id, session_id, tier, services, reason, chain, required, status, created_at, expires_at, resolved_at, resolved_by`
+
+// InsertApprovalRequest creates a pending approval request.
+func (d *DB) InsertApprovalRequest(ar *ApprovalRequest) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO approval_requests (session_id, tier, services, reason, chain, required, status, created_at, expires_at)
+		 VALUES (?, ?, ?, ?, ?, ?, 'pending', ?, ?)`,
+		ar.SessionID, ar.Tier, ar.Services, ar.Reason, ar.Chain, ar.Required, ar.CreatedAt, ar.ExpiresAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert approval request: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetApprovalRequest returns an approval request with its approvers, or nil
+// if it does not exist.
+func (d *DB) GetApprovalRequest(id int64) (*ApprovalRequest, error) {
+	ar, err := scanApprovalRequest(d.conn.QueryRow(`SELECT `+approvalRequestColumns+` FROM approval_requests WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get approval request %d: %w", id, err)
+	}
+	if ar.Approvers, err = d.listApprovers(id); err != nil {
+		return nil, err
+	}
+	return ar, nil
+}
+
+// ListApprovalRequests returns requests with the given status, newest
+// first, with their approvers.
+func (d *DB) ListApprovalRequests(status string, limit int) ([]ApprovalRequest, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+approvalRequestColumns+` FROM approval_requests WHERE status = ? ORDER BY id DESC LIMIT ?`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list approval requests: %w", err)
+	}
+	return d.collectApprovalRequests(rows)
+}
+
+// collectApprovalRequests scans and closes rows of approval requests, then
+// loads each request's approvers.
+func (d *DB) collectApprovalRequests(rows *sql.Rows) ([]ApprovalRequest, error) {
+	var out []ApprovalRequest
+	for rows.Next() {
+		ar, err := scanApprovalRequest(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan approval request: %w", err)
+		}
+		out = append(out, *ar)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		approvers, err := d.listApprovers(out[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		out[i].Approvers = approvers
+	}
+	return out, nil
+}
+
+// AddApproval records approver's approval of a pending request and returns
+// the number of distinct approvers so far. Approving twice counts once.
+func (d *DB) AddApproval(requestID int64, approver, at string) (int, error) {
+	var status string
+	if err := d.conn.QueryRow(`SELECT status FROM approval_requests WHERE id = ?`, requestID).Scan(&status); err != nil {
+		return 0, fmt.Errorf("get approval request %d: %w", requestID, err)
+	}
+	if status != "pending" {
+		return 0, ErrApprovalClosed
+	}
+	if _, err := d.conn.Exec(
+		`INSERT OR IGNORE INTO approvals (request_id, approver, created_at) VALUES (?, ?, ?)`,
+		requestID, approver, at,
+	); err != nil {
+		return 0, fmt.Errorf("insert approval: %w", err)
+	}
+	var n int
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM approvals WHERE request_id = ?`, requestID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count approvals: %w", err)
+	}
+	return n, nil
+}
+
+// ResolveApprovalRequest moves a pending request to status. It returns
+// ErrApprovalClosed if the request was already resolved.
+func (d *DB) ResolveApprovalRequest(id int64, status, by, at string) error {
+	res, err := d.conn.Exec(
+		`UPDATE approval_requests SET status = ?, resolved_by = ?, resolved_at = ? WHERE id = ? AND status = 'pending'`,
+		status, by, at, id,
+	)
+	if err != nil {
+		return fmt.Errorf("resolve approval request %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrApprovalClosed
+	}
+	return nil
+}
+
+// ReopenApprovalRequest moves an approved request back to pending so it
+// can be approved again; recorded approvals are kept.
+func (d *DB) ReopenApprovalRequest(id int64) error {
+	if _, err := d.conn.Exec(
+		`UPDATE approval_requests SET status = 'pending', resolved_by = NULL, resolved_at = NULL WHERE id = ? AND status = 'approved'`,
+		id,
+	); err != nil {
+		return fmt.Errorf("reopen approval request %d: %w", id, err)
+	}
+	return nil
+}
+
+// ExpireApprovalRequests marks pending requests whose expiry is at or
+// before now as expired and returns them.
+func (d *DB) ExpireApprovalRequests(now string) ([]ApprovalRequest, error) {
+	rows, err := d.conn.Query(
+		`UPDATE approval_requests SET status = 'expired', resolved_at = ?
+		 WHERE status = 'pending' AND expires_at <= ?
+		 RETURNING `+approvalRequestColumns, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("expire approval requests: %w", err)
+	}
+	return d.collectApprovalRequests(rows)
+}
+
+func (d *DB) listApprovers(requestID int64) ([]string, error) {
+	rows, err := d.conn.Query(`SELECT approver FROM approvals WHERE request_id = ? ORDER BY id`, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("list approvers: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []string
+	for rows.Next() {
+		var a string
+		if err := rows.Scan(&a); err != nil {
+			return nil, fmt.Errorf("scan approver: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func scanApprovalRequest(scanner interface{ Scan(...any) error }) (*ApprovalRequest, error) {
+	var ar ApprovalRequest
+	err := scanner.Scan(&ar.ID, &ar.SessionID, &ar.Tier, &ar.Services, &ar.Reason, &ar.Chain, &ar.Required,
+		&ar.Status, &ar.CreatedAt, &ar.ExpiresAt, &ar.ResolvedAt, &ar.ResolvedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &ar, nil
+}
+
+// --- Service Name Methods ---
+
+// ServiceNameCount is how many rows across the service-keyed tables use one
+// service name.
+type ServiceNameCount struct {
+	Service string
+	Rows    int64
+}
+
+// ListServiceNames returns every service name recorded in events, memories,
+// cooldown actions, health checks, health streaks, and knowledge base
+// articles, with its row count, ordered by name.
+func (d *DB) ListServiceNames() ([]ServiceNameCount, error) {
+	rows, err := d.conn.Query(
+		`SELECT service, COUNT(*) FROM (
+			SELECT service FROM events WHERE service IS NOT NULL
+			UNION ALL SELECT service FROM memories WHERE service IS NOT NULL
+			UNION ALL SELECT service FROM cooldown_actions
+			UNION ALL SELECT service FROM health_checks
+			UNION ALL SELECT service FROM service_health_streak
+			UNION ALL SELECT service FROM kb_articles
+		) GROUP BY service ORDER BY service`)
+	if err != nil {
+		return nil, fmt.Errorf("list service names: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []ServiceNameCount
+	for rows.Next() {
+		var c ServiceNameCount
+		if err := rows.Scan(&c.Service, &c.Rows); err != nil {
+			return nil, fmt.Errorf("scan service name: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// serviceMatch matches a service column against a name, or, when the name is
+// a merged alias, against the service it was merged into. It takes the name
+// twice.
+const serviceMatch = ` IN (?, (SELECT service FROM service_aliases WHERE alias = ?))`
+
+// ServiceAlias is a service name merged into another service.
+type ServiceAlias struct {
+	Alias     string
+	Service   string
+	MergedBy  *string
+	CreatedAt string
+}
+
+// MergeService moves every record of service from onto service to in one
+// transaction and returns the number of records changed. A health streak for
+// from is dropped when to already has one. Knowledge base articles for from
+// are deleted: they are compiled from memories, so the merged service's
+// article is regenerated on the next knowledge base pass.
+//
+// When alias is non-empty it is recorded as an alias of to, and aliases of
+// from are repointed at to. The merge is logged as an event under to.
+func (d *DB) MergeService(from, to, alias, mergedBy, at string) (int64, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin merge service: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var total int64
+	exec := func(q string, args ...any) error {
+		res, err := tx.Exec(q, args...)
+		if err != nil {
+			return fmt.Errorf("merge service %s: %w", from, err)
+		}
+		n, _ := res.RowsAffected()
+		total += n
+		return nil
+	}
+	for _, q := range []string{
+		`UPDATE events SET service = ? WHERE service = ?`,
+		`UPDATE memories SET service = ? WHERE service = ?`,
+		`UPDATE cooldown_actions SET service = ? WHERE service = ?`,
+		`UPDATE health_checks SET service = ? WHERE service = ?`,
+		`UPDATE OR IGNORE service_health_streak SET service = ? WHERE service = ?`,
+	} {
+		if err := exec(q, to, from); err != nil {
+			return 0, err
+		}
+	}
+	for _, q := range []string{
+		`DELETE FROM service_health_streak WHERE service = ?`,
+		`DELETE FROM kb_articles WHERE service = ?`,
+	} {
+		if err := exec(q, from); err != nil {
+			return 0, err
+		}
+	}
+
+	var by *string
+	if mergedBy != "" {
+		by = &mergedBy
+	}
+	if alias != "" {
+		if _, err := tx.Exec(`UPDATE service_aliases SET service = ? WHERE service = ?`, to, from); err != nil {
+			return 0, fmt.Errorf("repoint service aliases: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM service_aliases WHERE alias = ?`, to); err != nil {
+			return 0, fmt.Errorf("delete service alias: %w", err)
+		}
+		if _, err := tx.Exec(
+			`INSERT OR REPLACE INTO service_aliases (alias, service, merged_by, created_at) VALUES (?, ?, ?, ?)`,
+			alias, to, by, at); err != nil {
+			return 0, fmt.Errorf("insert service alias: %w", err)
+		}
+	}
+
+	noun := "records"
+	if total == 1 {
+		noun = "record"
+	}
+	msg := fmt.Sprintf("Merged service %q into %q (%d %s)", from, to, total, noun)
+	if mergedBy != "" {
+		msg += " by " + mergedBy
+	}
+	if _, err := tx.Exec(`INSERT INTO events (level, service, message, created_at) VALUES ('info', ?, ?, ?)`, to, msg, at); err != nil {
+		return 0, fmt.Errorf("log service merge: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit merge service: %w", err)
+	}
+	return total, nil
+}
+
+// GetServiceAlias returns the service alias was merged into, or "" if it is
+// not an alias.
+func (d *DB) GetServiceAlias(alias string) (string, error) {
+	var service string
+	err := d.conn.QueryRow(`SELECT service FROM service_aliases WHERE alias = ?`, alias).Scan(&service)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get service alias %s: %w", alias, err)
+	}
+	return service, nil
+}
+
+// ListServiceAliases returns the recorded service aliases, newest first.
+func (d *DB) ListServiceAliases() ([]ServiceAlias, error) {
+	rows, err := d.conn.Query(`SELECT alias, service, merged_by, created_at FROM service_aliases ORDER BY created_at DESC, alias`)
+	if err != nil {
+		return nil, fmt.Errorf("list service aliases: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []ServiceAlias
+	for rows.Next() {
+		var a ServiceAlias
+		if err := rows.Scan(&a.Alias, &a.Service, &a.MergedBy, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan service alias: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// --- Session Feedback Methods ---
+
+// SessionFeedback is an operator's rating of a session.
+type SessionFeedback struct {
+	ID        int64
+	SessionID int64
+	Rating    int // 1 (thumbs up) or -1 (thumbs down)
+	Comment   *string
+	Operator  *string
+	MemoryID  *int64 // corrective memory created by a thumbs down
+	CreatedAt string
+}
+
+const feedbackColumns = `id, session_id, rating, comment, operator, memory_id, created_at`
+
+func scanSessionFeedback(scanner interface{ Scan(...any) error }, f *SessionFeedback) error {
+	return scanner.Scan(&f.ID, &f.SessionID, &f.Rating, &f.Comment, &f.Operator, &f.MemoryID, &f.CreatedAt)
+}
+
+// InsertSessionFeedback records feedback on a session. When correction is
+// non-nil it is inserted as a memory in the same transaction and linked from
+// the feedback.
+func (d *DB) InsertSessionFeedback(f *SessionFeedback, correction *Memory) (int64, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin session feedback: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if correction != nil {
+		status := correction.ReviewStatus
+		if status == "" {
+			status = MemoryVerified
+		}
+		res, err := tx.Exec(
+			`INSERT INTO memories (service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			correction.Service, correction.Category, correction.Observation, correction.Confidence, boolToInt(correction.Active),
+			correction.CreatedAt, correction.UpdatedAt, correction.SessionID, correction.Tier, status,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("insert corrective memory: %w", err)
+		}
+		memoryID, err := res.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("insert corrective memory: %w", err)
+		}
+		correction.ID = memoryID
+		f.MemoryID = &memoryID
+	}
+
+	res, err := tx.Exec(
+		`INSERT INTO session_feedback (session_id, rating, comment, operator, memory_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		f.SessionID, f.Rating, f.Comment, f.Operator, f.MemoryID, f.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert session feedback %d: %w", f.SessionID, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("insert session feedback %d: %w", f.SessionID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit session feedback %d: %w", f.SessionID, err)
+	}
+	f.ID = id
+	return id, nil
+}
+
+// ListSessionFeedback returns the feedback on a session, oldest first.
+func (d *DB) ListSessionFeedback(sessionID int64) ([]SessionFeedback, error) {
+	return d.queryFeedback(`WHERE session_id = ? ORDER BY created_at, id`, sessionID)
+}
+
+// ListRecentFeedback returns the limit most recent feedback entries.
+func (d *DB) ListRecentFeedback(limit int) ([]SessionFeedback, error) {
+	return d.queryFeedback(`ORDER BY created_at DESC, id DESC LIMIT ?`, limit)
+}
+
+func (d *DB) queryFeedback(query string, args ...any) ([]SessionFeedback, error) {
+	rows, err := d.conn.Query(`SELECT `+feedbackColumns+` FROM session_feedback `+query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list session feedback: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []SessionFeedback
+	for rows.Next() {
+		var f SessionFeedback
+		if err := scanSessionFeedback(rows, &f); err != nil {
+			return nil, fmt.Errorf("scan session feedback: %w", err)
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// FeedbackStats counts sessions and the feedback on them over a period.
+type FeedbackStats struct {
+	Sessions  int // finished sessions started in the period, excluding drills
+	Rated     int // of those, sessions with any feedback
+	Corrected int // of those, sessions with a thumbs down
+	Up        int // thumbs up given in the period
+	Down      int // thumbs down given in the period
+}
+
+// CorrectionRate returns the fraction of sessions that needed correction, or
+// -1 when there were no sessions.
+func (f *FeedbackStats) CorrectionRate() float64 {
+	if f.Sessions == 0 {
+		return -1
+	}
+	return float64(f.Corrected) / float64(f.Sessions)
+}
+
+// GetFeedbackStats counts sessions started at or after since (RFC3339; empty
+// for all time) and the feedback on them.
+func (d *DB) GetFeedbackStats(since string) (*FeedbackStats, error) {
+	var st FeedbackStats
+	err := d.conn.QueryRow(
+		`SELECT COUNT(*),
+		        COALESCE(SUM(EXISTS (SELECT 1 FROM session_feedback f WHERE f.session_id = s.id)), 0),
+		        COALESCE(SUM(EXISTS (SELECT 1 FROM session_feedback f WHERE f.session_id = s.id AND f.rating < 0)), 0)
+		 FROM sessions s
+		 WHERE s.ended_at IS NOT NULL AND s.started_at >= ? AND COALESCE(s.trigger, '') != 'drill'`,
+		since,
+	).Scan(&st.Sessions, &st.Rated, &st.Corrected)
+	if err != nil {
+		return nil, fmt.Errorf("feedback session stats: %w", err)
+	}
+	err = d.conn.QueryRow(
+		`SELECT COALESCE(SUM(rating > 0), 0), COALESCE(SUM(rating < 0), 0) FROM session_feedback WHERE created_at >= ?`,
+		since,
+	).Scan(&st.Up, &st.Down)
+	if err != nil {
+		return nil, fmt.Errorf("feedback stats: %w", err)
+	}
+	return &st, nil
+}
+
+// --- Pager Incident Methods ---
+
+// Pager incident statuses.
+const (
+	PagerTriggered = "triggered"
+	PagerResolved  = "resolved"
+)
+
+// PagerIncident is an incident opened with a paging provider (PagerDuty or
+// Opsgenie) for a service.
+type PagerIncident struct {
+	ID                int64
+	Provider          string // pagerduty or opsgenie
+	DedupKey          string // PagerDuty dedup_key or Opsgenie alias
+	Service           string
+	SessionID         *int64 // session that raised the incident
+	Summary           string
+	Status            string // PagerTriggered or PagerResolved
+	CreatedAt         string
+	ResolvedAt        *string
+	ResolvedSessionID *int64  // verification session that resolved it
+	IssueURL          *string // issue opened for it in the ticket project
+}
+
+const pagerColumns = `id, provider, dedup_key, service, session_id, summary, status, created_at, resolved_at, resolved_session_id, issue_url`
+
+// InsertPagerIncident records an incident opened with a paging provider.
+func (d *DB) InsertPagerIncident(p *PagerIncident) (int64, error) {
+	if p.Status == "" {
+		p.Status = PagerTriggered
+	}
+	res, err := d.conn.Exec(
+		`INSERT INTO pager_incidents (provider, dedup_key, service, session_id, summary, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		p.Provider, p.DedupKey, p.Service, p.SessionID, p.Summary, p.Status, p.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert pager incident: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("insert pager incident: %w", err)
+	}
+	p.ID = id
+	return id, nil
+}
+
+// ListOpenPagerIncidents returns the triggered incidents for a service,
+// oldest first.
+func (d *DB) ListOpenPagerIncidents(service string) ([]PagerIncident, error) {
+	return d.queryPagerIncidents(`WHERE service = ? AND status = ? ORDER BY created_at, id`, service, PagerTriggered)
+}
+
+// ListTriggeredPagerIncidents returns the triggered incidents for all
+// services, oldest first.
+func (d *DB) ListTriggeredPagerIncidents() ([]PagerIncident, error) {
+	return d.queryPagerIncidents(`WHERE status = ? ORDER BY created_at, id`, PagerTriggered)
+}
+
+// ListSessionPagerIncidents returns the incidents a session raised or
+// resolved, oldest first.
+func (d *DB) ListSessionPagerIncidents(sessionID int64) ([]PagerIncident, error) {
+	return d.queryPagerIncidents(`WHERE session_id = ? OR resolved_session_id = ? ORDER BY created_at, id`, sessionID, sessionID)
+}
+
+// ResolvePagerIncident marks an incident resolved by a verification session.
+func (d *DB) ResolvePagerIncident(id int64, resolvedAt string, sessionID *int64) error {
+	_, err := d.conn.Exec(
+		`UPDATE pager_incidents SET status = ?, resolved_at = ?, resolved_session_id = ? WHERE id = ?`,
+		PagerResolved, resolvedAt, sessionID, id,
+	)
+	if err != nil {
+		return fmt.Errorf("resolve pager incident %d: %w", id, err)
+	}
+	return nil
+}
+
+// SetPagerIncidentIssueURL records the issue opened for a service's open
+// incidents that do not have one yet.
+func (d *DB) SetPagerIncidentIssueURL(service, issueURL string) error {
+	_, err := d.conn.Exec(
+		`UPDATE pager_incidents SET issue_url = ? WHERE service = ? AND status = ? AND issue_url IS NULL`,
+		issueURL, service, PagerTriggered,
+	)
+	if err != nil {
+		return fmt.Errorf("set pager incident issue: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) queryPagerIncidents(query string, args ...any) ([]PagerIncident, error) {
+	rows, err := d.conn.Query(`SELECT `+pagerColumns+` FROM pager_incidents `+query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list pager incidents: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []PagerIncident
+	for rows.Next() {
+		var p PagerIncident
+		if err := rows.Scan(&p.ID, &p.Provider, &p.DedupKey, &p.Service, &p.SessionID, &p.Summary,
+			&p.Status, &p.CreatedAt, &p.ResolvedAt, &p.ResolvedSessionID, &p.IssueURL); err != nil {
+			return nil, fmt.Errorf("scan pager incident: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// --- Session Commit Methods ---
+
+// SessionCommit is a commit of the file changes a session left in a git
+// repository, made on a branch of its own.
+type SessionCommit struct {
+	ID         int64
+	SessionID  int64
+	Repo       string // repository path
+	Branch     string // claudeops/session-<id>
+	BaseBranch string // branch checked out when the changes were found
+	CommitSHA  string
+	DiffStat   string
+	Diff       string // possibly truncated
+	Pushed     bool
+	PRURL      *string
+	Error      *string // why pushing or opening the pull request failed
+	CreatedAt  string
+}
+
+const sessionCommitColumns = `id, session_id, repo, branch, base_branch, commit_sha, diff_stat, diff, pushed, pr_url, error, created_at`
+
+// InsertSessionCommit records a commit of a session's changes.
+func (d *DB) InsertSessionCommit(c *SessionCommit) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO session_commits (session_id, repo, branch, base_branch, commit_sha, diff_stat, diff, pushed, pr_url, error, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.SessionID, c.Repo, c.Branch, c.BaseBranch, c.CommitSHA, c.DiffStat, c.Diff, c.Pushed, c.PRURL, c.Error, c.CreatedAt,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("list service statuses: %w", err)
+		return 0, fmt.Errorf("insert session commit: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("insert session commit: %w", err)
+	}
+	c.ID = id
+	return id, nil
+}
+
+// UpdateSessionCommitPublish records the outcome of pushing a session
+// commit's branch and opening its pull request.
+func (d *DB) UpdateSessionCommitPublish(id int64, pushed bool, prURL, errMsg *string) error {
+	_, err := d.conn.Exec(`UPDATE session_commits SET pushed = ?, pr_url = ?, error = ? WHERE id = ?`, pushed, prURL, errMsg, id)
+	if err != nil {
+		return fmt.Errorf("update session commit %d: %w", id, err)
+	}
+	return nil
+}
+
+// ListSessionCommits returns the commits made for a session, in the order
+// they were made.
+func (d *DB) ListSessionCommits(sessionID int64) ([]SessionCommit, error) {
+	rows, err := d.conn.Query(`SELECT `+sessionCommitColumns+` FROM session_commits WHERE session_id = ? ORDER BY id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list session commits: %w", err)
 	}
 	defer rows.Close() //nolint:errcheck
 
-	var statuses []ServiceStatus
+	var out []SessionCommit
 	for rows.Next() {
-		var s ServiceStatus
-		if err := rows.Scan(&s.Service, &s.Status, &s.LastCheck, &s.CheckCount); err != nil {
-			return nil, fmt.Errorf("scan service status: %w", err)
+		var c SessionCommit
+		if err := rows.Scan(&c.ID, &c.SessionID, &c.Repo, &c.Branch, &c.BaseBranch, &c.CommitSHA, &c.DiffStat, &c.Diff,
+			&c.Pushed, &c.PRURL, &c.Error, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan session commit: %w", err)
 		}
-		statuses = append(statuses, s)
+		out = append(out, c)
 	}
-	return statuses, rows.Err()
+	return out, rows.Err()
 }
 
-// RecentCooldown represents an aggregated cooldown view for the dashboard.
-type RecentCooldown struct {
+// --- Session Ticket Methods ---
+
+// Session ticket sources.
+const (
+	TicketSourceAPI    = "api"    // linked through the API
+	TicketSourceMarker = "marker" // parsed from a [TICKET:provider:id] marker
+	TicketSourceAuto   = "auto"   // opened for an unresolved incident
+)
+
+// SessionTicket links a session to an issue in an external tracker.
+type SessionTicket struct {
+	ID        int64
+	SessionID int64
+	Provider  string // github, gitea, or jira
+	TicketID  string // owner/repo#123 for GitHub and Gitea, PROJ-123 for Jira
+	URL       string // empty when the tracker's base URL is not configured
+	Source    string
+	CreatedAt string
+}
+
+const sessionTicketColumns = `id, session_id, provider, ticket_id, url, source, created_at`
+
+// InsertSessionTicket links a session to a ticket. Linking the same ticket
+// twice is a no-op that returns false.
+func (d *DB) InsertSessionTicket(t *SessionTicket) (bool, error) {
+	res, err := d.conn.Exec(
+		`INSERT OR IGNORE INTO session_tickets (session_id, provider, ticket_id, url, source, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		t.SessionID, t.Provider, t.TicketID, t.URL, t.Source, t.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("insert session ticket: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	t.ID, err = res.LastInsertId()
+	return true, err
+}
+
+// ListSessionTickets returns the tickets a session is linked to, in the
+// order they were linked.
+func (d *DB) ListSessionTickets(sessionID int64) ([]SessionTicket, error) {
+	rows, err := d.conn.Query(`SELECT `+sessionTicketColumns+` FROM session_tickets WHERE session_id = ? ORDER BY id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list session tickets: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []SessionTicket
+	for rows.Next() {
+		var t SessionTicket
+		if err := rows.Scan(&t.ID, &t.SessionID, &t.Provider, &t.TicketID, &t.URL, &t.Source, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan session ticket: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// DeleteSessionTicket unlinks a ticket from a session, returning false if
+// the session has no such link.
+func (d *DB) DeleteSessionTicket(sessionID, id int64) (bool, error) {
+	res, err := d.conn.Exec(`DELETE FROM session_tickets WHERE id = ? AND session_id = ?`, id, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("delete session ticket %d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Service ticket statuses.
+const (
+	ServiceTicketOpen   = "open"
+	ServiceTicketClosed = "closed"
+)
+
+// ServiceTicket is an issue opened automatically for a service's
+// unresolved incident. It stays open until the service is found healthy.
+type ServiceTicket struct {
+	ID        int64
+	Service   string
+	Provider  string
+	TicketID  string
+	URL       string
+	Status    string
+	CreatedAt string
+	ClosedAt  *string
+}
+
+// InsertServiceTicket records an issue opened for a service.
+func (d *DB) InsertServiceTicket(t *ServiceTicket) (int64, error) {
+	if t.Status == "" {
+		t.Status = ServiceTicketOpen
+	}
+	res, err := d.conn.Exec(
+		`INSERT INTO service_tickets (service, provider, ticket_id, url, status, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		t.Service, t.Provider, t.TicketID, t.URL, t.Status, t.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert service ticket: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// HasOpenServiceTicket reports whether an issue opened for service is
+// still open.
+func (d *DB) HasOpenServiceTicket(service string) (bool, error) {
+	var n int
+	err := d.conn.QueryRow(
+		`SELECT COUNT(*) FROM service_tickets WHERE service = ? AND status = ?`, service, ServiceTicketOpen,
+	).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("open service tickets: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ListOpenServiceTickets returns the issues opened for services that are
+// still open, oldest first.
+func (d *DB) ListOpenServiceTickets() ([]ServiceTicket, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, service, provider, ticket_id, url, status, created_at, closed_at FROM service_tickets
+		 WHERE status = ? ORDER BY id`, ServiceTicketOpen)
+	if err != nil {
+		return nil, fmt.Errorf("list service tickets: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []ServiceTicket
+	for rows.Next() {
+		var t ServiceTicket
+		if err := rows.Scan(&t.ID, &t.Service, &t.Provider, &t.TicketID, &t.URL, &t.Status, &t.CreatedAt, &t.ClosedAt); err != nil {
+			return nil, fmt.Errorf("scan service ticket: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// CloseServiceTickets closes the open issues of a service found healthy.
+func (d *DB) CloseServiceTickets(service, closedAt string) error {
+	_, err := d.conn.Exec(
+		`UPDATE service_tickets SET status = ?, closed_at = ? WHERE service = ? AND status = ?`,
+		ServiceTicketClosed, closedAt, service, ServiceTicketOpen,
+	)
+	if err != nil {
+		return fmt.Errorf("close service tickets: %w", err)
+	}
+	return nil
+}
+
+// --- Change Report Methods ---
+
+// Change report statuses.
+const (
+	ChangesPending   = "pending"
+	ChangesApplied   = "applied"
+	ChangesDiscarded = "discarded"
+)
+
+// ChangeReport is the set of file changes a sandboxed session proposed.
+type ChangeReport struct {
+	ID        int64
+	SessionID int64
+	Status    string // ChangesPending, ChangesApplied, or ChangesDiscarded
+	CreatedAt string
+	DecidedAt *string
+	DecidedBy *string
+	Error     *string // why the last attempt to apply failed
+	Files     []ChangeFile
+}
+
+// ChangeFile is one file in a change report.
+type ChangeFile struct {
+	ID         int64
+	ReportID   int64
+	Path       string
+	Kind       string // added, modified, or deleted
+	Mode       uint32
+	OldContent []byte
+	NewContent []byte
+	Diff       string
+}
+
+const changeReportColumns = `id, session_id, status, created_at, decided_at, decided_by, error`
+
+// InsertChangeReport records a pending change report and its files.
+func (d *DB) InsertChangeReport(r *ChangeReport) (int64, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin change report: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.Exec(`INSERT INTO change_reports (session_id, status, created_at) VALUES (?, ?, ?)`, r.SessionID, ChangesPending, r.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("insert change report %d: %w", r.SessionID, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("insert change report %d: %w", r.SessionID, err)
+	}
+	for i := range r.Files {
+		f := &r.Files[i]
+		res, err := tx.Exec(
+			`INSERT INTO change_files (report_id, path, kind, mode, old_content, new_content, diff) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, f.Path, f.Kind, f.Mode, f.OldContent, f.NewContent, f.Diff,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("insert change file %s: %w", f.Path, err)
+		}
+		if f.ID, err = res.LastInsertId(); err != nil {
+			return 0, fmt.Errorf("insert change file %s: %w", f.Path, err)
+		}
+		f.ReportID = id
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit change report %d: %w", r.SessionID, err)
+	}
+	r.ID, r.Status = id, ChangesPending
+	return id, nil
+}
+
+// GetSessionChangeReport returns a session's change report with its files,
+// or nil if it has none.
+func (d *DB) GetSessionChangeReport(sessionID int64) (*ChangeReport, error) {
+	r := &ChangeReport{}
+	err := d.conn.QueryRow(`SELECT `+changeReportColumns+` FROM change_reports WHERE session_id = ?`, sessionID).
+		Scan(&r.ID, &r.SessionID, &r.Status, &r.CreatedAt, &r.DecidedAt, &r.DecidedBy, &r.Error)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("get change report %d: %w", sessionID, err)
+	}
+
+	rows, err := d.conn.Query(
+		`SELECT id, report_id, path, kind, mode, old_content, new_content, diff FROM change_files WHERE report_id = ? ORDER BY path`, r.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list change files: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+	for rows.Next() {
+		var f ChangeFile
+		if err := rows.Scan(&f.ID, &f.ReportID, &f.Path, &f.Kind, &f.Mode, &f.OldContent, &f.NewContent, &f.Diff); err != nil {
+			return nil, fmt.Errorf("scan change file: %w", err)
+		}
+		r.Files = append(r.Files, f)
+	}
+	return r, rows.Err()
+}
+
+// ListPendingChangeReports returns the change reports awaiting a decision,
+// oldest first, without their files.
+func (d *DB) ListPendingChangeReports() ([]ChangeReport, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+changeReportColumns+`, (SELECT COUNT(*) FROM change_files f WHERE f.report_id = r.id)
+		 FROM change_reports r WHERE status = ? ORDER BY created_at, id`, ChangesPending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list pending change reports: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []ChangeReport
+	for rows.Next() {
+		var r ChangeReport
+		var files int
+		if err := rows.Scan(&r.ID, &r.SessionID, &r.Status, &r.CreatedAt, &r.DecidedAt, &r.DecidedBy, &r.Error, &files); err != nil {
+			return nil, fmt.Errorf("scan change report: %w", err)
+		}
+		r.Files = make([]ChangeFile, files)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// DecideChangeReport moves a pending change report to status. Returns false
+// when it was no longer pending.
+func (d *DB) DecideChangeReport(id int64, status, decidedBy, decidedAt string) (bool, error) {
+	res, err := d.conn.Exec(
+		`UPDATE change_reports SET status = ?, decided_by = ?, decided_at = ?, error = NULL WHERE id = ? AND status = ?`,
+		status, decidedBy, decidedAt, id, ChangesPending,
+	)
+	if err != nil {
+		return false, fmt.Errorf("decide change report %d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("decide change report %d: %w", id, err)
+	}
+	return n == 1, nil
+}
+
+// ReopenChangeReport returns a change report whose changes could not be
+// applied to pending, recording why.
+func (d *DB) ReopenChangeReport(id int64, msg string) error {
+	if _, err := d.conn.Exec(
+		`UPDATE change_reports SET status = ?, decided_by = NULL, decided_at = NULL, error = ? WHERE id = ?`,
+		ChangesPending, msg, id,
+	); err != nil {
+		return fmt.Errorf("reopen change report %d: %w", id, err)
+	}
+	return nil
+}
+
+// SessionArtifacts are the stored diffs of a session's commits and the
+// contents of its decided change report's files, as offloaded to object
+// storage. A pending change report keeps its files, as applying it needs
+// them.
+type SessionArtifacts struct {
+	CommitDiffs map[int64]string       `json:"commit_diffs,omitempty"` // by session commit ID
+	Files       map[int64]ArtifactFile `json:"change_files,omitempty"` // by change file ID
+}
+
+// ArtifactFile is the stored content of one change file.
+type ArtifactFile struct {
+	OldContent []byte `json:"old_content,omitempty"`
+	NewContent []byte `json:"new_content,omitempty"`
+	Diff       string `json:"diff"`
+}
+
+// GetSessionArtifacts returns a session's stored diffs and decided change
+// report files.
+func (d *DB) GetSessionArtifacts(sessionID int64) (*SessionArtifacts, error) {
+	a := &SessionArtifacts{CommitDiffs: map[int64]string{}, Files: map[int64]ArtifactFile{}}
+	commits, err := d.ListSessionCommits(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range commits {
+		if c.Diff != "" {
+			a.CommitDiffs[c.ID] = c.Diff
+		}
+	}
+	report, err := d.GetSessionChangeReport(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if report != nil && report.Status != ChangesPending {
+		for _, f := range report.Files {
+			a.Files[f.ID] = ArtifactFile{OldContent: f.OldContent, NewContent: f.NewContent, Diff: f.Diff}
+		}
+	}
+	return a, nil
+}
+
+// OffloadSessionArtifacts records the object storage key a session's
+// artifacts were offloaded to and empties the columns they were stored in.
+// Change files are only emptied while their report is still decided.
+func (d *DB) OffloadSessionArtifacts(sessionID int64, key string, a *SessionArtifacts) error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin offload artifacts %d: %w", sessionID, err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(`UPDATE sessions SET artifacts_object = ? WHERE id = ?`, key, sessionID); err != nil {
+		return fmt.Errorf("set session artifacts object %d: %w", sessionID, err)
+	}
+	for id := range a.CommitDiffs {
+		if _, err := tx.Exec(`UPDATE session_commits SET diff = '' WHERE id = ? AND session_id = ?`, id, sessionID); err != nil {
+			return fmt.Errorf("offload session commit %d: %w", id, err)
+		}
+	}
+	for id := range a.Files {
+		if _, err := tx.Exec(
+			`UPDATE change_files SET old_content = NULL, new_content = NULL, diff = ''
+			 WHERE id = ? AND report_id IN (SELECT id FROM change_reports WHERE session_id = ? AND status != ?)`,
+			id, sessionID, ChangesPending,
+		); err != nil {
+			return fmt.Errorf("offload change file %d: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit offload artifacts %d: %w", sessionID, err)
+	}
+	return nil
+}
+
+// Restore fills in the diffs and change file contents that were offloaded
+// from commits and report.
+func (a *SessionArtifacts) Restore(commits []SessionCommit, report *ChangeReport) {
+	for i := range commits {
+		if diff, ok := a.CommitDiffs[commits[i].ID]; ok && commits[i].Diff == "" {
+			commits[i].Diff = diff
+		}
+	}
+	if report == nil {
+		return
+	}
+	for i := range report.Files {
+		f := &report.Files[i]
+		if af, ok := a.Files[f.ID]; ok && f.Diff == "" {
+			f.OldContent, f.NewContent, f.Diff = af.OldContent, af.NewContent, af.Diff
+		}
+	}
+}
+
+// --- Prompt Version Methods ---
+
+// PromptVersion is a tier prompt as saved from the dashboard's editor.
+type PromptVersion struct {
+	ID      int64
+	Path    string // configured prompt path the version belongs to
+	Content string
+	SavedBy *string
+	Note    *string // e.g. "restored from version 3"
+	SavedAt string
+}
+
+const promptVersionColumns = `id, path, content, saved_by, note, saved_at`
+
+func scanPromptVersion(scanner interface{ Scan(...any) error }, v *PromptVersion) error {
+	return scanner.Scan(&v.ID, &v.Path, &v.Content, &v.SavedBy, &v.Note, &v.SavedAt)
+}
+
+// InsertPromptVersion records a saved prompt.
+func (d *DB) InsertPromptVersion(v *PromptVersion) (int64, error) {
+	res, err := d.conn.Exec(
+		`INSERT INTO prompt_versions (path, content, saved_by, note, saved_at) VALUES (?, ?, ?, ?, ?)`,
+		v.Path, v.Content, v.SavedBy, v.Note, v.SavedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert prompt version: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("insert prompt version: %w", err)
+	}
+	v.ID = id
+	return id, nil
+}
+
+// GetPromptVersion returns a prompt version by ID, or nil if it does not
+// exist.
+func (d *DB) GetPromptVersion(id int64) (*PromptVersion, error) {
+	var v PromptVersion
+	err := scanPromptVersion(d.conn.QueryRow(`SELECT `+promptVersionColumns+` FROM prompt_versions WHERE id = ?`, id), &v)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get prompt version %d: %w", id, err)
+	}
+	return &v, nil
+}
+
+// LatestPromptVersion returns the newest version saved for path, or nil if
+// it was never edited.
+func (d *DB) LatestPromptVersion(path string) (*PromptVersion, error) {
+	var v PromptVersion
+	err := scanPromptVersion(d.conn.QueryRow(
+		`SELECT `+promptVersionColumns+` FROM prompt_versions WHERE path = ? ORDER BY id DESC LIMIT 1`, path), &v)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("latest prompt version: %w", err)
+	}
+	return &v, nil
+}
+
+// ListPromptVersions returns the versions saved for path, newest first.
+func (d *DB) ListPromptVersions(path string, limit int) ([]PromptVersion, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+promptVersionColumns+` FROM prompt_versions WHERE path = ? ORDER BY id DESC LIMIT ?`, path, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list prompt versions: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []PromptVersion
+	for rows.Next() {
+		var v PromptVersion
+		if err := scanPromptVersion(rows, &v); err != nil {
+			return nil, fmt.Errorf("scan prompt version: %w", err)
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// --- Memory Suggestion Methods ---
+
+// MemorySuggestion is a recurring event pattern suggested as a memory.
+type MemorySuggestion struct {
+	ID         int64
+	MemoryID   int64
 	Service    string
-	ActionType string
-	Count      int
-	LastAction string
+	Pattern    string // normalized message the events share
+	EventCount int
+	FirstSeen  string
+	LastSeen   string
+	CreatedAt  string
+}
+
+// InsertMemorySuggestion stores mem, unverified, for operator review along
+// with the suggestion it came from.
+func (d *DB) InsertMemorySuggestion(mem *Memory, sug *MemorySuggestion) (int64, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin memory suggestion: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.Exec(
+		`INSERT INTO memories (service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		mem.Service, mem.Category, mem.Observation, mem.Confidence, boolToInt(mem.Active), mem.CreatedAt, mem.UpdatedAt, mem.SessionID, mem.Tier, MemoryUnverified,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert suggested memory: %w", err)
+	}
+	memID, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("insert suggested memory: %w", err)
+	}
+	res, err = tx.Exec(
+		`INSERT INTO memory_suggestions (memory_id, service, pattern, event_count, first_seen, last_seen, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		memID, sug.Service, sug.Pattern, sug.EventCount, sug.FirstSeen, sug.LastSeen, sug.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert memory suggestion: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("insert memory suggestion: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit memory suggestion: %w", err)
+	}
+	mem.ID, mem.ReviewStatus = memID, MemoryUnverified
+	sug.ID, sug.MemoryID = id, memID
+	return id, nil
 }
 
-// ListRecentCooldowns returns cooldown action counts per service/action_type
-// within the given window.
-func (d *DB) ListRecentCooldowns(window time.Duration) ([]RecentCooldown, error) {
-	since := time.Now().UTC().Add(-window).Format(time.RFC3339)
-	rows, err := d.conn.Query(`
-		SELECT service, action_type, COUNT(*) AS cnt, MAX(timestamp) AS last_action
-		FROM cooldown_actions
-		WHERE timestamp > ?
-		GROUP BY service, action_type
-		ORDER BY last_action DESC`, since)
+// ListMemorySuggestions returns the patterns already suggested for service,
+// whatever became of their memories.
+func (d *DB) ListMemorySuggestions(service string) ([]MemorySuggestion, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, memory_id, service, pattern, event_count, first_seen, last_seen, created_at
+		 FROM memory_suggestions WHERE service = ? ORDER BY id`, service)
 	if err != nil {
-		return nil, fmt.Errorf("list recent cooldowns: %w", err)
+		return nil, fmt.Errorf("list memory suggestions: %w", err)
 	}
 	defer rows.Close() //nolint:errcheck
 
-	var cooldowns []RecentCooldown
+	var out []MemorySuggestion
 	for rows.Next() {
-		var c RecentCooldown
-		if err := rows.Scan(&c.Service, &c.ActionType, &c.Count, &c.LastAction); err != nil {
-			return nil, fmt.Errorf("scan cooldown: %w", err)
+		var sg MemorySuggestion
+		if err := rows.Scan(&sg.ID, &sg.MemoryID, &sg.Service, &sg.Pattern, &sg.EventCount, &sg.FirstSeen, &sg.LastSeen, &sg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan memory suggestion: %w", err)
 		}
-		cooldowns = append(cooldowns, c)
+		out = append(out, sg)
 	}
-	return cooldowns, rows.Err()
+	return out, rows.Err()
 }
 
-// LatestSession returns the most recent session, or nil if none exist.
-func (d *DB) LatestSession() (*Session, error) {
-	s := &Session{}
-	row := d.conn.QueryRow(`SELECT ` + sessionColumns + ` FROM sessions ORDER BY started_at DESC LIMIT 1`)
-	if err := scanSession(row, s); err == sql.ErrNoRows {
-		return nil, nil
-	} else if err != nil {
-		return nil, fmt.Errorf("latest session: %w", err)
+// SuggestionEventCounts returns, for each of the given memories that was
+// suggested from recurring events, how many events it came from.
+func (d *DB) SuggestionEventCounts(memoryIDs []int64) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	if len(memoryIDs) == 0 {
+		return counts, nil
 	}
-	return s, nil
-}
-
-// RunningSession returns the currently-running session, or nil if none is active.
-func (d *DB) RunningSession() (*Session, error) {
-	s := &Session{}
-	row := d.conn.QueryRow(`SELECT ` + sessionColumns + ` FROM sessions WHERE status = 'running' ORDER BY started_at DESC LIMIT 1`)
-	if err := scanSession(row, s); err == sql.ErrNoRows {
-		return nil, nil
-	} else if err != nil {
-		return nil, fmt.Errorf("running session: %w", err)
+	args := make([]any, len(memoryIDs))
+	for i, id := range memoryIDs {
+		args[i] = id
 	}
-	return s, nil
-}
-
-// GetEscalationChain walks parent_session_id links from the given session
-// to the root, then returns the chain ordered from root to leaf.
-// Governing: SPEC-0016 REQ "Database Schema for Escalation Chains" — full chain queryable
-func (d *DB) GetEscalationChain(sessionID int64) ([]Session, error) {
-	rows, err := d.conn.Query(`
-		WITH RECURSIVE chain(id) AS (
-			SELECT id FROM sessions WHERE id = ?
-			UNION ALL
-			SELECT s.parent_session_id FROM sessions s
-			JOIN chain c ON s.id = c.id
-			WHERE s.parent_session_id IS NOT NULL
-		)
-		SELECT `+sessionColumns+` FROM sessions
-		WHERE id IN (SELECT id FROM chain)
-		ORDER BY id ASC`, sessionID)
+	rows, err := d.conn.Query(
+		`SELECT memory_id, event_count FROM memory_suggestions
+		 WHERE memory_id IN (?`+strings.Repeat(", ?", len(memoryIDs)-1)+`)`, args...)
 	if err != nil {
-		return nil, fmt.Errorf("get escalation chain: %w", err)
+		return nil, fmt.Errorf("suggestion event counts: %w", err)
 	}
 	defer rows.Close() //nolint:errcheck
 
-	var sessions []Session
 	for rows.Next() {
-		var s Session
-		if err := scanSession(rows, &s); err != nil {
-			return nil, fmt.Errorf("scan escalation chain session: %w", err)
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scan suggestion event count: %w", err)
 		}
-		sessions = append(sessions, s)
+		counts[id] = n
 	}
-	return sessions, rows.Err()
+	return counts, rows.Err()
 }
 
-// GetChildSessions returns direct child sessions of the given session.
-// Governing: SPEC-0016 REQ "Dashboard Escalation Chain Display" — child link for session detail
-func (d *DB) GetChildSessions(sessionID int64) ([]Session, error) {
-	rows, err := d.conn.Query(
-		`SELECT `+sessionColumns+` FROM sessions WHERE parent_session_id = ? ORDER BY id ASC`, sessionID,
-	)
+// --- Batch Lookup Methods ---
+// These load the rows related to many records in one query, so an API that
+// walks relations (session → children → events) does not query per record.
+
+// idPlaceholders returns "?, ?, ..." for ids and the ids as query arguments.
+func idPlaceholders(ids []int64) (string, []any) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return "?" + strings.Repeat(", ?", len(ids)-1), args
+}
+
+// querySessions runs a query over sessionColumns.
+func (d *DB) querySessions(query string, args ...any) ([]Session, error) {
+	rows, err := d.conn.Query(`SELECT `+sessionColumns+` FROM sessions `+query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("get child sessions: %w", err)
+		return nil, err
 	}
 	defer rows.Close() //nolint:errcheck
 
 	var sessions []Session
 	for rows.Next() {
 		var s Session
-		if err := scanSession(rows, &s); err != nil {
-			return nil, fmt.Errorf("scan child session: %w", err)
+		if err := d.scanSession(rows, &s); err != nil {
+			return nil, err
 		}
 		sessions = append(sessions, s)
 	}
 	return sessions, rows.Err()
 }
 
-// --- Memory Methods ---
-
-// InsertMemory stores a memory record and returns its ID.
-func (d *DB) InsertMemory(m *Memory) (int64, error) {
-	res, err := d.conn.Exec(
-		`INSERT INTO memories (service, category, observation, confidence, active, created_at, updated_at, session_id, tier)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		m.Service, m.Category, m.Observation, m.Confidence, boolToInt(m.Active), m.CreatedAt, m.UpdatedAt, m.SessionID, m.Tier,
-	)
-	if err != nil {
-		return 0, fmt.Errorf("insert memory: %w", err)
-	}
-	return res.LastInsertId()
-}
-
-// GetMemory retrieves a single memory by ID.
-func (d *DB) GetMemory(id int64) (*Memory, error) {
-	m := &Memory{}
-	var active int
-	err := d.conn.QueryRow(
-		`SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier
-		 FROM memories WHERE id = ?`, id,
-	).Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier)
-	if err == sql.ErrNoRows {
+// GetSessionsByIDs returns the sessions with the given IDs, in ID order.
+// IDs with no session are skipped.
+func (d *DB) GetSessionsByIDs(ids []int64) ([]Session, error) {
+	if len(ids) == 0 {
 		return nil, nil
 	}
+	in, args := idPlaceholders(ids)
+	sessions, err := d.querySessions(`WHERE id IN (`+in+`) ORDER BY id`, args...)
 	if err != nil {
-		return nil, fmt.Errorf("get memory %d: %w", id, err)
+		return nil, fmt.Errorf("get sessions by IDs: %w", err)
 	}
-	m.Active = active == 1
-	return m, nil
+	return sessions, nil
 }
 
-// Governing: SPEC-0015 "Confidence Scoring" — confidence updates for reinforcement, contradiction, and operator edits
-// UpdateMemory updates a memory's observation, confidence, and active flag.
-func (d *DB) UpdateMemory(id int64, observation string, confidence float64, active bool) error {
-	_, err := d.conn.Exec(
-		`UPDATE memories SET observation = ?, confidence = ?, active = ?, updated_at = datetime('now') WHERE id = ?`,
-		observation, confidence, boolToInt(active), id,
-	)
-	if err != nil {
-		return fmt.Errorf("update memory %d: %w", id, err)
+// ListChildSessionsOf returns the sessions escalated from any of the given
+// sessions, in ID order.
+func (d *DB) ListChildSessionsOf(parentIDs []int64) ([]Session, error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
 	}
-	return nil
-}
-
-// DeleteMemory removes a memory by ID.
-func (d *DB) DeleteMemory(id int64) error {
-	_, err := d.conn.Exec(`DELETE FROM memories WHERE id = ?`, id)
+	in, args := idPlaceholders(parentIDs)
+	sessions, err := d.querySessions(`WHERE parent_session_id IN (`+in+`) ORDER BY id`, args...)
 	if err != nil {
-		return fmt.Errorf("delete memory %d: %w", id, err)
+		return nil, fmt.Errorf("list child sessions: %w", err)
 	}
-	return nil
+	return sessions, nil
 }
 
-// ListMemories returns memories with optional service and category filters,
-// ordered by confidence descending.
-func (d *DB) ListMemories(service *string, category *string, limit, offset int) ([]Memory, error) {
-	query := `SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier FROM memories WHERE 1=1`
-	var args []any
-
-	if service != nil {
-		query += ` AND service = ?`
-		args = append(args, *service)
-	}
-	if category != nil {
-		query += ` AND category = ?`
-		args = append(args, *category)
+// ListEventsForSessions returns the events recorded by any of the given
+// sessions, oldest first.
+func (d *DB) ListEventsForSessions(sessionIDs []int64) ([]Event, error) {
+	if len(sessionIDs) == 0 {
+		return nil, nil
 	}
-	query += ` ORDER BY confidence DESC LIMIT ? OFFSET ?`
-	args = append(args, limit, offset)
-
-	rows, err := d.conn.Query(query, args...)
+	in, args := idPlaceholders(sessionIDs)
+	rows, err := d.conn.Query(
+		`SELECT id, session_id, level, service, message, created_at FROM events
+		 WHERE session_id IN (`+in+`) ORDER BY created_at, id`, args...)
 	if err != nil {
-		return nil, fmt.Errorf("list memories: %w", err)
+		return nil, fmt.Errorf("list events for sessions: %w", err)
 	}
 	defer rows.Close() //nolint:errcheck
 
-	var memories []Memory
+	var events []Event
 	for rows.Next() {
-		var m Memory
-		var active int
-		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier); err != nil {
-			return nil, fmt.Errorf("scan memory: %w", err)
+		var e Event
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Level, &e.Service, &e.Message, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
 		}
-		m.Active = active == 1
-		memories = append(memories, m)
+		events = append(events, e)
 	}
-	return memories, rows.Err()
+	return events, rows.Err()
 }
 
-// GetActiveMemories returns active memories with confidence >= 0.3,
-// ordered by confidence descending.
-func (d *DB) GetActiveMemories(limit int) ([]Memory, error) {
+// ListMemoriesForSessions returns the memories, other than deleted ones,
+// recorded by any of the given sessions, in ID order.
+func (d *DB) ListMemoriesForSessions(sessionIDs []int64) ([]Memory, error) {
+	if len(sessionIDs) == 0 {
+		return nil, nil
+	}
+	in, args := idPlaceholders(sessionIDs)
 	rows, err := d.conn.Query(
-		`SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier
-		 FROM memories WHERE active = 1 AND confidence >= 0.3
-		 ORDER BY confidence DESC LIMIT ?`, limit,
-	)
+		`SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier, review_status, deleted_at, tombstoned_at, suppressed_count
+		 FROM memories WHERE deleted_at IS NULL AND session_id IN (`+in+`) ORDER BY id`, args...)
 	if err != nil {
-		return nil, fmt.Errorf("get active memories: %w", err)
+		return nil, fmt.Errorf("list memories for sessions: %w", err)
 	}
 	defer rows.Close() //nolint:errcheck
 
@@ -676,8 +4579,8 @@ func (d *DB) GetActiveMemories(limit int) ([]Memory, error) {
 	for rows.Next() {
 		var m Memory
 		var active int
-		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier); err != nil {
-			return nil, fmt.Errorf("scan active memory: %w", err)
+		if err := rows.Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier, &m.ReviewStatus, &m.DeletedAt, &m.TombstonedAt, &m.SuppressedCount); err != nil {
+			return nil, fmt.Errorf("scan memory: %w", err)
 		}
 		m.Active = active == 1
 		memories = append(memories, m)
@@ -685,132 +4588,180 @@ func (d *DB) GetActiveMemories(limit int) ([]Memory, error) {
 	return memories, rows.Err()
 }
 
-// Governing: SPEC-0015 "Memory Reinforcement", "Memory Contradiction" — finds match for reinforce/contradict logic
-// FindSimilarMemory finds an existing memory matching the given service and category.
-func (d *DB) FindSimilarMemory(service *string, category string) (*Memory, error) {
-	var query string
-	var args []any
-
-	if service != nil {
-		query = `SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier
-			 FROM memories WHERE service = ? AND category = ? ORDER BY confidence DESC LIMIT 1`
-		args = []any{*service, category}
-	} else {
-		query = `SELECT id, service, category, observation, confidence, active, created_at, updated_at, session_id, tier
-			 FROM memories WHERE service IS NULL AND category = ? ORDER BY confidence DESC LIMIT 1`
-		args = []any{category}
-	}
-
-	m := &Memory{}
-	var active int
-	err := d.conn.QueryRow(query, args...).Scan(&m.ID, &m.Service, &m.Category, &m.Observation, &m.Confidence, &active, &m.CreatedAt, &m.UpdatedAt, &m.SessionID, &m.Tier)
-	if err == sql.ErrNoRows {
+// ListCooldownActionsForSessions returns the remediation actions recorded
+// by any of the given sessions, oldest first.
+func (d *DB) ListCooldownActionsForSessions(sessionIDs []int64) ([]CooldownAction, error) {
+	if len(sessionIDs) == 0 {
 		return nil, nil
 	}
+	in, args := idPlaceholders(sessionIDs)
+	actions, err := d.queryCooldownActions(`WHERE session_id IN (`+in+`) ORDER BY timestamp, id`, args...)
 	if err != nil {
-		return nil, fmt.Errorf("find similar memory: %w", err)
+		return nil, fmt.Errorf("list cooldown actions for sessions: %w", err)
 	}
-	m.Active = active == 1
-	return m, nil
+	return actions, nil
 }
 
-// Governing: SPEC-0015 "Staleness Decay" — reduces confidence after grace period, deactivates below 0.3
-// DecayStaleMemories reduces confidence for memories not updated within graceDays,
-// then deactivates any that fall below 0.3.
-func (d *DB) DecayStaleMemories(graceDays int, decayRate float64) error {
-	cutoff := time.Now().UTC().AddDate(0, 0, -graceDays).Format(time.RFC3339)
-	_, err := d.conn.Exec(
-		`UPDATE memories SET confidence = confidence - ? WHERE active = 1 AND updated_at < ?`,
-		decayRate, cutoff,
-	)
-	if err != nil {
-		return fmt.Errorf("decay stale memories: %w", err)
-	}
+// --- Maintenance Methods ---
 
-	_, err = d.conn.Exec(`UPDATE memories SET active = 0 WHERE confidence < 0.3`)
-	if err != nil {
-		return fmt.Errorf("deactivate low-confidence memories: %w", err)
-	}
-	return nil
+// TableStats is the size of one table.
+type TableStats struct {
+	Name  string
+	Rows  int64
+	Bytes int64 // 0 when the dbstat table is unavailable
 }
 
-// UpdateSessionSummary stores an LLM-generated summary for a session.
-// Governing: SPEC-0021 REQ "Session Summary Generation"
-func (d *DB) UpdateSessionSummary(id int64, summary string) error {
-	_, err := d.conn.Exec(`UPDATE sessions SET summary = ? WHERE id = ?`, summary, id)
-	if err != nil {
-		return fmt.Errorf("update session summary %d: %w", id, err)
-	}
-	return nil
+// IndexStats is the size of one index.
+type IndexStats struct {
+	Name    string
+	Table   string
+	Columns string // comma-separated
+	Bytes   int64  // 0 when the dbstat table is unavailable
 }
 
-// DashboardStats holds aggregate metrics for the TL;DR dashboard HUD.
-// Governing: SPEC-0021 REQ "Dashboard Stats HUD"
-type DashboardStats struct {
-	TotalRuns      int
-	Escalations    int     // sessions where parent_session_id IS NOT NULL
-	Remediations   int     // sessions where tier = 3
-	SuccessRate    float64 // completed root sessions / total root sessions (0–1)
-	TotalCostUSD   float64 // SUM(cost_usd)
-	ActiveMemories int     // COUNT WHERE active=1
-	CriticalEvents int     // level='critical' in last 24h
-	AvgDurationMs  int64   // AVG(duration_ms) non-null sessions
+// Stats describes the database file and its tables and indexes.
+type Stats struct {
+	Path      string
+	FileSize  int64
+	WALSize   int64
+	PageSize  int64
+	PageCount int64
+	FreePages int64 // pages VACUUM would reclaim
+	Tables    []TableStats
+	Indexes   []IndexStats
 }
 
-// GetDashboardStats returns aggregate metrics for the TL;DR dashboard.
-// Governing: SPEC-0021 REQ "Dashboard Stats HUD"
-func (d *DB) GetDashboardStats() (*DashboardStats, error) {
-	s := &DashboardStats{}
+// MigrationRecord is one row of goose's version table.
+type MigrationRecord struct {
+	Version   int64
+	Applied   bool
+	AppliedAt string
+}
 
-	// 1. Total root sessions.
-	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM sessions WHERE parent_session_id IS NULL`).Scan(&s.TotalRuns); err != nil {
-		return nil, fmt.Errorf("dashboard stats total runs: %w", err)
+// Stats returns file sizes, page counts, per-table row counts, and
+// per-table and per-index sizes, largest first.
+func (d *DB) Stats() (*Stats, error) {
+	var st Stats
+	var seq int
+	var name string
+	if err := d.conn.QueryRow(`PRAGMA database_list`).Scan(&seq, &name, &st.Path); err != nil {
+		return nil, fmt.Errorf("database list: %w", err)
 	}
-
-	// 2. Escalations (child sessions).
-	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM sessions WHERE parent_session_id IS NOT NULL`).Scan(&s.Escalations); err != nil {
-		return nil, fmt.Errorf("dashboard stats escalations: %w", err)
+	if st.Path != "" {
+		if fi, err := os.Stat(st.Path); err == nil {
+			st.FileSize = fi.Size()
+		}
+		if fi, err := os.Stat(st.Path + "-wal"); err == nil {
+			st.WALSize = fi.Size()
+		}
+	}
+	for pragma, dst := range map[string]*int64{"page_size": &st.PageSize, "page_count": &st.PageCount, "freelist_count": &st.FreePages} {
+		if err := d.conn.QueryRow(`PRAGMA ` + pragma).Scan(dst); err != nil {
+			return nil, fmt.Errorf("pragma %s: %w", pragma, err)
+		}
 	}
 
-	// 3. Remediations (tier 3 sessions).
-	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM sessions WHERE tier = 3`).Scan(&s.Remediations); err != nil {
-		return nil, fmt.Errorf("dashboard stats remediations: %w", err)
+	sizes, err := d.objectSizes()
+	if err != nil {
+		return nil, err
 	}
 
-	// 4. Success rate: completed root / total root.
-	var completedRoots int
-	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM sessions WHERE status = 'completed' AND parent_session_id IS NULL`).Scan(&completedRoots); err != nil {
-		return nil, fmt.Errorf("dashboard stats completed roots: %w", err)
+	rows, err := d.conn.Query(
+		`SELECT type, name, tbl_name FROM sqlite_master
+		 WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list schema: %w", err)
 	}
-	if s.TotalRuns > 0 {
-		s.SuccessRate = float64(completedRoots) / float64(s.TotalRuns)
+	var objects [][3]string
+	for rows.Next() {
+		var o [3]string
+		if err := rows.Scan(&o[0], &o[1], &o[2]); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan schema: %w", err)
+		}
+		objects = append(objects, o)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list schema: %w", err)
 	}
 
-	// 5. Total cost and average duration.
-	var totalCost sql.NullFloat64
-	var avgDuration sql.NullFloat64
-	if err := d.conn.QueryRow(`SELECT COALESCE(SUM(cost_usd), 0), COALESCE(AVG(duration_ms), 0) FROM sessions`).Scan(&totalCost, &avgDuration); err != nil {
-		return nil, fmt.Errorf("dashboard stats cost/duration: %w", err)
+	for _, o := range objects {
+		typ, name, table := o[0], o[1], o[2]
+		if typ == "table" {
+			t := TableStats{Name: name, Bytes: sizes[name]}
+			if err := d.conn.QueryRow(`SELECT COUNT(*) FROM "` + strings.ReplaceAll(name, `"`, `""`) + `"`).Scan(&t.Rows); err != nil {
+				return nil, fmt.Errorf("count %s: %w", name, err)
+			}
+			st.Tables = append(st.Tables, t)
+			continue
+		}
+		ix := IndexStats{Name: name, Table: table, Bytes: sizes[name]}
+		if err := d.conn.QueryRow(`SELECT COALESCE(group_concat(name, ', '), '') FROM (SELECT name FROM pragma_index_info(?) ORDER BY seqno)`, name).Scan(&ix.Columns); err != nil {
+			return nil, fmt.Errorf("index info %s: %w", name, err)
+		}
+		st.Indexes = append(st.Indexes, ix)
 	}
-	s.TotalCostUSD = totalCost.Float64
-	s.AvgDurationMs = int64(avgDuration.Float64)
+	sort.SliceStable(st.Tables, func(i, j int) bool { return st.Tables[i].Bytes > st.Tables[j].Bytes })
+	sort.SliceStable(st.Indexes, func(i, j int) bool { return st.Indexes[i].Bytes > st.Indexes[j].Bytes })
+	return &st, nil
+}
 
-	// 6. Active memories.
-	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM memories WHERE active = 1`).Scan(&s.ActiveMemories); err != nil {
-		return nil, fmt.Errorf("dashboard stats active memories: %w", err)
+// objectSizes returns the bytes used by each table and index, from the
+// dbstat virtual table. It returns an empty map when dbstat is not compiled
+// in.
+func (d *DB) objectSizes() (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	rows, err := d.conn.Query(`SELECT name, SUM(pgsize) FROM dbstat GROUP BY name`)
+	if err != nil {
+		return sizes, nil
+	}
+	defer rows.Close() //nolint:errcheck
+	for rows.Next() {
+		var name string
+		var n int64
+		if err := rows.Scan(&name, &n); err != nil {
+			return nil, fmt.Errorf("scan dbstat: %w", err)
+		}
+		sizes[name] = n
 	}
+	return sizes, rows.Err()
+}
 
-	// 7. Critical events in last 24h.
-	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM events WHERE level = 'critical' AND created_at > datetime('now', '-1 day')`).Scan(&s.CriticalEvents); err != nil {
-		return nil, fmt.Errorf("dashboard stats critical events: %w", err)
+// ListMigrations returns goose's migration history, oldest first.
+func (d *DB) ListMigrations() ([]MigrationRecord, error) {
+	rows, err := d.conn.Query(`SELECT version_id, is_applied, tstamp FROM goose_db_version WHERE version_id > 0 ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
 	}
+	defer rows.Close() //nolint:errcheck
 
-	return s, nil
+	var out []MigrationRecord
+	for rows.Next() {
+		var m MigrationRecord
+		var ts any
+		if err := rows.Scan(&m.Version, &m.Applied, &ts); err != nil {
+			return nil, fmt.Errorf("scan migration: %w", err)
+		}
+		switch v := ts.(type) {
+		case time.Time:
+			m.AppliedAt = v.UTC().Format(time.RFC3339)
+		case string:
+			m.AppliedAt = v
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
 }
 
-func boolToInt(b bool) int {
-	if b {
-		return 1
+// Vacuum rebuilds the database file to reclaim free pages, then truncates
+// the write-ahead log.
+func (d *DB) Vacuum() error {
+	if _, err := d.conn.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
 	}
-	return 0
+	if _, err := d.conn.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("wal checkpoint: %w", err)
+	}
+	return nil
 }
@@ -4,9 +4,15 @@ package db
 // All tests in this file must pass without modification after goose adoption.
 
 import (
+	"fmt"
+	"math"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/joestump/claude-ops/internal/seal"
 )
 
 func openTestDB(t *testing.T) *DB {
@@ -693,6 +699,73 @@ func TestDeleteMemory(t *testing.T) {
 	}
 }
 
+func TestMemoryTombstones(t *testing.T) {
+	d := openTestDB(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	svc := "nginx"
+
+	deleted, _ := d.InsertMemory(&Memory{Service: &svc, Category: "behavior", Observation: "restart fixes 502s", Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1})
+	rejected, _ := d.InsertMemory(&Memory{Service: &svc, Category: "timing", Observation: "slow on Mondays", Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1, ReviewStatus: MemoryUnverified})
+	_, _ = d.InsertMemory(&Memory{Service: &svc, Category: "dependency", Observation: "needs php-fpm", Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1})
+
+	if err := d.DeleteMemory(deleted); err != nil {
+		t.Fatalf("DeleteMemory: %v", err)
+	}
+	if err := d.SetMemoryReviewStatus(rejected, MemoryRejected); err != nil {
+		t.Fatalf("SetMemoryReviewStatus: %v", err)
+	}
+
+	// Soft-deleted memories drop out of the normal list; rejected ones stay visible.
+	all, _ := d.ListMemories(&svc, nil, nil, 100, 0)
+	if len(all) != 2 {
+		t.Fatalf("expected 2 listed memories, got %d", len(all))
+	}
+
+	since := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	tombs, err := d.FindTombstones(&svc, since)
+	if err != nil {
+		t.Fatalf("FindTombstones: %v", err)
+	}
+	if len(tombs) != 2 {
+		t.Fatalf("expected 2 tombstones, got %d", len(tombs))
+	}
+	future := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	if tombs, _ := d.FindTombstones(&svc, future); len(tombs) != 0 {
+		t.Fatalf("expected expired tombstones to be ignored, got %d", len(tombs))
+	}
+
+	if err := d.IncrementMemorySuppressed(deleted); err != nil {
+		t.Fatalf("IncrementMemorySuppressed: %v", err)
+	}
+	listed, err := d.ListTombstonedMemories(10)
+	if err != nil {
+		t.Fatalf("ListTombstonedMemories: %v", err)
+	}
+	if len(listed) != 2 {
+		t.Fatalf("expected 2 tombstoned memories, got %d", len(listed))
+	}
+	for _, m := range listed {
+		if m.ID == deleted && (m.DeletedAt == nil || m.SuppressedCount != 1) {
+			t.Errorf("unexpected deleted tombstone %+v", m)
+		}
+	}
+
+	if err := d.RestoreMemory(deleted); err != nil {
+		t.Fatalf("RestoreMemory: %v", err)
+	}
+	m, _ := d.GetMemory(deleted)
+	if m == nil || !m.Active || m.ReviewStatus != MemoryUnverified || m.TombstonedAt != nil {
+		t.Fatalf("expected restored memory back in review queue, got %+v", m)
+	}
+
+	if err := d.PurgeMemory(rejected); err != nil {
+		t.Fatalf("PurgeMemory: %v", err)
+	}
+	if listed, _ := d.ListTombstonedMemories(10); len(listed) != 0 {
+		t.Fatalf("expected no tombstones after restore and purge, got %d", len(listed))
+	}
+}
+
 func TestListMemoriesWithFilters(t *testing.T) {
 	d := openTestDB(t)
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -712,7 +785,7 @@ func TestListMemoriesWithFilters(t *testing.T) {
 	_, _ = d.InsertMemory(&Memory{Category: "remediation", Observation: "obs4", Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 3})
 
 	// No filters — all 4.
-	all, err := d.ListMemories(nil, nil, 100, 0)
+	all, err := d.ListMemories(nil, nil, nil, 100, 0)
 	if err != nil {
 		t.Fatalf("ListMemories (no filters): %v", err)
 	}
@@ -725,7 +798,7 @@ func TestListMemoriesWithFilters(t *testing.T) {
 	}
 
 	// Filter by service.
-	byService, err := d.ListMemories(&svc1, nil, 100, 0)
+	byService, err := d.ListMemories(&svc1, nil, nil, 100, 0)
 	if err != nil {
 		t.Fatalf("ListMemories (service filter): %v", err)
 	}
@@ -735,7 +808,7 @@ func TestListMemoriesWithFilters(t *testing.T) {
 
 	// Filter by category.
 	cat := "timing"
-	byCat, err := d.ListMemories(nil, &cat, 100, 0)
+	byCat, err := d.ListMemories(nil, &cat, nil, 100, 0)
 	if err != nil {
 		t.Fatalf("ListMemories (category filter): %v", err)
 	}
@@ -744,7 +817,7 @@ func TestListMemoriesWithFilters(t *testing.T) {
 	}
 
 	// Both filters.
-	both, err := d.ListMemories(&svc1, &cat, 100, 0)
+	both, err := d.ListMemories(&svc1, &cat, nil, 100, 0)
 	if err != nil {
 		t.Fatalf("ListMemories (both filters): %v", err)
 	}
@@ -753,7 +826,7 @@ func TestListMemoriesWithFilters(t *testing.T) {
 	}
 
 	// Limit.
-	limited, err := d.ListMemories(nil, nil, 2, 0)
+	limited, err := d.ListMemories(nil, nil, nil, 2, 0)
 	if err != nil {
 		t.Fatalf("ListMemories (limit): %v", err)
 	}
@@ -776,7 +849,7 @@ func TestGetActiveMemories(t *testing.T) {
 	// Exactly at threshold — included.
 	_, _ = d.InsertMemory(&Memory{Category: "remediation", Observation: "borderline", Confidence: 0.3, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 3})
 
-	active, err := d.GetActiveMemories(100)
+	active, err := d.GetActiveMemories(100, false)
 	if err != nil {
 		t.Fatalf("GetActiveMemories: %v", err)
 	}
@@ -792,6 +865,61 @@ func TestGetActiveMemories(t *testing.T) {
 	}
 }
 
+func TestMemoryReviewStatus(t *testing.T) {
+	d := openTestDB(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	svc := "caddy"
+
+	// Operator-created memories (empty status) default to verified.
+	operator, _ := d.InsertMemory(&Memory{Service: &svc, Category: "behavior", Observation: "operator note", Confidence: 0.5, Active: true, CreatedAt: now, UpdatedAt: now})
+	agent, _ := d.InsertMemory(&Memory{Service: &svc, Category: "timing", Observation: "agent guess", Confidence: 0.9, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1, ReviewStatus: MemoryUnverified})
+	wrong, _ := d.InsertMemory(&Memory{Service: &svc, Category: "dependency", Observation: "wrong guess", Confidence: 0.8, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1, ReviewStatus: MemoryUnverified})
+
+	m, err := d.GetMemory(operator)
+	if err != nil || m == nil {
+		t.Fatalf("GetMemory: %v", err)
+	}
+	if m.ReviewStatus != MemoryVerified {
+		t.Errorf("expected operator memory to be verified, got %q", m.ReviewStatus)
+	}
+
+	if err := d.SetMemoryReviewStatus(wrong, MemoryRejected); err != nil {
+		t.Fatalf("SetMemoryReviewStatus: %v", err)
+	}
+	m, _ = d.GetMemory(wrong)
+	if m.ReviewStatus != MemoryRejected || m.Active {
+		t.Errorf("expected rejected memory to be inactive, got status=%q active=%v", m.ReviewStatus, m.Active)
+	}
+	if err := d.SetMemoryReviewStatus(wrong, "maybe"); err == nil {
+		t.Error("expected error for invalid review status")
+	}
+
+	// Verified memories sort ahead of higher-confidence unverified ones.
+	all, err := d.GetActiveMemories(100, false)
+	if err != nil {
+		t.Fatalf("GetActiveMemories: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != operator || all[1].ID != agent {
+		t.Fatalf("expected [operator, agent], got %+v", all)
+	}
+	verified, err := d.GetActiveMemories(100, true)
+	if err != nil {
+		t.Fatalf("GetActiveMemories verified only: %v", err)
+	}
+	if len(verified) != 1 || verified[0].ID != operator {
+		t.Fatalf("expected only the operator memory, got %+v", verified)
+	}
+
+	unverified := MemoryUnverified
+	pending, err := d.ListMemories(nil, nil, &unverified, 100, 0)
+	if err != nil {
+		t.Fatalf("ListMemories: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != agent {
+		t.Fatalf("expected only the agent memory pending review, got %+v", pending)
+	}
+}
+
 func TestFindSimilarMemory(t *testing.T) {
 	d := openTestDB(t)
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -894,6 +1022,51 @@ func TestUpdateSessionSummary(t *testing.T) {
 	}
 }
 
+func TestUpdateSessionClient(t *testing.T) {
+	d := openTestDB(t)
+
+	id, err := d.InsertSession(&Session{
+		Tier:       1,
+		Model:      "haiku",
+		PromptFile: "/tmp/test.md",
+		Status:     "running",
+		StartedAt:  time.Now().UTC().Format(time.RFC3339),
+		Trigger:    "api",
+	})
+	if err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	user := "alice"
+	meta := `{"client":"open-webui","conversation_id":"c-42"}`
+	if err := d.UpdateSessionClient(id, &user, &meta); err != nil {
+		t.Fatalf("UpdateSessionClient: %v", err)
+	}
+
+	s, err := d.GetSession(id)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if s.ClientUser == nil || *s.ClientUser != user {
+		t.Fatalf("expected client user %q, got %v", user, s.ClientUser)
+	}
+	if s.ClientMetadata == nil || *s.ClientMetadata != meta {
+		t.Fatalf("expected client metadata %q, got %v", meta, s.ClientMetadata)
+	}
+
+	// Metadata alone, without a user.
+	if err := d.UpdateSessionClient(id, nil, &meta); err != nil {
+		t.Fatalf("UpdateSessionClient without user: %v", err)
+	}
+	s, err = d.GetSession(id)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if s.ClientUser != nil {
+		t.Fatalf("expected nil client user, got %q", *s.ClientUser)
+	}
+}
+
 func TestGetDashboardStats(t *testing.T) {
 	d := openTestDB(t)
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -984,7 +1157,7 @@ func TestDecayStaleMemories(t *testing.T) {
 	}
 
 	// Stale memory: 0.5 - 0.1 = 0.4, still active.
-	all, err := d.ListMemories(nil, nil, 100, 0)
+	all, err := d.ListMemories(nil, nil, nil, 100, 0)
 	if err != nil {
 		t.Fatalf("ListMemories: %v", err)
 	}
@@ -1028,3 +1201,1386 @@ func TestDecayStaleMemories(t *testing.T) {
 		t.Fatalf("expected stale memory deactivated after dropping below 0.3, confidence=%f", stale2.Confidence)
 	}
 }
+
+func TestKBArticles(t *testing.T) {
+	d := openTestDB(t)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, a := range []*KBArticle{
+		{Service: "jellyfin", Content: "# v1", MemoryCount: 2, SourceHash: "a", CreatedAt: now},
+		{Service: "jellyfin", Content: "# v2", MemoryCount: 3, SourceHash: "b", CreatedAt: now},
+		{Service: "caddy", Content: "# caddy", MemoryCount: 1, SourceHash: "c", CreatedAt: now},
+	} {
+		if _, err := d.InsertKBArticle(a); err != nil {
+			t.Fatalf("InsertKBArticle: %v", err)
+		}
+		if a.Service == "jellyfin" && a.Content == "# v2" && a.Version != 2 {
+			t.Fatalf("expected version 2, got %d", a.Version)
+		}
+	}
+
+	latest, err := d.GetKBArticle("jellyfin", 0)
+	if err != nil || latest == nil || latest.Version != 2 || latest.Content != "# v2" {
+		t.Fatalf("expected latest jellyfin v2, got %+v (err %v)", latest, err)
+	}
+	v1, err := d.GetKBArticle("jellyfin", 1)
+	if err != nil || v1 == nil || v1.Content != "# v1" {
+		t.Fatalf("expected jellyfin v1, got %+v (err %v)", v1, err)
+	}
+	if missing, _ := d.GetKBArticle("plex", 0); missing != nil {
+		t.Fatalf("expected nil for unknown service, got %+v", missing)
+	}
+
+	all, err := d.ListKBArticles()
+	if err != nil {
+		t.Fatalf("ListKBArticles: %v", err)
+	}
+	if len(all) != 2 || all[0].Service != "caddy" || all[1].Version != 2 {
+		t.Fatalf("expected latest caddy and jellyfin articles, got %+v", all)
+	}
+
+	versions, err := d.ListKBArticleVersions("jellyfin")
+	if err != nil {
+		t.Fatalf("ListKBArticleVersions: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 {
+		t.Fatalf("expected 2 versions newest first, got %+v", versions)
+	}
+}
+
+func TestDrills(t *testing.T) {
+	d := openTestDB(t)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if running, err := d.GetRunningDrill(); err != nil || running != nil {
+		t.Fatalf("expected no running drill, got %+v (err %v)", running, err)
+	}
+	id, err := d.InsertDrill(&Drill{Status: "running", StartedAt: now})
+	if err != nil {
+		t.Fatalf("InsertDrill: %v", err)
+	}
+
+	running, err := d.GetRunningDrill()
+	if err != nil || running == nil || running.ID != id {
+		t.Fatalf("expected running drill %d, got %+v (err %v)", id, running, err)
+	}
+	running.Remediated = true
+	running.Notified = true
+	running.MaxTier = 2
+	running.Status = "passed"
+	running.EndedAt = &now
+	if err := d.UpdateDrill(running); err != nil {
+		t.Fatalf("UpdateDrill: %v", err)
+	}
+
+	got, err := d.GetDrill(id)
+	if err != nil || got == nil {
+		t.Fatalf("GetDrill: %+v %v", got, err)
+	}
+	if got.Status != "passed" || !got.Remediated || !got.Notified || got.Detected || got.MaxTier != 2 || got.EndedAt == nil {
+		t.Errorf("unexpected drill %+v", got)
+	}
+	if running, _ := d.GetRunningDrill(); running != nil {
+		t.Errorf("expected no running drill after it finished, got %+v", running)
+	}
+
+	drills, err := d.ListDrills(10)
+	if err != nil || len(drills) != 1 {
+		t.Fatalf("ListDrills: %+v %v", drills, err)
+	}
+}
+
+func TestGetSessionCostStats(t *testing.T) {
+	d := openTestDB(t)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	prompt := "Jellyfin is down"
+	for _, s := range []struct {
+		tier   int
+		model  string
+		cost   float64
+		ms     int64
+		prompt *string
+	}{
+		{2, "sonnet", 0.40, 60000, &prompt},
+		{2, "sonnet", 0.20, 30000, nil},
+		{2, "opus", 2.00, 90000, nil},
+		{1, "haiku", 0.01, 5000, nil},
+	} {
+		id, err := d.InsertSession(&Session{Tier: s.tier, Model: s.model, PromptFile: "/dev/null", Status: "running", StartedAt: now, PromptText: s.prompt})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		if err := d.UpdateSessionResult(id, "done", s.cost, 3, s.ms); err != nil {
+			t.Fatalf("UpdateSessionResult: %v", err)
+		}
+		if err := d.UpdateSession(id, "completed", &now, nil, nil); err != nil {
+			t.Fatalf("UpdateSession: %v", err)
+		}
+	}
+	// A running session has no final cost and is ignored.
+	if _, err := d.InsertSession(&Session{Tier: 2, Model: "sonnet", PromptFile: "/dev/null", Status: "running", StartedAt: now}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	st, err := d.GetSessionCostStats(2, "sonnet", 50)
+	if err != nil {
+		t.Fatalf("GetSessionCostStats: %v", err)
+	}
+	if st.Samples != 2 || st.AvgCostUSD < 0.299 || st.AvgCostUSD > 0.301 || st.AvgDurationMs != 45000 || st.AvgPromptChars != float64(len(prompt)) {
+		t.Errorf("unexpected stats %+v", st)
+	}
+
+	if st, _ := d.GetSessionCostStats(2, "", 50); st.Samples != 3 {
+		t.Errorf("expected 3 tier 2 samples for any model, got %+v", st)
+	}
+	if st, _ := d.GetSessionCostStats(3, "", 50); st.Samples != 0 || st.AvgCostUSD != 0 {
+		t.Errorf("expected empty stats for tier 3, got %+v", st)
+	}
+}
+
+func TestPromptHistory(t *testing.T) {
+	d := openTestDB(t)
+
+	if err := d.RecordPrompt("check jellyfin", false, 2); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+	if err := d.RecordPrompt("restart caddy", true, 2); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+	if err := d.RecordPrompt("check jellyfin", false, 2); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+
+	prompts, err := d.ListPromptHistory(10)
+	if err != nil || len(prompts) != 2 {
+		t.Fatalf("ListPromptHistory: %+v %v", prompts, err)
+	}
+	if prompts[0].Prompt != "restart caddy" || !prompts[0].Favorite {
+		t.Errorf("expected favorite first, got %+v", prompts[0])
+	}
+	if prompts[1].Prompt != "check jellyfin" || prompts[1].UseCount != 2 {
+		t.Errorf("expected repeated prompt to be counted, got %+v", prompts[1])
+	}
+
+	// Re-running a favorite without the flag keeps it a favorite.
+	if err := d.RecordPrompt("restart caddy", false, 2); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+	// Only two non-favorites are kept.
+	for _, p := range []string{"check sonarr", "check radarr"} {
+		if err := d.RecordPrompt(p, false, 2); err != nil {
+			t.Fatalf("RecordPrompt: %v", err)
+		}
+	}
+	prompts, _ = d.ListPromptHistory(10)
+	if len(prompts) != 3 || !prompts[0].Favorite {
+		t.Fatalf("expected favorite plus two recent prompts, got %+v", prompts)
+	}
+	for _, p := range prompts {
+		if p.Prompt == "check jellyfin" {
+			t.Errorf("expected oldest non-favorite to be pruned, got %+v", prompts)
+		}
+	}
+
+	fav := prompts[0]
+	if err := d.SetPromptFavorite(fav.ID, false); err != nil {
+		t.Fatalf("SetPromptFavorite: %v", err)
+	}
+	if got, _ := d.GetPromptHistory(fav.ID); got == nil || got.Favorite {
+		t.Errorf("expected favorite cleared, got %+v", got)
+	}
+	if err := d.DeletePromptHistory(fav.ID); err != nil {
+		t.Fatalf("DeletePromptHistory: %v", err)
+	}
+	if got, err := d.GetPromptHistory(fav.ID); err != nil || got != nil {
+		t.Errorf("expected deleted prompt to be gone, got %+v (err %v)", got, err)
+	}
+}
+
+func TestEventFilters(t *testing.T) {
+	d := openTestDB(t)
+	now := time.Now().UTC()
+	old := now.Add(-48 * time.Hour).Format(time.RFC3339)
+	recent := now.Format(time.RFC3339)
+	caddy, jellyfin := "caddy", "jellyfin"
+
+	for _, e := range []Event{
+		{Level: "critical", Service: &caddy, Message: "caddy down", CreatedAt: old},
+		{Level: "info", Service: &caddy, Message: "caddy healthy", CreatedAt: recent},
+		{Level: "warning", Service: &jellyfin, Message: "jellyfin slow", CreatedAt: recent},
+		{Level: "info", Message: "run complete", CreatedAt: recent},
+	} {
+		if _, err := d.InsertEvent(&e); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	since := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	events, err := d.ListEvents(10, 0, EventFilter{Service: &caddy, Since: since})
+	if err != nil || len(events) != 1 || events[0].Message != "caddy healthy" {
+		t.Fatalf("expected recent caddy event, got %+v (err %v)", events, err)
+	}
+	if events, _ := d.ListEvents(10, 0, EventFilter{Until: since}); len(events) != 1 || events[0].Message != "caddy down" {
+		t.Errorf("expected old event only, got %+v", events)
+	}
+
+	info := "info"
+	counts, err := d.CountEventsByLevel(EventFilter{Level: &info, Since: since})
+	if err != nil {
+		t.Fatalf("CountEventsByLevel: %v", err)
+	}
+	if counts["info"] != 2 || counts["warning"] != 1 || counts["critical"] != 0 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+
+	services, err := d.ListEventServices()
+	if err != nil || len(services) != 2 || services[0] != "caddy" || services[1] != "jellyfin" {
+		t.Errorf("ListEventServices = %v (err %v)", services, err)
+	}
+}
+
+func TestGetDailyActivity(t *testing.T) {
+	d := openTestDB(t)
+	cost := func(f float64) *float64 { return &f }
+
+	for _, s := range []Session{
+		{Tier: 1, Model: "haiku", PromptFile: "p", Status: "completed", StartedAt: "2026-10-01T08:00:00Z", CostUSD: cost(0.10)},
+		{Tier: 2, Model: "sonnet", PromptFile: "p", Status: "completed", StartedAt: "2026-10-01T09:00:00Z", CostUSD: cost(0.50)},
+		{Tier: 1, Model: "haiku", PromptFile: "p", Status: "completed", StartedAt: "2026-10-03T08:00:00Z"},
+		{Tier: 1, Model: "haiku", PromptFile: "p", Status: "completed", StartedAt: "2026-09-01T08:00:00Z"},
+	} {
+		id, err := d.InsertSession(&s)
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		if s.CostUSD != nil {
+			if err := d.UpdateSessionResult(id, "done", *s.CostUSD, 1, 1000); err != nil {
+				t.Fatalf("UpdateSessionResult: %v", err)
+			}
+		}
+	}
+	for _, e := range []Event{
+		{Level: "info", Message: "ok", CreatedAt: "2026-10-01T08:01:00Z"},
+		{Level: "critical", Message: "down", CreatedAt: "2026-10-01T08:02:00Z"},
+		{Level: "warning", Message: "slow", CreatedAt: "2026-10-02T08:00:00Z"},
+	} {
+		if _, err := d.InsertEvent(&e); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	days, err := d.GetDailyActivity("2026-09-15T00:00:00Z", "2026-10-04T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetDailyActivity: %v", err)
+	}
+	want := []DailyActivity{
+		{Day: "2026-10-01", Sessions: 2, CostUSD: 0.60, MaxLevel: "critical"},
+		{Day: "2026-10-02", MaxLevel: "warning"},
+		{Day: "2026-10-03", Sessions: 1},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("GetDailyActivity = %+v, want %+v", days, want)
+	}
+	for i, w := range want {
+		got := days[i]
+		if got.Day != w.Day || got.Sessions != w.Sessions || got.MaxLevel != w.MaxLevel || math.Abs(got.CostUSD-w.CostUSD) > 1e-9 {
+			t.Errorf("day %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	sessions, err := d.ListSessionsBetween("2026-10-01T00:00:00Z", "2026-10-02T00:00:00Z")
+	if err != nil || len(sessions) != 2 || sessions[0].Tier != 2 {
+		t.Errorf("ListSessionsBetween = %+v (err %v)", sessions, err)
+	}
+}
+
+func TestStreamDiagnostics(t *testing.T) {
+	d := openTestDB(t)
+	for _, sd := range []StreamDiagnostic{
+		{SessionID: 1, EventType: "(unparseable)", Count: 1, Sample: "oops", CreatedAt: "2026-10-01T00:00:00Z"},
+		{SessionID: 1, EventType: "assistant_delta", Count: 12, Sample: `{"type":"assistant_delta"}`, CreatedAt: "2026-10-01T00:00:00Z"},
+		{SessionID: 2, EventType: "other", Count: 3, CreatedAt: "2026-10-01T00:00:00Z"},
+	} {
+		if _, err := d.InsertStreamDiagnostic(&sd); err != nil {
+			t.Fatalf("InsertStreamDiagnostic: %v", err)
+		}
+	}
+
+	diags, err := d.ListStreamDiagnostics(1)
+	if err != nil {
+		t.Fatalf("ListStreamDiagnostics: %v", err)
+	}
+	if len(diags) != 2 || diags[0].EventType != "assistant_delta" || diags[0].Count != 12 {
+		t.Errorf("ListStreamDiagnostics = %+v", diags)
+	}
+	if diags, _ := d.ListStreamDiagnostics(3); len(diags) != 0 {
+		t.Errorf("expected no diagnostics for session 3, got %+v", diags)
+	}
+}
+
+func TestSessionRedactions(t *testing.T) {
+	d := openTestDB(t)
+	now := time.Now().UTC()
+	for _, sr := range []SessionRedaction{
+		{SessionID: 1, Rule: "BROWSER_CRED_SONARR_PASS", Count: 2, CreatedAt: now.Format(time.RFC3339)},
+		{SessionID: 1, Rule: "BROWSER_CRED_SONARR_PASS:urlencoded", Count: 5, CreatedAt: now.Format(time.RFC3339)},
+		{SessionID: 2, Rule: "BROWSER_CRED_RADARR_PASS", Count: 4, CreatedAt: now.Add(-48 * time.Hour).Format(time.RFC3339)},
+	} {
+		if _, err := d.InsertSessionRedaction(&sr); err != nil {
+			t.Fatalf("InsertSessionRedaction: %v", err)
+		}
+	}
+
+	redactions, err := d.ListSessionRedactions(1)
+	if err != nil {
+		t.Fatalf("ListSessionRedactions: %v", err)
+	}
+	if len(redactions) != 2 || redactions[0].Rule != "BROWSER_CRED_SONARR_PASS:urlencoded" || redactions[0].Count != 5 {
+		t.Errorf("ListSessionRedactions = %+v", redactions)
+	}
+	counts, err := d.CountSessionRedactions([]int64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("CountSessionRedactions: %v", err)
+	}
+	if counts[1] != 7 || counts[2] != 4 || counts[3] != 0 {
+		t.Errorf("CountSessionRedactions = %v", counts)
+	}
+	stats, err := d.GetDashboardStats()
+	if err != nil {
+		t.Fatalf("GetDashboardStats: %v", err)
+	}
+	if stats.Redactions != 7 {
+		t.Errorf("Redactions = %d, want 7 (last 24h only)", stats.Redactions)
+	}
+}
+
+func TestPolicyEvaluations(t *testing.T) {
+	d := openTestDB(t)
+	for _, pe := range []PolicyEvaluation{
+		{SessionID: 1, Point: "escalation", Rule: "no-night-tier3", Matched: true, Action: "cap", Detail: "tier 3 capped to 2", CreatedAt: "2026-10-01T00:00:00Z"},
+		{SessionID: 1, Point: "escalation", Rule: "budget", Action: "none", CreatedAt: "2026-10-01T00:00:00Z"},
+		{SessionID: 2, Point: "cooldown", Rule: "canary", Matched: true, Action: "deny", CreatedAt: "2026-10-01T00:00:00Z"},
+	} {
+		if _, err := d.InsertPolicyEvaluation(&pe); err != nil {
+			t.Fatalf("InsertPolicyEvaluation: %v", err)
+		}
+	}
+
+	evals, err := d.ListPolicyEvaluations(1)
+	if err != nil {
+		t.Fatalf("ListPolicyEvaluations: %v", err)
+	}
+	if len(evals) != 2 || evals[0].Rule != "no-night-tier3" || !evals[0].Matched || evals[1].Matched {
+		t.Errorf("ListPolicyEvaluations = %+v", evals)
+	}
+}
+
+func TestSessionCostSince(t *testing.T) {
+	d := openTestDB(t)
+	for _, started := range []string{"2026-10-01T00:00:00Z", "2026-10-02T00:00:00Z", "2026-10-03T00:00:00Z"} {
+		id, err := d.InsertSession(&Session{Tier: 1, Model: "haiku", PromptFile: "p", Status: "completed", StartedAt: started})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		if err := d.UpdateSessionResult(id, "ok", 0.5, 1, 1000); err != nil {
+			t.Fatalf("UpdateSessionResult: %v", err)
+		}
+	}
+	if total, err := d.SessionCostSince("2026-10-02T00:00:00Z"); err != nil || total != 1.0 {
+		t.Errorf("SessionCostSince = %v, %v; want 1.0", total, err)
+	}
+}
+
+func TestEventsAfterAndReviewCounts(t *testing.T) {
+	d := openTestDB(t)
+	if id, err := d.LatestEventID(); err != nil || id != 0 {
+		t.Fatalf("LatestEventID on empty db = %d, %v", id, err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	var ids []int64
+	for _, level := range []string{"critical", "info", "critical"} {
+		id, err := d.InsertEvent(&Event{Level: level, Message: level, CreatedAt: now})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if id, _ := d.LatestEventID(); id != ids[2] {
+		t.Errorf("LatestEventID = %d, want %d", id, ids[2])
+	}
+	events, err := d.ListEventsAfter(ids[0], "critical", 10)
+	if err != nil || len(events) != 1 || events[0].ID != ids[2] {
+		t.Errorf("ListEventsAfter = %+v (err %v)", events, err)
+	}
+
+	for _, status := range []string{MemoryUnverified, MemoryUnverified, MemoryVerified} {
+		if _, err := d.InsertMemory(&Memory{Category: "behavior", Observation: "x", Confidence: 0.5, Active: true, CreatedAt: now, UpdatedAt: now, ReviewStatus: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := d.CountMemoriesByReviewStatus(MemoryUnverified); err != nil || n != 2 {
+		t.Errorf("CountMemoriesByReviewStatus = %d, %v", n, err)
+	}
+}
+
+func TestApprovalRequests(t *testing.T) {
+	d := openTestDB(t)
+	id, err := d.InsertApprovalRequest(&ApprovalRequest{
+		SessionID: 1, Tier: 3, Services: "postgres", Reason: "replica lag", Chain: `{"tier":3}`, Required: 2,
+		CreatedAt: "2026-10-01T00:00:00Z", ExpiresAt: "2026-10-01T01:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("InsertApprovalRequest: %v", err)
+	}
+
+	for _, approver := range []string{"alice", "alice", "bob"} {
+		if _, err := d.AddApproval(id, approver, "2026-10-01T00:10:00Z"); err != nil {
+			t.Fatalf("AddApproval(%s): %v", approver, err)
+		}
+	}
+	ar, err := d.GetApprovalRequest(id)
+	if err != nil || ar == nil || ar.Status != "pending" || len(ar.Approvers) != 2 || ar.Approvers[1] != "bob" {
+		t.Fatalf("GetApprovalRequest = %+v (%v)", ar, err)
+	}
+
+	if err := d.ResolveApprovalRequest(id, "approved", "bob", "2026-10-01T00:10:00Z"); err != nil {
+		t.Fatalf("ResolveApprovalRequest: %v", err)
+	}
+	if err := d.ResolveApprovalRequest(id, "rejected", "carol", "2026-10-01T00:11:00Z"); err != ErrApprovalClosed {
+		t.Errorf("resolving twice: %v", err)
+	}
+	if _, err := d.AddApproval(id, "carol", "2026-10-01T00:11:00Z"); err != ErrApprovalClosed {
+		t.Errorf("approving a resolved request: %v", err)
+	}
+	if ar, _ := d.GetApprovalRequest(999); ar != nil {
+		t.Errorf("expected nil for a missing request, got %+v", ar)
+	}
+}
+
+func TestExpireApprovalRequests(t *testing.T) {
+	d := openTestDB(t)
+	for _, expires := range []string{"2026-10-01T01:00:00Z", "2026-10-01T03:00:00Z"} {
+		if _, err := d.InsertApprovalRequest(&ApprovalRequest{
+			SessionID: 1, Tier: 3, Services: "postgres", Chain: "{}", Required: 2,
+			CreatedAt: "2026-10-01T00:00:00Z", ExpiresAt: expires,
+		}); err != nil {
+			t.Fatalf("InsertApprovalRequest: %v", err)
+		}
+	}
+	expired, err := d.ExpireApprovalRequests("2026-10-01T02:00:00Z")
+	if err != nil || len(expired) != 1 || expired[0].Status != "expired" {
+		t.Fatalf("ExpireApprovalRequests = %+v (%v)", expired, err)
+	}
+	pending, err := d.ListApprovalRequests("pending", 10)
+	if err != nil || len(pending) != 1 || pending[0].ExpiresAt != "2026-10-01T03:00:00Z" {
+		t.Errorf("ListApprovalRequests = %+v (%v)", pending, err)
+	}
+}
+
+func TestStatsAndVacuum(t *testing.T) {
+	d := openTestDB(t)
+	for i := range 3 {
+		if _, err := d.InsertEvent(&Event{Level: "info", Message: fmt.Sprintf("event %d", i), CreatedAt: "2026-10-01T00:00:00Z"}); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	st, err := d.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if st.Path == "" || st.FileSize == 0 || st.PageSize == 0 || st.PageCount == 0 {
+		t.Errorf("unexpected file stats %+v", st)
+	}
+	var events *TableStats
+	for i := range st.Tables {
+		if st.Tables[i].Name == "events" {
+			events = &st.Tables[i]
+		}
+		if strings.HasPrefix(st.Tables[i].Name, "sqlite_") {
+			t.Errorf("internal table listed: %s", st.Tables[i].Name)
+		}
+	}
+	if events == nil || events.Rows != 3 || events.Bytes == 0 {
+		t.Errorf("events table stats = %+v", events)
+	}
+	var found bool
+	for _, ix := range st.Indexes {
+		if ix.Name == "idx_events_level" {
+			found = ix.Table == "events" && ix.Columns == "level, created_at"
+		}
+	}
+	if !found {
+		t.Errorf("idx_events_level missing or wrong in %+v", st.Indexes)
+	}
+
+	migrations, err := d.ListMigrations()
+	if err != nil || len(migrations) == 0 || migrations[0].Version != 1 || !migrations[0].Applied || migrations[0].AppliedAt == "" {
+		t.Fatalf("ListMigrations = %+v (%v)", migrations, err)
+	}
+
+	if err := d.Vacuum(); err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+}
+
+func TestMergeService(t *testing.T) {
+	d := openTestDB(t)
+	now := "2026-10-01T00:00:00Z"
+	alias, canonical := "Jellyfin", "jellyfin"
+	for _, svc := range []*string{&alias, &alias, &canonical} {
+		if _, err := d.InsertEvent(&Event{Level: "warning", Service: svc, Message: "slow", CreatedAt: now}); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+	if _, err := d.InsertMemory(&Memory{Service: &alias, Category: "behavior", Observation: "slow at night", Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("InsertMemory: %v", err)
+	}
+	if _, err := d.InsertCooldownAction(&CooldownAction{Service: alias, ActionType: "restart", Timestamp: now, Success: true, Tier: 2}); err != nil {
+		t.Fatalf("InsertCooldownAction: %v", err)
+	}
+	for _, svc := range []string{alias, canonical} {
+		if err := d.SetHealthStreak(svc, 3); err != nil {
+			t.Fatalf("SetHealthStreak: %v", err)
+		}
+	}
+	if _, err := d.InsertKBArticle(&KBArticle{Service: alias, Content: "runbook", SourceHash: "x", CreatedAt: now}); err != nil {
+		t.Fatalf("InsertKBArticle: %v", err)
+	}
+
+	names, err := d.ListServiceNames()
+	if err != nil || len(names) != 2 || names[0] != (ServiceNameCount{alias, 6}) || names[1] != (ServiceNameCount{canonical, 2}) {
+		t.Fatalf("ListServiceNames = %+v (%v)", names, err)
+	}
+
+	n, err := d.MergeService(alias, canonical, "", "", now)
+	if err != nil || n != 6 {
+		t.Fatalf("MergeService = %d (%v), want 6 records", n, err)
+	}
+	names, err = d.ListServiceNames()
+	if err != nil || len(names) != 1 || names[0] != (ServiceNameCount{canonical, 7}) {
+		t.Errorf("after merge ListServiceNames = %+v (%v)", names, err)
+	}
+	if recent, _ := d.CheckCooldown(canonical, "restart", 24*365*time.Hour); recent == 0 {
+		t.Error("expected the cooldown action under the canonical name")
+	}
+	events, _ := d.ListEvents(1, 0, EventFilter{})
+	if len(events) != 1 || events[0].Message != `Merged service "Jellyfin" into "jellyfin" (6 records)` {
+		t.Errorf("expected the merge logged as an event, got %+v", events)
+	}
+	if aliases, _ := d.ListServiceAliases(); len(aliases) != 0 {
+		t.Errorf("expected no alias without one requested, got %+v", aliases)
+	}
+}
+
+func TestMergeServiceKeepsAlias(t *testing.T) {
+	d := openTestDB(t)
+	now := "2026-10-01T00:00:00Z"
+	if _, err := d.MergeService("jf", "jellyfin-container", "jf", "alice", now); err != nil {
+		t.Fatalf("MergeService: %v", err)
+	}
+	if _, err := d.MergeService("jellyfin-container", "jellyfin", "jellyfin-container", "", now); err != nil {
+		t.Fatalf("MergeService: %v", err)
+	}
+	for alias, want := range map[string]string{"jf": "jellyfin", "jellyfin-container": "jellyfin", "jellyfin": ""} {
+		if got, err := d.GetServiceAlias(alias); err != nil || got != want {
+			t.Errorf("GetServiceAlias(%q) = %q (%v), want %q", alias, got, err, want)
+		}
+	}
+
+	// Merging back the other way drops the now-canonical name's alias.
+	if _, err := d.MergeService("jellyfin", "jellyfin-container", "jellyfin", "", now); err != nil {
+		t.Fatalf("MergeService: %v", err)
+	}
+	aliases, err := d.ListServiceAliases()
+	if err != nil || len(aliases) != 2 {
+		t.Fatalf("ListServiceAliases = %+v (%v)", aliases, err)
+	}
+	for _, a := range aliases {
+		if a.Service != "jellyfin-container" {
+			t.Errorf("alias %s -> %s, want jellyfin-container", a.Alias, a.Service)
+		}
+		if a.Alias == "jf" && (a.MergedBy == nil || *a.MergedBy != "alice") {
+			t.Errorf("jf merged by %v, want alice", a.MergedBy)
+		}
+	}
+}
+
+func TestQueriesByMergedAlias(t *testing.T) {
+	d := openTestDB(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := d.MergeService("jf", "jellyfin", "jf", "", now); err != nil {
+		t.Fatalf("MergeService: %v", err)
+	}
+	svc := "jellyfin"
+	if _, err := d.InsertEvent(&Event{Level: "warning", Service: &svc, Message: "slow", CreatedAt: now}); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+	if _, err := d.InsertMemory(&Memory{Service: &svc, Category: "timing", Observation: "slow start", Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("InsertMemory: %v", err)
+	}
+	if _, err := d.InsertCooldownAction(&CooldownAction{Service: svc, ActionType: "restart", Timestamp: now, Success: true, Tier: 2}); err != nil {
+		t.Fatalf("InsertCooldownAction: %v", err)
+	}
+
+	alias := "jf"
+	level := "warning"
+	if events, err := d.ListEvents(10, 0, EventFilter{Service: &alias, Level: &level}); err != nil || len(events) != 1 {
+		t.Errorf("ListEvents by alias = %+v (%v)", events, err)
+	}
+	if memories, err := d.ListMemories(&alias, nil, nil, 10, 0); err != nil || len(memories) != 1 {
+		t.Errorf("ListMemories by alias = %+v (%v)", memories, err)
+	}
+	if n, err := d.CheckCooldown(alias, "restart", time.Hour); err != nil || n != 1 {
+		t.Errorf("CheckCooldown by alias = %d (%v)", n, err)
+	}
+	other := "caddy"
+	if events, _ := d.ListEvents(10, 0, EventFilter{Service: &other}); len(events) != 0 {
+		t.Errorf("ListEvents for another service = %+v", events)
+	}
+}
+
+func TestGetPeriodStats(t *testing.T) {
+	d := openTestDB(t)
+
+	now := time.Now().UTC()
+	at := func(daysAgo int) string { return now.AddDate(0, 0, -daysAgo).Format(time.RFC3339) }
+
+	insert := func(trigger, started string, c float64, dur int64, services ...string) {
+		t.Helper()
+		id, err := d.InsertSession(&Session{Tier: 1, Model: "haiku", PromptFile: "/p", Status: "completed", StartedAt: started, Trigger: trigger})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		if err := d.UpdateSessionResult(id, "", c, 1, dur); err != nil {
+			t.Fatalf("UpdateSessionResult: %v", err)
+		}
+		if len(services) > 0 {
+			if err := d.UpdateSessionServices(id, services); err != nil {
+				t.Fatalf("UpdateSessionServices: %v", err)
+			}
+		}
+	}
+	insert("scheduled", at(1), 0.10, 10000)
+	insert("escalation", at(1), 0.50, 30000, "jellyfin", "caddy")
+	insert("escalation", at(2), 0.30, 20000, "jellyfin")
+	insert("drill", at(2), 9.00, 90000)
+	insert("scheduled", at(10), 0.20, 5000) // previous week
+
+	svc := "jellyfin"
+	_, _ = d.InsertMemory(&Memory{Service: &svc, Category: "behavior", Observation: "new", Confidence: 0.7, Active: true, CreatedAt: at(1), UpdatedAt: at(1), Tier: 1})
+	_, _ = d.InsertMemory(&Memory{Service: &svc, Category: "timing", Observation: "stale", Confidence: 0.35, Active: true, CreatedAt: at(60), UpdatedAt: at(60), Tier: 1})
+	if err := d.DecayStaleMemories(30, 0.1); err != nil {
+		t.Fatalf("DecayStaleMemories: %v", err)
+	}
+
+	for i, ok := range []bool{true, true, false} {
+		_, err := d.InsertCooldownAction(&CooldownAction{Service: svc, ActionType: "restart", Timestamp: at(i + 1), Success: ok, Tier: 2})
+		if err != nil {
+			t.Fatalf("InsertCooldownAction: %v", err)
+		}
+	}
+
+	p, err := d.GetPeriodStats(at(7), now.Add(time.Minute).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("GetPeriodStats: %v", err)
+	}
+	if p.Sessions != 3 || p.Escalations != 2 {
+		t.Errorf("expected 3 sessions and 2 escalations, got %d and %d", p.Sessions, p.Escalations)
+	}
+	if p.EscalationsByService["jellyfin"] != 2 || p.EscalationsByService["caddy"] != 1 {
+		t.Errorf("unexpected escalations by service: %v", p.EscalationsByService)
+	}
+	if p.MeanCostUSD < 0.299 || p.MeanCostUSD > 0.301 || p.MeanDurationMs != 20000 {
+		t.Errorf("expected mean $0.30 over 20s, got $%f over %dms", p.MeanCostUSD, p.MeanDurationMs)
+	}
+	if p.NewMemories != 1 || p.DecayedMemories != 1 {
+		t.Errorf("expected 1 new and 1 decayed memory, got %d and %d", p.NewMemories, p.DecayedMemories)
+	}
+	if p.Remediations != 3 || p.RemediationsSucceeded != 2 {
+		t.Errorf("expected 2 of 3 remediations to succeed, got %d of %d", p.RemediationsSucceeded, p.Remediations)
+	}
+
+	prev, err := d.GetPeriodStats(at(14), at(7))
+	if err != nil {
+		t.Fatalf("GetPeriodStats (previous): %v", err)
+	}
+	if prev.Sessions != 1 || prev.Escalations != 0 || prev.RemediationSuccessRate() != -1 {
+		t.Errorf("unexpected previous week: %+v", prev)
+	}
+}
+
+func TestRemediationOutcomes(t *testing.T) {
+	d := openTestDB(t)
+
+	sid, _ := d.InsertSession(&Session{Tier: 2, Model: "sonnet", PromptFile: "/p", Status: "completed", StartedAt: "2026-10-01T00:00:00Z"})
+	insert := func(svc, action, ts string, ok bool) int64 {
+		t.Helper()
+		id, err := d.InsertCooldownAction(&CooldownAction{Service: svc, ActionType: action, Timestamp: ts, Success: ok, Tier: 2, SessionID: &sid})
+		if err != nil {
+			t.Fatalf("InsertCooldownAction: %v", err)
+		}
+		return id
+	}
+	first := insert("jellyfin", "restart", "2026-10-01T01:00:00Z", true)
+	insert("jellyfin", "restart", "2026-10-01T03:00:00Z", true)
+	insert("caddy", "redeployment", "2026-10-01T02:00:00Z", false)
+
+	unscored, err := d.ListUnscoredCooldownActions(10)
+	if err != nil || len(unscored) != 3 || unscored[0].ID != first {
+		t.Fatalf("expected 3 unscored actions oldest first, got %v (err %v)", unscored, err)
+	}
+	next, err := d.NextCooldownAction(&unscored[0], "2026-10-01T06:00:00Z")
+	if err != nil || next == nil || next.Timestamp != "2026-10-01T03:00:00Z" {
+		t.Fatalf("expected the 03:00 restart as next action, got %+v (err %v)", next, err)
+	}
+	if next, _ := d.NextCooldownAction(&unscored[0], "2026-10-01T02:00:00Z"); next != nil {
+		t.Errorf("expected no next action before 02:00, got %+v", next)
+	}
+
+	// Checks and events from the remediating session are skipped.
+	_, _ = d.InsertHealthCheck(&HealthCheck{SessionID: &sid, Service: "jellyfin", CheckType: "service", Status: "healthy", CheckedAt: "2026-10-01T01:05:00Z"})
+	_, _ = d.InsertHealthCheck(&HealthCheck{Service: "jellyfin", CheckType: "http", Status: "down", CheckedAt: "2026-10-01T01:30:00Z"})
+	hc, err := d.FirstHealthCheckAfter("jellyfin", "2026-10-01T01:00:00Z", "2026-10-01T06:00:00Z", &sid)
+	if err != nil || hc == nil || hc.Status != "down" {
+		t.Fatalf("expected the pulse check, got %+v (err %v)", hc, err)
+	}
+	if hc, _ := d.FirstHealthCheckAfter("jellyfin", "2026-10-01T01:00:00Z", "2026-10-01T06:00:00Z", nil); hc == nil || hc.Status != "healthy" {
+		t.Errorf("expected the session check without an exclusion, got %+v", hc)
+	}
+	svc := "jellyfin"
+	_, _ = d.InsertEvent(&Event{SessionID: &sid, Level: "critical", Service: &svc, Message: "was down", CreatedAt: "2026-10-01T01:10:00Z"})
+	if ev, err := d.FirstEventAfter("jellyfin", "critical", "2026-10-01T01:00:00Z", "2026-10-01T06:00:00Z", &sid); err != nil || ev != nil {
+		t.Errorf("expected no critical event outside the session, got %+v (err %v)", ev, err)
+	}
+
+	if err := d.ScoreCooldownAction(first, OutcomeEffective, "held", "2026-10-01T07:00:00Z"); err != nil {
+		t.Fatalf("ScoreCooldownAction: %v", err)
+	}
+	// A scored action is not rescored.
+	_ = d.ScoreCooldownAction(first, OutcomeIneffective, "changed", "2026-10-01T08:00:00Z")
+	scored, err := d.ListScoredCooldownActions(10)
+	if err != nil || len(scored) != 1 || *scored[0].Outcome != OutcomeEffective || *scored[0].OutcomeReason != "held" {
+		t.Fatalf("expected one effective action, got %+v (err %v)", scored, err)
+	}
+
+	scores, err := d.ListRemediationScores()
+	if err != nil {
+		t.Fatalf("ListRemediationScores: %v", err)
+	}
+	if len(scores) != 2 {
+		t.Fatalf("expected 2 score rows, got %+v", scores)
+	}
+	j := scores[1]
+	if j.Service != "jellyfin" || j.Attempts != 2 || j.Effective != 1 || j.Pending != 1 || j.SuccessRate() != 1 {
+		t.Errorf("unexpected jellyfin score %+v", j)
+	}
+	if c := scores[0]; c.Service != "caddy" || c.SuccessRate() != -1 {
+		t.Errorf("unexpected caddy score %+v", c)
+	}
+}
+
+func TestSessionFeedback(t *testing.T) {
+	d := openTestDB(t)
+
+	now := time.Now().UTC()
+	ts := now.Add(-time.Hour).Format(time.RFC3339)
+	var ids []int64
+	for _, trigger := range []string{"scheduled", "scheduled", "scheduled", "drill"} {
+		id, err := d.InsertSession(&Session{Tier: 1, Model: "haiku", PromptFile: "/p", Status: "completed", StartedAt: ts, Trigger: trigger})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		if err := d.UpdateSession(id, "completed", &ts, nil, nil); err != nil {
+			t.Fatalf("UpdateSession: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	op := "alice"
+	if _, err := d.InsertSessionFeedback(&SessionFeedback{SessionID: ids[0], Rating: 1, Operator: &op, CreatedAt: ts}, nil); err != nil {
+		t.Fatalf("InsertSessionFeedback (up): %v", err)
+	}
+	comment := "should have checked the disk first"
+	svc := "postgres"
+	f := &SessionFeedback{SessionID: ids[1], Rating: -1, Comment: &comment, CreatedAt: ts}
+	m := &Memory{Service: &svc, Category: "remediation", Observation: "flagged", Confidence: 0.95, Active: true, CreatedAt: ts, UpdatedAt: ts, SessionID: &ids[1]}
+	if _, err := d.InsertSessionFeedback(f, m); err != nil {
+		t.Fatalf("InsertSessionFeedback (down): %v", err)
+	}
+	if f.MemoryID == nil || *f.MemoryID != m.ID {
+		t.Fatalf("expected feedback linked to memory %d, got %v", m.ID, f.MemoryID)
+	}
+	mem, err := d.GetMemory(m.ID)
+	if err != nil || mem == nil || mem.ReviewStatus != MemoryVerified || mem.Confidence != 0.95 {
+		t.Fatalf("expected a verified corrective memory, got %+v (err %v)", mem, err)
+	}
+
+	list, err := d.ListSessionFeedback(ids[1])
+	if err != nil || len(list) != 1 || *list[0].Comment != comment || *list[0].MemoryID != m.ID {
+		t.Fatalf("unexpected session feedback %+v (err %v)", list, err)
+	}
+	recent, err := d.ListRecentFeedback(10)
+	if err != nil || len(recent) != 2 || recent[0].Rating != -1 {
+		t.Fatalf("unexpected recent feedback %+v (err %v)", recent, err)
+	}
+
+	st, err := d.GetFeedbackStats(now.AddDate(0, 0, -7).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("GetFeedbackStats: %v", err)
+	}
+	want := FeedbackStats{Sessions: 3, Rated: 2, Corrected: 1, Up: 1, Down: 1}
+	if *st != want {
+		t.Errorf("feedback stats = %+v, want %+v", *st, want)
+	}
+
+	p, err := d.GetPeriodStats(now.AddDate(0, 0, -7).Format(time.RFC3339), now.Format(time.RFC3339))
+	if err != nil || p.Corrections != 1 {
+		t.Errorf("expected 1 correction in period stats, got %+v (err %v)", p, err)
+	}
+}
+
+func TestSessionTickets(t *testing.T) {
+	d := openTestDB(t)
+	ts := "2026-10-01T00:00:00Z"
+	tk := &SessionTicket{SessionID: 1, Provider: "github", TicketID: "ops/homelab#12", URL: "https://github.com/ops/homelab/issues/12", Source: TicketSourceMarker, CreatedAt: ts}
+	if added, err := d.InsertSessionTicket(tk); err != nil || !added || tk.ID == 0 {
+		t.Fatalf("InsertSessionTicket: added=%v id=%d err=%v", added, tk.ID, err)
+	}
+	if added, err := d.InsertSessionTicket(&SessionTicket{SessionID: 1, Provider: "github", TicketID: "ops/homelab#12", Source: TicketSourceAPI, CreatedAt: ts}); err != nil || added {
+		t.Errorf("duplicate InsertSessionTicket: added=%v err=%v", added, err)
+	}
+	if _, err := d.InsertSessionTicket(&SessionTicket{SessionID: 1, Provider: "jira", TicketID: "OPS-7", Source: TicketSourceAPI, CreatedAt: ts}); err != nil {
+		t.Fatal(err)
+	}
+
+	tickets, err := d.ListSessionTickets(1)
+	if err != nil || len(tickets) != 2 || tickets[0].Source != TicketSourceMarker || tickets[1].TicketID != "OPS-7" {
+		t.Fatalf("ListSessionTickets = %+v (%v)", tickets, err)
+	}
+	if ok, err := d.DeleteSessionTicket(2, tk.ID); err != nil || ok {
+		t.Errorf("DeleteSessionTicket for another session: ok=%v err=%v", ok, err)
+	}
+	if ok, err := d.DeleteSessionTicket(1, tk.ID); err != nil || !ok {
+		t.Errorf("DeleteSessionTicket: ok=%v err=%v", ok, err)
+	}
+	if tickets, _ := d.ListSessionTickets(1); len(tickets) != 1 {
+		t.Errorf("after delete: %+v", tickets)
+	}
+}
+
+func TestUpdateSessionRequestID(t *testing.T) {
+	d := openTestDB(t)
+
+	var ids []int64
+	for range 2 {
+		id, err := d.InsertSession(&Session{
+			Tier:       1,
+			Model:      "haiku",
+			PromptFile: "/tmp/test.md",
+			Status:     "running",
+			StartedAt:  time.Now().UTC().Format(time.RFC3339),
+			Trigger:    "api",
+		})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := d.UpdateSessionRequestID(ids[1], "req-1"); err != nil {
+		t.Fatalf("UpdateSessionRequestID: %v", err)
+	}
+
+	s, err := d.GetSession(ids[1])
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if s.RequestID == nil || *s.RequestID != "req-1" {
+		t.Fatalf("expected request ID req-1, got %v", s.RequestID)
+	}
+	if s, _ := d.GetSession(ids[0]); s.RequestID != nil {
+		t.Fatalf("untagged session has request ID %q", *s.RequestID)
+	}
+
+	sessions, err := d.ListSessionsByRequestID("req-1")
+	if err != nil {
+		t.Fatalf("ListSessionsByRequestID: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != ids[1] {
+		t.Fatalf("expected session %d, got %+v", ids[1], sessions)
+	}
+}
+
+func TestBulkMemoryOperations(t *testing.T) {
+	d := openTestDB(t)
+	now := time.Now().UTC()
+	svc := func(s string) *string { return &s }
+	insert := func(service *string, category string, confidence float64, age time.Duration) int64 {
+		created := now.Add(-age).Format(time.RFC3339)
+		id, err := d.InsertMemory(&Memory{Service: service, Category: category, Observation: category, Confidence: confidence, Active: true, CreatedAt: created, UpdatedAt: created})
+		if err != nil {
+			t.Fatalf("InsertMemory: %v", err)
+		}
+		return id
+	}
+	noisy1 := insert(svc("jellyfin"), "timing", 0.4, 24*time.Hour)
+	noisy2 := insert(svc("jellyfin"), "timing", 0.5, 48*time.Hour)
+	old := insert(svc("jellyfin"), "timing", 0.4, 30*24*time.Hour)
+	sure := insert(svc("jellyfin"), "timing", 0.9, time.Hour)
+	other := insert(svc("postgres"), "timing", 0.4, time.Hour)
+
+	if !(MemoryFilter{}).Empty() {
+		t.Error("zero filter is not empty")
+	}
+	lo, hi := 0.3, 0.6
+	f := MemoryFilter{Service: svc("jellyfin"), MinConfidence: &lo, MaxConfidence: &hi, CreatedAfter: now.Add(-7 * 24 * time.Hour).Format(time.RFC3339)}
+	found, err := d.FindMemories(f)
+	if err != nil {
+		t.Fatalf("FindMemories: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != noisy1 || found[1].ID != noisy2 {
+		t.Fatalf("expected the two noisy memories, got %+v", found)
+	}
+
+	n, err := d.DeactivateMemories(f)
+	if err != nil || n != 2 {
+		t.Fatalf("DeactivateMemories: %d %v", n, err)
+	}
+	if n, _ := d.DeactivateMemories(f); n != 0 {
+		t.Errorf("deactivated %d already inactive memories", n)
+	}
+
+	cat := "dependency"
+	if n, err := d.RetagMemories(MemoryFilter{CreatedBefore: now.Add(-7 * 24 * time.Hour).Format(time.RFC3339)}, &cat, svc("")); err != nil || n != 1 {
+		t.Fatalf("RetagMemories: %d %v", n, err)
+	}
+	if m, _ := d.GetMemory(old); m.Category != "dependency" || m.Service != nil {
+		t.Errorf("retagged memory: %+v", m)
+	}
+
+	if n, err := d.DeleteMemories(MemoryFilter{Service: svc("jellyfin"), Category: svc("timing")}); err != nil || n != 3 {
+		t.Fatalf("DeleteMemories: %d %v", n, err)
+	}
+	for id, want := range map[int64]bool{noisy1: false, noisy2: false, old: true, sure: false, other: true} {
+		if m, _ := d.GetMemory(id); (m != nil) != want {
+			t.Errorf("memory %d present = %v, want %v", id, m != nil, want)
+		}
+	}
+	if tomb, _ := d.ListTombstonedMemories(10); len(tomb) != 3 {
+		t.Errorf("expected 3 tombstones, got %d", len(tomb))
+	}
+}
+
+func TestMemoryCategories(t *testing.T) {
+	d := openTestDB(t)
+	insert := func(category string, confidence float64) int64 {
+		now := time.Now().UTC().Format(time.RFC3339)
+		id, err := d.InsertMemory(&Memory{Category: category, Observation: category, Confidence: confidence, Active: true, CreatedAt: now, UpdatedAt: now})
+		if err != nil {
+			t.Fatalf("InsertMemory: %v", err)
+		}
+		return id
+	}
+	t1 := insert("timing", 0.6)
+	insert("timing", 0.8)
+	flaky := insert("flaky", 0.5)
+	insert("quirk", 0.7)
+	if err := d.ReinforceMemory(t1, 0.7); err != nil {
+		t.Fatalf("ReinforceMemory: %v", err)
+	}
+
+	cats, err := d.ListMemoryCategories()
+	if err != nil {
+		t.Fatalf("ListMemoryCategories: %v", err)
+	}
+	byName := map[string]MemoryCategory{}
+	for _, c := range cats {
+		byName[c.Name] = c
+	}
+	if c := byName["timing"]; !c.Registered || c.Description == "" || c.Count != 2 || math.Abs(c.AvgConfidence-0.75) > 1e-9 || c.LastReinforced == nil {
+		t.Errorf("timing: %+v", c)
+	}
+	if c := byName["flaky"]; c.Registered || c.Count != 1 || c.LastReinforced != nil {
+		t.Errorf("flaky: %+v", c)
+	}
+	if c, ok := byName["maintenance"]; !ok || c.Count != 0 {
+		t.Errorf("unused registered category: %+v", c)
+	}
+
+	if err := d.DeleteMemoryCategory("timing"); err != ErrMemoryCategoryInUse {
+		t.Errorf("delete category in use: %v", err)
+	}
+	if _, err := d.RenameMemoryCategory("flaky", "timing"); err != ErrMemoryCategoryExists {
+		t.Errorf("rename onto existing category: %v", err)
+	}
+	if err := d.DeleteMemory(flaky); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := d.RenameMemoryCategory("flaky", "intermittent"); err != nil || n != 1 {
+		t.Fatalf("RenameMemoryCategory: %d %v", n, err)
+	}
+	var category string
+	if err := d.conn.QueryRow(`SELECT category FROM memories WHERE id = ?`, flaky).Scan(&category); err != nil || category != "intermittent" {
+		t.Errorf("deleted memory not renamed: %q %v", category, err)
+	}
+
+	if n, err := d.MergeMemoryCategories([]string{"quirk", "timing"}, "behavior"); err != nil || n != 3 {
+		t.Fatalf("MergeMemoryCategories: %d %v", n, err)
+	}
+	names, _ := d.RegisteredMemoryCategories()
+	if strings.Join(names, ",") != "behavior,dependency,maintenance,remediation" {
+		t.Errorf("registered after merge: %v", names)
+	}
+	if err := d.UpsertMemoryCategory("maintenance", "Planned work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DeleteMemoryCategory("maintenance"); err != nil {
+		t.Fatalf("DeleteMemoryCategory: %v", err)
+	}
+}
+
+func TestDecayMemoriesByCategory(t *testing.T) {
+	d := openTestDB(t)
+	stale := time.Now().UTC().AddDate(0, 0, -60).Format(time.RFC3339)
+	insert := func(category string) int64 {
+		id, err := d.InsertMemory(&Memory{Category: category, Observation: category, Confidence: 0.8, Active: true, CreatedAt: stale, UpdatedAt: stale})
+		if err != nil {
+			t.Fatalf("InsertMemory: %v", err)
+		}
+		return id
+	}
+	timing, arch, dep := insert("timing"), insert("architecture"), insert("dependency")
+
+	def := MemoryScore{Reinforce: 0.1, Contradict: 0.1, Decay: 0.1}
+	byCategory := map[string]MemoryScore{
+		"timing":       {Reinforce: 0.1, Contradict: 0.1, Decay: 0.3},
+		"architecture": {Reinforce: 0.1, Contradict: 0.1},
+	}
+	if err := d.DecayMemories(30, def, byCategory); err != nil {
+		t.Fatalf("DecayMemories: %v", err)
+	}
+	for id, want := range map[int64]float64{timing: 0.5, arch: 0.8, dep: 0.7} {
+		m, _ := d.GetMemory(id)
+		if math.Abs(m.Confidence-want) > 1e-9 {
+			t.Errorf("memory %s confidence = %f, want %f", m.Category, m.Confidence, want)
+		}
+		revs, err := d.ListMemoryRevisions(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id == arch {
+			if len(revs) != 0 {
+				t.Errorf("revision recorded for a category without decay: %+v", revs)
+			}
+			continue
+		}
+		if len(revs) != 1 || revs[0].Change != MemoryDecayed || *revs[0].ConfidenceBefore != 0.8 || math.Abs(revs[0].ConfidenceAfter-want) > 1e-9 {
+			t.Errorf("memory %s revisions = %+v", m.Category, revs)
+		}
+	}
+
+	if err := d.PurgeMemory(timing); err != nil {
+		t.Fatal(err)
+	}
+	if revs, _ := d.ListMemoryRevisions(timing); len(revs) != 0 {
+		t.Errorf("purge left %d revisions", len(revs))
+	}
+}
+
+func TestSessionOutcome(t *testing.T) {
+	d := openTestDB(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	insert := func(status string, parent *int64) int64 {
+		id, err := d.InsertSession(&Session{Tier: 1, Model: "haiku", PromptFile: "/p.md", Status: status, StartedAt: now, Trigger: "scheduled", ParentSessionID: parent})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		return id
+	}
+	// Chain whose tip remediated: a success even though the root escalated.
+	root1 := insert("escalated", nil)
+	tip1 := insert("completed", &root1)
+	// Completed, but the agent observed issues it could not fix.
+	root2 := insert("completed", nil)
+	// Completed before outcomes were recorded: counts by status.
+	insert("completed", nil)
+
+	for id, outcome := range map[int64]string{root1: SessionIssuesObserved, tip1: SessionRemediated, root2: SessionIssuesObserved} {
+		if err := d.UpdateSessionOutcome(id, outcome); err != nil {
+			t.Fatalf("UpdateSessionOutcome: %v", err)
+		}
+	}
+
+	stats, err := d.GetDashboardStats()
+	if err != nil {
+		t.Fatalf("GetDashboardStats: %v", err)
+	}
+	if stats.SuccessRate < 0.66 || stats.SuccessRate > 0.67 {
+		t.Errorf("SuccessRate = %f, want ~0.667", stats.SuccessRate)
+	}
+
+	issues := SessionIssuesObserved
+	sessions, err := d.FindSessions(SessionFilter{Outcome: &issues}, "", false, 10, 0)
+	if err != nil {
+		t.Fatalf("FindSessions: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != root2 || *sessions[0].Outcome != SessionIssuesObserved {
+		t.Errorf("issues-observed sessions: %+v", sessions)
+	}
+}
+
+func TestFindSessions(t *testing.T) {
+	d := openTestDB(t)
+	insert := func(tier int, status, trigger, started string, cost *float64, durationMs *int64) int64 {
+		id, err := d.InsertSession(&Session{Tier: tier, Model: "haiku", PromptFile: "/p.md", Status: status, StartedAt: started, Trigger: trigger})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		if cost != nil {
+			if err := d.UpdateSessionResult(id, "done", *cost, 3, *durationMs); err != nil {
+				t.Fatalf("UpdateSessionResult: %v", err)
+			}
+		}
+		return id
+	}
+	f := func(v float64) *float64 { return &v }
+	ms := func(v int64) *int64 { return &v }
+	cheap := insert(1, "completed", "scheduled", "2026-09-01T10:00:00Z", f(0.01), ms(60000))
+	failed := insert(3, "failed", "escalation", "2026-09-10T10:00:00Z", f(2.5), ms(30000))
+	expensive := insert(3, "completed", "escalation", "2026-09-20T10:00:00Z", f(4), ms(600000))
+	running := insert(1, "running", "manual", "2026-10-01T10:00:00Z", nil, nil)
+
+	ids := func(sessions []Session) []int64 {
+		var out []int64
+		for _, s := range sessions {
+			out = append(out, s.ID)
+		}
+		return out
+	}
+	tier3, status := 3, "failed"
+	cases := []struct {
+		name   string
+		filter SessionFilter
+		sort   string
+		asc    bool
+		want   []int64
+	}{
+		{"newest first", SessionFilter{}, "", false, []int64{running, expensive, failed, cheap}},
+		{"failed tier 3 last month", SessionFilter{Tier: &tier3, Status: &status, Since: "2026-09-01T00:00:00Z", Until: "2026-10-01T00:00:00Z"}, "", false, []int64{failed}},
+		{"min cost", SessionFilter{MinCost: f(1)}, SessionSortCost, true, []int64{failed, expensive}},
+		{"by cost, unknown last", SessionFilter{}, SessionSortCost, false, []int64{expensive, failed, cheap, running}},
+		{"by duration", SessionFilter{}, SessionSortDuration, true, []int64{failed, cheap, expensive, running}},
+	}
+	for _, tc := range cases {
+		sessions, err := d.FindSessions(tc.filter, tc.sort, tc.asc, 10, 0)
+		if err != nil {
+			t.Fatalf("%s: FindSessions: %v", tc.name, err)
+		}
+		if got := ids(sessions); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		n, err := d.CountSessions(tc.filter)
+		if err != nil {
+			t.Fatalf("%s: CountSessions: %v", tc.name, err)
+		}
+		if n != len(tc.want) {
+			t.Errorf("%s: CountSessions = %d, want %d", tc.name, n, len(tc.want))
+		}
+	}
+	if _, err := d.FindSessions(SessionFilter{}, "model", false, 10, 0); err == nil {
+		t.Error("FindSessions accepted an unknown sort")
+	}
+}
+
+func TestScheduledCoverage(t *testing.T) {
+	d := openTestDB(t)
+	now := time.Now().UTC()
+	insert := func(trigger, status string, services []string, ago time.Duration, checks map[string]string) int64 {
+		at := now.Add(-ago).Format(time.RFC3339)
+		id, err := d.InsertSession(&Session{Tier: 1, Model: "haiku", PromptFile: "/p.md", Status: status, StartedAt: at, Trigger: trigger})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if services != nil {
+			if err := d.UpdateSessionServices(id, services); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for svc, status := range checks {
+			if _, err := d.InsertHealthCheck(&HealthCheck{SessionID: &id, Service: svc, CheckType: "service", Status: status, CheckedAt: at}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return id
+	}
+
+	if services, _, err := d.ScheduledCoverage(); err != nil || services != nil {
+		t.Fatalf("empty database: %v, %v", services, err)
+	}
+	insert("scheduled", "completed", nil, 3*time.Hour, map[string]string{"caddy": "healthy"})
+	full := insert("scheduled", "escalated", nil, 2*time.Hour, map[string]string{"caddy": "down", "postgres": "healthy"})
+	insert("scheduled", "completed", []string{"caddy"}, time.Hour, map[string]string{"caddy": "healthy"})
+	insert("scheduled", "failed", nil, time.Hour, map[string]string{"caddy": "healthy"})
+	insert("manual", "completed", nil, time.Hour, map[string]string{"redis": "healthy"})
+
+	services, id, err := d.ScheduledCoverage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != full || !slices.Equal(services, []string{"caddy", "postgres"}) {
+		t.Errorf("ScheduledCoverage = %v from %d, want caddy and postgres from %d", services, id, full)
+	}
+
+	fresh, err := d.FreshServiceChecks(now.Add(-90 * time.Minute).Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fresh) != 2 || fresh["caddy"].Status != "healthy" || fresh["redis"].Status != "healthy" {
+		t.Errorf("FreshServiceChecks = %+v, want caddy and redis", fresh)
+	}
+	fresh, err = d.FreshServiceChecks(now.Add(-5 * time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fresh["postgres"]; !ok || len(fresh) != 3 {
+		t.Errorf("FreshServiceChecks over 5h = %+v", fresh)
+	}
+
+	// Pulse probes record session-less checks; a newer probe that found
+	// redis down outweighs the healthy session check, while a healthy probe
+	// leaves caddy fresh.
+	pulse := func(svc, status string) {
+		at := now.Add(-30 * time.Minute).Format(time.RFC3339)
+		if _, err := d.InsertHealthCheck(&HealthCheck{Service: svc, CheckType: "http", Status: status, CheckedAt: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pulse("redis", "down")
+	pulse("caddy", "healthy")
+	fresh, err = d.FreshServiceChecks(now.Add(-90 * time.Minute).Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fresh["redis"]; ok || len(fresh) != 1 || fresh["caddy"].CheckType != "service" {
+		t.Errorf("FreshServiceChecks after pulse = %+v, want caddy only", fresh)
+	}
+}
+
+func TestSealedPromptHistory(t *testing.T) {
+	d := openTestDB(t)
+	sealer, err := seal.New(make([]byte, seal.KeySize))
+	if err != nil {
+		t.Fatalf("seal.New: %v", err)
+	}
+	if err := d.RecordPrompt("check db01.internal", false, 10); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+	// Turning the key on seals the prompt recorded before it.
+	if err := d.SetSealer(sealer); err != nil {
+		t.Fatalf("SetSealer: %v", err)
+	}
+	if err := d.RecordPrompt("check db01.internal", true, 10); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+	if err := d.RecordPrompt("restart 10.0.0.5", false, 10); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+
+	rows, err := d.Conn().Query(`SELECT prompt, prompt_hash FROM prompt_history`)
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	for rows.Next() {
+		var prompt, hash string
+		if err := rows.Scan(&prompt, &hash); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if !seal.IsSealed(prompt) || strings.Contains(prompt, "db01") || strings.Contains(prompt, "10.0.0.5") {
+			t.Errorf("stored in plaintext: %q", prompt)
+		}
+		if plain, _ := sealer.Open(prompt); hash != sealer.Hash(plain) {
+			t.Errorf("prompt hash %q is not the keyed hash of %q", hash, plain)
+		}
+	}
+	_ = rows.Close()
+
+	prompts, err := d.ListPromptHistory(10)
+	if err != nil || len(prompts) != 2 {
+		t.Fatalf("ListPromptHistory: %+v %v", prompts, err)
+	}
+	if prompts[0].Prompt != "check db01.internal" || prompts[0].UseCount != 2 || !prompts[0].Favorite {
+		t.Errorf("sealed prompt not deduplicated: %+v", prompts[0])
+	}
+
+	// A prompt recorded while the key was missing is merged into its sealed
+	// copy once the key is back.
+	if err := d.SetSealer(nil); err != nil {
+		t.Fatalf("SetSealer: %v", err)
+	}
+	if err := d.RecordPrompt("restart 10.0.0.5", false, 10); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+	if err := d.SetSealer(sealer); err != nil {
+		t.Fatalf("SetSealer: %v", err)
+	}
+	prompts, _ = d.ListPromptHistory(10)
+	if len(prompts) != 2 {
+		t.Fatalf("expected the prompts to be merged, got %+v", prompts)
+	}
+	for _, p := range prompts {
+		if p.Prompt == "restart 10.0.0.5" && p.UseCount != 2 {
+			t.Errorf("merged prompt use count = %d, want 2", p.UseCount)
+		}
+	}
+}
+
+func TestSealedSessionText(t *testing.T) {
+	d := openTestDB(t)
+	oldPrompt, prompt := "check 10.0.0.5", "check db01.internal"
+	sealer, err := seal.New(make([]byte, seal.KeySize))
+	if err != nil {
+		t.Fatalf("seal.New: %v", err)
+	}
+	plain, err := d.InsertSession(&Session{Tier: 1, Model: "haiku", PromptFile: "/p.md", Status: "completed", StartedAt: time.Now().UTC().Format(time.RFC3339), PromptText: &oldPrompt})
+	if err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	d.SetSealer(sealer)
+	id, err := d.InsertSession(&Session{Tier: 1, Model: "haiku", PromptFile: "/p.md", Status: "completed", StartedAt: time.Now().UTC().Format(time.RFC3339), PromptText: &prompt})
+	if err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	if err := d.UpdateSessionResult(id, "db01.internal restarted", 0.01, 3, 1000); err != nil {
+		t.Fatalf("UpdateSessionResult: %v", err)
+	}
+	if err := d.UpdateSessionSummary(id, "Restarted db01"); err != nil {
+		t.Fatalf("UpdateSessionSummary: %v", err)
+	}
+
+	var storedPrompt, response, summary string
+	if err := d.Conn().QueryRow(`SELECT prompt_text, response, summary FROM sessions WHERE id = ?`, id).Scan(&storedPrompt, &response, &summary); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	for _, stored := range []string{storedPrompt, response, summary} {
+		if !seal.IsSealed(stored) || strings.Contains(stored, "db01") {
+			t.Errorf("stored in plaintext: %q", stored)
+		}
+	}
+
+	s, err := d.GetSession(id)
+	if err != nil || s == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if *s.PromptText != "check db01.internal" || *s.Response != "db01.internal restarted" || *s.Summary != "Restarted db01" {
+		t.Errorf("GetSession did not open sealed text: %q %q %q", *s.PromptText, *s.Response, *s.Summary)
+	}
+	if s, _ := d.GetSession(plain); *s.PromptText != "check 10.0.0.5" {
+		t.Errorf("plaintext from before the key = %q", *s.PromptText)
+	}
+
+	d.SetSealer(nil)
+	if s, _ := d.GetSession(id); *s.Response != seal.Unreadable {
+		t.Errorf("without the key, response = %q", *s.Response)
+	}
+}
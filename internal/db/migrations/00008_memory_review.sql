@@ -0,0 +1,10 @@
+-- Memory review workflow: agent-created memories start as 'unverified' until an
+-- operator approves ('verified') or rejects ('rejected') them. Existing memories
+-- were already being injected, so they are grandfathered in as 'verified'.
+-- +goose Up
+ALTER TABLE memories ADD COLUMN review_status TEXT NOT NULL DEFAULT 'verified';
+CREATE INDEX idx_memories_review_status ON memories(review_status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_memories_review_status;
+ALTER TABLE memories DROP COLUMN review_status;
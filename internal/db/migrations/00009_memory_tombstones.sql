@@ -0,0 +1,15 @@
+-- Memory tombstones: deleting a memory soft-deletes it (deleted_at), and
+-- deleting or rejecting it records tombstoned_at. While a tombstone is fresh,
+-- the agent re-learning the same observation is suppressed and counted in
+-- suppressed_count instead of re-inserting the memory.
+-- +goose Up
+ALTER TABLE memories ADD COLUMN deleted_at TEXT;
+ALTER TABLE memories ADD COLUMN tombstoned_at TEXT;
+ALTER TABLE memories ADD COLUMN suppressed_count INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_memories_tombstoned ON memories(tombstoned_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_memories_tombstoned;
+ALTER TABLE memories DROP COLUMN suppressed_count;
+ALTER TABLE memories DROP COLUMN tombstoned_at;
+ALTER TABLE memories DROP COLUMN deleted_at;
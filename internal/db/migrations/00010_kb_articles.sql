@@ -0,0 +1,19 @@
+-- Knowledge base: per-service runbook articles compiled from memories by the
+-- summary model. Each regeneration inserts a new version; source_hash
+-- fingerprints the memories an article was compiled from so unchanged
+-- services are not recompiled.
+-- +goose Up
+CREATE TABLE kb_articles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    memory_count INTEGER NOT NULL DEFAULT 0,
+    source_hash TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    UNIQUE (service, version)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS kb_articles;
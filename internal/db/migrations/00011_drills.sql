@@ -0,0 +1,20 @@
+-- Self-test drills: a synthetic failing canary service is run through the
+-- full escalation pipeline to check that detection, remediation, and
+-- notification still work. Drill outcomes are kept apart from real
+-- incidents; session_id is the first session of the drill's chain.
+-- +goose Up
+CREATE TABLE drills (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    status TEXT NOT NULL DEFAULT 'running',
+    session_id INTEGER REFERENCES sessions(id),
+    max_tier INTEGER NOT NULL DEFAULT 0,
+    detected INTEGER NOT NULL DEFAULT 0,
+    remediated INTEGER NOT NULL DEFAULT 0,
+    notified INTEGER NOT NULL DEFAULT 0,
+    detail TEXT,
+    started_at TEXT NOT NULL,
+    ended_at TEXT
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS drills;
@@ -0,0 +1,14 @@
+-- Prompt history: ad-hoc prompts run from the dashboard, offered back on the
+-- Run Now form. Favorites are pinned and never pruned. Prompts are shared by
+-- everyone using the dashboard until it has user accounts.
+-- +goose Up
+CREATE TABLE prompt_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    prompt TEXT NOT NULL UNIQUE,
+    favorite INTEGER NOT NULL DEFAULT 0,
+    use_count INTEGER NOT NULL DEFAULT 1,
+    last_used_at TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS prompt_history;
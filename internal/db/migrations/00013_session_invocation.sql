@@ -0,0 +1,8 @@
+-- Session invocation: how the CLI process was started (arguments, tools,
+-- redacted system prompt, environment, and the model the CLI resolved), as
+-- a JSON blob for debugging differences between sessions.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN invocation TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN invocation;
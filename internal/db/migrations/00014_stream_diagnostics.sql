@@ -0,0 +1,17 @@
+-- Stream diagnostics: stream-json events a session's parser could not
+-- understand (unknown event types or unparseable lines), one row per type
+-- with a count and the first sample, so parser gaps after CLI upgrades are
+-- visible instead of silently dropped.
+-- +goose Up
+CREATE TABLE stream_diagnostics (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    event_type TEXT NOT NULL,
+    count INTEGER NOT NULL,
+    sample TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+CREATE INDEX idx_stream_diagnostics_session ON stream_diagnostics(session_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS stream_diagnostics;
@@ -0,0 +1,18 @@
+-- Policy evaluations: the outcome of each policy rule evaluated for a
+-- session, before an escalation or before recording a cooldown action, so
+-- the dashboard can show why a decision was denied or changed.
+-- +goose Up
+CREATE TABLE policy_evaluations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    point TEXT NOT NULL,
+    rule TEXT NOT NULL,
+    matched INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    detail TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+CREATE INDEX idx_policy_evaluations_session ON policy_evaluations(session_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS policy_evaluations;
@@ -0,0 +1,31 @@
+-- Approval requests: Tier 3 remediations of two-person services held until
+-- enough distinct operators approve. chain is the JSON escalation step to
+-- run once approved. Each approver is recorded once per request.
+-- +goose Up
+CREATE TABLE approval_requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    tier INTEGER NOT NULL,
+    services TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    chain TEXT NOT NULL,
+    required INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    resolved_at TEXT,
+    resolved_by TEXT
+);
+CREATE INDEX idx_approval_requests_status ON approval_requests(status);
+
+CREATE TABLE approvals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id INTEGER NOT NULL REFERENCES approval_requests(id),
+    approver TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    UNIQUE (request_id, approver)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS approvals;
+DROP TABLE IF EXISTS approval_requests;
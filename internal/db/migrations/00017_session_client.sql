@@ -0,0 +1,11 @@
+-- Session client: the OpenAI "user" field and metadata object sent by a chat
+-- client that triggered the session, so traffic from different frontends
+-- (Open WebUI, a Slack bot, ...) can be told apart. Metadata is a JSON object
+-- of string values.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN client_user TEXT;
+ALTER TABLE sessions ADD COLUMN client_metadata TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN client_metadata;
+ALTER TABLE sessions DROP COLUMN client_user;
@@ -0,0 +1,8 @@
+-- Synthetic session cost: set when cost_usd is an estimate from the
+-- configured pricing table because the CLI reported zero cost (Claude
+-- subscription plans) alongside real token usage.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN cost_synthetic INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN cost_synthetic;
@@ -0,0 +1,8 @@
+-- Session max context: the largest context, in tokens, any assistant turn
+-- of the session used, for spotting sessions that ran close to the model's
+-- context window.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN max_context_tokens INTEGER;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN max_context_tokens;
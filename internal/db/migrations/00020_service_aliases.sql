@@ -0,0 +1,12 @@
+-- Service aliases: names merged into another service from the dashboard.
+-- Markers naming an alias are recorded under its service.
+-- +goose Up
+CREATE TABLE service_aliases (
+    alias TEXT PRIMARY KEY,
+    service TEXT NOT NULL,
+    merged_by TEXT,
+    created_at TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS service_aliases;
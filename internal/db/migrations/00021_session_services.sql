@@ -0,0 +1,8 @@
+-- Session services: the comma-separated services a session was scoped to
+-- (an escalation's affected services, or the failing pulse targets), for
+-- counting escalations per service.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN services TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN services;
@@ -0,0 +1,7 @@
+-- Memory deactivation time: when staleness decay deactivated a memory, for
+-- counting decayed memories per week.
+-- +goose Up
+ALTER TABLE memories ADD COLUMN deactivated_at TEXT;
+
+-- +goose Down
+ALTER TABLE memories DROP COLUMN deactivated_at;
@@ -0,0 +1,13 @@
+-- Remediation outcome: whether a cooldown action actually fixed its service,
+-- scored from the health signals that followed it.
+-- +goose Up
+ALTER TABLE cooldown_actions ADD COLUMN outcome TEXT;
+ALTER TABLE cooldown_actions ADD COLUMN outcome_reason TEXT;
+ALTER TABLE cooldown_actions ADD COLUMN scored_at TEXT;
+CREATE INDEX idx_cooldown_actions_outcome ON cooldown_actions(outcome, timestamp);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cooldown_actions_outcome;
+ALTER TABLE cooldown_actions DROP COLUMN scored_at;
+ALTER TABLE cooldown_actions DROP COLUMN outcome_reason;
+ALTER TABLE cooldown_actions DROP COLUMN outcome;
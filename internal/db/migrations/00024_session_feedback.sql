@@ -0,0 +1,20 @@
+-- Session feedback: operator thumbs up/down on a session, with the corrective
+-- memory a thumbs down created.
+-- +goose Up
+CREATE TABLE session_feedback (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    rating INTEGER NOT NULL,
+    comment TEXT,
+    operator TEXT,
+    memory_id INTEGER REFERENCES memories(id),
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_session_feedback_session ON session_feedback(session_id);
+CREATE INDEX idx_session_feedback_created ON session_feedback(created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_session_feedback_created;
+DROP INDEX IF EXISTS idx_session_feedback_session;
+DROP TABLE IF EXISTS session_feedback;
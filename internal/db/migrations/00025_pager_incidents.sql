@@ -0,0 +1,23 @@
+-- Pager incidents: incidents opened with PagerDuty or Opsgenie for a service,
+-- linked to the session that raised them and resolved once verified.
+-- +goose Up
+CREATE TABLE pager_incidents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider TEXT NOT NULL,
+    dedup_key TEXT NOT NULL,
+    service TEXT NOT NULL,
+    session_id INTEGER REFERENCES sessions(id),
+    summary TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'triggered',
+    created_at TEXT NOT NULL,
+    resolved_at TEXT,
+    resolved_session_id INTEGER REFERENCES sessions(id)
+);
+
+CREATE INDEX idx_pager_incidents_service ON pager_incidents(service, status);
+CREATE INDEX idx_pager_incidents_session ON pager_incidents(session_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_pager_incidents_session;
+DROP INDEX IF EXISTS idx_pager_incidents_service;
+DROP TABLE IF EXISTS pager_incidents;
@@ -0,0 +1,9 @@
+-- Session working directory: the directory the CLI ran in and the git commit
+-- checked out there when the session started.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN work_dir TEXT;
+ALTER TABLE sessions ADD COLUMN git_sha TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN git_sha;
+ALTER TABLE sessions DROP COLUMN work_dir;
@@ -0,0 +1,24 @@
+-- Session commits: file changes a Tier 2 or Tier 3 session left in a git
+-- repository, committed to a branch of their own, with the diff shown on the
+-- session page and the push and pull request outcome.
+-- +goose Up
+CREATE TABLE session_commits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    repo TEXT NOT NULL,
+    branch TEXT NOT NULL,
+    base_branch TEXT NOT NULL,
+    commit_sha TEXT NOT NULL,
+    diff_stat TEXT NOT NULL,
+    diff TEXT NOT NULL,
+    pushed INTEGER NOT NULL DEFAULT 0,
+    pr_url TEXT,
+    error TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_session_commits_session ON session_commits(session_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_session_commits_session;
+DROP TABLE IF EXISTS session_commits;
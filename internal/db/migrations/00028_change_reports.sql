@@ -0,0 +1,33 @@
+-- Change reports: file changes a sandboxed session proposed instead of
+-- making, held until an operator applies or discards them.
+-- +goose Up
+CREATE TABLE change_reports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL UNIQUE REFERENCES sessions(id),
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TEXT NOT NULL,
+    decided_at TEXT,
+    decided_by TEXT,
+    error TEXT
+);
+
+CREATE INDEX idx_change_reports_status ON change_reports(status);
+
+CREATE TABLE change_files (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    report_id INTEGER NOT NULL REFERENCES change_reports(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    mode INTEGER NOT NULL,
+    old_content BLOB,
+    new_content BLOB,
+    diff TEXT NOT NULL
+);
+
+CREATE INDEX idx_change_files_report ON change_files(report_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_change_files_report;
+DROP TABLE IF EXISTS change_files;
+DROP INDEX IF EXISTS idx_change_reports_status;
+DROP TABLE IF EXISTS change_reports;
@@ -0,0 +1,18 @@
+-- Prompt versions: every edit to a tier prompt saved from the dashboard's
+-- prompt editor, keyed by the configured prompt path. With the database
+-- prompt store, the newest version of a path is the prompt sessions run.
+-- +goose Up
+CREATE TABLE prompt_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    path TEXT NOT NULL,
+    content TEXT NOT NULL,
+    saved_by TEXT,
+    note TEXT,
+    saved_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_prompt_versions_path ON prompt_versions(path, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_prompt_versions_path;
+DROP TABLE IF EXISTS prompt_versions;
@@ -0,0 +1,18 @@
+-- Parse warnings: text in a session's output that looked like a marker but
+-- was not in a format the supervisor parses, so what the agent tried to
+-- report is visible instead of silently dropped.
+-- +goose Up
+CREATE TABLE parse_warnings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    marker TEXT NOT NULL,
+    text TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_parse_warnings_session ON parse_warnings(session_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_parse_warnings_session;
+DROP TABLE IF EXISTS parse_warnings;
@@ -0,0 +1,22 @@
+-- Memory suggestions: recurring event patterns promoted to unverified
+-- memories for operator review, so the same pattern is not suggested again
+-- and the review queue can show how many events a suggestion came from.
+-- +goose Up
+CREATE TABLE memory_suggestions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    memory_id INTEGER NOT NULL REFERENCES memories(id),
+    service TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    event_count INTEGER NOT NULL,
+    first_seen TEXT NOT NULL,
+    last_seen TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_memory_suggestions_service ON memory_suggestions(service);
+CREATE INDEX idx_memory_suggestions_memory ON memory_suggestions(memory_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_memory_suggestions_memory;
+DROP INDEX IF EXISTS idx_memory_suggestions_service;
+DROP TABLE IF EXISTS memory_suggestions;
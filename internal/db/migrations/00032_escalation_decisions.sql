@@ -0,0 +1,28 @@
+-- Escalation decisions: the inputs the supervisor used when deciding whether
+-- a session's tier escalated, so the session page can explain why it did or
+-- did not without reconstructing it from logs.
+-- +goose Up
+CREATE TABLE escalation_decisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    from_tier INTEGER NOT NULL,
+    requested_tier INTEGER NOT NULL,
+    tier INTEGER NOT NULL,
+    outcome TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    source TEXT NOT NULL,
+    services TEXT NOT NULL,
+    handoff TEXT NOT NULL,
+    cooldowns TEXT NOT NULL,
+    chain_cost_usd REAL NOT NULL,
+    cost_24h_usd REAL NOT NULL,
+    dry_run INTEGER NOT NULL,
+    max_tier INTEGER NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_escalation_decisions_session ON escalation_decisions(session_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_escalation_decisions_session;
+DROP TABLE IF EXISTS escalation_decisions;
@@ -0,0 +1,17 @@
+-- Session tickets: external issues (GitHub, Gitea, Jira) a session is linked
+-- to, set through the API, parsed from [TICKET:provider:id] markers, or
+-- opened automatically for an unresolved incident.
+-- +goose Up
+CREATE TABLE session_tickets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    provider TEXT NOT NULL,
+    ticket_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    source TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    UNIQUE (session_id, provider, ticket_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS session_tickets;
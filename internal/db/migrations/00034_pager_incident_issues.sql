@@ -0,0 +1,7 @@
+-- Pager incident issues: the issue opened in the ticket project for an
+-- incident the escalation chain could not resolve.
+-- +goose Up
+ALTER TABLE pager_incidents ADD COLUMN issue_url TEXT;
+
+-- +goose Down
+ALTER TABLE pager_incidents DROP COLUMN issue_url;
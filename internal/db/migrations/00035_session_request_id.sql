@@ -0,0 +1,9 @@
+-- Session request ID: the ID of the dashboard or API request that triggered
+-- the session, so a caller can find the work its request started.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN request_id TEXT;
+CREATE INDEX idx_sessions_request_id ON sessions(request_id);
+
+-- +goose Down
+DROP INDEX idx_sessions_request_id;
+ALTER TABLE sessions DROP COLUMN request_id;
@@ -0,0 +1,22 @@
+-- Memory categories: the registry of categories memories are filed under,
+-- with a description of each, seeded with the built-in categories. Memories
+-- record when they were last reinforced, for per-category statistics.
+-- +goose Up
+CREATE TABLE memory_categories (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
+
+INSERT INTO memory_categories (name, description, created_at) VALUES
+    ('timing', 'When things happen: slow starts, scheduled jobs, and windows when a service is expected to be down', strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    ('dependency', 'What a service needs to be healthy, such as a database, a mount, or another service', strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    ('behavior', 'How a service normally behaves, including quirks that look like failures but are not', strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    ('remediation', 'What fixes a service, and what does not', strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    ('maintenance', 'Planned upkeep: upgrades, backups, and certificate renewals', strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+
+ALTER TABLE memories ADD COLUMN reinforced_at TEXT;
+
+-- +goose Down
+ALTER TABLE memories DROP COLUMN reinforced_at;
+DROP TABLE memory_categories;
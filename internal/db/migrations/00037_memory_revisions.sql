@@ -0,0 +1,20 @@
+-- Memory revisions: each confidence change the scoring model makes to a
+-- memory, with the scoring parameters in effect, so a memory's history can
+-- be explained after the parameters are tuned.
+-- +goose Up
+CREATE TABLE memory_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    memory_id INTEGER NOT NULL REFERENCES memories(id),
+    session_id INTEGER REFERENCES sessions(id),
+    change TEXT NOT NULL,
+    confidence_before REAL,
+    confidence_after REAL NOT NULL,
+    reinforce REAL NOT NULL,
+    contradict REAL NOT NULL,
+    decay REAL NOT NULL,
+    created_at TEXT NOT NULL
+);
+CREATE INDEX idx_memory_revisions_memory ON memory_revisions(memory_id, id);
+
+-- +goose Down
+DROP TABLE memory_revisions;
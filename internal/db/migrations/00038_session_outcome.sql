@@ -0,0 +1,21 @@
+-- Session outcome: what a finished session found and did (healthy,
+-- issues-observed, remediated, remediation-failed, inconclusive), classified
+-- when it ends. Existing sessions are classified from their status, events,
+-- and recorded remediations.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN outcome TEXT;
+CREATE INDEX idx_sessions_outcome ON sessions(outcome);
+UPDATE sessions SET outcome = CASE
+    WHEN status = 'running' THEN NULL
+    WHEN EXISTS (SELECT 1 FROM cooldown_actions c WHERE c.session_id = sessions.id AND c.success = 0) THEN 'remediation-failed'
+    WHEN status NOT IN ('completed', 'escalated', 'reopened') THEN 'inconclusive'
+    WHEN EXISTS (SELECT 1 FROM cooldown_actions c WHERE c.session_id = sessions.id) THEN
+        CASE WHEN status = 'completed' THEN 'remediated' ELSE 'remediation-failed' END
+    WHEN status IN ('escalated', 'reopened') THEN 'issues-observed'
+    WHEN EXISTS (SELECT 1 FROM events e WHERE e.session_id = sessions.id AND e.level IN ('warning', 'critical') AND e.service IS NOT NULL) THEN 'issues-observed'
+    ELSE 'healthy'
+END;
+
+-- +goose Down
+DROP INDEX idx_sessions_outcome;
+ALTER TABLE sessions DROP COLUMN outcome;
@@ -0,0 +1,7 @@
+-- Chain summary: a summary of a whole escalation chain, from the first tier
+-- to its verification, stored on the chain's root session.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN chain_summary TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN chain_summary;
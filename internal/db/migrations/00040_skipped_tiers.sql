@@ -0,0 +1,7 @@
+-- Skipped tiers: the tiers an escalation passed over on its way to this
+-- session, e.g. "2" for a Tier 3 session escalated directly from Tier 1.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN skipped_tiers TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN skipped_tiers;
@@ -0,0 +1,17 @@
+-- Session redactions: how many credential values the redaction filter
+-- replaced in a session's output, one row per rule (the variable the value
+-- came from), so a sudden rise in redactions, a sign that something is
+-- leaking credentials into tool output, is visible.
+-- +goose Up
+CREATE TABLE session_redactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    rule TEXT NOT NULL,
+    count INTEGER NOT NULL,
+    created_at TEXT NOT NULL
+);
+CREATE INDEX idx_session_redactions_session ON session_redactions(session_id);
+CREATE INDEX idx_session_redactions_created ON session_redactions(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS session_redactions;
@@ -0,0 +1,7 @@
+-- Log object: the object storage key a session's log was moved to once it
+-- was old enough to offload; the file at log_file is then only a stub.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN log_object TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN log_object;
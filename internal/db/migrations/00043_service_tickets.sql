@@ -0,0 +1,18 @@
+-- Service tickets: the issues opened automatically for a service's
+-- unresolved incident, kept open until the service is found healthy, so a
+-- later failure of the same incident does not open another.
+-- +goose Up
+CREATE TABLE service_tickets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    ticket_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    status TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    closed_at TEXT
+);
+CREATE INDEX idx_service_tickets_service ON service_tickets(service, status);
+
+-- +goose Down
+DROP TABLE IF EXISTS service_tickets;
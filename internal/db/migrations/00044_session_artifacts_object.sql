@@ -0,0 +1,8 @@
+-- Artifacts object: the object storage key a session's commit diffs and
+-- decided change report files were moved to once it was old enough to
+-- offload; their columns are then empty.
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN artifacts_object TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN artifacts_object;
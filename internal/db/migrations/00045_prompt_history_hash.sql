@@ -0,0 +1,32 @@
+-- Prompt history hash: prompts are deduplicated by a keyed hash instead of
+-- their text, so that under encryption at rest the prompt can be stored
+-- sealed. The table is rebuilt to drop the UNIQUE constraint on prompt.
+-- Existing rows are hashed, and sealed, when the database is opened, as
+-- that needs the encryption key.
+-- +goose Up
+CREATE TABLE prompt_history_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    prompt TEXT NOT NULL,
+    prompt_hash TEXT UNIQUE,
+    favorite INTEGER NOT NULL DEFAULT 0,
+    use_count INTEGER NOT NULL DEFAULT 1,
+    last_used_at TEXT NOT NULL
+);
+INSERT INTO prompt_history_new (id, prompt, favorite, use_count, last_used_at)
+    SELECT id, prompt, favorite, use_count, last_used_at FROM prompt_history;
+DROP TABLE prompt_history;
+ALTER TABLE prompt_history_new RENAME TO prompt_history;
+
+-- +goose Down
+-- Sealed prompts stay sealed, and are unique only by their ciphertext.
+CREATE TABLE prompt_history_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    prompt TEXT NOT NULL UNIQUE,
+    favorite INTEGER NOT NULL DEFAULT 0,
+    use_count INTEGER NOT NULL DEFAULT 1,
+    last_used_at TEXT NOT NULL
+);
+INSERT INTO prompt_history_old (id, prompt, favorite, use_count, last_used_at)
+    SELECT id, prompt, favorite, use_count, last_used_at FROM prompt_history;
+DROP TABLE prompt_history;
+ALTER TABLE prompt_history_old RENAME TO prompt_history;
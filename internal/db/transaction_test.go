@@ -9,12 +9,12 @@ import (
 // Governing: SPEC-0022 REQ "Transaction Safety"
 
 // TestMigrationTransactionSafety verifies that goose applies each migration
-// within a transaction. After all 7 migrations run successfully, every table
+// within a transaction. After all 45 migrations run successfully, every table
 // and index exists (atomic commit), and goose_db_version records all versions.
 func TestMigrationTransactionSafety(t *testing.T) {
 	d := openTestDB(t)
 
-	// All tables created by migrations 1-7 must exist.
+	// All tables created by migrations 1-45 must exist.
 	tables := []string{
 		"sessions",
 		"health_checks",
@@ -35,7 +35,7 @@ func TestMigrationTransactionSafety(t *testing.T) {
 		}
 	}
 
-	// goose_db_version must have recorded all 7 migrations.
+	// goose_db_version must have recorded all 45 migrations.
 	var maxVersion int64
 	err := d.Conn().QueryRow(
 		`SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE version_id > 0`,
@@ -43,8 +43,8 @@ func TestMigrationTransactionSafety(t *testing.T) {
 	if err != nil {
 		t.Fatalf("query goose_db_version: %v", err)
 	}
-	if maxVersion != 7 {
-		t.Fatalf("expected goose_db_version max version 7, got %d", maxVersion)
+	if maxVersion != 45 {
+		t.Fatalf("expected goose_db_version max version 45, got %d", maxVersion)
 	}
 }
 
@@ -61,7 +61,7 @@ func TestFailedMigrationRollback(t *testing.T) {
 		t.Fatalf("initial Open: %v", err)
 	}
 
-	// Verify we have 7 applied migrations.
+	// Verify we have 45 applied migrations.
 	var count int
 	err = d.Conn().QueryRow(
 		`SELECT COUNT(*) FROM goose_db_version WHERE version_id > 0`,
@@ -69,8 +69,8 @@ func TestFailedMigrationRollback(t *testing.T) {
 	if err != nil {
 		t.Fatalf("count goose_db_version: %v", err)
 	}
-	if count != 7 {
-		t.Fatalf("expected 7 applied migrations, got %d", count)
+	if count != 45 {
+		t.Fatalf("expected 45 applied migrations, got %d", count)
 	}
 
 	// Now simulate what would happen if a DDL statement within a migration
@@ -97,9 +97,9 @@ func TestFailedMigrationRollback(t *testing.T) {
 		t.Fatalf("rows error: %v", err)
 	}
 
-	// Expect exactly versions 1 through 7, no gaps.
-	if len(versions) != 7 {
-		t.Fatalf("expected 7 versions, got %d: %v", len(versions), versions)
+	// Expect exactly versions 1 through 45, no gaps.
+	if len(versions) != 45 {
+		t.Fatalf("expected 45 versions, got %d: %v", len(versions), versions)
 	}
 	for i, v := range versions {
 		if v != int64(i+1) {
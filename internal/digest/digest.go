@@ -0,0 +1,290 @@
+// Package digest pushes warning and critical events as notifications,
+// coalesced per service. A flapping service would otherwise page once per
+// event; instead its events are grouped over a window into a single push,
+// e.g. "jellyfin: 12 warnings, 3 criticals between 02:00–04:00". The first
+// critical event of a window is always delivered immediately.
+//
+// Notifications the agent sends while a digest is configured come here too,
+// through the supervisor's notify endpoint: the first with a given title in
+// a window is delivered at once, and repeats are counted into one digest.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+const (
+	// pollInterval is how often new events are read.
+	pollInterval = 15 * time.Second
+
+	// maxMessages is how many of a group's most recent event messages are
+	// quoted in its digest.
+	maxMessages = 5
+
+	// generalService groups events not tied to a service.
+	generalService = "general"
+
+	// noticeQueueSize bounds the agent notifications waiting to be read.
+	noticeQueueSize = 64
+)
+
+// SendFunc delivers one notification.
+type SendFunc func(ctx context.Context, title, body string) error
+
+// Digester coalesces events into per-service notifications.
+type Digester struct {
+	db     *db.DB
+	window time.Duration
+	send   SendFunc
+	now    func() time.Time
+
+	lastID   int64
+	groups   map[string]*group
+	incoming chan notice
+	notices  map[string]*repeats
+}
+
+// notice is a notification the agent sent.
+type notice struct {
+	title, body string
+	at          time.Time
+}
+
+// repeats counts the notifications with one title in the current window,
+// after the first, which was delivered immediately.
+type repeats struct {
+	title    string
+	opened   time.Time
+	first    time.Time // first and last repeat times
+	last     time.Time
+	count    int
+	messages []string // most recent bodies, oldest first
+}
+
+// group is one service's events in the current window.
+type group struct {
+	service   string
+	opened    time.Time // when the first event was read; the window runs from here
+	first     time.Time // first and last event times
+	last      time.Time
+	warnings  int
+	criticals int
+	alerted   bool     // the window's first critical was sent immediately
+	messages  []string // most recent messages, oldest first
+}
+
+// FromConfig builds a Digester from CLAUDEOPS_NOTIFY_DIGEST_WINDOW. Returns
+// nil when the window is zero or no Apprise URLs are configured.
+func FromConfig(cfg *config.Config, database *db.DB, send SendFunc) *Digester {
+	if cfg.NotifyDigestWindow <= 0 || cfg.AppriseURLs == "" {
+		return nil
+	}
+	return New(database, time.Duration(cfg.NotifyDigestWindow)*time.Minute, send)
+}
+
+// New creates a Digester grouping events over window.
+func New(database *db.DB, window time.Duration, send SendFunc) *Digester {
+	return &Digester{
+		db:     database,
+		window: window,
+		send:   send,
+		now:    time.Now,
+		groups: make(map[string]*group),
+
+		incoming: make(chan notice, noticeQueueSize),
+		notices:  make(map[string]*repeats),
+	}
+}
+
+// Notify queues a notification the agent sent, to be delivered at once if
+// it is the first with its title in the window and coalesced otherwise. It
+// does not block: when the queue is full, the notification is delivered
+// without coalescing.
+func (d *Digester) Notify(title, body string) {
+	select {
+	case d.incoming <- notice{title: title, body: body, at: d.now()}:
+	default:
+		go d.deliver(context.Background(), title, body)
+	}
+}
+
+// Run delivers notifications for events recorded after it starts, until
+// ctx is cancelled.
+func (d *Digester) Run(ctx context.Context) {
+	id, err := d.db.LatestEventID()
+	if err != nil {
+		log.Printf("digest: %v", err)
+	}
+	d.lastID = id
+	log.Printf("digest: coalescing event notifications per service over %s", d.window)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-d.incoming:
+			d.addNotice(ctx, n)
+		case <-ticker.C:
+			d.poll(ctx)
+		}
+	}
+}
+
+// addNotice delivers an agent notification if it is the first with its
+// title in the window, and counts it otherwise.
+func (d *Digester) addNotice(ctx context.Context, n notice) {
+	r := d.notices[n.title]
+	if r == nil {
+		d.notices[n.title] = &repeats{title: n.title, opened: d.now()}
+		d.deliver(ctx, n.title, n.body)
+		return
+	}
+	if r.count == 0 {
+		r.first = n.at
+	}
+	r.last = n.at
+	r.count++
+	r.messages = append(r.messages, n.body)
+	if len(r.messages) > maxMessages {
+		r.messages = r.messages[1:]
+	}
+}
+
+// poll reads new events into their groups and flushes groups whose window
+// has ended.
+func (d *Digester) poll(ctx context.Context) {
+	events, err := d.db.ListEventsAfterLevels(d.lastID, []string{"warning", "critical"}, 500)
+	if err != nil {
+		log.Printf("digest: %v", err)
+	}
+	for _, e := range events {
+		d.lastID = e.ID
+		d.add(ctx, e)
+	}
+	d.flush(ctx)
+}
+
+// add counts an event in its service's group, sending it right away if it
+// is the first critical of the window.
+func (d *Digester) add(ctx context.Context, e db.Event) {
+	service := generalService
+	if e.Service != nil && *e.Service != "" {
+		service = *e.Service
+	}
+	at, err := time.Parse(time.RFC3339, e.CreatedAt)
+	if err != nil {
+		at = d.now()
+	}
+	g := d.groups[service]
+	if g == nil {
+		g = &group{service: service, opened: d.now(), first: at}
+		d.groups[service] = g
+	}
+	g.last = at
+	g.messages = append(g.messages, e.Message)
+	if len(g.messages) > maxMessages {
+		g.messages = g.messages[1:]
+	}
+	if e.Level != "critical" {
+		g.warnings++
+		return
+	}
+	g.criticals++
+	if !g.alerted {
+		g.alerted = true
+		d.deliver(ctx, fmt.Sprintf("Claude Ops: %s critical", service), e.Message)
+	}
+}
+
+// flush sends a digest for every group whose window has ended. A group
+// holding only the critical already sent is dropped without a digest.
+func (d *Digester) flush(ctx context.Context) {
+	now := d.now()
+	services := make([]string, 0, len(d.groups))
+	for s, g := range d.groups {
+		if !now.Before(g.opened.Add(d.window)) {
+			services = append(services, s)
+		}
+	}
+	sort.Strings(services)
+	for _, s := range services {
+		g := d.groups[s]
+		delete(d.groups, s)
+		if g.alerted && g.warnings == 0 && g.criticals == 1 {
+			continue
+		}
+		d.deliver(ctx, "Claude Ops: "+g.headline(), g.body())
+	}
+
+	titles := make([]string, 0, len(d.notices))
+	for t, r := range d.notices {
+		if !now.Before(r.opened.Add(d.window)) {
+			titles = append(titles, t)
+		}
+	}
+	sort.Strings(titles)
+	for _, t := range titles {
+		r := d.notices[t]
+		delete(d.notices, t)
+		if r.count == 0 {
+			continue
+		}
+		d.deliver(ctx, fmt.Sprintf("%s (%s)", r.title, plural(r.count, "repeat")), r.body())
+	}
+}
+
+func (d *Digester) deliver(ctx context.Context, title, body string) {
+	if err := d.send(ctx, title, body); err != nil {
+		log.Printf("digest: notify: %v", err)
+	}
+}
+
+// headline summarises the group, e.g. "jellyfin: 12 warnings, 3 criticals".
+func (g *group) headline() string {
+	var counts []string
+	if g.warnings > 0 {
+		counts = append(counts, plural(g.warnings, "warning"))
+	}
+	if g.criticals > 0 {
+		counts = append(counts, plural(g.criticals, "critical"))
+	}
+	return g.service + ": " + strings.Join(counts, ", ")
+}
+
+func (g *group) body() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s between %s–%s", g.headline(), g.first.Local().Format("15:04"), g.last.Local().Format("15:04"))
+	if g.alerted {
+		b.WriteString(" (the first critical was sent immediately)")
+	}
+	b.WriteString("\n\nMost recent:\n")
+	for _, m := range g.messages {
+		fmt.Fprintf(&b, "- %s\n", m)
+	}
+	return b.String()
+}
+
+func (r *repeats) body() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Sent %s more between %s–%s\n\nMost recent:\n", plural(r.count, "time"), r.first.Local().Format("15:04"), r.last.Local().Format("15:04"))
+	for _, m := range r.messages {
+		fmt.Fprintf(&b, "- %s\n", m)
+	}
+	return b.String()
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,144 @@
+package digest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+type sent struct{ title, body string }
+
+func newTestDigester(t *testing.T) (*Digester, *[]sent, *time.Time) {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	var out []sent
+	now := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	d := New(database, time.Hour, func(_ context.Context, title, body string) error {
+		out = append(out, sent{title, body})
+		return nil
+	})
+	d.now = func() time.Time { return now }
+	return d, &out, &now
+}
+
+func insertEvent(t *testing.T, d *Digester, level, service, msg string, at time.Time) {
+	t.Helper()
+	e := &db.Event{Level: level, Message: msg, CreatedAt: at.Format(time.RFC3339)}
+	if service != "" {
+		e.Service = &service
+	}
+	if _, err := d.db.InsertEvent(e); err != nil {
+		t.Fatalf("insert event: %v", err)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if d := FromConfig(&config.Config{AppriseURLs: "json://x"}, nil, nil); d != nil {
+		t.Error("expected nil digester without a window")
+	}
+	if d := FromConfig(&config.Config{NotifyDigestWindow: 30}, nil, nil); d != nil {
+		t.Error("expected nil digester without apprise URLs")
+	}
+	d := FromConfig(&config.Config{NotifyDigestWindow: 30, AppriseURLs: "json://x"}, nil, nil)
+	if d == nil || d.window != 30*time.Minute {
+		t.Fatalf("expected 30m digester, got %+v", d)
+	}
+}
+
+func TestPoll_GroupsPerServiceAndSendsFirstCriticalImmediately(t *testing.T) {
+	d, out, now := newTestDigester(t)
+	ctx := context.Background()
+
+	insertEvent(t, d, "info", "jellyfin", "healthy", *now)
+	insertEvent(t, d, "warning", "jellyfin", "slow response", *now)
+	insertEvent(t, d, "critical", "jellyfin", "container exited", now.Add(time.Minute))
+	insertEvent(t, d, "critical", "jellyfin", "container exited again", now.Add(2*time.Minute))
+	insertEvent(t, d, "warning", "", "disk at 85%", now.Add(3*time.Minute))
+	d.poll(ctx)
+
+	if len(*out) != 1 || (*out)[0].title != "Claude Ops: jellyfin critical" || (*out)[0].body != "container exited" {
+		t.Fatalf("expected only the first critical sent, got %+v", *out)
+	}
+
+	*now = now.Add(30 * time.Minute)
+	insertEvent(t, d, "warning", "jellyfin", "slow response", *now)
+	d.poll(ctx)
+	if len(*out) != 1 {
+		t.Fatalf("expected no digest before the window ends, got %+v", *out)
+	}
+
+	*now = now.Add(30 * time.Minute)
+	d.poll(ctx)
+	if len(*out) != 3 {
+		t.Fatalf("expected two digests after the window, got %+v", *out)
+	}
+	general, jellyfin := (*out)[1], (*out)[2]
+	if general.title != "Claude Ops: general: 1 warning" {
+		t.Errorf("unexpected general title %q", general.title)
+	}
+	if jellyfin.title != "Claude Ops: jellyfin: 2 warnings, 2 criticals" {
+		t.Errorf("unexpected jellyfin title %q", jellyfin.title)
+	}
+	for _, want := range []string{"between", "container exited again", "first critical was sent immediately"} {
+		if !strings.Contains(jellyfin.body, want) {
+			t.Errorf("jellyfin digest body missing %q:\n%s", want, jellyfin.body)
+		}
+	}
+	if strings.Contains(jellyfin.body, "healthy") {
+		t.Errorf("info events should not be included:\n%s", jellyfin.body)
+	}
+}
+
+func TestFlush_SkipsLoneCritical(t *testing.T) {
+	d, out, now := newTestDigester(t)
+	ctx := context.Background()
+
+	insertEvent(t, d, "critical", "caddy", "down", *now)
+	d.poll(ctx)
+	*now = now.Add(time.Hour)
+	d.poll(ctx)
+	if len(*out) != 1 {
+		t.Fatalf("expected only the immediate critical, got %+v", *out)
+	}
+
+	// A new window sends its first critical immediately again.
+	insertEvent(t, d, "critical", "caddy", "down again", *now)
+	d.poll(ctx)
+	if len(*out) != 2 || (*out)[1].body != "down again" {
+		t.Fatalf("expected a new immediate critical, got %+v", *out)
+	}
+}
+
+func TestAgentNotificationsCoalescePerTitle(t *testing.T) {
+	d, out, now := newTestDigester(t)
+	ctx := context.Background()
+
+	for _, body := range []string{"restarted", "restarted again", "restarted a third time"} {
+		d.addNotice(ctx, notice{title: "Claude Ops: Auto-remediated jellyfin", body: body, at: *now})
+		*now = now.Add(10 * time.Minute)
+	}
+	d.addNotice(ctx, notice{title: "Claude Ops: Needs human attention — caddy", body: "cooldown reached", at: *now})
+	if len(*out) != 2 || (*out)[0].body != "restarted" || (*out)[1].title != "Claude Ops: Needs human attention — caddy" {
+		t.Fatalf("expected the first of each title at once, got %+v", *out)
+	}
+
+	*now = now.Add(time.Hour)
+	d.flush(ctx)
+	if len(*out) != 3 {
+		t.Fatalf("expected one digest of the repeats, got %+v", *out)
+	}
+	digest := (*out)[2]
+	if digest.title != "Claude Ops: Auto-remediated jellyfin (2 repeats)" || !strings.Contains(digest.body, "- restarted a third time") {
+		t.Errorf("unexpected digest %+v", digest)
+	}
+}
@@ -0,0 +1,386 @@
+// Package doctor checks that a deployment can run sessions before it has
+// to: embedded assets, writable directories and database, prompt files, the
+// Claude CLI, the MCP config, and the optional schema and policy files. Each
+// problem names the setting or mount that fixes it, so a missing volume is
+// found at startup instead of halfway through a session.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/policy"
+	"github.com/joestump/claude-ops/prompts"
+)
+
+// Check statuses. Skip is for checks of features that are not configured.
+const (
+	OK   = "ok"
+	Warn = "warn"
+	Fail = "fail"
+	Skip = "skip"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	// Fix says how to resolve a warning or failure.
+	Fix string `json:"fix,omitempty"`
+	// Latency is how long a network check took.
+	Latency time.Duration `json:"-"`
+}
+
+// MarshalJSON reports Latency in milliseconds.
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result
+	return json.Marshal(struct {
+		result
+		Latency int64 `json:"latency_ms,omitempty"`
+	}{result(r), r.Latency.Milliseconds()})
+}
+
+// Doctor runs the checks against a configuration.
+type Doctor struct {
+	cfg *config.Config
+	// assets checks the dashboard assets embedded in the binary.
+	assets func() error
+	// lookPath and cliVersion find and query the Claude CLI; replaced in
+	// tests.
+	lookPath   func(string) (string, error)
+	cliVersion func(context.Context) (string, error)
+	// The network checks' dependencies, likewise replaced in tests.
+	client     *http.Client
+	lookupHost func(context.Context, string) ([]string, error)
+	notify     func(ctx context.Context, title, body string) error
+	getenv     func(string) string
+	githubAPI  string
+}
+
+// New returns a Doctor for cfg. assets verifies the dashboard templates and
+// static files (web.CheckAssets); it is passed in so this package does not
+// depend on the web server.
+func New(cfg *config.Config, assets func() error) *Doctor {
+	return &Doctor{
+		cfg:        cfg,
+		assets:     assets,
+		lookPath:   exec.LookPath,
+		cliVersion: claudeVersion,
+		client:     &http.Client{Timeout: networkTimeout},
+		lookupHost: net.DefaultResolver.LookupHost,
+		notify:     appriseNotify(cfg.AppriseURLs),
+		getenv:     os.Getenv,
+		githubAPI:  "https://api.github.com",
+	}
+}
+
+// Failed reports whether any result is a failure.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Problems returns the warnings and failures in results.
+func Problems(results []Result) []Result {
+	var out []Result
+	for _, r := range results {
+		if r.Status == Warn || r.Status == Fail {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Assets checks only what is built into the binary. It needs no
+// configuration, so it also runs at image build time.
+func (d *Doctor) Assets() []Result {
+	r := Result{Name: "embedded assets", Status: OK, Detail: "dashboard templates, static files, and default prompts are embedded"}
+	var missing []string
+	for _, name := range []string{"tier1-observe.md", "tier2-investigate.md", "tier3-remediate.md", "verify.md"} {
+		if _, ok := prompts.Default(name); !ok {
+			missing = append(missing, name)
+		}
+	}
+	var err error
+	if d.assets != nil {
+		err = d.assets()
+	}
+	if len(missing) > 0 {
+		err = errors.Join(err, fmt.Errorf("default prompts missing: %s", strings.Join(missing, ", ")))
+	}
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		r.Fix = "rebuild the binary from a full checkout (go build ./cmd/claudeops embeds internal/web/templates, internal/web/static, and prompts)"
+	}
+	return []Result{r}
+}
+
+// Run runs every check, in the order a session depends on them.
+func (d *Doctor) Run(ctx context.Context) []Result {
+	results := d.Assets()
+	results = append(results, d.directories()...)
+	results = append(results, d.database())
+	results = append(results, d.prompts()...)
+	results = append(results, d.cli(ctx))
+	results = append(results, d.mcp()...)
+	results = append(results, d.repos())
+	results = append(results, d.schema())
+	if d.cfg.PolicyFile != "" {
+		results = append(results, d.policy())
+	}
+	return results
+}
+
+func (d *Doctor) directories() []Result {
+	var out []Result
+	for _, dir := range []struct{ name, path, key string }{
+		{"state directory", d.cfg.StateDir, "state_dir"},
+		{"results directory", d.cfg.ResultsDir, "results_dir"},
+		{"repos directory", d.cfg.ReposDir, "repos_dir"},
+	} {
+		r := Result{Name: dir.name, Status: OK, Detail: dir.path + " is writable"}
+		if err := config.WritableDir(dir.path); err != nil {
+			r.Status, r.Detail = Fail, err.Error()
+			r.Fix = fmt.Sprintf("mount a writable volume at %s or set %s", dir.path, config.Setting(dir.key))
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// database opens the database, applying pending migrations as startup
+// would, and writes to it.
+func (d *Doctor) database() Result {
+	path := filepath.Join(d.cfg.StateDir, "claudeops.db")
+	r := Result{Name: "database", Status: OK}
+	fix := fmt.Sprintf("check that %s is on a writable volume owned by the user the supervisor runs as", path)
+	database, err := db.Open(path)
+	if err != nil {
+		return Result{Name: r.Name, Status: Fail, Detail: err.Error(), Fix: fix}
+	}
+	defer database.Close() //nolint:errcheck
+	if err := database.SetConfig("doctor_checked_at", time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return Result{Name: r.Name, Status: Fail, Detail: err.Error(), Fix: fix}
+	}
+	migrations, err := database.ListMigrations()
+	if err != nil {
+		return Result{Name: r.Name, Status: Fail, Detail: err.Error(), Fix: fix}
+	}
+	r.Detail = fmt.Sprintf("%s is writable (%d migrations applied)", path, len(migrations))
+	return r
+}
+
+// prompts checks the prompt file for each enabled tier. A missing file that
+// has an embedded default only warns: sessions still run, but on the
+// bundled prompt rather than the one the mount was meant to provide.
+func (d *Doctor) prompts() []Result {
+	var out []Result
+	for _, p := range []struct {
+		name, path, key string
+		needed          bool
+	}{
+		{"tier 1 prompt", d.cfg.Prompt, "prompt", true},
+		{"tier 2 prompt", d.cfg.Tier2Prompt, "tier2_prompt", d.cfg.MaxTier >= 2},
+		{"tier 3 prompt", d.cfg.Tier3Prompt, "tier3_prompt", d.cfg.MaxTier >= 3},
+		{"verification prompt", d.cfg.VerifyPrompt, "verify_prompt", d.cfg.VerifyDelay > 0},
+	} {
+		if !p.needed {
+			continue
+		}
+		r := Result{Name: p.name, Status: OK, Detail: p.path}
+		if _, err := os.Stat(p.path); err != nil {
+			r.Status, r.Detail = Fail, err.Error()
+			r.Fix = fmt.Sprintf("mount the prompts directory or set %s", config.Setting(p.key))
+			if _, ok := prompts.Default(p.path); ok && os.IsNotExist(err) {
+				r.Status = Warn
+				r.Detail = p.path + " not found; the copy embedded in the binary will be used"
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func (d *Doctor) cli(ctx context.Context) Result {
+	r := Result{Name: "claude CLI"}
+	if d.cfg.Demo {
+		r.Status, r.Detail = OK, "not needed in demo mode"
+		return r
+	}
+	path, err := d.lookPath("claude")
+	if err != nil {
+		r.Status, r.Detail = Fail, "claude not found in PATH"
+		r.Fix = "install Claude Code (npm install -g @anthropic-ai/claude-code), or use the Docker image, or set --demo to try the dashboard without it"
+		return r
+	}
+	version, err := d.cliVersion(ctx)
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("%s --version: %v", path, err)
+		r.Fix = "reinstall Claude Code; the binary on PATH does not run"
+		return r
+	}
+	r.Status, r.Detail = OK, fmt.Sprintf("%s (%s)", version, path)
+	return r
+}
+
+// mcp parses the MCP config and each repo's .claude-ops/mcp.json, which are
+// merged before every session, and checks that stdio servers' commands
+// exist.
+func (d *Doctor) mcp() []Result {
+	r := Result{Name: "MCP config", Status: OK}
+	servers, err := readMCPServers(d.cfg.MCPConfig)
+	switch {
+	case os.IsNotExist(err):
+		r.Status, r.Detail = Warn, d.cfg.MCPConfig+" not found; sessions run without MCP servers"
+		r.Fix = "mount an MCP config or set " + config.Setting("mcp_config") + " if sessions need MCP servers"
+		return []Result{r}
+	case err != nil:
+		r.Status, r.Detail = Fail, err.Error()
+		r.Fix = "fix the JSON in " + d.cfg.MCPConfig + "; merging it fails before every session"
+		return []Result{r}
+	}
+	out := []Result{r}
+
+	repoConfigs, _ := filepath.Glob(filepath.Join(d.cfg.ReposDir, "*", ".claude-ops", "mcp.json"))
+	for _, path := range repoConfigs {
+		repoServers, err := readMCPServers(path)
+		if err != nil {
+			out = append(out, Result{Name: "MCP config", Status: Fail, Detail: err.Error(),
+				Fix: "fix the JSON in " + path + "; merging it fails before every session"})
+			continue
+		}
+		for name, s := range repoServers {
+			servers[name] = s
+		}
+	}
+
+	var missing []string
+	for name, s := range servers {
+		if s.Command == "" {
+			continue
+		}
+		if _, err := d.lookPath(s.Command); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%s)", name, s.Command))
+		}
+	}
+	if len(missing) > 0 {
+		out[0].Status = Warn
+		out[0].Detail = "commands not found for MCP servers: " + strings.Join(missing, ", ")
+		out[0].Fix = "install the commands in the image or remove the servers from the MCP config"
+	} else {
+		out[0].Detail = fmt.Sprintf("%d servers configured (%d from repos)", len(servers), len(repoConfigs))
+	}
+	return out
+}
+
+type mcpServer struct {
+	Command string `json:"command"`
+}
+
+func readMCPServers(path string) (map[string]mcpServer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		MCPServers map[string]mcpServer `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if doc.MCPServers == nil {
+		doc.MCPServers = make(map[string]mcpServer)
+	}
+	return doc.MCPServers, nil
+}
+
+// repos warns when no repositories are mounted: the agent then has no
+// infrastructure code or runbooks to work from.
+func (d *Doctor) repos() Result {
+	r := Result{Name: "repositories", Status: OK}
+	entries, err := os.ReadDir(d.cfg.ReposDir)
+	var n int
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			n++
+		}
+	}
+	if err != nil || n == 0 {
+		r.Status, r.Detail = Warn, "no repositories under "+d.cfg.ReposDir
+		r.Fix = "mount infrastructure repos under " + d.cfg.ReposDir + " (see docs/repo-mounting.md)"
+		return r
+	}
+	r.Detail = fmt.Sprintf("%d repositories under %s", n, d.cfg.ReposDir)
+	return r
+}
+
+// schema checks the JSON Schema for structured output. Without it the CLI
+// runs without --json-schema and escalation falls back to handoff files.
+func (d *Doctor) schema() Result {
+	r := Result{Name: "output schema", Status: OK, Detail: d.cfg.SchemaPath}
+	data, err := os.ReadFile(d.cfg.SchemaPath)
+	if err == nil && !json.Valid(data) {
+		err = fmt.Errorf("%s is not valid JSON", d.cfg.SchemaPath)
+	}
+	if err != nil {
+		r.Status, r.Detail = Warn, fmt.Sprintf("%v; structured output is disabled", err)
+		r.Fix = "mount the schemas directory or set " + config.Setting("schema_path")
+	}
+	return r
+}
+
+func (d *Doctor) policy() Result {
+	r := Result{Name: "policy file", Status: OK, Detail: d.cfg.PolicyFile}
+	if _, err := policy.Load(d.cfg.PolicyFile); err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		r.Fix = "fix the rule or set " + config.Setting("policy_file") + " to another file; startup stops on an invalid policy"
+	}
+	return r
+}
+
+// claudeVersion runs `claude --version`.
+func claudeVersion(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "claude", "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// WriteTable writes results as a table, each warning or failure followed by
+// its fix.
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tLATENCY\tDETAIL") //nolint:errcheck
+	for _, r := range results {
+		latency := ""
+		if r.Latency > 0 {
+			latency = r.Latency.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, strings.ToUpper(r.Status), latency, r.Detail) //nolint:errcheck
+		if r.Fix != "" {
+			fmt.Fprintf(tw, "\t\t\tfix: %s\n", r.Fix) //nolint:errcheck
+		}
+	}
+	return tw.Flush()
+}
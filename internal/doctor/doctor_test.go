@@ -0,0 +1,138 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+// testDoctor returns a Doctor for a deployment in a temp dir with every
+// file in place and a fake Claude CLI on PATH.
+func testDoctor(t *testing.T) (*Doctor, *config.Config) {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	cfg := &config.Config{
+		StateDir:    filepath.Join(dir, "state"),
+		ResultsDir:  filepath.Join(dir, "results"),
+		ReposDir:    filepath.Join(dir, "repos"),
+		Prompt:      write("prompts/tier1-observe.md", "observe"),
+		Tier2Prompt: write("prompts/tier2-investigate.md", "investigate"),
+		MaxTier:     2,
+		MCPConfig:   write("mcp.json", `{"mcpServers": {"docker": {"command": "docker-mcp"}, "web": {"type": "http", "url": "http://localhost"}}}`),
+		SchemaPath:  write("schemas/agent-response.json", `{"type": "object"}`),
+	}
+	write("repos/infra/README.md", "infra")
+	d := New(cfg, func() error { return nil })
+	d.lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	d.cliVersion = func(context.Context) (string, error) { return "2.0.0 (Claude Code)", nil }
+	return d, cfg
+}
+
+func find(t *testing.T, results []Result, name string) Result {
+	t.Helper()
+	for _, r := range results {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("no %q result in %+v", name, results)
+	return Result{}
+}
+
+func TestRunHealthy(t *testing.T) {
+	d, _ := testDoctor(t)
+	results := d.Run(context.Background())
+	if p := Problems(results); len(p) > 0 {
+		t.Fatalf("unexpected problems: %+v", p)
+	}
+	if r := find(t, results, "claude CLI"); !strings.Contains(r.Detail, "2.0.0") {
+		t.Errorf("claude CLI detail = %q", r.Detail)
+	}
+	if r := find(t, results, "MCP config"); !strings.Contains(r.Detail, "2 servers") {
+		t.Errorf("MCP config detail = %q", r.Detail)
+	}
+}
+
+func TestRunReportsProblemsWithFixes(t *testing.T) {
+	d, cfg := testDoctor(t)
+	d.assets = func() error { return errors.New("template index.html is not defined") }
+	d.lookPath = func(name string) (string, error) { return "", errors.New("not found") }
+	if err := os.WriteFile(cfg.MCPConfig, []byte(`{"mcpServers": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Tier2Prompt = filepath.Join(filepath.Dir(cfg.Prompt), "custom.md")
+	cfg.SchemaPath = filepath.Join(t.TempDir(), "missing.json")
+
+	results := d.Run(context.Background())
+	if !Failed(results) {
+		t.Fatal("expected failures")
+	}
+	for _, want := range []struct{ name, status, fix string }{
+		{"embedded assets", Fail, "rebuild"},
+		{"tier 2 prompt", Fail, "CLAUDEOPS_TIER2_PROMPT"},
+		{"claude CLI", Fail, "install Claude Code"},
+		{"MCP config", Fail, "fix the JSON"},
+		{"output schema", Warn, "CLAUDEOPS_SCHEMA_PATH"},
+	} {
+		r := find(t, results, want.name)
+		if r.Status != want.status || !strings.Contains(r.Fix, want.fix) {
+			t.Errorf("%s = %+v, want status %s and a fix mentioning %q", want.name, r, want.status, want.fix)
+		}
+	}
+}
+
+func TestMCPRepoConfigs(t *testing.T) {
+	d, cfg := testDoctor(t)
+	repoConfig := filepath.Join(cfg.ReposDir, "infra", ".claude-ops", "mcp.json")
+	if err := os.MkdirAll(filepath.Dir(repoConfig), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(repoConfig, []byte(`{"mcpServers": {"k8s": {"command": "kubectl-mcp"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d.lookPath = func(name string) (string, error) {
+		if name == "kubectl-mcp" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + name, nil
+	}
+	r := find(t, d.mcp(), "MCP config")
+	if r.Status != Warn || !strings.Contains(r.Detail, "k8s (kubectl-mcp)") {
+		t.Errorf("MCP config = %+v", r)
+	}
+}
+
+func TestMissingPromptWithEmbeddedDefaultWarns(t *testing.T) {
+	d, cfg := testDoctor(t)
+	if err := os.Remove(cfg.Prompt); err != nil {
+		t.Fatal(err)
+	}
+	r := find(t, d.prompts(), "tier 1 prompt")
+	if r.Status != Warn || !strings.Contains(r.Detail, "embedded") {
+		t.Errorf("tier 1 prompt = %+v", r)
+	}
+}
+
+func TestCLINotNeededInDemoMode(t *testing.T) {
+	d, cfg := testDoctor(t)
+	cfg.Demo = true
+	d.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	if r := d.cli(context.Background()); r.Status != OK {
+		t.Errorf("claude CLI in demo mode = %+v", r)
+	}
+}
@@ -0,0 +1,321 @@
+package doctor
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+// networkTimeout bounds each network check, so an unreachable endpoint is
+// reported rather than hanging the command.
+const networkTimeout = 10 * time.Second
+
+// anthropicAPIURL is the API used when ANTHROPIC_BASE_URL is not set.
+const anthropicAPIURL = "https://api.anthropic.com"
+
+// Network checks the endpoints sessions reach out to: the Anthropic API (or
+// the gateway in ANTHROPIC_BASE_URL), the git provider APIs pull requests go
+// to, the Apprise URLs (by sending a test notification), the browser
+// sidecar's DevTools endpoint, and DNS for all of their hosts. Checks for
+// endpoints that are not configured are skipped.
+func (d *Doctor) Network(ctx context.Context) []Result {
+	var hosts []string
+	addHost := func(rawURL string) {
+		if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+			hosts = append(hosts, u.Hostname())
+		}
+	}
+
+	base := strings.TrimRight(d.getenv("ANTHROPIC_BASE_URL"), "/")
+	if base == "" {
+		base = anthropicAPIURL
+	}
+	addHost(base)
+	results := []Result{d.anthropic(ctx, base)}
+
+	if token := d.getenv("GITHUB_TOKEN"); token != "" {
+		addHost(d.githubAPI)
+		results = append(results, d.gitProvider(ctx, "GitHub API", d.githubAPI+"/user", "Bearer "+token, "GITHUB_TOKEN"))
+	}
+	if gitea := strings.TrimRight(d.getenv("GITEA_URL"), "/"); gitea != "" {
+		addHost(gitea)
+		auth := ""
+		if token := d.getenv("GITEA_TOKEN"); token != "" {
+			auth = "token " + token
+		}
+		results = append(results, d.gitProvider(ctx, "Gitea API", gitea+"/api/v1/version", auth, "GITEA_URL"))
+	}
+	if len(results) == 1 {
+		results = append(results, Result{Name: "git provider API", Status: Skip, Detail: "neither GITHUB_TOKEN nor GITEA_URL is set"})
+	}
+
+	results = append(results, d.apprise(ctx))
+
+	if d.cfg.BrowserCDPURL != "" {
+		addHost(d.cfg.BrowserCDPURL)
+		results = append(results, d.cdp(ctx))
+	} else {
+		results = append(results, Result{Name: "browser sidecar", Status: Skip, Detail: config.Setting("browser_cdp_url") + " is not set"})
+	}
+
+	return append(results, d.dns(ctx, hosts))
+}
+
+// timed runs check and records how long it took.
+func timed(r *Result, check func()) {
+	start := time.Now()
+	check()
+	r.Latency = time.Since(start).Round(time.Millisecond)
+}
+
+// anthropic checks that the API answers. Any HTTP response means it is
+// reachable; a rejected key only warns, since the CLI may authenticate with
+// a subscription login rather than ANTHROPIC_API_KEY.
+func (d *Doctor) anthropic(ctx context.Context, base string) Result {
+	r := Result{Name: "Anthropic API"}
+	timed(&r, func() {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/v1/models", nil)
+		if err != nil {
+			r.Status, r.Detail = Fail, err.Error()
+			r.Fix = "set ANTHROPIC_BASE_URL to the gateway's URL, or unset it to use " + anthropicAPIURL
+			return
+		}
+		req.Header.Set("anthropic-version", "2023-06-01")
+		if key := d.getenv("ANTHROPIC_API_KEY"); key != "" {
+			req.Header.Set("x-api-key", key)
+		}
+		status, err := d.get(req)
+		switch {
+		case err != nil:
+			r.Status, r.Detail = Fail, err.Error()
+			r.Fix = "check outbound HTTPS from the container to " + base + " (firewall, proxy, or ANTHROPIC_BASE_URL)"
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			if d.getenv("ANTHROPIC_API_KEY") == "" {
+				r.Status, r.Detail = OK, base+" is reachable (ANTHROPIC_API_KEY is not set; the CLI uses its own login)"
+				return
+			}
+			r.Status, r.Detail = Warn, fmt.Sprintf("%s is reachable but rejected the API key (HTTP %d)", base, status)
+			r.Fix = "check ANTHROPIC_API_KEY"
+		case status >= 500:
+			r.Status, r.Detail = Warn, fmt.Sprintf("%s is reachable but returned HTTP %d", base, status)
+			r.Fix = "the API or gateway is having problems; sessions may fail until it recovers"
+		default:
+			r.Status, r.Detail = OK, fmt.Sprintf("%s is reachable (HTTP %d)", base, status)
+		}
+	})
+	return r
+}
+
+// gitProvider checks a git provider API with the token pull requests are
+// opened with.
+func (d *Doctor) gitProvider(ctx context.Context, name, endpoint, auth, env string) Result {
+	r := Result{Name: name}
+	timed(&r, func() {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			r.Status, r.Detail = Fail, err.Error()
+			r.Fix = "check " + env
+			return
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		status, err := d.get(req)
+		switch {
+		case err != nil:
+			r.Status, r.Detail = Fail, err.Error()
+			r.Fix = "check outbound HTTPS to " + req.URL.Host + " or " + env
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			r.Status, r.Detail = Fail, fmt.Sprintf("%s rejected the token (HTTP %d)", req.URL.Host, status)
+			r.Fix = "check the token's validity and scopes; pull requests cannot be opened without it"
+		case status >= 400:
+			r.Status, r.Detail = Warn, fmt.Sprintf("%s returned HTTP %d", endpoint, status)
+			r.Fix = "check " + env
+		default:
+			r.Status, r.Detail = OK, fmt.Sprintf("%s is reachable (HTTP %d)", req.URL.Host, status)
+		}
+	})
+	return r
+}
+
+// get sends req and returns the response status, discarding the body.
+func (d *Doctor) get(req *http.Request) (int, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), networkTimeout)
+	defer cancel()
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	return resp.StatusCode, nil
+}
+
+// apprise sends a test notification to the configured URLs.
+func (d *Doctor) apprise(ctx context.Context) Result {
+	r := Result{Name: "Apprise"}
+	if d.cfg.AppriseURLs == "" {
+		r.Status, r.Detail = Skip, config.Setting("apprise_urls")+" is not set"
+		return r
+	}
+	timed(&r, func() {
+		ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+		defer cancel()
+		if err := d.notify(ctx, "Claude Ops doctor", "Test notification from claudeops doctor."); err != nil {
+			r.Status, r.Detail = Fail, err.Error()
+			r.Fix = "check " + config.Setting("apprise_urls") + " (see https://github.com/caronc/apprise/wiki for URL formats)"
+			return
+		}
+		r.Status, r.Detail = OK, "test notification sent"
+	})
+	return r
+}
+
+func appriseNotify(urls string) func(context.Context, string, string) error {
+	return func(ctx context.Context, title, body string) error {
+		out, err := exec.CommandContext(ctx, "apprise", "-t", title, "-b", body, urls).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("apprise: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+}
+
+// cdp asks the browser sidecar for its DevTools WebSocket URL and completes
+// the WebSocket handshake with it, which is what the Chrome DevTools MCP
+// server does before its first command.
+func (d *Doctor) cdp(ctx context.Context) Result {
+	r := Result{Name: "browser sidecar"}
+	fix := "start the sidecar with docker compose --profile browser up -d, or check " + config.Setting("browser_cdp_url")
+	timed(&r, func() {
+		ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+		defer cancel()
+		base := strings.TrimRight(d.cfg.BrowserCDPURL, "/")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/json/version", nil)
+		if err != nil {
+			r.Status, r.Detail, r.Fix = Fail, err.Error(), fix
+			return
+		}
+		resp, err := d.client.Do(req)
+		if err != nil {
+			r.Status, r.Detail, r.Fix = Fail, err.Error(), fix
+			return
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		var version struct {
+			Browser string `json:"Browser"`
+			WS      string `json:"webSocketDebuggerUrl"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			r.Status, r.Detail, r.Fix = Fail, fmt.Sprintf("%s/json/version returned HTTP %d", base, resp.StatusCode), fix
+			return
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&version); err != nil || version.WS == "" {
+			r.Status, r.Detail = Fail, base+"/json/version did not return a webSocketDebuggerUrl"
+			r.Fix = "point " + config.Setting("browser_cdp_url") + " at a Chrome DevTools endpoint"
+			return
+		}
+		if err := wsHandshake(ctx, version.WS); err != nil {
+			r.Status, r.Detail, r.Fix = Fail, "DevTools WebSocket handshake: "+err.Error(), fix
+			return
+		}
+		r.Status, r.Detail = OK, version.Browser+" accepted a DevTools connection"
+	})
+	return r
+}
+
+// wsHandshake opens a WebSocket connection to wsURL and closes it once the
+// server has agreed to switch protocols.
+func wsHandshake(ctx context.Context, wsURL string) error {
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "ws" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "80")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() //nolint:errcheck
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	req, err := http.NewRequest(http.MethodGet, "http://"+u.Host+u.RequestURI(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(nonce))
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("server answered HTTP %d instead of switching protocols", resp.StatusCode)
+	}
+	return nil
+}
+
+// dns resolves every host the other checks contact, so a broken resolver
+// shows up as one clear failure rather than as each check's dial error.
+func (d *Doctor) dns(ctx context.Context, hosts []string) Result {
+	r := Result{Name: "DNS"}
+	seen := make(map[string]bool)
+	var names, failed []string
+	for _, h := range hosts {
+		if seen[h] || net.ParseIP(h) != nil {
+			continue
+		}
+		seen[h] = true
+		names = append(names, h)
+	}
+	sort.Strings(names)
+	timed(&r, func() {
+		ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+		defer cancel()
+		for _, h := range names {
+			if _, err := d.lookupHost(ctx, h); err != nil {
+				failed = append(failed, h)
+			}
+		}
+	})
+	switch {
+	case len(names) == 0:
+		r.Status, r.Detail = Skip, "no host names to resolve"
+	case len(failed) > 0:
+		r.Status, r.Detail = Fail, "cannot resolve "+strings.Join(failed, ", ")
+		r.Fix = "check the container's DNS servers (/etc/resolv.conf, or dns: in docker-compose.yaml)"
+	default:
+		r.Status, r.Detail = OK, "resolved "+strings.Join(names, ", ")
+	}
+	return r
+}
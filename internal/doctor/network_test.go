@@ -0,0 +1,156 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCDP serves /json/version and accepts the DevTools WebSocket upgrade.
+func fakeCDP(t *testing.T) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json/version":
+			ws := "ws://" + strings.TrimPrefix(srv.URL, "http://") + "/devtools/browser/abc"
+			_ = json.NewEncoder(w).Encode(map[string]string{"Browser": "HeadlessChrome/130.0", "webSocketDebuggerUrl": ws})
+		case "/devtools/browser/abc":
+			if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Key") == "" {
+				http.Error(w, "not a websocket request", http.StatusBadRequest)
+				return
+			}
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close() //nolint:errcheck
+			_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+			_ = buf.Flush()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNetwork(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/models" && r.Header.Get("x-api-key") == "sk-good":
+			_, _ = w.Write([]byte(`{"data": []}`))
+		case r.URL.Path == "/v1/models":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/user" && r.Header.Get("Authorization") == "Bearer gh-token":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer api.Close()
+	cdp := fakeCDP(t)
+
+	d, cfg := testDoctor(t)
+	env := map[string]string{"ANTHROPIC_BASE_URL": api.URL, "ANTHROPIC_API_KEY": "sk-good", "GITHUB_TOKEN": "gh-token"}
+	d.getenv = func(k string) string { return env[k] }
+	d.githubAPI = api.URL
+	var notified string
+	d.notify = func(_ context.Context, title, _ string) error { notified = title; return nil }
+	d.lookupHost = func(context.Context, string) ([]string, error) {
+		return nil, errors.New("lookups are not expected for IPs")
+	}
+	cfg.AppriseURLs = "json://localhost"
+	cfg.BrowserCDPURL = cdp.URL
+
+	results := d.Network(context.Background())
+	for _, name := range []string{"Anthropic API", "GitHub API", "Apprise", "browser sidecar"} {
+		if r := find(t, results, name); r.Status != OK {
+			t.Errorf("%s = %+v", name, r)
+		}
+	}
+	if notified == "" {
+		t.Error("no test notification was sent")
+	}
+	if r := find(t, results, "browser sidecar"); !strings.Contains(r.Detail, "HeadlessChrome") {
+		t.Errorf("browser sidecar detail = %q", r.Detail)
+	}
+	if r := find(t, results, "DNS"); r.Status != Skip {
+		t.Errorf("DNS for IP-only endpoints = %+v", r)
+	}
+
+	env["ANTHROPIC_API_KEY"] = "sk-bad"
+	env["GITHUB_TOKEN"] = "expired"
+	d.notify = func(context.Context, string, string) error { return errors.New("apprise: exit status 1") }
+	cdp.Close()
+	results = d.Network(context.Background())
+	for _, want := range []struct{ name, status string }{
+		{"Anthropic API", Warn},
+		{"GitHub API", Fail},
+		{"Apprise", Fail},
+		{"browser sidecar", Fail},
+	} {
+		if r := find(t, results, want.name); r.Status != want.status || r.Fix == "" {
+			t.Errorf("%s = %+v, want %s with a fix", want.name, r, want.status)
+		}
+	}
+}
+
+func TestNetworkSkipsUnconfigured(t *testing.T) {
+	d, _ := testDoctor(t)
+	d.getenv = func(string) string { return "" }
+	d.client = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("dial tcp: lookup api.anthropic.com: no such host")
+	})}
+	d.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		return nil, errors.New("no such host " + host)
+	}
+
+	results := d.Network(context.Background())
+	for _, name := range []string{"git provider API", "Apprise", "browser sidecar"} {
+		if r := find(t, results, name); r.Status != Skip {
+			t.Errorf("%s = %+v, want skip", name, r)
+		}
+	}
+	if r := find(t, results, "DNS"); r.Status != Fail || !strings.Contains(r.Detail, "api.anthropic.com") {
+		t.Errorf("DNS = %+v", r)
+	}
+	if p := Problems(results); len(p) != 2 {
+		t.Errorf("problems = %+v, want the API and DNS failures", p)
+	}
+}
+
+func TestResultJSONAndTable(t *testing.T) {
+	results := []Result{
+		{Name: "Anthropic API", Status: OK, Detail: "reachable", Latency: 2 * time.Millisecond},
+		{Name: "DNS", Status: Fail, Detail: "cannot resolve example.com", Fix: "check DNS"},
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"latency_ms":2`) || strings.Count(string(data), "latency_ms") != 1 {
+		t.Errorf("JSON = %s", data)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"CHECK", "Anthropic API", "2ms", "FAIL", "fix: check DNS"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
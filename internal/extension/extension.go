@@ -0,0 +1,401 @@
+// Package extension forwards the session manager's lifecycle callbacks to
+// integrations Claude Ops does not support natively (PagerDuty, OpsGenie, a
+// CMDB) without forking it. Every callback becomes a JSON envelope delivered
+// to each configured target: an http(s) URL receives it as a POST, and an
+// "exec:" command is run once per envelope with it on stdin. BROWSER_CRED_*
+// values are redacted from every envelope, as from session logs.
+package extension
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+const (
+	// queueSize bounds the envelopes waiting for delivery. When targets fall
+	// behind, new envelopes are dropped rather than stalling sessions.
+	queueSize = 256
+
+	deliveryTimeout = 10 * time.Second
+)
+
+// Envelope types, one per session.Hooks callback.
+const (
+	TypeSessionStart = "session.start"
+	TypeSessionEnd   = "session.end"
+	TypeEscalation   = "escalation"
+	TypeEvent        = "event"
+	TypeMemory       = "memory"
+	TypeCooldown     = "cooldown"
+	TypeVerification = "verification"
+)
+
+// Envelope is the JSON document delivered to every target.
+type Envelope struct {
+	Type string `json:"type"`
+	Time string `json:"time"`
+	Data any    `json:"data"`
+}
+
+// Session is the data of session.start and session.end envelopes.
+type Session struct {
+	ID              int64    `json:"id"`
+	ParentSessionID *int64   `json:"parent_session_id,omitempty"`
+	Tier            int      `json:"tier"`
+	Model           string   `json:"model"`
+	Status          string   `json:"status"`
+	Trigger         string   `json:"trigger"`
+	StartedAt       string   `json:"started_at"`
+	EndedAt         *string  `json:"ended_at,omitempty"`
+	CostUSD         *float64 `json:"cost_usd,omitempty"`
+	NumTurns        *int     `json:"num_turns,omitempty"`
+	Summary         *string  `json:"summary,omitempty"`
+}
+
+// Escalation is the data of escalation envelopes.
+type Escalation struct {
+	SessionID int64    `json:"session_id"`
+	FromTier  int      `json:"from_tier"`
+	ToTier    int      `json:"to_tier"`
+	Services  []string `json:"services"`
+	Context   string   `json:"context"`
+}
+
+// Event is the data of event envelopes.
+type Event struct {
+	ID        int64   `json:"id"`
+	SessionID *int64  `json:"session_id,omitempty"`
+	Level     string  `json:"level"`
+	Service   *string `json:"service,omitempty"`
+	Message   string  `json:"message"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// Memory is the data of memory envelopes.
+type Memory struct {
+	ID          int64   `json:"id"`
+	SessionID   *int64  `json:"session_id,omitempty"`
+	Service     *string `json:"service,omitempty"`
+	Category    string  `json:"category"`
+	Observation string  `json:"observation"`
+	Confidence  float64 `json:"confidence"`
+	Tier        int     `json:"tier"`
+}
+
+// Cooldown is the data of cooldown envelopes.
+type Cooldown struct {
+	ID        int64   `json:"id"`
+	SessionID *int64  `json:"session_id,omitempty"`
+	Service   string  `json:"service"`
+	Action    string  `json:"action"`
+	Success   bool    `json:"success"`
+	Tier      int     `json:"tier"`
+	Error     *string `json:"error,omitempty"`
+	Timestamp string  `json:"timestamp"`
+}
+
+// Verification is the data of verification envelopes.
+type Verification struct {
+	SessionID     int64    `json:"session_id"`
+	RemediationID int64    `json:"remediation_id"`
+	Services      []string `json:"services"`
+	Unhealthy     []string `json:"unhealthy"`
+}
+
+// target receives envelopes.
+type target interface {
+	deliver(ctx context.Context, typ string, body []byte) error
+	String() string
+}
+
+// parseTargets parses a comma-separated list of targets: http:// or
+// https:// URLs, and commands prefixed with "exec:".
+func parseTargets(spec string) ([]target, error) {
+	var targets []target
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if cmd, ok := strings.CutPrefix(part, "exec:"); ok {
+			if strings.TrimSpace(cmd) == "" {
+				return nil, fmt.Errorf("invalid extension %q: empty command", part)
+			}
+			targets = append(targets, execTarget{command: strings.TrimSpace(cmd)})
+			continue
+		}
+		u, err := url.Parse(part)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid extension %q: want an http(s) URL or exec:command", part)
+		}
+		targets = append(targets, webhookTarget{url: u, client: &http.Client{Timeout: deliveryTimeout}})
+	}
+	return targets, nil
+}
+
+// webhookTarget POSTs each envelope to a URL.
+type webhookTarget struct {
+	url    *url.URL
+	client *http.Client
+}
+
+func (w webhookTarget) deliver(ctx context.Context, typ string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Claudeops-Event", typ)
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// String omits the URL's user info and query, which often carry tokens.
+func (w webhookTarget) String() string {
+	return w.url.Scheme + "://" + w.url.Host + w.url.Path
+}
+
+// execTarget runs a shell command per envelope, with the envelope on stdin
+// and its type in CLAUDEOPS_EVENT_TYPE.
+type execTarget struct {
+	command string
+}
+
+func (x execTarget) deliver(ctx context.Context, typ string, body []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", x.command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), "CLAUDEOPS_EVENT_TYPE="+typ)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (x execTarget) String() string {
+	return "exec:" + x.command
+}
+
+// Emitter implements session.Hooks by queueing an envelope per callback and
+// delivering it to every target from Run. Callbacks never block; envelopes
+// are dropped when the queue is full.
+type Emitter struct {
+	targets  []target
+	queue    chan Envelope
+	redactor *session.RedactionFilter
+	now      func() time.Time
+}
+
+var _ session.Hooks = (*Emitter)(nil)
+
+// FromConfig builds an Emitter from CLAUDEOPS_EXTENSIONS. Returns nil when
+// no targets are configured or the list is invalid.
+func FromConfig(cfg *config.Config) *Emitter {
+	if strings.TrimSpace(cfg.Extensions) == "" {
+		return nil
+	}
+	e, err := New(cfg.Extensions)
+	if err != nil {
+		log.Printf("extension: %v (extensions disabled)", err)
+		return nil
+	}
+	return e
+}
+
+// New creates an Emitter delivering to the comma-separated targets in spec.
+func New(spec string) (*Emitter, error) {
+	targets, err := parseTargets(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Emitter{
+		targets:  targets,
+		queue:    make(chan Envelope, queueSize),
+		redactor: session.NewRedactionFilter(),
+		now:      time.Now,
+	}, nil
+}
+
+// Run delivers queued envelopes in order until ctx is cancelled.
+func (e *Emitter) Run(ctx context.Context) {
+	names := make([]string, len(e.targets))
+	for i, t := range e.targets {
+		names[i] = t.String()
+	}
+	log.Printf("extension: forwarding lifecycle events to %s", strings.Join(names, ", "))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case env := <-e.queue:
+			e.deliver(ctx, env)
+		}
+	}
+}
+
+// deliver sends env to every target, logging failures. A failed delivery
+// is not retried.
+func (e *Emitter) deliver(ctx context.Context, env Envelope) {
+	body, err := e.encode(env)
+	if err != nil {
+		log.Printf("extension: encode %s: %v", env.Type, err)
+		return
+	}
+	for _, t := range e.targets {
+		tctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+		if err := t.deliver(tctx, env.Type, body); err != nil {
+			log.Printf("extension: deliver %s to %s: %v", env.Type, t, err)
+		}
+		cancel()
+	}
+}
+
+// encode marshals env with the credential values in its strings redacted.
+// The data is redacted as decoded strings rather than as JSON text, where a
+// value holding a quote or backslash would be escaped and not match.
+func (e *Emitter) encode(env Envelope) ([]byte, error) {
+	raw, err := json.Marshal(env.Data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+	env.Data = e.redact(data)
+	return json.Marshal(env)
+}
+
+// redact returns v, decoded JSON, with every string redacted.
+func (e *Emitter) redact(v any) any {
+	switch v := v.(type) {
+	case string:
+		return e.redactor.Redact(v)
+	case []any:
+		for i := range v {
+			v[i] = e.redact(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = e.redact(v[k])
+		}
+	}
+	return v
+}
+
+// emit queues an envelope without blocking.
+func (e *Emitter) emit(typ string, data any) {
+	env := Envelope{Type: typ, Time: e.now().UTC().Format(time.RFC3339), Data: data}
+	select {
+	case e.queue <- env:
+	default:
+		log.Printf("extension: queue full, dropping %s", typ)
+	}
+}
+
+func sessionData(s *db.Session) Session {
+	return Session{
+		ID:              s.ID,
+		ParentSessionID: s.ParentSessionID,
+		Tier:            s.Tier,
+		Model:           s.Model,
+		Status:          s.Status,
+		Trigger:         s.Trigger,
+		StartedAt:       s.StartedAt,
+		EndedAt:         s.EndedAt,
+		CostUSD:         s.CostUSD,
+		NumTurns:        s.NumTurns,
+		Summary:         s.Summary,
+	}
+}
+
+// OnSessionStart implements session.Hooks.
+func (e *Emitter) OnSessionStart(s *db.Session) {
+	e.emit(TypeSessionStart, sessionData(s))
+}
+
+// OnSessionEnd implements session.Hooks.
+func (e *Emitter) OnSessionEnd(s *db.Session) {
+	e.emit(TypeSessionEnd, sessionData(s))
+}
+
+// OnEscalation implements session.Hooks.
+func (e *Emitter) OnEscalation(esc session.Escalation) {
+	e.emit(TypeEscalation, Escalation{
+		SessionID: esc.SessionID,
+		FromTier:  esc.FromTier,
+		ToTier:    esc.ToTier,
+		Services:  esc.Services,
+		Context:   esc.Context,
+	})
+}
+
+// OnEvent implements session.Hooks.
+func (e *Emitter) OnEvent(evt *db.Event) {
+	e.emit(TypeEvent, Event{
+		ID:        evt.ID,
+		SessionID: evt.SessionID,
+		Level:     evt.Level,
+		Service:   evt.Service,
+		Message:   evt.Message,
+		CreatedAt: evt.CreatedAt,
+	})
+}
+
+// OnMemory implements session.Hooks.
+func (e *Emitter) OnMemory(mem *db.Memory) {
+	e.emit(TypeMemory, Memory{
+		ID:          mem.ID,
+		SessionID:   mem.SessionID,
+		Service:     mem.Service,
+		Category:    mem.Category,
+		Observation: mem.Observation,
+		Confidence:  mem.Confidence,
+		Tier:        mem.Tier,
+	})
+}
+
+// OnCooldown implements session.Hooks.
+func (e *Emitter) OnCooldown(a *db.CooldownAction) {
+	e.emit(TypeCooldown, Cooldown{
+		ID:        a.ID,
+		SessionID: a.SessionID,
+		Service:   a.Service,
+		Action:    a.ActionType,
+		Success:   a.Success,
+		Tier:      a.Tier,
+		Error:     a.Error,
+		Timestamp: a.Timestamp,
+	})
+}
+
+// OnVerification implements session.Hooks.
+func (e *Emitter) OnVerification(v session.Verification) {
+	e.emit(TypeVerification, Verification{
+		SessionID:     v.SessionID,
+		RemediationID: v.RemediationID,
+		Services:      v.Services,
+		Unhealthy:     v.Unhealthy,
+	})
+}
@@ -0,0 +1,118 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := parseTargets(" https://user:pw@hooks.example.com/ops?key=abc , exec:/opt/plugins/cmdb.sh,")
+	if err != nil {
+		t.Fatalf("parseTargets: %v", err)
+	}
+	if len(targets) != 2 || targets[0].String() != "https://hooks.example.com/ops" || targets[1].String() != "exec:/opt/plugins/cmdb.sh" {
+		t.Fatalf("unexpected targets %v", targets)
+	}
+
+	for _, bad := range []string{"ftp://example.com", "hooks.example.com/ops", "exec: ", "https://"} {
+		if _, err := parseTargets(bad); err == nil {
+			t.Errorf("parseTargets(%q): expected error", bad)
+		}
+	}
+}
+
+func TestEmitterDeliversToWebhookAndExec(t *testing.T) {
+	got := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got <- r
+		bodies <- b
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "out.json")
+	e, err := New(srv.URL + "/hook,exec:cat > " + out + "; echo $CLAUDEOPS_EVENT_TYPE >> " + out)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	e.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	svc := "jellyfin"
+	sid := int64(7)
+	e.OnEvent(&db.Event{ID: 3, SessionID: &sid, Level: "critical", Service: &svc, Message: "jellyfin is down", CreatedAt: "2026-01-02T03:04:05Z"})
+	e.deliver(context.Background(), <-e.queue)
+
+	r := <-got
+	if r.Method != http.MethodPost || r.URL.Path != "/hook" || r.Header.Get("X-Claudeops-Event") != TypeEvent {
+		t.Errorf("unexpected request %s %s %v", r.Method, r.URL.Path, r.Header)
+	}
+	var env struct {
+		Type string
+		Time string
+		Data Event
+	}
+	if err := json.Unmarshal(<-bodies, &env); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if env.Type != TypeEvent || env.Time != "2026-01-02T03:04:05Z" || env.Data.ID != 3 || *env.Data.Service != "jellyfin" || *env.Data.SessionID != 7 {
+		t.Errorf("unexpected envelope %+v", env)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("exec target output: %v", err)
+	}
+	if !strings.Contains(string(data), `"message":"jellyfin is down"`) || !strings.HasSuffix(string(data), "event\n") {
+		t.Errorf("unexpected exec target input %q", data)
+	}
+}
+
+func TestEmitterDropsWhenQueueFull(t *testing.T) {
+	e, err := New("exec:true")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < queueSize+10; i++ {
+		e.OnEscalation(session.Escalation{SessionID: int64(i), FromTier: 1, ToTier: 2})
+	}
+	if len(e.queue) != queueSize {
+		t.Errorf("queue length = %d, want %d", len(e.queue), queueSize)
+	}
+}
+
+func TestEmitterRedactsCredentials(t *testing.T) {
+	t.Setenv("BROWSER_CRED_SONARR_PASS", `s3"cr3t`)
+	e, err := New("exec:true")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	sid := int64(7)
+	e.OnMemory(&db.Memory{ID: 1, SessionID: &sid, Category: "auth", Observation: `sonarr login works with password s3"cr3t`, Confidence: 0.9, Tier: 2})
+	e.OnEscalation(session.Escalation{SessionID: 7, FromTier: 1, ToTier: 2, Services: []string{"sonarr"}, Context: `tried s3"cr3t`})
+
+	for range 2 {
+		body, err := e.encode(<-e.queue)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		if strings.Contains(string(body), "cr3t") || !strings.Contains(string(body), "[REDACTED:BROWSER_CRED_SONARR_PASS]") {
+			t.Errorf("credential not redacted from %s", body)
+		}
+		var env Envelope
+		if err := json.Unmarshal(body, &env); err != nil {
+			t.Errorf("decode %s: %v", body, err)
+		}
+	}
+}
@@ -0,0 +1,803 @@
+// Package graphql executes read-only GraphQL queries against a schema of
+// object types defined in Go. It supports the query language clients use
+// day to day: aliases, arguments, variables, fragments, and the @skip and
+// @include directives. Mutations, subscriptions, interfaces, unions, and
+// introspection beyond __typename are not supported; Schema.SDL describes
+// the schema instead.
+//
+// Fields resolve in batches: a field's resolver is called once for all the
+// objects at its level of the query, not once per object, which is the
+// batching a dataloader would provide. A query for sessions, their child
+// sessions, and their events runs one resolver call (and one database
+// query) per field, however many sessions it returns.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxDepth bounds how deeply selections may nest.
+	maxDepth = 12
+
+	// maxFields bounds the field selections a query makes, counting a
+	// selection again each time a fragment spreads it, so that neither a
+	// wide query nor fragments spread over and over can fan out into more
+	// resolver calls than any dashboard needs.
+	maxFields = 500
+
+	// maxAliases bounds the fields selected under an alias, which can
+	// otherwise run the same costly field any number of times.
+	maxAliases = 30
+)
+
+// ResolveFunc returns a field's value for each of parents, in order. The
+// value of an object field is passed to the resolvers of its selection;
+// the value of a list field must be a []any.
+type ResolveFunc func(ctx context.Context, parents []any, args Args) ([]any, error)
+
+// Object is a GraphQL object type.
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+// Field is a field of an object type.
+type Field struct {
+	Name        string
+	Description string
+	// Type is the field's GraphQL type, e.g. "Int!", "Session", or
+	// "[Event!]!". A named type is a scalar (Int, Float, String, Boolean,
+	// ID) or an object type in the schema.
+	Type    string
+	Args    []Arg
+	Resolve ResolveFunc
+}
+
+// Arg is an argument of a field. Arguments are scalars or lists of scalars.
+type Arg struct {
+	Name        string
+	Type        string
+	Default     any // applied when the argument is omitted; nil for none
+	Description string
+}
+
+// Prop returns a field whose value is computed from each parent on its own.
+func Prop(name, typ, description string, get func(parent any) any) *Field {
+	return &Field{Name: name, Type: typ, Description: description,
+		Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			out := make([]any, len(parents))
+			for i, p := range parents {
+				out[i] = get(p)
+			}
+			return out, nil
+		}}
+}
+
+// Args are a field's coerced arguments: ints as int, floats as float64,
+// strings and IDs as string, booleans as bool, and lists as []any.
+// Omitted arguments without a default are absent.
+type Args map[string]any
+
+// Int returns an Int argument, or 0 when it is absent or null.
+func (a Args) Int(name string) int {
+	n, _ := a[name].(int)
+	return n
+}
+
+// String returns a String argument, or "" when it is absent or null.
+func (a Args) String(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+// Has reports whether an argument was given (or has a default) and is not
+// null.
+func (a Args) Has(name string) bool {
+	return a[name] != nil
+}
+
+var scalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+// Schema is a set of object types with Query as the root.
+type Schema struct {
+	query   *Object
+	types   []*Object
+	byName  map[string]*Object
+	fieldOf map[*Object]map[string]*Field
+}
+
+// NewSchema builds a schema from its root query type and the object types
+// its fields refer to.
+func NewSchema(query *Object, types ...*Object) (*Schema, error) {
+	s := &Schema{
+		query:   query,
+		types:   append([]*Object{query}, types...),
+		byName:  make(map[string]*Object),
+		fieldOf: make(map[*Object]map[string]*Field),
+	}
+	for _, t := range s.types {
+		if _, dup := s.byName[t.Name]; dup || scalars[t.Name] {
+			return nil, fmt.Errorf("type %s is defined more than once", t.Name)
+		}
+		s.byName[t.Name] = t
+		fields := make(map[string]*Field, len(t.Fields))
+		for _, f := range t.Fields {
+			if _, dup := fields[f.Name]; dup {
+				return nil, fmt.Errorf("field %s.%s is defined more than once", t.Name, f.Name)
+			}
+			if f.Resolve == nil {
+				return nil, fmt.Errorf("field %s.%s has no resolver", t.Name, f.Name)
+			}
+			fields[f.Name] = f
+			for _, a := range f.Args {
+				if !scalars[namedType(a.Type)] {
+					return nil, fmt.Errorf("argument %s.%s(%s) must be a scalar", t.Name, f.Name, a.Name)
+				}
+			}
+		}
+		s.fieldOf[t] = fields
+	}
+	for _, t := range s.types {
+		for _, f := range t.Fields {
+			if n := namedType(f.Type); !scalars[n] && s.byName[n] == nil {
+				return nil, fmt.Errorf("field %s.%s has unknown type %s", t.Name, f.Name, n)
+			}
+		}
+	}
+	return s, nil
+}
+
+// namedType strips list and non-null wrappers from a type.
+func namedType(typ string) string {
+	return strings.Trim(typ, "[]!")
+}
+
+func isList(typ string) bool {
+	return strings.HasPrefix(typ, "[")
+}
+
+// Request is a GraphQL request as clients POST it.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response. Data is absent when the request is
+// invalid, and null when a resolver failed.
+type Response struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []Error         `json:"errors,omitempty"`
+}
+
+// Error is an error in a Response.
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Execute runs a query. A Response without Data means the request itself
+// was invalid.
+func (s *Schema) Execute(ctx context.Context, req Request) Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: "syntax error: " + err.Error()}}}
+	}
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	if op.kind != "query" {
+		return Response{Errors: []Error{{Message: op.kind + " is not supported: the API is read-only"}}}
+	}
+	vars, err := coerceVariables(op.variables, req.Variables)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{schema: s, doc: doc, op: op, vars: vars}
+	if err := e.validate(s.query, op.selection, 1); err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	// Execution collects the same selections again.
+	e.selected, e.aliased = 0, 0
+	results, err := e.execute(ctx, s.query, []any{nil}, op.selection, nil)
+	if err != nil {
+		resp := Response{Data: json.RawMessage("null")}
+		if gerr, ok := err.(*Error); ok {
+			resp.Errors = []Error{*gerr}
+		} else {
+			resp.Errors = []Error{{Message: err.Error()}}
+		}
+		return resp
+	}
+	data, err := json.Marshal(results[0])
+	if err != nil {
+		return Response{Data: json.RawMessage("null"), Errors: []Error{{Message: "encode result: " + err.Error()}}}
+	}
+	return Response{Data: data}
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document has more than one operation")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+func coerceVariables(defs []variableDef, given map[string]any) (map[string]any, error) {
+	vars := make(map[string]any, len(defs))
+	for _, d := range defs {
+		if !scalars[namedType(d.typ)] {
+			return nil, fmt.Errorf("variable $%s: type %s is not supported", d.name, d.typ)
+		}
+		v, ok := given[d.name]
+		if !ok {
+			if !d.has {
+				if strings.HasSuffix(d.typ, "!") {
+					return nil, fmt.Errorf("variable $%s of type %s is required", d.name, d.typ)
+				}
+				continue
+			}
+			v = d.value
+		}
+		c, err := coerce(d.typ, v)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %v", d.name, err)
+		}
+		vars[d.name] = c
+	}
+	return vars, nil
+}
+
+// coerce converts a literal or JSON value to the Go value of typ.
+func coerce(typ string, v any) (any, error) {
+	nonNull := strings.HasSuffix(typ, "!")
+	base := strings.TrimSuffix(typ, "!")
+	if v == nil {
+		if nonNull {
+			return nil, fmt.Errorf("expected %s, got null", typ)
+		}
+		return nil, nil
+	}
+	if isList(base) {
+		inner := base[1 : len(base)-1]
+		items, ok := v.([]any)
+		if !ok {
+			items = []any{v}
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			c, err := coerce(inner, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = c
+		}
+		return out, nil
+	}
+	switch base {
+	case "Int":
+		switch n := v.(type) {
+		case int: // an already coerced variable
+			return n, nil
+		case int64:
+			if n == int64(int32(n)) {
+				return int(n), nil
+			}
+		case float64: // from JSON variables
+			if n == float64(int32(n)) {
+				return int(n), nil
+			}
+		}
+	case "Float":
+		switch n := v.(type) {
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case "String":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case "ID":
+		switch id := v.(type) {
+		case string:
+			return id, nil
+		case int64:
+			return strconv.FormatInt(id, 10), nil
+		case float64:
+			if id == float64(int64(id)) {
+				return strconv.FormatInt(int64(id), 10), nil
+			}
+		}
+	case "Boolean":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %s", typ, describeValue(v))
+}
+
+func describeValue(v any) string {
+	switch v := v.(type) {
+	case enumValue:
+		return string(v)
+	case string:
+		return strconv.Quote(v)
+	case []argument:
+		return "an input object"
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+type executor struct {
+	schema *Schema
+	doc    *document
+	op     *operation
+	vars   map[string]any
+
+	// selected and aliased count the field selections collected, and
+	// those under an alias, against maxFields and maxAliases.
+	selected, aliased int
+}
+
+// field is a field to resolve: one or more selections under the same
+// response key, whose sub-selections are merged.
+type field struct {
+	key       string
+	sel       selection
+	selection []selection
+}
+
+// collect flattens fragments and applies @skip and @include, returning the
+// fields selected on obj in order.
+func (e *executor) collect(obj *Object, sel []selection, visited map[string]bool) ([]*field, error) {
+	var out []*field
+	byKey := make(map[string]*field)
+	var walk func([]selection) error
+	walk = func(sel []selection) error {
+		for _, s := range sel {
+			ok, err := e.included(s.directives)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			switch {
+			case s.spread != "":
+				frag := e.doc.fragments[s.spread]
+				if frag == nil {
+					return fmt.Errorf("unknown fragment %q", s.spread)
+				}
+				if visited[s.spread] {
+					return fmt.Errorf("fragment %q spreads itself", s.spread)
+				}
+				if err := e.checkCondition(obj, frag.on); err != nil {
+					return err
+				}
+				visited[s.spread] = true
+				err := walk(frag.selection)
+				delete(visited, s.spread)
+				if err != nil {
+					return err
+				}
+			case s.inline:
+				if s.on != "" {
+					if err := e.checkCondition(obj, s.on); err != nil {
+						return err
+					}
+				}
+				if err := walk(s.selection); err != nil {
+					return err
+				}
+			default:
+				if e.selected++; e.selected > maxFields {
+					return fmt.Errorf("query selects more than %d fields", maxFields)
+				}
+				if s.alias != "" && s.alias != s.name {
+					if e.aliased++; e.aliased > maxAliases {
+						return fmt.Errorf("query has more than %d aliases", maxAliases)
+					}
+				}
+				key := s.responseKey()
+				if f, ok := byKey[key]; ok {
+					if f.sel.name != s.name {
+						return fmt.Errorf("%q selects both %s and %s", key, f.sel.name, s.name)
+					}
+					f.selection = append(f.selection, s.selection...)
+					continue
+				}
+				f := &field{key: key, sel: s, selection: append([]selection(nil), s.selection...)}
+				byKey[key] = f
+				out = append(out, f)
+			}
+		}
+		return nil
+	}
+	return out, walk(sel)
+}
+
+func (e *executor) checkCondition(obj *Object, on string) error {
+	if e.schema.byName[on] == nil {
+		return fmt.Errorf("unknown type %s", on)
+	}
+	if on != obj.Name {
+		return fmt.Errorf("fragment on %s cannot be spread on %s", on, obj.Name)
+	}
+	return nil
+}
+
+func (e *executor) included(dirs []directive) (bool, error) {
+	for _, d := range dirs {
+		if d.name != "skip" && d.name != "include" {
+			return false, fmt.Errorf("unknown directive @%s", d.name)
+		}
+		if len(d.args) != 1 || d.args[0].name != "if" {
+			return false, fmt.Errorf("@%s requires one argument, if", d.name)
+		}
+		v, err := e.value("Boolean!", d.args[0].value)
+		if err != nil {
+			return false, fmt.Errorf("@%s(if:): %v", d.name, err)
+		}
+		if v.(bool) == (d.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// value resolves variables in a literal and coerces it to typ.
+func (e *executor) value(typ string, v any) (any, error) {
+	return coerce(typ, e.substitute(v))
+}
+
+func (e *executor) substitute(v any) any {
+	switch v := v.(type) {
+	case variable:
+		return e.vars[string(v)]
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = e.substitute(item)
+		}
+		return out
+	}
+	return v
+}
+
+// args coerces a field's arguments and applies defaults.
+func (e *executor) args(obj *Object, def *Field, given []argument) (Args, error) {
+	args := make(Args, len(def.Args))
+	seen := make(map[string]bool, len(given))
+	for _, g := range given {
+		var a *Arg
+		for i := range def.Args {
+			if def.Args[i].Name == g.name {
+				a = &def.Args[i]
+			}
+		}
+		if a == nil {
+			return nil, fmt.Errorf("unknown argument %q on field %s.%s", g.name, obj.Name, def.Name)
+		}
+		if seen[g.name] {
+			return nil, fmt.Errorf("argument %q is given more than once", g.name)
+		}
+		seen[g.name] = true
+		if vr, ok := g.value.(variable); ok {
+			if _, declared := e.vars[string(vr)]; !declared {
+				if a.Default != nil {
+					args[a.Name] = a.Default
+					continue
+				}
+				if strings.HasSuffix(a.Type, "!") {
+					return nil, fmt.Errorf("argument %q of %s.%s is required", a.Name, obj.Name, def.Name)
+				}
+				continue
+			}
+		}
+		v, err := e.value(a.Type, g.value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q of %s.%s: %v", a.Name, obj.Name, def.Name, err)
+		}
+		args[a.Name] = v
+	}
+	for _, a := range def.Args {
+		if seen[a.Name] {
+			continue
+		}
+		if a.Default != nil {
+			args[a.Name] = a.Default
+		} else if strings.HasSuffix(a.Type, "!") {
+			return nil, fmt.Errorf("argument %q of %s.%s is required", a.Name, obj.Name, def.Name)
+		}
+	}
+	return args, nil
+}
+
+// validate checks a selection against the schema before anything runs, so
+// an invalid query fails as a whole even where no objects would reach it.
+func (e *executor) validate(obj *Object, sel []selection, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("query is nested more than %d levels deep", maxDepth)
+	}
+	if err := e.checkVariables(sel, make(map[string]bool)); err != nil {
+		return err
+	}
+	fields, err := e.collect(obj, sel, make(map[string]bool))
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.sel.name == "__typename" {
+			if len(f.selection) > 0 {
+				return fmt.Errorf("__typename cannot have a selection")
+			}
+			continue
+		}
+		if strings.HasPrefix(f.sel.name, "__") {
+			return fmt.Errorf("introspection is not supported; use the schema SDL instead")
+		}
+		def := e.schema.fieldOf[obj][f.sel.name]
+		if def == nil {
+			return fmt.Errorf("cannot query field %q on type %s", f.sel.name, obj.Name)
+		}
+		if _, err := e.args(obj, def, f.sel.args); err != nil {
+			return err
+		}
+		target := e.schema.byName[namedType(def.Type)]
+		switch {
+		case target == nil && len(f.selection) > 0:
+			return fmt.Errorf("field %s.%s of type %s cannot have a selection", obj.Name, def.Name, def.Type)
+		case target != nil && len(f.selection) == 0:
+			return fmt.Errorf("field %s.%s of type %s must have a selection", obj.Name, def.Name, def.Type)
+		case target != nil:
+			if err := e.validate(target, f.selection, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkVariables reports uses of undeclared variables.
+func (e *executor) checkVariables(sel []selection, visited map[string]bool) error {
+	var check func(v any) error
+	check = func(v any) error {
+		switch v := v.(type) {
+		case variable:
+			if _, ok := e.vars[string(v)]; !ok && !e.declared(string(v)) {
+				return fmt.Errorf("variable $%s is not declared", string(v))
+			}
+		case []any:
+			for _, item := range v {
+				if err := check(item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, s := range sel {
+		for _, a := range s.args {
+			if err := check(a.value); err != nil {
+				return err
+			}
+		}
+		for _, d := range s.directives {
+			for _, a := range d.args {
+				if err := check(a.value); err != nil {
+					return err
+				}
+			}
+		}
+		if frag := e.doc.fragments[s.spread]; frag != nil && !visited[s.spread] {
+			visited[s.spread] = true
+			if err := e.checkVariables(frag.selection, visited); err != nil {
+				return err
+			}
+		}
+		if err := e.checkVariables(s.selection, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declared reports whether the operation declares a variable, including
+// optional ones that were not given.
+func (e *executor) declared(name string) bool {
+	for _, d := range e.op.variables {
+		if d.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// execute resolves a selection for all parents at once and returns one
+// result object per parent.
+func (e *executor) execute(ctx context.Context, obj *Object, parents []any, sel []selection, path []any) ([]*result, error) {
+	out := make([]*result, len(parents))
+	for i := range out {
+		out[i] = &result{}
+	}
+	if len(parents) == 0 {
+		return out, nil
+	}
+	fields, err := e.collect(obj, sel, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fpath := append(append([]any(nil), path...), f.key)
+		if f.sel.name == "__typename" {
+			for _, r := range out {
+				r.set(f.key, obj.Name)
+			}
+			continue
+		}
+		def := e.schema.fieldOf[obj][f.sel.name]
+		args, err := e.args(obj, def, f.sel.args)
+		if err != nil {
+			return nil, &Error{Message: err.Error(), Path: fpath}
+		}
+		values, err := def.Resolve(ctx, parents, args)
+		if err != nil {
+			return nil, &Error{Message: err.Error(), Path: fpath}
+		}
+		if len(values) != len(parents) {
+			return nil, &Error{Message: fmt.Sprintf("resolver returned %d values for %d objects", len(values), len(parents)), Path: fpath}
+		}
+
+		target := e.schema.byName[namedType(def.Type)]
+		if target == nil {
+			for i, v := range values {
+				out[i].set(f.key, v)
+			}
+			continue
+		}
+		list := isList(def.Type)
+		var children []any
+		for _, v := range values {
+			switch {
+			case v == nil:
+			case list:
+				items, ok := v.([]any)
+				if !ok {
+					return nil, &Error{Message: fmt.Sprintf("resolver returned %T for a list", v), Path: fpath}
+				}
+				children = append(children, items...)
+			default:
+				children = append(children, v)
+			}
+		}
+		childResults, err := e.execute(ctx, target, children, f.selection, fpath)
+		if err != nil {
+			return nil, err
+		}
+		k := 0
+		for i, v := range values {
+			switch {
+			case v == nil:
+				out[i].set(f.key, nil)
+			case list:
+				items := v.([]any)
+				objs := make([]*result, len(items))
+				copy(objs, childResults[k:k+len(items)])
+				k += len(items)
+				out[i].set(f.key, objs)
+			default:
+				out[i].set(f.key, childResults[k])
+				k++
+			}
+		}
+	}
+	return out, nil
+}
+
+// result is an object in a response, with its keys in selection order.
+type result struct {
+	keys   []string
+	values []any
+}
+
+func (r *result) set(key string, v any) {
+	r.keys = append(r.keys, key)
+	r.values = append(r.values, v)
+}
+
+// MarshalJSON encodes the object with its keys in selection order.
+func (r *result) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range r.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		b.Write(key)
+		b.WriteByte(':')
+		v, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// SDL returns the schema in the GraphQL schema definition language.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for i, t := range s.types {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeDescription(&b, "", t.Description)
+		fmt.Fprintf(&b, "type %s {\n", t.Name)
+		for _, f := range t.Fields {
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + f.Name)
+			if len(f.Args) > 0 {
+				args := make([]string, len(f.Args))
+				for j, a := range f.Args {
+					args[j] = a.Name + ": " + a.Type
+					if a.Default != nil {
+						args[j] += " = " + sdlValue(a.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, desc string) {
+	if desc != "" {
+		fmt.Fprintf(b, "%s%s\n", indent, strconv.Quote(desc))
+	}
+}
+
+func sdlValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = sdlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
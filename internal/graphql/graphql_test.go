@@ -0,0 +1,245 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testPost struct {
+	ID     int
+	Title  string
+	Author int
+}
+
+type testAuthor struct {
+	ID   int
+	Name string
+}
+
+// testSchema returns a schema of authors and posts, and a counter of
+// author resolver calls.
+func testSchema(t *testing.T) (*Schema, *int) {
+	t.Helper()
+	posts := []testPost{{1, "Hello", 10}, {2, "Again", 10}, {3, "Elsewhere", 20}}
+	authors := map[int]testAuthor{10: {10, "Ada"}, 20: {20, "Grace"}}
+	authorCalls := 0
+
+	post := &Object{Name: "Post", Fields: []*Field{
+		Prop("id", "Int!", "", func(p any) any { return p.(testPost).ID }),
+		Prop("title", "String!", "", func(p any) any { return p.(testPost).Title }),
+		{Name: "author", Type: "Author", Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			authorCalls++
+			out := make([]any, len(parents))
+			for i, p := range parents {
+				if a, ok := authors[p.(testPost).Author]; ok {
+					out[i] = a
+				}
+			}
+			return out, nil
+		}},
+	}}
+	author := &Object{Name: "Author", Description: "Writes posts.", Fields: []*Field{
+		Prop("id", "Int!", "", func(p any) any { return p.(testAuthor).ID }),
+		Prop("name", "String!", "", func(p any) any { return p.(testAuthor).Name }),
+		{Name: "posts", Type: "[Post!]!", Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			out := make([]any, len(parents))
+			for i, p := range parents {
+				list := []any{}
+				for _, post := range posts {
+					if post.Author == p.(testAuthor).ID {
+						list = append(list, post)
+					}
+				}
+				out[i] = list
+			}
+			return out, nil
+		}},
+	}}
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "posts", Type: "[Post!]!", Description: "All posts.",
+			Args: []Arg{{Name: "limit", Type: "Int", Default: 10}, {Name: "title", Type: "String"}},
+			Resolve: func(_ context.Context, _ []any, args Args) ([]any, error) {
+				list := []any{}
+				for _, p := range posts {
+					if len(list) < args.Int("limit") && (!args.Has("title") || p.Title == args.String("title")) {
+						list = append(list, p)
+					}
+				}
+				return []any{list}, nil
+			}},
+		{Name: "post", Type: "Post", Args: []Arg{{Name: "id", Type: "Int!"}},
+			Resolve: func(_ context.Context, _ []any, args Args) ([]any, error) {
+				for _, p := range posts {
+					if p.ID == args.Int("id") {
+						return []any{p}, nil
+					}
+				}
+				return []any{nil}, nil
+			}},
+	}}
+	s, err := NewSchema(query, post, author)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	return s, &authorCalls
+}
+
+func run(t *testing.T, s *Schema, query string, vars map[string]any) Response {
+	t.Helper()
+	return s.Execute(context.Background(), Request{Query: query, Variables: vars})
+}
+
+func TestExecuteBatchesNestedFields(t *testing.T) {
+	s, calls := testSchema(t)
+	resp := run(t, s, `{ posts { id author { name } } }`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	want := `{"posts":[{"id":1,"author":{"name":"Ada"}},{"id":2,"author":{"name":"Ada"}},{"id":3,"author":{"name":"Grace"}}]}`
+	if string(resp.Data) != want {
+		t.Errorf("data = %s\nwant %s", resp.Data, want)
+	}
+	if *calls != 1 {
+		t.Errorf("author resolved in %d calls, want 1", *calls)
+	}
+}
+
+func TestExecuteQueryFeatures(t *testing.T) {
+	s, _ := testSchema(t)
+	query := `
+		# Aliases, variables, fragments, and directives.
+		query Posts($limit: Int = 1, $full: Boolean!) {
+			first: posts(limit: $limit) { ...PostFields }
+			byTitle: posts(title: "Elsewhere") { id __typename }
+			post(id: 3) {
+				title @include(if: $full)
+				... on Post { author { name posts { id } } }
+			}
+			missing: post(id: 99) { id }
+		}
+		fragment PostFields on Post { id title }`
+	resp := run(t, s, query, map[string]any{"full": false})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	want := `{"first":[{"id":1,"title":"Hello"}],"byTitle":[{"id":3,"__typename":"Post"}],"post":{"author":{"name":"Grace","posts":[{"id":3}]}},"missing":null}`
+	if string(resp.Data) != want {
+		t.Errorf("data = %s\nwant %s", resp.Data, want)
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	s, _ := testSchema(t)
+	tests := []struct {
+		query string
+		vars  map[string]any
+		want  string
+	}{
+		{`{ posts { id `, nil, "syntax error"},
+		{`{ posts { nope } }`, nil, `cannot query field "nope" on type Post`},
+		{`{ posts }`, nil, "must have a selection"},
+		{`{ posts { id { x } } }`, nil, "cannot have a selection"},
+		{`{ post { id } }`, nil, `argument "id" of Query.post is required`},
+		{`{ post(id: "x") { id } }`, nil, "expected Int!"},
+		{`{ posts(color: 1) { id } }`, nil, `unknown argument "color"`},
+		{`query($id: Int!) { post(id: $id) { id } }`, nil, "$id of type Int! is required"},
+		{`{ post(id: $id) { id } }`, nil, "$id is not declared"},
+		{`mutation { posts { id } }`, nil, "read-only"},
+		{`{ __schema { types { name } } }`, nil, "introspection is not supported"},
+		{`{ posts { ...Missing } }`, nil, `unknown fragment "Missing"`},
+		{`{ posts { ...A } } fragment A on Author { id }`, nil, "cannot be spread on Post"},
+		{`{ posts { id @skip } }`, nil, "@skip requires one argument"},
+		{`{ a: posts { id } a: post(id: 1) { id } }`, nil, `"a" selects both posts and post`},
+	}
+	for _, tt := range tests {
+		resp := run(t, s, tt.query, tt.vars)
+		if resp.Data != nil {
+			t.Errorf("%s: expected no data, got %s", tt.query, resp.Data)
+		}
+		if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.want) {
+			t.Errorf("%s: errors = %+v, want %q", tt.query, resp.Errors, tt.want)
+		}
+	}
+}
+
+func TestExecuteLimits(t *testing.T) {
+	s, calls := testSchema(t)
+	var aliases, fields strings.Builder
+	for i := 0; i <= maxAliases; i++ {
+		fmt.Fprintf(&aliases, "p%d: posts { id } ", i)
+	}
+	for i := 0; i <= maxFields; i++ {
+		fields.WriteString("id ")
+	}
+	// Each fragment spreads the next twice, selecting 2^20 fields.
+	fanOut := `{ posts { ...F0 } } fragment F20 on Post { id }`
+	for i := 0; i < 20; i++ {
+		fanOut += fmt.Sprintf(" fragment F%d on Post { ...F%d ...F%d }", i, i+1, i+1)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"{ " + aliases.String() + "}", fmt.Sprintf("more than %d aliases", maxAliases)},
+		{"{ posts { " + fields.String() + "} }", fmt.Sprintf("more than %d fields", maxFields)},
+		{fanOut, fmt.Sprintf("more than %d fields", maxFields)},
+		{"{ post(id: 1) { " + strings.Repeat("author { posts { ", 7) + "id" + strings.Repeat(" } }", 7) + " } }", fmt.Sprintf("more than %d levels deep", maxDepth)},
+		{"{ " + strings.Repeat("posts { ", 100000) + "id" + strings.Repeat(" }", 100001), fmt.Sprintf("more than %d levels deep", maxNesting)},
+		{"{ posts(title: " + strings.Repeat("[", 100000) + ") { id } }", fmt.Sprintf("more than %d levels deep", maxNesting)},
+	}
+	for _, tt := range tests {
+		resp := run(t, s, tt.query, nil)
+		if resp.Data != nil || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.want) {
+			t.Errorf("%.60s...: data %s, errors %+v, want %q", tt.query, resp.Data, resp.Errors, tt.want)
+		}
+	}
+	if *calls != 0 {
+		t.Errorf("author resolved %d times, want 0", *calls)
+	}
+
+	// A query at the limits runs.
+	aliases.Reset()
+	for i := 0; i < maxAliases; i++ {
+		fmt.Fprintf(&aliases, "p%d: posts { id } ", i)
+	}
+	if resp := run(t, s, "{ "+aliases.String()+"}", nil); len(resp.Errors) > 0 {
+		t.Errorf("%d aliases: errors %+v", maxAliases, resp.Errors)
+	}
+}
+
+func TestExecuteVariablesFromJSON(t *testing.T) {
+	s, _ := testSchema(t)
+	var vars map[string]any
+	if err := json.Unmarshal([]byte(`{"id": 2}`), &vars); err != nil {
+		t.Fatal(err)
+	}
+	resp := run(t, s, `query Post($id: Int!) { post(id: $id) { title } }`, vars)
+	if string(resp.Data) != `{"post":{"title":"Again"}}` {
+		t.Errorf("data = %s, errors = %+v", resp.Data, resp.Errors)
+	}
+}
+
+func TestSDL(t *testing.T) {
+	s, _ := testSchema(t)
+	sdl := s.SDL()
+	for _, want := range []string{
+		"type Query {\n",
+		`  "All posts."` + "\n  posts(limit: Int = 10, title: String): [Post!]!\n",
+		`"Writes posts."` + "\ntype Author {\n",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("SDL missing %q:\n%s", want, sdl)
+		}
+	}
+}
+
+func TestNewSchemaRejectsUnknownType(t *testing.T) {
+	q := &Object{Name: "Query", Fields: []*Field{Prop("x", "Widget", "", func(any) any { return nil })}}
+	if _, err := NewSchema(q); err == nil || !strings.Contains(err.Error(), "unknown type Widget") {
+		t.Errorf("err = %v", err)
+	}
+}
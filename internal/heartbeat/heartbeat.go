@@ -0,0 +1,180 @@
+// Package heartbeat pings a dead man's switch, such as healthchecks.io or
+// an Uptime Kuma push monitor, after every scheduled check, so the monitor
+// itself is monitored: if Claude Ops stops running its checks, the pings
+// stop and the external service raises the alarm.
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+	"github.com/joestump/claude-ops/internal/textutil"
+)
+
+const (
+	pingTimeout = 10 * time.Second
+
+	// maxMessage bounds the failure detail sent with a fail ping. Uptime Kuma
+	// carries it in the query string.
+	maxMessage = 500
+)
+
+// ping is one heartbeat: ok, or failed with a message.
+type ping struct {
+	ok  bool
+	msg string
+}
+
+// Heartbeat implements session.Hooks, pinging after each scheduled Tier 1
+// session: the success URL when it completed or escalated, the failure
+// variant with the reason when it failed or timed out.
+type Heartbeat struct {
+	session.NopHooks
+
+	url    *url.URL
+	kuma   bool // Uptime Kuma push URL rather than healthchecks.io style
+	client *http.Client
+	queue  chan ping
+}
+
+// FromConfig builds a Heartbeat from CLAUDEOPS_HEARTBEAT_URL. Returns nil
+// when it is unset or invalid.
+func FromConfig(cfg *config.Config) *Heartbeat {
+	if strings.TrimSpace(cfg.HeartbeatURL) == "" {
+		return nil
+	}
+	h, err := New(strings.TrimSpace(cfg.HeartbeatURL))
+	if err != nil {
+		log.Printf("heartbeat: %v (heartbeat disabled)", err)
+		return nil
+	}
+	return h
+}
+
+// New creates a Heartbeat pinging rawURL. A URL whose path contains
+// /api/push/ is treated as an Uptime Kuma push monitor; any other is pinged
+// healthchecks.io style, with /fail appended to report a failure.
+func New(rawURL string) (*Heartbeat, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid heartbeat URL: want an http(s) URL")
+	}
+	return &Heartbeat{
+		url:    u,
+		kuma:   strings.Contains(u.Path, "/api/push/"),
+		client: &http.Client{Timeout: pingTimeout},
+		queue:  make(chan ping, 16),
+	}, nil
+}
+
+// Run sends queued pings until ctx is cancelled.
+func (h *Heartbeat) Run(ctx context.Context) {
+	kind := "healthchecks.io"
+	if h.kuma {
+		kind = "Uptime Kuma"
+	}
+	log.Printf("heartbeat: pinging %s://%s (%s) after each scheduled check", h.url.Scheme, h.url.Host, kind)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-h.queue:
+			if err := h.send(ctx, p); err != nil {
+				log.Printf("heartbeat: %v", err)
+			}
+		}
+	}
+}
+
+// OnSessionEnd queues a ping for a scheduled Tier 1 session.
+func (h *Heartbeat) OnSessionEnd(s *db.Session) {
+	if s.Trigger != "scheduled" || s.Tier != 1 {
+		return
+	}
+	var p ping
+	switch s.Status {
+	case "completed", "escalated":
+		p = ping{ok: true, msg: "OK"}
+	case "skipped":
+		// Recent sessions had already checked every service.
+		p = ping{ok: true, msg: "OK (skipped, checks fresh)"}
+	case "failed", "timed_out":
+		p = ping{msg: failureMessage(s)}
+	default:
+		// Stopped by an operator or interrupted by shutdown: neither proves
+		// nor disproves that checks are working.
+		return
+	}
+	select {
+	case h.queue <- p:
+	default:
+		log.Printf("heartbeat: queue full, dropping ping")
+	}
+}
+
+// failureMessage describes why a scheduled session failed.
+func failureMessage(s *db.Session) string {
+	msg := fmt.Sprintf("Scheduled session #%d %s", s.ID, strings.ReplaceAll(s.Status, "_", " "))
+	if s.ExitCode != nil {
+		msg += fmt.Sprintf(" (exit code %d)", *s.ExitCode)
+	}
+	if s.Response != nil && strings.TrimSpace(*s.Response) != "" {
+		msg += ": " + strings.TrimSpace(*s.Response)
+	}
+	return textutil.Truncate(msg, maxMessage)
+}
+
+// send pings the monitor. Uptime Kuma receives status and msg as query
+// parameters; healthchecks.io receives a POST to the URL, or to its /fail
+// variant, with the message as the body.
+func (h *Heartbeat) send(ctx context.Context, p ping) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	u := *h.url
+	method := http.MethodPost
+	body := p.msg
+	if h.kuma {
+		q := u.Query()
+		q.Set("status", "up")
+		if !p.ok {
+			q.Set("status", "down")
+		}
+		q.Set("msg", p.msg)
+		u.RawQuery = q.Encode()
+		method, body = http.MethodGet, ""
+	} else if !p.ok {
+		u.Path = strings.TrimRight(u.Path, "/") + "/fail"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		// Report the cause without the URL, whose path holds the check's token.
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err
+		}
+		return fmt.Errorf("ping %s://%s: %w", u.Scheme, u.Host, err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("ping %s://%s: status %s", u.Scheme, u.Host, resp.Status)
+	}
+	return nil
+}
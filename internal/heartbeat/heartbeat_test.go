@@ -0,0 +1,105 @@
+package heartbeat
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// received is one request seen by the fake monitor.
+type received struct {
+	method, path, query, body string
+}
+
+func fakeMonitor(t *testing.T) (*httptest.Server, *[]received) {
+	t.Helper()
+	var got []received
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = append(got, received{r.Method, r.URL.Path, r.URL.RawQuery, string(b)})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+// drain sends every queued ping.
+func drain(t *testing.T, h *Heartbeat) {
+	t.Helper()
+	for {
+		select {
+		case p := <-h.queue:
+			if err := h.send(context.Background(), p); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if FromConfig(&config.Config{}) != nil {
+		t.Error("expected no heartbeat without a URL")
+	}
+	if FromConfig(&config.Config{HeartbeatURL: "hc-ping.com/uuid"}) != nil {
+		t.Error("expected no heartbeat for a URL without a scheme")
+	}
+	if h := FromConfig(&config.Config{HeartbeatURL: "https://kuma.example.com/api/push/abc?status=up"}); h == nil || !h.kuma {
+		t.Errorf("expected an Uptime Kuma heartbeat, got %+v", h)
+	}
+}
+
+func TestHealthchecksPings(t *testing.T) {
+	srv, got := fakeMonitor(t)
+	h, err := New(srv.URL + "/ping/uuid")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	exit := 1
+	resp := "claude: API error"
+	h.OnSessionEnd(&db.Session{ID: 1, Tier: 1, Trigger: "scheduled", Status: "completed"})
+	h.OnSessionEnd(&db.Session{ID: 2, Tier: 1, Trigger: "scheduled", Status: "failed", ExitCode: &exit, Response: &resp})
+	h.OnSessionEnd(&db.Session{ID: 3, Tier: 1, Trigger: "manual", Status: "completed"})
+	h.OnSessionEnd(&db.Session{ID: 4, Tier: 2, Trigger: "escalation", Status: "failed"})
+	h.OnSessionEnd(&db.Session{ID: 5, Tier: 1, Trigger: "scheduled", Status: "interrupted"})
+	drain(t, h)
+
+	if len(*got) != 2 {
+		t.Fatalf("expected 2 pings, got %+v", *got)
+	}
+	ok, fail := (*got)[0], (*got)[1]
+	if ok.method != http.MethodPost || ok.path != "/ping/uuid" {
+		t.Errorf("unexpected success ping %+v", ok)
+	}
+	if fail.path != "/ping/uuid/fail" || fail.body != "Scheduled session #2 failed (exit code 1): claude: API error" {
+		t.Errorf("unexpected failure ping %+v", fail)
+	}
+}
+
+func TestUptimeKumaPings(t *testing.T) {
+	srv, got := fakeMonitor(t)
+	h, err := New(srv.URL + "/api/push/token?status=up&msg=OK&ping=")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	h.OnSessionEnd(&db.Session{ID: 1, Tier: 1, Trigger: "scheduled", Status: "escalated"})
+	h.OnSessionEnd(&db.Session{ID: 2, Tier: 1, Trigger: "scheduled", Status: "timed_out"})
+	drain(t, h)
+
+	if len(*got) != 2 {
+		t.Fatalf("expected 2 pings, got %+v", *got)
+	}
+	if (*got)[0].method != http.MethodGet || !strings.Contains((*got)[0].query, "status=up") {
+		t.Errorf("unexpected success ping %+v", (*got)[0])
+	}
+	if (*got)[1].path != "/api/push/token" || !strings.Contains((*got)[1].query, "status=down") ||
+		!strings.Contains((*got)[1].query, "msg=Scheduled+session+%232+timed+out") {
+		t.Errorf("unexpected failure ping %+v", (*got)[1])
+	}
+}
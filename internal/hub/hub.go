@@ -1,47 +1,103 @@
+// Package hub is the event bus for live session output. The session manager
+// publishes every event of a session once, tagged with its Kind, and each
+// consumer subscribes only to the kinds it needs: the dashboard terminal to
+// formatted HTML lines, the OpenAI and Ollama endpoints to raw NDJSON, and
+// anything tracking runs to lifecycle changes.
 package hub
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 const defaultBufferCap = 1000
 
-// session holds the state for a single streaming session.
-type session struct {
-	buf     []string // circular buffer
-	pos     int      // next write position
-	count   int      // total lines written (may exceed cap)
-	clients map[chan string]struct{}
-	done    bool
+// Kind identifies what an Event carries. Kinds are bit flags so a
+// subscriber can ask for several at once, e.g. Raw|Lifecycle.
+type Kind uint8
+
+const (
+	// Raw is a redacted NDJSON line from the Claude CLI's stream-json output.
+	Raw Kind = 1 << iota
+	// Formatted is a color-coded HTML log line for the dashboard terminal.
+	Formatted
+	// Lifecycle is a session status change: "running" when it starts and
+	// its final status when it ends.
+	Lifecycle
+
+	// All matches every kind.
+	All = Raw | Formatted | Lifecycle
+)
+
+// ChainID returns the ID of the stream of the escalation chain whose first
+// session is rootSessionID. Chain streams are negative so they never share
+// an ID with a session's own stream.
+func ChainID(rootSessionID int64) int {
+	return -int(rootSessionID)
 }
 
-// lines returns the buffered lines in order from oldest to newest.
-func (s *session) lines() []string {
-	n := len(s.buf)
-	if n == 0 || s.pos == 0 {
+// Event is one item published for a session.
+type Event struct {
+	Kind Kind
+	Data string
+	seq  uint64 // publish order within the session, for merging replays
+}
+
+// ring holds the last defaultBufferCap events of one kind.
+type ring struct {
+	buf []Event // circular buffer
+	pos int     // next write position
+}
+
+// events returns the buffered events in order from oldest to newest.
+func (r *ring) events() []Event {
+	n := len(r.buf)
+	if n == 0 || r.pos == 0 {
 		// Buffer is empty, partially filled, or pos just wrapped to 0 —
 		// in all cases buf[:n] is already in order.
-		return s.buf
+		return r.buf
 	}
 	// Buffer has wrapped: pos points to the oldest entry.
-	out := make([]string, n)
-	copy(out, s.buf[s.pos:])
-	copy(out[n-s.pos:], s.buf[:s.pos])
+	out := make([]Event, n)
+	copy(out, r.buf[r.pos:])
+	copy(out[n-r.pos:], r.buf[:r.pos])
 	return out
 }
 
-// append adds a line to the circular buffer. O(1) regardless of size.
-func (s *session) append(line string) {
-	if len(s.buf) < cap(s.buf) {
-		s.buf = append(s.buf, line)
+// append adds an event to the circular buffer. O(1) regardless of size.
+func (r *ring) append(e Event) {
+	if len(r.buf) < cap(r.buf) {
+		r.buf = append(r.buf, e)
 	} else {
-		s.buf[s.pos] = line
+		r.buf[r.pos] = e
 	}
-	s.pos = (s.pos + 1) % cap(s.buf)
-	s.count++
+	r.pos = (r.pos + 1) % cap(r.buf)
 }
 
-// Hub fans out session output lines to multiple SSE subscribers.
-// It buffers the last defaultBufferCap lines per session so late-joining
-// clients receive catchup output before live streaming.
+// session holds the state for a single streaming session.
+type session struct {
+	rings   map[Kind]*ring
+	seq     uint64
+	clients map[chan Event]Kind // subscriber channel → kinds it receives
+	done    bool
+}
+
+// buffered returns the buffered events of the given kinds in publish order.
+func (s *session) buffered(kinds Kind) []Event {
+	var out []Event
+	for k, r := range s.rings {
+		if k&kinds != 0 {
+			out = append(out, r.events()...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].seq < out[j].seq })
+	return out
+}
+
+// Hub fans out session events to subscribers, each filtered to the kinds it
+// asked for. It buffers the last defaultBufferCap events of each kind per
+// session so late-joining clients receive catchup output before live
+// streaming.
 // Governing: SPEC-0008 REQ-6 — real-time session output streaming via SSE fan-out.
 type Hub struct {
 	mu       sync.Mutex
@@ -61,17 +117,17 @@ func (h *Hub) getOrCreate(id int) *session {
 	s, ok := h.sessions[id]
 	if !ok {
 		s = &session{
-			buf:     make([]string, 0, defaultBufferCap),
-			clients: make(map[chan string]struct{}),
+			rings:   make(map[Kind]*ring),
+			clients: make(map[chan Event]Kind),
 		}
 		h.sessions[id] = s
 	}
 	return s
 }
 
-// Publish sends a line to all current subscribers of the session and
-// appends it to the session buffer (up to defaultBufferCap lines).
-func (h *Hub) Publish(sessionID int, line string) {
+// Publish sends an event to the session's subscribers of its kind and
+// appends it to that kind's buffer (up to defaultBufferCap events).
+func (h *Hub) Publish(sessionID int, kind Kind, data string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -80,34 +136,46 @@ func (h *Hub) Publish(sessionID int, line string) {
 		return
 	}
 
-	s.append(line)
+	s.seq++
+	e := Event{Kind: kind, Data: data, seq: s.seq}
+	r, ok := s.rings[kind]
+	if !ok {
+		r = &ring{buf: make([]Event, 0, defaultBufferCap)}
+		s.rings[kind] = r
+	}
+	r.append(e)
 
 	// Fan out to all connected clients. Non-blocking send so a slow
 	// consumer cannot stall publishing.
-	for ch := range s.clients {
+	for ch, kinds := range s.clients {
+		if kinds&kind == 0 {
+			continue
+		}
 		select {
-		case ch <- line:
+		case ch <- e:
 		default:
 		}
 	}
 }
 
-// Subscribe returns a channel that receives future lines for the session
-// and an unsubscribe function. If the session has buffered lines, they
-// are sent immediately on the returned channel. If the session is already
-// done, the buffered lines are sent and the channel is closed.
-func (h *Hub) Subscribe(sessionID int) (<-chan string, func()) {
+// Subscribe returns a channel that receives future events of the given
+// kinds for the session and an unsubscribe function. If the session has
+// buffered events of those kinds, they are sent immediately on the returned
+// channel. If the session is already done, the buffered events are sent and
+// the channel is closed.
+func (h *Hub) Subscribe(sessionID int, kinds Kind) (<-chan Event, func()) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	s := h.getOrCreate(sessionID)
+	history := s.buffered(kinds)
 
 	// Buffer enough for catchup + some live headroom.
-	ch := make(chan string, defaultBufferCap+64)
+	ch := make(chan Event, len(history)+defaultBufferCap+64)
 
 	// Replay buffered history.
-	for _, line := range s.lines() {
-		ch <- line
+	for _, e := range history {
+		ch <- e
 	}
 
 	if s.done {
@@ -115,7 +183,7 @@ func (h *Hub) Subscribe(sessionID int) (<-chan string, func()) {
 		return ch, func() {}
 	}
 
-	s.clients[ch] = struct{}{}
+	s.clients[ch] = kinds
 
 	unsubscribe := func() {
 		h.mu.Lock()
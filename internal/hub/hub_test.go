@@ -8,17 +8,17 @@ import (
 
 func TestPublishAndSubscribe(t *testing.T) {
 	h := New()
-	ch, unsub := h.Subscribe(1)
+	ch, unsub := h.Subscribe(1, Formatted)
 	defer unsub()
 
-	h.Publish(1, "hello")
-	h.Publish(1, "world")
+	h.Publish(1, Formatted, "hello")
+	h.Publish(1, Formatted, "world")
 
-	got := <-ch
+	got := (<-ch).Data
 	if got != "hello" {
 		t.Fatalf("expected hello, got %q", got)
 	}
-	got = <-ch
+	got = (<-ch).Data
 	if got != "world" {
 		t.Fatalf("expected world, got %q", got)
 	}
@@ -27,15 +27,15 @@ func TestPublishAndSubscribe(t *testing.T) {
 func TestCatchupOnSubscribe(t *testing.T) {
 	h := New()
 
-	h.Publish(1, "line1")
-	h.Publish(1, "line2")
-	h.Publish(1, "line3")
+	h.Publish(1, Formatted, "line1")
+	h.Publish(1, Formatted, "line2")
+	h.Publish(1, Formatted, "line3")
 
-	ch, unsub := h.Subscribe(1)
+	ch, unsub := h.Subscribe(1, Formatted)
 	defer unsub()
 
 	for _, want := range []string{"line1", "line2", "line3"} {
-		got := <-ch
+		got := (<-ch).Data
 		if got != want {
 			t.Fatalf("expected %q, got %q", want, got)
 		}
@@ -44,9 +44,9 @@ func TestCatchupOnSubscribe(t *testing.T) {
 
 func TestCloseSession(t *testing.T) {
 	h := New()
-	ch, _ := h.Subscribe(1)
+	ch, _ := h.Subscribe(1, Formatted)
 
-	h.Publish(1, "before")
+	h.Publish(1, Formatted, "before")
 	h.Close(1)
 
 	// Drain buffered line, then channel should be closed.
@@ -60,14 +60,14 @@ func TestCloseSession(t *testing.T) {
 func TestSubscribeAfterClose(t *testing.T) {
 	h := New()
 
-	h.Publish(1, "a")
-	h.Publish(1, "b")
+	h.Publish(1, Formatted, "a")
+	h.Publish(1, Formatted, "b")
 	h.Close(1)
 
-	ch, _ := h.Subscribe(1)
+	ch, _ := h.Subscribe(1, Formatted)
 	var lines []string
 	for line := range ch {
-		lines = append(lines, line)
+		lines = append(lines, line.Data)
 	}
 	if len(lines) != 2 {
 		t.Fatalf("expected 2 catchup lines, got %d", len(lines))
@@ -81,7 +81,7 @@ func TestIsActive(t *testing.T) {
 		t.Fatal("expected inactive for unknown session")
 	}
 
-	h.Publish(1, "x")
+	h.Publish(1, Formatted, "x")
 	if !h.IsActive(1) {
 		t.Fatal("expected active after publish")
 	}
@@ -94,14 +94,14 @@ func TestIsActive(t *testing.T) {
 
 func TestPublishAfterCloseIsNoop(t *testing.T) {
 	h := New()
-	h.Publish(1, "before")
+	h.Publish(1, Formatted, "before")
 	h.Close(1)
-	h.Publish(1, "after") // should not panic or grow buffer
+	h.Publish(1, Formatted, "after") // should not panic or grow buffer
 
 	h.mu.Lock()
 	s := h.sessions[1]
-	if len(s.buf) != 1 {
-		t.Fatalf("expected 1 buffered line, got %d", len(s.buf))
+	if len(s.rings[Formatted].buf) != 1 {
+		t.Fatalf("expected 1 buffered line, got %d", len(s.rings[Formatted].buf))
 	}
 	h.mu.Unlock()
 }
@@ -109,13 +109,13 @@ func TestPublishAfterCloseIsNoop(t *testing.T) {
 func TestBufferEviction(t *testing.T) {
 	h := New()
 	for i := 0; i < defaultBufferCap+100; i++ {
-		h.Publish(1, "line")
+		h.Publish(1, Formatted, "line")
 	}
 
 	h.mu.Lock()
 	s := h.sessions[1]
-	if len(s.buf) != defaultBufferCap {
-		t.Fatalf("expected buffer capped at %d, got %d", defaultBufferCap, len(s.buf))
+	if len(s.rings[Formatted].buf) != defaultBufferCap {
+		t.Fatalf("expected buffer capped at %d, got %d", defaultBufferCap, len(s.rings[Formatted].buf))
 	}
 	h.mu.Unlock()
 }
@@ -125,18 +125,18 @@ func TestBufferEvictionOrdering(t *testing.T) {
 	// Write more than buffer capacity to force wrapping.
 	total := defaultBufferCap + 50
 	for i := 0; i < total; i++ {
-		h.Publish(1, fmt.Sprintf("line-%d", i))
+		h.Publish(1, Formatted, fmt.Sprintf("line-%d", i))
 	}
 
 	// Subscribe should get the last defaultBufferCap lines in order.
-	ch, unsub := h.Subscribe(1)
+	ch, unsub := h.Subscribe(1, Formatted)
 	defer unsub()
 
 	h.Close(1) // close so we can range over ch
 
 	var got []string
 	for line := range ch {
-		got = append(got, line)
+		got = append(got, line.Data)
 	}
 
 	if len(got) != defaultBufferCap {
@@ -158,15 +158,15 @@ func TestBufferEvictionOrdering(t *testing.T) {
 
 func TestMultipleSubscribers(t *testing.T) {
 	h := New()
-	ch1, unsub1 := h.Subscribe(1)
-	ch2, unsub2 := h.Subscribe(1)
+	ch1, unsub1 := h.Subscribe(1, Formatted)
+	ch2, unsub2 := h.Subscribe(1, Formatted)
 	defer unsub1()
 	defer unsub2()
 
-	h.Publish(1, "msg")
+	h.Publish(1, Formatted, "msg")
 
-	got1 := <-ch1
-	got2 := <-ch2
+	got1 := (<-ch1).Data
+	got2 := (<-ch2).Data
 	if got1 != "msg" || got2 != "msg" {
 		t.Fatalf("expected both subscribers to get msg, got %q and %q", got1, got2)
 	}
@@ -174,7 +174,7 @@ func TestMultipleSubscribers(t *testing.T) {
 
 func TestConcurrentPublish(t *testing.T) {
 	h := New()
-	ch, unsub := h.Subscribe(1)
+	ch, unsub := h.Subscribe(1, Formatted)
 	defer unsub()
 
 	var wg sync.WaitGroup
@@ -182,7 +182,7 @@ func TestConcurrentPublish(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			h.Publish(1, "concurrent")
+			h.Publish(1, Formatted, "concurrent")
 		}()
 	}
 	wg.Wait()
@@ -197,10 +197,10 @@ func TestConcurrentPublish(t *testing.T) {
 
 func TestUnsubscribe(t *testing.T) {
 	h := New()
-	ch, unsub := h.Subscribe(1)
+	ch, unsub := h.Subscribe(1, Formatted)
 	unsub()
 
-	h.Publish(1, "after-unsub")
+	h.Publish(1, Formatted, "after-unsub")
 
 	// Channel should not receive anything after unsubscribe.
 	select {
@@ -212,8 +212,8 @@ func TestUnsubscribe(t *testing.T) {
 
 func TestRemove(t *testing.T) {
 	h := New()
-	ch, _ := h.Subscribe(1)
-	h.Publish(1, "data")
+	ch, _ := h.Subscribe(1, Formatted)
+	h.Publish(1, Formatted, "data")
 
 	h.Remove(1)
 
@@ -233,7 +233,7 @@ func TestRemove(t *testing.T) {
 	}
 
 	// Re-publishing should create a fresh session.
-	h.Publish(1, "fresh")
+	h.Publish(1, Formatted, "fresh")
 	if !h.IsActive(1) {
 		t.Fatal("expected new session to be active")
 	}
@@ -247,25 +247,78 @@ func TestRemoveNonexistent(t *testing.T) {
 func TestMultipleSessions(t *testing.T) {
 	h := New()
 
-	ch1, unsub1 := h.Subscribe(1)
-	ch2, unsub2 := h.Subscribe(2)
+	ch1, unsub1 := h.Subscribe(1, Formatted)
+	ch2, unsub2 := h.Subscribe(2, Formatted)
 	defer unsub1()
 	defer unsub2()
 
-	h.Publish(1, "session-1")
-	h.Publish(2, "session-2")
+	h.Publish(1, Formatted, "session-1")
+	h.Publish(2, Formatted, "session-2")
 
-	if got := <-ch1; got != "session-1" {
+	if got := (<-ch1).Data; got != "session-1" {
 		t.Fatalf("session 1: expected session-1, got %q", got)
 	}
-	if got := <-ch2; got != "session-2" {
+	if got := (<-ch2).Data; got != "session-2" {
 		t.Fatalf("session 2: expected session-2, got %q", got)
 	}
 
 	// Closing one session shouldn't affect the other.
 	h.Close(1)
-	h.Publish(2, "still-alive")
-	if got := <-ch2; got != "still-alive" {
+	h.Publish(2, Formatted, "still-alive")
+	if got := (<-ch2).Data; got != "still-alive" {
 		t.Fatalf("session 2: expected still-alive, got %q", got)
 	}
 }
+
+func TestSubscribeFiltersByKind(t *testing.T) {
+	h := New()
+	raw, unsubRaw := h.Subscribe(1, Raw)
+	defer unsubRaw()
+	both, unsubBoth := h.Subscribe(1, Raw|Lifecycle)
+	defer unsubBoth()
+
+	h.Publish(1, Lifecycle, "running")
+	h.Publish(1, Raw, `{"type":"system"}`)
+	h.Publish(1, Formatted, "<span>system</span>")
+	h.Close(1)
+
+	var rawGot []Event
+	for e := range raw {
+		rawGot = append(rawGot, e)
+	}
+	if len(rawGot) != 1 || rawGot[0].Kind != Raw || rawGot[0].Data != `{"type":"system"}` {
+		t.Fatalf("expected only the raw event, got %+v", rawGot)
+	}
+	var kinds []Kind
+	for e := range both {
+		kinds = append(kinds, e.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != Lifecycle || kinds[1] != Raw {
+		t.Fatalf("expected lifecycle then raw, got %v", kinds)
+	}
+}
+
+func TestCatchupMergesKindsInOrder(t *testing.T) {
+	h := New()
+	h.Publish(1, Lifecycle, "running")
+	for i := 0; i < defaultBufferCap+10; i++ {
+		h.Publish(1, Raw, fmt.Sprintf("raw-%d", i))
+		h.Publish(1, Formatted, fmt.Sprintf("html-%d", i))
+	}
+	h.Publish(1, Lifecycle, "completed")
+	h.Close(1)
+
+	ch, _ := h.Subscribe(1, All)
+	var got []string
+	for e := range ch {
+		got = append(got, e.Data)
+	}
+	// Each kind keeps its own buffer, so the lifecycle events survive the
+	// flood of output lines.
+	if len(got) != 2*defaultBufferCap+2 {
+		t.Fatalf("expected %d events, got %d", 2*defaultBufferCap+2, len(got))
+	}
+	if got[0] != "running" || got[1] != "raw-10" || got[2] != "html-10" || got[len(got)-1] != "completed" {
+		t.Fatalf("unexpected replay order: %v ... %v", got[:3], got[len(got)-1])
+	}
+}
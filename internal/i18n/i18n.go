@@ -0,0 +1,120 @@
+// Package i18n translates dashboard text. Catalogs are gettext-style: the
+// English source string is the message ID, so English needs no catalog and
+// any string missing from a locale's catalog falls back to English.
+//
+// Catalogs live in locales/<lang>.json as flat {"English": "translation"}
+// objects and are embedded at build time. Adding a locale is adding a file.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the source language of the dashboard templates.
+const Default = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs maps a language code to its translations.
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	out := map[string]map[string]string{Default: {}}
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: read locales: %v", err))
+	}
+	for _, f := range files {
+		data, err := localeFS.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: read %s: %v", f.Name(), err))
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: parse %s: %v", f.Name(), err))
+		}
+		out[strings.TrimSuffix(f.Name(), ".json")] = catalog
+	}
+	return out
+}
+
+// Languages returns the supported language codes, the default first.
+func Languages() []string {
+	langs := []string{Default}
+	for lang := range catalogs {
+		if lang != Default {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// Name returns a language's name in that language, for a language picker.
+// Catalogs carry it under the reserved "$name" key.
+func Name(lang string) string {
+	if name := catalogs[lang]["$name"]; name != "" {
+		return name
+	}
+	if lang == Default {
+		return "English"
+	}
+	return lang
+}
+
+// Supported reports whether lang has a catalog.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// T translates msg into lang, formatting it with args when given. Unknown
+// languages and untranslated messages fall back to English.
+func T(lang, msg string, args ...any) string {
+	if tr, ok := catalogs[lang][msg]; ok && tr != "" {
+		msg = tr
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Negotiate picks the best supported language for an Accept-Language header
+// value, matching "es-MX" to "es" when there is no exact catalog.
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		if q <= bestQ {
+			continue
+		}
+		base, _, _ := strings.Cut(tag, "-")
+		switch {
+		case Supported(tag):
+			best, bestQ = tag, q
+		case Supported(base):
+			best, bestQ = base, q
+		}
+	}
+	return best
+}
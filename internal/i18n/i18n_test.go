@@ -0,0 +1,75 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header, want string
+	}{
+		{"", "en"},
+		{"es", "es"},
+		{"es-MX,es;q=0.9,en;q=0.8", "es"},
+		{"en-US,en;q=0.9,es;q=0.8", "en"},
+		{"fr-FR,fr;q=0.9,es;q=0.5", "es"},
+		{"fr, de", "en"},
+		{"en;q=0.2, ES;q=0.7", "es"},
+		{"*", "en"},
+		{"es;q=bogus, en", "en"},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("es", "Sessions"); got != "Sesiones" {
+		t.Errorf("T(es, Sessions) = %q", got)
+	}
+	if got := T("es", "Tier %d", 2); got != "Nivel 2" {
+		t.Errorf("T(es, Tier %%d) = %q", got)
+	}
+	if got := T("es", "Not in any catalog"); got != "Not in any catalog" {
+		t.Errorf("expected English fallback, got %q", got)
+	}
+	if got := T("xx", "Tier %d", 3); got != "Tier 3" {
+		t.Errorf("expected fallback for unknown language, got %q", got)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	langs := Languages()
+	if len(langs) < 2 || langs[0] != Default {
+		t.Fatalf("Languages() = %v", langs)
+	}
+	if Name("es") != "Español" || Name("en") != "English" {
+		t.Errorf("unexpected names %q, %q", Name("es"), Name("en"))
+	}
+}
+
+// Translations must keep their message's format verbs, or T would print
+// %!d(MISSING) and friends.
+func TestCatalogFormatVerbs(t *testing.T) {
+	verbs := regexp.MustCompile(`%[a-z%]`)
+	for lang, catalog := range catalogs {
+		for msg, tr := range catalog {
+			if msg == "$name" {
+				continue
+			}
+			want, got := verbs.FindAllString(msg, -1), verbs.FindAllString(tr, -1)
+			if len(want) != len(got) {
+				t.Errorf("%s: %q translates to %q with different format verbs", lang, msg, tr)
+				continue
+			}
+			for i := range want {
+				if want[i] != got[i] {
+					t.Errorf("%s: %q translates to %q with different format verbs", lang, msg, tr)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// export writes the article to <exportDir>/<service>.md. When the export
+// directory is a git checkout, the change is committed and, if the checkout
+// has a remote, pushed.
+func (g *Generator) export(ctx context.Context, a *db.KBArticle) error {
+	if !ValidService(a.Service) {
+		return fmt.Errorf("invalid service name %q", a.Service)
+	}
+	if err := os.MkdirAll(g.exportDir, 0o755); err != nil {
+		return err
+	}
+	name := a.Service + ".md"
+	if err := os.WriteFile(filepath.Join(g.exportDir, name), []byte(a.Content), 0o644); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(g.exportDir, ".git")); err != nil {
+		return nil
+	}
+	if _, err := g.run(ctx, g.exportDir, "add", "--", name); err != nil {
+		return err
+	}
+	// Nothing staged means the file content was already committed.
+	if _, err := g.run(ctx, g.exportDir, "diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	msg := fmt.Sprintf("Update %s runbook (v%d)", a.Service, a.Version)
+	if _, err := g.run(ctx, g.exportDir, "commit", "-m", msg, "--", name); err != nil {
+		return err
+	}
+	remotes, err := g.run(ctx, g.exportDir, "remote")
+	if err != nil || strings.TrimSpace(string(remotes)) == "" {
+		return err
+	}
+	_, err = g.run(ctx, g.exportDir, "push")
+	return err
+}
+
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
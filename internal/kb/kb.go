@@ -0,0 +1,224 @@
+// Package kb compiles per-service memories into runbook articles. Memories
+// are atomic fragments written for the agent ("takes 60s to start after
+// restart"); a runbook groups and rewrites them into something an operator
+// can read before touching a service.
+//
+// Articles are regenerated periodically with the summary model, stored as
+// versions in the database (browsable at /kb/{service}), and optionally
+// written to an export directory that may be a git checkout of a wiki.
+package kb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// maxMemories bounds how many memories are considered per regeneration.
+const maxMemories = 1000
+
+const compileSystemPrompt = `You are a technical writer maintaining an infrastructure runbook. You are given operational memories an on-call agent has learned about one service. Write a concise runbook article for that service in GitHub-flavoured markdown.
+
+Start with a level-1 heading containing the service name. Group the knowledge under level-2 headings (for example: Overview, Dependencies, Known Issues, Remediation, Timing). Merge duplicate or overlapping observations, prefer higher-confidence memories when they conflict, and mark anything labelled unverified as such. Do not invent facts that are not in the memories. Output only the article.`
+
+// CompileFunc turns a service's memories into a markdown article.
+type CompileFunc func(ctx context.Context, model, service string, memories []db.Memory) (string, error)
+
+// Generator regenerates runbook articles for every service with memories.
+type Generator struct {
+	db        *db.DB
+	model     string
+	interval  time.Duration
+	exportDir string
+	compile   CompileFunc
+	run       func(ctx context.Context, dir string, args ...string) ([]byte, error)
+}
+
+// FromConfig builds a Generator from CLAUDEOPS_KB_*. Returns nil when the
+// knowledge base is disabled (KBInterval is 0) or no summary model is set.
+func FromConfig(cfg *config.Config, database *db.DB) *Generator {
+	if cfg.KBInterval <= 0 || cfg.SummaryModel == "" {
+		return nil
+	}
+	g := New(database, cfg.SummaryModel, cfg.KBExportDir)
+	g.interval = time.Duration(cfg.KBInterval) * time.Hour
+	return g
+}
+
+// New creates a Generator that compiles articles with model via the
+// Anthropic Messages API. exportDir may be empty.
+func New(database *db.DB, model, exportDir string) *Generator {
+	return &Generator{
+		db:        database,
+		model:     model,
+		interval:  24 * time.Hour,
+		exportDir: exportDir,
+		compile:   compileArticle,
+		run:       runGit,
+	}
+}
+
+// Run regenerates articles immediately and then on every interval until ctx
+// is cancelled. Services whose memories are unchanged are skipped, so
+// restarts do not recompile everything.
+func (g *Generator) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		if n, err := g.GenerateAll(ctx); err != nil {
+			log.Printf("kb: %v", err)
+		} else if n > 0 {
+			log.Printf("kb: regenerated %d runbook article(s)", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GenerateAll regenerates the article for each service whose memories have
+// changed since its latest version and returns how many were regenerated.
+// A failure for one service is logged and does not stop the others.
+func (g *Generator) GenerateAll(ctx context.Context) (int, error) {
+	memories, err := g.db.GetActiveMemories(maxMemories, false)
+	if err != nil {
+		return 0, err
+	}
+	byService := make(map[string][]db.Memory)
+	for _, m := range memories {
+		// General (service-less) memories have no article of their own.
+		if m.Service == nil || !ValidService(*m.Service) {
+			continue
+		}
+		byService[*m.Service] = append(byService[*m.Service], m)
+	}
+	services := make([]string, 0, len(byService))
+	for svc := range byService {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+
+	n := 0
+	for _, svc := range services {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		a, err := g.Generate(ctx, svc, byService[svc])
+		if err != nil {
+			log.Printf("kb: %s: %v", svc, err)
+			continue
+		}
+		if a != nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Generate compiles and stores a new article version for service. Returns
+// nil without compiling when the memories match the latest version.
+func (g *Generator) Generate(ctx context.Context, service string, memories []db.Memory) (*db.KBArticle, error) {
+	hash := sourceHash(memories)
+	latest, err := g.db.GetKBArticle(service, 0)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.SourceHash == hash {
+		return nil, nil
+	}
+
+	content, err := g.compile(ctx, g.model, service, memories)
+	if err != nil {
+		return nil, fmt.Errorf("compile article: %w", err)
+	}
+	a := &db.KBArticle{
+		Service:     service,
+		Content:     strings.TrimSpace(content) + "\n",
+		MemoryCount: len(memories),
+		SourceHash:  hash,
+		Model:       g.model,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if _, err := g.db.InsertKBArticle(a); err != nil {
+		return nil, err
+	}
+	if g.exportDir != "" {
+		if err := g.export(ctx, a); err != nil {
+			// The article is stored; a failed export is retried on the next change.
+			log.Printf("kb: export %s: %v", service, err)
+		}
+	}
+	return a, nil
+}
+
+// serviceRe restricts article names to safe file names (they come from
+// agent output and are used as export paths).
+var serviceRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidService reports whether service can be used as an article name.
+func ValidService(service string) bool {
+	return serviceRe.MatchString(service) && !strings.Contains(service, "..")
+}
+
+// sourceHash fingerprints the memories an article is compiled from.
+func sourceHash(memories []db.Memory) string {
+	lines := make([]string, len(memories))
+	for i, m := range memories {
+		lines[i] = fmt.Sprintf("%d|%s|%s|%.2f|%s", m.ID, m.Category, m.Observation, m.Confidence, m.ReviewStatus)
+	}
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatMemories renders memories as the user message for the compile prompt.
+func formatMemories(service string, memories []db.Memory) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Service: %s\n\nMemories:\n", service)
+	for _, m := range memories {
+		label := ""
+		if m.ReviewStatus == db.MemoryUnverified {
+			label = ", unverified"
+		}
+		fmt.Fprintf(&b, "- [%s] %s (confidence: %.1f%s)\n", m.Category, m.Observation, m.Confidence, label)
+	}
+	return b.String()
+}
+
+// compileArticle calls the Anthropic Messages API to write the article.
+func compileArticle(ctx context.Context, model, service string, memories []db.Memory) (string, error) {
+	client := anthropic.NewClient()
+
+	msg, err := client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     anthropic.Model(model),
+		MaxTokens: 2000,
+		System: []anthropic.TextBlockParam{
+			{Text: compileSystemPrompt},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(formatMemories(service, memories))),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("anthropic messages: %w", err)
+	}
+	for _, block := range msg.Content {
+		if block.Type == "text" {
+			return block.Text, nil
+		}
+	}
+	return "", fmt.Errorf("no text block in response")
+}
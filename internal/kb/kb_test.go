@@ -0,0 +1,161 @@
+package kb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func testGenerator(t *testing.T, exportDir string) (*Generator, *db.DB, *int) {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	calls := 0
+	g := New(database, "test-model", exportDir)
+	g.compile = func(_ context.Context, _, service string, memories []db.Memory) (string, error) {
+		calls++
+		return "# " + service + "\n\n" + formatMemories(service, memories), nil
+	}
+	return g, database, &calls
+}
+
+func insertMemory(t *testing.T, database *db.DB, service, observation string) {
+	t.Helper()
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := database.InsertMemory(&db.Memory{
+		Service: &service, Category: "behavior", Observation: observation,
+		Confidence: 0.8, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	}); err != nil {
+		t.Fatalf("InsertMemory: %v", err)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if g := FromConfig(&config.Config{SummaryModel: "haiku"}, nil); g != nil {
+		t.Error("expected nil generator when interval is 0")
+	}
+	g := FromConfig(&config.Config{KBInterval: 6, SummaryModel: "haiku"}, nil)
+	if g == nil || g.interval != 6*time.Hour {
+		t.Fatalf("expected generator with 6h interval, got %+v", g)
+	}
+}
+
+func TestGenerateAll_SkipsUnchanged(t *testing.T) {
+	g, database, calls := testGenerator(t, "")
+	insertMemory(t, database, "jellyfin", "Takes 60s to start")
+	insertMemory(t, database, "caddy", "Reload on config change")
+	general := time.Now().UTC().Format(time.RFC3339)
+	_, _ = database.InsertMemory(&db.Memory{
+		Category: "behavior", Observation: "DNS flaps", Confidence: 0.8, Active: true,
+		CreatedAt: general, UpdatedAt: general, Tier: 1,
+	})
+
+	n, err := g.GenerateAll(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 articles, got %d (err %v)", n, err)
+	}
+
+	// Unchanged memories are not recompiled.
+	if n, _ := g.GenerateAll(context.Background()); n != 0 || *calls != 2 {
+		t.Fatalf("expected no regeneration, got %d (compile calls %d)", n, *calls)
+	}
+
+	insertMemory(t, database, "jellyfin", "DB lock on first restart")
+	if n, _ := g.GenerateAll(context.Background()); n != 1 {
+		t.Fatalf("expected 1 regenerated article, got %d", n)
+	}
+	a, err := database.GetKBArticle("jellyfin", 0)
+	if err != nil || a == nil {
+		t.Fatalf("GetKBArticle: %+v %v", a, err)
+	}
+	if a.Version != 2 || a.MemoryCount != 2 || !strings.Contains(a.Content, "DB lock on first restart") {
+		t.Errorf("unexpected article %+v", a)
+	}
+}
+
+func TestGenerate_CompileError(t *testing.T) {
+	g, database, _ := testGenerator(t, "")
+	g.compile = func(context.Context, string, string, []db.Memory) (string, error) {
+		return "", errors.New("rate limited")
+	}
+	insertMemory(t, database, "jellyfin", "Takes 60s to start")
+
+	if n, err := g.GenerateAll(context.Background()); err != nil || n != 0 {
+		t.Fatalf("expected failure to be skipped, got %d (err %v)", n, err)
+	}
+	if a, _ := database.GetKBArticle("jellyfin", 0); a != nil {
+		t.Fatalf("expected no article stored, got %+v", a)
+	}
+}
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	g, database, _ := testGenerator(t, dir)
+	insertMemory(t, database, "jellyfin", "Takes 60s to start")
+
+	if _, err := g.GenerateAll(context.Background()); err != nil {
+		t.Fatalf("GenerateAll: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "jellyfin.md"))
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# jellyfin") {
+		t.Errorf("unexpected export content %q", data)
+	}
+}
+
+func TestExport_GitCheckout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	g, database, _ := testGenerator(t, dir)
+	var cmds []string
+	g.run = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		cmds = append(cmds, args[0])
+		switch args[0] {
+		case "diff":
+			return nil, errors.New("exit status 1") // changes staged
+		case "remote":
+			return []byte("origin\n"), nil
+		}
+		return nil, nil
+	}
+	insertMemory(t, database, "jellyfin", "Takes 60s to start")
+
+	if _, err := g.GenerateAll(context.Background()); err != nil {
+		t.Fatalf("GenerateAll: %v", err)
+	}
+	if got := strings.Join(cmds, ","); got != "add,diff,commit,remote,push" {
+		t.Errorf("unexpected git commands %s", got)
+	}
+}
+
+func TestValidService(t *testing.T) {
+	for svc, want := range map[string]bool{
+		"jellyfin":    true,
+		"home-assist": true,
+		"app_v2.1":    true,
+		"":            false,
+		"../etc":      false,
+		".hidden":     false,
+		"a/b":         false,
+		"a..b":        false,
+	} {
+		if got := ValidService(svc); got != want {
+			t.Errorf("ValidService(%q) = %v, want %v", svc, got, want)
+		}
+	}
+}
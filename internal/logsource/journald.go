@@ -0,0 +1,67 @@
+package logsource
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Journald fetches logs from the systemd journal via journalctl.
+//
+// The match template selects the journal entries for a service. A plain
+// template (the default, "$service") is passed as --unit; a template
+// containing "=" is passed as a field match, e.g. "CONTAINER_NAME=$service"
+// for containers using Docker's journald log driver.
+type Journald struct {
+	match string
+	run   func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// NewJournald creates a journald source. An empty match uses "$service".
+func NewJournald(match string) *Journald {
+	if strings.TrimSpace(match) == "" {
+		match = ServicePlaceholder
+	}
+	return &Journald{match: match, run: runCommand}
+}
+
+// Name implements Source.
+func (j *Journald) Name() string { return "journald" }
+
+// Fetch implements Source.
+func (j *Journald) Fetch(ctx context.Context, service string, since time.Time, limit int) ([]string, error) {
+	args := []string{
+		"--since", since.Local().Format("2006-01-02 15:04:05"),
+		"--priority", "err",
+		"--lines", strconv.Itoa(limit),
+		"--no-pager",
+		"--output", "short-iso",
+	}
+	match := strings.ReplaceAll(j.match, ServicePlaceholder, service)
+	if strings.Contains(match, "=") {
+		args = append(args, match)
+	} else {
+		args = append(args, "--unit", match)
+	}
+
+	out, err := j.run(ctx, "journalctl", args...)
+	if err != nil {
+		return nil, fmt.Errorf("journalctl: %w", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		// journalctl prints "-- No entries --" and boot separators as "-- ... --".
+		if line == "" || strings.HasPrefix(line, "-- ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
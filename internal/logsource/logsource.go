@@ -0,0 +1,148 @@
+// Package logsource pre-fetches recent error logs for unhealthy services so
+// the supervisor can attach them to the escalation context. Without this,
+// Tier 2 spends its first turns rediscovering where logs live and how to
+// filter them; with it, the relevant error lines are already in the prompt.
+//
+// Two backends are supported: a Loki HTTP API (query_range) and the local
+// systemd journal via journalctl. Both are best-effort — a fetch failure is
+// reported in the context rather than blocking escalation.
+package logsource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/textutil"
+)
+
+// Source fetches recent error log lines for a single service.
+type Source interface {
+	// Name identifies the backend in the rendered context ("loki", "journald").
+	Name() string
+	// Fetch returns up to limit error lines for service logged since the given
+	// time, oldest first.
+	Fetch(ctx context.Context, service string, since time.Time, limit int) ([]string, error)
+}
+
+const (
+	// defaultLookback is used when CLAUDEOPS_LOG_LOOKBACK is unset or invalid.
+	defaultLookback = 30 * time.Minute
+
+	// defaultLines is used when CLAUDEOPS_LOG_LINES is unset or invalid.
+	defaultLines = 40
+
+	// maxLineLen truncates individual log lines so one stack trace cannot
+	// crowd out every other service in the prompt.
+	maxLineLen = 400
+)
+
+// Result is the outcome of fetching logs for one service.
+type Result struct {
+	Service string
+	Lines   []string
+	Err     error
+}
+
+// Fetcher fetches logs for a set of services from one Source using the
+// configured lookback window and line limit.
+type Fetcher struct {
+	source   Source
+	lookback time.Duration
+	lines    int
+}
+
+// FromConfig builds a Fetcher for CLAUDEOPS_LOG_SOURCE. Returns nil when the
+// log source is unset or unknown, so callers can treat nil as "disabled".
+func FromConfig(cfg *config.Config) *Fetcher {
+	var src Source
+	switch strings.ToLower(strings.TrimSpace(cfg.LogSource)) {
+	case "loki":
+		if cfg.LokiURL == "" {
+			return nil
+		}
+		src = NewLoki(cfg.LokiURL, cfg.LokiSelector)
+	case "journald":
+		src = NewJournald(cfg.JournaldUnit)
+	default:
+		return nil
+	}
+	return NewFetcher(src, time.Duration(cfg.LogLookback)*time.Minute, cfg.LogLines)
+}
+
+// NewFetcher wraps src with the given lookback window and per-service line
+// limit. Non-positive values fall back to the defaults.
+func NewFetcher(src Source, lookback time.Duration, lines int) *Fetcher {
+	if lookback <= 0 {
+		lookback = defaultLookback
+	}
+	if lines <= 0 {
+		lines = defaultLines
+	}
+	return &Fetcher{source: src, lookback: lookback, lines: lines}
+}
+
+// Fetch retrieves recent error logs for each service. Services are fetched
+// sequentially (the list is short — it comes from a single escalation) and
+// duplicates or invalid names are skipped.
+func (f *Fetcher) Fetch(ctx context.Context, services []string) []Result {
+	since := time.Now().Add(-f.lookback)
+	seen := make(map[string]bool, len(services))
+	var results []Result
+	for _, svc := range services {
+		svc = strings.TrimSpace(svc)
+		if !validService(svc) || seen[svc] {
+			continue
+		}
+		seen[svc] = true
+		lines, err := f.source.Fetch(ctx, svc, since, f.lines)
+		results = append(results, Result{Service: svc, Lines: lines, Err: err})
+	}
+	return results
+}
+
+// Format renders fetched logs as a markdown section for the next tier's
+// system prompt. Returns "" when there is nothing worth showing.
+func (f *Fetcher) Format(results []Result) string {
+	if len(results) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Recent Error Logs (%s, last %s)\n\n", f.source.Name(), f.lookback)
+	b.WriteString("Pre-fetched by the supervisor for the affected services. Start here before querying logs yourself.\n\n")
+	for _, r := range results {
+		fmt.Fprintf(&b, "### %s\n\n", r.Service)
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(&b, "_Log fetch failed: %v_\n\n", r.Err)
+		case len(r.Lines) == 0:
+			b.WriteString("_No error lines in window._\n\n")
+		default:
+			b.WriteString("```\n")
+			for _, line := range r.Lines {
+				b.WriteString(truncateLine(line) + "\n")
+			}
+			b.WriteString("```\n\n")
+		}
+	}
+	return b.String()
+}
+
+// validService rejects names that could be misread as journalctl options or
+// break out of a LogQL string literal. Service names come from agent output.
+func validService(s string) bool {
+	if s == "" || strings.HasPrefix(s, "-") {
+		return false
+	}
+	return !strings.ContainsAny(s, "\"\\`\n\r{}")
+}
+
+func truncateLine(s string) string {
+	s = strings.TrimRight(s, "\r\n")
+	if cut, ok := textutil.Cut(s, maxLineLen); ok {
+		return cut + "…"
+	}
+	return s
+}
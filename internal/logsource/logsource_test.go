@@ -0,0 +1,156 @@
+package logsource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+type stubSource struct {
+	calls []string
+	lines map[string][]string
+	err   error
+}
+
+func (s *stubSource) Name() string { return "stub" }
+
+func (s *stubSource) Fetch(_ context.Context, service string, _ time.Time, _ int) ([]string, error) {
+	s.calls = append(s.calls, service)
+	return s.lines[service], s.err
+}
+
+func TestFromConfig(t *testing.T) {
+	if f := FromConfig(&config.Config{}); f != nil {
+		t.Error("expected nil fetcher when log source is unset")
+	}
+	if f := FromConfig(&config.Config{LogSource: "loki"}); f != nil {
+		t.Error("expected nil fetcher for loki without a URL")
+	}
+	if f := FromConfig(&config.Config{LogSource: "Loki", LokiURL: "http://loki:3100"}); f == nil || f.source.Name() != "loki" {
+		t.Error("expected loki fetcher")
+	}
+	f := FromConfig(&config.Config{LogSource: "journald"})
+	if f == nil || f.source.Name() != "journald" {
+		t.Fatal("expected journald fetcher")
+	}
+	if f.lookback != defaultLookback || f.lines != defaultLines {
+		t.Errorf("expected defaults, got lookback=%s lines=%d", f.lookback, f.lines)
+	}
+}
+
+func TestFetcher_SkipsDuplicatesAndInvalidNames(t *testing.T) {
+	src := &stubSource{}
+	f := NewFetcher(src, time.Minute, 10)
+	f.Fetch(context.Background(), []string{"nginx", " nginx ", "--all", `x"}`, "", "postgres"})
+	if strings.Join(src.calls, ",") != "nginx,postgres" {
+		t.Errorf("unexpected fetch calls %v", src.calls)
+	}
+}
+
+func TestFetcher_Format(t *testing.T) {
+	src := &stubSource{lines: map[string][]string{"nginx": {"upstream timed out", strings.Repeat("x", 1000)}}}
+	f := NewFetcher(src, 30*time.Minute, 10)
+
+	out := f.Format(f.Fetch(context.Background(), []string{"nginx", "postgres"}))
+	for _, want := range []string{
+		"## Recent Error Logs (stub, last 30m0s)",
+		"### nginx",
+		"upstream timed out",
+		"### postgres",
+		"_No error lines in window._",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, strings.Repeat("x", maxLineLen+1)) {
+		t.Error("expected long lines to be truncated")
+	}
+
+	src.err = errors.New("connection refused")
+	out = f.Format(f.Fetch(context.Background(), []string{"nginx"}))
+	if !strings.Contains(out, "_Log fetch failed: connection refused_") {
+		t.Errorf("expected fetch error in output:\n%s", out)
+	}
+
+	if got := f.Format(nil); got != "" {
+		t.Errorf("expected empty output for no results, got %q", got)
+	}
+}
+
+func TestLoki_Fetch(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/query_range" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		gotQuery = r.URL.Query().Get("query")
+		// Two streams, interleaved and newest first, as Loki returns with direction=backward.
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"container":"nginx"},"values":[["3000000000","third"],["1000000000","first"]]},
+			{"stream":{"container":"nginx"},"values":[["2000000000","second"]]}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	l := NewLoki(srv.URL+"/", "")
+	lines, err := l.Fetch(context.Background(), "nginx", time.Now().Add(-time.Hour), 2)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if gotQuery != `{container="nginx"} |~ "(?i)(error|fatal|panic|exception)"` {
+		t.Errorf("unexpected query %q", gotQuery)
+	}
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], " second") || !strings.HasSuffix(lines[1], " third") {
+		t.Errorf("expected the 2 most recent lines oldest first, got %v", lines)
+	}
+}
+
+func TestLoki_FetchHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad query", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	if _, err := NewLoki(srv.URL, "").Fetch(context.Background(), "nginx", time.Now(), 10); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+}
+
+func TestJournald_Fetch(t *testing.T) {
+	cases := []struct {
+		match    string
+		wantTail []string
+	}{
+		{"", []string{"--unit", "nginx"}},
+		{"CONTAINER_NAME=$service", []string{"CONTAINER_NAME=nginx"}},
+	}
+	for _, tc := range cases {
+		var gotArgs []string
+		j := NewJournald(tc.match)
+		j.run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+			if name != "journalctl" {
+				t.Errorf("unexpected command %s", name)
+			}
+			gotArgs = args
+			return []byte("-- Boot abc --\n2026-01-01T00:00:00+0000 host nginx[1]: error one\n\n"), nil
+		}
+		lines, err := j.Fetch(context.Background(), "nginx", time.Now(), 5)
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		if len(lines) != 1 || !strings.HasSuffix(lines[0], "error one") {
+			t.Errorf("unexpected lines %v", lines)
+		}
+		tail := gotArgs[len(gotArgs)-len(tc.wantTail):]
+		if strings.Join(tail, " ") != strings.Join(tc.wantTail, " ") {
+			t.Errorf("match %q: got args %v", tc.match, gotArgs)
+		}
+	}
+}
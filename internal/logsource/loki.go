@@ -0,0 +1,107 @@
+package logsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServicePlaceholder is replaced with the service name in the Loki selector
+// and the journald unit/match template.
+const ServicePlaceholder = "$service"
+
+// DefaultLokiSelector matches error-looking lines from a container named
+// after the service.
+const DefaultLokiSelector = `{container="$service"} |~ "(?i)(error|fatal|panic|exception)"`
+
+// Loki fetches logs via the Loki HTTP API (/loki/api/v1/query_range).
+type Loki struct {
+	baseURL  string
+	selector string
+	client   *http.Client
+}
+
+// NewLoki creates a Loki source. selector is a LogQL query containing
+// ServicePlaceholder; an empty selector uses DefaultLokiSelector.
+func NewLoki(baseURL, selector string) *Loki {
+	if strings.TrimSpace(selector) == "" {
+		selector = DefaultLokiSelector
+	}
+	return &Loki{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		selector: selector,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name implements Source.
+func (l *Loki) Name() string { return "loki" }
+
+// Fetch implements Source.
+func (l *Loki) Fetch(ctx context.Context, service string, since time.Time, limit int) ([]string, error) {
+	q := url.Values{}
+	q.Set("query", strings.ReplaceAll(l.selector, ServicePlaceholder, service))
+	q.Set("start", strconv.FormatInt(since.UnixNano(), 10))
+	q.Set("end", strconv.FormatInt(time.Now().UnixNano(), 10))
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("direction", "backward")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.baseURL+"/loki/api/v1/query_range?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("loki query: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read loki response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("loki query: %s", resp.Status)
+	}
+
+	var parsed struct {
+		Data struct {
+			Result []struct {
+				Values [][2]string `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("decode loki response: %w", err)
+	}
+
+	type entry struct {
+		ts   int64
+		line string
+	}
+	var entries []entry
+	for _, stream := range parsed.Data.Result {
+		for _, v := range stream.Values {
+			ts, _ := strconv.ParseInt(v[0], 10, 64)
+			entries = append(entries, entry{ts: ts, line: v[1]})
+		}
+	}
+	// Streams are returned independently; merge them into one timeline and
+	// keep the most recent lines.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ts < entries[j].ts })
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = time.Unix(0, e.ts).UTC().Format(time.RFC3339) + " " + e.line
+	}
+	return lines, nil
+}
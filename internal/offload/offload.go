@@ -0,0 +1,267 @@
+// Package offload moves the logs and artifacts of old sessions to
+// S3-compatible object storage, keeping the results volume small on hosts
+// with little disk. An offloaded log's object key is recorded on its
+// session and the local file is replaced by a one-line stub naming the
+// object; the dashboard fetches the log from the bucket when a session
+// page, log search, or snapshot reads it. A log's line index stays on local
+// disk, as it is small.
+//
+// A session's artifacts are the diffs of the commits it made and the file
+// contents of its change report, which the database stores. They are
+// offloaded as one JSON object per session, sealed when encryption at rest
+// is on, and their columns emptied; the session page fetches them back. A
+// change report still pending keeps its files until it is decided, as
+// applying it needs them.
+package offload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+const (
+	// offloadInterval is how often logs are checked for offloading.
+	offloadInterval = time.Hour
+
+	// batchSize bounds the sessions listed per query.
+	batchSize = 50
+)
+
+// ErrNotConfigured is returned when reading an offloaded log without object
+// storage configured.
+var ErrNotConfigured = errors.New("session log was moved to object storage, which is not configured")
+
+// Store is object storage for logs.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	URI(key string) string
+}
+
+// Offloader moves session logs to a Store once their session is older than
+// its age, and reads them back. A nil *Offloader reads local logs only.
+type Offloader struct {
+	db     *db.DB
+	store  Store
+	prefix string
+	age    time.Duration
+	now    func() time.Time
+}
+
+// FromConfig builds an Offloader from the CLAUDEOPS_S3_* settings, offloading
+// logs older than CLAUDEOPS_OFFLOAD_DAYS. It returns nil when no bucket is
+// configured or the endpoint is invalid. With a bucket but no
+// CLAUDEOPS_OFFLOAD_DAYS, logs offloaded earlier are still read back but no
+// more are offloaded.
+func FromConfig(cfg *config.Config, database *db.DB) *Offloader {
+	if cfg.S3Bucket == "" {
+		if cfg.OffloadDays > 0 {
+			log.Printf("offload: CLAUDEOPS_OFFLOAD_DAYS is set but CLAUDEOPS_S3_BUCKET is not (offload disabled)")
+		}
+		return nil
+	}
+	store, err := NewS3(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey)
+	if err != nil {
+		log.Printf("offload: %v (offload disabled)", err)
+		return nil
+	}
+	return New(database, store, cfg.S3Prefix, time.Duration(cfg.OffloadDays)*24*time.Hour)
+}
+
+// New creates an Offloader storing logs under prefix in store once their
+// session ended more than age ago (0 never offloads).
+func New(database *db.DB, store Store, prefix string, age time.Duration) *Offloader {
+	return &Offloader{db: database, store: store, prefix: prefix, age: age, now: time.Now}
+}
+
+// Run offloads logs until ctx is cancelled. It returns at once when logs
+// are not offloaded.
+func (o *Offloader) Run(ctx context.Context) {
+	if o.age <= 0 {
+		return
+	}
+	log.Printf("offload: moving session logs older than %g days to %s", o.age.Hours()/24, o.store.URI(o.prefix))
+	o.offloadAll(ctx)
+	ticker := time.NewTicker(offloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.offloadAll(ctx)
+		}
+	}
+}
+
+// offloadAll offloads the log and artifacts of every session that ended
+// before the cutoff and still keeps them locally.
+func (o *Offloader) offloadAll(ctx context.Context) {
+	o.offloadLogs(ctx)
+	o.offloadAllArtifacts(ctx)
+}
+
+func (o *Offloader) offloadLogs(ctx context.Context) {
+	cutoff := o.now().Add(-o.age).UTC().Format(time.RFC3339)
+	var after int64
+	for ctx.Err() == nil {
+		sessions, err := o.db.ListSessionsToOffload(cutoff, after, batchSize)
+		if err != nil {
+			log.Printf("offload: %v", err)
+			return
+		}
+		for i := range sessions {
+			s := &sessions[i]
+			after = s.ID
+			if err := o.offload(ctx, s); err != nil {
+				log.Printf("offload: session %d: %v", s.ID, err)
+			}
+		}
+		if len(sessions) < batchSize {
+			return
+		}
+	}
+}
+
+func (o *Offloader) offloadAllArtifacts(ctx context.Context) {
+	cutoff := o.now().Add(-o.age).UTC().Format(time.RFC3339)
+	var after int64
+	for ctx.Err() == nil {
+		sessions, err := o.db.ListSessionsToOffloadArtifacts(cutoff, after, batchSize)
+		if err != nil {
+			log.Printf("offload: %v", err)
+			return
+		}
+		for i := range sessions {
+			s := &sessions[i]
+			after = s.ID
+			if err := o.offloadArtifacts(ctx, s); err != nil {
+				log.Printf("offload: session %d artifacts: %v", s.ID, err)
+			}
+		}
+		if len(sessions) < batchSize {
+			return
+		}
+	}
+}
+
+// offload uploads a session's log, records its key, and replaces the local
+// file with a stub. A log that no longer exists is skipped.
+func (o *Offloader) offload(ctx context.Context, s *db.Session) error {
+	data, err := os.ReadFile(*s.LogFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	key := o.prefix + filepath.Base(*s.LogFile)
+	if err := o.store.Put(ctx, key, data); err != nil {
+		return err
+	}
+	if err := o.db.SetSessionLogObject(s.ID, key); err != nil {
+		return err
+	}
+	return writeStub(*s.LogFile, o.store.URI(key))
+}
+
+// writeStub replaces a log with a line saying where it went.
+func writeStub(logPath, uri string) error {
+	tmp := logPath + ".tmp"
+	stub := fmt.Sprintf("This session log was moved to object storage: %s\n", uri)
+	if err := os.WriteFile(tmp, []byte(stub), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, logPath); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// artifactsKey is the object key of a session's artifacts.
+func (o *Offloader) artifactsKey(sessionID int64) string {
+	return fmt.Sprintf("%ssession-%d-artifacts.json", o.prefix, sessionID)
+}
+
+// offloadArtifacts uploads a session's diffs and decided change report
+// files, then empties their columns.
+func (o *Offloader) offloadArtifacts(ctx context.Context, s *db.Session) error {
+	a, err := o.db.GetSessionArtifacts(s.ID)
+	if err != nil {
+		return err
+	}
+	if len(a.CommitDiffs) == 0 && len(a.Files) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	key := o.artifactsKey(s.ID)
+	if err := o.store.Put(ctx, key, []byte(o.db.Sealer().Seal(string(data)))); err != nil {
+		return err
+	}
+	return o.db.OffloadSessionArtifacts(s.ID, key, a)
+}
+
+// RestoreArtifacts fills in the diffs and change file contents of a session
+// whose artifacts were offloaded, fetching them from object storage.
+func (o *Offloader) RestoreArtifacts(ctx context.Context, s *db.Session, commits []db.SessionCommit, report *db.ChangeReport) error {
+	if s.ArtifactsObject == nil {
+		return nil
+	}
+	if o == nil {
+		return ErrNotConfigured
+	}
+	body, err := o.store.Get(ctx, *s.ArtifactsObject)
+	if err != nil {
+		return err
+	}
+	defer body.Close() //nolint:errcheck
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	text, err := o.db.Sealer().Open(strings.TrimSpace(string(data)))
+	if err != nil {
+		return err
+	}
+	var a db.SessionArtifacts
+	if err := json.Unmarshal([]byte(text), &a); err != nil {
+		return fmt.Errorf("decode %s: %w", *s.ArtifactsObject, err)
+	}
+	a.Restore(commits, report)
+	return nil
+}
+
+// Open opens a session's log for reading, from object storage when it was
+// offloaded and from the local file otherwise.
+func (o *Offloader) Open(ctx context.Context, s *db.Session) (io.ReadCloser, error) {
+	if s.LogFile == nil || *s.LogFile == "" {
+		return nil, os.ErrNotExist
+	}
+	if s.LogObject == nil {
+		return session.OpenLog(*s.LogFile)
+	}
+	if o == nil {
+		return nil, ErrNotConfigured
+	}
+	body, err := o.store.Get(ctx, *s.LogObject)
+	if err != nil {
+		return nil, err
+	}
+	return session.NewLogReader(body, *s.LogFile)
+}
@@ -0,0 +1,182 @@
+package offload
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// memStore is an in-memory Store.
+type memStore map[string][]byte
+
+func (m memStore) Put(_ context.Context, key string, data []byte) error {
+	m[key] = data
+	return nil
+}
+
+func (m memStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m memStore) URI(key string) string { return "mem://" + key }
+
+func TestOffload(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	insert := func(name string, ended time.Time) int64 {
+		t.Helper()
+		logPath := filepath.Join(dir, name)
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write([]byte("2026-10-01T00:00:00Z\t{\"type\":\"result\"}\n"))
+		_ = zw.Close()
+		if err := os.WriteFile(logPath, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		endedAt := ended.Format(time.RFC3339)
+		id, err := database.InsertSession(&db.Session{Tier: 1, Model: "haiku", PromptFile: "/p.md", Status: "completed", StartedAt: endedAt, EndedAt: &endedAt, LogFile: &logPath})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		return id
+	}
+	old := insert("run-old.log.gz", now.Add(-10*24*time.Hour))
+	recent := insert("run-recent.log.gz", now.Add(-time.Hour))
+
+	store := memStore{}
+	o := New(database, store, "claudeops/", 7*24*time.Hour)
+	o.now = func() time.Time { return now }
+	o.offloadAll(context.Background())
+
+	if _, ok := store["claudeops/run-old.log.gz"]; !ok || len(store) != 1 {
+		t.Fatalf("stored objects = %v, want only the old log", store)
+	}
+	s, _ := database.GetSession(old)
+	if s.LogObject == nil || *s.LogObject != "claudeops/run-old.log.gz" {
+		t.Fatalf("LogObject = %v", s.LogObject)
+	}
+	stub, _ := os.ReadFile(*s.LogFile)
+	if !strings.Contains(string(stub), "mem://claudeops/run-old.log.gz") {
+		t.Errorf("stub = %q", stub)
+	}
+
+	read := func(o *Offloader, s *db.Session) (string, error) {
+		f, err := o.Open(context.Background(), s)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close() //nolint:errcheck
+		data, err := io.ReadAll(f)
+		return string(data), err
+	}
+	if got, err := read(o, s); err != nil || !strings.Contains(got, `{"type":"result"}`) {
+		t.Errorf("offloaded log = %q, %v", got, err)
+	}
+	r, _ := database.GetSession(recent)
+	if got, err := read(nil, r); err != nil || !strings.Contains(got, `{"type":"result"}`) {
+		t.Errorf("local log = %q, %v", got, err)
+	}
+	if _, err := read(nil, s); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("offloaded log without storage: err = %v", err)
+	}
+
+	// Offloaded logs are not offloaded again.
+	delete(store, "claudeops/run-old.log.gz")
+	o.offloadAll(context.Background())
+	if len(store) != 0 {
+		t.Errorf("offloaded again: %v", store)
+	}
+}
+
+func TestOffloadArtifacts(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	endedAt := now.Add(-10 * 24 * time.Hour).Format(time.RFC3339)
+
+	insert := func(reportStatus string) int64 {
+		t.Helper()
+		id, err := database.InsertSession(&db.Session{Tier: 3, Model: "opus", PromptFile: "/p.md", Status: "completed", StartedAt: endedAt, EndedAt: &endedAt})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		if _, err := database.InsertSessionCommit(&db.SessionCommit{SessionID: id, Repo: "/repos/infra", Branch: "claudeops/fix", BaseBranch: "main",
+			CommitSHA: "abc123", DiffStat: "1 file changed", Diff: "+restart: always", CreatedAt: endedAt}); err != nil {
+			t.Fatalf("InsertSessionCommit: %v", err)
+		}
+		r := &db.ChangeReport{SessionID: id, CreatedAt: endedAt, Files: []db.ChangeFile{
+			{Path: "/repos/infra/compose.yaml", Kind: "modified", Mode: 0o644, OldContent: []byte("a"), NewContent: []byte("b"), Diff: "-a\n+b"},
+		}}
+		if _, err := database.InsertChangeReport(r); err != nil {
+			t.Fatalf("InsertChangeReport: %v", err)
+		}
+		if reportStatus != db.ChangesPending {
+			if _, err := database.DecideChangeReport(r.ID, reportStatus, "ops", endedAt); err != nil {
+				t.Fatalf("DecideChangeReport: %v", err)
+			}
+		}
+		return id
+	}
+	decided := insert(db.ChangesApplied)
+	pending := insert(db.ChangesPending)
+
+	store := memStore{}
+	o := New(database, store, "claudeops/", 7*24*time.Hour)
+	o.now = func() time.Time { return now }
+	o.offloadAll(context.Background())
+
+	for _, tc := range []struct {
+		id      int64
+		emptied bool
+	}{{decided, true}, {pending, false}} {
+		s, _ := database.GetSession(tc.id)
+		if s.ArtifactsObject == nil {
+			t.Fatalf("session %d: artifacts were not offloaded", tc.id)
+		}
+		commits, _ := database.ListSessionCommits(tc.id)
+		report, _ := database.GetSessionChangeReport(tc.id)
+		if commits[0].Diff != "" {
+			t.Errorf("session %d: commit diff still stored", tc.id)
+		}
+		if got := report.Files[0].Diff == ""; got != tc.emptied {
+			t.Errorf("session %d: change file emptied = %v, want %v", tc.id, got, tc.emptied)
+		}
+
+		if err := o.RestoreArtifacts(context.Background(), s, commits, report); err != nil {
+			t.Fatalf("RestoreArtifacts: %v", err)
+		}
+		f := report.Files[0]
+		if commits[0].Diff != "+restart: always" || f.Diff != "-a\n+b" || string(f.OldContent) != "a" || string(f.NewContent) != "b" {
+			t.Errorf("session %d: restored commit diff %q, file %+v", tc.id, commits[0].Diff, f)
+		}
+	}
+
+	// Without object storage the session page cannot fetch them.
+	s, _ := database.GetSession(decided)
+	var none *Offloader
+	if err := none.RestoreArtifacts(context.Background(), s, nil, nil); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("RestoreArtifacts without storage: %v, want ErrNotConfigured", err)
+	}
+}
@@ -0,0 +1,413 @@
+// Package paging opens incidents with PagerDuty or Opsgenie when something
+// needs a human: a critical event for a service, a failed Tier 3
+// remediation, or a remediation that did not hold. An incident is resolved
+// once a verification session, or any later session that checks the
+// service, reports its service healthy. Each incident is
+// recorded in the database and shown on the session that raised it.
+package paging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+	"github.com/joestump/claude-ops/internal/textutil"
+)
+
+const (
+	pagerDutyURL       = "https://events.pagerduty.com/v2/enqueue"
+	defaultOpsgenieURL = "https://api.opsgenie.com"
+
+	// queueSize bounds the paging jobs waiting to run. When a provider is
+	// slow, new jobs are dropped rather than stalling sessions.
+	queueSize = 128
+
+	requestTimeout = 15 * time.Second
+)
+
+// provider opens and resolves incidents keyed by a deduplication key.
+type provider interface {
+	name() string
+	trigger(ctx context.Context, key string, inc incident) error
+	resolve(ctx context.Context, key string) error
+}
+
+// incident describes what to page about.
+type incident struct {
+	service   string
+	summary   string
+	sessionID *int64
+	tier      int
+}
+
+// Pager implements session.Hooks, turning lifecycle callbacks into paging
+// jobs that Run carries out in order.
+type Pager struct {
+	session.NopHooks
+
+	db        *db.DB
+	providers []provider
+	queue     chan func(context.Context)
+	now       func() time.Time
+}
+
+// FromConfig builds a Pager from CLAUDEOPS_PAGERDUTY_ROUTING_KEY and
+// CLAUDEOPS_OPSGENIE_API_KEY. Returns nil when neither is set or in
+// dry-run mode.
+func FromConfig(cfg *config.Config, database *db.DB) *Pager {
+	if cfg.DryRun {
+		return nil
+	}
+	client := &http.Client{Timeout: requestTimeout}
+	var providers []provider
+	if cfg.PagerDutyRoutingKey != "" {
+		providers = append(providers, &pagerDuty{url: pagerDutyURL, routingKey: cfg.PagerDutyRoutingKey, client: client})
+	}
+	if cfg.OpsgenieAPIKey != "" {
+		base := strings.TrimRight(cfg.OpsgenieURL, "/")
+		if base == "" {
+			base = defaultOpsgenieURL
+		}
+		providers = append(providers, &opsgenie{url: base, apiKey: cfg.OpsgenieAPIKey, client: client})
+	}
+	if len(providers) == 0 {
+		return nil
+	}
+	return newPager(database, providers)
+}
+
+func newPager(database *db.DB, providers []provider) *Pager {
+	return &Pager{
+		db:        database,
+		providers: providers,
+		queue:     make(chan func(context.Context), queueSize),
+		now:       time.Now,
+	}
+}
+
+// Run carries out queued paging jobs until ctx is cancelled.
+func (p *Pager) Run(ctx context.Context) {
+	names := make([]string, len(p.providers))
+	for i, pr := range p.providers {
+		names[i] = pr.name()
+	}
+	log.Printf("paging: opening incidents with %s", strings.Join(names, ", "))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-p.queue:
+			job(ctx)
+		}
+	}
+}
+
+// enqueue queues a job without blocking.
+func (p *Pager) enqueue(job func(context.Context)) {
+	select {
+	case p.queue <- job:
+	default:
+		log.Printf("paging: queue full, dropping job")
+	}
+}
+
+// OnEvent pages for critical events tied to a service.
+func (p *Pager) OnEvent(evt *db.Event) {
+	if evt.Level != "critical" || evt.Service == nil || *evt.Service == "" {
+		return
+	}
+	inc := incident{service: *evt.Service, summary: *evt.Service + ": " + evt.Message, sessionID: evt.SessionID}
+	p.enqueue(func(ctx context.Context) { p.trigger(ctx, inc) })
+}
+
+// OnSessionEnd pages for each service of a Tier 3 session that failed or
+// timed out, and resolves the incidents of services a finished session
+// found healthy.
+func (p *Pager) OnSessionEnd(s *db.Session) {
+	sid := s.ID
+	if s.Status == "completed" || s.Status == "escalated" {
+		p.enqueue(func(ctx context.Context) { p.resolveHealthy(ctx, sid) })
+	}
+	if s.Tier != 3 || (s.Status != "failed" && s.Status != "timed_out") {
+		return
+	}
+	for _, svc := range sessionServices(s) {
+		inc := incident{
+			service:   svc,
+			summary:   fmt.Sprintf("%s: Tier 3 remediation %s (session #%d)", svc, strings.ReplaceAll(s.Status, "_", " "), s.ID),
+			sessionID: &sid,
+			tier:      3,
+		}
+		p.enqueue(func(ctx context.Context) { p.trigger(ctx, inc) })
+	}
+}
+
+// OnVerification pages for services whose remediation did not hold and
+// resolves the incidents of services verified healthy.
+func (p *Pager) OnVerification(v session.Verification) {
+	sid := v.SessionID
+	unhealthy := make(map[string]bool, len(v.Unhealthy))
+	for _, svc := range v.Unhealthy {
+		unhealthy[svc] = true
+		inc := incident{
+			service:   svc,
+			summary:   fmt.Sprintf("%s: remediation did not hold after Tier 3 session #%d", svc, v.RemediationID),
+			sessionID: &sid,
+			tier:      3,
+		}
+		p.enqueue(func(ctx context.Context) { p.trigger(ctx, inc) })
+	}
+	for _, svc := range v.Services {
+		if !unhealthy[svc] {
+			p.enqueue(func(ctx context.Context) { p.resolve(ctx, svc, &sid) })
+		}
+	}
+}
+
+// trigger opens an incident for inc.service with every provider that has
+// no open incident for it yet. Drill sessions never page.
+func (p *Pager) trigger(ctx context.Context, inc incident) {
+	if inc.sessionID != nil {
+		if s, err := p.db.GetSession(*inc.sessionID); err == nil && s != nil && s.Trigger == "drill" {
+			return
+		}
+	}
+	open, err := p.db.ListOpenPagerIncidents(inc.service)
+	if err != nil {
+		log.Printf("paging: %v", err)
+		return
+	}
+	for _, pr := range p.providers {
+		if hasProvider(open, pr.name()) {
+			continue
+		}
+		key := dedupKey(inc.service)
+		if err := pr.trigger(ctx, key, inc); err != nil {
+			log.Printf("paging: %s trigger %s: %v", pr.name(), inc.service, err)
+			continue
+		}
+		if _, err := p.db.InsertPagerIncident(&db.PagerIncident{
+			Provider:  pr.name(),
+			DedupKey:  key,
+			Service:   inc.service,
+			SessionID: inc.sessionID,
+			Summary:   inc.summary,
+			CreatedAt: p.now().UTC().Format(time.RFC3339),
+		}); err != nil {
+			log.Printf("paging: %v", err)
+		}
+	}
+}
+
+// resolve resolves the open incidents for service, crediting the
+// verification session sessionID.
+func (p *Pager) resolve(ctx context.Context, service string, sessionID *int64) {
+	open, err := p.db.ListOpenPagerIncidents(service)
+	if err != nil {
+		log.Printf("paging: %v", err)
+		return
+	}
+	for _, inc := range open {
+		pr := p.provider(inc.Provider)
+		if pr == nil {
+			continue
+		}
+		if err := pr.resolve(ctx, inc.DedupKey); err != nil {
+			log.Printf("paging: %s resolve %s: %v", pr.name(), service, err)
+			continue
+		}
+		if err := p.db.ResolvePagerIncident(inc.ID, p.now().UTC().Format(time.RFC3339), sessionID); err != nil {
+			log.Printf("paging: %v", err)
+		}
+	}
+}
+
+// resolveHealthy resolves the open incidents of services whose latest
+// check was made by session sessionID and found them healthy. Without it,
+// an incident paged for a critical event outside a Tier 3 remediation would
+// stay open, and suppress every later page for its service, until someone
+// ran a verification.
+func (p *Pager) resolveHealthy(ctx context.Context, sessionID int64) {
+	if s, err := p.db.GetSession(sessionID); err != nil || s == nil || s.Trigger == "drill" {
+		return
+	}
+	open, err := p.db.ListTriggeredPagerIncidents()
+	if err != nil {
+		log.Printf("paging: %v", err)
+		return
+	}
+	var services []string
+	seen := map[string]bool{}
+	for _, inc := range open {
+		if seen[inc.Service] || (inc.SessionID != nil && *inc.SessionID == sessionID) {
+			continue
+		}
+		seen[inc.Service] = true
+		services = append(services, inc.Service)
+	}
+	until := p.now().UTC().Format(time.RFC3339)
+	for _, svc := range services {
+		checks, err := p.db.QueryHealthChecks(svc, "", until, 1)
+		if err != nil {
+			log.Printf("paging: %v", err)
+			continue
+		}
+		if len(checks) == 1 && checks[0].Status == "healthy" && checks[0].SessionID != nil && *checks[0].SessionID == sessionID {
+			sid := sessionID
+			p.resolve(ctx, svc, &sid)
+		}
+	}
+}
+
+func (p *Pager) provider(name string) provider {
+	for _, pr := range p.providers {
+		if pr.name() == name {
+			return pr
+		}
+	}
+	return nil
+}
+
+func hasProvider(incidents []db.PagerIncident, name string) bool {
+	for _, inc := range incidents {
+		if inc.Provider == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupKey is the provider-side key for a service's incident, so repeated
+// triggers while one is open land on the same incident.
+func dedupKey(service string) string {
+	return "claude-ops/" + service
+}
+
+// sessionServices returns the services a session was scoped to.
+func sessionServices(s *db.Session) []string {
+	if s.Services == nil {
+		return nil
+	}
+	var out []string
+	for _, svc := range strings.Split(*s.Services, ",") {
+		if svc = strings.TrimSpace(svc); svc != "" {
+			out = append(out, svc)
+		}
+	}
+	return out
+}
+
+// pagerDuty sends PagerDuty Events API v2 events.
+type pagerDuty struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+func (pd *pagerDuty) name() string { return "pagerduty" }
+
+func (pd *pagerDuty) trigger(ctx context.Context, key string, inc incident) error {
+	details := map[string]any{"service": inc.service}
+	if inc.sessionID != nil {
+		details["session_id"] = *inc.sessionID
+	}
+	if inc.tier > 0 {
+		details["tier"] = inc.tier
+	}
+	return pd.send(ctx, map[string]any{
+		"routing_key":  pd.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    key,
+		"payload": map[string]any{
+			"summary":        truncate(inc.summary, 1024),
+			"source":         "claude-ops",
+			"severity":       "critical",
+			"component":      inc.service,
+			"custom_details": details,
+		},
+	})
+}
+
+func (pd *pagerDuty) resolve(ctx context.Context, key string) error {
+	return pd.send(ctx, map[string]any{
+		"routing_key":  pd.routingKey,
+		"event_action": "resolve",
+		"dedup_key":    key,
+	})
+}
+
+func (pd *pagerDuty) send(ctx context.Context, event map[string]any) error {
+	return postJSON(ctx, pd.client, pd.url, nil, event)
+}
+
+// opsgenie creates and closes Opsgenie alerts by alias.
+type opsgenie struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func (og *opsgenie) name() string { return "opsgenie" }
+
+func (og *opsgenie) trigger(ctx context.Context, key string, inc incident) error {
+	details := map[string]string{"service": inc.service}
+	if inc.sessionID != nil {
+		details["session_id"] = fmt.Sprint(*inc.sessionID)
+	}
+	return postJSON(ctx, og.client, og.url+"/v2/alerts", og.header(), map[string]any{
+		"message":  truncate(inc.summary, 130),
+		"alias":    key,
+		"source":   "claude-ops",
+		"priority": "P1",
+		"entity":   inc.service,
+		"details":  details,
+	})
+}
+
+func (og *opsgenie) resolve(ctx context.Context, key string) error {
+	u := og.url + "/v2/alerts/" + url.PathEscape(key) + "/close?identifierType=alias"
+	return postJSON(ctx, og.client, u, og.header(), map[string]any{"source": "claude-ops", "note": "Verified healthy by Claude Ops"})
+}
+
+func (og *opsgenie) header() http.Header {
+	return http.Header{"Authorization": {"GenieKey " + og.apiKey}}
+}
+
+// postJSON POSTs body as JSON and fails on a non-2xx response.
+func postJSON(ctx context.Context, client *http.Client, u string, header http.Header, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n runes, as providers cap summary length.
+func truncate(s string, n int) string {
+	return textutil.Truncate(s, n)
+}
@@ -0,0 +1,216 @@
+package paging
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// fakeProviders serves the PagerDuty and Opsgenie APIs and records calls.
+type fakeProviders struct {
+	mu    sync.Mutex
+	calls []string // "pagerduty:trigger:key", "opsgenie:close:key", ...
+}
+
+func (f *fakeProviders) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/enqueue", func(w http.ResponseWriter, r *http.Request) {
+		var ev struct {
+			RoutingKey  string `json:"routing_key"`
+			EventAction string `json:"event_action"`
+			DedupKey    string `json:"dedup_key"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil || ev.RoutingKey != "rk" {
+			t.Errorf("bad PagerDuty event %+v (%v)", ev, err)
+		}
+		f.record("pagerduty:" + ev.EventAction + ":" + ev.DedupKey)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("POST /v2/alerts", func(w http.ResponseWriter, r *http.Request) {
+		var alert struct {
+			Alias string `json:"alias"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&alert)
+		if r.Header.Get("Authorization") != "GenieKey gk" {
+			t.Errorf("missing Opsgenie key, got %q", r.Header.Get("Authorization"))
+		}
+		f.record("opsgenie:create:" + alert.Alias)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("POST /v2/alerts/{alias}/close", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("identifierType") != "alias" {
+			t.Errorf("expected close by alias, got %s", r.URL)
+		}
+		f.record("opsgenie:close:" + r.PathValue("alias"))
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
+
+func (f *fakeProviders) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func testPager(t *testing.T) (*Pager, *db.DB, *fakeProviders) {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	fake := &fakeProviders{}
+	srv := httptest.NewServer(fake.handler(t))
+	t.Cleanup(srv.Close)
+
+	p := FromConfig(&config.Config{PagerDutyRoutingKey: "rk", OpsgenieAPIKey: "gk", OpsgenieURL: srv.URL}, database)
+	if p == nil {
+		t.Fatal("expected a pager")
+	}
+	p.providers[0].(*pagerDuty).url = srv.URL + "/v2/enqueue"
+	return p, database, fake
+}
+
+// drain runs every queued job.
+func drain(p *Pager) {
+	for {
+		select {
+		case job := <-p.queue:
+			job(context.Background())
+		default:
+			return
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if FromConfig(&config.Config{}, nil) != nil {
+		t.Error("expected no pager without keys")
+	}
+	if FromConfig(&config.Config{PagerDutyRoutingKey: "rk", DryRun: true}, nil) != nil {
+		t.Error("expected no pager in dry-run mode")
+	}
+	p := FromConfig(&config.Config{OpsgenieAPIKey: "gk"}, nil)
+	if p == nil || len(p.providers) != 1 || p.providers[0].(*opsgenie).url != defaultOpsgenieURL {
+		t.Errorf("unexpected pager %+v", p)
+	}
+}
+
+func TestCriticalEventPagesOnceAndVerificationResolves(t *testing.T) {
+	p, database, fake := testPager(t)
+	sess := &db.Session{Tier: 3, Model: "opus", Status: "completed", StartedAt: "2026-10-01T00:00:00Z", Trigger: "escalation"}
+	sid, err := database.InsertSession(sess)
+	if err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	svc := "jellyfin"
+	p.OnEvent(&db.Event{SessionID: &sid, Level: "critical", Service: &svc, Message: "jellyfin is down"})
+	p.OnEvent(&db.Event{SessionID: &sid, Level: "critical", Service: &svc, Message: "still down"})
+	p.OnEvent(&db.Event{SessionID: &sid, Level: "warning", Service: &svc, Message: "slow"})
+	p.OnEvent(&db.Event{SessionID: &sid, Level: "critical", Message: "no service"})
+	drain(p)
+
+	want := []string{"pagerduty:trigger:claude-ops/jellyfin", "opsgenie:create:claude-ops/jellyfin"}
+	if len(fake.calls) != len(want) || fake.calls[0] != want[0] || fake.calls[1] != want[1] {
+		t.Fatalf("calls = %v, want %v", fake.calls, want)
+	}
+	incidents, err := database.ListSessionPagerIncidents(sid)
+	if err != nil || len(incidents) != 2 || incidents[0].Status != db.PagerTriggered {
+		t.Fatalf("unexpected incidents %+v (%v)", incidents, err)
+	}
+
+	verifyID := sid + 1
+	p.OnVerification(session.Verification{SessionID: verifyID, RemediationID: sid, Services: []string{"jellyfin", "caddy"}})
+	drain(p)
+	if open, _ := database.ListOpenPagerIncidents("jellyfin"); len(open) != 0 {
+		t.Errorf("expected incidents resolved, got %+v", open)
+	}
+	got := fake.calls[2:]
+	if len(got) != 2 || got[0] != "pagerduty:resolve:claude-ops/jellyfin" || got[1] != "opsgenie:close:claude-ops/jellyfin" {
+		t.Errorf("unexpected resolve calls %v", got)
+	}
+	incidents, _ = database.ListSessionPagerIncidents(sid)
+	if incidents[0].ResolvedSessionID == nil || *incidents[0].ResolvedSessionID != verifyID {
+		t.Errorf("expected incident resolved by session %d, got %+v", verifyID, incidents[0])
+	}
+}
+
+func TestHealthyRunResolvesSoLaterIncidentsPage(t *testing.T) {
+	p, database, fake := testPager(t)
+	first, err := database.InsertSession(&db.Session{Tier: 1, Model: "haiku", Status: "completed", StartedAt: "2026-10-01T00:00:00Z", Trigger: "scheduled"})
+	if err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	svc := "jellyfin"
+	p.OnEvent(&db.Event{SessionID: &first, Level: "critical", Service: &svc, Message: "jellyfin is down"})
+	drain(p)
+
+	later, err := database.InsertSession(&db.Session{Tier: 1, Model: "haiku", Status: "completed", StartedAt: "2026-10-01T01:00:00Z", Trigger: "scheduled"})
+	if err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	if _, err := database.InsertHealthCheck(&db.HealthCheck{SessionID: &later, Service: svc, CheckType: "service", Status: "healthy", CheckedAt: "2026-10-01T01:00:05Z"}); err != nil {
+		t.Fatalf("InsertHealthCheck: %v", err)
+	}
+	p.OnSessionEnd(&db.Session{ID: later, Tier: 1, Status: "completed"})
+	drain(p)
+	if open, _ := database.ListOpenPagerIncidents(svc); len(open) != 0 {
+		t.Fatalf("expected the healthy run to resolve the incidents, got %+v", open)
+	}
+
+	p.OnEvent(&db.Event{SessionID: &later, Level: "critical", Service: &svc, Message: "jellyfin is down again"})
+	drain(p)
+	want := []string{
+		"pagerduty:trigger:claude-ops/jellyfin", "opsgenie:create:claude-ops/jellyfin",
+		"pagerduty:resolve:claude-ops/jellyfin", "opsgenie:close:claude-ops/jellyfin",
+		"pagerduty:trigger:claude-ops/jellyfin", "opsgenie:create:claude-ops/jellyfin",
+	}
+	if strings.Join(fake.calls, " ") != strings.Join(want, " ") {
+		t.Errorf("calls = %v, want %v", fake.calls, want)
+	}
+}
+
+func TestFailedRemediationPages(t *testing.T) {
+	p, database, fake := testPager(t)
+	services := "jellyfin,caddy"
+	p.OnSessionEnd(&db.Session{ID: 1, Tier: 3, Status: "failed", Services: &services})
+	p.OnSessionEnd(&db.Session{ID: 2, Tier: 2, Status: "failed", Services: &services})
+	p.OnSessionEnd(&db.Session{ID: 3, Tier: 3, Status: "completed", Services: &services})
+	p.OnVerification(session.Verification{SessionID: 4, RemediationID: 3, Services: []string{"postgres"}, Unhealthy: []string{"postgres"}})
+	drain(p)
+
+	if len(fake.calls) != 6 {
+		t.Fatalf("expected jellyfin, caddy, and postgres paged with both providers, got %v", fake.calls)
+	}
+	for _, svc := range []string{"jellyfin", "caddy", "postgres"} {
+		if open, _ := database.ListOpenPagerIncidents(svc); len(open) != 2 {
+			t.Errorf("expected 2 open incidents for %s, got %+v", svc, open)
+		}
+	}
+}
+
+func TestDrillNeverPages(t *testing.T) {
+	p, database, fake := testPager(t)
+	sid, err := database.InsertSession(&db.Session{Tier: 1, Model: "haiku", Status: "running", StartedAt: "2026-10-01T00:00:00Z", Trigger: "drill"})
+	if err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	svc := "claudeops-canary"
+	p.OnEvent(&db.Event{SessionID: &sid, Level: "critical", Service: &svc, Message: "canary down"})
+	drain(p)
+	if len(fake.calls) != 0 {
+		t.Errorf("expected no pages for a drill, got %v", fake.calls)
+	}
+}
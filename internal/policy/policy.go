@@ -0,0 +1,334 @@
+// Package policy evaluates operator guardrails written as CEL expressions.
+// Rules run at two points in a session: before the supervisor escalates to
+// the next tier, and at a cooldown action, either reported by the agent
+// before it is recorded or requested of the API before it is taken. A matching rule can deny the step or, for escalations, cap the tier
+// it goes to.
+//
+// Rules live in a YAML file:
+//
+//	rules:
+//	  - name: no-overnight-remediation
+//	    when: escalation
+//	    expr: escalation.to_tier == 3 && (hour < 7 || hour >= 22)
+//	    action: cap
+//	    max_tier: 2
+//	    message: Full remediation waits for business hours
+//	  - name: daily-budget
+//	    when: escalation
+//	    expr: budget.cost_24h_usd > 20.0
+//	    action: deny
+//
+// Expressions see these variables:
+//
+//	escalation  from_tier, to_tier, skip, trigger, context, services (escalation rules only)
+//	cooldown    service, action, tier, success, recent_count (cooldown rules only)
+//	services    service catalog: name -> {status, last_check, check_count}
+//	budget      chain_cost_usd, cost_24h_usd
+//	now         current time (timestamp)
+//	hour        local hour of day, 0-23
+//	weekday     local day of week, 0 (Sunday) to 6
+//
+// A rule whose expression fails to evaluate (e.g. indexing a service missing
+// from the catalog) is reported and skipped, so a broken rule never blocks
+// the pipeline on its own.
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"go.yaml.in/yaml/v3"
+)
+
+// Points at which rules are evaluated.
+const (
+	PointEscalation = "escalation"
+	PointCooldown   = "cooldown"
+)
+
+// Rule actions.
+const (
+	ActionDeny = "deny"
+	ActionCap  = "cap"
+)
+
+// Rule is one guardrail from the policy file.
+type Rule struct {
+	Name    string `yaml:"name"`
+	When    string `yaml:"when"`
+	Expr    string `yaml:"expr"`
+	Action  string `yaml:"action"`
+	MaxTier int    `yaml:"max_tier"` // highest tier allowed by a cap rule
+	Message string `yaml:"message"`
+
+	prg cel.Program
+}
+
+// Engine holds the compiled rules. A nil *Engine allows everything.
+type Engine struct {
+	rules []Rule
+}
+
+// Load reads and compiles the policy file at path. An empty path returns a
+// nil Engine.
+func Load(path string) (*Engine, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy file: %w", err)
+	}
+	e, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("policy file %s: %w", path, err)
+	}
+	return e, nil
+}
+
+// Parse compiles a policy document.
+func Parse(data []byte) (*Engine, error) {
+	var doc struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i := range doc.Rules {
+		r := &doc.Rules[i]
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("rule %s: duplicate name", r.Name)
+		}
+		seen[r.Name] = true
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		ast, iss := env.Compile(r.Expr)
+		if iss.Err() != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, iss.Err())
+		}
+		if !ast.OutputType().IsExactType(cel.BoolType) {
+			return nil, fmt.Errorf("rule %s: expression must be a bool, got %s", r.Name, ast.OutputType())
+		}
+		if r.prg, err = env.Program(ast); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+	}
+	return &Engine{rules: doc.Rules}, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Expr == "" {
+		return fmt.Errorf("expr is required")
+	}
+	switch r.When {
+	case PointEscalation, PointCooldown:
+	default:
+		return fmt.Errorf("when must be %q or %q, got %q", PointEscalation, PointCooldown, r.When)
+	}
+	switch r.Action {
+	case ActionDeny:
+	case ActionCap:
+		if r.When != PointEscalation {
+			return fmt.Errorf("cap only applies to escalation rules")
+		}
+		if r.MaxTier < 1 {
+			return fmt.Errorf("cap requires max_tier of at least 1")
+		}
+	default:
+		return fmt.Errorf("action must be %q or %q, got %q", ActionDeny, ActionCap, r.Action)
+	}
+	return nil
+}
+
+func newEnv() (*cel.Env, error) {
+	obj := cel.MapType(cel.StringType, cel.DynType)
+	env, err := cel.NewEnv(
+		cel.Variable("escalation", obj),
+		cel.Variable("cooldown", obj),
+		cel.Variable("services", cel.MapType(cel.StringType, obj)),
+		cel.Variable("budget", obj),
+		cel.Variable("now", cel.TimestampType),
+		cel.Variable("hour", cel.IntType),
+		cel.Variable("weekday", cel.IntType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create CEL environment: %w", err)
+	}
+	return env, nil
+}
+
+// Escalation is a proposed step up the escalation chain.
+type Escalation struct {
+	FromTier int
+	ToTier   int
+	Trigger  string
+	Context  string
+	Services []string
+}
+
+// Cooldown is a remediation action about to be recorded.
+type Cooldown struct {
+	Service     string
+	Action      string
+	Tier        int
+	Success     bool
+	RecentCount int // actions of this kind on the service in the last 24 hours
+}
+
+// Service is the latest health check for a service in the catalog.
+type Service struct {
+	Status     string
+	LastCheck  string
+	CheckCount int
+}
+
+// Budget is the spend so far.
+type Budget struct {
+	ChainCostUSD float64 // the escalation chain the session belongs to
+	Cost24hUSD   float64 // all sessions started in the last 24 hours
+}
+
+// Input is the state rules are evaluated over. Escalation is set for
+// escalation rules and Cooldown for cooldown rules.
+type Input struct {
+	Escalation *Escalation
+	Cooldown   *Cooldown
+	Services   map[string]Service
+	Budget     Budget
+	Now        time.Time
+}
+
+// Result is the outcome of one rule.
+type Result struct {
+	Rule    string
+	Matched bool
+	Action  string // the rule's action if it matched, "none" if not, "error" if evaluation failed
+	Detail  string
+}
+
+// Decision is the combined outcome of the rules at a point.
+type Decision struct {
+	Deny    bool
+	MaxTier int // lowest max_tier among matching cap rules, 0 if none matched
+	// Reason names the rule that decided, with its message.
+	Reason  string
+	Results []Result
+}
+
+// Evaluate runs the rules for point over in. Every matching deny rule and
+// the strictest matching cap apply; the first deny gives the reason.
+func (e *Engine) Evaluate(point string, in Input) Decision {
+	var d Decision
+	if e == nil {
+		return d
+	}
+	vars := in.activation()
+	for _, r := range e.rules {
+		if r.When != point {
+			continue
+		}
+		res := Result{Rule: r.Name, Action: "none"}
+		out, _, err := r.prg.Eval(vars)
+		switch {
+		case err != nil:
+			res.Action = "error"
+			res.Detail = err.Error()
+		case out.Value() == true:
+			res.Matched = true
+			res.Action = r.Action
+			res.Detail = r.describe()
+			switch r.Action {
+			case ActionDeny:
+				if !d.Deny {
+					d.Deny = true
+					d.Reason = res.Detail
+				}
+			case ActionCap:
+				if d.MaxTier == 0 || r.MaxTier < d.MaxTier {
+					d.MaxTier = r.MaxTier
+					if !d.Deny {
+						d.Reason = res.Detail
+					}
+				}
+			}
+		}
+		d.Results = append(d.Results, res)
+	}
+	return d
+}
+
+func (r Rule) describe() string {
+	desc := r.Name
+	if r.Action == ActionCap {
+		desc = fmt.Sprintf("%s (max tier %d)", desc, r.MaxTier)
+	}
+	if r.Message != "" {
+		desc += ": " + r.Message
+	}
+	return desc
+}
+
+// activation converts the input to CEL variables. Numbers are int64 and
+// lists are non-nil so expressions see CEL's own types.
+func (in Input) activation() map[string]any {
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	services := make(map[string]any, len(in.Services))
+	for name, s := range in.Services {
+		services[name] = map[string]any{
+			"status":      s.Status,
+			"last_check":  s.LastCheck,
+			"check_count": int64(s.CheckCount),
+		}
+	}
+	vars := map[string]any{
+		"escalation": map[string]any{},
+		"cooldown":   map[string]any{},
+		"services":   services,
+		"budget": map[string]any{
+			"chain_cost_usd": in.Budget.ChainCostUSD,
+			"cost_24h_usd":   in.Budget.Cost24hUSD,
+		},
+		"now":     now.UTC(),
+		"hour":    int64(now.Local().Hour()),
+		"weekday": int64(now.Local().Weekday()),
+	}
+	if esc := in.Escalation; esc != nil {
+		affected := append([]string{}, esc.Services...)
+		vars["escalation"] = map[string]any{
+			"from_tier": int64(esc.FromTier),
+			"to_tier":   int64(esc.ToTier),
+			"skip":      esc.ToTier > esc.FromTier+1,
+			"trigger":   esc.Trigger,
+			"context":   esc.Context,
+			"services":  affected,
+		}
+	}
+	if cd := in.Cooldown; cd != nil {
+		vars["cooldown"] = map[string]any{
+			"service":      cd.Service,
+			"action":       cd.Action,
+			"tier":         int64(cd.Tier),
+			"success":      cd.Success,
+			"recent_count": int64(cd.RecentCount),
+		}
+	}
+	return vars
+}
@@ -0,0 +1,142 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPolicy = `
+rules:
+  - name: overnight
+    when: escalation
+    expr: escalation.to_tier == 3 && (hour < 7 || hour >= 22)
+    action: cap
+    max_tier: 2
+    message: Full remediation waits for business hours
+  - name: budget
+    when: escalation
+    expr: budget.cost_24h_usd > 20.0
+    action: deny
+  - name: catalog
+    when: escalation
+    expr: services["jellyfin"].status == "down" && "jellyfin" in escalation.services
+    action: cap
+    max_tier: 1
+  - name: restart-loop
+    when: cooldown
+    expr: cooldown.action == "restart" && cooldown.recent_count >= 2
+    action: deny
+`
+
+func TestEvaluateEscalation(t *testing.T) {
+	e, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	night := time.Date(2026, 10, 1, 23, 0, 0, 0, time.Local)
+	noon := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	esc := &Escalation{FromTier: 2, ToTier: 3, Trigger: "escalation", Services: []string{"jellyfin"}}
+	jellyfinUp := map[string]Service{"jellyfin": {Status: "healthy"}}
+
+	d := e.Evaluate(PointEscalation, Input{Escalation: esc, Services: jellyfinUp, Now: night})
+	if d.Deny || d.MaxTier != 2 || !strings.Contains(d.Reason, "business hours") {
+		t.Errorf("overnight: %+v", d)
+	}
+	if len(d.Results) != 3 {
+		t.Errorf("expected only escalation rules to run, got %+v", d.Results)
+	}
+
+	d = e.Evaluate(PointEscalation, Input{Escalation: esc, Services: jellyfinUp, Now: noon})
+	if d.Deny || d.MaxTier != 0 {
+		t.Errorf("noon: %+v", d)
+	}
+
+	d = e.Evaluate(PointEscalation, Input{Escalation: esc, Services: jellyfinUp, Budget: Budget{Cost24hUSD: 25}, Now: night})
+	if !d.Deny || !strings.HasPrefix(d.Reason, "budget") || d.MaxTier != 2 {
+		t.Errorf("over budget: %+v", d)
+	}
+
+	d = e.Evaluate(PointEscalation, Input{Escalation: esc, Services: map[string]Service{"jellyfin": {Status: "down"}}, Now: night})
+	if d.MaxTier != 1 {
+		t.Errorf("strictest cap should win: %+v", d)
+	}
+}
+
+func TestEvaluateTierSkip(t *testing.T) {
+	e, err := Parse([]byte("rules:\n  - {name: investigate-first, when: escalation, expr: escalation.skip == true, action: cap, max_tier: 2}"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	if d := e.Evaluate(PointEscalation, Input{Escalation: &Escalation{FromTier: 1, ToTier: 3}, Now: now}); d.MaxTier != 2 {
+		t.Errorf("1 to 3: %+v", d)
+	}
+	if d := e.Evaluate(PointEscalation, Input{Escalation: &Escalation{FromTier: 2, ToTier: 3}, Now: now}); d.MaxTier != 0 {
+		t.Errorf("2 to 3 is not a skip: %+v", d)
+	}
+}
+
+func TestEvaluateErrorSkipsRule(t *testing.T) {
+	e, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// jellyfin is missing from the catalog, so the catalog rule cannot evaluate.
+	d := e.Evaluate(PointEscalation, Input{Escalation: &Escalation{FromTier: 1, ToTier: 2, Services: []string{"jellyfin"}}, Now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)})
+	if d.Deny || d.MaxTier != 0 {
+		t.Errorf("a failing rule must not decide: %+v", d)
+	}
+	var failed bool
+	for _, r := range d.Results {
+		if r.Rule == "catalog" && r.Action == "error" && r.Detail != "" {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Errorf("expected the catalog rule to report an error: %+v", d.Results)
+	}
+}
+
+func TestEvaluateCooldown(t *testing.T) {
+	e, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	d := e.Evaluate(PointCooldown, Input{Cooldown: &Cooldown{Service: "jellyfin", Action: "restart", RecentCount: 2}})
+	if !d.Deny || len(d.Results) != 1 {
+		t.Errorf("restart loop: %+v", d)
+	}
+	d = e.Evaluate(PointCooldown, Input{Cooldown: &Cooldown{Service: "jellyfin", Action: "restart", RecentCount: 1}})
+	if d.Deny {
+		t.Errorf("first restart: %+v", d)
+	}
+}
+
+func TestNilEngineAllows(t *testing.T) {
+	var e *Engine
+	if d := e.Evaluate(PointEscalation, Input{}); d.Deny || d.MaxTier != 0 || len(d.Results) != 0 {
+		t.Errorf("nil engine: %+v", d)
+	}
+	if e, err := Load(""); e != nil || err != nil {
+		t.Errorf("Load(\"\") = %v, %v", e, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"syntax":       "rules:\n  - {name: a, when: escalation, expr: 'escalation.to_tier ==', action: deny}",
+		"not bool":     "rules:\n  - {name: a, when: escalation, expr: 'escalation.to_tier', action: deny}",
+		"unknown var":  "rules:\n  - {name: a, when: escalation, expr: 'tier == 3', action: deny}",
+		"bad point":    "rules:\n  - {name: a, when: always, expr: 'true', action: deny}",
+		"bad action":   "rules:\n  - {name: a, when: escalation, expr: 'true', action: warn}",
+		"cap cooldown": "rules:\n  - {name: a, when: cooldown, expr: 'true', action: cap, max_tier: 1}",
+		"cap no tier":  "rules:\n  - {name: a, when: escalation, expr: 'true', action: cap}",
+		"duplicate":    "rules:\n  - {name: a, when: escalation, expr: 'true', action: deny}\n  - {name: a, when: cooldown, expr: 'true', action: deny}",
+		"unknown key":  "rules:\n  - {name: a, when: escalation, expr: 'true', action: deny, tier: 2}",
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
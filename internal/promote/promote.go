@@ -0,0 +1,297 @@
+// Package promote suggests memories from recurring events. When a
+// service's warning or critical events keep repeating the same message
+// ("traefik: 502 from upstream sonarr"), that pattern is usually something
+// the agent should know up front. Each recurring pattern is queued once as
+// an unverified memory in the memory review queue, where an operator can
+// edit, approve, or reject it.
+package promote
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+const (
+	// maxEvents bounds the events considered per pass.
+	maxEvents = 5000
+
+	// similarity is the share of words two messages must have in common
+	// (Jaccard index of their normalized words) to be the same pattern.
+	similarity = 0.6
+
+	// category is the category suggested memories are filed under.
+	category = "behavior"
+
+	// confidence is the starting confidence of a suggested memory, below
+	// the 0.7 of memories the agent reports itself.
+	confidence = 0.5
+)
+
+const phraseSystemPrompt = `You write operational memories for an on-call agent that monitors a homelab. You are given warning and critical events that keep recurring for one service. Write one sentence stating the recurring behavior as a general fact the agent should know before investigating, for example "traefik returns 502 for about 60s after a backend restarts". Do not speculate beyond the events. Output only the sentence.`
+
+// PhraseFunc writes a memory observation for a recurring pattern.
+type PhraseFunc func(ctx context.Context, model, service string, events []db.Event) (string, error)
+
+// Suggester looks for recurring event patterns and queues them as memories.
+type Suggester struct {
+	db       *db.DB
+	interval time.Duration
+	minCount int
+	window   time.Duration
+	model    string // summary model; empty describes patterns without one
+	phrase   PhraseFunc
+	now      func() time.Time
+}
+
+// FromConfig builds a Suggester from CLAUDEOPS_MEMORY_SUGGEST_*. Returns nil
+// when suggestions are disabled.
+func FromConfig(cfg *config.Config, database *db.DB) *Suggester {
+	if cfg.MemorySuggestInterval <= 0 {
+		return nil
+	}
+	s := New(database, cfg.MemorySuggestCount, time.Duration(cfg.MemorySuggestDays)*24*time.Hour)
+	s.interval = time.Duration(cfg.MemorySuggestInterval) * time.Hour
+	s.model = cfg.SummaryModel
+	return s
+}
+
+// New creates a Suggester for patterns of at least minCount events within
+// window.
+func New(database *db.DB, minCount int, window time.Duration) *Suggester {
+	if minCount < 2 {
+		minCount = 2
+	}
+	if window <= 0 {
+		window = 7 * 24 * time.Hour
+	}
+	return &Suggester{
+		db:       database,
+		interval: 24 * time.Hour,
+		minCount: minCount,
+		window:   window,
+		phrase:   phraseObservation,
+		now:      time.Now,
+	}
+}
+
+// Run suggests memories immediately and then on every interval until ctx
+// is cancelled.
+func (s *Suggester) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.SuggestAll(ctx); err != nil {
+			log.Printf("promote: %v", err)
+		} else if n > 0 {
+			log.Printf("promote: suggested %d memory(s) from recurring events", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pattern is a group of a service's events with similar messages, newest
+// first.
+type pattern struct {
+	words  map[string]bool // of the newest event's message
+	events []db.Event
+}
+
+// SuggestAll queues a memory for each recurring pattern not suggested
+// before and returns how many were queued.
+func (s *Suggester) SuggestAll(ctx context.Context) (int, error) {
+	since := s.now().UTC().Add(-s.window).Format(time.RFC3339)
+	events, err := s.db.ListEvents(maxEvents, 0, db.EventFilter{Since: since})
+	if err != nil {
+		return 0, err
+	}
+	byService := make(map[string][]*pattern)
+	for _, e := range events {
+		if e.Service == nil || (e.Level != "warning" && e.Level != "critical") {
+			continue
+		}
+		words := normalize(e.Message)
+		if len(words) == 0 {
+			continue
+		}
+		svc := *e.Service
+		var match *pattern
+		for _, p := range byService[svc] {
+			if jaccard(words, p.words) >= similarity {
+				match = p
+				break
+			}
+		}
+		if match == nil {
+			match = &pattern{words: words}
+			byService[svc] = append(byService[svc], match)
+		}
+		match.events = append(match.events, e)
+	}
+
+	services := make([]string, 0, len(byService))
+	for svc := range byService {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+
+	n := 0
+	for _, svc := range services {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		previous, err := s.db.ListMemorySuggestions(svc)
+		if err != nil {
+			return n, err
+		}
+		for _, p := range byService[svc] {
+			if len(p.events) < s.minCount || suggested(p, previous) {
+				continue
+			}
+			if err := s.suggest(ctx, svc, p); err != nil {
+				log.Printf("promote: %s: %v", svc, err)
+				continue
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// suggested reports whether p matches a pattern suggested before.
+func suggested(p *pattern, previous []db.MemorySuggestion) bool {
+	for _, sg := range previous {
+		if jaccard(p.words, wordSet(strings.Fields(sg.Pattern))) >= similarity {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest queues p as an unverified memory for service.
+func (s *Suggester) suggest(ctx context.Context, service string, p *pattern) error {
+	newest, oldest := p.events[0], p.events[len(p.events)-1]
+	observation := describe(p.events, s.window)
+	if s.model != "" {
+		if text, err := s.phrase(ctx, s.model, service, p.events); err != nil {
+			log.Printf("promote: %s: phrase with %s: %v (using the event summary)", service, s.model, err)
+		} else if text = strings.TrimSpace(text); text != "" {
+			observation = text
+		}
+	}
+	words := make([]string, 0, len(p.words))
+	for w := range p.words {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+
+	now := s.now().UTC().Format(time.RFC3339)
+	svc := service
+	_, err := s.db.InsertMemorySuggestion(&db.Memory{
+		Service:     &svc,
+		Category:    category,
+		Observation: observation,
+		Confidence:  confidence,
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, &db.MemorySuggestion{
+		Service:    service,
+		Pattern:    strings.Join(words, " "),
+		EventCount: len(p.events),
+		FirstSeen:  oldest.CreatedAt,
+		LastSeen:   newest.CreatedAt,
+		CreatedAt:  now,
+	})
+	return err
+}
+
+// describe summarizes a pattern without a model: its newest message and
+// how often it recurred.
+func describe(events []db.Event, window time.Duration) string {
+	return fmt.Sprintf("Recurring %s (%d times in %d days): %s",
+		events[0].Level, len(events), int(window.Hours()/24), events[0].Message)
+}
+
+// normalize returns the words of a message, lowercased, with numbers
+// replaced by "#" so that timings, counts, and IDs do not split a pattern.
+func normalize(msg string) map[string]bool {
+	fields := strings.FieldsFunc(strings.ToLower(msg), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		if strings.ContainsFunc(f, unicode.IsDigit) {
+			fields[i] = "#"
+		}
+	}
+	return wordSet(fields)
+}
+
+func wordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// jaccard is the share of words a and b have in common.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// phraseObservation asks the summary model to state a pattern as a memory.
+func phraseObservation(ctx context.Context, model, service string, events []db.Event) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Service: %s\n\nEvents (newest first):\n", service)
+	for i, e := range events {
+		if i == 20 {
+			fmt.Fprintf(&b, "- … and %d more\n", len(events)-i)
+			break
+		}
+		fmt.Fprintf(&b, "- %s [%s] %s\n", e.CreatedAt, e.Level, e.Message)
+	}
+
+	client := anthropic.NewClient()
+	msg, err := client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     anthropic.Model(model),
+		MaxTokens: 200,
+		System: []anthropic.TextBlockParam{
+			{Text: phraseSystemPrompt},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(b.String())),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("anthropic messages: %w", err)
+	}
+	for _, block := range msg.Content {
+		if block.Type == "text" {
+			return block.Text, nil
+		}
+	}
+	return "", fmt.Errorf("no text block in response")
+}
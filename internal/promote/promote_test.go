@@ -0,0 +1,160 @@
+package promote
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+var testNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func testSuggester(t *testing.T) (*Suggester, *db.DB) {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	s := New(database, 3, 7*24*time.Hour)
+	s.now = func() time.Time { return testNow }
+	return s, database
+}
+
+func insertEvent(t *testing.T, database *db.DB, service, level, message string, age time.Duration) {
+	t.Helper()
+	e := &db.Event{Level: level, Message: message, CreatedAt: testNow.Add(-age).Format(time.RFC3339)}
+	if service != "" {
+		e.Service = &service
+	}
+	if _, err := database.InsertEvent(e); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+}
+
+func pendingMemories(t *testing.T, database *db.DB) []db.Memory {
+	t.Helper()
+	unverified := db.MemoryUnverified
+	mems, err := database.ListMemories(nil, nil, &unverified, 100, 0)
+	if err != nil {
+		t.Fatalf("ListMemories: %v", err)
+	}
+	return mems
+}
+
+func TestFromConfig(t *testing.T) {
+	if s := FromConfig(&config.Config{MemorySuggestCount: 3, MemorySuggestDays: 7}, nil); s != nil {
+		t.Error("expected nil suggester when interval is 0")
+	}
+	s := FromConfig(&config.Config{MemorySuggestInterval: 12, MemorySuggestCount: 4, MemorySuggestDays: 3, SummaryModel: "haiku"}, nil)
+	if s == nil || s.interval != 12*time.Hour || s.minCount != 4 || s.window != 72*time.Hour || s.model != "haiku" {
+		t.Fatalf("unexpected suggester %+v", s)
+	}
+}
+
+func TestSuggestAll_RecurringPattern(t *testing.T) {
+	s, database := testSuggester(t)
+	insertEvent(t, database, "traefik", "warning", "traefik returned 502 for sonarr for 58s after restart", 50*time.Hour)
+	insertEvent(t, database, "traefik", "warning", "traefik returned 502 for sonarr for 61s after restart", 26*time.Hour)
+	insertEvent(t, database, "traefik", "critical", "traefik returned 502 for sonarr for 64s after restart", 2*time.Hour)
+	// Too few, unrelated, informational, or outside the window.
+	insertEvent(t, database, "traefik", "warning", "certificate for example.com expires in 5 days", time.Hour)
+	insertEvent(t, database, "jellyfin", "info", "jellyfin restarted", time.Hour)
+	insertEvent(t, database, "jellyfin", "info", "jellyfin restarted", 2*time.Hour)
+	insertEvent(t, database, "jellyfin", "info", "jellyfin restarted", 3*time.Hour)
+	insertEvent(t, database, "sonarr", "warning", "sonarr health check failed", 8*24*time.Hour)
+	insertEvent(t, database, "sonarr", "warning", "sonarr health check failed", 9*24*time.Hour)
+	insertEvent(t, database, "sonarr", "warning", "sonarr health check failed", time.Hour)
+
+	n, err := s.SuggestAll(context.Background())
+	if err != nil {
+		t.Fatalf("SuggestAll: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("suggested %d, want 1", n)
+	}
+	mems := pendingMemories(t, database)
+	if len(mems) != 1 {
+		t.Fatalf("pending memories = %d, want 1", len(mems))
+	}
+	m := mems[0]
+	if m.Service == nil || *m.Service != "traefik" || m.Category != "behavior" || !m.Active {
+		t.Errorf("unexpected memory %+v", m)
+	}
+	if !strings.Contains(m.Observation, "3 times in 7 days") || !strings.Contains(m.Observation, "64s after restart") {
+		t.Errorf("observation = %q", m.Observation)
+	}
+
+	counts, err := database.SuggestionEventCounts([]int64{m.ID})
+	if err != nil {
+		t.Fatalf("SuggestionEventCounts: %v", err)
+	}
+	if counts[m.ID] != 3 {
+		t.Errorf("event count = %d, want 3", counts[m.ID])
+	}
+
+	// The same pattern is not suggested again, even after more events.
+	insertEvent(t, database, "traefik", "warning", "traefik returned 502 for sonarr for 59s after restart", time.Minute)
+	if n, err := s.SuggestAll(context.Background()); err != nil || n != 0 {
+		t.Fatalf("second SuggestAll = %d, %v; want 0, nil", n, err)
+	}
+}
+
+func TestSuggestAll_Phrase(t *testing.T) {
+	s, database := testSuggester(t)
+	for i := 0; i < 3; i++ {
+		insertEvent(t, database, "caddy", "warning", "caddy reload failed", time.Duration(i+1)*time.Hour)
+	}
+	s.model = "test-model"
+	var got []db.Event
+	s.phrase = func(_ context.Context, model, service string, events []db.Event) (string, error) {
+		got = events
+		return "  caddy reloads fail after config edits\n", nil
+	}
+	if _, err := s.SuggestAll(context.Background()); err != nil {
+		t.Fatalf("SuggestAll: %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("phrase got %d events, want 3", len(got))
+	}
+	mems := pendingMemories(t, database)
+	if len(mems) != 1 || mems[0].Observation != "caddy reloads fail after config edits" {
+		t.Fatalf("unexpected memories %+v", mems)
+	}
+}
+
+func TestSuggestAll_PhraseErrorFallsBack(t *testing.T) {
+	s, database := testSuggester(t)
+	for i := 0; i < 3; i++ {
+		insertEvent(t, database, "caddy", "warning", "caddy reload failed", time.Duration(i+1)*time.Hour)
+	}
+	s.model = "test-model"
+	s.phrase = func(context.Context, string, string, []db.Event) (string, error) {
+		return "", errors.New("rate limited")
+	}
+	if n, err := s.SuggestAll(context.Background()); err != nil || n != 1 {
+		t.Fatalf("SuggestAll = %d, %v; want 1, nil", n, err)
+	}
+	mems := pendingMemories(t, database)
+	if len(mems) != 1 || !strings.HasPrefix(mems[0].Observation, "Recurring warning") {
+		t.Fatalf("unexpected memories %+v", mems)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	a := normalize("Backend 10.0.0.5:8080 timed out after 30s")
+	b := normalize("backend 10.0.0.7:8080 timed out after 45s")
+	if got := jaccard(a, b); got != 1 {
+		t.Errorf("jaccard of messages differing only in numbers = %v, want 1", got)
+	}
+	c := normalize("disk usage above 90 percent")
+	if got := jaccard(a, c); got >= similarity {
+		t.Errorf("jaccard of unrelated messages = %v, want < %v", got, similarity)
+	}
+}
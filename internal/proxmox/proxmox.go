@@ -0,0 +1,286 @@
+// Package proxmox is a minimal client for the Proxmox VE REST API. It lists
+// the VM and LXC guests running on a cluster (the hypervisor inventory that
+// feeds the service catalog and Tier 1 context) and performs the small set of
+// guest power actions the agent is allowed to request: start, stop, reboot.
+//
+// Authentication uses a Proxmox API token ("user@realm!tokenid" plus its
+// secret), sent as the PVEAPIToken Authorization header. Tokens should be
+// created with the narrowest privileges that allow VM.Audit and VM.PowerMgmt.
+package proxmox
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+const (
+	// defaultTimeout bounds each API request so an unreachable hypervisor
+	// cannot stall session startup.
+	defaultTimeout = 10 * time.Second
+
+	// maxBodyBytes bounds how much of a response we read.
+	maxBodyBytes = 4 << 20 // 4 MiB
+)
+
+// Guest is a single VM or LXC container as reported by /cluster/resources.
+type Guest struct {
+	VMID     int     `json:"vmid"`
+	Name     string  `json:"name"`
+	Node     string  `json:"node"`
+	Type     string  `json:"type"`   // "qemu" or "lxc"
+	Status   string  `json:"status"` // "running", "stopped", "paused", ...
+	Uptime   int64   `json:"uptime"`
+	CPU      float64 `json:"cpu"`
+	Mem      int64   `json:"mem"`
+	MaxMem   int64   `json:"maxmem"`
+	Template int     `json:"template"`
+	Tags     string  `json:"tags"` // semicolon-separated
+}
+
+// HasTag reports whether the guest carries tag (case-insensitively).
+func (g Guest) HasTag(tag string) bool {
+	for _, t := range strings.FieldsFunc(g.Tags, func(r rune) bool { return r == ';' || r == ',' || r == ' ' }) {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceName returns the name used for this guest in the service catalog.
+// Guests without a name fall back to "vm-<vmid>" / "ct-<vmid>".
+func (g Guest) ServiceName() string {
+	if g.Name != "" {
+		return g.Name
+	}
+	if g.Type == "lxc" {
+		return fmt.Sprintf("ct-%d", g.VMID)
+	}
+	return fmt.Sprintf("vm-%d", g.VMID)
+}
+
+// HealthStatus maps the Proxmox guest status onto the health check vocabulary
+// (healthy, degraded, down) used by the health_checks table.
+func (g Guest) HealthStatus() string {
+	switch g.Status {
+	case "running":
+		return "healthy"
+	case "paused", "suspended":
+		return "degraded"
+	default:
+		return "down"
+	}
+}
+
+// Actions lists the guest power actions the client will perform.
+var Actions = []string{"start", "stop", "reboot"}
+
+// ValidAction reports whether action is one of Actions.
+func ValidAction(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// CooldownActionType returns the cooldown_actions.action_type recorded for a
+// guest power action, e.g. "vm_reboot".
+func CooldownActionType(action string) string {
+	return "vm_" + action
+}
+
+// Limit is the maximum number of times an action may be performed on a single
+// guest within Window.
+type Limit struct {
+	Max    int
+	Window time.Duration
+}
+
+// Limits are the cooldown policy for guest power actions. They are separate
+// from (and stricter than) the container restart/redeployment limits because
+// a single VM typically hosts several services.
+var Limits = map[string]Limit{
+	"start":  {Max: 2, Window: 4 * time.Hour},
+	"reboot": {Max: 1, Window: 4 * time.Hour},
+	"stop":   {Max: 1, Window: 24 * time.Hour},
+}
+
+// Client talks to a single Proxmox VE API endpoint.
+type Client struct {
+	baseURL string
+	tokenID string
+	secret  string
+	client  *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient overrides the HTTP client (primarily for tests).
+func WithHTTPClient(c *http.Client) Option {
+	return func(p *Client) {
+		if c != nil {
+			p.client = c
+		}
+	}
+}
+
+// WithInsecureTLS disables certificate verification. Proxmox installs ship
+// with a self-signed certificate, so homelab deployments commonly need this.
+func WithInsecureTLS(insecure bool) Option {
+	return func(p *Client) {
+		if !insecure {
+			return
+		}
+		p.client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // opt-in for self-signed Proxmox certs
+		}
+	}
+}
+
+// New constructs a Client for baseURL (e.g. "https://pve.local:8006").
+// Returns nil if baseURL is empty so callers can treat a nil *Client as
+// "integration disabled".
+func New(baseURL, tokenID, secret string, opts ...Option) *Client {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil
+	}
+	c := &Client{
+		baseURL: baseURL,
+		tokenID: tokenID,
+		secret:  secret,
+		client:  &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// FromConfig constructs a Client from the CLAUDEOPS_PROXMOX_* settings.
+// Returns nil when ProxmoxURL is not set.
+func FromConfig(cfg *config.Config) *Client {
+	return New(cfg.ProxmoxURL, cfg.ProxmoxTokenID, cfg.ProxmoxTokenSecret, WithInsecureTLS(cfg.ProxmoxInsecureTLS))
+}
+
+// Inventory returns all non-template VM and LXC guests in the cluster,
+// sorted by node then VMID.
+func (c *Client) Inventory(ctx context.Context) ([]Guest, error) {
+	var guests []Guest
+	if err := c.do(ctx, http.MethodGet, "/cluster/resources?type=vm", &guests); err != nil {
+		return nil, fmt.Errorf("list guests: %w", err)
+	}
+	out := guests[:0]
+	for _, g := range guests {
+		if g.Template == 1 {
+			continue
+		}
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Node != out[j].Node {
+			return out[i].Node < out[j].Node
+		}
+		return out[i].VMID < out[j].VMID
+	})
+	return out, nil
+}
+
+// FindGuest returns the guest with the given VMID, or nil if it does not exist.
+func (c *Client) FindGuest(ctx context.Context, vmid int) (*Guest, error) {
+	guests, err := c.Inventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range guests {
+		if guests[i].VMID == vmid {
+			return &guests[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Action performs a power action on a guest and returns the Proxmox task ID
+// (UPID). The action is asynchronous on the Proxmox side; the returned UPID
+// can be used to follow the task in the Proxmox UI.
+func (c *Client) Action(ctx context.Context, g Guest, action string) (string, error) {
+	if !ValidAction(action) {
+		return "", fmt.Errorf("unsupported action %q", action)
+	}
+	path := fmt.Sprintf("/nodes/%s/%s/%d/status/%s", url.PathEscape(g.Node), url.PathEscape(g.Type), g.VMID, action)
+	var upid string
+	if err := c.do(ctx, http.MethodPost, path, &upid); err != nil {
+		return "", fmt.Errorf("%s guest %d: %w", action, g.VMID, err)
+	}
+	return upid, nil
+}
+
+// do performs an API request and decodes the "data" envelope into out.
+func (c *Client) do(ctx context.Context, method, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api2/json"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("PVEAPIToken=%s=%s", c.tokenID, c.secret))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxmox API %s %s: %s", method, path, resp.Status)
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("decode data: %w", err)
+	}
+	return nil
+}
+
+// FormatInventory renders the guest list as a markdown section for injection
+// into the agent's system prompt.
+func FormatInventory(guests []Guest) string {
+	if len(guests) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Hypervisor Inventory (Proxmox, %d guests)\n\n", len(guests))
+	for _, g := range guests {
+		line := fmt.Sprintf("- **%s** (%s %d on %s): %s", g.ServiceName(), g.Type, g.VMID, g.Node, g.Status)
+		if g.Status == "running" && g.MaxMem > 0 {
+			line += fmt.Sprintf(", mem %d%%, cpu %.0f%%, up %s",
+				g.Mem*100/g.MaxMem, g.CPU*100, (time.Duration(g.Uptime) * time.Second).String())
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
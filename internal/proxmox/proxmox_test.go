@@ -0,0 +1,163 @@
+package proxmox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const resourcesJSON = `{"data":[
+	{"vmid":201,"name":"media","node":"pve2","type":"lxc","status":"running","uptime":3600,"cpu":0.25,"mem":512,"maxmem":1024,"template":0},
+	{"vmid":100,"name":"docker-host","node":"pve1","type":"qemu","status":"stopped","template":0},
+	{"vmid":9000,"name":"debian-template","node":"pve1","type":"qemu","status":"stopped","template":1},
+	{"vmid":105,"name":"","node":"pve1","type":"qemu","status":"paused","template":0}
+]}`
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(srv.URL+"/", "ops@pve!claude", "s3cret")
+}
+
+func TestNew_EmptyURLDisables(t *testing.T) {
+	if c := New("  ", "id", "secret"); c != nil {
+		t.Fatalf("expected nil client for empty URL")
+	}
+}
+
+func TestInventory_SkipsTemplatesAndSorts(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api2/json/cluster/resources" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("type") != "vm" {
+			t.Errorf("expected type=vm, got %q", r.URL.RawQuery)
+		}
+		if got := r.Header.Get("Authorization"); got != "PVEAPIToken=ops@pve!claude=s3cret" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		_, _ = w.Write([]byte(resourcesJSON))
+	})
+
+	guests, err := c.Inventory(context.Background())
+	if err != nil {
+		t.Fatalf("Inventory: %v", err)
+	}
+	var ids []int
+	for _, g := range guests {
+		ids = append(ids, g.VMID)
+	}
+	want := []int{100, 105, 201}
+	if len(ids) != len(want) {
+		t.Fatalf("got %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("got %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestInventory_HTTPError(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "permission denied", http.StatusForbidden)
+	})
+	if _, err := c.Inventory(context.Background()); err == nil {
+		t.Fatal("expected error for 403 response")
+	}
+}
+
+func TestFindGuest(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(resourcesJSON))
+	})
+
+	g, err := c.FindGuest(context.Background(), 201)
+	if err != nil {
+		t.Fatalf("FindGuest: %v", err)
+	}
+	if g == nil || g.Name != "media" || g.Node != "pve2" {
+		t.Fatalf("unexpected guest %+v", g)
+	}
+
+	g, err = c.FindGuest(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("FindGuest: %v", err)
+	}
+	if g != nil {
+		t.Fatalf("expected nil for unknown vmid, got %+v", g)
+	}
+}
+
+func TestAction_PostsToGuestStatusPath(t *testing.T) {
+	var gotMethod, gotPath string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		_, _ = w.Write([]byte(`{"data":"UPID:pve2:0001:reboot"}`))
+	})
+
+	upid, err := c.Action(context.Background(), Guest{VMID: 201, Node: "pve2", Type: "lxc"}, "reboot")
+	if err != nil {
+		t.Fatalf("Action: %v", err)
+	}
+	if gotMethod != http.MethodPost {
+		t.Errorf("expected POST, got %s", gotMethod)
+	}
+	if gotPath != "/api2/json/nodes/pve2/lxc/201/status/reboot" {
+		t.Errorf("unexpected path %s", gotPath)
+	}
+	if upid != "UPID:pve2:0001:reboot" {
+		t.Errorf("unexpected upid %q", upid)
+	}
+}
+
+func TestAction_RejectsUnknownAction(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected for an invalid action")
+	})
+	if _, err := c.Action(context.Background(), Guest{VMID: 1, Node: "pve1", Type: "qemu"}, "destroy"); err == nil {
+		t.Fatal("expected error for unsupported action")
+	}
+}
+
+func TestGuestServiceNameAndHealth(t *testing.T) {
+	cases := []struct {
+		g       Guest
+		service string
+		health  string
+	}{
+		{Guest{VMID: 100, Name: "docker-host", Type: "qemu", Status: "running"}, "docker-host", "healthy"},
+		{Guest{VMID: 101, Type: "qemu", Status: "paused"}, "vm-101", "degraded"},
+		{Guest{VMID: 102, Type: "lxc", Status: "stopped"}, "ct-102", "down"},
+	}
+	for _, tc := range cases {
+		if got := tc.g.ServiceName(); got != tc.service {
+			t.Errorf("ServiceName(%d) = %q, want %q", tc.g.VMID, got, tc.service)
+		}
+		if got := tc.g.HealthStatus(); got != tc.health {
+			t.Errorf("HealthStatus(%d) = %q, want %q", tc.g.VMID, got, tc.health)
+		}
+	}
+}
+
+func TestFormatInventory(t *testing.T) {
+	if got := FormatInventory(nil); got != "" {
+		t.Fatalf("expected empty output for no guests, got %q", got)
+	}
+	out := FormatInventory([]Guest{
+		{VMID: 100, Name: "docker-host", Node: "pve1", Type: "qemu", Status: "stopped"},
+		{VMID: 201, Name: "media", Node: "pve2", Type: "lxc", Status: "running", Uptime: 3600, CPU: 0.25, Mem: 512, MaxMem: 1024},
+	})
+	for _, want := range []string{
+		"## Hypervisor Inventory (Proxmox, 2 guests)",
+		"- **docker-host** (qemu 100 on pve1): stopped",
+		"- **media** (lxc 201 on pve2): running, mem 50%, cpu 25%, up 1h0m0s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Package pulse implements Tier 0: cheap native probes that run between full
+// LLM sessions. A scheduled Tier 1 session only runs every interval (an hour
+// by default), so a service can be down for most of that window unnoticed.
+// The pulse monitor probes a fixed list of HTTP and TCP endpoints every few
+// minutes and, when a target fails several probes in a row, triggers a Tier 1
+// session out-of-band instead of waiting for the next scheduled run.
+package pulse
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+const (
+	// defaultInterval is used when CLAUDEOPS_PULSE_INTERVAL is unset or invalid.
+	defaultInterval = 2 * time.Minute
+
+	// defaultThreshold is the number of consecutive failed probes that
+	// triggers a session.
+	defaultThreshold = 2
+
+	probeTimeout = 10 * time.Second
+)
+
+// Target is a single endpoint probed by the pulse monitor.
+type Target struct {
+	Service string
+	URL     *url.URL // http://, https://, or tcp://host:port
+}
+
+// String renders the target as "service=url".
+func (t Target) String() string {
+	return t.Service + "=" + t.URL.String()
+}
+
+// ParseTargets parses a comma-separated list of service=url pairs, e.g.
+// "jellyfin=http://jellyfin:8096/health,postgres=tcp://db:5432".
+func ParseTargets(spec string) ([]Target, error) {
+	var targets []Target
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(part, "=")
+		name, raw = strings.TrimSpace(name), strings.TrimSpace(raw)
+		if !ok || name == "" || raw == "" {
+			return nil, fmt.Errorf("invalid pulse target %q: want service=url", part)
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pulse target %q: %w", part, err)
+		}
+		switch u.Scheme {
+		case "http", "https":
+		case "tcp":
+			if u.Port() == "" {
+				return nil, fmt.Errorf("invalid pulse target %q: tcp targets need a port", part)
+			}
+		default:
+			return nil, fmt.Errorf("invalid pulse target %q: scheme must be http, https, or tcp", part)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid pulse target %q: missing host", part)
+		}
+		targets = append(targets, Target{Service: name, URL: u})
+	}
+	return targets, nil
+}
+
+// TriggerFunc starts an out-of-band Tier 1 session for the failing services.
+// detail is markdown describing the failures for the session context. It
+// returns false if the session could not be started (e.g. one is running).
+type TriggerFunc func(services []string, detail string) bool
+
+// Monitor runs the Tier 0 probe loop.
+type Monitor struct {
+	targets   []Target
+	interval  time.Duration
+	threshold int
+	db        *db.DB // optional; records probe state changes as health checks
+	trigger   TriggerFunc
+	probe     func(ctx context.Context, t Target) error
+
+	failures map[string]int    // consecutive failures per service
+	lastErr  map[string]string // most recent failure per service
+	tripped  map[string]bool   // a session was triggered for the current outage
+}
+
+// FromConfig builds a Monitor from CLAUDEOPS_PULSE_*. Returns nil when no
+// targets are configured. Invalid targets are logged and the monitor is
+// disabled rather than failing startup.
+func FromConfig(cfg *config.Config, database *db.DB, trigger TriggerFunc) *Monitor {
+	if strings.TrimSpace(cfg.PulseTargets) == "" {
+		return nil
+	}
+	targets, err := ParseTargets(cfg.PulseTargets)
+	if err != nil {
+		log.Printf("pulse: %v (Tier 0 disabled)", err)
+		return nil
+	}
+	return New(targets, time.Duration(cfg.PulseInterval)*time.Second, cfg.PulseThreshold, database, trigger)
+}
+
+// New creates a Monitor. Non-positive interval and threshold fall back to
+// the defaults. database may be nil.
+func New(targets []Target, interval time.Duration, threshold int, database *db.DB, trigger TriggerFunc) *Monitor {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	if threshold <= 0 {
+		threshold = defaultThreshold
+	}
+	return &Monitor{
+		targets:   targets,
+		interval:  interval,
+		threshold: threshold,
+		db:        database,
+		trigger:   trigger,
+		probe:     Probe,
+		failures:  make(map[string]int),
+		lastErr:   make(map[string]string),
+		tripped:   make(map[string]bool),
+	}
+}
+
+// Run probes every target each interval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	log.Printf("pulse: probing %d target(s) every %s", len(m.targets), m.interval)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// check runs one round of probes and triggers a session for services that
+// have just reached the failure threshold. A service triggers at most once
+// per outage; it re-arms after a successful probe.
+func (m *Monitor) check(ctx context.Context) {
+	var failing []string
+	for _, t := range m.targets {
+		start := time.Now()
+		err := m.probe(ctx, t)
+		if ctx.Err() != nil {
+			return
+		}
+		elapsed := int(time.Since(start).Milliseconds())
+
+		if err == nil {
+			if m.failures[t.Service] >= m.threshold {
+				m.record(t, "healthy", elapsed, nil)
+			}
+			m.failures[t.Service] = 0
+			delete(m.lastErr, t.Service)
+			delete(m.tripped, t.Service)
+			continue
+		}
+
+		m.failures[t.Service]++
+		m.lastErr[t.Service] = err.Error()
+		if m.failures[t.Service] == m.threshold {
+			msg := err.Error()
+			m.record(t, "down", elapsed, &msg)
+		}
+		if m.failures[t.Service] >= m.threshold && !m.tripped[t.Service] {
+			failing = append(failing, t.Service)
+		}
+	}
+	if len(failing) == 0 || m.trigger == nil {
+		return
+	}
+	sort.Strings(failing)
+
+	if !m.trigger(failing, m.detail(failing)) {
+		// A session is already running or queued; retry on the next round.
+		log.Printf("pulse: %s failing, session not started (busy)", strings.Join(failing, ", "))
+		return
+	}
+	log.Printf("pulse: %s failing, triggered Tier 1 session", strings.Join(failing, ", "))
+	for _, svc := range failing {
+		m.tripped[svc] = true
+	}
+}
+
+// detail renders the failures as a markdown section for the Tier 1 prompt.
+func (m *Monitor) detail(services []string) string {
+	var b strings.Builder
+	b.WriteString("## Tier 0 Pulse Alert\n\n")
+	fmt.Fprintf(&b, "This session was triggered out-of-band because native probes failed %d or more consecutive times (probed every %s):\n\n", m.threshold, m.interval)
+	for _, svc := range services {
+		for _, t := range m.targets {
+			if t.Service == svc {
+				fmt.Fprintf(&b, "- **%s** (`%s`): %s\n", svc, t.URL.Redacted(), m.lastErr[svc])
+			}
+		}
+	}
+	b.WriteString("\nCheck these services first.\n")
+	return b.String()
+}
+
+// record stores a probe state change as a health check.
+func (m *Monitor) record(t Target, status string, elapsedMs int, errDetail *string) {
+	if m.db == nil {
+		return
+	}
+	checkType := "http"
+	if t.URL.Scheme == "tcp" {
+		checkType = "tcp"
+	}
+	if _, err := m.db.InsertHealthCheck(&db.HealthCheck{
+		Service:        t.Service,
+		CheckType:      checkType,
+		Status:         status,
+		ResponseTimeMs: &elapsedMs,
+		ErrorDetail:    errDetail,
+		CheckedAt:      time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		log.Printf("pulse: record health check: %v", err)
+	}
+}
+
+var httpClient = &http.Client{
+	Timeout: probeTimeout,
+	// Probes check reachability, not certificate hygiene; self-signed
+	// homelab certificates are common.
+	Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, //nolint:gosec
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// Probe checks a single target. HTTP targets fail on transport errors and
+// 5xx responses; TCP targets fail if the port does not accept a connection.
+func Probe(ctx context.Context, t Target) error {
+	if t.URL.Scheme == "tcp" {
+		d := net.Dialer{Timeout: probeTimeout}
+		conn, err := d.DialContext(ctx, "tcp", t.URL.Host)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.URL.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return nil
+}
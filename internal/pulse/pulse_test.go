@@ -0,0 +1,161 @@
+package pulse
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := ParseTargets(" jellyfin=http://jellyfin:8096/health , postgres=tcp://db:5432,")
+	if err != nil {
+		t.Fatalf("ParseTargets: %v", err)
+	}
+	if len(targets) != 2 || targets[0].String() != "jellyfin=http://jellyfin:8096/health" || targets[1].URL.Host != "db:5432" {
+		t.Fatalf("unexpected targets %v", targets)
+	}
+
+	for _, bad := range []string{
+		"jellyfin",
+		"=http://x",
+		"postgres=tcp://db",
+		"dns=udp://1.1.1.1:53",
+		"web=http://",
+	} {
+		if _, err := ParseTargets(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if m := FromConfig(&config.Config{}, nil, nil); m != nil {
+		t.Error("expected nil monitor without targets")
+	}
+	if m := FromConfig(&config.Config{PulseTargets: "bogus"}, nil, nil); m != nil {
+		t.Error("expected nil monitor for invalid targets")
+	}
+	m := FromConfig(&config.Config{PulseTargets: "web=http://web"}, nil, nil)
+	if m == nil || m.interval != defaultInterval || m.threshold != defaultThreshold {
+		t.Fatalf("expected monitor with defaults, got %+v", m)
+	}
+}
+
+func TestCheck_TriggersOncePerOutage(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	targets, _ := ParseTargets("jellyfin=http://jellyfin:8096,caddy=http://caddy")
+	var triggered [][]string
+	var lastDetail string
+	accept := true
+	m := New(targets, time.Minute, 2, database, func(services []string, detail string) bool {
+		triggered = append(triggered, services)
+		lastDetail = detail
+		return accept
+	})
+	down := map[string]bool{"jellyfin": true}
+	m.probe = func(_ context.Context, t Target) error {
+		if down[t.Service] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	ctx := context.Background()
+
+	m.check(ctx)
+	if len(triggered) != 0 {
+		t.Fatal("expected no trigger after a single failure")
+	}
+
+	// Second failure reaches the threshold, but a session is already running.
+	accept = false
+	m.check(ctx)
+	if len(triggered) != 1 {
+		t.Fatalf("expected a trigger attempt at the threshold, got %d", len(triggered))
+	}
+
+	// Retried on the next round and accepted.
+	accept = true
+	m.check(ctx)
+	if len(triggered) != 2 || strings.Join(triggered[1], ",") != "jellyfin" {
+		t.Fatalf("expected retried trigger for jellyfin, got %v", triggered)
+	}
+	if !strings.Contains(lastDetail, "**jellyfin** (`http://jellyfin:8096`): connection refused") {
+		t.Errorf("unexpected detail:\n%s", lastDetail)
+	}
+
+	// Still down: no further triggers for the same outage.
+	m.check(ctx)
+	if len(triggered) != 2 {
+		t.Fatalf("expected one trigger per outage, got %d", len(triggered))
+	}
+
+	// Recovery re-arms the target.
+	down["jellyfin"] = false
+	m.check(ctx)
+	down["jellyfin"] = true
+	m.check(ctx)
+	m.check(ctx)
+	if len(triggered) != 3 {
+		t.Fatalf("expected a new trigger after recovery, got %d", len(triggered))
+	}
+
+	// Down and recovery transitions are recorded as health checks.
+	checks, err := database.QueryHealthChecks("jellyfin", "", "9999", 10)
+	if err != nil {
+		t.Fatalf("QueryHealthChecks: %v", err)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 recorded state changes, got %d", len(checks))
+	}
+}
+
+func TestProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized) // reachable, even if auth is required
+	}))
+	defer srv.Close()
+
+	targets, err := ParseTargets("ok=" + srv.URL + ",broken=" + srv.URL + "/broken,tcp=tcp://" + srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("ParseTargets: %v", err)
+	}
+	ctx := context.Background()
+	if err := Probe(ctx, targets[0]); err != nil {
+		t.Errorf("expected 401 to count as up, got %v", err)
+	}
+	if err := Probe(ctx, targets[1]); err == nil {
+		t.Error("expected 502 to count as down")
+	}
+	if err := Probe(ctx, targets[2]); err != nil {
+		t.Errorf("expected tcp probe to succeed, got %v", err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+	closed, _ := ParseTargets("closed=tcp://" + addr)
+	if err := Probe(ctx, closed[0]); err == nil {
+		t.Error("expected tcp probe to a closed port to fail")
+	}
+}
@@ -0,0 +1,135 @@
+// Package remediation scores whether each remediation (a cooldown action
+// such as a restart or redeployment) actually fixed its service. A
+// remediation is ineffective if the action itself failed, if the service's
+// next health check from a later session or the pulse is not healthy, if a
+// critical event for the service follows it, or if the service has to be
+// remediated again, all within the scoring window. Otherwise it is
+// effective once the window has passed.
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+const (
+	// scoreInterval is how often unscored actions are checked.
+	scoreInterval = 5 * time.Minute
+
+	// batchSize bounds the actions checked per pass.
+	batchSize = 200
+)
+
+// Scorer scores cooldown actions from the health signals that follow them.
+type Scorer struct {
+	db     *db.DB
+	window time.Duration
+	now    func() time.Time
+}
+
+// FromConfig builds a Scorer from CLAUDEOPS_REMEDIATION_SCORE_HOURS. Returns
+// nil when scoring is disabled.
+func FromConfig(cfg *config.Config, database *db.DB) *Scorer {
+	if cfg.RemediationScoreHours <= 0 {
+		return nil
+	}
+	return New(database, time.Duration(cfg.RemediationScoreHours)*time.Hour)
+}
+
+// New creates a Scorer that judges each action over window.
+func New(database *db.DB, window time.Duration) *Scorer {
+	return &Scorer{db: database, window: window, now: time.Now}
+}
+
+// Run scores actions until ctx is cancelled.
+func (s *Scorer) Run(ctx context.Context) {
+	log.Printf("remediation: scoring remediations over %g hours", s.window.Hours())
+	s.scoreAll()
+	ticker := time.NewTicker(scoreInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.scoreAll()
+		}
+	}
+}
+
+// scoreAll scores every unscored action whose outcome is known.
+func (s *Scorer) scoreAll() {
+	actions, err := s.db.ListUnscoredCooldownActions(batchSize)
+	if err != nil {
+		log.Printf("remediation: %v", err)
+		return
+	}
+	now := s.now().UTC()
+	for i := range actions {
+		a := &actions[i]
+		outcome, reason, err := s.score(a, now)
+		if err != nil {
+			log.Printf("remediation: action %d: %v", a.ID, err)
+			continue
+		}
+		if outcome == "" {
+			continue
+		}
+		if err := s.db.ScoreCooldownAction(a.ID, outcome, reason, now.Format(time.RFC3339)); err != nil {
+			log.Printf("remediation: %v", err)
+		}
+	}
+}
+
+// score returns the action's outcome and the reason for it, or an empty
+// outcome while its window is still open and nothing has gone wrong.
+func (s *Scorer) score(a *db.CooldownAction, now time.Time) (string, string, error) {
+	if !a.Success {
+		return db.OutcomeIneffective, "the action itself failed", nil
+	}
+	at, err := time.Parse(time.RFC3339, a.Timestamp)
+	if err != nil {
+		return "", "", fmt.Errorf("parse timestamp %q: %w", a.Timestamp, err)
+	}
+	end := at.Add(s.window)
+	until := end.Format(time.RFC3339)
+	if now.Before(end) {
+		until = now.Format(time.RFC3339)
+	}
+
+	hc, err := s.db.FirstHealthCheckAfter(a.Service, a.Timestamp, until, a.SessionID)
+	if err != nil {
+		return "", "", err
+	}
+	if hc != nil && hc.Status != "healthy" {
+		return db.OutcomeIneffective, fmt.Sprintf("%s at the next check (%s)", hc.Status, hc.CheckedAt), nil
+	}
+	ev, err := s.db.FirstEventAfter(a.Service, "critical", a.Timestamp, until, a.SessionID)
+	if err != nil {
+		return "", "", err
+	}
+	if ev != nil {
+		return db.OutcomeIneffective, fmt.Sprintf("critical event at %s: %s", ev.CreatedAt, ev.Message), nil
+	}
+	next, err := s.db.NextCooldownAction(a, until)
+	if err != nil {
+		return "", "", err
+	}
+	if next != nil {
+		return db.OutcomeIneffective, fmt.Sprintf("remediated again (%s) at %s", next.ActionType, next.Timestamp), nil
+	}
+
+	if now.Before(end) {
+		return "", "", nil
+	}
+	reason := fmt.Sprintf("no critical events for %g hours", s.window.Hours())
+	if hc != nil {
+		reason = fmt.Sprintf("healthy at the next check and %s", reason)
+	}
+	return db.OutcomeEffective, reason, nil
+}
@@ -0,0 +1,123 @@
+package remediation
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+var base = time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC)
+
+func ts(d time.Duration) string { return base.Add(d).Format(time.RFC3339) }
+
+func newTestScorer(t *testing.T) (*Scorer, *time.Time) {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	now := base
+	s := New(database, 6*time.Hour)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func insertAction(t *testing.T, s *Scorer, service string, at time.Duration, ok bool, sessionID *int64) int64 {
+	t.Helper()
+	id, err := s.db.InsertCooldownAction(&db.CooldownAction{Service: service, ActionType: "restart", Timestamp: ts(at), Success: ok, Tier: 2, SessionID: sessionID})
+	if err != nil {
+		t.Fatalf("insert cooldown action: %v", err)
+	}
+	return id
+}
+
+func outcomes(t *testing.T, s *Scorer) map[string]string {
+	t.Helper()
+	actions, err := s.db.ListScoredCooldownActions(100)
+	if err != nil {
+		t.Fatalf("list scored: %v", err)
+	}
+	out := make(map[string]string)
+	for _, a := range actions {
+		out[a.Service] = *a.Outcome + ": " + *a.OutcomeReason
+	}
+	return out
+}
+
+func TestFromConfig(t *testing.T) {
+	if s := FromConfig(&config.Config{}, nil); s != nil {
+		t.Error("expected nil scorer when disabled")
+	}
+	if s := FromConfig(&config.Config{RemediationScoreHours: 4}, nil); s == nil || s.window != 4*time.Hour {
+		t.Fatalf("expected 4h scorer, got %+v", s)
+	}
+}
+
+func TestScoreAll(t *testing.T) {
+	s, now := newTestScorer(t)
+	sid, err := s.db.InsertSession(&db.Session{Tier: 2, Model: "sonnet", PromptFile: "/p", Status: "completed", StartedAt: ts(0)})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+
+	insertAction(t, s, "failed", 0, false, &sid)
+	insertAction(t, s, "held", 0, true, &sid)
+	insertAction(t, s, "checked", 0, true, &sid)
+	insertAction(t, s, "critical", 0, true, &sid)
+	insertAction(t, s, "repeated", 0, true, &sid)
+	insertAction(t, s, "repeated", 2*time.Hour, true, nil)
+
+	// The remediating session's own report does not count.
+	_, _ = s.db.InsertHealthCheck(&db.HealthCheck{SessionID: &sid, Service: "critical", CheckType: "service", Status: "healthy", CheckedAt: ts(time.Minute)})
+	_, _ = s.db.InsertHealthCheck(&db.HealthCheck{Service: "checked", CheckType: "http", Status: "healthy", CheckedAt: ts(time.Hour)})
+	svc := "critical"
+	_, _ = s.db.InsertEvent(&db.Event{Level: "critical", Service: &svc, Message: "down again", CreatedAt: ts(3 * time.Hour)})
+
+	*now = base.Add(time.Hour)
+	s.scoreAll()
+	got := outcomes(t, s)
+	if len(got) != 1 || got["failed"] != "ineffective: the action itself failed" {
+		t.Fatalf("expected only the failed action scored within the first hour, got %v", got)
+	}
+
+	*now = base.Add(4 * time.Hour)
+	s.scoreAll()
+	got = outcomes(t, s)
+	if !strings.HasPrefix(got["critical"], "ineffective: critical event at") || !strings.Contains(got["critical"], "down again") {
+		t.Errorf("unexpected critical outcome %q", got["critical"])
+	}
+	if !strings.HasPrefix(got["repeated"], "ineffective: remediated again (restart)") {
+		t.Errorf("unexpected repeated outcome %q", got["repeated"])
+	}
+	if _, ok := got["held"]; ok {
+		t.Errorf("expected held to be pending until the window ends, got %q", got["held"])
+	}
+
+	*now = base.Add(7 * time.Hour)
+	s.scoreAll()
+	got = outcomes(t, s)
+	if got["held"] != "effective: no critical events for 6 hours" {
+		t.Errorf("unexpected held outcome %q", got["held"])
+	}
+	if got["checked"] != "effective: healthy at the next check and no critical events for 6 hours" {
+		t.Errorf("unexpected checked outcome %q", got["checked"])
+	}
+}
+
+func TestScore_UnhealthyNextCheck(t *testing.T) {
+	s, now := newTestScorer(t)
+	insertAction(t, s, "jellyfin", 0, true, nil)
+	_, _ = s.db.InsertHealthCheck(&db.HealthCheck{Service: "jellyfin", CheckType: "service", Status: "degraded", CheckedAt: ts(30 * time.Minute)})
+	_, _ = s.db.InsertHealthCheck(&db.HealthCheck{Service: "jellyfin", CheckType: "service", Status: "healthy", CheckedAt: ts(time.Hour)})
+
+	*now = base.Add(2 * time.Hour)
+	s.scoreAll()
+	if got := outcomes(t, s)["jellyfin"]; got != "ineffective: degraded at the next check ("+ts(30*time.Minute)+")" {
+		t.Errorf("unexpected outcome %q", got)
+	}
+}
@@ -0,0 +1,235 @@
+// Package report builds the weekly trend report: how the agent behaved over
+// the last seven days compared with the seven days before. It covers
+// escalations per service, mean session cost and duration, memories learned
+// and decayed, how often remediations succeeded, and how often operators
+// flagged a session as wrong. The report is rendered at /reports/weekly and,
+// when CLAUDEOPS_WEEKLY_REPORT_DAY is set, pushed through notifications on
+// that day.
+package report
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+const (
+	// period is the length of each compared window.
+	period = 7 * 24 * time.Hour
+
+	// checkInterval is how often the sender checks whether a report is due.
+	checkInterval = time.Hour
+
+	// lastSentKey is the config key recording the date the report was last
+	// sent, so restarts do not send it twice.
+	lastSentKey = "weekly_report_last_sent"
+
+	// maxServices is how many services are listed in the notification.
+	maxServices = 5
+)
+
+// Report compares the last seven days with the seven days before.
+type Report struct {
+	GeneratedAt time.Time
+	This        *db.PeriodStats
+	Last        *db.PeriodStats
+	Services    []ServiceTrend // services with escalations in either week, most escalated first
+}
+
+// ServiceTrend is one service's escalation count in each week.
+type ServiceTrend struct {
+	Service string
+	This    int
+	Last    int
+}
+
+// Weekly builds the report for the seven days ending at now.
+func Weekly(database *db.DB, now time.Time) (*Report, error) {
+	now = now.UTC()
+	mid := now.Add(-period)
+	start := mid.Add(-period)
+	this, err := database.GetPeriodStats(mid.Format(time.RFC3339), now.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	last, err := database.GetPeriodStats(start.Format(time.RFC3339), mid.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{GeneratedAt: now, This: this, Last: last}
+	seen := make(map[string]bool)
+	for _, counts := range []map[string]int{this.EscalationsByService, last.EscalationsByService} {
+		for svc := range counts {
+			if !seen[svc] {
+				seen[svc] = true
+				r.Services = append(r.Services, ServiceTrend{
+					Service: svc,
+					This:    this.EscalationsByService[svc],
+					Last:    last.EscalationsByService[svc],
+				})
+			}
+		}
+	}
+	sort.Slice(r.Services, func(i, j int) bool {
+		a, b := r.Services[i], r.Services[j]
+		if a.This != b.This {
+			return a.This > b.This
+		}
+		if a.Last != b.Last {
+			return a.Last > b.Last
+		}
+		return a.Service < b.Service
+	})
+	return r, nil
+}
+
+// Title returns the notification title.
+func (r *Report) Title() string {
+	return "Claude Ops: weekly report"
+}
+
+// Text renders the report as a plain-text notification body.
+func (r *Report) Text() string {
+	this, last := r.This, r.Last
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s – %s compared with the previous week\n\n",
+		r.GeneratedAt.Add(-period).Format("Jan 2"), r.GeneratedAt.Format("Jan 2"))
+	fmt.Fprintf(&b, "Sessions: %d (%s)\n", this.Sessions, Delta(float64(this.Sessions), float64(last.Sessions)))
+	fmt.Fprintf(&b, "Escalations: %d (%s)\n", this.Escalations, Delta(float64(this.Escalations), float64(last.Escalations)))
+	fmt.Fprintf(&b, "Mean session cost: $%.2f (%s)\n", this.MeanCostUSD, Delta(this.MeanCostUSD, last.MeanCostUSD))
+	fmt.Fprintf(&b, "Mean session duration: %s (%s)\n",
+		(time.Duration(this.MeanDurationMs) * time.Millisecond).Round(time.Second),
+		Delta(float64(this.MeanDurationMs), float64(last.MeanDurationMs)))
+	fmt.Fprintf(&b, "Memories: %d new, %d decayed (last week %d new, %d decayed)\n",
+		this.NewMemories, this.DecayedMemories, last.NewMemories, last.DecayedMemories)
+	fmt.Fprintf(&b, "Remediation success: %s (last week %s)\n",
+		Rate(this.RemediationSuccessRate(), this.Remediations), Rate(last.RemediationSuccessRate(), last.Remediations))
+	fmt.Fprintf(&b, "Operator corrections: %d (%s)\n", this.Corrections, Delta(float64(this.Corrections), float64(last.Corrections)))
+	if len(r.Services) > 0 {
+		b.WriteString("\nEscalations by service:\n")
+		for i, s := range r.Services {
+			if i == maxServices {
+				fmt.Fprintf(&b, "  …and %d more\n", len(r.Services)-maxServices)
+				break
+			}
+			fmt.Fprintf(&b, "  %s: %d (last week %d)\n", s.Service, s.This, s.Last)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Delta describes the change from last to this, e.g. "+25%", "-3%", "no
+// change", or "new" when last is zero.
+func Delta(this, last float64) string {
+	switch {
+	case this == last:
+		return "no change"
+	case last == 0:
+		return "new"
+	}
+	return fmt.Sprintf("%+.0f%%", (this-last)/last*100)
+}
+
+// Rate formats a success rate over n attempts, or "none" when there were
+// none.
+func Rate(rate float64, n int) string {
+	if rate < 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%.0f%% of %d", rate*100, n)
+}
+
+// SendFunc delivers one notification.
+type SendFunc func(ctx context.Context, title, body string) error
+
+// Sender pushes the weekly report on a fixed weekday.
+type Sender struct {
+	db   *db.DB
+	day  time.Weekday
+	send SendFunc
+	now  func() time.Time
+}
+
+// FromConfig builds a Sender from CLAUDEOPS_WEEKLY_REPORT_DAY. Returns nil
+// when the day is empty or invalid, or no Apprise URLs are configured.
+func FromConfig(cfg *config.Config, database *db.DB, send SendFunc) *Sender {
+	if cfg.WeeklyReportDay == "" || cfg.AppriseURLs == "" {
+		return nil
+	}
+	day, ok := ParseWeekday(cfg.WeeklyReportDay)
+	if !ok {
+		log.Printf("report: invalid weekly report day %q (report disabled)", cfg.WeeklyReportDay)
+		return nil
+	}
+	return New(database, day, send)
+}
+
+// New creates a Sender that pushes the report on day.
+func New(database *db.DB, day time.Weekday, send SendFunc) *Sender {
+	return &Sender{db: database, day: day, send: send, now: time.Now}
+}
+
+// ParseWeekday parses an English weekday name, case-insensitively.
+func ParseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if name := strings.ToLower(d.String()); s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// Run sends the report once on each configured weekday until ctx is
+// cancelled.
+func (s *Sender) Run(ctx context.Context) {
+	log.Printf("report: sending the weekly report on %ss", s.day)
+	s.check(ctx)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(ctx)
+		}
+	}
+}
+
+// check sends the report if today is the configured day and it has not
+// been sent today.
+func (s *Sender) check(ctx context.Context) {
+	now := s.now().UTC()
+	if now.Weekday() != s.day {
+		return
+	}
+	today := now.Format("2006-01-02")
+	lastSent, err := s.db.GetConfig(lastSentKey, "")
+	if err != nil {
+		log.Printf("report: %v", err)
+		return
+	}
+	if lastSent == today {
+		return
+	}
+	r, err := Weekly(s.db, now)
+	if err != nil {
+		log.Printf("report: %v", err)
+		return
+	}
+	if err := s.send(ctx, r.Title(), r.Text()); err != nil {
+		log.Printf("report: send: %v", err)
+		return
+	}
+	if err := s.db.SetConfig(lastSentKey, today); err != nil {
+		log.Printf("report: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func openTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	return database
+}
+
+func insertEscalation(t *testing.T, database *db.DB, at time.Time, services ...string) {
+	t.Helper()
+	id, err := database.InsertSession(&db.Session{Tier: 2, Model: "sonnet", PromptFile: "/p", Status: "completed", StartedAt: at.Format(time.RFC3339), Trigger: "escalation"})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+	if err := database.UpdateSessionServices(id, services); err != nil {
+		t.Fatalf("update session services: %v", err)
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	database := openTestDB(t)
+	now := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	insertEscalation(t, database, now.AddDate(0, 0, -1), "jellyfin")
+	insertEscalation(t, database, now.AddDate(0, 0, -2), "jellyfin", "caddy")
+	insertEscalation(t, database, now.AddDate(0, 0, -9), "postgres")
+	insertEscalation(t, database, now.AddDate(0, 0, -20), "ignored")
+
+	r, err := Weekly(database, now)
+	if err != nil {
+		t.Fatalf("Weekly: %v", err)
+	}
+	if r.This.Escalations != 2 || r.Last.Escalations != 1 {
+		t.Fatalf("expected 2 and 1 escalations, got %d and %d", r.This.Escalations, r.Last.Escalations)
+	}
+	want := []ServiceTrend{{"jellyfin", 2, 0}, {"caddy", 1, 0}, {"postgres", 0, 1}}
+	if len(r.Services) != len(want) {
+		t.Fatalf("expected %v, got %v", want, r.Services)
+	}
+	for i := range want {
+		if r.Services[i] != want[i] {
+			t.Errorf("service %d: expected %v, got %v", i, want[i], r.Services[i])
+		}
+	}
+
+	text := r.Text()
+	for _, s := range []string{"Mar 2 – Mar 9", "Escalations: 2 (+100%)", "Remediation success: none", "jellyfin: 2 (last week 0)"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("report text missing %q:\n%s", s, text)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	for _, tc := range []struct {
+		this, last float64
+		want       string
+	}{
+		{5, 5, "no change"},
+		{3, 0, "new"},
+		{0, 4, "-100%"},
+		{15, 10, "+50%"},
+	} {
+		if got := Delta(tc.this, tc.last); got != tc.want {
+			t.Errorf("Delta(%v, %v) = %q, want %q", tc.this, tc.last, got, tc.want)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if s := FromConfig(&config.Config{AppriseURLs: "json://x"}, nil, nil); s != nil {
+		t.Error("expected nil sender without a day")
+	}
+	if s := FromConfig(&config.Config{WeeklyReportDay: "monday"}, nil, nil); s != nil {
+		t.Error("expected nil sender without apprise URLs")
+	}
+	if s := FromConfig(&config.Config{WeeklyReportDay: "someday", AppriseURLs: "json://x"}, nil, nil); s != nil {
+		t.Error("expected nil sender for an invalid day")
+	}
+	s := FromConfig(&config.Config{WeeklyReportDay: "Mon", AppriseURLs: "json://x"}, nil, nil)
+	if s == nil || s.day != time.Monday {
+		t.Fatalf("expected Monday sender, got %+v", s)
+	}
+}
+
+func TestCheck_SendsOncePerDay(t *testing.T) {
+	database := openTestDB(t)
+	var titles []string
+	s := New(database, time.Monday, func(_ context.Context, title, _ string) error {
+		titles = append(titles, title)
+		return nil
+	})
+	now := time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC) // Sunday
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.check(ctx)
+	if len(titles) != 0 {
+		t.Fatalf("expected nothing sent on Sunday, got %v", titles)
+	}
+	now = now.Add(24 * time.Hour)
+	s.check(ctx)
+	now = now.Add(time.Hour)
+	s.check(ctx)
+	if len(titles) != 1 || titles[0] != "Claude Ops: weekly report" {
+		t.Fatalf("expected one report on Monday, got %v", titles)
+	}
+
+	// A restarted sender does not resend the same day.
+	s2 := New(database, time.Monday, s.send)
+	s2.now = s.now
+	s2.check(ctx)
+	if len(titles) != 1 {
+		t.Fatalf("expected no resend after restart, got %v", titles)
+	}
+}
@@ -0,0 +1,185 @@
+package sandbox
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around a change.
+	diffContext = 3
+
+	// maxDiffCells bounds the line-by-line comparison. Larger files are
+	// shown as a whole replacement.
+	maxDiffCells = 4 << 20
+)
+
+// Diff returns a unified diff of c for display.
+func Diff(c Change) string {
+	if isBinary(c.Old) || isBinary(c.New) {
+		return fmt.Sprintf("Binary file %s %s\n", c.Path, c.Kind)
+	}
+	from, to := "a"+c.Path, "b"+c.Path
+	switch c.Kind {
+	case Added:
+		from = "/dev/null"
+	case Deleted:
+		to = "/dev/null"
+	}
+	a, b := splitLines(c.Old), splitLines(c.New)
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+	for _, h := range hunks(diffLines(a, b)) {
+		out.WriteString(h)
+	}
+	return out.String()
+}
+
+func isBinary(b []byte) bool {
+	return bytes.IndexByte(b, 0) >= 0 || !utf8.Valid(b)
+}
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// op is one line of an edit script: ' ' kept, '-' removed, '+' added.
+type op struct {
+	kind       byte
+	line       string
+	aIdx, bIdx int // 1-based line numbers in a and b
+}
+
+// diffLines computes an edit script from a to b using the longest common
+// subsequence of lines.
+func diffLines(a, b []string) []op {
+	// Trim the common prefix and suffix to keep the table small.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	var ops []op
+	for i := 0; i < pre; i++ {
+		ops = append(ops, op{' ', a[i], i + 1, i + 1})
+	}
+	if len(ma)*len(mb) > maxDiffCells {
+		for i, l := range ma {
+			ops = append(ops, op{'-', l, pre + i + 1, 0})
+		}
+		for j, l := range mb {
+			ops = append(ops, op{'+', l, 0, pre + j + 1})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of ma[i:] and mb[j:].
+		lcs := make([][]int, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(mb)+1)
+		}
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(ma) || j < len(mb) {
+			switch {
+			case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+				ops = append(ops, op{' ', ma[i], pre + i + 1, pre + j + 1})
+				i++
+				j++
+			case i < len(ma) && (j == len(mb) || lcs[i+1][j] >= lcs[i][j+1]):
+				ops = append(ops, op{'-', ma[i], pre + i + 1, 0})
+				i++
+			default:
+				ops = append(ops, op{'+', mb[j], 0, pre + j + 1})
+				j++
+			}
+		}
+	}
+	for k := 0; k < suf; k++ {
+		ops = append(ops, op{' ', a[len(a)-suf+k], len(a) - suf + k + 1, len(b) - suf + k + 1})
+	}
+	return ops
+}
+
+// hunks groups an edit script into unified diff hunks with context.
+func hunks(ops []op) []string {
+	var out []string
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		lo := max(0, start-diffContext)
+		// Extend until diffContext*2 unchanged lines separate changes.
+		end, same := start, 0
+		for end < len(ops) && same <= 2*diffContext {
+			if ops[end].kind == ' ' {
+				same++
+			} else {
+				same = 0
+			}
+			end++
+		}
+		hi := end - max(0, same-diffContext)
+
+		var body strings.Builder
+		aStart, bStart, aLen, bLen := 0, 0, 0, 0
+		for _, o := range ops[lo:hi] {
+			if o.aIdx > 0 && aStart == 0 {
+				aStart = o.aIdx
+			}
+			if o.bIdx > 0 && bStart == 0 {
+				bStart = o.bIdx
+			}
+			if o.kind != '+' {
+				aLen++
+			}
+			if o.kind != '-' {
+				bLen++
+			}
+			body.WriteByte(o.kind)
+			body.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))+body.String())
+		start = hi
+	}
+	return out
+}
+
+// hunkRange formats one side of a hunk header. A side is only empty in a
+// hunk when that version of the file has no lines at all.
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		return "0,0"
+	case 1:
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, n)
+}
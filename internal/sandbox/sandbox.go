@@ -0,0 +1,346 @@
+// Package sandbox turns a session's file edits into a proposal. A snapshot
+// of the sandboxed directories is taken before the session starts; when it
+// ends, every file the session added, modified, or deleted is captured as a
+// Change and restored to its snapshot state. The changes can later be
+// applied to the real paths, once an operator approves them.
+//
+// The snapshot is kept on disk, with a manifest, so the changes of a session
+// cut short by a supervisor crash can still be captured on restart. Files
+// are restored and applied by writing a temporary file and renaming it over
+// the original, so a file is never left half written.
+package sandbox
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// manifestName is the snapshot's index of the files it copied.
+const manifestName = "manifest.json"
+
+// MaxFileSize is the largest file the sandbox tracks. Larger files are
+// neither snapshotted nor captured, so edits to them stand.
+const MaxFileSize = 5 << 20
+
+// Change kinds.
+const (
+	Added    = "added"
+	Modified = "modified"
+	Deleted  = "deleted"
+)
+
+// Change is one file a session changed.
+type Change struct {
+	Path string
+	Kind string // Added, Modified, or Deleted
+	Old  []byte // content before the session; nil when Added
+	New  []byte // content the session left; nil when Deleted
+	Mode fs.FileMode
+}
+
+// file is a snapshotted file.
+type file struct {
+	hash [sha256.Size]byte
+	mode fs.FileMode
+	copy string // path of the copy in the snapshot directory
+}
+
+// Snapshot is the state of the sandboxed directories before a session.
+type Snapshot struct {
+	roots   []string
+	dir     string
+	files   map[string]file
+	skipped map[string]bool // too large to track
+}
+
+// Take snapshots the regular files under roots, copying them into dir.
+// Version control directories (.git) are skipped. Roots that do not exist
+// are ignored.
+func Take(dir string, roots ...string) (*Snapshot, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create snapshot dir: %w", err)
+	}
+	s := &Snapshot{dir: dir, files: make(map[string]file), skipped: make(map[string]bool)}
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(abs); err != nil {
+			continue
+		}
+		s.roots = append(s.roots, abs)
+	}
+	err := s.walk(func(path string, info fs.FileInfo) error {
+		if _, seen := s.files[path]; seen || s.skipped[path] {
+			return nil // roots may nest
+		}
+		if info.Size() > MaxFileSize {
+			s.skipped[path] = true
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		cp := filepath.Join(s.dir, strconv.Itoa(len(s.files)))
+		if err := os.WriteFile(cp, data, 0o600); err != nil {
+			return err
+		}
+		s.files[path] = file{hash: sha256.Sum256(data), mode: info.Mode().Perm(), copy: cp}
+		return nil
+	})
+	if err == nil {
+		err = s.save()
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	return s, nil
+}
+
+// manifest is the on-disk form of a Snapshot.
+type manifest struct {
+	Roots   []string                `json:"roots"`
+	Files   map[string]manifestFile `json:"files"`
+	Skipped []string                `json:"skipped,omitempty"`
+}
+
+type manifestFile struct {
+	Hash string      `json:"sha256"`
+	Mode fs.FileMode `json:"mode"`
+	Copy string      `json:"copy"` // name of the copy in the snapshot directory
+}
+
+// save writes the snapshot's manifest.
+func (s *Snapshot) save() error {
+	m := manifest{Roots: s.roots, Files: make(map[string]manifestFile, len(s.files))}
+	for path, f := range s.files {
+		m.Files[path] = manifestFile{Hash: hex.EncodeToString(f.hash[:]), Mode: f.mode, Copy: filepath.Base(f.copy)}
+	}
+	for path := range s.skipped {
+		m.Skipped = append(m.Skipped, path)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(s.dir, manifestName), data, 0o600)
+}
+
+// Load reads the snapshot Take left in dir. It returns an os.ErrNotExist
+// error when there is none.
+func Load(dir string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("snapshot manifest: %w", err)
+	}
+	s := &Snapshot{roots: m.Roots, dir: dir, files: make(map[string]file, len(m.Files)), skipped: make(map[string]bool)}
+	for path, f := range m.Files {
+		hash, err := hex.DecodeString(f.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("snapshot manifest: bad hash for %s", path)
+		}
+		sf := file{mode: f.Mode, copy: filepath.Join(dir, f.Copy)}
+		copy(sf.hash[:], hash)
+		s.files[path] = sf
+	}
+	for _, path := range m.Skipped {
+		s.skipped[path] = true
+	}
+	return s, nil
+}
+
+// Capture compares the sandboxed directories with the snapshot, restores
+// every changed file to its snapshot state, and returns the changes, sorted
+// by path. The snapshot is removed. A file that cannot be restored is still
+// returned, along with an error naming it.
+func (s *Snapshot) Capture() ([]Change, error) {
+	defer os.RemoveAll(s.dir) //nolint:errcheck
+
+	var changes []Change
+	var errs []error
+	present := make(map[string]bool)
+	err := s.walk(func(path string, info fs.FileInfo) error {
+		if present[path] || s.skipped[path] {
+			return nil
+		}
+		present[path] = true
+		if info.Size() > MaxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		orig, ok := s.files[path]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Path: path, Kind: Added, New: data, Mode: info.Mode().Perm()})
+			if err := os.Remove(path); err != nil {
+				errs = append(errs, fmt.Errorf("restore %s: %w", path, err))
+			}
+		case sha256.Sum256(data) != orig.hash:
+			old, err := os.ReadFile(orig.copy)
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			changes = append(changes, Change{Path: path, Kind: Modified, Old: old, New: data, Mode: orig.mode})
+			if err := writeFile(path, old, orig.mode); err != nil {
+				errs = append(errs, fmt.Errorf("restore %s: %w", path, err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for path, orig := range s.files {
+		if present[path] {
+			continue
+		}
+		old, err := os.ReadFile(orig.copy)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changes = append(changes, Change{Path: path, Kind: Deleted, Old: old, Mode: orig.mode})
+		if err := writeFile(path, old, orig.mode); err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", path, err))
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, errors.Join(errs...)
+}
+
+// walk calls fn for every regular file under the roots, skipping .git
+// directories and unreadable entries.
+func (s *Snapshot) walk(fn func(path string, info fs.FileInfo) error) error {
+	for _, root := range s.roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if d != nil && d.IsDir() && path != root {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" || path == s.dir {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			return fn(path, info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply writes changes to their paths. It first checks that every path is
+// still in the state the change was made from, and writes nothing if any
+// has changed since. If a change cannot be written, the changes already
+// written are undone.
+func Apply(changes []Change) error {
+	for _, c := range changes {
+		cur, err := os.ReadFile(c.Path)
+		switch {
+		case c.Kind == Added:
+			if err == nil {
+				return fmt.Errorf("%s already exists", c.Path)
+			}
+		case err != nil:
+			return fmt.Errorf("%s: %w", c.Path, err)
+		case !bytes.Equal(cur, c.Old):
+			return fmt.Errorf("%s has changed since the session ran", c.Path)
+		}
+	}
+	for i, c := range changes {
+		var err error
+		if c.Kind == Deleted {
+			err = os.Remove(c.Path)
+		} else {
+			err = writeFile(c.Path, c.New, c.Mode)
+		}
+		if err != nil {
+			err = fmt.Errorf("apply %s: %w", c.Path, err)
+			return errors.Join(err, undo(changes[:i]))
+		}
+	}
+	return nil
+}
+
+// undo puts back the content changes were made from, in reverse order.
+func undo(changes []Change) error {
+	var errs []error
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		var err error
+		if c.Kind == Added {
+			err = os.Remove(c.Path)
+		} else {
+			err = writeFile(c.Path, c.Old, c.Mode)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("undo %s: %w", c.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeFile replaces path with data, creating parent directories as
+// needed. The data goes to a temporary file in the same directory that is
+// then renamed over path, so readers see the old or the new content, never
+// a mix.
+func writeFile(path string, data []byte, mode fs.FileMode) error {
+	if mode == 0 {
+		mode = 0o644
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
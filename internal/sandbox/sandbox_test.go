@@ -0,0 +1,175 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "<missing>"
+	}
+	return string(b)
+}
+
+func TestCaptureRestoresAndApplyReplays(t *testing.T) {
+	root := t.TempDir()
+	compose := filepath.Join(root, "infra", "compose.yaml")
+	notes := filepath.Join(root, "infra", "NOTES.md")
+	stale := filepath.Join(root, "infra", "stale.conf")
+	gitFile := filepath.Join(root, "infra", ".git", "HEAD")
+	write(t, compose, "services:\n  jellyfin:\n    image: jellyfin:10.8\n")
+	write(t, stale, "old\n")
+	write(t, gitFile, "ref: refs/heads/main\n")
+
+	snap, err := Take(filepath.Join(t.TempDir(), "snap"), root, filepath.Join(root, "missing"))
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+
+	// The session edits, adds, and deletes files, and touches .git.
+	write(t, compose, "services:\n  jellyfin:\n    image: jellyfin:10.9\n")
+	write(t, notes, "pinned jellyfin\n")
+	if err := os.Remove(stale); err != nil {
+		t.Fatal(err)
+	}
+	write(t, gitFile, "ref: refs/heads/claudeops\n")
+
+	changes, err := snap.Capture()
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	var kinds []string
+	for _, c := range changes {
+		kinds = append(kinds, filepath.Base(c.Path)+":"+c.Kind)
+	}
+	if got := strings.Join(kinds, " "); got != "NOTES.md:added compose.yaml:modified stale.conf:deleted" {
+		t.Fatalf("changes = %s", got)
+	}
+
+	// The real paths are back to their original state; .git is untouched.
+	if got := read(t, compose); !strings.Contains(got, "10.8") {
+		t.Errorf("compose.yaml not restored: %q", got)
+	}
+	if got := read(t, notes); got != "<missing>" {
+		t.Errorf("NOTES.md not removed: %q", got)
+	}
+	if got := read(t, stale); got != "old\n" {
+		t.Errorf("stale.conf not restored: %q", got)
+	}
+	if got := read(t, gitFile); !strings.Contains(got, "claudeops") {
+		t.Errorf(".git should not be sandboxed, got %q", got)
+	}
+
+	if err := Apply(changes); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if !strings.Contains(read(t, compose), "10.9") || read(t, notes) != "pinned jellyfin\n" || read(t, stale) != "<missing>" {
+		t.Error("changes not applied")
+	}
+
+	// Applying again conflicts and writes nothing.
+	write(t, compose, "services: {}\n")
+	if err := Apply(changes); err == nil {
+		t.Error("expected a conflict")
+	}
+	if read(t, compose) != "services: {}\n" {
+		t.Error("conflicting apply wrote files")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	got := Diff(Change{Path: "/repos/x.txt", Kind: Modified, Old: []byte(old), New: []byte(new)})
+	want := `--- a/repos/x.txt
++++ b/repos/x.txt
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+	if got != want {
+		t.Errorf("Diff =\n%s\nwant\n%s", got, want)
+	}
+
+	added := Diff(Change{Path: "/repos/new.txt", Kind: Added, New: []byte("one\ntwo")})
+	if !strings.Contains(added, "--- /dev/null\n+++ b/repos/new.txt\n@@ -0,0 +1,2 @@\n+one\n+two\n\\ No newline at end of file\n") {
+		t.Errorf("unexpected added diff:\n%s", added)
+	}
+	if got := Diff(Change{Path: "/repos/img.png", Kind: Added, New: []byte{0x89, 'P', 0}}); !strings.HasPrefix(got, "Binary file") {
+		t.Errorf("unexpected binary diff %q", got)
+	}
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	root := t.TempDir()
+	conf := filepath.Join(root, "app.conf")
+	write(t, conf, "port=80\n")
+	dir := filepath.Join(t.TempDir(), "snap")
+	if _, err := Take(dir, root); err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	write(t, conf, "port=8080\n")
+
+	// A supervisor restarted after a crash loads the snapshot from disk.
+	snap, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	changes, err := snap.Capture()
+	if err != nil || len(changes) != 1 || changes[0].Kind != Modified || string(changes[0].Old) != "port=80\n" {
+		t.Fatalf("Capture = %+v, %v", changes, err)
+	}
+	if got := read(t, conf); got != "port=80\n" {
+		t.Errorf("app.conf not restored: %q", got)
+	}
+	if _, err := Load(dir); !os.IsNotExist(err) {
+		t.Errorf("Load after Capture: %v, want not exist", err)
+	}
+}
+
+func TestApplyUndoesOnFailure(t *testing.T) {
+	root := t.TempDir()
+	conf := filepath.Join(root, "app.conf")
+	blocker := filepath.Join(root, "blocker")
+	write(t, conf, "port=80\n")
+	write(t, blocker, "a file, not a directory\n")
+
+	err := Apply([]Change{
+		{Path: conf, Kind: Modified, Old: []byte("port=80\n"), New: []byte("port=8080\n"), Mode: 0o644},
+		{Path: filepath.Join(blocker, "child"), Kind: Added, New: []byte("x\n"), Mode: 0o644},
+	})
+	if err == nil {
+		t.Fatal("expected Apply to fail")
+	}
+	if got := read(t, conf); got != "port=80\n" {
+		t.Errorf("app.conf not undone: %q", got)
+	}
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 2 {
+		t.Errorf("expected no temporary files left, got %v", entries)
+	}
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// adaptive stretches the interval between scheduled runs while they keep
+// finding everything healthy and tightens it when one does not.
+type adaptive struct {
+	min, max time.Duration
+	runs     int
+
+	mu       sync.Mutex
+	interval time.Duration
+	streak   int
+	reason   string
+	changed  time.Time
+}
+
+// AdaptiveState is the adaptive interval's current value and why it has
+// that value.
+type AdaptiveState struct {
+	IntervalSeconds int `json:"interval_seconds"`
+	MinSeconds      int `json:"min_seconds"`
+	MaxSeconds      int `json:"max_seconds"`
+	// HealthyStreak is how many scheduled runs in a row found everything
+	// healthy, and HealthyRuns how many it takes to stretch the interval.
+	HealthyStreak int    `json:"healthy_streak"`
+	HealthyRuns   int    `json:"healthy_runs"`
+	Reason        string `json:"reason"`
+	// ChangedAt is when the interval last changed (nil if it has not).
+	ChangedAt *time.Time `json:"changed_at"`
+}
+
+func newAdaptive(interval, min, max time.Duration, runs int) *adaptive {
+	return &adaptive{
+		min: min, max: max, runs: runs, interval: interval,
+		reason: fmt.Sprintf("starting at %s; stretched after %d healthy runs in a row", formatInterval(interval), runs),
+	}
+}
+
+// observe adjusts the interval for the outcome of a scheduled run that
+// finished at t: healthy reports whether it found everything healthy, and
+// detail says what it found.
+func (a *adaptive) observe(t time.Time, healthy bool, detail string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	prev := a.interval
+	if !healthy {
+		a.streak = 0
+		a.interval = max(a.interval/2, a.min)
+		if a.interval != prev {
+			a.reason = fmt.Sprintf("%s; tightened from %s to %s", detail, formatInterval(prev), formatInterval(a.interval))
+		} else {
+			a.reason = fmt.Sprintf("%s; holding at the minimum %s", detail, formatInterval(a.min))
+		}
+	} else {
+		a.streak++
+		switch {
+		case a.interval >= a.max:
+			a.reason = fmt.Sprintf("healthy for %d runs in a row; holding at the maximum %s", a.streak, formatInterval(a.max))
+		case a.streak%a.runs == 0:
+			a.interval = min(a.interval*2, a.max)
+			a.reason = fmt.Sprintf("healthy for %d runs in a row; stretched from %s to %s", a.streak, formatInterval(prev), formatInterval(a.interval))
+		default:
+			a.reason = fmt.Sprintf("healthy for %d runs in a row; stretched after %d more", a.streak, a.runs-a.streak%a.runs)
+		}
+	}
+	if a.interval != prev {
+		a.changed = t
+	}
+}
+
+func (a *adaptive) current() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.interval
+}
+
+func (a *adaptive) state() AdaptiveState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	st := AdaptiveState{
+		IntervalSeconds: int(a.interval / time.Second),
+		MinSeconds:      int(a.min / time.Second),
+		MaxSeconds:      int(a.max / time.Second),
+		HealthyStreak:   a.streak,
+		HealthyRuns:     a.runs,
+		Reason:          a.reason,
+	}
+	if !a.changed.IsZero() {
+		changed := a.changed.UTC()
+		st.ChangedAt = &changed
+	}
+	return st
+}
+
+// formatInterval formats d without zero trailing units, e.g. "2h" or
+// "1h30m" rather than "2h0m0s".
+func formatInterval(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,152 @@
+// Package scheduler decides when the supervisor's scheduled runs fire:
+// every CLAUDEOPS_INTERVAL seconds after the previous run finishes, or at
+// the times a cron expression (CLAUDEOPS_SCHEDULE) names. Either can be
+// delayed by a random jitter of up to CLAUDEOPS_JITTER seconds, so several
+// instances on the same schedule do not all call the API in the same
+// minute. With CLAUDEOPS_INTERVAL_MAX set the interval adapts to what the
+// runs find: it stretches while everything stays healthy and tightens when
+// something is not.
+package scheduler
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+// Scheduler computes the fire time of the next scheduled run.
+type Scheduler struct {
+	cfg  *config.Config
+	cron *Cron
+	// adaptive is the adaptive interval (nil when disabled).
+	adaptive *adaptive
+	// jitter returns a random delay in [0, max] (replaced in tests).
+	jitter func(max time.Duration) time.Duration
+}
+
+// New returns a Scheduler for cfg.Schedule and cfg.Jitter, and for the
+// adaptive interval when cfg.IntervalMax is set. A fixed interval is read
+// from cfg on each call to Next, so dashboard changes to it apply to the
+// following wait.
+func New(cfg *config.Config) (*Scheduler, error) {
+	if cfg.Jitter < 0 {
+		return nil, fmt.Errorf("jitter must not be negative, got %d", cfg.Jitter)
+	}
+	s := &Scheduler{cfg: cfg, jitter: randomJitter}
+	if cfg.Schedule != "" {
+		c, err := ParseCron(cfg.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		s.cron = c
+	}
+	if cfg.IntervalMax > 0 {
+		a, err := adaptiveFor(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.adaptive = a
+	}
+	return s, nil
+}
+
+// adaptiveFor validates cfg's adaptive interval settings.
+func adaptiveFor(cfg *config.Config) (*adaptive, error) {
+	if cfg.Schedule != "" {
+		return nil, fmt.Errorf("an adaptive interval (interval max) cannot be combined with a cron schedule")
+	}
+	lo := cfg.IntervalMin
+	if lo == 0 {
+		lo = cfg.Interval
+	}
+	if lo <= 0 || lo > cfg.Interval || cfg.Interval > cfg.IntervalMax {
+		return nil, fmt.Errorf("adaptive interval needs 0 < interval min (%ds) <= interval (%ds) <= interval max (%ds)", lo, cfg.Interval, cfg.IntervalMax)
+	}
+	if cfg.AdaptiveRuns < 1 {
+		return nil, fmt.Errorf("adaptive runs must be at least 1, got %d", cfg.AdaptiveRuns)
+	}
+	return newAdaptive(time.Duration(cfg.Interval)*time.Second, time.Duration(lo)*time.Second,
+		time.Duration(cfg.IntervalMax)*time.Second, cfg.AdaptiveRuns), nil
+}
+
+func randomJitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max + 1)
+}
+
+// Next returns when the scheduled run after one that finished at t fires,
+// jitter included. A cron expression that never matches falls back to the
+// interval.
+func (s *Scheduler) Next(t time.Time) time.Time {
+	var next time.Time
+	if s.cron != nil {
+		next = s.cron.Next(t)
+	}
+	if next.IsZero() {
+		next = t.Add(s.Interval())
+	}
+	return next.Add(s.jitter(time.Duration(s.cfg.Jitter) * time.Second))
+}
+
+// Interval returns the time between scheduled runs: the adaptive interval
+// when it is enabled, cfg.Interval otherwise.
+func (s *Scheduler) Interval() time.Duration {
+	if s.adaptive != nil {
+		return s.adaptive.current()
+	}
+	return time.Duration(s.cfg.Interval) * time.Second
+}
+
+// Adaptive reports whether the interval adapts to what scheduled runs find.
+func (s *Scheduler) Adaptive() bool {
+	return s.adaptive != nil
+}
+
+// Observe adjusts the adaptive interval for a scheduled run that finished
+// at t: healthy reports whether it found everything healthy, and detail
+// what it found, for the reason shown on the dashboard. It does nothing
+// when the interval is not adaptive.
+func (s *Scheduler) Observe(t time.Time, healthy bool, detail string) {
+	if s.adaptive != nil {
+		s.adaptive.observe(t, healthy, detail)
+	}
+}
+
+// AdaptiveState returns the adaptive interval's state, or nil when the
+// interval is not adaptive.
+func (s *Scheduler) AdaptiveState() *AdaptiveState {
+	if s.adaptive == nil {
+		return nil
+	}
+	st := s.adaptive.state()
+	return &st
+}
+
+// Cron returns the cron expression runs follow, or "" when they follow the
+// interval.
+func (s *Scheduler) Cron() string {
+	if s.cron == nil {
+		return ""
+	}
+	return s.cron.String()
+}
+
+// String describes the schedule, e.g. "every 3600s" or
+// "cron 0 * * * * (jitter up to 300s)".
+func (s *Scheduler) String() string {
+	d := fmt.Sprintf("every %ds", s.cfg.Interval)
+	if s.adaptive != nil {
+		d = fmt.Sprintf("every %ds, adaptive %ds-%ds", s.cfg.Interval, s.adaptive.min/time.Second, s.adaptive.max/time.Second)
+	}
+	if s.cron != nil {
+		d = "cron " + s.cron.String()
+	}
+	if s.cfg.Jitter > 0 {
+		d += fmt.Sprintf(" (jitter up to %ds)", s.cfg.Jitter)
+	}
+	return d
+}
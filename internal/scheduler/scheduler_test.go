@@ -0,0 +1,181 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		expr, from, want string
+	}{
+		{"*/15 * * * *", "2026-10-18 10:07", "2026-10-18 10:15"},
+		{"*/15 * * * *", "2026-10-18 10:45", "2026-10-18 11:00"},
+		{"@hourly", "2026-10-18 10:00", "2026-10-18 11:00"},
+		{"7 3 * * *", "2026-10-18 04:00", "2026-10-19 03:07"},
+		{"0 9-17/4 * * mon-fri", "2026-10-17 12:00", "2026-10-19 09:00"}, // Saturday -> Monday
+		{"0 0 * * 7", "2026-10-18 00:00", "2026-10-25 00:00"},            // 7 is Sunday
+		{"30 2 1,15 * *", "2026-10-15 02:30", "2026-11-01 02:30"},
+		{"0 0 13 * fri", "2026-10-18 00:00", "2026-10-23 00:00"}, // either day field matches
+		{"0 0 29 feb *", "2026-10-18 00:00", "2028-02-29 00:00"},
+		{"5/20 * * * *", "2026-10-18 10:26", "2026-10-18 10:45"},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := c.Next(at(tt.from)); !got.Equal(at(tt.want)) {
+			t.Errorf("%q from %s: got %s, want %s", tt.expr, tt.from, got.Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+
+	never, err := ParseCron("0 0 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := never.Next(at("2026-10-18 00:00")); !got.IsZero() {
+		t.Errorf("impossible date matched %s", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * smarch *",
+		"@fortnightly",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q): expected an error", expr)
+		}
+	}
+}
+
+func TestSchedulerNext(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 7, 30, 0, time.UTC)
+
+	cfg := &config.Config{Interval: 600}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(now); !got.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("interval: got %s", got)
+	}
+	if s.String() != "every 600s" {
+		t.Errorf("String() = %q", s.String())
+	}
+	cfg.Interval = 60 // dashboard changes apply to the next wait
+	if got := s.Next(now); !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("changed interval: got %s", got)
+	}
+
+	cfg = &config.Config{Interval: 600, Schedule: "*/30 * * * *", Jitter: 120}
+	if s, err = New(cfg); err != nil {
+		t.Fatal(err)
+	}
+	s.jitter = func(max time.Duration) time.Duration {
+		if max != 2*time.Minute {
+			t.Errorf("jitter max = %s", max)
+		}
+		return 45 * time.Second
+	}
+	want := time.Date(2026, 10, 18, 10, 30, 45, 0, time.UTC)
+	if got := s.Next(now); !got.Equal(want) {
+		t.Errorf("cron with jitter: got %s, want %s", got, want)
+	}
+	if s.Cron() != "*/30 * * * *" || s.String() != "cron */30 * * * * (jitter up to 120s)" {
+		t.Errorf("Cron() = %q, String() = %q", s.Cron(), s.String())
+	}
+
+	for i := 0; i < 100; i++ {
+		if d := randomJitter(time.Second); d < 0 || d > time.Second {
+			t.Fatalf("randomJitter out of range: %s", d)
+		}
+	}
+
+	if _, err := New(&config.Config{Schedule: "every hour"}); err == nil {
+		t.Error("expected an invalid cron expression to be rejected")
+	}
+	if _, err := New(&config.Config{Interval: 60, Jitter: -1}); err == nil {
+		t.Error("expected negative jitter to be rejected")
+	}
+}
+
+func TestAdaptiveInterval(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	cfg := &config.Config{Interval: 3600, IntervalMin: 900, IntervalMax: 4 * 3600, AdaptiveRuns: 2}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interval := func() time.Duration { return s.Next(now).Sub(now) }
+
+	s.Observe(now, true, "")
+	if interval() != time.Hour {
+		t.Errorf("stretched after one healthy run: %s", interval())
+	}
+	s.Observe(now, true, "")
+	if st := s.AdaptiveState(); interval() != 2*time.Hour || st.Reason != "healthy for 2 runs in a row; stretched from 1h to 2h" || st.ChangedAt == nil {
+		t.Errorf("after two healthy runs: %s, %+v", interval(), st)
+	}
+	for i := 0; i < 6; i++ {
+		s.Observe(now, true, "")
+	}
+	if st := s.AdaptiveState(); interval() != 4*time.Hour || st.HealthyStreak != 8 || !strings.Contains(st.Reason, "maximum 4h") {
+		t.Errorf("capped at max: %s, %+v", interval(), st)
+	}
+
+	s.Observe(now, false, "session #9 found issues-observed")
+	if st := s.AdaptiveState(); interval() != 2*time.Hour || st.HealthyStreak != 0 || st.Reason != "session #9 found issues-observed; tightened from 4h to 2h" {
+		t.Errorf("after a problem: %s, %+v", interval(), st)
+	}
+	for i := 0; i < 3; i++ {
+		s.Observe(now, false, "session #10 was failed")
+	}
+	if interval() != 15*time.Minute {
+		t.Errorf("floored at min: %s", interval())
+	}
+	cfg.Interval = 60 // the adaptive interval ignores dashboard changes to the fixed one
+	if interval() != 15*time.Minute {
+		t.Errorf("fixed interval change applied: %s", interval())
+	}
+
+	fixed, err := New(&config.Config{Interval: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed.Observe(now, false, "")
+	if fixed.Adaptive() || fixed.AdaptiveState() != nil || fixed.Interval() != 10*time.Minute {
+		t.Error("fixed interval adapted")
+	}
+
+	for _, bad := range []config.Config{
+		{Interval: 3600, IntervalMax: 1800, AdaptiveRuns: 3},
+		{Interval: 3600, IntervalMin: 7200, IntervalMax: 9000, AdaptiveRuns: 3},
+		{Interval: 3600, IntervalMax: 7200},
+		{Interval: 3600, IntervalMax: 7200, AdaptiveRuns: 3, Schedule: "@hourly"},
+	} {
+		if _, err := New(&bad); err == nil {
+			t.Errorf("accepted %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package seal encrypts sensitive text at rest: session responses,
+// summaries, and prompts in the database, and the lines of session logs,
+// so a copy of the database or the results directory is not a map of the
+// infrastructure. Sealed text is "sealed:v1:" followed by the base64 of an
+// AES-256-GCM nonce and ciphertext. Text without that prefix, stored before
+// encryption was turned on, is read as is.
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+const prefix = "sealed:v1:"
+
+// KeySize is the length of an encryption key in bytes (AES-256).
+const KeySize = 32
+
+// Unreadable replaces sealed text that cannot be opened because no key or
+// a different key is configured.
+const Unreadable = "[encrypted: configure the encryption key to read this]"
+
+// ErrNoKey is returned when opening sealed text without a key.
+var ErrNoKey = errors.New("text is encrypted but no encryption key is configured")
+
+// Sealer seals and opens text with one key. A nil *Sealer leaves text it
+// seals in plaintext, so callers need not check whether encryption is on.
+type Sealer struct {
+	aead cipher.AEAD
+	// hashKey keys Hash, derived from the key so that the hashes do not
+	// reuse it.
+	hashKey []byte
+}
+
+// New returns a Sealer for a KeySize-byte key.
+func New(key []byte) (*Sealer, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead, hashKey: mac(key, "claude-ops hash key")}, nil
+}
+
+// FromConfig builds a Sealer from CLAUDEOPS_ENCRYPTION_KEY or, if that is
+// not set, the file named by CLAUDEOPS_ENCRYPTION_KEY_FILE. It returns nil
+// when neither is set, and an error when the key is not valid.
+func FromConfig(cfg *config.Config) (*Sealer, error) {
+	switch {
+	case cfg.EncryptionKey != "":
+		key, err := ParseKey([]byte(cfg.EncryptionKey))
+		if err != nil {
+			return nil, fmt.Errorf("CLAUDEOPS_ENCRYPTION_KEY: %w", err)
+		}
+		return New(key)
+	case cfg.EncryptionKeyFile != "":
+		data, err := os.ReadFile(cfg.EncryptionKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read encryption key file: %w", err)
+		}
+		key, err := ParseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.EncryptionKeyFile, err)
+		}
+		return New(key)
+	}
+	return nil, nil
+}
+
+// ParseKey decodes a key given as base64 or hex, or as KeySize raw bytes
+// (as read from a key file).
+func ParseKey(data []byte) ([]byte, error) {
+	if len(data) == KeySize {
+		return data, nil
+	}
+	s := strings.TrimSpace(string(data))
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key must be %d bytes, base64 or hex encoded (e.g. from `openssl rand -base64 32`)", KeySize)
+}
+
+// IsSealed reports whether text was sealed.
+func IsSealed(text string) bool {
+	return strings.HasPrefix(text, prefix)
+}
+
+// Seal encrypts text. A nil Sealer returns it unchanged.
+func (s *Sealer) Seal(text string) string {
+	if s == nil {
+		return text
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	_, _ = rand.Read(nonce) // crypto/rand.Read does not fail
+	return prefix + base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, []byte(text), nil))
+}
+
+// SealPtr seals the text p points to, if any.
+func (s *Sealer) SealPtr(p *string) *string {
+	if p == nil || s == nil {
+		return p
+	}
+	sealed := s.Seal(*p)
+	return &sealed
+}
+
+// Hash returns a hex HMAC-SHA256 of text, for finding sealed text by
+// equality without storing it in plaintext. A nil Sealer returns the plain
+// SHA-256 of text, which hides nothing but matches text stored unsealed.
+func (s *Sealer) Hash(text string) string {
+	if s == nil {
+		sum := sha256.Sum256([]byte(text))
+		return hex.EncodeToString(sum[:])
+	}
+	return hex.EncodeToString(mac(s.hashKey, text))
+}
+
+func mac(key []byte, text string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(text))
+	return h.Sum(nil)
+}
+
+// Open decrypts sealed text. Text that was not sealed is returned as is.
+func (s *Sealer) Open(text string) (string, error) {
+	if !IsSealed(text) {
+		return text, nil
+	}
+	if s == nil {
+		return "", ErrNoKey
+	}
+	data, err := base64.StdEncoding.DecodeString(text[len(prefix):])
+	if err != nil {
+		return "", fmt.Errorf("decode sealed text: %w", err)
+	}
+	n := s.aead.NonceSize()
+	if len(data) < n {
+		return "", errors.New("decode sealed text: too short")
+	}
+	plain, err := s.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return "", fmt.Errorf("open sealed text: %w", err)
+	}
+	return string(plain), nil
+}
+
+// OpenOr decrypts sealed text, returning Unreadable when it cannot be
+// opened, for readers that show the text rather than act on it.
+func (s *Sealer) OpenOr(text string) string {
+	plain, err := s.Open(text)
+	if err != nil {
+		return Unreadable
+	}
+	return plain
+}
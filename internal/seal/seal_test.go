@@ -0,0 +1,122 @@
+package seal
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+func testKey(b byte) []byte {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = b
+	}
+	return key
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	s, err := New(testKey(1))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	sealed := s.Seal("postgres on db01.internal is down")
+	if !IsSealed(sealed) || strings.Contains(sealed, "db01") {
+		t.Fatalf("Seal = %q", sealed)
+	}
+	if s.Seal("same") == s.Seal("same") {
+		t.Error("sealing the same text twice gave the same output")
+	}
+	plain, err := s.Open(sealed)
+	if err != nil || plain != "postgres on db01.internal is down" {
+		t.Errorf("Open = %q, %v", plain, err)
+	}
+	if plain, err := s.Open("written before encryption"); err != nil || plain != "written before encryption" {
+		t.Errorf("Open(plaintext) = %q, %v", plain, err)
+	}
+
+	other, _ := New(testKey(2))
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("opened with the wrong key")
+	}
+	if got := other.OpenOr(sealed); got != Unreadable {
+		t.Errorf("OpenOr with the wrong key = %q", got)
+	}
+}
+
+func TestNilSealer(t *testing.T) {
+	var s *Sealer
+	if got := s.Seal("text"); got != "text" {
+		t.Errorf("nil Seal = %q", got)
+	}
+	text := "text"
+	if got := s.SealPtr(&text); got != &text {
+		t.Error("nil SealPtr changed the pointer")
+	}
+	sealed, _ := New(testKey(1))
+	if _, err := s.Open(sealed.Seal("text")); !errors.Is(err, ErrNoKey) {
+		t.Errorf("nil Open of sealed text: err = %v", err)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	key := testKey(3)
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name    string
+		cfg     config.Config
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "none", wantNil: true},
+		{name: "base64", cfg: config.Config{EncryptionKey: base64.StdEncoding.EncodeToString(key)}},
+		{name: "hex file", cfg: config.Config{EncryptionKeyFile: keyFile}},
+		{name: "short", cfg: config.Config{EncryptionKey: "c2hvcnQ="}, wantErr: true},
+		{name: "missing file", cfg: config.Config{EncryptionKeyFile: keyFile + ".missing"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := FromConfig(&tc.cfg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if tc.wantErr {
+				return
+			}
+			if (s == nil) != tc.wantNil {
+				t.Fatalf("sealer = %v", s)
+			}
+			if s != nil {
+				want, _ := New(key)
+				if plain, err := want.Open(s.Seal("x")); err != nil || plain != "x" {
+					t.Errorf("key mismatch: %q, %v", plain, err)
+				}
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	a, _ := New(testKey(1))
+	b, _ := New(testKey(2))
+	if a.Hash("restart jellyfin") != a.Hash("restart jellyfin") {
+		t.Error("Hash is not deterministic")
+	}
+	if a.Hash("restart jellyfin") == a.Hash("restart sonarr") {
+		t.Error("different text hashed the same")
+	}
+	if a.Hash("restart jellyfin") == b.Hash("restart jellyfin") {
+		t.Error("Hash does not depend on the key")
+	}
+	var none *Sealer
+	if h := none.Hash("restart jellyfin"); h == a.Hash("restart jellyfin") || len(h) != 64 {
+		t.Errorf("nil Sealer Hash = %q", h)
+	}
+}
@@ -0,0 +1,119 @@
+// Package servicename validates and canonicalises the service names agents
+// write in markers and structured output. Agents name the same service
+// "Jellyfin", "jellyfin", or "jellyfin-container" from one run to the next,
+// which splits its events, memories, and cooldowns across several names.
+// Names are case-folded and then mapped through the operator's aliases from
+// CLAUDEOPS_SERVICE_ALIASES and the aliases recorded by service merges.
+package servicename
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// maxLen bounds a service name.
+const maxLen = 64
+
+// validRe matches a folded service name.
+var validRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// Normalizer maps service names to their canonical form. A nil Normalizer
+// only case-folds.
+type Normalizer struct {
+	aliases map[string]string // folded alias -> canonical name
+	db      *db.DB            // optional; merged aliases, consulted after aliases
+}
+
+// ParseAliases parses a comma-separated list of alias=canonical pairs, e.g.
+// "jellyfin-container=jellyfin,jf=jellyfin". Both sides are folded.
+func ParseAliases(spec string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		alias, canonical, ok := strings.Cut(part, "=")
+		alias, canonical = Fold(alias), Fold(canonical)
+		if !ok || !valid(alias) || !valid(canonical) {
+			return nil, fmt.Errorf("invalid service alias %q: want alias=service", part)
+		}
+		if alias != canonical {
+			aliases[alias] = canonical
+		}
+	}
+	// Resolve chains (a=b, b=c) so every alias points at a name that is not
+	// itself an alias.
+	for alias := range aliases {
+		seen := map[string]bool{alias: true}
+		target := aliases[alias]
+		for next, ok := aliases[target]; ok; next, ok = aliases[target] {
+			if seen[next] {
+				return nil, fmt.Errorf("service alias cycle through %q", alias)
+			}
+			seen[target] = true
+			target = next
+		}
+		aliases[alias] = target
+	}
+	return aliases, nil
+}
+
+// FromConfig builds a Normalizer from CLAUDEOPS_SERVICE_ALIASES and the
+// merged aliases in database, which may be nil. Invalid configured aliases
+// are logged and ignored.
+func FromConfig(cfg *config.Config, database *db.DB) *Normalizer {
+	aliases, err := ParseAliases(cfg.ServiceAliases)
+	if err != nil {
+		log.Printf("servicename: %v (aliases ignored)", err)
+		aliases = nil
+	}
+	return New(aliases, database)
+}
+
+// New creates a Normalizer with aliases as returned by ParseAliases.
+// database may be nil.
+func New(aliases map[string]string, database *db.DB) *Normalizer {
+	return &Normalizer{aliases: aliases, db: database}
+}
+
+// Normalize returns the canonical form of name and whether it is a valid
+// service name. Invalid names (empty, too long, or containing characters
+// other than letters, digits, '.', '_', and '-') are returned folded.
+func (n *Normalizer) Normalize(name string) (string, bool) {
+	name = Fold(name)
+	if !valid(name) {
+		return name, false
+	}
+	if n == nil {
+		return name, true
+	}
+	if canonical, ok := n.aliases[name]; ok {
+		return canonical, true
+	}
+	if n.db != nil {
+		canonical, err := n.db.GetServiceAlias(name)
+		if err != nil {
+			log.Printf("servicename: %v", err)
+		}
+		if canonical != "" {
+			return canonical, true
+		}
+	}
+	return name, true
+}
+
+// Fold lower-cases name and joins whitespace-separated words with '-', so
+// "Home Assistant" becomes "home-assistant".
+func Fold(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), "-"))
+}
+
+func valid(name string) bool {
+	return len(name) <= maxLen && validRe.MatchString(name)
+}
@@ -0,0 +1,59 @@
+package servicename
+
+import (
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+func TestParseAliases(t *testing.T) {
+	aliases, err := ParseAliases(" Jellyfin-Container=jellyfin, jf = jellyfin-container ,pg=Postgres,")
+	if err != nil {
+		t.Fatalf("ParseAliases: %v", err)
+	}
+	want := map[string]string{"jellyfin-container": "jellyfin", "jf": "jellyfin", "pg": "postgres"}
+	if len(aliases) != len(want) {
+		t.Fatalf("aliases = %v, want %v", aliases, want)
+	}
+	for k, v := range want {
+		if aliases[k] != v {
+			t.Errorf("aliases[%q] = %q, want %q", k, aliases[k], v)
+		}
+	}
+
+	for _, bad := range []string{"jellyfin", "=jellyfin", "jf=", "a=b,b=a", "jf=jelly/fin"} {
+		if _, err := ParseAliases(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	n := FromConfig(&config.Config{ServiceAliases: "jellyfin-container=jellyfin"}, nil)
+	for _, tc := range []struct {
+		in, want string
+		ok       bool
+	}{
+		{"Jellyfin", "jellyfin", true},
+		{"  JELLYFIN-Container ", "jellyfin", true},
+		{"Home Assistant", "home-assistant", true},
+		{"postgres_16", "postgres_16", true},
+		{"", "", false},
+		{"-bad", "-bad", false},
+		{"a/b", "a/b", false},
+	} {
+		got, ok := n.Normalize(tc.in)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("Normalize(%q) = %q, %v; want %q, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+
+	// Invalid aliases are ignored; case-folding still applies.
+	if got, _ := FromConfig(&config.Config{ServiceAliases: "bogus"}, nil).Normalize("Caddy"); got != "caddy" {
+		t.Errorf("Normalize with invalid aliases = %q", got)
+	}
+	var nilN *Normalizer
+	if got, ok := nilN.Normalize("Caddy"); got != "caddy" || !ok {
+		t.Errorf("nil Normalize = %q, %v", got, ok)
+	}
+}
@@ -0,0 +1,225 @@
+package session
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ansiColorNames are the 8 standard terminal colors, in SGR order. Bright
+// variants use the "bright-" prefix; both map to CSS classes in style.css.
+var ansiColorNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ansiState is the SGR attributes in effect for a run of text.
+type ansiState struct {
+	fg, bg                       string // class suffix or "#rrggbb"
+	bold, dim, italic, underline bool
+}
+
+// span returns the opening tag for the state, or "" for plain text.
+func (st ansiState) span() string {
+	var classes, styles []string
+	color := func(prop, prefix, c string) {
+		if strings.HasPrefix(c, "#") {
+			styles = append(styles, prop+":"+c)
+		} else if c != "" {
+			classes = append(classes, prefix+c)
+		}
+	}
+	color("color", "ansi-", st.fg)
+	color("background-color", "ansi-bg-", st.bg)
+	for _, a := range []struct {
+		on  bool
+		cls string
+	}{{st.bold, "ansi-bold"}, {st.dim, "ansi-dim"}, {st.italic, "ansi-italic"}, {st.underline, "ansi-underline"}} {
+		if a.on {
+			classes = append(classes, a.cls)
+		}
+	}
+	if len(classes) == 0 && len(styles) == 0 {
+		return ""
+	}
+	tag := `<span class="ansi`
+	if len(classes) > 0 {
+		tag += " " + strings.Join(classes, " ")
+	}
+	tag += `"`
+	if len(styles) > 0 {
+		tag += ` style="` + strings.Join(styles, ";") + `"`
+	}
+	return tag + ">"
+}
+
+// ansiToHTML converts text containing ANSI escape sequences to escaped HTML,
+// rendering SGR color and style codes as spans and dropping all other
+// control sequences (cursor movement, erase, and sequences cut off by
+// truncation). Used for the web terminal; stdout uses stripANSI.
+func ansiToHTML(s string) string {
+	var out strings.Builder
+	out.Grow(len(s))
+	var st ansiState
+	open := false
+	text := 0 // start of the pending plain-text run
+
+	flushText := func(end int) {
+		if end > text {
+			out.WriteString(htmlEscape(s[text:end]))
+		}
+	}
+
+	i := 0
+	for i < len(s) {
+		if s[i] != '\x1b' {
+			i++
+			continue
+		}
+		flushText(i)
+		if i+1 >= len(s) || s[i+1] != '[' {
+			// Lone ESC or a non-CSI sequence: drop the ESC byte.
+			i++
+			text = i
+			continue
+		}
+		j := i + 2
+		for j < len(s) && s[j] >= 0x20 && s[j] <= 0x3F {
+			j++ // parameter bytes
+		}
+		if j >= len(s) {
+			// Truncated sequence: drop the remainder.
+			i, text = len(s), len(s)
+			break
+		}
+		final := s[j]
+		params := s[i+2 : j]
+		i = j + 1
+		text = i
+		if final != 'm' {
+			continue
+		}
+		next := applySGR(st, params)
+		if next == st {
+			continue
+		}
+		if open {
+			out.WriteString("</span>")
+			open = false
+		}
+		st = next
+		if tag := st.span(); tag != "" {
+			out.WriteString(tag)
+			open = true
+		}
+	}
+	flushText(len(s))
+	if open {
+		out.WriteString("</span>")
+	}
+	return out.String()
+}
+
+// applySGR returns the state after a "Select Graphic Rendition" parameter
+// list such as "1;31" or "38;5;208".
+func applySGR(st ansiState, params string) ansiState {
+	if params == "" {
+		return ansiState{}
+	}
+	codes := strings.Split(params, ";")
+	for k := 0; k < len(codes); k++ {
+		n, err := strconv.Atoi(codes[k])
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			st = ansiState{}
+		case n == 1:
+			st.bold = true
+		case n == 2:
+			st.dim = true
+		case n == 3:
+			st.italic = true
+		case n == 4:
+			st.underline = true
+		case n == 22:
+			st.bold, st.dim = false, false
+		case n == 23:
+			st.italic = false
+		case n == 24:
+			st.underline = false
+		case n >= 30 && n <= 37:
+			st.fg = ansiColorNames[n-30]
+		case n >= 90 && n <= 97:
+			st.fg = "bright-" + ansiColorNames[n-90]
+		case n == 39:
+			st.fg = ""
+		case n >= 40 && n <= 47:
+			st.bg = ansiColorNames[n-40]
+		case n >= 100 && n <= 107:
+			st.bg = "bright-" + ansiColorNames[n-100]
+		case n == 49:
+			st.bg = ""
+		case n == 38 || n == 48:
+			c, used := extendedColor(codes[k+1:])
+			k += used
+			if n == 38 {
+				st.fg = c
+			} else {
+				st.bg = c
+			}
+		}
+	}
+	return st
+}
+
+// extendedColor parses the arguments after 38/48: "5;n" (256-color palette)
+// or "2;r;g;b" (truecolor). It returns the color and how many codes it used.
+func extendedColor(args []string) (string, int) {
+	num := func(k int) int {
+		if k >= len(args) {
+			return -1
+		}
+		v, err := strconv.Atoi(args[k])
+		if err != nil || v < 0 || v > 255 {
+			return -1
+		}
+		return v
+	}
+	switch num(0) {
+	case 5:
+		n := num(1)
+		if n < 0 {
+			return "", min(len(args), 2)
+		}
+		return xterm256(n), 2
+	case 2:
+		r, g, b := num(1), num(2), num(3)
+		if r < 0 || g < 0 || b < 0 {
+			return "", min(len(args), 4)
+		}
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b), 4
+	}
+	return "", min(len(args), 1)
+}
+
+// xterm256 maps a 256-color palette index to a class suffix (for the 16
+// standard colors) or a hex color.
+func xterm256(n int) string {
+	switch {
+	case n < 8:
+		return ansiColorNames[n]
+	case n < 16:
+		return "bright-" + ansiColorNames[n-8]
+	case n < 232:
+		n -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	default:
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+}
@@ -0,0 +1,43 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnsiToHTML(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "a < b", "a &lt; b"},
+		{"basic color", "\x1b[32mUp\x1b[0m 2 hours", `<span class="ansi ansi-green">Up</span> 2 hours`},
+		{"bold bright", "\x1b[1;91mfailed\x1b[m", `<span class="ansi ansi-bright-red ansi-bold">failed</span>`},
+		{"state carries", "\x1b[1mA\x1b[31mB\x1b[22mC\x1b[39mD", `<span class="ansi ansi-bold">A</span><span class="ansi ansi-red ansi-bold">B</span><span class="ansi ansi-red">C</span>D`},
+		{"background", "\x1b[30;43mWARN\x1b[0m", `<span class="ansi ansi-black ansi-bg-yellow">WARN</span>`},
+		{"256 standard", "\x1b[38;5;9mx", `<span class="ansi ansi-bright-red">x</span>`},
+		{"256 cube", "\x1b[38;5;208mx", `<span class="ansi" style="color:#ff8700">x</span>`},
+		{"truecolor bg", "\x1b[48;2;1;2;3mx", `<span class="ansi" style="background-color:#010203">x</span>`},
+		{"non-SGR dropped", "\x1b[2K\x1b[1Gdone", "done"},
+		{"unclosed span closed", "\x1b[34mblue", `<span class="ansi ansi-blue">blue</span>`},
+		{"truncated sequence", "ok\x1b[3", "ok"},
+		{"lone escape", "a\x1bb", "ab"},
+		{"escaped inside span", "\x1b[31m<err>\x1b[0m", `<span class="ansi ansi-red">&lt;err&gt;</span>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ansiToHTML(tt.in); got != tt.want {
+				t.Errorf("ansiToHTML(%q)\n got %s\nwant %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatStreamEvent_ToolResultANSI(t *testing.T) {
+	raw := `{"type":"user","message":{"content":[{"type":"tool_result","content":"\u001b[32mactive (running)\u001b[0m"}]}}`
+	if got := FormatStreamEventHTML(raw); !strings.Contains(got, `<span class="ansi ansi-green">active (running)</span>`) {
+		t.Errorf("expected colors preserved in HTML, got %q", got)
+	}
+	if got := FormatStreamEvent(raw); got != "[result] active (running)" {
+		t.Errorf("expected colors stripped from plain text, got %q", got)
+	}
+}
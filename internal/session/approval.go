@@ -0,0 +1,211 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// requiredApprovers is how many distinct operators must approve a Tier 3
+// remediation of a two-person service.
+const requiredApprovers = 2
+
+// TwoPersonTag marks a service as needing two-person approval in the
+// service catalog: a Proxmox guest with this tag gates its service as if it
+// were listed in CLAUDEOPS_TWO_PERSON_SERVICES.
+const TwoPersonTag = "two-person"
+
+// twoPersonServices returns the services in affected that need two-person
+// approval: those listed in the config and those whose Proxmox guest is
+// tagged TwoPersonTag. An unreachable hypervisor leaves only the listed
+// services gated.
+func (m *Manager) twoPersonServices(ctx context.Context, affected []string) []string {
+	if len(affected) == 0 {
+		return nil
+	}
+	names := m.twoPersonNames(ctx)
+	var gated []string
+	for _, svc := range affected {
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name != "" && strings.EqualFold(name, svc) {
+				gated = append(gated, svc)
+				break
+			}
+		}
+	}
+	return gated
+}
+
+// promptTwoPersonServices returns the services needing two-person approval
+// that prompt names, for a chain started at Tier 3 without a handoff to
+// name the affected services.
+func (m *Manager) promptTwoPersonServices(ctx context.Context, prompt string) []string {
+	var canonical []string
+	for _, name := range m.twoPersonNames(ctx) {
+		if c, ok := m.services.Normalize(strings.TrimSpace(name)); ok {
+			canonical = append(canonical, c)
+		}
+	}
+	return m.promptServices(prompt, canonical)
+}
+
+// twoPersonNames lists the services needing two-person approval, as
+// configured or tagged, possibly with blanks and duplicates.
+func (m *Manager) twoPersonNames(ctx context.Context) []string {
+	names := strings.Split(m.cfg.TwoPersonServices, ",")
+	if m.proxmox != nil {
+		guests, err := m.proxmox.Inventory(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "two-person services: proxmox inventory: %v\n", err)
+		}
+		for _, g := range guests {
+			if g.HasTag(TwoPersonTag) {
+				names = append(names, g.ServiceName())
+			}
+		}
+	}
+	return names
+}
+
+// requestApproval holds the escalation step next, recording an approval
+// request for it instead of running it. sessionID is the session that asked
+// to escalate, or 0 for a chain started at Tier 3, which has none.
+func (m *Manager) requestApproval(ctx context.Context, sessionID int64, next ChainStart, gated []string) {
+	chain, err := json.Marshal(next)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: marshal approval chain: %v\n", sessionID, err)
+		return
+	}
+	ttl := m.cfg.ApprovalTTL
+	if ttl <= 0 {
+		ttl = 60
+	}
+	now := time.Now().UTC()
+	services := strings.Join(gated, ", ")
+	id, err := m.db.InsertApprovalRequest(&db.ApprovalRequest{
+		SessionID: sessionID,
+		Tier:      next.Tier,
+		Services:  services,
+		Reason:    escalationReason(next.Context),
+		Chain:     string(chain),
+		Required:  requiredApprovers,
+		CreatedAt: now.Format(time.RFC3339),
+		ExpiresAt: now.Add(time.Duration(ttl) * time.Minute).Format(time.RFC3339),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: insert approval request: %v\n", sessionID, err)
+		m.emitEscalationEventLevel(sessionID, "critical", fmt.Sprintf(
+			"Tier %d remediation of %s needs two-person approval, but the request could not be recorded: %v", next.Tier, services, err))
+		return
+	}
+	msg := fmt.Sprintf("Tier %d remediation of %s is waiting for approval from %d operators (request #%d, expires in %d minutes)",
+		next.Tier, services, requiredApprovers, id, ttl)
+	m.emitEscalationEventLevel(sessionID, "warning", msg)
+	fmt.Printf("[%s] %s\n", now.Format(time.RFC3339), msg)
+	if err := m.notify(ctx, "Claude Ops: Approval needed for "+services, msg); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: approval notification: %v\n", sessionID, err)
+	}
+}
+
+// ApproveRemediation records approver's approval of a held remediation.
+// Once enough distinct operators have approved, the held escalation step is
+// queued to run. Approving twice counts once.
+func (m *Manager) ApproveRemediation(id int64, approver string) error {
+	approver = strings.TrimSpace(approver)
+	if approver == "" {
+		return fmt.Errorf("approver name is required")
+	}
+	if m.Draining() {
+		return fmt.Errorf("shutting down")
+	}
+	m.ExpireApprovals()
+	ar, err := m.db.GetApprovalRequest(id)
+	if err != nil {
+		return err
+	}
+	if ar == nil {
+		return fmt.Errorf("no approval request #%d", id)
+	}
+	n, err := m.db.AddApproval(id, approver, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	if n < ar.Required {
+		m.emitEscalationEventLevel(ar.SessionID, "info", fmt.Sprintf(
+			"%s approved Tier %d remediation of %s (request #%d, %d of %d)", approver, ar.Tier, ar.Services, id, n, ar.Required))
+		return nil
+	}
+
+	var start ChainStart
+	if err := json.Unmarshal([]byte(ar.Chain), &start); err != nil {
+		return fmt.Errorf("parse approval request #%d: %w", id, err)
+	}
+	start.ApprovalID = id
+	if err := m.db.ResolveApprovalRequest(id, "approved", approver, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	select {
+	case m.approvedCh <- start:
+	default:
+		// Leave the request pending so the approval can be retried once
+		// the queue drains.
+		if err := m.db.ReopenApprovalRequest(id); err != nil {
+			fmt.Fprintf(os.Stderr, "reopen approval request #%d: %v\n", id, err)
+		}
+		return fmt.Errorf("trigger queue full")
+	}
+	m.emitEscalationEventLevel(ar.SessionID, "info", fmt.Sprintf(
+		"Tier %d remediation of %s approved by %s (request #%d); starting", ar.Tier, ar.Services, strings.Join(append(ar.Approvers, approver), ", "), id))
+	return nil
+}
+
+// RejectRemediation rejects a held remediation; the escalation chain ends.
+func (m *Manager) RejectRemediation(id int64, approver string) error {
+	approver = strings.TrimSpace(approver)
+	if approver == "" {
+		return fmt.Errorf("approver name is required")
+	}
+	m.ExpireApprovals()
+	ar, err := m.db.GetApprovalRequest(id)
+	if err != nil {
+		return err
+	}
+	if ar == nil {
+		return fmt.Errorf("no approval request #%d", id)
+	}
+	if err := m.db.ResolveApprovalRequest(id, "rejected", approver, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	m.emitEscalationEventLevel(ar.SessionID, "warning", fmt.Sprintf(
+		"%s rejected Tier %d remediation of %s (request #%d)", approver, ar.Tier, ar.Services, id))
+	return nil
+}
+
+// ExpireApprovals closes approval requests that ran out of time.
+func (m *Manager) ExpireApprovals() {
+	expired, err := m.db.ExpireApprovalRequests(time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "expire approval requests: %v\n", err)
+		return
+	}
+	for _, ar := range expired {
+		m.emitEscalationEventLevel(ar.SessionID, "warning", fmt.Sprintf(
+			"Approval request #%d for Tier %d remediation of %s expired with %d of %d approvals", ar.ID, ar.Tier, ar.Services, len(ar.Approvers), ar.Required))
+	}
+}
+
+// escalationReason returns the reason line of an escalation context, or ""
+// if it has none.
+func escalationReason(escalationCtx string) string {
+	for _, line := range strings.Split(escalationCtx, "\n") {
+		if reason, ok := strings.CutPrefix(strings.TrimSpace(line), "**Reason:**"); ok {
+			return strings.TrimSpace(reason)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,214 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/proxmox"
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestTwoPersonApprovalGate(t *testing.T) {
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Postgres is down.", testkit.Escalate("postgres refuses connections", "postgres")),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Replica is corrupt.", testkit.Escalate("replica needs a rebuild", "postgres")),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Rebuilt the replica.", testkit.Healthy("postgres rebuilt", "postgres")),
+		}},
+	)
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.cfg.Tier3Prompt = "/dev/null"
+	m.cfg.TwoPersonServices = "jellyfin, Postgres"
+	m.runner = runner
+	var notified []string
+	m.notify = func(_ context.Context, title, _ string) error {
+		notified = append(notified, title)
+		return nil
+	}
+
+	m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+	if n := len(runner.Calls()); n != 2 {
+		t.Fatalf("expected tier 3 to be held, got %d sessions", n)
+	}
+	pending, err := database.ListApprovalRequests("pending", 10)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected one pending request, got %+v (%v)", pending, err)
+	}
+	ar := pending[0]
+	if ar.Tier != 3 || ar.Services != "postgres" || ar.Reason != "replica needs a rebuild" || ar.Required != 2 {
+		t.Errorf("unexpected request %+v", ar)
+	}
+	if len(notified) != 1 {
+		t.Errorf("expected an approval notification, got %v", notified)
+	}
+
+	if err := m.ApproveRemediation(ar.ID, " "); err == nil {
+		t.Error("expected an anonymous approval to be refused")
+	}
+	for _, approver := range []string{"alice", "alice"} {
+		if err := m.ApproveRemediation(ar.ID, approver); err != nil {
+			t.Fatalf("ApproveRemediation(%s): %v", approver, err)
+		}
+	}
+	select {
+	case <-m.approvedCh:
+		t.Fatal("one approver approving twice must not release the remediation")
+	default:
+	}
+
+	if err := m.ApproveRemediation(ar.ID, "bob"); err != nil {
+		t.Fatalf("ApproveRemediation(bob): %v", err)
+	}
+	if err := m.RejectRemediation(ar.ID, "carol"); err == nil {
+		t.Error("expected rejecting an approved request to fail")
+	}
+	start := <-m.approvedCh
+	if start.Tier != 3 || start.ApprovalID != ar.ID || start.ParentID == nil || *start.ParentID != ar.SessionID {
+		t.Fatalf("unexpected approved chain %+v", start)
+	}
+
+	m.runChain(context.Background(), start)
+	calls := runner.Calls()
+	if len(calls) != 3 || calls[2].Model != "opus" {
+		t.Fatalf("expected the approved tier 3 session to run, got %+v", calls)
+	}
+	got, err := database.GetApprovalRequest(ar.ID)
+	if err != nil || got.Status != "approved" || got.ResolvedBy == nil || *got.ResolvedBy != "bob" || len(got.Approvers) != 2 {
+		t.Errorf("unexpected resolved request %+v (%v)", got, err)
+	}
+}
+
+func TestTwoPersonApprovalRejectAndExpire(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.TwoPersonServices = "postgres"
+	m.notify = func(context.Context, string, string) error { return nil }
+	parent := int64(1)
+	next := ChainStart{Tier: 3, Trigger: "escalation", Services: []string{"postgres"}, ParentID: &parent}
+
+	m.requestApproval(context.Background(), parent, next, []string{"postgres"})
+	m.cfg.ApprovalTTL = -1 // falls back to the default
+	m.requestApproval(context.Background(), parent, next, []string{"postgres"})
+	pending, _ := database.ListApprovalRequests("pending", 10)
+	if len(pending) != 2 {
+		t.Fatalf("expected two pending requests, got %+v", pending)
+	}
+
+	if err := m.RejectRemediation(pending[0].ID, "alice"); err != nil {
+		t.Fatalf("RejectRemediation: %v", err)
+	}
+	if err := m.ApproveRemediation(pending[0].ID, "bob"); err == nil {
+		t.Error("expected approving a rejected request to fail")
+	}
+
+	if _, err := database.Conn().Exec(`UPDATE approval_requests SET expires_at = '2000-01-01T00:00:00Z' WHERE id = ?`, pending[1].ID); err != nil {
+		t.Fatalf("backdate request: %v", err)
+	}
+	if err := m.ApproveRemediation(pending[1].ID, "bob"); err == nil {
+		t.Error("expected approving an expired request to fail")
+	}
+	if ar, _ := database.GetApprovalRequest(pending[1].ID); ar == nil || ar.Status != "expired" {
+		t.Errorf("expected the request to expire, got %+v", ar)
+	}
+}
+
+func TestTwoPersonApprovalGateOnDirectTier3(t *testing.T) {
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Checked jellyfin.", testkit.Healthy("jellyfin ok", "jellyfin")),
+		}},
+	)
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier3Prompt = "/dev/null"
+	m.cfg.TwoPersonServices = "postgres"
+	m.runner = runner
+	m.notify = func(context.Context, string, string) error { return nil }
+
+	prompt := "rebuild the postgres replica"
+	m.runEscalationChain(context.Background(), "manual", &prompt, 3, "", nil)
+	if n := len(runner.Calls()); n != 0 {
+		t.Fatalf("expected the tier 3 session to be held, got %d sessions", n)
+	}
+	pending, err := database.ListApprovalRequests("pending", 10)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected one pending request, got %+v (%v)", pending, err)
+	}
+	if ar := pending[0]; ar.Tier != 3 || ar.Services != "postgres" {
+		t.Errorf("unexpected request %+v", ar)
+	}
+
+	prompt = "restart jellyfin"
+	m.runEscalationChain(context.Background(), "manual", &prompt, 3, "", nil)
+	if n := len(runner.Calls()); n != 1 {
+		t.Errorf("expected an ungated tier 3 prompt to run, got %d sessions", n)
+	}
+}
+
+func TestApproveRemediationQueueFull(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.TwoPersonServices = "postgres"
+	m.notify = func(context.Context, string, string) error { return nil }
+	parent := int64(1)
+	next := ChainStart{Tier: 3, Trigger: "escalation", Services: []string{"postgres"}, ParentID: &parent}
+	m.requestApproval(context.Background(), parent, next, []string{"postgres"})
+	pending, _ := database.ListApprovalRequests("pending", 10)
+	if len(pending) != 1 {
+		t.Fatalf("expected one pending request, got %+v", pending)
+	}
+	id := pending[0].ID
+
+	for len(m.approvedCh) < cap(m.approvedCh) {
+		m.approvedCh <- ChainStart{}
+	}
+	if err := m.ApproveRemediation(id, "alice"); err != nil {
+		t.Fatalf("ApproveRemediation(alice): %v", err)
+	}
+	if err := m.ApproveRemediation(id, "bob"); err == nil {
+		t.Fatal("expected a full trigger queue to refuse the approval")
+	}
+	if ar, _ := database.GetApprovalRequest(id); ar == nil || ar.Status != "pending" || ar.ResolvedBy != nil {
+		t.Fatalf("expected the request to stay pending, got %+v", ar)
+	}
+
+	for len(m.approvedCh) > 0 {
+		<-m.approvedCh
+	}
+	if err := m.ApproveRemediation(id, "bob"); err != nil {
+		t.Fatalf("retry ApproveRemediation(bob): %v", err)
+	}
+	if start := <-m.approvedCh; start.ApprovalID != id || start.Tier != 3 {
+		t.Errorf("unexpected approved chain %+v", start)
+	}
+	if ar, _ := database.GetApprovalRequest(id); ar == nil || ar.Status != "approved" {
+		t.Errorf("expected the request to be approved, got %+v", ar)
+	}
+}
+
+func TestTwoPersonServicesFromCatalogTags(t *testing.T) {
+	m, _ := testManager(t)
+	m.cfg.TwoPersonServices = "vault"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[
+			{"vmid":100,"name":"postgres","node":"pve1","type":"qemu","status":"running","tags":"db;two-person"},
+			{"vmid":101,"name":"jellyfin","node":"pve1","type":"lxc","status":"running","tags":"media"}
+		]}`))
+	}))
+	defer srv.Close()
+	m.proxmox = proxmox.New(srv.URL, "ops@pve!claude", "secret")
+
+	got := m.twoPersonServices(context.Background(), []string{"Postgres", "jellyfin", "vault"})
+	if !slices.Equal(got, []string{"Postgres", "vault"}) {
+		t.Errorf("twoPersonServices = %v, want [Postgres vault]", got)
+	}
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/joestump/claude-ops/internal/hub"
+)
+
+// Chain stream event types.
+const (
+	ChainSessionStarted = "session"    // the chain started a session
+	ChainEscalated      = "escalation" // the chain is escalating to a higher tier
+	ChainHandedBack     = "hand_back"  // a session handed back to a lower tier to verify
+	ChainEnded          = "end"        // the chain finished
+)
+
+// ChainEvent is one step of an escalation chain, published as JSON on the
+// chain's hub stream (see hub.ChainID) so a client following a session can
+// follow the rest of its chain.
+type ChainEvent struct {
+	Type string `json:"type"`
+	// SessionID is the session started, or the one that escalated or
+	// handed back.
+	SessionID int64 `json:"session_id,omitempty"`
+	// Tier and Model are the started session's, or those of the tier the
+	// chain goes to; FromTier is the tier it goes from.
+	Tier     int      `json:"tier,omitempty"`
+	FromTier int      `json:"from_tier,omitempty"`
+	Model    string   `json:"model,omitempty"`
+	Services []string `json:"services,omitempty"`
+	// Sessions and CostUSD are the finished chain's sessions, in the order
+	// they started, and their total cost.
+	Sessions []int64 `json:"sessions,omitempty"`
+	CostUSD  float64 `json:"cost_usd,omitempty"`
+}
+
+// publishChainEvent publishes e on the stream of the chain whose first
+// session is rootID.
+func (m *Manager) publishChainEvent(rootID int64, e ChainEvent) {
+	if m.hub == nil || rootID == 0 {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "chain %d: encode stream event: %v\n", rootID, err)
+		return
+	}
+	m.hub.Publish(hub.ChainID(rootID), hub.Lifecycle, string(data))
+}
+
+// endChainStream publishes the chain's sessions and cost and closes its
+// stream.
+func (m *Manager) endChainStream(rootID int64) {
+	e := ChainEvent{Type: ChainEnded}
+	chain, err := m.chainSessions(rootID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "chain %d: load sessions for stream: %v\n", rootID, err)
+	}
+	for _, s := range chain {
+		e.Sessions = append(e.Sessions, s.ID)
+		if s.CostUSD != nil {
+			e.CostUSD += *s.CostUSD
+		}
+	}
+	m.publishChainEvent(rootID, e)
+	if m.hub != nil {
+		m.hub.Close(hub.ChainID(rootID))
+	}
+}
@@ -0,0 +1,56 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestChainStream(t *testing.T) {
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Postgres is down.", testkit.Escalate("postgres down", "postgres")),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Restarted postgres.", testkit.Healthy("postgres restarted", "postgres")),
+		}},
+	)
+	m, _ := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 2
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.runner = runner
+
+	rootID := m.runEscalationChain(context.Background(), "manual", nil, 1, "", nil)
+
+	ch, unsubscribe := m.hub.Subscribe(hub.ChainID(rootID), hub.Lifecycle)
+	defer unsubscribe()
+	var events []ChainEvent
+	for evt := range ch {
+		var e ChainEvent
+		if err := json.Unmarshal([]byte(evt.Data), &e); err != nil {
+			t.Fatalf("decode %q: %v", evt.Data, err)
+		}
+		events = append(events, e)
+	}
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	if want := []string{ChainSessionStarted, ChainEscalated, ChainSessionStarted, ChainEnded}; !slices.Equal(types, want) {
+		t.Fatalf("chain stream = %v, want %v", types, want)
+	}
+	if e := events[0]; e.SessionID != rootID || e.Tier != 1 || e.Model != "haiku" {
+		t.Errorf("first session = %+v", e)
+	}
+	if e := events[1]; e.SessionID != rootID || e.FromTier != 1 || e.Tier != 2 || e.Model != "sonnet" || !slices.Equal(e.Services, []string{"postgres"}) {
+		t.Errorf("escalation = %+v", e)
+	}
+	if e := events[3]; len(e.Sessions) != 2 || e.Sessions[0] != rootID || e.Sessions[1] != events[2].SessionID {
+		t.Errorf("end = %+v", e)
+	}
+}
@@ -0,0 +1,155 @@
+package session
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+const chainSummarySystemPrompt = "You are a concise technical summarizer. The following are the sessions of one infrastructure monitoring escalation chain, in the order they ran, each with its own summary. Summarize the whole chain in one or two sentences as a sequence of steps joined by arrows, e.g. \"Tier 1 found X → Tier 2 diagnosed Y → Tier 3 restarted Z, verified healthy\". Be specific about service names and outcomes."
+
+// summarizeChainSteps asks the summary model to summarize an escalation
+// chain described by steps (see chainSteps).
+func summarizeChainSteps(ctx context.Context, steps, model string) (string, error) {
+	return summarizeText(ctx, chainSummarySystemPrompt, steps, model)
+}
+
+// chainSessions returns the escalation chain sessionID belongs to: its root
+// and every session descended from it (escalations, continuations, and
+// verifications), in the order they started.
+func (m *Manager) chainSessions(sessionID int64) ([]db.Session, error) {
+	up, err := m.db.GetEscalationChain(sessionID)
+	if err != nil || len(up) == 0 {
+		return nil, err
+	}
+	chain := up[:1]
+	for i := 0; i < len(chain); i++ {
+		children, err := m.db.GetChildSessions(chain[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, children...)
+	}
+	slices.SortFunc(chain, func(a, b db.Session) int { return cmp.Compare(a.ID, b.ID) })
+	return chain, nil
+}
+
+// recordChainSummary summarizes the escalation chain sessionID belongs to
+// and stores the summary on the chain's root session. A session that did
+// not escalate, continue, or get verified is not part of a chain and gets
+// none. When the summary model fails or no API key is set, the summary is
+// built from the sessions' own summaries (see localChainSummary).
+func (m *Manager) recordChainSummary(ctx context.Context, sessionID int64) {
+	chain, err := m.chainSessions(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: load chain for summary: %v\n", sessionID, err)
+		return
+	}
+	if len(chain) < 2 {
+		return
+	}
+	summary, err := m.summarizeChain(ctx, chainSteps(chain), m.cfg.SummaryModel)
+	if err != nil && !errors.Is(err, errNoAPIKey) {
+		fmt.Fprintf(os.Stderr, "failed to summarize chain %d, using a local summary: %v\n", chain[0].ID, err)
+	}
+	if summary = strings.TrimSpace(summary); err != nil || summary == "" {
+		summary = localChainSummary(chain)
+	}
+	if err := m.db.UpdateSessionChainSummary(chain[0].ID, summary); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to store chain summary %d: %v\n", chain[0].ID, err)
+	}
+}
+
+// chainSteps describes each session of a chain for the summary model: its
+// tier, trigger, status, and outcome, then its summary, or the start of its
+// response when it has none.
+func chainSteps(chain []db.Session) string {
+	var b strings.Builder
+	for _, s := range chain {
+		fmt.Fprintf(&b, "Session #%d, Tier %d, %s, %s", s.ID, s.Tier, s.Trigger, s.Status)
+		if s.Outcome != nil {
+			b.WriteString(", outcome " + *s.Outcome)
+		}
+		b.WriteString(":\n")
+		switch {
+		case s.Summary != nil && *s.Summary != "":
+			b.WriteString(*s.Summary)
+		case s.Response != nil && *s.Response != "":
+			b.WriteString(truncatePreserve(*s.Response, localSummaryMax))
+		default:
+			b.WriteString("(no response)")
+		}
+		b.WriteString("\n\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// localChainSummary builds a chain summary without the summary model: the
+// first line of each tier's summary, joined by arrows, followed by the
+// verification result, e.g. "Tier 1: caddy is down → Tier 3: restarted
+// caddy, verified healthy".
+func localChainSummary(chain []db.Session) string {
+	var steps []string
+	verified := ""
+	reopened := false // a remediation in the chain was reopened by its verification
+	for _, s := range chain {
+		if s.Trigger == "verify" {
+			switch {
+			// Verification sessions recorded before the remediation was
+			// reopened instead were marked reopened themselves.
+			case s.Status == "reopened", s.Status == "completed" && reopened:
+				verified = "remediation did not hold"
+			case s.Status == "completed":
+				verified = "verified healthy"
+			default:
+				verified = "verification " + s.Status
+			}
+			continue
+		}
+		if s.Status == "reopened" {
+			reopened = true
+		}
+		label := fmt.Sprintf("Tier %d", s.Tier)
+		if s.Trigger == "continuation" {
+			label += " (continued)"
+		}
+		what := s.Status
+		if s.Summary != nil {
+			if line := firstSummaryLine(*s.Summary); line != "" {
+				what = line
+			}
+		} else if s.Outcome != nil {
+			what = *s.Outcome
+		}
+		steps = append(steps, label+": "+what)
+	}
+	summary := strings.Join(steps, " → ")
+	if verified != "" {
+		summary += ", " + verified
+	}
+	return summary
+}
+
+// firstSummaryLine returns the first line of a session summary without its
+// markdown emphasis, heading, and list markers, cut at the end of its first
+// sentence.
+func firstSummaryLine(summary string) string {
+	for _, line := range strings.Split(summary, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#-* "))
+		line = strings.ReplaceAll(line, "**", "")
+		if line == "" {
+			continue
+		}
+		if i := strings.Index(line, ". "); i > 0 {
+			line = line[:i]
+		}
+		return truncateString(strings.TrimSuffix(line, "."), 160)
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestRecordChainSummary(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	insert := func(tier int, status, trigger, summary string, parent *int64) int64 {
+		id, err := database.InsertSession(&db.Session{Tier: tier, Model: "haiku", PromptFile: "/p.md", Status: status, StartedAt: now, Trigger: trigger, ParentSessionID: parent})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		if summary != "" {
+			if err := database.UpdateSessionSummary(id, summary); err != nil {
+				t.Fatalf("UpdateSessionSummary: %v", err)
+			}
+		}
+		return id
+	}
+	chainSummary := func(id int64) string {
+		s, err := database.GetSession(id)
+		if err != nil || s == nil {
+			t.Fatalf("GetSession(%d): %v", id, err)
+		}
+		if s.ChainSummary == nil {
+			return ""
+		}
+		return *s.ChainSummary
+	}
+
+	lone := insert(1, "completed", "scheduled", "All services healthy.", nil)
+	root := insert(1, "escalated", "scheduled", "**Health check**\n\n- critical (caddy): caddy is down", nil)
+	t2 := insert(2, "escalated", "escalation", "Caddy crashed after a bad config reload. Logs show a parse error.", &root)
+	t3 := insert(3, "completed", "escalation", "Restarted caddy with the previous config.", &t2)
+
+	var steps string
+	m.summarizeChain = func(_ context.Context, s, _ string) (string, error) {
+		steps = s
+		return "Tier 1 found caddy down → Tier 2 found a bad config → Tier 3 restarted caddy", nil
+	}
+	m.recordChainSummary(context.Background(), lone)
+	if steps != "" || chainSummary(lone) != "" {
+		t.Error("a session that is not part of a chain was summarized as one")
+	}
+	m.recordChainSummary(context.Background(), t3)
+	if !strings.Contains(steps, "Tier 2, escalation, escalated") || !strings.Contains(steps, "Restarted caddy") {
+		t.Errorf("chain steps = %q", steps)
+	}
+	if got := chainSummary(root); !strings.HasPrefix(got, "Tier 1 found caddy down") {
+		t.Errorf("root chain summary = %q", got)
+	}
+	if chainSummary(t3) != "" {
+		t.Error("chain summary stored on the tip instead of the root")
+	}
+
+	// Without the summary model, the summary is built from the tiers'
+	// summaries and the verification result.
+	insert(1, "completed", "verify", "caddy is healthy.", &t3)
+	m.summarizeChain = func(context.Context, string, string) (string, error) { return "", errors.New("rate limited") }
+	m.recordChainSummary(context.Background(), t3)
+	want := "Tier 1: Health check → Tier 2: Caddy crashed after a bad config reload → Tier 3: Restarted caddy with the previous config, verified healthy"
+	if got := chainSummary(root); got != want {
+		t.Errorf("local chain summary = %q, want %q", got, want)
+	}
+}
+
+func TestLocalChainSummaryReopenedRemediation(t *testing.T) {
+	restarted := "Restarted caddy."
+	chain := []db.Session{
+		{Tier: 3, Status: "reopened", Trigger: "manual", Summary: &restarted},
+		{Tier: 1, Status: "completed", Trigger: "verify"},
+	}
+	if got, want := localChainSummary(chain), "Tier 3: Restarted caddy, remediation did not hold"; got != want {
+		t.Errorf("localChainSummary = %q, want %q", got, want)
+	}
+}
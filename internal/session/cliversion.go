@@ -0,0 +1,173 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// CLIVersionKey is the config key holding the CLI version seen at the last
+// startup.
+const CLIVersionKey = "claude_cli_version"
+
+// Thresholds for the stream format check: a session whose stream has at
+// least streamCheckMinLines lines, of which streamAnomalyRatio or more are
+// unparseable or of unknown event types, raises a warning.
+const (
+	streamCheckMinLines = 5
+	streamAnomalyRatio  = 0.2
+)
+
+// knownStreamTypes are the stream-json event types the parser understands.
+var knownStreamTypes = map[string]bool{"system": true, "assistant": true, "user": true, "result": true}
+
+// claudeVersion runs `claude --version`.
+func claudeVersion(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "claude", "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// DetectCLIVersion records the installed CLI version for session
+// invocations. When it differs from the version seen at the previous
+// startup, an info event notes the update, so a later change in the
+// stream-json format can be traced to it.
+func (m *Manager) DetectCLIVersion(ctx context.Context) {
+	version, err := m.cliVersionFn(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "claude --version: %v\n", err)
+		return
+	}
+	m.cliVersion = version
+	fmt.Printf("Claude CLI: %s\n", version)
+
+	previous, err := m.db.GetConfig(CLIVersionKey, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "get %s: %v\n", CLIVersionKey, err)
+		return
+	}
+	if previous == version {
+		return
+	}
+	if err := m.db.SetConfig(CLIVersionKey, version); err != nil {
+		fmt.Fprintf(os.Stderr, "set %s: %v\n", CLIVersionKey, err)
+	}
+	if previous != "" {
+		m.insertSystemEvent("info", fmt.Sprintf("Claude CLI updated from %s to %s", previous, version))
+	}
+}
+
+func (m *Manager) insertSystemEvent(level, message string) {
+	if err := m.insertEvent(&db.Event{
+		Level:     level,
+		Message:   message,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "insert event: %v\n", err)
+	}
+}
+
+// unparseableEventType is the stream diagnostics type for lines that are
+// not stream-json events at all.
+const unparseableEventType = "(unparseable)"
+
+// streamSampleMax bounds a stored sample line.
+const streamSampleMax = 2000
+
+// streamStats counts a session's stream-json lines the parser could not
+// make sense of, per unknown event type, keeping the first line of each as a
+// sample.
+type streamStats struct {
+	lines   int
+	dropped map[string]int    // event type (or unparseableEventType) -> count
+	samples map[string]string // event type -> first line
+}
+
+// observe classifies one raw stream line.
+func (st *streamStats) observe(raw string) {
+	if strings.TrimSpace(raw) == "" {
+		return
+	}
+	st.lines++
+	var evt streamEvent
+	eventType := ""
+	if err := json.Unmarshal([]byte(raw), &evt); err != nil || evt.Type == "" {
+		eventType = unparseableEventType
+	} else if !knownStreamTypes[evt.Type] {
+		eventType = evt.Type
+	}
+	if eventType == "" {
+		return
+	}
+	if st.dropped == nil {
+		st.dropped = make(map[string]int)
+		st.samples = make(map[string]string)
+	}
+	st.dropped[eventType]++
+	if _, ok := st.samples[eventType]; !ok {
+		st.samples[eventType] = truncateString(raw, streamSampleMax)
+	}
+}
+
+// anomalies returns how many lines were unparseable or of unknown types.
+func (st *streamStats) anomalies() int {
+	n := 0
+	for _, c := range st.dropped {
+		n += c
+	}
+	return n
+}
+
+// saveStreamDiagnostics persists the session's dropped event counts and
+// samples for the session page.
+func (m *Manager) saveStreamDiagnostics(sessionID int64, st *streamStats) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for eventType, n := range st.dropped {
+		if _, err := m.db.InsertStreamDiagnostic(&db.StreamDiagnostic{
+			SessionID: sessionID,
+			EventType: eventType,
+			Count:     n,
+			Sample:    st.samples[eventType],
+			CreatedAt: now,
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+		}
+	}
+}
+
+// checkStreamFormat raises a warning event when a session's stream looks
+// like the CLI changed its output format underneath the parser.
+func (m *Manager) checkStreamFormat(sessionID int64, st *streamStats, cliVersion string) {
+	bad := st.anomalies()
+	if st.lines < streamCheckMinLines || float64(bad) < streamAnomalyRatio*float64(st.lines) {
+		return
+	}
+	var types []string
+	for t := range st.dropped {
+		if t != unparseableEventType {
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	detail := fmt.Sprintf("%d unparseable", st.dropped[unparseableEventType])
+	if len(types) > 0 {
+		detail += ", unknown event types: " + strings.Join(types, ", ")
+	}
+	if cliVersion == "" {
+		cliVersion = "unknown version"
+	}
+	m.emitEscalationEventLevel(sessionID, "warning", fmt.Sprintf(
+		"Claude CLI output format may have changed (CLI %s): %d of %d stream lines were not understood (%s). Session results may be incomplete; this is a parsing problem, not an infrastructure one.",
+		cliVersion, bad, st.lines, detail))
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestDetectCLIVersion(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	version := "2.0.1 (Claude Code)"
+	m.cliVersionFn = func(context.Context) (string, error) { return version, nil }
+
+	m.DetectCLIVersion(context.Background())
+	if m.cliVersion != version {
+		t.Errorf("cliVersion = %q", m.cliVersion)
+	}
+	if events, _ := database.ListEvents(10, 0, db.EventFilter{}); len(events) != 0 {
+		t.Errorf("expected no event on first startup, got %+v", events)
+	}
+
+	// Same version at the next startup: nothing to report.
+	m.DetectCLIVersion(context.Background())
+	// Updated underneath the container.
+	version = "2.1.0 (Claude Code)"
+	m.DetectCLIVersion(context.Background())
+
+	events, _ := database.ListEvents(10, 0, db.EventFilter{})
+	if len(events) != 1 || events[0].Message != "Claude CLI updated from 2.0.1 (Claude Code) to 2.1.0 (Claude Code)" {
+		t.Errorf("expected one update event, got %+v", events)
+	}
+}
+
+func TestCheckStreamFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		wantWarn bool
+	}{
+		{"healthy", []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"type":"assistant","message":{"content":[]}}`,
+			`{"type":"user","message":{"content":[]}}`,
+			`{"type":"assistant","message":{"content":[]}}`,
+			`{"type":"result","result":"ok"}`,
+		}, false},
+		{"format changed", []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"kind":"message","text":"hi"}`,
+			`{"type":"assistant_delta"}`,
+			`{"type":"assistant_delta"}`,
+			`not json`,
+		}, true},
+		{"too short to judge", []string{`not json`, `{"type":"result"}`}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, database := testManagerWithDB(t)
+			var st streamStats
+			for _, l := range tt.lines {
+				st.observe(l)
+			}
+			m.checkStreamFormat(7, &st, "2.1.0")
+
+			events, _ := database.ListEvents(10, 0, db.EventFilter{})
+			if tt.wantWarn != (len(events) == 1) {
+				t.Fatalf("events = %+v, want warning=%v", events, tt.wantWarn)
+			}
+			if tt.wantWarn {
+				msg := events[0].Message
+				if events[0].Level != "warning" || !strings.Contains(msg, "CLI 2.1.0") || !strings.Contains(msg, "4 of 5") || !strings.Contains(msg, "assistant_delta") {
+					t.Errorf("unexpected warning %+v", events[0])
+				}
+			}
+		})
+	}
+}
+
+func TestRunTierSavesStreamDiagnostics(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.runner = &pipeRunner{
+		events: []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"type":"assistant_delta","text":"a"}`,
+			`{"type":"assistant_delta","text":"b"}`,
+			`not json`,
+			`{"type":"result","result":"All healthy.","is_error":false}`,
+		},
+		resultIdx: 4,
+	}
+
+	id, _, err := m.runTier(context.Background(), 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	diags, err := database.ListStreamDiagnostics(id)
+	if err != nil {
+		t.Fatalf("ListStreamDiagnostics: %v", err)
+	}
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %+v", diags)
+	}
+	if diags[0].EventType != "assistant_delta" || diags[0].Count != 2 || !strings.Contains(diags[0].Sample, `"text":"a"`) {
+		t.Errorf("unexpected first diagnostic %+v", diags[0])
+	}
+	if diags[1].EventType != unparseableEventType || diags[1].Count != 1 || diags[1].Sample != "not json" {
+		t.Errorf("unexpected second diagnostic %+v", diags[1])
+	}
+}
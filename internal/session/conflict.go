@@ -0,0 +1,139 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// ErrQueued is returned by TriggerAdHoc when the request waits for the
+// running chain, which is not working on the services the prompt names, and
+// runs after it.
+var ErrQueued = errors.New("queued until the running session finishes")
+
+// QueuedError is the ErrQueued TriggerAdHoc returns, with a channel that
+// receives the ID of the session the request starts once the running chain
+// ends, or 0 if none starts.
+type QueuedError struct {
+	Started <-chan int64
+}
+
+func (e *QueuedError) Error() string { return ErrQueued.Error() }
+
+// Is makes a *QueuedError match ErrQueued.
+func (e *QueuedError) Is(target error) bool { return target == ErrQueued }
+
+// ServiceConflictError is returned by TriggerAdHoc when the prompt names
+// services the running chain is already working on.
+type ServiceConflictError struct {
+	// SessionID is the chain's latest session.
+	SessionID int64
+	Services  []string
+}
+
+func (e *ServiceConflictError) Error() string {
+	return fmt.Sprintf("session #%d is already working on %s", e.SessionID, strings.Join(e.Services, ", "))
+}
+
+// activeChain is what the running escalation chain is working on.
+type activeChain struct {
+	rootID    int64 // its first session
+	sessionID int64 // its latest session
+	services  []string
+}
+
+// beginChain records that a chain started for services (none if it is not
+// scoped yet); endChain that it finished, ending its stream.
+func (m *Manager) beginChain(services []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chain = &activeChain{services: m.serviceNames(0, services)}
+}
+
+func (m *Manager) endChain() {
+	m.mu.Lock()
+	rootID := m.chain.rootID
+	m.chain = nil
+	m.mu.Unlock()
+	if rootID != 0 {
+		m.endChainStream(rootID)
+	}
+}
+
+// chainSession records that the running chain started sess for services,
+// adding them to the services it is working on, and publishes it on the
+// chain's stream.
+func (m *Manager) chainSession(sess *db.Session, services []string) {
+	m.mu.Lock()
+	if m.chain == nil {
+		m.mu.Unlock()
+		return
+	}
+	if m.chain.rootID == 0 {
+		m.chain.rootID = sess.ID
+	}
+	rootID := m.chain.rootID
+	m.chain.sessionID = sess.ID
+	for _, svc := range services {
+		if !slices.Contains(m.chain.services, svc) {
+			m.chain.services = append(m.chain.services, svc)
+		}
+	}
+	m.mu.Unlock()
+	m.publishChainEvent(rootID, ChainEvent{Type: ChainSessionStarted, SessionID: sess.ID, Tier: sess.Tier, Model: sess.Model, Services: services})
+}
+
+// chainRoot returns the running chain's first session, or 0 if none.
+func (m *Manager) chainRoot() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.chain == nil {
+		return 0
+	}
+	return m.chain.rootID
+}
+
+// serviceConflict returns the running chain's services that prompt names,
+// or nil when none does.
+func (m *Manager) serviceConflict(prompt string) *ServiceConflictError {
+	m.mu.Lock()
+	var chain activeChain
+	if m.chain != nil {
+		chain = activeChain{sessionID: m.chain.sessionID, services: slices.Clone(m.chain.services)}
+	}
+	m.mu.Unlock()
+	if chain.sessionID == 0 {
+		return nil
+	}
+	if named := m.promptServices(prompt, chain.services); len(named) > 0 {
+		return &ServiceConflictError{SessionID: chain.sessionID, Services: named}
+	}
+	return nil
+}
+
+// promptWordRe matches the words of a prompt that could be service names.
+var promptWordRe = regexp.MustCompile(`[a-z0-9][a-z0-9_.-]*`)
+
+// promptServices returns the services prompt names, directly or by an
+// alias. Up to three consecutive words are tried as one name, so "Home
+// Assistant" names home-assistant.
+func (m *Manager) promptServices(prompt string, services []string) []string {
+	words := promptWordRe.FindAllString(strings.ToLower(prompt), -1)
+	for i, w := range words {
+		words[i] = strings.TrimRight(w, ".-")
+	}
+	var named []string
+	for i := range words {
+		for n := 1; n <= 3 && i+n <= len(words); n++ {
+			name, ok := m.services.Normalize(strings.Join(words[i:i+n], "-"))
+			if ok && slices.Contains(services, name) && !slices.Contains(named, name) {
+				named = append(named, name)
+			}
+		}
+	}
+	return named
+}
@@ -0,0 +1,49 @@
+package session
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/servicename"
+)
+
+func TestTriggerAdHocServiceConflict(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	aliases, err := servicename.ParseAliases("jf=jellyfin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.services = servicename.New(aliases, database)
+
+	m.beginChain([]string{"Jellyfin"})
+	m.chainSession(&db.Session{ID: 41, Tier: 3, Model: "opus"}, []string{"home-assistant"})
+
+	for _, prompt := range []string{"Why is jellyfin down?", "restart JF please", "Is Home Assistant back up?"} {
+		_, err := m.TriggerAdHoc(prompt, 1, "api")
+		var conflict *ServiceConflictError
+		if !errors.As(err, &conflict) || conflict.SessionID != 41 || len(conflict.Services) != 1 {
+			t.Errorf("%q: err = %v, want a conflict with session #41", prompt, err)
+		}
+	}
+
+	_, err = m.TriggerAdHoc("check sonarr", 1, "api")
+	var queued *QueuedError
+	if !errors.Is(err, ErrQueued) || !errors.As(err, &queued) {
+		t.Fatalf("unrelated prompt: err = %v, want ErrQueued", err)
+	}
+	req := <-m.triggerCh
+	if req.prompt != "check sonarr" {
+		t.Errorf("queued request = %+v", req)
+	}
+	req.started <- 42
+	if id := <-queued.Started; id != 42 {
+		t.Errorf("queued request started session %d, want 42", id)
+	}
+
+	m.endChain()
+	if got := m.promptServices("jellyfin and sonarr", []string{"sonarr", "jellyfin"}); !slices.Equal(got, []string{"jellyfin", "sonarr"}) {
+		t.Errorf("promptServices = %v", got)
+	}
+}
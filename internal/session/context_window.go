@@ -0,0 +1,61 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context windows, in tokens. Models run with the 1M-token context beta are
+// named with a "[1m]" suffix (e.g. "sonnet[1m]").
+const (
+	defaultContextWindow = 200_000
+	largeContextWindow   = 1_000_000
+)
+
+// ContextWindow returns the context window of model.
+func ContextWindow(model string) int64 {
+	if strings.Contains(strings.ToLower(model), "[1m]") {
+		return largeContextWindow
+	}
+	return defaultContextWindow
+}
+
+// contextTracker follows how full a session's context window gets from the
+// usage reported on each assistant message.
+type contextTracker struct {
+	window  int64
+	warnPct int // 0 disables the warning
+	max     int64
+	warned  bool
+}
+
+func newContextTracker(model string, warnPct int) *contextTracker {
+	return &contextTracker{window: ContextWindow(model), warnPct: warnPct}
+}
+
+// setModel widens the window for the model the CLI resolved, which may name
+// a larger window than the requested alias.
+func (c *contextTracker) setModel(model string) {
+	c.window = max(c.window, ContextWindow(model))
+}
+
+// observe records the context size after one assistant message. It returns
+// true the first time the size crosses the warning threshold.
+func (c *contextTracker) observe(u *tokenUsage) bool {
+	size := u.total()
+	if size <= c.max {
+		return false
+	}
+	c.max = size
+	if c.warned || c.warnPct <= 0 || size*100 < c.window*int64(c.warnPct) {
+		return false
+	}
+	c.warned = true
+	return true
+}
+
+// warning is the event message for crossing the threshold.
+func (c *contextTracker) warning() string {
+	return fmt.Sprintf("Context window %d%% full (%dk of %dk tokens); the session may degrade as it nears the limit",
+		c.max*100/c.window, c.max/1000, c.window/1000)
+}
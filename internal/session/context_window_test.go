@@ -0,0 +1,87 @@
+package session
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestContextWindow(t *testing.T) {
+	if got := ContextWindow("claude-opus-4-1-20250805"); got != 200_000 {
+		t.Errorf("opus: got %d", got)
+	}
+	if got := ContextWindow("sonnet[1m]"); got != 1_000_000 {
+		t.Errorf("sonnet[1m]: got %d", got)
+	}
+}
+
+func TestContextTrackerWarnsOnce(t *testing.T) {
+	c := newContextTracker("opus", 80)
+	if c.observe(&tokenUsage{InputTokens: 100_000}) {
+		t.Error("50% should not warn")
+	}
+	if !c.observe(&tokenUsage{InputTokens: 150_000, CacheReadInputTokens: 10_000}) {
+		t.Error("80% should warn")
+	}
+	if c.observe(&tokenUsage{InputTokens: 190_000}) {
+		t.Error("should warn only once")
+	}
+	if c.observe(nil) || c.max != 190_000 {
+		t.Errorf("expected max 190000, got %d", c.max)
+	}
+	if w := c.warning(); !strings.Contains(w, "95% full (190k of 200k tokens)") {
+		t.Errorf("unexpected warning %q", w)
+	}
+
+	off := newContextTracker("opus", 0)
+	if off.observe(&tokenUsage{InputTokens: 199_000}) {
+		t.Error("0 should disable the warning")
+	}
+}
+
+func TestSessionRecordsContextPressure(t *testing.T) {
+	usage := func(text string, input int) testkit.Event {
+		return testkit.Raw(`{"type":"assistant","message":{"content":[{"type":"text","text":"` + text + `"}],` +
+			`"usage":{"input_tokens":` + strconv.Itoa(input) + `,"output_tokens":500}}}`)
+	}
+	runner := testkit.NewRunner(testkit.Script{Events: []testkit.Event{
+		testkit.Init("claude-opus-4-1-20250805"),
+		usage("Checking services.", 60_000),
+		usage("Still checking.", 170_000),
+		usage("Nearly done.", 180_000),
+		testkit.Result("All healthy.", nil),
+	}})
+
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 1
+	m.cfg.ContextWarnPercent = 80
+	m.runner = runner
+
+	id := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	s, err := database.GetSession(id)
+	if err != nil || s == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if s.MaxContext == nil || *s.MaxContext != 180_500 {
+		t.Errorf("expected max context 180500, got %v", s.MaxContext)
+	}
+	events, err := database.ListEvents(50, 0, db.EventFilter{})
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	warnings := 0
+	for _, e := range events {
+		if e.Level == "warning" && strings.Contains(e.Message, "Context window") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("expected one context warning, got %d", warnings)
+	}
+}
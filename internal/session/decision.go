@@ -0,0 +1,69 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// Escalation decision outcomes, recorded after each session's tier.
+const (
+	DecisionEscalated    = "escalated"      // the chain went on to the next tier
+	DecisionNotRequested = "not_requested"  // the agent did not ask to escalate
+	DecisionFailed       = "session_failed" // the session failed before it could ask
+	DecisionHandoffError = "handoff_error"  // the handoff file could not be read
+	DecisionInvalid      = "invalid_handoff"
+	DecisionDryRun       = "dry_run"
+	DecisionPolicyDenied = "policy_denied"
+	DecisionCooldown     = "escalation_cooldown" // another chain escalated for the services too recently
+	DecisionMaxTier      = "max_tier"            // the requested tier is above CLAUDEOPS_MAX_TIER
+	DecisionShutdown     = "shutdown"            // the supervisor was shutting down
+	DecisionHandedBack   = "handed_back"         // the session handed back to a lower tier to verify its work
+)
+
+// newDecision starts the escalation decision for a session that finished
+// its tier, with the config it is decided under.
+func (m *Manager) newDecision(sessionID int64, tier int) *db.EscalationDecision {
+	return &db.EscalationDecision{
+		SessionID: sessionID,
+		FromTier:  tier,
+		Cooldowns: "[]",
+		DryRun:    m.cfg.DryRun,
+		MaxTier:   m.cfg.MaxTier,
+	}
+}
+
+// requested fills in the escalation the agent asked for and the state it is
+// decided on: the affected services' cooldown budgets and the spend so far.
+func (m *Manager) requested(d *db.EscalationDecision, source string, tier int, services []string, handoff string) {
+	d.Source = source
+	d.RequestedTier = tier
+	d.Services = strings.Join(services, ",")
+	d.Handoff = handoff
+	budgets, err := m.serviceCooldownBudgets(services)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: decision cooldowns: %v\n", d.SessionID, err)
+	}
+	if data, err := json.Marshal(budgets); err == nil && budgets != nil {
+		d.Cooldowns = string(data)
+	}
+	spend := chainSpend(m.db, d.SessionID, time.Now())
+	d.ChainCostUSD, d.Cost24hUSD = spend.ChainCostUSD, spend.Cost24hUSD
+}
+
+// recordDecision stores d with its outcome. tier is the tier the chain goes
+// on to, 0 if it stops.
+func (m *Manager) recordDecision(d *db.EscalationDecision, outcome string, tier int, reason string) {
+	if d == nil || d.SessionID == 0 {
+		return
+	}
+	d.Outcome, d.Tier, d.Reason = outcome, tier, reason
+	d.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	if _, err := m.db.InsertEscalationDecision(d); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: record escalation decision: %v\n", d.SessionID, err)
+	}
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/policy"
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestEscalationDecisionsRecorded(t *testing.T) {
+	engine, err := policy.Parse([]byte(`
+rules:
+  - name: no-tier3
+    when: escalation
+    expr: escalation.to_tier == 3
+    action: deny
+    message: remediation needs a human
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Jellyfin is down.", testkit.Escalate("jellyfin returns 502", "jellyfin")),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Still failing.", testkit.Escalate("jellyfin still returns 502", "jellyfin")),
+		}},
+	)
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.cfg.Tier3Prompt = "/dev/null"
+	m.runner = runner
+	m.Policy = engine
+	if _, err := database.InsertCooldownAction(&db.CooldownAction{
+		Service: "jellyfin", ActionType: "restart", Timestamp: time.Now().UTC().Format(time.RFC3339), Success: true, Tier: 2,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rootID := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	d, err := database.GetEscalationDecision(rootID)
+	if err != nil || d == nil {
+		t.Fatalf("tier 1 decision: %+v (%v)", d, err)
+	}
+	if d.Outcome != DecisionEscalated || d.FromTier != 1 || d.RequestedTier != 2 || d.Tier != 2 ||
+		d.Source != "structured" || d.Services != "jellyfin" || d.DryRun || d.MaxTier != 3 {
+		t.Errorf("unexpected tier 1 decision %+v", d)
+	}
+	if !strings.Contains(d.Handoff, "jellyfin returns 502") {
+		t.Errorf("tier 1 handoff = %q", d.Handoff)
+	}
+	var budgets []CooldownBudget
+	if err := json.Unmarshal([]byte(d.Cooldowns), &budgets); err != nil || len(budgets) != 2 ||
+		budgets[0].Action != "restart" || budgets[0].Used != 1 || budgets[0].Remaining != 1 {
+		t.Errorf("tier 1 cooldowns = %s (%v)", d.Cooldowns, err)
+	}
+
+	children, err := database.GetChildSessions(rootID)
+	if err != nil || len(children) != 1 {
+		t.Fatalf("GetChildSessions: %+v (%v)", children, err)
+	}
+	d, err = database.GetEscalationDecision(children[0].ID)
+	if err != nil || d == nil {
+		t.Fatalf("tier 2 decision: %+v (%v)", d, err)
+	}
+	if d.Outcome != DecisionPolicyDenied || d.Tier != 0 || d.RequestedTier != 3 || !strings.Contains(d.Reason, "remediation needs a human") {
+		t.Errorf("unexpected tier 2 decision %+v", d)
+	}
+}
+
+func TestEscalationDecisionNotRequestedAndDryRun(t *testing.T) {
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{testkit.Result("All good.", testkit.Healthy("all healthy", "jellyfin"))}},
+		testkit.Script{Events: []testkit.Event{testkit.Result("Jellyfin is down.", testkit.Escalate("502", "jellyfin"))}},
+	)
+	m, database := testManagerWithDB(t)
+	m.cfg.MaxTier = 3
+	m.runner = runner
+
+	id := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+	if d, err := database.GetEscalationDecision(id); err != nil || d == nil || d.Outcome != DecisionNotRequested || d.RequestedTier != 0 {
+		t.Errorf("healthy run decision: %+v (%v)", d, err)
+	}
+
+	id = m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+	if d, err := database.GetEscalationDecision(id); err != nil || d == nil || d.Outcome != DecisionDryRun || !d.DryRun || d.RequestedTier != 2 {
+		t.Errorf("dry run decision: %+v (%v)", d, err)
+	}
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+//go:embed demo/*.ndjson
+var demoFixtures embed.FS
+
+// demoLineDelay paces fixture replay so the live session view streams like
+// a real session.
+var demoLineDelay = 500 * time.Millisecond
+
+// DemoRunner implements ProcessRunner by replaying canned stream-json
+// fixtures instead of invoking the CLI. The fixtures walk one incident
+// through all three tiers (jellyfin is OOM-killed, a restart fails, and a
+// redeploy fixes it) plus the follow-up verification, so the dashboard,
+// escalation chain, memories, and events can be exercised without an API key.
+type DemoRunner struct {
+	cfg *config.Config
+}
+
+// NewDemoRunner returns a DemoRunner that picks fixtures using cfg's tier
+// models.
+func NewDemoRunner(cfg *config.Config) *DemoRunner {
+	return &DemoRunner{cfg: cfg}
+}
+
+// Start replays the fixture for the session's tier. The wait function
+// returns the context's error if the session is cancelled mid-replay.
+func (r *DemoRunner) Start(ctx context.Context, model string, _ string, _ string, _ string, appendSystemPrompt string, _ string, _ string, _ []string) (io.ReadCloser, func() error, error) {
+	name := r.fixture(model, appendSystemPrompt)
+	data, err := demoFixtures.ReadFile("demo/" + name + ".ndjson")
+	if err != nil {
+		return nil, nil, fmt.Errorf("demo fixture %s: %w", name, err)
+	}
+	lines := strings.SplitAfter(strings.ReplaceAll(string(data), "$MODEL", model), "\n")
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		defer pw.Close() //nolint:errcheck
+		for _, line := range lines {
+			if line == "" {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				done <- ctx.Err()
+				return
+			case <-time.After(demoLineDelay):
+			}
+			if _, err := io.WriteString(pw, line); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	return pr, func() error { return <-done }, nil
+}
+
+// fixture picks the fixture for a session from its model and context.
+func (r *DemoRunner) fixture(model, appendSystemPrompt string) string {
+	switch {
+	case strings.Contains(appendSystemPrompt, "## Post-Remediation Verification"):
+		return "verify"
+	case model == r.cfg.Tier3Model:
+		return "tier3"
+	case model == r.cfg.Tier2Model || strings.Contains(appendSystemPrompt, "## Escalation Context"):
+		return "tier2"
+	default:
+		return "tier1"
+	}
+}
+
+// demoSummary stands in for the summary model in demo mode: the agent's
+// first paragraph that is not a markdown heading or table.
+func demoSummary(_ context.Context, response string, _ string) (string, error) {
+	for _, para := range strings.Split(response, "\n\n") {
+		if para = strings.TrimSpace(para); para != "" && !strings.HasPrefix(para, "#") && !strings.HasPrefix(para, "|") {
+			return para, nil
+		}
+	}
+	return "", nil
+}
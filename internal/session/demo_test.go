@@ -0,0 +1,76 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/hub"
+)
+
+func TestDemoRunnerChain(t *testing.T) {
+	defer func(d time.Duration) { demoLineDelay = d }(demoLineDelay)
+	demoLineDelay = 0
+	base, database := testManagerWithDB(t)
+	cfg := base.cfg
+	cfg.Demo = true
+	cfg.DryRun = false
+	cfg.MaxTier = 3
+	cfg.Tier2Prompt = "/dev/null"
+	cfg.Tier3Prompt = "/dev/null"
+	m := New(cfg, database, hub.New(), NewDemoRunner(cfg))
+
+	m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	sessions, err := database.ListSessions(10, 0)
+	if err != nil || len(sessions) != 3 {
+		t.Fatalf("expected a three-tier chain, got %d sessions (%v)", len(sessions), err)
+	}
+	byTier := map[int]db.Session{}
+	for _, s := range sessions {
+		byTier[s.Tier] = s
+	}
+	if byTier[1].Status != "escalated" || byTier[2].Status != "escalated" || byTier[3].Status != "completed" {
+		t.Errorf("unexpected statuses: %s %s %s", byTier[1].Status, byTier[2].Status, byTier[3].Status)
+	}
+	if s := byTier[3].Summary; s == nil || !strings.Contains(*s, "Redeployed jellyfin") {
+		t.Errorf("expected demo summary on tier 3, got %v", s)
+	}
+
+	mems, err := database.ListMemories(nil, nil, nil, 10, 0)
+	if err != nil || len(mems) != 3 {
+		t.Errorf("expected a memory per tier, got %d (%v)", len(mems), err)
+	}
+	events, err := database.ListEvents(50, 0, db.EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var critical bool
+	for _, e := range events {
+		critical = critical || (e.Level == "critical" && strings.Contains(e.Message, "502"))
+	}
+	if !critical {
+		t.Error("expected the tier 1 critical event")
+	}
+}
+
+func TestDemoRunnerFixture(t *testing.T) {
+	base, _ := testManagerWithDB(t)
+	r := NewDemoRunner(base.cfg)
+	tests := []struct {
+		model, ctx, want string
+	}{
+		{"haiku", "", "tier1"},
+		{"haiku", "## Escalation Context\n", "tier2"},
+		{"sonnet", "", "tier2"},
+		{"opus", "## Escalation Context\n", "tier3"},
+		{"haiku", "## Post-Remediation Verification\n", "verify"},
+	}
+	for _, tt := range tests {
+		if got := r.fixture(tt.model, tt.ctx); got != tt.want {
+			t.Errorf("fixture(%q, %q) = %s, want %s", tt.model, tt.ctx, got, tt.want)
+		}
+	}
+}
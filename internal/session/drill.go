@@ -0,0 +1,169 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// canaryService is the synthetic service a self-test drill breaks. The web
+// server serves its health endpoint and records its restart and
+// notification while a drill is running.
+const canaryService = "claudeops-canary"
+
+// TriggerDrill queues a self-test drill. It does not wait for the drill to
+// start. Drills need escalation, so they are refused in dry-run mode.
+func (m *Manager) TriggerDrill() error {
+	if m.cfg.DryRun {
+		return fmt.Errorf("self-test drills are disabled in dry-run mode")
+	}
+	if m.IsRunning() {
+		return fmt.Errorf("session already running")
+	}
+	select {
+	case m.drillCh <- struct{}{}:
+		return nil
+	default:
+		return fmt.Errorf("drill already queued")
+	}
+}
+
+// RunSelfTest triggers a drill every SelfTestInterval hours until ctx is
+// cancelled. A drill that cannot start because a session is running is
+// retried every minute.
+func (m *Manager) RunSelfTest(ctx context.Context) {
+	interval := time.Duration(m.cfg.SelfTestInterval) * time.Hour
+	if interval <= 0 {
+		return
+	}
+	if m.cfg.DryRun {
+		fmt.Println("self-test drills disabled: dry-run mode suppresses escalation")
+		return
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if err := m.TriggerDrill(); err != nil {
+			fmt.Printf("[%s] self-test drill deferred: %v\n", time.Now().UTC().Format(time.RFC3339), err)
+			timer.Reset(time.Minute)
+			continue
+		}
+		timer.Reset(interval)
+	}
+}
+
+// runDrill runs the escalation chain against the canary and records which
+// stages of the pipeline worked. A failed drill is reported by the
+// supervisor itself, since the agent's own notification path may be what
+// is broken.
+func (m *Manager) runDrill(ctx context.Context) {
+	drillID, err := m.db.InsertDrill(&db.Drill{
+		Status:    "running",
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "insert drill: %v\n", err)
+		return
+	}
+
+	rootID := m.runEscalationChain(ctx, "drill", nil, 1, m.buildDrillContext(drillID), []string{canaryService})
+
+	// Re-read the drill: the canary endpoints record restart and notification.
+	d, err := m.db.GetDrill(drillID)
+	if err != nil || d == nil {
+		fmt.Fprintf(os.Stderr, "get drill %d: %v\n", drillID, err)
+		return
+	}
+	if rootID != 0 {
+		d.SessionID = &rootID
+		d.MaxTier = m.chainMaxTier(rootID)
+	}
+	d.Detected = d.MaxTier >= 2
+
+	var missing []string
+	if !d.Detected {
+		missing = append(missing, "Tier 1 did not escalate the canary")
+	}
+	if !d.Remediated {
+		missing = append(missing, "the canary was not restarted")
+	}
+	if !d.Notified {
+		missing = append(missing, "no notification reached the canary")
+	}
+	d.Status = "passed"
+	if len(missing) > 0 {
+		d.Status = "failed"
+		detail := strings.Join(missing, "; ")
+		d.Detail = &detail
+	}
+	endedAt := time.Now().UTC().Format(time.RFC3339)
+	d.EndedAt = &endedAt
+	if err := m.db.UpdateDrill(d); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+
+	if d.Status == "passed" {
+		if rootID != 0 {
+			m.emitEscalationEventLevel(rootID, "info", fmt.Sprintf("Self-test drill #%d passed (reached tier %d)", drillID, d.MaxTier))
+		}
+		return
+	}
+	msg := fmt.Sprintf("Self-test drill #%d failed: %s", drillID, *d.Detail)
+	if rootID != 0 {
+		m.emitEscalationEvent(rootID, msg)
+	}
+	if err := m.notify(ctx, "Claude Ops: Self-test drill failed", msg); err != nil {
+		fmt.Fprintf(os.Stderr, "drill notify: %v\n", err)
+	}
+}
+
+// chainMaxTier returns the highest tier reached by the escalation chain
+// starting at rootID.
+func (m *Manager) chainMaxTier(rootID int64) int {
+	maxTier := 0
+	if s, err := m.db.GetSession(rootID); err == nil && s != nil {
+		maxTier = s.Tier
+	}
+	for id := rootID; ; {
+		children, err := m.db.GetChildSessions(id)
+		if err != nil || len(children) == 0 {
+			return maxTier
+		}
+		child := children[0]
+		if child.Tier > maxTier {
+			maxTier = child.Tier
+		}
+		id = child.ID
+	}
+}
+
+// buildDrillContext tells the agent about the canary. The endpoints are
+// served by the dashboard, so they are reachable on localhost.
+func (m *Manager) buildDrillContext(drillID int64) string {
+	base, curlOpts := m.localAPI()
+	curl := "curl"
+	if curlOpts != "" {
+		curl += " " + curlOpts
+	}
+	var b strings.Builder
+	b.WriteString("## Self-Test Drill\n\n")
+	fmt.Fprintf(&b, "This chain is scheduled self-test drill #%d, not a real incident. The supervisor is serving a synthetic failing service to check that detection, investigation, remediation, and notification still work end to end. Handle it exactly as you would a real failure, with these additions:\n\n", drillID)
+	fmt.Fprintf(&b, "- **%s** is a service you MUST check. Health check: `%s -sS -o /dev/null -w '%%{http_code}' %s/selftest/canary` (healthy only when it returns 200). Report it in `services_checked`.\n", canaryService, curl, base)
+	fmt.Fprintf(&b, "- Restarting the canary is a Tier 2 remediation: `%s -fsS -X POST %s/api/v1/selftest/canary/restart`. Tier 1 MUST NOT run it. The canary is exempt from cooldown limits; do not record a cooldown for it.\n", curl, base)
+	if notify := m.localNotifyURL("/api/v1/selftest/notify"); notify != "" {
+		fmt.Fprintf(&b, "- After remediating, send the usual remediation notification with a title starting with \"[DRILL]\". Send it even if CLAUDEOPS_APPRISE_URLS is empty, and add `%s` as an extra Apprise URL so the supervisor can confirm delivery.\n", notify)
+	} else {
+		fmt.Fprintf(&b, "- After remediating, send the usual remediation notification with a title starting with \"[DRILL]\", then confirm delivery to the supervisor with `%s -fsS -X POST -H 'Content-Type: application/json' -d '{\"title\":\"[DRILL] <title>\",\"body\":\"<body>\"}' %s/api/v1/selftest/notify` using the same title and body. Do both even if CLAUDEOPS_APPRISE_URLS is empty.\n", curl, base)
+	}
+	b.WriteString("- Do not record memories about the canary or this drill.\n")
+	return b.String()
+}
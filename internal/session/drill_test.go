@@ -0,0 +1,153 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTriggerDrill(t *testing.T) {
+	m, _ := testManager(t)
+	if err := m.TriggerDrill(); err == nil {
+		t.Fatal("expected drill to be refused in dry-run mode")
+	}
+
+	m.cfg.DryRun = false
+	if err := m.TriggerDrill(); err != nil {
+		t.Fatalf("TriggerDrill: %v", err)
+	}
+	if err := m.TriggerDrill(); err == nil {
+		t.Fatal("expected second drill to be rejected while one is queued")
+	}
+}
+
+func TestRunDrill(t *testing.T) {
+	// Tier 1 escalates the canary; tier 2 reports it healthy.
+	escalating := `{"type":"result","result":"Canary down.","is_error":false,"structured_output":{"summary":"canary down","events":[],"memories":[{"key":"claudeops-canary:behavior","value":"canary flaps"}],"escalation":{"needed":true,"reason":"canary down"},"services_checked":[{"name":"claudeops-canary","status":"down"}]}}`
+
+	tests := []struct {
+		name          string
+		agentMarks    bool // the agent restarts the canary and notifies
+		wantStatus    string
+		wantSupNotify bool
+	}{
+		{"passed", true, "passed", false},
+		{"failed", false, "failed", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, database := testManagerWithDB(t)
+			m.cfg.DryRun = false
+			m.cfg.MaxTier = 2
+			m.cfg.Tier2Prompt = "/dev/null"
+			m.runner = &pipeRunner{
+				events:    []string{`{"type":"system","subtype":"init"}`, escalating},
+				resultIdx: 1,
+			}
+			// Stand in for the agent calling the canary endpoints.
+			m.PreSessionHook = func() error {
+				if !tt.agentMarks {
+					return nil
+				}
+				d, err := database.GetRunningDrill()
+				if err != nil || d == nil {
+					return err
+				}
+				d.Remediated, d.Notified = true, true
+				return database.UpdateDrill(d)
+			}
+			var notified []string
+			m.notify = func(_ context.Context, _, body string) error {
+				notified = append(notified, body)
+				return nil
+			}
+
+			m.runDrill(context.Background())
+
+			drills, err := database.ListDrills(1)
+			if err != nil || len(drills) != 1 {
+				t.Fatalf("ListDrills: %+v %v", drills, err)
+			}
+			d := drills[0]
+			if d.Status != tt.wantStatus || !d.Detected || d.MaxTier != 2 || d.SessionID == nil || d.EndedAt == nil {
+				t.Errorf("unexpected drill %+v", d)
+			}
+			if tt.wantSupNotify != (len(notified) == 1) {
+				t.Errorf("supervisor notifications = %v, want notify=%v", notified, tt.wantSupNotify)
+			}
+			if tt.wantSupNotify && !strings.Contains(notified[0], "the canary was not restarted") {
+				t.Errorf("expected missing stages in notification, got %q", notified[0])
+			}
+
+			root, err := database.GetSession(*d.SessionID)
+			if err != nil || root.Trigger != "drill" {
+				t.Fatalf("expected drill root session, got %+v (err %v)", root, err)
+			}
+			children, _ := database.GetChildSessions(root.ID)
+			if len(children) != 1 || children[0].Trigger != "drill" {
+				t.Errorf("expected escalated session to keep the drill trigger, got %+v", children)
+			}
+			if mems, _ := database.GetActiveMemories(10, false); len(mems) != 0 {
+				t.Errorf("expected drill memories to be discarded, got %+v", mems)
+			}
+		})
+	}
+}
+
+func TestBuildDrillContextTLS(t *testing.T) {
+	m, _ := testManager(t)
+	m.cfg.DashboardPort = 8443
+	if ctx := m.buildDrillContext(1); !strings.Contains(ctx, "curl -sS -o /dev/null -w '%{http_code}' http://127.0.0.1:8443/selftest/canary") ||
+		!strings.Contains(ctx, "`json://127.0.0.1:8443/api/v1/selftest/notify`") {
+		t.Errorf("plain HTTP drill context:\n%s", ctx)
+	}
+
+	m.cfg.TLSCert, m.cfg.TLSKey = "cert.pem", "key.pem"
+	ctx := m.buildDrillContext(1)
+	for _, want := range []string{
+		"curl -k -fsS -X POST https://127.0.0.1:8443/api/v1/selftest/canary/restart",
+		"`jsons://127.0.0.1:8443/api/v1/selftest/notify?verify=no`",
+	} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("HTTPS drill context missing %q:\n%s", want, ctx)
+		}
+	}
+}
+
+func TestBuildDrillContextSocket(t *testing.T) {
+	m, _ := testManager(t)
+	m.cfg.DashboardSocket = "/run/claudeops/dashboard.sock"
+	ctx := m.buildDrillContext(1)
+	for _, want := range []string{
+		"curl --unix-socket /run/claudeops/dashboard.sock -sS -o /dev/null -w '%{http_code}' http://localhost/selftest/canary",
+		"curl --unix-socket /run/claudeops/dashboard.sock -fsS -X POST -H 'Content-Type: application/json'",
+		"http://localhost/api/v1/selftest/notify",
+	} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("socket drill context missing %q:\n%s", want, ctx)
+		}
+	}
+	if strings.Contains(ctx, "json://") {
+		t.Errorf("socket drill context offers an Apprise URL:\n%s", ctx)
+	}
+}
+
+func TestBuildDrillContextAgentPort(t *testing.T) {
+	m, _ := testManager(t)
+	m.cfg.DashboardSocket = "/run/claudeops/dashboard.sock"
+	m.cfg.TLSCert, m.cfg.TLSKey = "cert.pem", "key.pem"
+	m.cfg.AgentPort = 8081
+	ctx := m.buildDrillContext(1)
+	for _, want := range []string{
+		"curl -sS -o /dev/null -w '%{http_code}' http://127.0.0.1:8081/selftest/canary",
+		"curl -fsS -X POST http://127.0.0.1:8081/api/v1/selftest/canary/restart",
+		"`json://127.0.0.1:8081/api/v1/selftest/notify`",
+	} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("agent port drill context missing %q:\n%s", want, ctx)
+		}
+	}
+	if strings.Contains(ctx, "unix-socket") {
+		t.Errorf("agent port drill context uses the dashboard socket:\n%s", ctx)
+	}
+}
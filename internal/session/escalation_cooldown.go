@@ -0,0 +1,112 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// repeatEscalation is an earlier chain's escalation for a service, which
+// keeps the service in escalation cooldown.
+type repeatEscalation struct {
+	Service   string
+	SessionID int64 // the session that escalated
+	Ago       time.Duration
+}
+
+// repeatEscalations returns, for each service in services, the latest
+// escalation for it by another chain within the last
+// cfg.EscalationCooldown minutes. It returns nil unless every service has
+// one: an escalation that is also for a service no chain has tried yet goes
+// ahead. sessionID is the session asking to escalate; its own chain's
+// escalations do not count.
+func (m *Manager) repeatEscalations(sessionID int64, services []string, now time.Time) ([]repeatEscalation, error) {
+	if m.cfg.EscalationCooldown <= 0 || len(services) == 0 {
+		return nil, nil
+	}
+	window := time.Duration(m.cfg.EscalationCooldown) * time.Minute
+	recent, err := m.db.ListEscalationsSince(now.Add(-window).UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	sameChain := make(map[int64]bool)
+	if sessionID != 0 {
+		chain, err := m.db.GetEscalationChain(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range chain {
+			sameChain[s.ID] = true
+		}
+	}
+
+	var repeats []repeatEscalation
+	for _, svc := range services {
+		found := false
+		for _, d := range recent {
+			if sameChain[d.SessionID] || !containsService(strings.Split(d.Services, ","), svc) {
+				continue
+			}
+			var ago time.Duration
+			if t, err := time.Parse(time.RFC3339, d.CreatedAt); err == nil {
+				ago = now.Sub(t)
+			}
+			repeats = append(repeats, repeatEscalation{Service: svc, SessionID: d.SessionID, Ago: ago})
+			found = true
+			break
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+	return repeats, nil
+}
+
+func containsService(list []string, svc string) bool {
+	for _, s := range list {
+		if strings.EqualFold(strings.TrimSpace(s), svc) {
+			return true
+		}
+	}
+	return false
+}
+
+// repeatEscalationMessage explains why an escalation to tier was
+// suppressed.
+func repeatEscalationMessage(tier, cooldown int, repeats []repeatEscalation) string {
+	parts := make([]string, len(repeats))
+	for i, r := range repeats {
+		parts[i] = fmt.Sprintf("%s (session #%d, %s ago)", r.Service, r.SessionID, r.Ago.Round(time.Minute))
+	}
+	return fmt.Sprintf("Suppressed repeat escalation to tier %d: another chain escalated for %s within the %d-minute escalation cooldown",
+		tier, strings.Join(parts, ", "), cooldown)
+}
+
+// checkEscalationCooldown suppresses an escalation for services that other
+// chains escalated for within the escalation cooldown, so a service that
+// keeps failing does not pay for the same higher-tier investigation every
+// run. A suppressed escalation is recorded as a warning event and a human
+// is notified. It returns the message explaining the suppression, or ""
+// when the escalation may go ahead. Drills are exempt.
+func (m *Manager) checkEscalationCooldown(ctx context.Context, sessionID int64, tier int, trigger string, services []string) string {
+	if trigger == "drill" {
+		return ""
+	}
+	repeats, err := m.repeatEscalations(sessionID, services, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: escalation cooldown: %v\n", sessionID, err)
+		return ""
+	}
+	if len(repeats) == 0 {
+		return ""
+	}
+	msg := repeatEscalationMessage(tier, m.cfg.EscalationCooldown, repeats)
+	m.emitEscalationEventLevel(sessionID, "warning", msg)
+	fmt.Printf("[%s] %s\n", time.Now().UTC().Format(time.RFC3339), msg)
+	if err := m.notify(ctx, "Claude Ops: Repeat escalation suppressed for "+strings.Join(services, ", "), msg); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: escalation cooldown notification: %v\n", sessionID, err)
+	}
+	return msg
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestEscalationCooldownSuppressesRepeatChain(t *testing.T) {
+	runner := testkit.NewRunner(
+		// First chain: tier 1 escalates jellyfin, and tier 2 asks for tier 3,
+		// which its own chain's escalation does not hold back.
+		testkit.Script{Events: []testkit.Event{testkit.Result("Jellyfin is down.", testkit.Escalate("jellyfin returns 502", "jellyfin"))}},
+		testkit.Script{Events: []testkit.Event{testkit.Result("Still failing.", testkit.Escalate("jellyfin still returns 502", "jellyfin"))}},
+		// Second chain: jellyfin again, within the cooldown.
+		testkit.Script{Events: []testkit.Event{testkit.Result("Jellyfin is down again.", testkit.Escalate("jellyfin returns 502", "jellyfin"))}},
+		// Third chain: sonarr has not been escalated yet, so it goes ahead.
+		testkit.Script{Events: []testkit.Event{testkit.Result("Two services down.", testkit.Escalate("502s", "jellyfin", "sonarr"))}},
+		testkit.Script{Events: []testkit.Event{testkit.Result("Fixed.", testkit.Healthy("all healthy", "jellyfin", "sonarr"))}},
+	)
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 2
+	m.cfg.EscalationCooldown = 120
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.runner = runner
+	var notified []string
+	m.notify = func(_ context.Context, title, _ string) error {
+		notified = append(notified, title)
+		return nil
+	}
+
+	first := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+	children, err := database.GetChildSessions(first)
+	if err != nil || len(children) != 1 {
+		t.Fatalf("first chain did not escalate: %+v (%v)", children, err)
+	}
+	if d, _ := database.GetEscalationDecision(children[0].ID); d == nil || d.Outcome != DecisionMaxTier {
+		t.Errorf("own chain's escalation held back tier 2: %+v", d)
+	}
+
+	second := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+	d, err := database.GetEscalationDecision(second)
+	if err != nil || d == nil {
+		t.Fatalf("second chain decision: %+v (%v)", d, err)
+	}
+	if d.Outcome != DecisionCooldown || d.Tier != 0 || !strings.Contains(d.Reason, "jellyfin (session #") {
+		t.Errorf("unexpected second chain decision %+v", d)
+	}
+	if children, _ := database.GetChildSessions(second); len(children) != 0 {
+		t.Errorf("suppressed chain escalated anyway: %+v", children)
+	}
+	if len(notified) != 1 || !strings.Contains(notified[0], "jellyfin") {
+		t.Errorf("notifications = %q", notified)
+	}
+
+	third := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+	if d, _ := database.GetEscalationDecision(third); d == nil || d.Outcome != DecisionEscalated {
+		t.Errorf("escalation for a new service was suppressed: %+v", d)
+	}
+
+	sim, err := m.Simulate(SimulationRequest{RecommendedTier: 2, ServicesAffected: []string{"sonarr"}})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if last := sim.Steps[len(sim.Steps)-1]; sim.Outcome != SimulationBlocked || last.Check != "escalation_cooldown" {
+		t.Errorf("simulation = %+v", sim)
+	}
+	if sim, _ := m.Simulate(SimulationRequest{RecommendedTier: 2, ServicesAffected: []string{"sonarr"}, Trigger: "drill"}); sim.Outcome != SimulationEscalate {
+		t.Errorf("drill simulation = %+v", sim)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 // ---------------------------------------------------------------------------
@@ -331,6 +332,9 @@ func TestFormatStreamEvent_UnicodeToolResult(t *testing.T) {
 	if !strings.HasPrefix(got, "[result] ") {
 		t.Errorf("unicode tool result: got %q, expected [result] prefix", got)
 	}
+	if !utf8.ValidString(got) {
+		t.Errorf("unicode tool result split a character: %q", got)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -512,6 +516,17 @@ func TestTruncateString_Over(t *testing.T) {
 	}
 }
 
+func TestTruncateString_MultiByte(t *testing.T) {
+	got := truncateString(strings.Repeat("世", 301), 300)
+	if got != strings.Repeat("世", 300)+"..." {
+		t.Errorf("CJK: got %d runes", utf8.RuneCountInString(got))
+	}
+	// A cut inside an emoji with a skin tone drops the whole emoji.
+	if got := truncateString("done 👍🏽 ok", 6); got != "done ..." {
+		t.Errorf("emoji: got %q", got)
+	}
+}
+
 func TestTruncateString_CollapsesWhitespace(t *testing.T) {
 	got := truncateString("hello   world\n\ttab", 300)
 	if got != "hello world tab" {
@@ -844,6 +859,67 @@ func TestFormatStreamEventHTML_AssistantTextNoEventMarker(t *testing.T) {
 	}
 }
 
+func TestFormatStreamEventHTML_AssistantMarkdown(t *testing.T) {
+	text := "## Findings\n\n| service | status |\n|---|---|\n| nginx | up |\n\n- one\n- two\n\n```\ndocker ps\n```"
+	b, _ := json.Marshal(text)
+	raw := `{"type":"assistant","message":{"content":[{"type":"text","text":` + string(b) + `}]}}`
+	got := FormatStreamEventHTML(raw)
+	for _, want := range []string{"term-markdown", "<h2>Findings</h2>", "<table>", "<td>nginx</td>", "<li>one</li>", "<pre><code>docker ps"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestFormatStreamEventHTML_AssistantMarkdownAroundEvent(t *testing.T) {
+	raw := `{"type":"assistant","message":{"content":[{"type":"text","text":"**Checking** services\n[EVENT:warning:nginx] Slow responses\n- done"}]}}`
+	got := FormatStreamEventHTML(raw)
+	ev := strings.Index(got, "term-event-block")
+	if ev < 0 || strings.Index(got, "<strong>Checking</strong>") > ev || strings.LastIndex(got, "<li>done</li>") < ev {
+		t.Errorf("expected markdown before and after the event badge, got %q", got)
+	}
+}
+
+func TestFormatStreamEventHTML_AssistantMarkdownUnsafe(t *testing.T) {
+	raw := `{"type":"assistant","message":{"content":[{"type":"text","text":"<img src=x onerror=alert(1)> and [link](javascript:alert(1))"}]}}`
+	got := FormatStreamEventHTML(raw)
+	if strings.Contains(got, "<img") || strings.Contains(got, `href="javascript`) {
+		t.Errorf("unsafe markup rendered: %q", got)
+	}
+	if !strings.Contains(got, "&lt;img") {
+		t.Errorf("expected raw HTML to be shown escaped, got %q", got)
+	}
+}
+
+func TestFormatStreamEventHTMLAt_ExpandTruncatedResult(t *testing.T) {
+	long := strings.Repeat("z", toolResultHTMLMax+1)
+	raw := `{"type":"user","message":{"content":[{"type":"tool_result","content":"` + long + `"}]}}`
+
+	got := FormatStreamEventHTMLAt(raw, 12, 34)
+	if !strings.Contains(got, `hx-get="/sessions/12/log/34"`) || !strings.Contains(got, "2001 bytes") {
+		t.Errorf("expected expand control, got %.200q", got[len(got)-300:])
+	}
+	if strings.Contains(FormatStreamEventHTML(raw), "hx-get") {
+		t.Error("expected no expand control without a log line")
+	}
+	short := `{"type":"user","message":{"content":[{"type":"tool_result","content":"ok"}]}}`
+	if strings.Contains(FormatStreamEventHTMLAt(short, 12, 34), "hx-get") {
+		t.Error("expected no expand control for an untruncated result")
+	}
+}
+
+func TestFullToolResultHTML(t *testing.T) {
+	long := strings.Repeat("<", toolResultHTMLMax+1)
+	raw := `{"type":"user","message":{"content":[{"type":"tool_result","content":"` + long + `"}]}}`
+	got := FullToolResultHTML(raw)
+	if !strings.Contains(got, strings.Repeat("&lt;", toolResultHTMLMax+1)) || !strings.HasPrefix(got, "<details") {
+		t.Errorf("expected full escaped result in a details block, got %.100q", got)
+	}
+	if FullToolResultHTML(`{"type":"assistant","message":{"content":[]}}`) != "" {
+		t.Error("expected empty output for a non tool-result line")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // parseMarkers (generic DRY parser)
 // ---------------------------------------------------------------------------
@@ -1326,3 +1402,62 @@ func TestStructuredOutputDeserialization_EscalationNeeded(t *testing.T) {
 		t.Errorf("FailedChecks[1] = %q", resp.Escalation.FailedChecks[1])
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Extended thinking blocks
+// ---------------------------------------------------------------------------
+
+func TestFormatStreamEvent_Thinking(t *testing.T) {
+	raw := `{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"Check the\nnginx logs first.","signature":"abc"},{"type":"text","text":"Checking nginx."}]}}`
+	got := FormatStreamEvent(raw)
+	want := "[thinking] Check the nginx logs first.\nChecking nginx."
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFormatStreamEvent_RedactedThinking(t *testing.T) {
+	raw := `{"type":"assistant","message":{"content":[{"type":"redacted_thinking","data":"opaque"}]}}`
+	if got := FormatStreamEvent(raw); got != "[thinking] (redacted)" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestFormatStreamEventHTML_ThinkingCollapsed(t *testing.T) {
+	raw := `{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"Is <db> down?"}]}}`
+	got := FormatStreamEventHTML(raw)
+	if !strings.HasPrefix(got, `<details class="term-thinking-block">`) || strings.Contains(got, "<details open") {
+		t.Errorf("expected a collapsed details block, got %q", got)
+	}
+	if !strings.Contains(got, "Is &lt;db&gt; down?") {
+		t.Errorf("expected escaped thinking text, got %q", got)
+	}
+}
+
+func TestFormatStreamEventHTML_EmptyThinking(t *testing.T) {
+	raw := `{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"  "}]}}`
+	if got := FormatStreamEventHTML(raw); got != "" {
+		t.Errorf("expected empty thinking to be suppressed, got %q", got)
+	}
+}
+
+func TestStripThinking(t *testing.T) {
+	raw := `{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"secret"},{"type":"redacted_thinking","data":"x"},{"type":"text","text":"ok"}],"id":"m1"}}`
+	got := stripThinking(raw)
+	if strings.Contains(got, "secret") || strings.Contains(got, "redacted_thinking") {
+		t.Errorf("thinking not stripped: %s", got)
+	}
+	if FormatStreamEvent(got) != "ok" || !strings.Contains(got, `"id":"m1"`) {
+		t.Errorf("expected remaining content preserved, got %s", got)
+	}
+
+	for _, unchanged := range []string{
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"thinking about it"}]}}`,
+		`{"type":"result","result":"no thinking here"}`,
+		`not json thinking`,
+	} {
+		if got := stripThinking(unchanged); got != unchanged {
+			t.Errorf("stripThinking(%q) = %q, want unchanged", unchanged, got)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// freshCoverage is what recent sessions already checked of what a
+// scheduled Tier 1 run would check.
+type freshCoverage struct {
+	// expected are the services the latest full scheduled run checked.
+	expected []string
+	// fresh are the latest healthy checks, within the freshness window, of
+	// the expected services, and stale the expected services without one.
+	fresh map[string]db.HealthCheck
+	stale []string
+}
+
+// checkFreshness compares the services a scheduled Tier 1 run is expected
+// to cover with the services finished sessions found healthy in the last
+// cfg.FreshnessWindow minutes. It returns nil when the window is disabled
+// or no full scheduled run has recorded its checks yet.
+func (m *Manager) checkFreshness(now time.Time) (*freshCoverage, error) {
+	if m.cfg.FreshnessWindow <= 0 {
+		return nil, nil
+	}
+	expected, _, err := m.db.ScheduledCoverage()
+	if err != nil || len(expected) == 0 {
+		return nil, err
+	}
+	window := time.Duration(m.cfg.FreshnessWindow) * time.Minute
+	checks, err := m.db.FreshServiceChecks(now.Add(-window).UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	fc := &freshCoverage{expected: expected, fresh: make(map[string]db.HealthCheck)}
+	for _, svc := range expected {
+		if hc, ok := checks[svc]; ok {
+			fc.fresh[svc] = hc
+		} else {
+			fc.stale = append(fc.stale, svc)
+		}
+	}
+	return fc, nil
+}
+
+// sessions returns the IDs of the sessions that made the fresh checks.
+func (fc *freshCoverage) sessions() []int64 {
+	var ids []int64
+	for _, hc := range fc.fresh {
+		if hc.SessionID != nil && !slices.Contains(ids, *hc.SessionID) {
+			ids = append(ids, *hc.SessionID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// runScheduled runs the scheduled Tier 1 observation. Services that a
+// finished session found healthy within the freshness window are left out
+// of its scope; when that leaves none, the run is skipped and recorded as a
+// "skipped" session so the history has no gap. It returns the ID of the
+// chain's first session or of the skipped session, or 0 if none was
+// recorded.
+func (m *Manager) runScheduled(ctx context.Context) int64 {
+	fc, err := m.checkFreshness(time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check freshness: %v\n", err)
+	}
+	switch {
+	case fc == nil || len(fc.fresh) == 0:
+		return m.runEscalationChain(ctx, "scheduled", nil, 1, "", nil)
+	case len(fc.stale) == 0:
+		return m.recordSkippedRun(fc)
+	default:
+		fmt.Printf("[%s] Scheduled run narrowed to %s: %d service(s) checked within the last %d minutes\n",
+			time.Now().UTC().Format(time.RFC3339), strings.Join(fc.stale, ", "), len(fc.fresh), m.cfg.FreshnessWindow)
+		return m.runEscalationChain(ctx, "scheduled", nil, 1, m.freshnessContext(fc), fc.stale)
+	}
+}
+
+// freshnessContext tells a narrowed scheduled run which services it was
+// narrowed to and why.
+func (m *Manager) freshnessContext(fc *freshCoverage) string {
+	fresh := make([]string, 0, len(fc.fresh))
+	for svc := range fc.fresh {
+		fresh = append(fresh, svc)
+	}
+	slices.Sort(fresh)
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Scope\n\nCheck only these services: %s.\n\n", strings.Join(fc.stale, ", "))
+	fmt.Fprintf(&b, "These services were checked and found healthy within the last %d minutes by session(s) %s, so they are out of scope for this run: %s.\n",
+		m.cfg.FreshnessWindow, sessionRefs(fc.sessions()), strings.Join(fresh, ", "))
+	return b.String()
+}
+
+// recordSkippedRun records a scheduled run skipped because every service
+// it would check was checked recently, and runs the session end hooks for
+// it, so a heartbeat still reports that the checks are working. It returns
+// the skipped session's ID, or 0 if it could not be recorded.
+func (m *Manager) recordSkippedRun(fc *freshCoverage) int64 {
+	now := time.Now().UTC().Format(time.RFC3339)
+	msg := fmt.Sprintf("Skipped – fresh: all %d services were checked and found healthy within the last %d minutes by session(s) %s.",
+		len(fc.expected), m.cfg.FreshnessWindow, sessionRefs(fc.sessions()))
+	sess := &db.Session{
+		Tier:       1,
+		Model:      m.cfg.Tier1Model,
+		PromptFile: m.cfg.Prompt,
+		Status:     "skipped",
+		StartedAt:  now,
+		EndedAt:    &now,
+		Trigger:    "scheduled",
+		Response:   &msg,
+	}
+	id, err := m.db.InsertSession(sess)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "record skipped run: %v\n", err)
+		return 0
+	}
+	sess.ID = id
+	if err := m.db.UpdateSessionResult(id, msg, 0, 0, 0); err != nil {
+		fmt.Fprintf(os.Stderr, "record skipped run %d: %v\n", id, err)
+	}
+	fmt.Printf("[%s] Scheduled run skipped (session #%d): %s\n", now, id, msg)
+	m.runHooks("OnSessionEnd", func(h Hooks) { h.OnSessionEnd(sess) })
+	return id
+}
+
+// sessionRefs formats session IDs as "#1, #2".
+func sessionRefs(ids []int64) string {
+	refs := make([]string, len(ids))
+	for i, id := range ids {
+		refs[i] = fmt.Sprintf("#%d", id)
+	}
+	return strings.Join(refs, ", ")
+}
@@ -0,0 +1,79 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestCheckFreshness(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	now := time.Now().UTC()
+	at := func(ago time.Duration) string { return now.Add(-ago).Format(time.RFC3339) }
+	insert := func(trigger, status string, ago time.Duration, checks map[string]string) int64 {
+		id, err := database.InsertSession(&db.Session{Tier: 1, Model: "haiku", PromptFile: "/p.md", Status: status, StartedAt: at(ago), Trigger: trigger})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		for svc, status := range checks {
+			if _, err := database.InsertHealthCheck(&db.HealthCheck{SessionID: &id, Service: svc, CheckType: "service", Status: status, CheckedAt: at(ago)}); err != nil {
+				t.Fatalf("InsertHealthCheck: %v", err)
+			}
+		}
+		return id
+	}
+
+	insert("scheduled", "completed", 2*time.Hour, map[string]string{"caddy": "healthy", "postgres": "healthy", "redis": "healthy"})
+	if fc, err := m.checkFreshness(now); err != nil || fc != nil {
+		t.Fatalf("disabled window: %+v, %v", fc, err)
+	}
+
+	m.cfg.FreshnessWindow = 30
+	fc, err := m.checkFreshness(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.fresh) != 0 || strings.Join(fc.stale, ",") != "caddy,postgres,redis" {
+		t.Errorf("nothing checked recently: fresh %v, stale %v", fc.fresh, fc.stale)
+	}
+
+	manual := insert("manual", "completed", 10*time.Minute, map[string]string{"caddy": "healthy", "postgres": "healthy"})
+	insert("manual", "failed", 5*time.Minute, map[string]string{"redis": "healthy"})
+	insert("pulse", "completed", 5*time.Minute, map[string]string{"postgres": "down"})
+	fc, err = m.checkFreshness(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.fresh) != 1 || fc.fresh["caddy"].SessionID == nil || *fc.fresh["caddy"].SessionID != manual {
+		t.Errorf("fresh = %v, want caddy from session %d", fc.fresh, manual)
+	}
+	if strings.Join(fc.stale, ",") != "postgres,redis" {
+		t.Errorf("stale = %v, want postgres and redis (failed session, latest check down)", fc.stale)
+	}
+	if ctx := m.freshnessContext(fc); !strings.Contains(ctx, "Check only these services: postgres, redis.") || !strings.Contains(ctx, sessionRefs([]int64{manual})) {
+		t.Errorf("freshness context = %q", ctx)
+	}
+
+	chat := insert("manual", "completed", time.Minute, map[string]string{"postgres": "healthy", "redis": "healthy"})
+	fc, err = m.checkFreshness(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.stale) != 0 {
+		t.Fatalf("stale = %v, want every service fresh", fc.stale)
+	}
+	m.recordSkippedRun(fc)
+	skipped, err := database.FindSessions(db.SessionFilter{Status: strPtr("skipped")}, db.SessionSortStarted, false, 10, 0)
+	if err != nil || len(skipped) != 1 {
+		t.Fatalf("skipped sessions: %v, %v", skipped, err)
+	}
+	s := skipped[0]
+	if s.Trigger != "scheduled" || s.EndedAt == nil || s.Response == nil || !strings.HasPrefix(*s.Response, "Skipped – fresh") {
+		t.Errorf("skipped session = %+v", s)
+	}
+	if refs := sessionRefs([]int64{manual, chat}); !strings.Contains(*s.Response, refs) {
+		t.Errorf("skipped response %q does not name sessions %s", *s.Response, refs)
+	}
+}
@@ -0,0 +1,369 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/textutil"
+)
+
+const (
+	// maxCommitDiff bounds the diff stored for the session page.
+	maxCommitDiff = 256 << 10
+
+	// gitCommitTimeout bounds committing one repository's changes;
+	// gitPublishTimeout bounds pushing the branch and opening its pull
+	// request.
+	gitCommitTimeout  = 30 * time.Second
+	gitPublishTimeout = 2 * time.Minute
+
+	gitAuthorName  = "Claude Ops"
+	gitAuthorEmail = "claude-ops@localhost"
+)
+
+// gitBaseline is the uncommitted state of a repository when a session
+// started: each changed or untracked path, relative to the repository root,
+// with a hash of its content ("" when deleted).
+type gitBaseline map[string]string
+
+// startGitBaselines records the uncommitted state of each repository a
+// session may edit, so that only the paths the session changes are
+// committed for it.
+func (m *Manager) startGitBaselines(sessionID int64, tier int) {
+	if !m.cfg.GitAutoCommit || m.cfg.DryRun || tier < 2 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gitCommitTimeout)
+	defer cancel()
+	baselines := make(map[string]gitBaseline)
+	for _, repo := range m.gitRepos(tier) {
+		b, err := dirtyPaths(ctx, repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: git status in %s: %v\n", sessionID, repo, err)
+			continue
+		}
+		baselines[repo] = b
+	}
+	m.gitBaselines.Store(sessionID, baselines)
+}
+
+// commitSessionChanges commits the file changes a Tier 2 or Tier 3 session
+// made in each git repository to a claudeops/session-<id> branch. Paths
+// that were already changed or untracked when the session started are left
+// out unless the session changed them further. The working tree and the
+// checked-out branch are left as they are, so the changes stay in effect
+// for the rest of the chain. It runs before the session's end is announced,
+// so an escalated tier never has its edits swept into this session's commit.
+// Pushing and opening pull requests continue in the background.
+func (m *Manager) commitSessionChanges(sess *db.Session) []db.SessionCommit {
+	var baselines map[string]gitBaseline
+	if v, ok := m.gitBaselines.LoadAndDelete(sess.ID); ok {
+		baselines = v.(map[string]gitBaseline)
+	}
+	if !m.cfg.GitAutoCommit || m.cfg.DryRun || sess.Tier < 2 {
+		return nil
+	}
+	var commits []db.SessionCommit
+	for _, repo := range m.gitRepos(sess.Tier) {
+		c, err := commitRepo(repo, sess, baselines[repo])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: commit changes in %s: %v\n", sess.ID, repo, err)
+			continue
+		}
+		if c == nil {
+			continue
+		}
+		c.Diff = m.redactor.Redact(c.Diff)
+		if _, err := m.db.InsertSessionCommit(c); err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: %v\n", sess.ID, err)
+			continue
+		}
+		fmt.Printf("[session %d] Committed changes in %s to %s (%s)\n", sess.ID, repo, c.Branch, shortSHA(c.CommitSHA))
+		commits = append(commits, *c)
+		if m.cfg.GitPush {
+			go m.publishSessionCommit(*c, sess)
+		}
+	}
+	return commits
+}
+
+// gitRepos returns the repositories a session may have edited: the tier's
+// working directory and each repository under the repos directory. Only
+// repository roots count, so a repos directory nested inside another
+// checkout never commits to the outer one.
+func (m *Manager) gitRepos(tier int) []string {
+	var repos []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		if seen[dir] {
+			return
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+			return
+		}
+		seen[dir] = true
+		repos = append(repos, dir)
+	}
+	if dir := m.tierDir(tier); dir != "" {
+		add(dir)
+	}
+	entries, _ := os.ReadDir(m.cfg.ReposDir)
+	for _, e := range entries {
+		if e.IsDir() {
+			add(filepath.Join(m.cfg.ReposDir, e.Name()))
+		}
+	}
+	return repos
+}
+
+// commitRepo commits the paths the session changed in repo, relative to
+// baseline (nil counts every uncommitted path as the session's), to the
+// session's branch. The commit is built in a temporary index on top of
+// HEAD, so neither the working tree, the real index, nor the checked-out
+// branch changes. Returns nil when the session changed nothing.
+func commitRepo(repo string, sess *db.Session, baseline gitBaseline) (*db.SessionCommit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitCommitTimeout)
+	defer cancel()
+
+	current, err := dirtyPaths(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for path, hash := range current {
+		if before, ok := baseline[path]; !ok || before != hash {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	head, err := runGit(ctx, repo, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	base, err := runGit(ctx, repo, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if base == "HEAD" {
+		base = head // detached
+	}
+
+	tmp, err := os.MkdirTemp("", "claudeops-index")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp) //nolint:errcheck
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index"), "GIT_LITERAL_PATHSPECS=1"}
+	git := func(args ...string) (string, error) { return runGitEnv(ctx, repo, env, args...) }
+
+	if _, err := git("read-tree", "HEAD"); err != nil {
+		return nil, err
+	}
+	if _, err := git(append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return nil, err
+	}
+	stat, err := git("diff", "--cached", "--stat")
+	if err != nil {
+		return nil, err
+	}
+	if stat == "" {
+		return nil, nil
+	}
+	diff, err := git("diff", "--cached")
+	if err != nil {
+		return nil, err
+	}
+	if cut, ok := textutil.CutBytes(diff, maxCommitDiff); ok {
+		diff = cut + "\n… diff truncated"
+	}
+	tree, err := git("write-tree")
+	if err != nil {
+		return nil, err
+	}
+	sha, err := git("-c", "user.name="+gitAuthorName, "-c", "user.email="+gitAuthorEmail,
+		"commit-tree", tree, "-p", head, "-m", commitMessage(sess))
+	if err != nil {
+		return nil, err
+	}
+	branch := fmt.Sprintf("claudeops/session-%d", sess.ID)
+	// The empty old value refuses to move a branch that already exists.
+	if _, err := git("update-ref", "refs/heads/"+branch, sha, ""); err != nil {
+		return nil, err
+	}
+
+	return &db.SessionCommit{
+		SessionID:  sess.ID,
+		Repo:       repo,
+		Branch:     branch,
+		BaseBranch: base,
+		CommitSHA:  sha,
+		DiffStat:   stat,
+		Diff:       diff,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// dirtyPaths returns the changed and untracked paths in repo, relative to
+// its root, with a hash of each one's content ("" when deleted).
+func dirtyPaths(ctx context.Context, repo string) (gitBaseline, error) {
+	// Not runGit: trimming would cut the leading space of the first entry.
+	out, err := gitOutput(ctx, repo, nil, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	paths := make(gitBaseline)
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 4 {
+			continue
+		}
+		names := []string{e[3:]}
+		if e[0] == 'R' || e[0] == 'C' {
+			// A rename or copy is followed by its source path.
+			if i+1 < len(entries) {
+				i++
+				names = append(names, entries[i])
+			}
+		}
+		for _, name := range names {
+			paths[name] = fileHash(filepath.Join(repo, name))
+		}
+	}
+	return paths, nil
+}
+
+// fileHash returns the hex SHA-256 of a file's content, or "" when it
+// cannot be read.
+func fileHash(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// commitTitle is the subject line of a session's commit and the title of
+// its pull request.
+func commitTitle(sess *db.Session) string {
+	title := fmt.Sprintf("claudeops: Tier %d session #%d changes", sess.Tier, sess.ID)
+	if sess.Services != nil && *sess.Services != "" {
+		title += " (" + strings.ReplaceAll(*sess.Services, ",", ", ") + ")"
+	}
+	return title
+}
+
+// commitMessage is the structured commit message for a session's changes:
+// a title, the agent's report, and trailers identifying the session.
+func commitMessage(sess *db.Session) string {
+	var b strings.Builder
+	b.WriteString(commitTitle(sess) + "\n\n")
+	if sess.Response != nil && strings.TrimSpace(*sess.Response) != "" {
+		b.WriteString(textutil.Truncate(strings.TrimSpace(*sess.Response), 2000) + "\n\n")
+	}
+	fmt.Fprintf(&b, "Claude-Ops-Session: %d\n", sess.ID)
+	fmt.Fprintf(&b, "Claude-Ops-Tier: %d\n", sess.Tier)
+	fmt.Fprintf(&b, "Claude-Ops-Model: %s\n", sess.Model)
+	fmt.Fprintf(&b, "Claude-Ops-Trigger: %s\n", sess.Trigger)
+	fmt.Fprintf(&b, "Claude-Ops-Status: %s\n", sess.Status)
+	if sess.Services != nil && *sess.Services != "" {
+		fmt.Fprintf(&b, "Claude-Ops-Services: %s\n", *sess.Services)
+	}
+	return b.String()
+}
+
+// publishSessionCommit pushes a session commit's branch to origin and, when
+// configured, opens a pull request for it, recording the outcome.
+func (m *Manager) publishSessionCommit(c db.SessionCommit, sess *db.Session) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitPublishTimeout)
+	defer cancel()
+
+	var prURL *string
+	pushed, err := false, error(nil)
+	if _, err = runGit(ctx, c.Repo, "push", "-q", "origin", c.Branch); err == nil {
+		pushed = true
+		if m.cfg.GitOpenPR {
+			var u string
+			if u, err = m.openSessionPR(ctx, c, sess); err == nil {
+				prURL = &u
+			}
+		}
+	}
+	var errMsg *string
+	if err != nil {
+		msg := err.Error()
+		errMsg = &msg
+		fmt.Fprintf(os.Stderr, "session %d: publish %s in %s: %v\n", c.SessionID, c.Branch, c.Repo, err)
+	}
+	if dbErr := m.db.UpdateSessionCommitPublish(c.ID, pushed, prURL, errMsg); dbErr != nil {
+		fmt.Fprintf(os.Stderr, "session %d: %v\n", c.SessionID, dbErr)
+	}
+}
+
+// openSessionPR opens a pull request from a session commit's branch into
+// the branch it was made from.
+func (m *Manager) openSessionPR(ctx context.Context, c db.SessionCommit, sess *db.Session) (string, error) {
+	if _, err := runGit(ctx, c.Repo, "show-ref", "--verify", "--quiet", "refs/heads/"+c.BaseBranch); err != nil {
+		return "", fmt.Errorf("no pull request: %s was made from a detached HEAD", c.Branch)
+	}
+	remote, err := runGit(ctx, c.Repo, "remote", "get-url", "origin")
+	if err != nil {
+		return "", err
+	}
+	body := fmt.Sprintf("Changes left by Claude Ops Tier %d session #%d.\n\n```\n%s\n```\n", sess.Tier, sess.ID, c.DiffStat)
+	if sess.Response != nil && strings.TrimSpace(*sess.Response) != "" {
+		body += "\n## Session report\n\n" + strings.TrimSpace(*sess.Response) + "\n"
+	}
+	return openPullRequest(ctx, remote, c.BaseBranch, c.Branch, commitTitle(sess), body)
+}
+
+// runGit runs git in dir and returns its trimmed output. Errors include
+// git's stderr.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	return runGitEnv(ctx, dir, nil, args...)
+}
+
+// runGitEnv is runGit with env added to git's environment.
+func runGitEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	out, err := gitOutput(ctx, dir, env, args...)
+	return strings.TrimSpace(out), err
+}
+
+// gitOutput runs git in dir with env added to its environment and returns
+// its output as is.
+func gitOutput(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	// Never wait on a credential prompt.
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,65 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// isHandBack reports whether a handoff from fromTier recommending tier
+// hands back down to a cheaper tier rather than escalating.
+func isHandBack(tier, fromTier int) bool {
+	return tier >= 1 && tier < fromTier
+}
+
+// handBackFindings is what a session that hands back reports to the
+// verification session: its summary and escalation context.
+func handBackFindings(resp *AgentResponse) string {
+	if resp == nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join([]string{resp.Summary, resp.Escalation.Context}, "\n\n"))
+}
+
+// handBack runs the verification session a session handed back to: an
+// observation-only session at tier, linked to sessionID in the same chain,
+// that checks services the way a scheduled verification does. Dry runs and
+// a draining supervisor record the hand-back without running it.
+func (m *Manager) handBack(ctx context.Context, d *db.EscalationDecision, source string, sessionID int64, fromTier, tier int, services []string, findings string) {
+	m.requested(d, source, tier, services, findings)
+	if len(services) == 0 {
+		msg := fmt.Sprintf("Hand-back blocked: tier %d handed back to tier %d without naming any services", fromTier, tier)
+		m.emitEscalationEvent(sessionID, msg)
+		m.recordDecision(d, DecisionInvalid, 0, msg)
+		return
+	}
+	if m.cfg.DryRun {
+		msg := fmt.Sprintf("Hand-back suppressed (dry run): would have handed back to tier %d to verify: %s",
+			tier, strings.Join(services, ", "))
+		m.emitEscalationEventLevel(sessionID, "info", msg)
+		m.recordDecision(d, DecisionDryRun, 0, msg)
+		return
+	}
+	if m.Draining() {
+		m.recordDecision(d, DecisionShutdown, 0, fmt.Sprintf("Hand-back to tier %d not run: the supervisor is shutting down", tier))
+		return
+	}
+
+	msg := fmt.Sprintf("Tier %d handed back to tier %d to verify %s", fromTier, tier, strings.Join(services, ", "))
+	fmt.Printf("[%s] %s\n", time.Now().UTC().Format(time.RFC3339), msg)
+	m.emitEscalationEventLevel(sessionID, "info", msg)
+	m.recordDecision(d, DecisionHandedBack, tier, msg)
+	_, model := m.verifyTier(tier)
+	m.publishChainEvent(m.chainRoot(), ChainEvent{Type: ChainHandedBack, SessionID: sessionID,
+		FromTier: fromTier, Tier: tier, Model: model, Services: services})
+	m.verify(ctx, verifyRequest{
+		remediationID: sessionID,
+		services:      services,
+		tier:          tier,
+		handBackTier:  fromTier,
+		findings:      findings,
+	})
+}
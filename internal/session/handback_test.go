@@ -0,0 +1,81 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestHandBack(t *testing.T) {
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Caddy is down with the known bad reload.", testkit.EscalateTo(3, "caddy config reload failure", "caddy")),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Restored the previous caddy config.", testkit.HandBack(1, "Restored the previous caddy config", "caddy")),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Caddy is serving again.", testkit.Healthy("caddy healthy", "caddy")),
+		}},
+	)
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.VerifyDelay = 0
+	m.cfg.VerifyModel = "haiku"
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.cfg.Tier3Prompt = "/dev/null"
+	m.cfg.VerifyPrompt = "/dev/null"
+	m.runner = runner
+
+	rootID := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	if calls := runner.Calls(); len(calls) != 3 || calls[1].Model != "opus" || calls[2].Model != "haiku" {
+		t.Fatalf("unexpected CLI calls %+v", calls)
+	}
+	children, err := database.GetChildSessions(rootID)
+	if err != nil || len(children) != 1 {
+		t.Fatalf("GetChildSessions(%d): %+v (%v)", rootID, children, err)
+	}
+	t3 := children[0]
+	d, err := database.GetEscalationDecision(t3.ID)
+	if err != nil || d == nil {
+		t.Fatalf("decision %+v (%v)", d, err)
+	}
+	if d.Outcome != DecisionHandedBack || d.Tier != 1 || d.Reason != "Tier 3 handed back to tier 1 to verify caddy" {
+		t.Errorf("hand-back decision = %+v", d)
+	}
+	if s, _ := database.GetSession(t3.ID); s == nil || s.Status != "completed" || s.Outcome == nil || *s.Outcome == db.SessionRemediationFailed {
+		t.Errorf("handing back session = %+v", s)
+	}
+	verified, err := database.GetChildSessions(t3.ID)
+	if err != nil || len(verified) != 1 {
+		t.Fatalf("GetChildSessions(%d): %+v (%v)", t3.ID, verified, err)
+	}
+	if v := verified[0]; v.Tier != 1 || v.Trigger != "verify" || v.Status != "completed" {
+		t.Errorf("verification session = %+v", v)
+	}
+}
+
+func TestSimulateHandBack(t *testing.T) {
+	m, _ := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.VerifyModel = "haiku"
+	sim, err := m.Simulate(SimulationRequest{FromTier: 3, RecommendedTier: 1, ServicesAffected: []string{"caddy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Outcome != SimulationVerify || sim.Tier != 1 || sim.Model != "haiku" {
+		t.Errorf("hand-back simulation = %+v", sim)
+	}
+
+	if sim, err = m.Simulate(SimulationRequest{FromTier: 3, RecommendedTier: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Outcome != SimulationBlocked || !hasStep(sim, "handoff", "block") {
+		t.Errorf("hand-back without services = %+v", sim)
+	}
+}
@@ -61,7 +61,7 @@ func ReadHandoff(stateDir string) (*Handoff, error) {
 // Returns nil if the file does not exist.
 func DeleteHandoff(stateDir string) error {
 	path := filepath.Join(stateDir, handoffFileName)
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+	if err := removeFile(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("delete handoff: %w", err)
 	}
 	return nil
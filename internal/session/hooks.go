@@ -0,0 +1,96 @@
+package session
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// Hooks receives the manager's lifecycle callbacks, so integrations such as
+// notifications, metrics, and incident grouping register with AddHooks
+// instead of being wired into runTier. Callbacks run synchronously on the
+// session's goroutine and must not block; a hook that panics is logged and
+// skipped. Embed NopHooks to implement only the callbacks you need.
+type Hooks interface {
+	// OnSessionStart is called once a session's record has been created.
+	OnSessionStart(s *db.Session)
+	// OnSessionEnd is called after a session's final state has been stored.
+	OnSessionEnd(s *db.Session)
+	// OnEscalation is called when a chain escalates to a higher tier.
+	OnEscalation(e Escalation)
+	// OnEvent is called for each event the manager records.
+	OnEvent(e *db.Event)
+	// OnMemory is called for each new memory a session learns.
+	OnMemory(mem *db.Memory)
+	// OnCooldown is called for each remediation action a session records.
+	OnCooldown(a *db.CooldownAction)
+	// OnVerification is called when a verification session has checked a
+	// Tier 3 remediation.
+	OnVerification(v Verification)
+}
+
+// Escalation describes a chain moving from one tier to the next.
+type Escalation struct {
+	SessionID int64 // the session that asked to escalate
+	FromTier  int
+	ToTier    int
+	Services  []string // services the next tier is scoped to
+	Context   string   // handoff context given to the next tier
+}
+
+// Verification is the outcome of a post-remediation verification session.
+type Verification struct {
+	SessionID     int64    // the verification session
+	RemediationID int64    // the Tier 3 session it verified
+	Services      []string // services that were checked
+	Unhealthy     []string // services still unhealthy; empty when the remediation held
+}
+
+// NopHooks implements Hooks with callbacks that do nothing.
+type NopHooks struct{}
+
+func (NopHooks) OnSessionStart(*db.Session)    {}
+func (NopHooks) OnSessionEnd(*db.Session)      {}
+func (NopHooks) OnEscalation(Escalation)       {}
+func (NopHooks) OnEvent(*db.Event)             {}
+func (NopHooks) OnMemory(*db.Memory)           {}
+func (NopHooks) OnCooldown(*db.CooldownAction) {}
+func (NopHooks) OnVerification(Verification)   {}
+
+// AddHooks registers h to receive the manager's lifecycle callbacks, after
+// any hooks registered before it.
+func (m *Manager) AddHooks(h Hooks) {
+	m.hooksMu.Lock()
+	defer m.hooksMu.Unlock()
+	m.hooks = append(m.hooks, h)
+}
+
+// runHooks calls call on every registered hook in order, recovering from a
+// hook that panics so one bad integration cannot take down a session.
+func (m *Manager) runHooks(name string, call func(Hooks)) {
+	m.hooksMu.RLock()
+	hooks := m.hooks
+	m.hooksMu.RUnlock()
+	for _, h := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Fprintf(os.Stderr, "hook %s (%T) panicked: %v\n", name, h, r)
+				}
+			}()
+			call(h)
+		}()
+	}
+}
+
+// insertEvent records evt and passes it to the OnEvent hooks.
+func (m *Manager) insertEvent(evt *db.Event) error {
+	id, err := m.db.InsertEvent(evt)
+	if err != nil {
+		return err
+	}
+	evt.ID = id
+	m.runHooks("OnEvent", func(h Hooks) { h.OnEvent(evt) })
+	return nil
+}
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// recordingHooks records the name of every callback it receives.
+type recordingHooks struct {
+	NopHooks
+	mu          sync.Mutex
+	calls       []string
+	escalations []Escalation
+	ended       []*db.Session
+}
+
+func (r *recordingHooks) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, name)
+}
+
+func (r *recordingHooks) OnSessionStart(*db.Session) { r.record("start") }
+func (r *recordingHooks) OnEvent(*db.Event)          { r.record("event") }
+func (r *recordingHooks) OnMemory(*db.Memory)        { r.record("memory") }
+func (r *recordingHooks) OnCooldown(*db.CooldownAction) {
+	r.record("cooldown")
+}
+
+func (r *recordingHooks) OnSessionEnd(s *db.Session) {
+	r.record("end")
+	r.mu.Lock()
+	r.ended = append(r.ended, s)
+	r.mu.Unlock()
+}
+
+func (r *recordingHooks) OnEscalation(e Escalation) {
+	r.record("escalation")
+	r.mu.Lock()
+	r.escalations = append(r.escalations, e)
+	r.mu.Unlock()
+}
+
+// panickingHooks panics in every callback it implements.
+type panickingHooks struct{ NopHooks }
+
+func (panickingHooks) OnSessionStart(*db.Session) { panic("boom") }
+func (panickingHooks) OnEvent(*db.Event)          { panic("boom") }
+
+func TestHooksReceiveLifecycle(t *testing.T) {
+	m, _ := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 2
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.runner = &pipeRunner{
+		events: []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"[COOLDOWN:restart:jellyfin] success — Container restarted"}]}}`,
+			`{"type":"result","result":"Jellyfin down.","is_error":false,"structured_output":{"summary":"jellyfin down","events":[{"level":"warning","service":"jellyfin","message":"Jellyfin is slow"}],"memories":[{"key":"jellyfin:timing","value":"Takes 60s to start after restart"}],"escalation":{"needed":true,"reason":"jellyfin down"},"services_checked":[{"name":"jellyfin","status":"down"}]}}`,
+		},
+		resultIdx: 2,
+	}
+	rec := &recordingHooks{}
+	m.AddHooks(panickingHooks{})
+	m.AddHooks(rec)
+
+	rootID := m.runChain(context.Background(), ChainStart{Tier: 1, Trigger: "scheduled"})
+	if rootID == 0 {
+		t.Fatal("runChain returned no session")
+	}
+
+	counts := make(map[string]int)
+	for _, c := range rec.calls {
+		counts[c]++
+	}
+	for _, name := range []string{"start", "end", "event", "memory", "cooldown"} {
+		if counts[name] == 0 {
+			t.Errorf("expected %s hook to fire, got calls %v", name, rec.calls)
+		}
+	}
+	if counts["start"] != 2 || counts["end"] != 2 {
+		t.Errorf("expected a start and end per tier, got calls %v", rec.calls)
+	}
+	if rec.calls[0] != "start" {
+		t.Errorf("expected calls to begin with start, got %v", rec.calls)
+	}
+
+	if len(rec.escalations) == 0 {
+		t.Fatal("expected an escalation")
+	}
+	e := rec.escalations[0]
+	if e.SessionID != rootID || e.FromTier != 1 || e.ToTier != 2 {
+		t.Errorf("unexpected escalation %+v (root %d)", e, rootID)
+	}
+	if rec.ended[0].EndedAt == nil || rec.ended[0].Status == "running" {
+		t.Errorf("expected end hook to see the final session, got %+v", rec.ended[0])
+	}
+}
@@ -0,0 +1,388 @@
+package session
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/seal"
+)
+
+// ImportedTrigger is the trigger of sessions reconstructed from log files.
+const ImportedTrigger = "imported"
+
+// runCompleteRe matches the line entrypoint.sh appended to each log when
+// the CLI exited: "[2025-01-02T03:04:05Z] Run complete. Log: ...".
+var runCompleteRe = regexp.MustCompile(`^\[(\S+)\] Run complete\.`)
+
+// ImportedLog is a session reconstructed from a log file, with the result
+// its log recorded.
+type ImportedLog struct {
+	Session db.Session
+	// Result is the final response, from the log's result event or, for
+	// the plain text output entrypoint.sh logged, the output itself.
+	Result     string
+	CostUSD    float64
+	NumTurns   int
+	DurationMs int64
+	// Redactions counts the credential values redacted from the log, by
+	// rule, as for a session the supervisor ran.
+	Redactions map[string]int
+}
+
+// ImportResult reports what ImportLogs did.
+type ImportResult struct {
+	Imported []ImportedLog
+	// Linked counts log files a session already links to.
+	Linked int
+	// Unreadable lists the log files that could not be parsed, with why.
+	Unreadable []string
+}
+
+// ImportLogs creates a session for each session log in dir (run-*.log or
+// run-*.log.gz) that no session links to, such as the logs entrypoint.sh
+// wrote before the Go supervisor kept a database, and links the file to it.
+// Logs are imported in name order, which is the order they were written.
+// Logs written before the supervisor redacted its output can hold
+// BROWSER_CRED_* values; these are redacted from the imported result and
+// from the log file itself. With dryRun, the sessions are reconstructed but
+// not inserted and no file is changed.
+func ImportLogs(database *db.DB, dir string, dryRun bool) (*ImportResult, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	known, err := database.ListSessionLogFiles()
+	if err != nil {
+		return nil, err
+	}
+	linked := map[string]bool{}
+	for _, p := range known {
+		linked[strings.TrimSuffix(filepath.Base(p), compressedLogSuffix)] = true
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.Type().IsRegular() && strings.HasPrefix(name, "run-") &&
+			(strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log"+compressedLogSuffix)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	redactor := NewRedactionFilter()
+	res := &ImportResult{}
+	for _, name := range names {
+		if linked[strings.TrimSuffix(name, compressedLogSuffix)] {
+			res.Linked++
+			continue
+		}
+		imp, err := parseImportedLog(filepath.Join(dir, name), database.Sealer(), redactor)
+		if err != nil {
+			res.Unreadable = append(res.Unreadable, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if !dryRun {
+			if len(imp.Redactions) > 0 {
+				if err := redactLogFile(*imp.Session.LogFile, database.Sealer(), redactor); err != nil {
+					return res, fmt.Errorf("redact %s: %w", name, err)
+				}
+			}
+			if err := insertImportedLog(database, imp); err != nil {
+				return res, fmt.Errorf("import %s: %w", name, err)
+			}
+		}
+		res.Imported = append(res.Imported, *imp)
+	}
+	return res, nil
+}
+
+func insertImportedLog(database *db.DB, imp *ImportedLog) error {
+	id, err := database.InsertSession(&imp.Session)
+	if err != nil {
+		return err
+	}
+	imp.Session.ID = id
+	for rule, n := range imp.Redactions {
+		if _, err := database.InsertSessionRedaction(&db.SessionRedaction{
+			SessionID: id, Rule: rule, Count: n, CreatedAt: *imp.Session.EndedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	if imp.Result == "" && imp.CostUSD == 0 && imp.NumTurns == 0 && imp.DurationMs == 0 {
+		return nil
+	}
+	return database.UpdateSessionResult(id, imp.Result, imp.CostUSD, imp.NumTurns, imp.DurationMs)
+}
+
+// redactLogFile rewrites a log with the credential values redactor knows
+// redacted, keeping it compressed and its lines sealed as they were. The
+// log's line index is removed, as the offsets change; reading a line then
+// scans the log.
+func redactLogFile(logPath string, sealer *seal.Sealer, redactor *RedactionFilter) error {
+	info, err := os.Stat(logPath)
+	if err != nil {
+		return err
+	}
+	in, err := OpenLog(logPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+
+	tmp := logPath + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	var w io.Writer = out
+	var zw *gzip.Writer
+	if strings.HasSuffix(logPath, compressedLogSuffix) {
+		zw = gzip.NewWriter(out)
+		w = zw
+	}
+	bw := bufio.NewWriter(w)
+	scanner := NewLogScanner(in)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if seal.IsSealed(line) {
+			// A line that cannot be opened is kept as it is.
+			if plain, err := sealer.Open(line); err == nil {
+				if redacted := redactor.Redact(plain); redacted != plain {
+					line = sealer.Seal(redacted)
+				}
+			}
+		} else {
+			line = redactor.Redact(line)
+		}
+		if _, err = bw.WriteString(line + "\n"); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	if zw != nil {
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, logPath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	_ = os.Remove(LogIndexPath(logPath))
+	return nil
+}
+
+// parseImportedLog reconstructs a session from a log. It reads the run
+// metadata block and plain text output entrypoint.sh wrote, and the
+// timestamped stream-json lines the supervisor writes, so a log of either
+// kind can be imported:
+//
+//   - started: the metadata timestamp, else the first timestamped line,
+//     else the time in the file name, else the file's modification time
+//   - ended: the "Run complete" line, else the last timestamped line,
+//     else the start plus the result's duration, else the modification time
+//   - tier: the metadata tier, else guessed from the model (opus is Tier 3,
+//     sonnet Tier 2, anything else Tier 1)
+//   - status: failed if the result is an error, completed if the run
+//     finished, else interrupted
+//
+// sealer opens the lines of an encrypted log, and redactor redacts the
+// credential values in them.
+func parseImportedLog(logPath string, sealer *seal.Sealer, redactor *RedactionFilter) (*ImportedLog, error) {
+	info, err := os.Stat(logPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := OpenLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	var (
+		meta             = map[string]string{}
+		inMeta, metaDone bool
+		output           []string
+		started, ended   time.Time
+		firstTS, lastTS  time.Time
+		result           *streamEvent
+		model            string
+		finished         bool
+		redactions       = map[string]int{}
+	)
+	scanner := NewLogScanner(f)
+	for scanner.Scan() {
+		line := redactor.RedactCount(sealer.OpenOr(scanner.Text()), redactions)
+		if !metaDone {
+			switch {
+			case !inMeta && line == "--- Run metadata ---":
+				inMeta = true
+				continue
+			case inMeta && line == "---":
+				inMeta, metaDone = false, true
+				continue
+			case inMeta:
+				if k, v, ok := strings.Cut(line, ":"); ok {
+					meta[strings.TrimSpace(k)] = strings.TrimSpace(v)
+				}
+				continue
+			case strings.TrimSpace(line) != "":
+				metaDone = true
+			}
+		}
+		if m := runCompleteRe.FindStringSubmatch(line); m != nil {
+			if t, err := time.Parse(time.RFC3339, m[1]); err == nil {
+				ended = t
+			}
+			finished = true
+			continue
+		}
+
+		ts, raw, hasTS := ParseTimestampedLogLine(line)
+		if hasTS {
+			if firstTS.IsZero() {
+				firstTS = ts
+			}
+			lastTS = ts
+		}
+		var evt streamEvent
+		if strings.HasPrefix(raw, "{") && json.Unmarshal([]byte(raw), &evt) == nil && evt.Type != "" {
+			switch {
+			case evt.Type == "system" && evt.Subtype == "init" && evt.Model != "":
+				model = evt.Model
+			case evt.Type == "result":
+				result = &evt
+				finished = true
+			}
+			continue
+		}
+		output = append(output, raw)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(meta) == 0 && result == nil && firstTS.IsZero() && len(output) == 0 {
+		return nil, fmt.Errorf("empty log")
+	}
+
+	if t, err := time.Parse(time.RFC3339, meta["timestamp"]); err == nil {
+		started = t
+	}
+	if started.IsZero() {
+		started = firstTS
+	}
+	if started.IsZero() {
+		started = logNameTime(filepath.Base(logPath))
+	}
+	if started.IsZero() {
+		started = info.ModTime()
+	}
+	if ended.IsZero() {
+		ended = lastTS
+	}
+	if ended.IsZero() && result != nil && result.DurationMs > 0 {
+		ended = started.Add(time.Duration(result.DurationMs) * time.Millisecond)
+	}
+	if ended.IsZero() || ended.Before(started) {
+		ended = info.ModTime()
+	}
+
+	if m := meta["model"]; m != "" {
+		model = m
+	}
+	if model == "" {
+		model = "unknown"
+	}
+	tier, err := strconv.Atoi(meta["tier"])
+	if err != nil || tier < 1 || tier > 3 {
+		tier = guessTier(model)
+	}
+
+	status := "interrupted"
+	if finished {
+		status = "completed"
+	}
+	imp := &ImportedLog{Result: strings.TrimSpace(strings.Join(output, "\n"))}
+	if len(redactions) > 0 {
+		imp.Redactions = redactions
+	}
+	if result != nil {
+		if result.IsError {
+			status = "failed"
+		}
+		if strings.TrimSpace(result.Result) != "" {
+			imp.Result = result.Result
+		}
+		imp.CostUSD, imp.NumTurns, imp.DurationMs = result.TotalCostUSD, result.NumTurns, result.DurationMs
+	}
+	if imp.DurationMs == 0 && finished {
+		imp.DurationMs = ended.Sub(started).Milliseconds()
+	}
+
+	startedAt := started.UTC().Format(time.RFC3339)
+	endedAt := ended.UTC().Format(time.RFC3339)
+	imp.Session = db.Session{
+		Tier:      tier,
+		Model:     model,
+		Status:    status,
+		StartedAt: startedAt,
+		EndedAt:   &endedAt,
+		LogFile:   &logPath,
+		Trigger:   ImportedTrigger,
+	}
+	return imp, nil
+}
+
+// logNameTime parses the time in a log file name, run-20060102-150405.log,
+// which entrypoint.sh and the supervisor write in local time.
+func logNameTime(name string) time.Time {
+	name = strings.TrimPrefix(name, "run-")
+	if len(name) < len("20060102-150405") {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation("20060102-150405", name[:len("20060102-150405")], time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// guessTier guesses the tier a session ran at from its model, by the
+// default model of each tier.
+func guessTier(model string) int {
+	switch {
+	case strings.Contains(model, "opus"):
+		return 3
+	case strings.Contains(model, "sonnet"):
+		return 2
+	}
+	return 1
+}
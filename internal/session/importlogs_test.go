@@ -0,0 +1,149 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestImportLogs(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	// Written by entrypoint.sh: run metadata, the CLI's text output, and
+	// the completion line.
+	write("run-20250102-030405.log", `--- Run metadata ---
+timestamp: 2025-01-02T03:04:05Z
+tier: 1
+model: haiku
+dry_run: false
+---
+All 12 services are healthy.
+[2025-01-02T03:06:05Z] Run complete. Log: /results/run-20250102-030405.log
+`)
+	// Written by the supervisor, whose database row was lost.
+	write("run-20250301-120000.log", "2025-03-01T12:00:00Z\t"+`{"type":"system","subtype":"init","model":"claude-sonnet-4"}`+"\n"+
+		"2025-03-01T12:01:30Z\t"+`{"type":"result","result":"Restarted jellyfin.","total_cost_usd":0.42,"num_turns":7,"duration_ms":90000,"is_error":false}`+"\n")
+	// Cut off before the CLI exited.
+	write("run-20250401-000000.log", "--- Run metadata ---\ntimestamp: 2025-04-01T00:00:00Z\ntier: 3\nmodel: opus\n---\nRestarting postgres\n")
+	write("run-20250402-000000.log", "")
+	write("notes.txt", "not a log")
+	linkedPath := write("run-20250501-000000.log", "2025-05-01T00:00:00Z\t{\"type\":\"result\"}\n")
+	if _, err := database.InsertSession(&db.Session{Tier: 1, Model: "haiku", Status: "completed", StartedAt: "2025-05-01T00:00:00Z", LogFile: &linkedPath}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := ImportLogs(database, dir, true)
+	if err != nil {
+		t.Fatalf("ImportLogs (dry run): %v", err)
+	}
+	if len(res.Imported) != 3 {
+		t.Fatalf("dry run imported %d logs, want 3", len(res.Imported))
+	}
+	if sessions, _ := database.ListSessions(-1, 0); len(sessions) != 1 {
+		t.Fatalf("dry run inserted sessions: %d", len(sessions))
+	}
+
+	res, err = ImportLogs(database, dir, false)
+	if err != nil {
+		t.Fatalf("ImportLogs: %v", err)
+	}
+	if res.Linked != 1 || len(res.Unreadable) != 1 || !strings.Contains(res.Unreadable[0], "run-20250402-000000.log") {
+		t.Errorf("Linked = %d, Unreadable = %v", res.Linked, res.Unreadable)
+	}
+	if len(res.Imported) != 3 {
+		t.Fatalf("imported %d logs, want 3", len(res.Imported))
+	}
+
+	legacy, _ := database.GetSession(res.Imported[0].Session.ID)
+	if legacy.Tier != 1 || legacy.Model != "haiku" || legacy.Status != "completed" || legacy.Trigger != ImportedTrigger ||
+		legacy.StartedAt != "2025-01-02T03:04:05Z" || *legacy.EndedAt != "2025-01-02T03:06:05Z" ||
+		*legacy.Response != "All 12 services are healthy." || *legacy.DurationMs != 120000 {
+		t.Errorf("legacy session = %+v", legacy)
+	}
+	if !filepath.IsAbs(*legacy.LogFile) || filepath.Base(*legacy.LogFile) != "run-20250102-030405.log" {
+		t.Errorf("legacy LogFile = %q", *legacy.LogFile)
+	}
+
+	supervised, _ := database.GetSession(res.Imported[1].Session.ID)
+	if supervised.Tier != 2 || supervised.Model != "claude-sonnet-4" || *supervised.CostUSD != 0.42 || *supervised.NumTurns != 7 ||
+		*supervised.Response != "Restarted jellyfin." || *supervised.EndedAt != "2025-03-01T12:01:30Z" {
+		t.Errorf("supervised session = %+v", supervised)
+	}
+
+	cut, _ := database.GetSession(res.Imported[2].Session.ID)
+	if cut.Tier != 3 || cut.Status != "interrupted" || *cut.Response != "Restarting postgres" {
+		t.Errorf("interrupted session = %+v", cut)
+	}
+
+	// Importing again links nothing new.
+	res, err = ImportLogs(database, dir, false)
+	if err != nil || len(res.Imported) != 0 || res.Linked != 4 {
+		t.Errorf("second import: %+v, %v", res, err)
+	}
+}
+
+func TestImportLogsRedactsCredentials(t *testing.T) {
+	t.Setenv("BROWSER_CRED_SONARR_PASS", "s3cretP@ss")
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	logPath := filepath.Join(t.TempDir(), "run-20250102-030405.log")
+	if err := os.WriteFile(logPath, []byte("--- Run metadata ---\ntier: 1\n---\nLogged in to sonarr with s3cretP@ss.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := ImportLogs(database, filepath.Dir(logPath), true)
+	if err != nil || len(res.Imported) != 1 {
+		t.Fatalf("ImportLogs (dry run): %+v, %v", res, err)
+	}
+	if data, _ := os.ReadFile(logPath); !strings.Contains(string(data), "s3cretP@ss") {
+		t.Error("dry run changed the log")
+	}
+
+	res, err = ImportLogs(database, filepath.Dir(logPath), false)
+	if err != nil || len(res.Imported) != 1 {
+		t.Fatalf("ImportLogs: %+v, %v", res, err)
+	}
+	id := res.Imported[0].Session.ID
+	s, _ := database.GetSession(id)
+	if want := "Logged in to sonarr with [REDACTED:BROWSER_CRED_SONARR_PASS]."; s.Response == nil || *s.Response != want {
+		t.Errorf("Response = %v, want %q", s.Response, want)
+	}
+	data, _ := os.ReadFile(logPath)
+	if strings.Contains(string(data), "s3cretP@ss") || !strings.Contains(string(data), "[REDACTED:BROWSER_CRED_SONARR_PASS]") {
+		t.Errorf("log not redacted:\n%s", data)
+	}
+	redactions, err := database.ListSessionRedactions(id)
+	if err != nil || len(redactions) != 1 || redactions[0].Count != 1 {
+		t.Errorf("redactions = %+v, %v", redactions, err)
+	}
+}
+
+func TestLogNameTime(t *testing.T) {
+	want := time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)
+	if got := logNameTime("run-20250102-030405.log.gz"); !got.Equal(want) {
+		t.Errorf("logNameTime = %v, want %v", got, want)
+	}
+	if got := logNameTime("run-x.log"); !got.IsZero() {
+		t.Errorf("logNameTime of a bad name = %v", got)
+	}
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Invocation records how a session's CLI process was started, so "why did
+// this session behave differently" can be answered after the fact. It is
+// stored as JSON on the session row.
+type Invocation struct {
+	// Args are the CLI arguments. The prompt and appended system prompt are
+	// elided; the system prompt is recorded separately, redacted.
+	Args            []string `json:"args"`
+	RequestedModel  string   `json:"requested_model"`
+	ResolvedModel   string   `json:"resolved_model,omitempty"` // from the CLI's init event
+	CLIVersion      string   `json:"cli_version,omitempty"`    // from `claude --version`, or the CLI's init event
+	AllowedTools    string   `json:"allowed_tools"`
+	DisallowedTools string   `json:"disallowed_tools,omitempty"`
+	SystemPrompt    string   `json:"append_system_prompt"`
+	// Env holds the relevant environment variables. Secret values are
+	// replaced with [REDACTED].
+	Env map[string]string `json:"env"`
+}
+
+// invocationEnvPrefixes select the environment variables worth recording.
+var invocationEnvPrefixes = []string{"CLAUDEOPS_", "ANTHROPIC_", "CLAUDE_", "BROWSER_"}
+
+// secretEnvMarkers mark environment variables whose values must not be
+// recorded. Apprise URLs, extension targets, and heartbeat URLs embed
+// service tokens; per-tier environments may hold secret values.
+var secretEnvMarkers = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "CRED", "APPRISE_URLS", "EXTENSIONS", "HEARTBEAT_URL",
+	"TIER1_ENV", "TIER2_ENV", "TIER3_ENV"}
+
+func isSecretEnv(name string) bool {
+	for _, m := range secretEnvMarkers {
+		if strings.Contains(name, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildInvocation records the arguments runner.Start is about to receive
+// for a session of the given tier.
+func (m *Manager) buildInvocation(tier int, model, promptContent, allowedTools, disallowedTools, appendSystemPrompt string) *Invocation {
+	args := cliArgs(model, promptContent, allowedTools, disallowedTools, appendSystemPrompt, m.cfg.SchemaPath)
+	for i := 1; i < len(args); i++ {
+		switch args[i-1] {
+		case "-p":
+			args[i] = fmt.Sprintf("<prompt: %d bytes>", len(promptContent))
+		case "--append-system-prompt":
+			args[i] = "<append_system_prompt>"
+		}
+	}
+
+	inv := &Invocation{
+		Args:            args,
+		RequestedModel:  model,
+		CLIVersion:      m.cliVersion,
+		AllowedTools:    allowedTools,
+		DisallowedTools: disallowedTools,
+		Env:             make(map[string]string),
+	}
+	// Replace secret values wherever they appear in the system prompt (e.g.
+	// CLAUDEOPS_APPRISE_URLS from the config), longest first so a secret
+	// containing another is replaced whole.
+	secrets := make(map[string]string)
+	if m.cfg.AppriseURLs != "" {
+		secrets[m.cfg.AppriseURLs] = "[REDACTED:CLAUDEOPS_APPRISE_URLS]"
+	}
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !hasAnyPrefix(name, invocationEnvPrefixes) {
+			continue
+		}
+		if isSecretEnv(name) {
+			inv.Env[name] = "[REDACTED]"
+			if len(value) >= 4 {
+				secrets[value] = "[REDACTED:" + name + "]"
+			}
+			continue
+		}
+		inv.Env[name] = value
+	}
+	// The tier's own variables override the process environment.
+	for _, v := range m.tierEnv[tier] {
+		inv.Env[v.Name] = v.Value
+		if v.Secret {
+			inv.Env[v.Name] = "[REDACTED]"
+		}
+	}
+	values := make([]string, 0, len(secrets))
+	for v := range secrets {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	prompt := m.redactor.Redact(appendSystemPrompt)
+	for _, v := range values {
+		prompt = strings.ReplaceAll(prompt, v, secrets[v])
+	}
+	inv.SystemPrompt = prompt
+	return inv
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveInvocation stores inv on the session row. Failures are logged: the
+// record is for debugging and must not fail the session.
+func (m *Manager) saveInvocation(sessionID int64, inv *Invocation) {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: marshal invocation: %v\n", sessionID, err)
+		return
+	}
+	if err := m.db.UpdateSessionInvocation(sessionID, string(data)); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+	}
+}
+
+// ParseInvocation decodes a session's stored invocation. It returns nil for
+// sessions recorded before invocations were captured.
+func ParseInvocation(raw *string) *Invocation {
+	if raw == nil || *raw == "" {
+		return nil
+	}
+	var inv Invocation
+	if err := json.Unmarshal([]byte(*raw), &inv); err != nil {
+		return nil
+	}
+	return &inv
+}
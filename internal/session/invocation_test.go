@@ -0,0 +1,68 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildInvocation(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-secret-value")
+	t.Setenv("CLAUDEOPS_TIER1_MODEL", "haiku")
+	t.Setenv("UNRELATED_SETTING", "x")
+	m, _ := testManagerWithDB(t)
+	m.cfg.AppriseURLs = "ntfy://token@ntfy.example.com/ops"
+
+	inv := m.buildInvocation(1, "haiku", "check everything", "Bash,Read", "Bash(rm:*)",
+		"CLAUDEOPS_APPRISE_URLS=ntfy://token@ntfy.example.com/ops key sk-ant-secret-value")
+
+	if i := slices.Index(inv.Args, "-p"); i < 0 || inv.Args[i+1] != "<prompt: 16 bytes>" {
+		t.Errorf("expected elided prompt in args, got %v", inv.Args)
+	}
+	if !slices.Contains(inv.Args, "--disallowedTools") || !slices.Contains(inv.Args, "Bash(rm:*)") {
+		t.Errorf("expected disallowed tools in args, got %v", inv.Args)
+	}
+	if strings.Contains(inv.SystemPrompt, "token@") || strings.Contains(inv.SystemPrompt, "sk-ant-secret-value") {
+		t.Errorf("expected secrets redacted from system prompt, got %q", inv.SystemPrompt)
+	}
+	if !strings.Contains(inv.SystemPrompt, "[REDACTED:CLAUDEOPS_APPRISE_URLS]") || !strings.Contains(inv.SystemPrompt, "[REDACTED:ANTHROPIC_API_KEY]") {
+		t.Errorf("expected redaction placeholders, got %q", inv.SystemPrompt)
+	}
+	if inv.Env["ANTHROPIC_API_KEY"] != "[REDACTED]" || inv.Env["CLAUDEOPS_TIER1_MODEL"] != "haiku" {
+		t.Errorf("unexpected env %v", inv.Env)
+	}
+	if _, ok := inv.Env["UNRELATED_SETTING"]; ok {
+		t.Errorf("expected unrelated env vars to be skipped, got %v", inv.Env)
+	}
+}
+
+func TestRunTierRecordsInvocation(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.runner = &pipeRunner{
+		events: []string{
+			`{"type":"system","subtype":"init","model":"claude-haiku-4-5-20251001","claude_code_version":"2.0.1"}`,
+			`{"type":"result","result":"All healthy.","is_error":false}`,
+		},
+		resultIdx: 1,
+	}
+
+	id, _, err := m.runTier(context.Background(), 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	sess, err := database.GetSession(id)
+	if err != nil || sess == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	inv := ParseInvocation(sess.Invocation)
+	if inv == nil {
+		t.Fatal("expected invocation to be recorded")
+	}
+	if inv.RequestedModel != "haiku" || inv.ResolvedModel != "claude-haiku-4-5-20251001" || inv.CLIVersion != "2.0.1" {
+		t.Errorf("unexpected invocation %+v", inv)
+	}
+	if inv.AllowedTools == "" || len(inv.Args) == 0 {
+		t.Errorf("expected tools and args, got %+v", inv)
+	}
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"time"
+)
+
+// listPricing prices live cost estimates for models the synthetic pricing
+// table does not cover, at Anthropic's list rates in USD per million tokens.
+var listPricing = ParsePricing("haiku=1/5;sonnet=3/15;opus=5/25")
+
+// LiveSession is the progress of the running session, for the dashboard's
+// live incident banner.
+type LiveSession struct {
+	SessionID int64
+	Tier      int
+	Trigger   string
+	Services  []string
+	StartedAt time.Time
+	// CostUSD is the cost so far: estimated from the token usage of each
+	// assistant message until the CLI reports the session's cost.
+	CostUSD float64
+	// Critical is set once the session reports a critical event.
+	Critical bool
+}
+
+// Incident reports whether the session is an incident worth a banner:
+// escalated from a lower tier, or reporting a critical event.
+func (l *LiveSession) Incident() bool {
+	return l.Trigger == "escalation" || l.Critical
+}
+
+// liveCost accumulates a session's cost from the usage on its assistant
+// messages. The CLI emits one assistant event per content block, each
+// repeating its message's usage, so usage is counted once per message.
+type liveCost struct {
+	model    string
+	prices   []ModelPrice
+	messages map[string]float64
+	total    float64
+}
+
+func newLiveCost(model string, prices []ModelPrice) *liveCost {
+	return &liveCost{model: model, prices: prices, messages: make(map[string]float64)}
+}
+
+// observe records the usage of one assistant message and returns the
+// running total.
+func (c *liveCost) observe(messageID string, u *tokenUsage) float64 {
+	cost, ok := estimateCost(c.prices, c.model, u)
+	if !ok {
+		if cost, ok = estimateCost(listPricing, c.model, u); !ok {
+			return c.total
+		}
+	}
+	c.total += cost - c.messages[messageID]
+	if messageID != "" {
+		c.messages[messageID] = cost
+	}
+	return c.total
+}
+
+// setLive records the running session's progress; nil clears it.
+func (m *Manager) setLive(l *LiveSession) {
+	m.mu.Lock()
+	m.live = l
+	m.mu.Unlock()
+}
+
+// updateLive applies fn to the running session's progress.
+func (m *Manager) updateLive(fn func(*LiveSession)) {
+	m.mu.Lock()
+	if m.live != nil {
+		fn(m.live)
+	}
+	m.mu.Unlock()
+}
+
+// Live returns a copy of the running session's progress, or nil when no
+// session is running.
+func (m *Manager) Live() *LiveSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.live == nil {
+		return nil
+	}
+	l := *m.live
+	l.Services = append([]string(nil), m.live.Services...)
+	return &l
+}
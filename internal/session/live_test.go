@@ -0,0 +1,51 @@
+package session
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLiveCostCountsEachMessageOnce(t *testing.T) {
+	c := newLiveCost("claude-sonnet-4-5", ParsePricing("sonnet=3/15"))
+	usage := &tokenUsage{InputTokens: 100_000, OutputTokens: 10_000} // $0.45
+	c.observe("msg_1", usage)
+	if got := c.observe("msg_1", usage); math.Abs(got-0.45) > 1e-9 {
+		t.Errorf("repeated message counted twice: $%.4f", got)
+	}
+	if got := c.observe("msg_2", usage); math.Abs(got-0.90) > 1e-9 {
+		t.Errorf("second message: $%.4f, want $0.90", got)
+	}
+
+	// Models outside the pricing table fall back to list prices.
+	opus := newLiveCost("claude-opus-4-5", nil)
+	if got := opus.observe("msg_1", &tokenUsage{OutputTokens: 40_000}); math.Abs(got-1.00) > 1e-9 {
+		t.Errorf("opus at list price: $%.4f, want $1.00", got)
+	}
+	if got := newLiveCost("mystery", nil).observe("msg_1", usage); got != 0 {
+		t.Errorf("unpriced model: $%.4f, want $0", got)
+	}
+}
+
+func TestLiveReturnsCopy(t *testing.T) {
+	m, _ := testManager(t)
+	if m.Live() != nil {
+		t.Fatal("Live() before any session")
+	}
+	m.setLive(&LiveSession{SessionID: 1, Tier: 2, Trigger: "escalation", Services: []string{"postgres"}})
+	m.updateLive(func(l *LiveSession) { l.CostUSD = 1.2 })
+	l := m.Live()
+	if l == nil || l.CostUSD != 1.2 || !l.Incident() {
+		t.Fatalf("Live() = %+v", l)
+	}
+	l.Services[0] = "redis"
+	if m.Live().Services[0] != "postgres" {
+		t.Error("Live() shares its services slice")
+	}
+	m.setLive(nil)
+	if m.Live() != nil {
+		t.Error("Live() after the session ended")
+	}
+	if (&LiveSession{Trigger: "scheduled"}).Incident() {
+		t.Error("scheduled session without a critical event is an incident")
+	}
+}
@@ -0,0 +1,72 @@
+package session
+
+import "fmt"
+
+// localAPI returns how the agent reaches the dashboard from this host: the
+// base URL and the curl options the requests need. The agent listener on
+// CLAUDEOPS_AGENT_PORT is plain HTTP on the loopback address, whatever the
+// dashboard itself listens on. Without it, an HTTPS dashboard's certificate
+// names its public host, not 127.0.0.1, so curl skips verification on the
+// loopback address, and a dashboard on a Unix socket is reached through it,
+// with localhost as the nominal host.
+func (m *Manager) localAPI() (base, curlOpts string) {
+	if m.cfg.AgentPort > 0 {
+		return fmt.Sprintf("http://127.0.0.1:%d", m.cfg.AgentPort), ""
+	}
+	scheme, host := "http", fmt.Sprintf("127.0.0.1:%d", m.cfg.DashboardPort)
+	if m.tls() {
+		scheme, curlOpts = "https", "-k"
+	}
+	if m.cfg.DashboardSocket != "" {
+		host = "localhost"
+		if curlOpts != "" {
+			curlOpts += " "
+		}
+		curlOpts += "--unix-socket " + m.cfg.DashboardSocket
+	}
+	return scheme + "://" + host, curlOpts
+}
+
+// localNotifyURL is the Apprise URL that delivers a notification to the
+// dashboard endpoint at path, or "" when only a Unix socket serves it,
+// which Apprise cannot reach.
+func (m *Manager) localNotifyURL(path string) string {
+	switch {
+	case m.cfg.AgentPort > 0:
+		return fmt.Sprintf("json://127.0.0.1:%d%s", m.cfg.AgentPort, path)
+	case m.cfg.DashboardSocket != "":
+		return ""
+	case m.tls():
+		return fmt.Sprintf("jsons://127.0.0.1:%d%s?verify=no", m.cfg.DashboardPort, path)
+	}
+	return fmt.Sprintf("json://127.0.0.1:%d%s", m.cfg.DashboardPort, path)
+}
+
+// agentAppriseURLs is the CLAUDEOPS_APPRISE_URLS the agent sees. With a
+// notification digest configured, it is the supervisor's notify endpoint,
+// so the agent's notifications are coalesced with the rest; the digest
+// delivers them to the configured URLs.
+func (m *Manager) agentAppriseURLs() string {
+	if m.cfg.NotifyDigestWindow > 0 && m.cfg.AppriseURLs != "" {
+		if u := m.localNotifyURL("/api/v1/notify"); u != "" {
+			return u
+		}
+	}
+	return m.cfg.AppriseURLs
+}
+
+// agentEnv is the extra environment of a tier's CLI process: the agent's
+// CLAUDEOPS_APPRISE_URLS when it differs from the supervisor's, then the
+// tier's own CLAUDEOPS_TIER<n>_ENV variables.
+func (m *Manager) agentEnv(tier int) []string {
+	env := m.tierEnvList(tier)
+	if u := m.agentAppriseURLs(); u != m.cfg.AppriseURLs {
+		env = append([]string{"CLAUDEOPS_APPRISE_URLS=" + u}, env...)
+	}
+	return env
+}
+
+// tls reports whether the dashboard serves HTTPS.
+func (m *Manager) tls() bool {
+	return m.cfg.TLSCert != "" || m.cfg.ACMEDomains != ""
+}
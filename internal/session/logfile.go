@@ -0,0 +1,105 @@
+package session
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// compressedLogSuffix marks a session log gzipped after its session ended.
+const compressedLogSuffix = ".gz"
+
+// OpenLog opens a session log for reading, decompressing it when it was
+// gzipped after its session ended. Logs kept in plaintext, from before
+// compression or with it turned off, are read as is.
+func OpenLog(logPath string) (io.ReadCloser, error) {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogReader(f, logPath)
+}
+
+// NewLogReader reads the session log logPath from r, which is the file or a
+// copy of it fetched from elsewhere, decompressing it when it was gzipped.
+// Closing the reader closes r.
+func NewLogReader(r io.ReadCloser, logPath string) (io.ReadCloser, error) {
+	if !strings.HasSuffix(logPath, compressedLogSuffix) {
+		return r, nil
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		r.Close() //nolint:errcheck
+		return nil, fmt.Errorf("open compressed log: %w", err)
+	}
+	return &gzipLog{Reader: zr, r: r}, nil
+}
+
+type gzipLog struct {
+	*gzip.Reader
+	r io.Closer
+}
+
+func (g *gzipLog) Close() error {
+	err := g.Reader.Close()
+	if cerr := g.r.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// compressLog gzips a finished session log to logPath.gz and returns the
+// new path. The log's index keeps its name and its offsets into the
+// uncompressed log.
+func compressLog(logPath string) (string, error) {
+	in, err := os.Open(logPath)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close() //nolint:errcheck
+
+	gzPath := logPath + compressedLogSuffix
+	tmp := gzPath + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return "", err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, gzPath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("compress %s: %w", logPath, err)
+	}
+	return gzPath, nil
+}
+
+// compressSessionLog gzips a session's log once the session has ended and
+// points the session record at the compressed file. A log that cannot be
+// compressed is left as it is.
+func (m *Manager) compressSessionLog(sessionID int64, logPath string) {
+	if m.cfg.NoLogCompression {
+		return
+	}
+	gzPath, err := compressLog(logPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+		return
+	}
+	if err := m.db.UpdateSessionLogFile(sessionID, gzPath); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+		_ = os.Remove(gzPath)
+		return
+	}
+	_ = os.Remove(logPath)
+}
@@ -0,0 +1,150 @@
+package session
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// A session log's index is a sidecar file next to the NDJSON log holding one
+// big-endian int64 byte offset per log line, so a single line (e.g. a tool
+// result truncated in the activity log) can be read back without scanning
+// the whole log.
+
+// maxLogLine is the longest session log line read back: a stream-json line
+// of up to 1 MiB, which grows by a third when the log is encrypted.
+const maxLogLine = 2 * 1024 * 1024
+
+// NewLogScanner returns a scanner over the lines of a session log.
+func NewLogScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024*1024), maxLogLine)
+	return scanner
+}
+
+// LogIndexPath returns the index file path for a session log. A compressed
+// log shares the index written for it before it was compressed.
+func LogIndexPath(logPath string) string {
+	return strings.TrimSuffix(logPath, compressedLogSuffix) + ".idx"
+}
+
+// logIndexWriter appends line offsets as the log is written.
+type logIndexWriter struct {
+	f      *os.File
+	offset int64
+}
+
+func createLogIndex(logPath string) (*logIndexWriter, error) {
+	f, err := os.Create(LogIndexPath(logPath))
+	if err != nil {
+		return nil, fmt.Errorf("create log index: %w", err)
+	}
+	return &logIndexWriter{f: f}, nil
+}
+
+// add records that a line of n bytes (including its newline) was written.
+func (w *logIndexWriter) add(n int) {
+	if w == nil {
+		return
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(w.offset))
+	_, _ = w.f.Write(buf[:])
+	w.offset += int64(n)
+}
+
+func (w *logIndexWriter) Close() error {
+	if w == nil {
+		return nil
+	}
+	return w.f.Close()
+}
+
+// ErrLogLineNotFound is returned when a log line number is out of range.
+var ErrLogLineNotFound = errors.New("log line not found")
+
+// ReadLogLine returns the 1-based line of a session log, without its
+// trailing newline. It seeks via the log's index when one exists and falls
+// back to scanning for logs written before indexes were kept.
+func ReadLogLine(logPath string, line int) (string, error) {
+	if line < 1 {
+		return "", ErrLogLineNotFound
+	}
+	f, err := OpenLog(logPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck
+	return ReadLogLineFrom(f, logPath, line)
+}
+
+// ReadLogLineFrom is ReadLogLine for the log logPath already opened as f,
+// e.g. fetched from object storage. The index is still read from next to
+// logPath.
+func ReadLogLineFrom(f io.Reader, logPath string, line int) (string, error) {
+	if line < 1 {
+		return "", ErrLogLineNotFound
+	}
+	if offset, err := indexedOffset(logPath, line); err == nil {
+		// A compressed or fetched log cannot seek, so it is read up to the
+		// offset.
+		if err := skipTo(f, offset); err != nil {
+			return "", err
+		}
+		s, err := bufio.NewReaderSize(f, 64*1024).ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && s != "") {
+			return "", ErrLogLineNotFound
+		}
+		return strings.TrimSuffix(s, "\n"), nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	scanner := NewLogScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if n == line {
+			return scanner.Text(), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", ErrLogLineNotFound
+}
+
+// skipTo moves r to offset, seeking when r is a plain file.
+func skipTo(r io.Reader, offset int64) error {
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, r, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrLogLineNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// indexedOffset looks up a line's byte offset in the log's index.
+func indexedOffset(logPath string, line int) (int64, error) {
+	idx, err := os.Open(LogIndexPath(logPath))
+	if err != nil {
+		return 0, err
+	}
+	defer idx.Close() //nolint:errcheck
+
+	var buf [8]byte
+	if _, err := idx.ReadAt(buf[:], int64(line-1)*8); err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, ErrLogLineNotFound
+		}
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(buf[:])), nil
+}
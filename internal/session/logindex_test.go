@@ -0,0 +1,125 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeIndexedLog(t *testing.T, lines []string) string {
+	t.Helper()
+	logPath := filepath.Join(t.TempDir(), "run.log")
+	f, err := os.Create(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := createLogIndex(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range lines {
+		n, _ := fmt.Fprintf(f, "%s\n", l)
+		idx.add(n)
+	}
+	_ = f.Close()
+	_ = idx.Close()
+	return logPath
+}
+
+func TestReadLogLine(t *testing.T) {
+	lines := []string{"first", strings.Repeat("x", 100_000), "", "last"}
+	logPath := writeIndexedLog(t, lines)
+
+	check := func(name, logPath string) {
+		for i, want := range lines {
+			got, err := ReadLogLine(logPath, i+1)
+			if err != nil || got != want {
+				t.Errorf("%s: line %d = %.20q (err %v), want %.20q", name, i+1, got, err, want)
+			}
+		}
+		for _, line := range []int{0, len(lines) + 1} {
+			if _, err := ReadLogLine(logPath, line); !errors.Is(err, ErrLogLineNotFound) {
+				t.Errorf("%s: line %d err = %v, want ErrLogLineNotFound", name, line, err)
+			}
+		}
+	}
+	check("indexed", logPath)
+
+	// A compressed log is read through the index of the plain one.
+	gzPath, err := compressLog(logPath)
+	if err != nil {
+		t.Fatalf("compressLog: %v", err)
+	}
+	check("compressed", gzPath)
+
+	// Logs written before indexes existed are scanned instead.
+	if err := os.Remove(LogIndexPath(logPath)); err != nil {
+		t.Fatal(err)
+	}
+	check("unindexed", logPath)
+	check("compressed unindexed", gzPath)
+}
+
+func TestRunTierWritesLogIndex(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	long := strings.Repeat("y", toolResultHTMLMax+50)
+	m.runner = &pipeRunner{
+		events: []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"type":"user","message":{"content":[{"type":"tool_result","content":"` + long + `"}]}}`,
+			`{"type":"result","result":"done","is_error":false}`,
+		},
+		resultIdx: 2,
+	}
+
+	id, _, err := m.runTier(context.Background(), 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	sess, _ := database.GetSession(id)
+	if sess == nil || sess.LogFile == nil {
+		t.Fatal("expected a log file")
+	}
+	if !strings.HasSuffix(*sess.LogFile, ".log.gz") {
+		t.Errorf("LogFile = %q, want the compressed log", *sess.LogFile)
+	}
+	if _, err := os.Stat(strings.TrimSuffix(*sess.LogFile, ".gz")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("plain log left behind: %v", err)
+	}
+	if _, err := os.Stat(LogIndexPath(*sess.LogFile)); err != nil {
+		t.Fatalf("expected log index: %v", err)
+	}
+	line, err := ReadLogLine(*sess.LogFile, 2)
+	if err != nil {
+		t.Fatalf("ReadLogLine: %v", err)
+	}
+	_, raw, _ := ParseTimestampedLogLine(line)
+	if got := FullToolResultHTML(raw); !strings.Contains(got, long) {
+		t.Errorf("expected the full tool result, got %.100q", got)
+	}
+}
+
+func TestRunTierNoLogCompression(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.NoLogCompression = true
+	m.runner = &pipeRunner{
+		events:    []string{`{"type":"result","result":"done","is_error":false}`},
+		resultIdx: 0,
+	}
+
+	id, _, err := m.runTier(context.Background(), 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	sess, _ := database.GetSession(id)
+	if sess == nil || sess.LogFile == nil || !strings.HasSuffix(*sess.LogFile, ".log") {
+		t.Fatalf("expected a plain log, got %+v", sess)
+	}
+	if _, err := os.Stat(*sess.LogFile); err != nil {
+		t.Errorf("plain log: %v", err)
+	}
+}
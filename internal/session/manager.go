@@ -4,18 +4,28 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/joestump/claude-ops/internal/config"
 	"github.com/joestump/claude-ops/internal/db"
 	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/logsource"
+	"github.com/joestump/claude-ops/internal/policy"
+	"github.com/joestump/claude-ops/internal/proxmox"
+	"github.com/joestump/claude-ops/internal/scheduler"
+	"github.com/joestump/claude-ops/internal/servicename"
+	"github.com/joestump/claude-ops/internal/textutil"
 )
 
 // adHocRequest carries the prompt, start tier, and trigger label for a manually triggered session.
@@ -24,6 +34,16 @@ type adHocRequest struct {
 	prompt    string
 	startTier int
 	trigger   string // "manual" for web UI, "api" for Ollama/OpenAI API callers
+	// started receives the ID of the chain's first session, or 0 if none
+	// started.
+	started chan int64
+}
+
+// pulseRequest carries the services and failure detail for a session
+// triggered by Tier 0 pulse probes.
+type pulseRequest struct {
+	services []string
+	detail   string
 }
 
 // Governing: SPEC-0012 "Channel-Based Trigger in Session Manager"
@@ -34,49 +54,128 @@ type adHocRequest struct {
 type Manager struct {
 	cfg      *config.Config
 	db       *db.DB
-	hub      *hub.Hub
-	rawHub   *hub.Hub // Governing: SPEC-0024 REQ-5 — raw NDJSON event hub for OpenAI streaming
+	hub      *hub.Hub // event bus for raw NDJSON, formatted HTML, and lifecycle events
 	runner   ProcessRunner
-	redactor *RedactionFilter // Governing: SPEC-0014 REQ "Log Redaction of Credential Values" — applied to all output streams
+	tierEnv  map[int][]EnvVar   // extra CLI environment per tier, from CLAUDEOPS_TIER<n>_ENV
+	redactor *RedactionFilter   // Governing: SPEC-0014 REQ "Log Redaction of Credential Values" — applied to all output streams
+	proxmox  *proxmox.Client    // nil when the Proxmox integration is not configured
+	logs     *logsource.Fetcher // nil when no log source is configured
+	// promptRules select specialized Tier 2 prompts from the escalation context.
+	promptRules []PromptRule
+	// pricing estimates session cost from token usage when the CLI reports
+	// none, as on a Claude subscription.
+	pricing []ModelPrice
+	// services canonicalises the service names agents report.
+	services *servicename.Normalizer
+
+	// hooks receive lifecycle callbacks; see AddHooks.
+	hooksMu sync.RWMutex
+	hooks   []Hooks
+
+	// gitBaselines holds, per running session, the uncommitted state of
+	// each repository it may edit (map[string]gitBaseline).
+	gitBaselines sync.Map
 
 	// PreSessionHook is called before each session starts.
 	// If it returns an error, the session is skipped.
 	PreSessionHook func() error
 
-	mu             sync.Mutex
-	running        bool
-	cmd            *exec.Cmd
-	stopFn         func() // cancels the currently running session's context
-	stoppedByUser  bool   // set by Stop() so runTier can use "stopped" status
+	// Policy holds the operator's guardrail rules, evaluated before each
+	// escalation and cooldown action. Nil allows everything.
+	Policy *policy.Engine
+
+	// Scheduler decides when scheduled runs fire. Nil runs them every
+	// cfg.Interval seconds.
+	Scheduler *scheduler.Scheduler
+
+	mu            sync.Mutex
+	running       bool
+	cmd           *exec.Cmd
+	stopFn        func() // cancels the currently running session's context
+	stoppedByUser bool   // set by Stop() so runTier can use "stopped" status
 	// Governing: SPEC-0012 "Channel-Based Trigger in Session Manager" — buffered channel (size 1)
-	triggerCh   chan adHocRequest
-	lastAdHocID chan int64
+	triggerCh chan adHocRequest
+	// adHocStarted is the running ad-hoc request's started channel until
+	// its first session is recorded.
+	adHocStarted chan int64
+	// chain is what the running escalation chain is working on (nil when
+	// none is running).
+	chain      *activeChain
+	pulseCh    chan pulseRequest
+	verifyCh   chan verifyRequest
+	drillCh    chan struct{}
+	resumeCh   chan ChainStart
+	approvedCh chan ChainStart
+	// runNowCh ends the wait for the next scheduled run; nextRun is when
+	// that run is due (zero when the manager is not waiting).
+	runNowCh chan struct{}
+	nextRun  time.Time
+	// live is the running session's progress (nil when none is running).
+	live *LiveSession
+	// drainCh is closed by Drain when shutdown begins.
+	drainCh   chan struct{}
+	drainOnce sync.Once
+	// notify sends a supervisor notification (apprise; replaced in tests).
+	notify func(ctx context.Context, title, body string) error
+	// cliVersion is the `claude --version` output recorded at startup;
+	// cliVersionFn runs it (replaced in tests).
+	cliVersion   string
+	cliVersionFn func(ctx context.Context) (string, error)
+	// summarize writes a session's TL;DR (the summary model; replaced in
+	// demo mode).
+	summarize func(ctx context.Context, response, model string) (string, error)
+	// summarizeChain writes an escalation chain's summary (the summary
+	// model; demo mode builds it locally).
+	summarizeChain func(ctx context.Context, steps, model string) (string, error)
+	// summaries caches summaries for reuse by near-identical responses.
+	summaries *summaryCache
 }
 
 // New creates a Manager with the given configuration.
 func New(cfg *config.Config, database *db.DB, h *hub.Hub, runner ProcessRunner) *Manager {
-	return &Manager{
+	m := &Manager{
 		cfg:         cfg,
 		db:          database,
 		hub:         h,
-		rawHub:      hub.New(), // Governing: SPEC-0024 REQ-5 — raw NDJSON hub for OpenAI streaming
 		runner:      runner,
 		redactor:    NewRedactionFilter(),
+		proxmox:     proxmox.FromConfig(cfg),
+		logs:        logsource.FromConfig(cfg),
+		promptRules: ParsePromptRules(cfg.Tier2PromptRules),
+		pricing:     ParsePricing(cfg.SyntheticPricing),
+		services:    servicename.FromConfig(cfg, database),
 		triggerCh:   make(chan adHocRequest, 1),
-		lastAdHocID: make(chan int64, 1),
-	}
-}
-
-// RawHub returns the raw NDJSON event hub used by OpenAI streaming.
-// Governing: SPEC-0024 REQ-5 (Streaming Response), ADR-0020
-func (m *Manager) RawHub() *hub.Hub {
-	return m.rawHub
+		pulseCh:     make(chan pulseRequest, 1),
+		verifyCh:    make(chan verifyRequest, 8),
+		drillCh:     make(chan struct{}, 1),
+		resumeCh:    make(chan ChainStart, 1),
+		approvedCh:  make(chan ChainStart, 8),
+		runNowCh:    make(chan struct{}, 1),
+		drainCh:     make(chan struct{}),
+		tierEnv:     make(map[int][]EnvVar),
+		summaries:   newSummaryCache(),
+	}
+	m.loadTierEnv()
+	m.checkTierDirs()
+	m.notify = m.notifyApprise
+	m.cliVersionFn = claudeVersion
+	m.summarize = summarizeResponse
+	m.summarizeChain = summarizeChainSteps
+	if cfg.Demo {
+		m.cliVersionFn = func(context.Context) (string, error) { return "demo", nil }
+		m.summarize = demoSummary
+		m.summarizeChain = func(context.Context, string, string) (string, error) { return "", errNoAPIKey }
+	}
+	return m
 }
 
 // Governing: SPEC-0012 REQ "Busy Rejection When Session Already Running" (mutex check + channel buffer rejects concurrent triggers)
 // Governing: SPEC-0012 "TriggerAdHoc Public API" — non-blocking send, goroutine-safe
 // TriggerAdHoc sends a prompt to trigger an immediate session.
-// Returns the session ID once created, or error if busy.
+// Returns the session ID once created. While a chain is running, a prompt
+// that names a service the chain is working on is rejected with a
+// *ServiceConflictError; any other waits for the chain and returns a
+// *QueuedError.
 // Governing: SPEC-0012 "TriggerAdHoc Public API" — channel-based trigger, busy rejection
 // Governing: SPEC-0024 REQ-4 (Session Triggering with startTier), ADR-0020 (Tier Selection)
 func (m *Manager) TriggerAdHoc(prompt string, startTier int, trigger string) (int64, error) {
@@ -93,23 +192,51 @@ func (m *Manager) TriggerAdHoc(prompt string, startTier int, trigger string) (in
 		trigger = "manual"
 	}
 
-	m.mu.Lock()
-	if m.running {
-		m.mu.Unlock()
-		return 0, fmt.Errorf("session already running")
+	if m.Draining() {
+		return 0, fmt.Errorf("shutting down")
 	}
+
+	m.mu.Lock()
+	busy := m.running || m.chain != nil
 	m.mu.Unlock()
+	req := adHocRequest{prompt: prompt, startTier: startTier, trigger: trigger, started: make(chan int64, 1)}
+	if busy {
+		if conflict := m.serviceConflict(prompt); conflict != nil {
+			return 0, conflict
+		}
+	}
 
 	select {
-	case m.triggerCh <- adHocRequest{prompt: prompt, startTier: startTier, trigger: trigger}:
+	case m.triggerCh <- req:
+		if busy {
+			return 0, &QueuedError{Started: req.started}
+		}
 		// Wait for the session ID to be assigned.
-		id := <-m.lastAdHocID
-		return id, nil
+		if id := <-req.started; id != 0 {
+			return id, nil
+		}
+		return 0, fmt.Errorf("session did not start")
 	default:
 		return 0, fmt.Errorf("trigger queue full")
 	}
 }
 
+// TriggerPulse queues an out-of-band Tier 1 session because Tier 0 probes
+// failed for services. detail is added to the session context. It does not
+// wait for the session to start and returns false if a session is already
+// running or queued.
+func (m *Manager) TriggerPulse(services []string, detail string) bool {
+	if m.IsRunning() || m.Draining() {
+		return false
+	}
+	select {
+	case m.pulseCh <- pulseRequest{services: services, detail: detail}:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsRunning reports whether a session is currently executing.
 func (m *Manager) IsRunning() bool {
 	m.mu.Lock()
@@ -131,18 +258,21 @@ func (m *Manager) Stop() bool {
 }
 
 // Run starts the session loop. It runs one scheduled session immediately, then
-// waits cfg.Interval seconds before the next. Ad-hoc triggers received during
+// waits until the Scheduler's next fire time (cfg.Interval seconds by default)
+// before the next. Ad-hoc triggers received during
 // the interval wait are executed immediately and do NOT restart the interval
 // timer or cause an extra scheduled run — the wait simply resumes for whatever
-// time remains. It returns when ctx is cancelled.
+// time remains. It returns when ctx is cancelled, or after the in-flight chain
+// once Drain is called.
 // Governing: SPEC-0008 REQ-5 "Scheduled session invocation"
 // — invokes sessions at the configured interval after each completion.
 func (m *Manager) Run(ctx context.Context) error {
 	for {
-		m.runEscalationChain(ctx, "scheduled", nil, 1)
-
-		fmt.Printf("[%s] Sleeping %ds until next run...\n\n",
-			time.Now().UTC().Format(time.RFC3339), m.cfg.Interval)
+		m.ExpireApprovals()
+		m.observeScheduled(m.runScheduled(ctx))
+		if m.Draining() {
+			return nil
+		}
 
 		if !m.waitForInterval(ctx) {
 			return nil
@@ -150,14 +280,26 @@ func (m *Manager) Run(ctx context.Context) error {
 	}
 }
 
-// waitForInterval blocks until the configured interval has elapsed (measured
-// from the moment of the call) or ctx is cancelled. Any ad-hoc triggers that
-// arrive during the wait are executed immediately; the deadline is not reset
-// after an ad-hoc run — the interval continues counting from when
-// waitForInterval was first called. Returns false if ctx is cancelled.
+// waitForInterval blocks until the next scheduled run is due (the interval
+// measured from the moment of the call, or the cron schedule's next fire
+// time, plus jitter) or ctx is cancelled. Any ad-hoc, pulse,
+// verification, or drill triggers that arrive during the wait are executed immediately;
+// the deadline is not reset after an ad-hoc run — the interval continues counting from when
+// waitForInterval was first called. RunNow ends the wait early. Returns false
+// if ctx is cancelled or the manager is draining.
 // Governing: SPEC-0012 "Channel-Based Trigger in Session Manager" — select wakes on triggerCh
 func (m *Manager) waitForInterval(ctx context.Context) bool {
-	deadline := time.Now().Add(time.Duration(m.cfg.Interval) * time.Second)
+	now := time.Now()
+	deadline := m.nextScheduledRun(now)
+	fmt.Printf("[%s] Sleeping %s until next run at %s...\n\n",
+		now.UTC().Format(time.RFC3339), deadline.Sub(now).Round(time.Second), deadline.UTC().Format(time.RFC3339))
+	m.setNextRun(deadline)
+	defer m.setNextRun(time.Time{})
+	// Drop a run-now request left over from before this wait.
+	select {
+	case <-m.runNowCh:
+	default:
+	}
 	for {
 		remaining := time.Until(deadline)
 		if remaining <= 0 {
@@ -166,9 +308,23 @@ func (m *Manager) waitForInterval(ctx context.Context) bool {
 		select {
 		case <-ctx.Done():
 			return false
+		case <-m.drainCh:
+			return false
 		case req := <-m.triggerCh:
-			m.runAdHoc(ctx, req.prompt, req.startTier, req.trigger)
+			m.runAdHoc(ctx, req)
 			// Don't reset deadline — resume waiting for the original interval.
+		case req := <-m.pulseCh:
+			m.runEscalationChain(ctx, "pulse", nil, 1, req.detail, req.services)
+		case req := <-m.verifyCh:
+			m.runVerification(ctx, req)
+		case <-m.drillCh:
+			m.runDrill(ctx)
+		case start := <-m.resumeCh:
+			m.runChain(ctx, start)
+		case start := <-m.approvedCh:
+			m.runChain(ctx, start)
+		case <-m.runNowCh:
+			return true
 		case <-time.After(remaining):
 			return true
 		}
@@ -177,19 +333,54 @@ func (m *Manager) waitForInterval(ctx context.Context) bool {
 
 // Governing: SPEC-0012 REQ "Ad-Hoc Session Uses runOnce with Custom Prompt" (custom prompt via promptOverride, identical lifecycle to scheduled)
 // runAdHoc handles a manually triggered session with full escalation support.
-func (m *Manager) runAdHoc(ctx context.Context, prompt string, startTier int, trigger string) {
-	m.runEscalationChain(ctx, trigger, &prompt, startTier)
+func (m *Manager) runAdHoc(ctx context.Context, req adHocRequest) {
+	m.mu.Lock()
+	m.adHocStarted = req.started
+	m.mu.Unlock()
+	m.runEscalationChain(ctx, req.trigger, &req.prompt, req.startTier, "", nil)
+	// The chain ended without recording a session.
+	if started := m.takeAdHocStarted(); started != nil {
+		started <- 0
+	}
+}
+
+// takeAdHocStarted returns the running ad-hoc request's started channel, if
+// its first session has not been sent on it yet, and clears it.
+func (m *Manager) takeAdHocStarted() chan int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	started := m.adHocStarted
+	m.adHocStarted = nil
+	return started
 }
 
 // Governing: SPEC-0016 "Supervisor Escalation Logic" — controls all escalation decisions
 // runEscalationChain runs Tier 1 (or startTier) and escalates to higher tiers if the agent
 // writes a handoff file requesting it. promptOverride is used for ad-hoc
 // sessions where the first tier uses a custom prompt instead of the standard
-// prompt file.
-func (m *Manager) runEscalationChain(ctx context.Context, trigger string, promptOverride *string, startTier int) {
+// prompt file. initialContext and initialServices seed the first tier's
+// context the same way a handoff seeds an escalated tier (used by pulse
+// triggers to name the failing services). It returns the ID of the chain's
+// first session, or 0 if none was started.
+func (m *Manager) runEscalationChain(ctx context.Context, trigger string, promptOverride *string, startTier int, initialContext string, initialServices []string) int64 {
+	return m.runChain(ctx, ChainStart{
+		Tier:     startTier,
+		Trigger:  trigger,
+		Prompt:   promptOverride,
+		Context:  initialContext,
+		Services: initialServices,
+	})
+}
+
+// runChain runs an escalation chain from start, which is either a fresh
+// chain or one resumed after a shutdown interrupted it. When the manager is
+// draining, the chain stops before its next tier, or when its in-flight tier
+// is cancelled, and is recorded so it can be resumed after restart.
+func (m *Manager) runChain(ctx context.Context, start ChainStart) int64 {
 	// Governing: SPEC-0015 "Staleness Decay" — 0.1/week after 30-day grace, deactivate below 0.3
 	// Decay stale memories before each escalation chain.
-	if err := m.db.DecayStaleMemories(30, 0.1); err != nil {
+	scoring := NewMemoryScoring(m.cfg)
+	if err := m.db.DecayMemories(scoring.DecayGraceDays, scoring.Default, scoring.Categories); err != nil {
 		fmt.Fprintf(os.Stderr, "decay stale memories: %v\n", err)
 	}
 
@@ -199,6 +390,9 @@ func (m *Manager) runEscalationChain(ctx context.Context, trigger string, prompt
 		fmt.Fprintf(os.Stderr, "cleanup stale handoff: %v\n", err)
 	}
 
+	m.beginChain(start.Services)
+	defer m.endChain()
+
 	tierModels := map[int]string{
 		1: m.cfg.Tier1Model,
 		2: m.cfg.Tier2Model,
@@ -210,15 +404,26 @@ func (m *Manager) runEscalationChain(ctx context.Context, trigger string, prompt
 		3: m.cfg.Tier3Prompt,
 	}
 
-	var parentSessionID *int64
-	currentTier := startTier
-	handoffContext := ""
-	currentTrigger := trigger
+	var rootSessionID int64
+	parentSessionID := start.ParentID
+	currentTier := start.Tier
+	handoffContext := start.Context
+	handoffServices := start.Services
+	selectedPrompt := start.PromptFile
+	currentTrigger := start.Trigger
+	promptOverride := start.Prompt
+	// splits counts continuation sessions at the current tier, and
+	// splitBase is the context its first session was given.
+	splits := 0
+	splitBase := handoffContext
 
 	// Governing: SPEC-0016 "Supervisor Escalation Logic" — MaxTier enforces tier limit
 	for currentTier <= m.cfg.MaxTier {
 		model := tierModels[currentTier]
 		promptFile := tierPrompts[currentTier]
+		if selectedPrompt != "" {
+			promptFile = selectedPrompt
+		}
 
 		// Only use the prompt override for the first tier in the chain.
 		var po *string
@@ -226,22 +431,110 @@ func (m *Manager) runEscalationChain(ctx context.Context, trigger string, prompt
 			po = promptOverride
 		}
 
-		sessionID, agentResp, err := m.runTier(ctx, currentTier, model, promptFile, parentSessionID, handoffContext, currentTrigger, po)
+		// Tier 3 remediation of a two-person service waits for approval,
+		// whether escalated to or started at directly. Without a handoff to
+		// name the services, those the prompt names are gated.
+		if currentTier == 3 && start.ApprovalID == 0 && splits == 0 {
+			gated := m.twoPersonServices(ctx, handoffServices)
+			if len(handoffServices) == 0 && promptOverride != nil {
+				gated = m.promptTwoPersonServices(ctx, *promptOverride)
+			}
+			if len(gated) > 0 {
+				var requester int64
+				if parentSessionID != nil {
+					requester = *parentSessionID
+				}
+				m.requestApproval(ctx, requester, ChainStart{
+					Tier:       currentTier,
+					Trigger:    currentTrigger,
+					PromptFile: selectedPrompt,
+					Prompt:     promptOverride,
+					Context:    handoffContext,
+					Services:   handoffServices,
+					ParentID:   parentSessionID,
+				}, gated)
+				break
+			}
+		}
+
+		sessionID, agentResp, err := m.runTier(ctx, currentTier, model, promptFile, parentSessionID, handoffContext, handoffServices, currentTrigger, po)
+		if rootSessionID == 0 {
+			rootSessionID = sessionID
+		}
+		var split *splitError
+		if errors.As(err, &split) {
+			if splits >= m.cfg.MaxSplits || ctx.Err() != nil {
+				m.emitEscalationEventLevel(sessionID, "warning", fmt.Sprintf("Session split (%s) but not continued: limit of %d continuation sessions reached", split.reason, m.cfg.MaxSplits))
+				break
+			}
+			splits++
+			handoffContext = split.continuationContext(sessionID, splitBase)
+			parentSessionID = &sessionID
+			if start.Trigger != "drill" {
+				currentTrigger = "continuation"
+			}
+			if m.Draining() {
+				m.saveInterruptedChain(sessionID, start, ChainStart{
+					Tier:       currentTier,
+					Trigger:    currentTrigger,
+					PromptFile: selectedPrompt,
+					Context:    handoffContext,
+					Services:   handoffServices,
+					ParentID:   parentSessionID,
+					ApprovalID: start.ApprovalID,
+				})
+				break
+			}
+			fmt.Printf("[%s] Continuing tier %d in a new session (%s)\n",
+				time.Now().UTC().Format(time.RFC3339), currentTier, split.reason)
+			continue
+		}
 		if err != nil {
 			fmt.Printf("[%s] ERROR: tier %d session failed: %v\n",
 				time.Now().UTC().Format(time.RFC3339), currentTier, err)
+			m.recordDecision(m.newDecision(sessionID, currentTier), DecisionFailed, 0, err.Error())
+			if ctx.Err() != nil && m.Draining() {
+				m.saveInterruptedChain(sessionID, start, ChainStart{
+					Tier:       currentTier,
+					Trigger:    currentTrigger,
+					Prompt:     po,
+					PromptFile: selectedPrompt,
+					Context:    handoffContext,
+					Services:   handoffServices,
+					ParentID:   parentSessionID,
+					ApprovalID: start.ApprovalID,
+				})
+			}
 			break
 		}
 
+		// Schedule a check that the remediation held. Tier 3 is the top of
+		// the chain, so this runs whether or not it asked to escalate further.
+		if currentTier == 3 && sessionID != 0 && !m.cfg.DryRun {
+			m.scheduleVerification(sessionID, remediatedServices(handoffServices, agentResp))
+		}
+
 		// Governing: ADR-0030, SPEC-0031 REQ-3 — check structured output for escalation first,
 		// then fall back to handoff file for backward compatibility (SPEC-0031 REQ-8).
 		escalationNeeded := false
 		nextTier := currentTier + 1
 		var escalationCtx string
 		var servicesAffected []string
+		decision := m.newDecision(sessionID, currentTier)
+		source := "structured"
 
-		if agentResp != nil && agentResp.Escalation.Needed {
+		// A session that hands back to a lower tier ends its chain with a
+		// cheaper session that verifies its work.
+		if agentResp != nil && agentResp.Escalation.Needed && isHandBack(agentResp.Escalation.RecommendedTier, currentTier) {
+			_ = DeleteHandoff(m.cfg.StateDir)
+			m.handBack(ctx, decision, source, sessionID, currentTier, agentResp.Escalation.RecommendedTier,
+				remediatedServices(handoffServices, agentResp), handBackFindings(agentResp))
+			break
+		} else if agentResp != nil && agentResp.Escalation.Needed {
 			escalationNeeded = true
+			if rt := agentResp.Escalation.RecommendedTier; rt > currentTier && rt <= 3 {
+				nextTier = rt
+			}
 			escalationCtx = buildStructuredEscalationContext(agentResp)
 			for _, sc := range agentResp.ServicesChecked {
 				if sc.Status != "healthy" {
@@ -254,16 +547,26 @@ func (m *Manager) runEscalationChain(ctx context.Context, trigger string, prompt
 			_ = DeleteHandoff(m.cfg.StateDir)
 		} else if agentResp != nil && !agentResp.Escalation.Needed {
 			_ = DeleteHandoff(m.cfg.StateDir)
+			m.recordDecision(decision, DecisionNotRequested, 0, "The agent reported that no escalation is needed")
 		} else {
+			source = "handoff"
 			// Governing: SPEC-0031 REQ-8 — fallback to handoff file when no structured output
 			h, hErr := ReadHandoff(m.cfg.StateDir)
 			if hErr != nil {
 				fmt.Fprintf(os.Stderr, "read handoff after tier %d: %v\n", currentTier, hErr)
 				msg := fmt.Sprintf("Escalation blocked: could not read handoff from tier %d — %v", currentTier, hErr)
 				m.emitEscalationEvent(sessionID, msg)
+				m.recordDecision(decision, DecisionHandoffError, 0, msg)
 				break
 			}
 			if h == nil {
+				m.recordDecision(decision, DecisionNotRequested, 0, "The agent did not ask to escalate")
+				break
+			}
+			if h.SchemaVersion == 1 && isHandBack(h.RecommendedTier, currentTier) {
+				_ = DeleteHandoff(m.cfg.StateDir)
+				m.handBack(ctx, decision, source, sessionID, currentTier, h.RecommendedTier,
+					h.ServicesAffected, strings.TrimSpace(h.InvestigationFindings+"\n\n"+h.RemediationAttempted))
 				break
 			}
 			if vErr := ValidateHandoff(h, m.cfg.MaxTier); vErr != nil {
@@ -271,6 +574,9 @@ func (m *Manager) runEscalationChain(ctx context.Context, trigger string, prompt
 				_ = DeleteHandoff(m.cfg.StateDir)
 				msg := fmt.Sprintf("Escalation blocked: invalid handoff from tier %d — %v", currentTier, vErr)
 				m.emitEscalationEvent(sessionID, msg)
+				raw, _ := json.MarshalIndent(h, "", "  ")
+				m.requested(decision, source, h.RecommendedTier, h.ServicesAffected, string(raw))
+				m.recordDecision(decision, DecisionInvalid, 0, msg)
 				break
 			}
 			escalationNeeded = true
@@ -283,6 +589,7 @@ func (m *Manager) runEscalationChain(ctx context.Context, trigger string, prompt
 		if !escalationNeeded {
 			break
 		}
+		m.requested(decision, source, nextTier, servicesAffected, escalationCtx)
 
 		// Governing: SPEC-0016 "Supervisor Escalation Logic" — dry-run prevents escalation
 		if m.cfg.DryRun && nextTier >= 2 {
@@ -291,6 +598,21 @@ func (m *Manager) runEscalationChain(ctx context.Context, trigger string, prompt
 			msg := fmt.Sprintf("Escalation suppressed (dry run): would have escalated to tier %d for: %s",
 				nextTier, strings.Join(servicesAffected, ", "))
 			m.emitEscalationEventLevel(sessionID, "info", msg)
+			m.recordDecision(decision, DecisionDryRun, 0, msg)
+			break
+		}
+
+		// Skipping a tier may be disallowed; policy rules may then deny the
+		// escalation or cap the tier it goes to.
+		var skipMsg, policyMsg string
+		nextTier, skipMsg = m.checkTierSkip(sessionID, currentTier, nextTier)
+		if nextTier, policyMsg = m.checkEscalationPolicy(sessionID, currentTier, nextTier, start.Trigger, escalationCtx, servicesAffected); nextTier == 0 {
+			m.recordDecision(decision, DecisionPolicyDenied, 0, policyMsg)
+			break
+		}
+		// Another chain escalated for the same services too recently.
+		if msg := m.checkEscalationCooldown(ctx, sessionID, nextTier, start.Trigger, servicesAffected); msg != "" {
+			m.recordDecision(decision, DecisionCooldown, 0, msg)
 			break
 		}
 
@@ -298,14 +620,78 @@ func (m *Manager) runEscalationChain(ctx context.Context, trigger string, prompt
 			fmt.Fprintf(os.Stderr, "update escalated status for session %d: %v\n", sessionID, err)
 		}
 
+		// Pick a specialized investigation prompt from the handoff before log
+		// lines are attached, so incidental log text does not steer it.
+		selectedPrompt = ""
+		if nextTier == 2 {
+			if p, matched := selectPrompt(tierPrompts[2], m.promptRules, escalationCtx); len(matched) > 0 {
+				selectedPrompt = p
+				m.emitEscalationEventLevel(sessionID, "info", fmt.Sprintf("Tier 2 prompt %s selected (matched: %s)",
+					filepath.Base(p), strings.Join(matched, ", ")))
+			}
+		}
+
+		if logCtx := m.buildLogContext(ctx, servicesAffected); logCtx != "" {
+			escalationCtx = strings.TrimRight(escalationCtx, "\n") + "\n\n" + logCtx
+		}
+
 		handoffContext = escalationCtx
+		handoffServices = servicesAffected
 		parentSessionID = &sessionID
+		fromTier := currentTier
 		currentTier = nextTier
-		currentTrigger = "escalation"
+		// Drill sessions keep their trigger so they stay apart from real incidents.
+		if start.Trigger != "drill" {
+			currentTrigger = "escalation"
+		}
+
+		if m.Draining() {
+			m.saveInterruptedChain(sessionID, start, ChainStart{
+				Tier:       currentTier,
+				Trigger:    currentTrigger,
+				PromptFile: selectedPrompt,
+				Context:    handoffContext,
+				Services:   handoffServices,
+				ParentID:   parentSessionID,
+			})
+			fmt.Printf("[%s] Shutting down: not escalating to tier %d\n",
+				time.Now().UTC().Format(time.RFC3339), currentTier)
+			m.recordDecision(decision, DecisionShutdown, 0,
+				fmt.Sprintf("The supervisor was shutting down; the escalation to tier %d was saved to resume after restart", currentTier))
+			break
+		}
+		if currentTier > m.cfg.MaxTier {
+			m.recordDecision(decision, DecisionMaxTier, 0,
+				fmt.Sprintf("Tier %d is above the maximum tier %d", currentTier, m.cfg.MaxTier))
+		} else {
+			reasons := []string{skipMsg, policyMsg}
+			if msg := tierSkipMessage(fromTier, currentTier); msg != "" {
+				m.emitEscalationEventLevel(sessionID, "info", msg)
+				reasons = append(reasons, msg)
+			}
+			m.recordDecision(decision, DecisionEscalated, currentTier, joinReasons(reasons))
+		}
 
 		fmt.Printf("[%s] Escalating to tier %d for services %v\n",
 			time.Now().UTC().Format(time.RFC3339), currentTier, servicesAffected)
+		if currentTier <= m.cfg.MaxTier {
+			m.publishChainEvent(m.chainRoot(), ChainEvent{Type: ChainEscalated, SessionID: sessionID,
+				FromTier: fromTier, Tier: currentTier, Model: tierModels[currentTier], Services: servicesAffected})
+		}
+		m.runHooks("OnEscalation", func(h Hooks) {
+			h.OnEscalation(Escalation{
+				SessionID: sessionID,
+				FromTier:  fromTier,
+				ToTier:    currentTier,
+				Services:  servicesAffected,
+				Context:   handoffContext,
+			})
+		})
 	}
+	if rootSessionID != 0 {
+		m.recordChainSummary(ctx, rootSessionID)
+	}
+	return rootSessionID
 }
 
 // tierToolConfig returns the allowed and disallowed tool strings for the given tier,
@@ -334,7 +720,7 @@ func (m *Manager) tierToolConfig(tier int) (allowed, disallowed string) {
 // promptOverride is used for ad-hoc sessions (non-nil pointer); promptFile is used otherwise.
 // Governing: SPEC-0008 REQ-7 — full subprocess lifecycle (start, stream, wait, exit code, crash handling).
 // Governing: ADR-0030, SPEC-0031 — returns AgentResponse for structured escalation decisions.
-func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFile string, parentSessionID *int64, handoffContext string, trigger string, promptOverride *string) (int64, *AgentResponse, error) {
+func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFile string, parentSessionID *int64, handoffContext string, services []string, trigger string, promptOverride *string) (int64, *AgentResponse, error) {
 	// Governing: SPEC-0008 REQ-5 "Session already running"
 	// — guards against starting a second session for the same tier.
 	// Create a per-session cancellable context so Stop() can kill just this
@@ -380,6 +766,14 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 	}
 	defer logFile.Close() //nolint:errcheck
 
+	// The index is only needed to expand truncated lines on the session page,
+	// so a session runs without one rather than failing.
+	logIndex, err := createLogIndex(logPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	defer logIndex.Close() //nolint:errcheck
+
 	// Governing: SPEC-0016 "Supervisor Escalation Logic" — session record with parent_session_id
 	// Insert session record into DB.
 	// Governing: SPEC-0016 REQ "Per-Tier Cost Attribution" — each tier gets its own session record
@@ -393,30 +787,90 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 		StartedAt:       startedAt,
 		Trigger:         trigger,
 		ParentSessionID: parentSessionID,
+		// Recorded up front so truncated lines can be expanded while running.
+		LogFile: &logPath,
 	}
+	sess.SkippedTiers = m.sessionSkippedTiers(parentSessionID, tier)
 	if promptOverride != nil {
 		sess.PromptText = promptOverride
 		sess.PromptFile = "(ad-hoc)"
 	}
+	// Record where the CLI runs and what is checked out there, so file
+	// edits can be traced to the repo state they were made against.
+	workDir, gitSHA := resolveWorkDir(sessionCtx, m.tierDir(tier))
+	sess.WorkDir = &workDir
+	if gitSHA != "" {
+		sess.GitSHA = &gitSHA
+	}
 	sessionID, err := m.db.InsertSession(sess)
 	if err != nil {
 		return 0, nil, fmt.Errorf("insert session: %w", err)
 	}
+	sess.ID = sessionID
+	names := m.serviceNames(sessionID, services)
+	if len(names) > 0 {
+		if dbErr := m.db.UpdateSessionServices(sessionID, names); dbErr != nil {
+			fmt.Fprintf(os.Stderr, "failed to store session services %d: %v\n", sessionID, dbErr)
+		}
+		joined := strings.Join(names, ",")
+		sess.Services = &joined
+	}
+	live := &LiveSession{SessionID: sessionID, Tier: tier, Trigger: trigger, Services: services, StartedAt: time.Now()}
+	if sess.Services != nil {
+		live.Services = strings.Split(*sess.Services, ",")
+	}
+	m.chainSession(sess, live.Services)
+	m.setLive(live)
+	defer m.setLive(nil)
+	m.runHooks("OnSessionStart", func(h Hooks) { h.OnSessionStart(sess) })
 
-	// If this is a manual trigger, send the session ID back to the caller.
-	if trigger == "manual" {
-		m.lastAdHocID <- sessionID
+	// If this is an ad-hoc chain's first session, send its ID back to the
+	// caller.
+	if started := m.takeAdHocStarted(); started != nil {
+		started <- sessionID
 	}
 
 	// Build environment context string.
 	// Governing: SPEC-0015 REQ "Prompt Injection via buildMemoryContext" (memory context appended to --append-system-prompt)
 	envCtx := m.buildEnvContext()
-	if memCtx := m.buildMemoryContext(); memCtx != "" {
+	if m.proxmox != nil {
+		// The hypervisor skill calls back into the dashboard API and must
+		// identify the requesting session for the tier and cooldown guards.
+		base, curlOpts := m.localAPI()
+		envCtx += fmt.Sprintf(" CLAUDEOPS_SESSION_ID=%d CLAUDEOPS_API_URL=%s/api/v1", sessionID, base)
+		if curlOpts != "" {
+			envCtx += fmt.Sprintf(" CLAUDEOPS_API_CURL_OPTS=%q", curlOpts)
+		}
+	}
+	// The snapshot spares the agent from querying the database for state.
+	// A session runs without one rather than failing.
+	if path, err := m.writeStateSnapshot(); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+	} else {
+		envCtx += " CLAUDEOPS_STATE_SNAPSHOT=" + path
+	}
+	// Tier 1 sweeps every service, so cap each service to its top memories;
+	// escalated tiers are scoped to the handoff's services instead.
+	perService := 0
+	if tier == 1 {
+		perService = m.cfg.MemoryTier1PerService
+	}
+	// Memories are stored under canonical names, so aliases in the handoff
+	// are matched through names.
+	if memCtx := m.buildMemoryContext(names, perService); memCtx != "" {
 		envCtx += "\n\n" + memCtx
 	}
+	if tier == 1 {
+		if hvCtx := m.buildHypervisorContext(sessionCtx, sessionID); hvCtx != "" {
+			envCtx += "\n\n" + hvCtx
+		}
+	}
 	if handoffContext != "" {
 		envCtx += "\n\n" + handoffContext
 	}
+	if m.sandboxed(tier) {
+		envCtx += "\n\n" + m.sandboxContext(tier)
+	}
 
 	// Determine prompt content: use override for ad-hoc sessions,
 	// otherwise read from the prompt file.
@@ -424,12 +878,15 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 	if promptOverride != nil {
 		promptContent = *promptOverride
 	} else {
-		data, err := os.ReadFile(promptFile)
+		content, err := ReadPrompt(m.db, m.cfg.PromptStore, promptFile)
 		if err != nil {
 			m.finalizeSession(sessionID, "failed", nil, &logPath)
+			m.compressSessionLog(sessionID, logPath)
+			m.recordOutcome(sessionID, tier, "failed", nil, nil, false)
+			m.endSession(sessionID, "failed")
 			return 0, nil, fmt.Errorf("read prompt file %s: %w", promptFile, err)
 		}
-		promptContent = string(data)
+		promptContent = content
 	}
 
 	runStart := time.Now().UTC().Format(time.RFC3339)
@@ -443,19 +900,40 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 	// — passes both --allowedTools and --disallowedTools to the CLI subprocess.
 	// Governing: SPEC-0024 REQ-11 (Per-Tier Tool Enforcement for Chat Sessions), ADR-0023
 	allowedTools, disallowedTools := m.tierToolConfig(tier)
+	invocation := m.buildInvocation(tier, model, promptContent, allowedTools, disallowedTools, envCtx)
+	m.saveInvocation(sessionID, invocation)
+	m.startGitBaselines(sessionID, tier)
+	if m.sandboxed(tier) {
+		if err := m.startSandbox(sessionID, tier); err != nil {
+			m.finalizeSession(sessionID, "failed", nil, &logPath)
+			m.compressSessionLog(sessionID, logPath)
+			m.recordOutcome(sessionID, tier, "failed", nil, nil, false)
+			m.endSession(sessionID, "failed")
+			return 0, nil, fmt.Errorf("start sandbox: %w", err)
+		}
+	}
+	if tier == 3 {
+		// A split request or handoff left by a session that crashed is stale.
+		_ = m.takeSplitHandoff()
+	}
 	// Governing: ADR-0030, SPEC-0031 REQ-4 — pass schema path to CLI for structured output
-	stdoutPipe, waitFn, err := m.runner.Start(sessionCtx, model, promptContent, allowedTools, disallowedTools, envCtx, m.cfg.SchemaPath)
+	stdoutPipe, waitFn, err := m.runner.Start(sessionCtx, model, promptContent, allowedTools, disallowedTools, envCtx, m.cfg.SchemaPath, m.tierDir(tier), m.agentEnv(tier))
 	if err != nil {
 		m.finalizeSession(sessionID, "failed", nil, &logPath)
+		m.compressSessionLog(sessionID, logPath)
+		m.recordOutcome(sessionID, tier, "failed", nil, nil, false)
+		m.endSession(sessionID, "failed")
 		return 0, nil, fmt.Errorf("start claude: %w", err)
 	}
 
 	// Parse stream-json events and fan out formatted lines to stdout, log, and hub.
 	hubID := int(sessionID)
+	m.hub.Publish(hubID, hub.Lifecycle, "running")
 	// Governing: SPEC-0011 REQ "Result Event Metadata Extraction"
 	// — captures result, total_cost_usd, num_turns, and duration_ms from the result event.
 	var resultResponse string
 	var resultCostUSD float64
+	var resultCostSynthetic bool
 	var resultNumTurns int
 	var resultDurationMs int64
 	// lastAssistantText tracks the last non-empty assistant text block so we can
@@ -467,25 +945,54 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 	// Governing: ADR-0030, SPEC-0031 REQ-8 — collect text markers for fallback if no structured output
 	var pendingEvents []parsedEvent
 	var pendingMemories []parsedMemory
+	// Marker-like text the parsers above drop, recorded as parse warnings.
+	var parseWarnings []parseWarning
+
+	var stats streamStats
+	// Credential values redacted from the stream, by rule.
+	redactions := make(map[string]int)
+	ctxTracker := newContextTracker(model, m.cfg.ContextWarnPercent)
+	cost := newLiveCost(model, m.pricing)
+	// Long Tier 3 remediations are split into a continuation session before
+	// they run out of turns or context.
+	splitter := newSplitTracker(0, 0)
+	if tier == 3 {
+		splitter = newSplitTracker(m.cfg.SplitTurns, m.cfg.SplitContextPercent)
+	}
+	// Stops the session if it does not exit within the grace period after
+	// it was asked to split.
+	var splitTimer *time.Timer
 
 	streamDone := make(chan struct{})
 	go func() {
 		defer close(streamDone)
 		scanner := bufio.NewScanner(stdoutPipe)
 		scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
-		var lineNum int
+		var lineNum, logLine int
 		for scanner.Scan() {
 			// Governing: SPEC-0014 REQ "Log Redaction of Credential Values" — redact before any output channel
 			// Governing: SPEC-0014 "Browser Automation Auditing" — redact credentials before logging/SSE.
-			raw := m.redactor.Redact(scanner.Text())
+			raw := m.redactor.RedactCount(scanner.Text(), redactions)
 			ts := time.Now().UTC()
 
 			// Governing: SPEC-0011 "Raw NDJSON Log Preservation" (every raw line written unmodified for auditability)
 			// Write timestamped JSON to log file for forensic analysis.
-			_, _ = fmt.Fprintf(logFile, "%s\t%s\n", ts.Format(time.RFC3339Nano), raw)
+			// Thinking blocks can be left out of the stored log for privacy/size;
+			// they are still shown in the live stream.
+			logged := raw
+			if m.cfg.StripThinking {
+				logged = stripThinking(raw)
+			}
+			// With at-rest encryption on, each line is sealed on its own so
+			// the line index and line-by-line readers still work.
+			n, _ := fmt.Fprintln(logFile, m.db.Sealer().Seal(ts.Format(time.RFC3339Nano)+"\t"+logged))
+			logIndex.add(n)
+			logLine++
+
+			// Governing: SPEC-0024 REQ-5 — publish raw NDJSON for OpenAI streaming
+			m.hub.Publish(hubID, hub.Raw, raw)
 
-			// Governing: SPEC-0024 REQ-5 — publish raw NDJSON to rawHub for OpenAI streaming
-			m.rawHub.Publish(hubID, raw)
+			stats.observe(raw)
 
 			// Plain text for container stdout logs.
 			plainText := FormatStreamEvent(raw)
@@ -496,11 +1003,29 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 			// Check for result event to capture response and metadata.
 			var evt streamEvent
 			if err := json.Unmarshal([]byte(raw), &evt); err == nil {
+				if evt.Type == "system" && evt.Subtype == "init" && (evt.Model != "" || evt.Version != "") {
+					invocation.ResolvedModel = evt.Model
+					ctxTracker.setModel(evt.Model)
+					if evt.Model != "" {
+						cost.model = evt.Model
+					}
+					if evt.Version != "" {
+						invocation.CLIVersion = evt.Version
+					}
+					m.saveInvocation(sessionID, invocation)
+				}
 				if evt.Type == "result" {
 					if evt.Result != "" {
 						resultResponse = evt.Result
 					}
-					resultCostUSD = evt.TotalCostUSD
+					pricedModel := invocation.ResolvedModel
+					if pricedModel == "" {
+						pricedModel = model
+					}
+					resultCostUSD, resultCostSynthetic = m.resultCost(pricedModel, &evt)
+					if resultCostUSD > 0 {
+						m.updateLive(func(l *LiveSession) { l.CostUSD = resultCostUSD })
+					}
 					resultNumTurns = evt.NumTurns
 					resultDurationMs = evt.DurationMs
 					// Governing: ADR-0030, SPEC-0031 REQ-4 — capture structured_output from result event
@@ -513,30 +1038,47 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 				// for fallback. Events and memories are inserted after the stream completes,
 				// using structured output when available or text markers as fallback.
 				if evt.Type == "assistant" {
+					if ctxTracker.observe(evt.Message.Usage) {
+						m.emitEscalationEventLevel(sessionID, "warning", ctxTracker.warning())
+					}
+					costSoFar := cost.observe(evt.Message.ID, evt.Message.Usage)
+					m.updateLive(func(l *LiveSession) { l.CostUSD = costSoFar })
 					for _, block := range evt.Message.Content {
 						if block.Type == "text" {
 							if t := strings.TrimSpace(block.Text); t != "" {
 								lastAssistantText = t
 							}
-							pendingEvents = append(pendingEvents, parseEventMarkers(block.Text)...)
+							for _, e := range parseEventMarkers(block.Text) {
+								if e.Level == "critical" {
+									m.updateLive(func(l *LiveSession) { l.Critical = true })
+								}
+								pendingEvents = append(pendingEvents, e)
+							}
 							pendingMemories = append(pendingMemories, parseMemoryMarkers(block.Text)...)
+							parseWarnings = append(parseWarnings, findMalformedMarkers(block.Text)...)
 							// Cooldown markers are always parsed from text (not in structured output schema).
 							for _, pc := range parseCooldownMarkers(block.Text) {
 								m.insertCooldown(sessionID, tier, pc)
 							}
+							m.linkTicketMarkers(sessionID, block.Text)
 						}
 					}
 				}
+
+				if splitter.observe(&evt, ctxTracker) {
+					m.emitEscalationEventLevel(sessionID, "info", fmt.Sprintf("Splitting session: %s; asked the agent to write a handoff for a continuation session", splitter.reason))
+					splitTimer = m.requestSplit(splitter.reason, cancelSession)
+				}
 			}
 
 			_, _ = fmt.Fprintln(os.Stdout, plainText)
 
 			// Color-coded HTML for browser SSE stream, wrapped with line number + timestamp.
-			htmlLine := FormatStreamEventHTML(raw)
+			htmlLine := FormatStreamEventHTMLAt(raw, sessionID, logLine)
 			if htmlLine != "" {
 				lineNum++
 				wrapped := WrapLogLine(lineNum, ts.Format("15:04:05"), htmlLine)
-				m.hub.Publish(hubID, wrapped)
+				m.hub.Publish(hubID, hub.Formatted, wrapped)
 			}
 		}
 	}()
@@ -574,25 +1116,55 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 
 	case <-ctx.Done():
 		// Governing: SPEC-0008 REQ-13 — context cancellation triggers graceful session teardown.
+		// Stop reading the stream, so the redactions it counted can be saved.
+		_ = stdoutPipe.Close()
+		<-streamDone
+		m.saveRedactions(sessionID, redactions)
 		exitCode := 137
 		m.finalizeSession(sessionID, "timed_out", &exitCode, &logPath)
-		m.hub.Close(hubID)
-		m.rawHub.Close(hubID) // Governing: SPEC-0024 REQ-5 — close raw hub for OpenAI streaming
+		m.compressSessionLog(sessionID, logPath)
+		m.recordOutcome(sessionID, tier, "timed_out", nil, nil, false)
+		m.endSession(sessionID, "timed_out")
 		return sessionID, nil, ctx.Err()
 	}
 
+	var splitHandoff string
+	if splitter.reason != "" {
+		if splitTimer != nil {
+			splitTimer.Stop()
+		}
+		splitHandoff = m.takeSplitHandoff()
+	}
+
+	m.saveStreamDiagnostics(sessionID, &stats)
+	m.saveRedactions(sessionID, redactions)
+	m.checkStreamFormat(sessionID, &stats, invocation.CLIVersion)
+
 	runEnd := time.Now().UTC().Format(time.RFC3339)
 	fmt.Printf("[%s] Tier %d run complete.\n", runEnd, tier)
 
 	status := "completed"
 	var exitCode int
+	m.mu.Lock()
+	wasStopped := m.stoppedByUser
+	m.mu.Unlock()
+	if splitter.reason != "" && !wasStopped {
+		// A split session is continued when it wrote its handoff or was
+		// stopped at the end of the grace period. One that exited without a
+		// handoff finished its work before it saw the request.
+		switch {
+		case splitHandoff != "":
+			status = "continued"
+			m.emitEscalationEventLevel(sessionID, "info", "Split session wrote its handoff for the continuation session")
+		case waitErr != nil:
+			status = "continued"
+			m.emitEscalationEventLevel(sessionID, "warning", "Split session did not write a handoff in time; the continuation session gets one built from its transcript")
+		}
+	}
 	if waitErr != nil {
-		m.mu.Lock()
-		wasStopped := m.stoppedByUser
-		m.mu.Unlock()
 		if wasStopped {
 			status = "stopped"
-		} else {
+		} else if status != "continued" {
 			status = "failed"
 		}
 		if exitErr, ok := waitErr.(*exec.ExitError); ok {
@@ -602,6 +1174,7 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 		}
 	}
 	m.finalizeSession(sessionID, status, &exitCode, &logPath)
+	m.compressSessionLog(sessionID, logPath)
 
 	// If result.result was empty (model's last turn was a tool call, e.g. writing
 	// handoff.json), fall back to the last non-empty assistant text block so the
@@ -626,7 +1199,11 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 		// Governing: SPEC-0031 REQ-6 — insert events from structured output
 		m.processStructuredEvents(sessionID, agentResp.Events)
 		// Governing: SPEC-0031 REQ-7 — insert memories from structured output
-		m.processStructuredMemories(sessionID, tier, agentResp.Memories)
+		// Drills exercise a synthetic service; nothing they learn is real.
+		if trigger != "drill" {
+			m.processStructuredMemories(sessionID, tier, agentResp.Memories)
+			m.processServiceChecks(sessionID, agentResp.ServicesChecked)
+		}
 		// Use the structured summary for the session if the response text is empty.
 		if resultResponse == "" && agentResp.Summary != "" {
 			resultResponse = agentResp.Summary
@@ -639,18 +1216,21 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 		for _, pe := range pendingEvents {
 			sid := sessionID
 			now := time.Now().UTC().Format(time.RFC3339)
-			_, _ = m.db.InsertEvent(&db.Event{
+			_ = m.insertEvent(&db.Event{
 				SessionID: &sid,
 				Level:     pe.Level,
-				Service:   pe.Service,
+				Service:   m.serviceName(sessionID, pe.Service),
 				Message:   pe.Message,
 				CreatedAt: now,
 			})
 		}
 		for _, pm := range pendingMemories {
-			m.upsertMemory(sessionID, tier, pm)
+			if trigger != "drill" {
+				m.upsertMemory(sessionID, tier, pm)
+			}
 		}
 	}
+	m.saveParseWarnings(sessionID, parseWarnings, agentResp != nil)
 
 	// Governing: SPEC-0011 REQ "Result Event Metadata Extraction"
 	// — stores extracted metadata in the sessions table via UpdateSessionResult.
@@ -658,25 +1238,47 @@ func (m *Manager) runTier(ctx context.Context, tier int, model string, promptFil
 		if dbErr := m.db.UpdateSessionResult(sessionID, resultResponse, resultCostUSD, resultNumTurns, resultDurationMs); dbErr != nil {
 			fmt.Fprintf(os.Stderr, "failed to store session result %d: %v\n", sessionID, dbErr)
 		}
+		if resultCostSynthetic {
+			if dbErr := m.db.UpdateSessionCostSynthetic(sessionID, true); dbErr != nil {
+				fmt.Fprintf(os.Stderr, "failed to flag synthetic cost %d: %v\n", sessionID, dbErr)
+			}
+		}
+	}
+
+	if ctxTracker.max > 0 {
+		if dbErr := m.db.UpdateSessionMaxContext(sessionID, ctxTracker.max); dbErr != nil {
+			fmt.Fprintf(os.Stderr, "failed to store max context %d: %v\n", sessionID, dbErr)
+		}
 	}
 
 	// Generate and store an LLM summary of the session response.
 	// Governing: SPEC-0021 REQ "Session Summary Generation"
 	if resultResponse != "" {
-		summary, sumErr := summarizeResponse(ctx, resultResponse, m.cfg.SummaryModel)
-		if sumErr != nil {
-			fmt.Fprintf(os.Stderr, "failed to summarize session %d: %v\n", sessionID, sumErr)
-		} else if summary != "" {
+		summary, sumErr := m.summarizeCached(ctx, resultResponse)
+		if sumErr != nil && !errors.Is(sumErr, errNoAPIKey) {
+			fmt.Fprintf(os.Stderr, "failed to summarize session %d, using a local summary: %v\n", sessionID, sumErr)
+		}
+		if summary != "" {
 			if dbErr := m.db.UpdateSessionSummary(sessionID, summary); dbErr != nil {
 				fmt.Fprintf(os.Stderr, "failed to store session summary %d: %v\n", sessionID, dbErr)
 			}
 		}
 	}
 
-	// Close the SSE hub AFTER DB updates so the browser reload sees the final state.
-	m.hub.Close(hubID)
-	m.rawHub.Close(hubID) // Governing: SPEC-0024 REQ-5 — close raw hub for OpenAI streaming
+	m.recordOutcome(sessionID, tier, status, agentResp, pendingEvents, resultResponse != "")
 
+	// Close the SSE hub AFTER DB updates so the browser reload sees the final state.
+	m.endSession(sessionID, status)
+
+	if splitter.reason != "" && status == "continued" {
+		return sessionID, nil, &splitError{
+			reason:    splitter.reason,
+			turns:     splitter.turns,
+			handoff:   splitHandoff,
+			lastText:  lastAssistantText,
+			toolCalls: splitter.toolCalls,
+		}
+	}
 	if waitErr != nil {
 		return sessionID, agentResp, fmt.Errorf("claude exited: %w", waitErr)
 	}
@@ -689,10 +1291,29 @@ func (m *Manager) runOnce(ctx context.Context, promptOverride string, trigger st
 	if promptOverride != "" {
 		po = &promptOverride
 	}
-	_, _, err := m.runTier(ctx, 1, m.cfg.Tier1Model, m.cfg.Prompt, nil, "", trigger, po)
+	_, _, err := m.runTier(ctx, 1, m.cfg.Tier1Model, m.cfg.Prompt, nil, "", nil, trigger, po)
 	return err
 }
 
+// endSession captures a sandboxed session's file changes for approval,
+// commits the changes it left in git repositories (when enabled), publishes its final status as a lifecycle event, closes
+// its stream, ending every subscription, and passes the stored session to
+// the OnSessionEnd hooks.
+func (m *Manager) endSession(sessionID int64, status string) {
+	m.captureSandbox(sessionID)
+	sess, err := m.db.GetSession(sessionID)
+	if err == nil && sess != nil {
+		m.commitSessionChanges(sess)
+	}
+	m.hub.Publish(int(sessionID), hub.Lifecycle, status)
+	m.hub.Close(int(sessionID))
+	if err != nil || sess == nil {
+		fmt.Fprintf(os.Stderr, "session %d: load for end hooks: %v\n", sessionID, err)
+		return
+	}
+	m.runHooks("OnSessionEnd", func(h Hooks) { h.OnSessionEnd(sess) })
+}
+
 // finalizeSession updates the session record in the DB with final status.
 func (m *Manager) finalizeSession(id int64, status string, exitCode *int, logPath *string) {
 	endedAt := time.Now().UTC().Format(time.RFC3339)
@@ -715,14 +1336,53 @@ func (m *Manager) processStructuredEvents(sessionID int64, events []AgentEvent)
 		}
 		if ae.Service != "" {
 			svc := ae.Service
-			evt.Service = &svc
+			evt.Service = m.serviceName(sessionID, &svc)
 		}
-		if _, err := m.db.InsertEvent(evt); err != nil {
+		if err := m.insertEvent(evt); err != nil {
 			fmt.Fprintf(os.Stderr, "session %d: failed to insert structured event: %v\n", sessionID, err)
 		}
 	}
 }
 
+// processServiceChecks records the services the agent reported checking as
+// health checks, so a later session's result shows whether an earlier
+// remediation held.
+func (m *Manager) processServiceChecks(sessionID int64, checks []ServiceCheck) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, sc := range checks {
+		svc, ok := m.canonicalService(sessionID, sc.Name)
+		if !ok {
+			continue
+		}
+		sid := sessionID
+		hc := &db.HealthCheck{
+			SessionID: &sid,
+			Service:   svc,
+			CheckType: "service",
+			Status:    healthStatus(sc.Status),
+			CheckedAt: now,
+		}
+		if sc.Detail != "" && hc.Status != "healthy" {
+			detail := sc.Detail
+			hc.ErrorDetail = &detail
+		}
+		if _, err := m.db.InsertHealthCheck(hc); err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: failed to record service check: %v\n", sessionID, err)
+		}
+	}
+}
+
+// healthStatus maps an agent-reported service status onto the health check
+// statuses: healthy, degraded, or down.
+func healthStatus(status string) string {
+	switch s := strings.ToLower(strings.TrimSpace(status)); s {
+	case "healthy", "degraded":
+		return s
+	default:
+		return "down"
+	}
+}
+
 // processStructuredMemories inserts memories from structured output into the database.
 // The key field is parsed as "service:category" or plain "category".
 // Governing: ADR-0030, SPEC-0031 REQ-7
@@ -767,6 +1427,9 @@ type AgentEscalation struct {
 	Reason       string   `json:"reason,omitempty"`
 	Context      string   `json:"context,omitempty"`
 	FailedChecks []string `json:"failed_checks,omitempty"`
+	// RecommendedTier asks for a tier above the next one, e.g. Tier 3
+	// straight from Tier 1 for a known issue (0 means the next tier).
+	RecommendedTier int `json:"recommended_tier,omitempty"`
 }
 
 // ServiceCheck represents a service status observation in the structured output.
@@ -782,26 +1445,34 @@ type streamEvent struct {
 	Type    string `json:"type"`
 	Subtype string `json:"subtype,omitempty"`
 	Message struct {
+		ID      string         `json:"id,omitempty"`
 		Content []contentBlock `json:"content"`
+		Usage   *tokenUsage    `json:"usage,omitempty"`
 	} `json:"message,omitempty"`
+	// Fields from the "system" init event.
+	Model   string `json:"model,omitempty"`
+	Version string `json:"claude_code_version,omitempty"`
 	// Governing: SPEC-0011 REQ "Result Event Metadata Extraction"
 	// Fields from the "result" event.
-	Result       string  `json:"result,omitempty"`
-	TotalCostUSD float64 `json:"total_cost_usd,omitempty"`
-	NumTurns     int     `json:"num_turns,omitempty"`
-	DurationMs   int64   `json:"duration_ms,omitempty"`
-	IsError      bool    `json:"is_error,omitempty"`
+	Result       string      `json:"result,omitempty"`
+	TotalCostUSD float64     `json:"total_cost_usd,omitempty"`
+	NumTurns     int         `json:"num_turns,omitempty"`
+	DurationMs   int64       `json:"duration_ms,omitempty"`
+	IsError      bool        `json:"is_error,omitempty"`
+	Usage        *tokenUsage `json:"usage,omitempty"`
 	// Governing: ADR-0030, SPEC-0031 REQ-4 — structured output from --json-schema
 	StructuredOutput json.RawMessage `json:"structured_output,omitempty"`
 }
 
 type contentBlock struct {
-	Type       string          `json:"type"`
-	Text       string          `json:"text,omitempty"`
-	Name       string          `json:"name,omitempty"`
-	Input      json.RawMessage `json:"input,omitempty"`
-	Content    json.RawMessage `json:"content,omitempty"`
-	ToolUseID  string          `json:"tool_use_id,omitempty"`
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Thinking  string          `json:"thinking,omitempty"`
 }
 
 // FormatStreamEvent parses a raw NDJSON line and returns a human-readable
@@ -833,6 +1504,12 @@ func FormatStreamEvent(raw string) string {
 			case "tool_use":
 				input := truncateJSON(string(block.Input), 200)
 				parts = append(parts, fmt.Sprintf("[tool] %s: %s", block.Name, input))
+			case "thinking":
+				if text := strings.TrimSpace(stripANSI(block.Thinking)); text != "" {
+					parts = append(parts, "[thinking] "+truncateString(text, 200))
+				}
+			case "redacted_thinking":
+				parts = append(parts, "[thinking] (redacted)")
 			}
 		}
 		return strings.Join(parts, "\n")
@@ -864,6 +1541,51 @@ func FormatStreamEvent(raw string) string {
 	}
 }
 
+// stripThinking removes thinking and redacted_thinking blocks from an
+// assistant event, returning raw unchanged for anything else.
+func stripThinking(raw string) string {
+	if !strings.Contains(raw, "thinking") {
+		return raw
+	}
+	var evt map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &evt); err != nil {
+		return raw
+	}
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(evt["message"], &message); err != nil {
+		return raw
+	}
+	var content []json.RawMessage
+	if err := json.Unmarshal(message["content"], &content); err != nil {
+		return raw
+	}
+	kept := content[:0:0]
+	for _, c := range content {
+		var block struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(c, &block) == nil && (block.Type == "thinking" || block.Type == "redacted_thinking") {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if len(kept) == len(content) {
+		return raw
+	}
+	var err error
+	if message["content"], err = json.Marshal(kept); err != nil {
+		return raw
+	}
+	if evt["message"], err = json.Marshal(message); err != nil {
+		return raw
+	}
+	out, err := json.Marshal(evt)
+	if err != nil {
+		return raw
+	}
+	return string(out)
+}
+
 // extractToolResultContent handles tool_result content which can be a string
 // or a JSON array of content blocks.
 func extractToolResultContent(raw json.RawMessage) string {
@@ -894,31 +1616,24 @@ func extractToolResultContent(raw json.RawMessage) string {
 
 // truncateJSON truncates a JSON string to maxLen characters, adding "..." if needed.
 func truncateJSON(s string, maxLen int) string {
-	s = strings.TrimSpace(s)
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "..."
+	return truncatePreserve(strings.TrimSpace(s), maxLen)
 }
 
 // truncateString collapses whitespace and truncates to maxLen characters.
 // Used for single-line plain-text display (stdout).
 func truncateString(s string, maxLen int) string {
 	// Collapse runs of whitespace for display.
-	s = strings.Join(strings.Fields(s), " ")
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "..."
+	return truncatePreserve(strings.Join(strings.Fields(s), " "), maxLen)
 }
 
 // truncatePreserve truncates to maxLen characters without collapsing whitespace.
-// Used for <pre> blocks where newlines matter.
+// Used for <pre> blocks where newlines matter. Characters are counted in
+// runes, and a cut never splits one (see textutil.Cut).
 func truncatePreserve(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+	if cut, ok := textutil.Cut(s, maxLen); ok {
+		return cut + "..."
 	}
-	return s[:maxLen] + "..."
+	return s
 }
 
 // stripANSI removes ANSI escape sequences from a string.
@@ -950,6 +1665,18 @@ func stripANSI(s string) string {
 // SSE delivery and browser display. Returns "" for suppressed events.
 // Governing: SPEC-0011 "Event Parsing and Formatting" — HTML variant for browser display; HTML-formatted events for SSE and log replay.
 func FormatStreamEventHTML(raw string) string {
+	return FormatStreamEventHTMLAt(raw, 0, 0)
+}
+
+// toolResultHTMLMax is how much of a tool result the activity log shows
+// before offering to load the rest.
+const toolResultHTMLMax = 2000
+
+// FormatStreamEventHTMLAt is FormatStreamEventHTML for a line read from (or
+// written to) a session's log at the given 1-based line number. Truncated
+// tool results get a control that loads the full result from that line; a
+// zero sessionID or logLine omits it.
+func FormatStreamEventHTMLAt(raw string, sessionID int64, logLine int) string {
 	var evt streamEvent
 	if err := json.Unmarshal([]byte(raw), &evt); err != nil {
 		return `<div class="term-line"><span class="term-text">` + htmlEscape(stripANSI(raw)) + `</span></div>`
@@ -973,6 +1700,10 @@ func FormatStreamEventHTML(raw string) string {
 				}
 			case "tool_use":
 				parts = append(parts, formatToolUseHTML(block))
+			case "thinking", "redacted_thinking":
+				if html := formatThinkingHTML(block); html != "" {
+					parts = append(parts, html)
+				}
 			}
 		}
 		return strings.Join(parts, "")
@@ -980,9 +1711,15 @@ func FormatStreamEventHTML(raw string) string {
 	case "user":
 		for _, block := range evt.Message.Content {
 			if block.Type == "tool_result" {
-				content := stripANSI(extractToolResultContent(block.Content))
-				truncated := truncatePreserve(content, 2000)
-				return `<div class="term-result-block"><pre class="term-result-content">` + htmlEscape(truncated) + `</pre></div>`
+				// Tool output keeps its ANSI colors in the web terminal.
+				content := extractToolResultContent(block.Content)
+				truncated := truncatePreserve(content, toolResultHTMLMax)
+				var expand string
+				if len(content) > toolResultHTMLMax && sessionID > 0 && logLine > 0 {
+					expand = fmt.Sprintf(`<button type="button" class="term-result-expand" hx-get="/sessions/%d/log/%d" hx-target="closest .term-result-block" hx-swap="outerHTML">show full result (%d bytes)</button>`,
+						sessionID, logLine, len(content))
+				}
+				return `<div class="term-result-block"><pre class="term-result-content">` + ansiToHTML(truncated) + `</pre>` + expand + `</div>`
 			}
 		}
 		return ""
@@ -1005,6 +1742,23 @@ func FormatStreamEventHTML(raw string) string {
 	}
 }
 
+// FullToolResultHTML renders the untruncated tool result in a raw stream
+// line as a collapsible block. Returns "" if the line has no tool result.
+func FullToolResultHTML(raw string) string {
+	var evt streamEvent
+	if err := json.Unmarshal([]byte(raw), &evt); err != nil || evt.Type != "user" {
+		return ""
+	}
+	for _, block := range evt.Message.Content {
+		if block.Type == "tool_result" {
+			content := extractToolResultContent(block.Content)
+			return fmt.Sprintf(`<details class="term-result-block" open><summary class="term-result-expand">full result (%d bytes)</summary><pre class="term-result-content">%s</pre></details>`,
+				len(content), ansiToHTML(content))
+		}
+	}
+	return ""
+}
+
 // formatToolUseHTML renders a tool_use block with a badge and highlighted input.
 func formatToolUseHTML(block contentBlock) string {
 	name := block.Name
@@ -1020,39 +1774,57 @@ func formatToolUseHTML(block contentBlock) string {
 	return b.String()
 }
 
-// renderAssistantTextHTML renders an assistant text block as HTML, converting
-// [EVENT:...] marker lines into styled badge elements so they are visually
-// distinct in the live activity log terminal (matching the Response card rendering).
+// formatThinkingHTML renders an extended thinking block collapsed behind a
+// <details> summary so reasoning does not crowd out the activity log.
+func formatThinkingHTML(block contentBlock) string {
+	if block.Type == "redacted_thinking" {
+		return `<div class="term-thinking-block"><span class="term-thinking-summary">thinking (redacted)</span></div>`
+	}
+	text := strings.TrimSpace(stripANSI(block.Thinking))
+	if text == "" {
+		return ""
+	}
+	return `<details class="term-thinking-block"><summary class="term-thinking-summary">thinking</summary><pre class="term-thinking-content">` +
+		htmlEscape(truncatePreserve(text, 4000)) + `</pre></details>`
+}
+
+// renderAssistantTextHTML renders an assistant text block as HTML. Prose is
+// rendered as sanitized markdown (matching the Response card), while
+// [EVENT:...] marker lines become styled badge elements so they are visually
+// distinct in the live activity log terminal.
 func renderAssistantTextHTML(text string) string {
 	var parts []string
+	var md []string
+	flush := func() {
+		if chunk := strings.TrimSpace(strings.Join(md, "\n")); chunk != "" {
+			parts = append(parts, `<div class="term-assistant term-markdown">`+renderMarkdownHTML(chunk)+`</div>`)
+		}
+		md = md[:0]
+	}
 	for _, line := range strings.Split(text, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
+		m := eventMarkerRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			md = append(md, line)
 			continue
 		}
-		if m := eventMarkerRe.FindStringSubmatch(line); m != nil {
-			level := normalizeEventLevel(m[1])
-			service := m[2]
-			msg := strings.TrimSpace(m[3])
-			cls := "level-info"
-			switch level {
-			case "warning":
-				cls = "level-warning"
-			case "critical":
-				cls = "level-critical"
-			}
-			badge := `<span class="badge-pill ` + cls + `">` + level + `</span>`
-			if service != "" {
-				badge += ` <span class="text-xs font-mono text-muted">` + htmlEscape(service) + `</span>`
-			}
-			parts = append(parts, `<div class="term-event-block">`+badge+` `+htmlEscape(msg)+`</div>`)
-		} else {
-			parts = append(parts, `<div class="term-line term-assistant">`+htmlEscape(line)+`</div>`)
+		flush()
+		level := normalizeEventLevel(m[1])
+		service := m[2]
+		msg := strings.TrimSpace(m[3])
+		cls := "level-info"
+		switch level {
+		case "warning":
+			cls = "level-warning"
+		case "critical":
+			cls = "level-critical"
 		}
+		badge := `<span class="badge-pill ` + cls + `">` + level + `</span>`
+		if service != "" {
+			badge += ` <span class="text-xs font-mono text-muted">` + htmlEscape(service) + `</span>`
+		}
+		parts = append(parts, `<div class="term-event-block">`+badge+` `+htmlEscape(msg)+`</div>`)
 	}
-	if len(parts) == 0 {
-		return ""
-	}
+	flush()
 	return strings.Join(parts, "")
 }
 
@@ -1263,6 +2035,43 @@ func parseCooldownMarkers(text string) []parsedCooldown {
 	return cooldowns
 }
 
+// --- Service names ---
+
+// canonicalService returns the canonical form of an agent-reported service
+// name and whether it is valid, logging invalid names.
+func (m *Manager) canonicalService(sessionID int64, name string) (string, bool) {
+	canonical, ok := m.services.Normalize(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "session %d: invalid service name %q\n", sessionID, name)
+	}
+	return canonical, ok
+}
+
+// serviceName canonicalises an optional service name. Invalid names are
+// dropped, so the record is kept without a service.
+func (m *Manager) serviceName(sessionID int64, svc *string) *string {
+	if svc == nil {
+		return nil
+	}
+	canonical, ok := m.canonicalService(sessionID, *svc)
+	if !ok {
+		return nil
+	}
+	return &canonical
+}
+
+// serviceNames canonicalises a list of service names, dropping invalid names
+// and duplicates.
+func (m *Manager) serviceNames(sessionID int64, names []string) []string {
+	var out []string
+	for _, name := range names {
+		if canonical, ok := m.canonicalService(sessionID, name); ok && !slices.Contains(out, canonical) {
+			out = append(out, canonical)
+		}
+	}
+	return out
+}
+
 // WrapLogLine wraps formatted HTML content with a line number, timestamp, and anchor.
 func WrapLogLine(num int, ts string, content string) string {
 	return fmt.Sprintf(`<div class="log-line" id="L%d"><a class="line-num" href="#L%d">%d</a><span class="line-ts">%s</span><div class="line-content">%s</div></div>`,
@@ -1324,12 +2133,16 @@ func (m *Manager) emitEscalationEvent(sessionID int64, message string) {
 }
 
 // emitEscalationEventLevel emits an event at the given level linked to the given session ID.
+// A sessionID of 0, for a chain held before its first session, links to none.
 // Governing: SPEC-0016 REQ "Handoff Validation Events"
 func (m *Manager) emitEscalationEventLevel(sessionID int64, level, message string) {
-	sid := sessionID
+	var sid *int64
+	if sessionID != 0 {
+		sid = &sessionID
+	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	if _, err := m.db.InsertEvent(&db.Event{
-		SessionID: &sid,
+	if err := m.insertEvent(&db.Event{
+		SessionID: sid,
 		Level:     level,
 		Service:   nil,
 		Message:   message,
@@ -1349,8 +2162,8 @@ func (m *Manager) buildEnvContext() string {
 	ctx += fmt.Sprintf(" CLAUDEOPS_TIER2_MODEL=%s", m.cfg.Tier2Model)
 	ctx += fmt.Sprintf(" CLAUDEOPS_TIER3_MODEL=%s", m.cfg.Tier3Model)
 
-	if m.cfg.AppriseURLs != "" {
-		ctx += fmt.Sprintf(" CLAUDEOPS_APPRISE_URLS=%s", m.cfg.AppriseURLs)
+	if urls := m.agentAppriseURLs(); urls != "" {
+		ctx += fmt.Sprintf(" CLAUDEOPS_APPRISE_URLS=%s", urls)
 	}
 
 	if m.cfg.BrowserAllowedOrigins != "" {
@@ -1360,11 +2173,66 @@ func (m *Manager) buildEnvContext() string {
 	return ctx
 }
 
+// buildLogContext pre-fetches recent error logs for the services affected by
+// an escalation and returns them as a markdown section for the next tier's
+// prompt. Returns "" when no log source is configured or no services are named.
+func (m *Manager) buildLogContext(ctx context.Context, services []string) string {
+	if m.logs == nil || len(services) == 0 {
+		return ""
+	}
+	return m.logs.Format(m.logs.Fetch(ctx, services))
+}
+
+// buildHypervisorContext fetches the Proxmox guest inventory, records each
+// guest's state as a health check (so VMs and containers appear in the service
+// catalog alongside agent-checked services), and returns a markdown summary
+// for the Tier 1 system prompt. Returns "" when the integration is disabled or
+// the hypervisor is unreachable — a missing inventory never blocks a session.
+func (m *Manager) buildHypervisorContext(ctx context.Context, sessionID int64) string {
+	if m.proxmox == nil {
+		return ""
+	}
+	guests, err := m.proxmox.Inventory(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: proxmox inventory: %v\n", sessionID, err)
+		return ""
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, g := range guests {
+		sid := sessionID
+		hc := &db.HealthCheck{
+			SessionID: &sid,
+			Service:   g.ServiceName(),
+			CheckType: "hypervisor",
+			Status:    g.HealthStatus(),
+			CheckedAt: now,
+		}
+		if g.Status != "running" {
+			detail := fmt.Sprintf("%s %d on %s is %s", g.Type, g.VMID, g.Node, g.Status)
+			hc.ErrorDetail = &detail
+		}
+		if _, err := m.db.InsertHealthCheck(hc); err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: record guest %d health: %v\n", sessionID, g.VMID, err)
+		}
+	}
+	return proxmox.FormatInventory(guests)
+}
+
 // Governing: SPEC-0015 "Confidence Scoring", "Memory Reinforcement", "Memory Contradiction" — default 0.7, +0.1 reinforce, -0.1 contradict
 // upsertMemory handles the insert-or-update logic for a parsed memory marker.
 // If a similar memory exists (same service + category), it either reinforces
 // (increases confidence) or contradicts (decreases old, inserts new).
 func (m *Manager) upsertMemory(sessionID int64, tier int, pm parsedMemory) {
+	category, ok := m.memoryCategory(sessionID, pm)
+	if !ok {
+		return
+	}
+	pm.Category = category
+	pm.Service = m.serviceName(sessionID, pm.Service)
+	if m.suppressedByTombstone(pm) {
+		return
+	}
+
 	existing, err := m.db.FindSimilarMemory(pm.Service, pm.Category)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "find similar memory: %v\n", err)
@@ -1372,27 +2240,33 @@ func (m *Manager) upsertMemory(sessionID int64, tier int, pm parsedMemory) {
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339)
+	scoring := NewMemoryScoring(m.cfg)
+	score := scoring.For(pm.Category)
 
 	if existing != nil {
 		if existing.Observation == pm.Observation {
 			// Same observation — reinforce confidence.
-			newConf := existing.Confidence + 0.1
+			newConf := existing.Confidence + score.Reinforce
 			if newConf > 1.0 {
 				newConf = 1.0
 			}
-			if err := m.db.UpdateMemory(existing.ID, existing.Observation, newConf, existing.Active); err != nil {
+			if err := m.db.ReinforceMemory(existing.ID, newConf); err != nil {
 				fmt.Fprintf(os.Stderr, "reinforce memory %d: %v\n", existing.ID, err)
+				return
 			}
+			m.recordMemoryRevision(existing.ID, sessionID, db.MemoryReinforced, &existing.Confidence, newConf, score)
 			return
 		}
 		// Different observation — decrease old confidence.
-		newConf := existing.Confidence - 0.1
+		newConf := existing.Confidence - score.Contradict
 		active := existing.Active
-		if newConf < 0.3 {
+		if newConf < scoring.DeactivateBelow {
 			active = false
 		}
 		if err := m.db.UpdateMemory(existing.ID, existing.Observation, newConf, active); err != nil {
 			fmt.Fprintf(os.Stderr, "decay contradicted memory %d: %v\n", existing.ID, err)
+		} else {
+			m.recordMemoryRevision(existing.ID, sessionID, db.MemoryContradicted, &existing.Confidence, newConf, score)
 		}
 	}
 
@@ -1401,20 +2275,111 @@ func (m *Manager) upsertMemory(sessionID int64, tier int, pm parsedMemory) {
 		Service:     pm.Service,
 		Category:    pm.Category,
 		Observation: pm.Observation,
-		Confidence:  0.7,
+		Confidence:  scoring.InitialConfidence,
 		Active:      true,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 		SessionID:   &sessionID,
 		Tier:        tier,
+		// Agent-created memories await operator review.
+		ReviewStatus: db.MemoryUnverified,
 	}
-	if _, err := m.db.InsertMemory(mem); err != nil {
+	id, err := m.db.InsertMemory(mem)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "insert memory: %v\n", err)
+		return
 	}
+	mem.ID = id
+	m.recordMemoryRevision(id, sessionID, db.MemoryCreated, nil, mem.Confidence, score)
+	m.runHooks("OnMemory", func(h Hooks) { h.OnMemory(mem) })
 }
 
-// insertCooldown records a parsed cooldown marker as a CooldownAction in the database.
+// recordMemoryRevision records a confidence change the scoring model made
+// to a memory, with the parameters it used.
+func (m *Manager) recordMemoryRevision(memoryID, sessionID int64, change string, before *float64, after float64, score db.MemoryScore) {
+	if err := m.db.InsertMemoryRevision(&db.MemoryRevision{
+		MemoryID:         memoryID,
+		SessionID:        &sessionID,
+		Change:           change,
+		ConfidenceBefore: before,
+		ConfidenceAfter:  after,
+		Score:            score,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "record memory revision: %v\n", err)
+	}
+}
+
+// suppressedByTombstone reports whether pm matches a memory the operator
+// recently deleted or rejected (same service, near-identical observation).
+// Matches are counted on the tombstone so the rejected-memories view shows
+// how often the agent tries to re-learn it.
+func (m *Manager) suppressedByTombstone(pm parsedMemory) bool {
+	days := m.cfg.MemoryTombstoneDays
+	if days <= 0 {
+		return false
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days).Format(time.RFC3339)
+	tombstones, err := m.db.FindTombstones(pm.Service, since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "find memory tombstones: %v\n", err)
+		return false
+	}
+	for _, t := range tombstones {
+		if !similarObservation(t.Observation, pm.Observation) {
+			continue
+		}
+		if err := m.db.IncrementMemorySuppressed(t.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "record suppressed memory %d: %v\n", t.ID, err)
+		}
+		return true
+	}
+	return false
+}
+
+// similarObservation reports whether two memory observations are
+// near-identical: equal after normalizing case, punctuation, and whitespace,
+// or sharing at least 80% of their distinct words.
+func similarObservation(a, b string) bool {
+	wa, wb := observationWords(a), observationWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return false
+	}
+	if strings.Join(wa, " ") == strings.Join(wb, " ") {
+		return true
+	}
+	set := make(map[string]bool, len(wa))
+	for _, w := range wa {
+		set[w] = true
+	}
+	union := len(set)
+	shared := 0
+	seen := make(map[string]bool, len(wb))
+	for _, w := range wb {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		if set[w] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared)/float64(union) >= 0.8
+}
+
+// observationWords lowercases s and splits it into alphanumeric words.
+func observationWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// insertCooldown records a parsed cooldown marker as a CooldownAction in the
+// database, after evaluating the policy for it.
 func (m *Manager) insertCooldown(sessionID int64, tier int, pc parsedCooldown) {
+	pc.Service, _ = m.canonicalService(sessionID, pc.Service)
+	m.checkCooldownPolicy(sessionID, tier, pc)
 	now := time.Now().UTC().Format(time.RFC3339)
 	var errMsg *string
 	if !pc.Success {
@@ -1430,23 +2395,43 @@ func (m *Manager) insertCooldown(sessionID int64, tier int, pc parsedCooldown) {
 		Error:      errMsg,
 		SessionID:  &sessionID,
 	}
-	if _, err := m.db.InsertCooldownAction(a); err != nil {
+	id, err := m.db.InsertCooldownAction(a)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "insert cooldown action: %v\n", err)
+		return
 	}
+	a.ID = id
+	m.runHooks("OnCooldown", func(h Hooks) { h.OnCooldown(a) })
 }
 
+// memoryFetchLimit bounds the memories loaded per service for the memory
+// context when no per-service cap applies; the token budget trims them
+// further.
+const memoryFetchLimit = 200
+
 // Governing: SPEC-0015 REQ "Token Budget Enforcement" (2000-token default, chars/4 estimation)
 // Governing: SPEC-0015 REQ "Memory Context Format" (grouped by service, category tag, confidence score)
 // buildMemoryContext queries active memories and formats them as a structured
 // markdown block for injection into the system prompt. It respects the
 // configured MemoryBudget (estimated as characters / 4).
-func (m *Manager) buildMemoryContext() string {
+//
+// When services is non-empty, only memories for those services (plus general,
+// service-less memories) are injected, affected services first. When
+// perService is positive, at most that many memories are injected per
+// service, highest ranked first.
+func (m *Manager) buildMemoryContext(services []string, perService int) string {
 	budget := m.cfg.MemoryBudget
 	if budget <= 0 {
 		return ""
 	}
 
-	memories, err := m.db.GetActiveMemories(200)
+	limit := perService
+	if limit <= 0 {
+		limit = memoryFetchLimit
+	}
+	// The database scopes and limits per service, so a service whose
+	// memories rank low overall still gets its best ones.
+	memories, err := m.db.GetActiveMemoriesByService(services, limit, m.cfg.MemoryVerifiedOnly)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "get active memories: %v\n", err)
 		return ""
@@ -1460,6 +2445,7 @@ func (m *Manager) buildMemoryContext() string {
 		Category    string
 		Observation string
 		Confidence  float64
+		Unverified  bool
 	}
 	groups := make(map[string][]memEntry)
 	var order []string
@@ -1475,6 +2461,13 @@ func (m *Manager) buildMemoryContext() string {
 			Category:    mem.Category,
 			Observation: mem.Observation,
 			Confidence:  mem.Confidence,
+			Unverified:  mem.ReviewStatus == db.MemoryUnverified,
+		})
+	}
+	if len(services) > 0 {
+		// Affected services take the budget before general memories.
+		sort.SliceStable(order, func(i, j int) bool {
+			return order[i] != "general" && order[j] == "general"
 		})
 	}
 
@@ -1486,6 +2479,9 @@ func (m *Manager) buildMemoryContext() string {
 		entries := groups[svc]
 		for _, e := range entries {
 			line := fmt.Sprintf("- [%s] %s (confidence: %.1f)\n", e.Category, e.Observation, e.Confidence)
+			if e.Unverified {
+				line = fmt.Sprintf("- [%s] %s (confidence: %.1f, unverified)\n", e.Category, e.Observation, e.Confidence)
+			}
 			header := ""
 			if svc != lastSvc {
 				header = fmt.Sprintf("\n### %s\n", svc)
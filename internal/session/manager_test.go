@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,6 +16,9 @@ import (
 	"github.com/joestump/claude-ops/internal/config"
 	"github.com/joestump/claude-ops/internal/db"
 	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/logsource"
+	"github.com/joestump/claude-ops/internal/proxmox"
+	"github.com/joestump/claude-ops/internal/servicename"
 )
 
 func testConfig(t *testing.T) *config.Config {
@@ -81,6 +86,20 @@ func TestBuildEnvContextWithApprise(t *testing.T) {
 	if !strings.Contains(ctx, "CLAUDEOPS_APPRISE_URLS=ntfy://example.com/test") {
 		t.Errorf("envContext should contain APPRISE_URLS; got %q", ctx)
 	}
+	if env := m.agentEnv(1); len(env) != 0 {
+		t.Errorf("agent env without a digest = %v", env)
+	}
+
+	// With a digest, the agent notifies the supervisor, which coalesces.
+	m.cfg.NotifyDigestWindow = 60
+	m.cfg.AgentPort = 8081
+	const local = "CLAUDEOPS_APPRISE_URLS=json://127.0.0.1:8081/api/v1/notify"
+	if ctx := m.buildEnvContext(); !strings.Contains(ctx, local) || strings.Contains(ctx, "ntfy://") {
+		t.Errorf("envContext with a digest = %q", ctx)
+	}
+	if env := m.agentEnv(1); len(env) != 1 || env[0] != local {
+		t.Errorf("agent env with a digest = %v", env)
+	}
 }
 
 func TestPreSessionHookCalled(t *testing.T) {
@@ -118,14 +137,14 @@ func TestLogFileCreated(t *testing.T) {
 
 	found := false
 	for _, e := range entries {
-		if strings.HasPrefix(e.Name(), "run-") && strings.HasSuffix(e.Name(), ".log") {
+		if strings.HasPrefix(e.Name(), "run-") && strings.HasSuffix(e.Name(), ".log.gz") {
 			found = true
 			break
 		}
 	}
 
 	if !found {
-		t.Error("no run-*.log file created in results dir")
+		t.Error("no run-*.log.gz file created in results dir")
 	}
 }
 
@@ -270,10 +289,15 @@ func TestRedactionInStreamPipeline(t *testing.T) {
 	}
 
 	for _, e := range entries {
-		if !strings.HasPrefix(e.Name(), "run-") || !strings.HasSuffix(e.Name(), ".log") {
+		if !strings.HasPrefix(e.Name(), "run-") || !strings.HasSuffix(e.Name(), ".log.gz") {
 			continue
 		}
-		data, err := os.ReadFile(filepath.Join(cfg.ResultsDir, e.Name()))
+		f, err := OpenLog(filepath.Join(cfg.ResultsDir, e.Name()))
+		if err != nil {
+			t.Fatalf("open log: %v", err)
+		}
+		data, err := io.ReadAll(f)
+		_ = f.Close()
 		if err != nil {
 			t.Fatalf("read log: %v", err)
 		}
@@ -331,7 +355,7 @@ type orphanPipeRunner struct {
 	events []string
 }
 
-func (o *orphanPipeRunner) Start(_ context.Context, _ string, _ string, _ string, _ string, _ string, _ string) (io.ReadCloser, func() error, error) {
+func (o *orphanPipeRunner) Start(_ context.Context, _ string, _ string, _ string, _ string, _ string, _ string, _ string, _ []string) (io.ReadCloser, func() error, error) {
 	pr, pw, err := os.Pipe()
 	if err != nil {
 		return nil, nil, err
@@ -414,7 +438,7 @@ func TestSessionCompletesWhenPipeOrphaned(t *testing.T) {
 	entries, _ := os.ReadDir(cfg.ResultsDir)
 	found := false
 	for _, e := range entries {
-		if strings.HasPrefix(e.Name(), "run-") && strings.HasSuffix(e.Name(), ".log") {
+		if strings.HasPrefix(e.Name(), "run-") && strings.HasSuffix(e.Name(), ".log.gz") {
 			found = true
 		}
 	}
@@ -437,7 +461,7 @@ type pipeRunner struct {
 	waitCalled bool
 }
 
-func (p *pipeRunner) Start(_ context.Context, _ string, _ string, _ string, _ string, _ string, _ string) (io.ReadCloser, func() error, error) {
+func (p *pipeRunner) Start(_ context.Context, _ string, _ string, _ string, _ string, _ string, _ string, _ string, _ []string) (io.ReadCloser, func() error, error) {
 	pr, pw, err := os.Pipe()
 	if err != nil {
 		return nil, nil, err
@@ -662,7 +686,7 @@ func TestProcessStructuredEvents(t *testing.T) {
 	m.processStructuredEvents(sid, events)
 
 	// Verify events were inserted by listing all events.
-	dbEvents, err := database.ListEvents(10, 0, nil, nil)
+	dbEvents, err := database.ListEvents(10, 0, db.EventFilter{})
 	if err != nil {
 		t.Fatalf("ListEvents: %v", err)
 	}
@@ -730,7 +754,7 @@ func TestProcessStructuredEvents_Empty(t *testing.T) {
 
 	m.processStructuredEvents(sid, []AgentEvent{})
 
-	dbEvents, err := database.ListEvents(10, 0, nil, nil)
+	dbEvents, err := database.ListEvents(10, 0, db.EventFilter{})
 	if err != nil {
 		t.Fatalf("ListEvents: %v", err)
 	}
@@ -757,7 +781,7 @@ func TestProcessStructuredEvents_NilService(t *testing.T) {
 		{Level: "info", Message: "General observation"},
 	})
 
-	dbEvents, err := database.ListEvents(10, 0, nil, nil)
+	dbEvents, err := database.ListEvents(10, 0, db.EventFilter{})
 	if err != nil {
 		t.Fatalf("ListEvents: %v", err)
 	}
@@ -789,7 +813,7 @@ func TestProcessStructuredEvents_LevelNormalization(t *testing.T) {
 		{Level: "ok", Message: "all good"},
 	})
 
-	dbEvents, err := database.ListEvents(10, 0, nil, nil)
+	dbEvents, err := database.ListEvents(10, 0, db.EventFilter{})
 	if err != nil {
 		t.Fatalf("ListEvents: %v", err)
 	}
@@ -878,7 +902,7 @@ func TestProcessStructuredMemories_Empty(t *testing.T) {
 	m.processStructuredMemories(sid, 1, []AgentMemory{})
 
 	// No memories should exist in the DB.
-	mems, err := database.ListMemories(nil, nil, 10, 0)
+	mems, err := database.ListMemories(nil, nil, nil, 10, 0)
 	if err != nil {
 		t.Fatalf("ListMemories: %v", err)
 	}
@@ -890,6 +914,39 @@ func TestProcessStructuredMemories_Empty(t *testing.T) {
 // TestBuildStructuredEscalationContext verifies that an AgentResponse is formatted
 // into a readable markdown section for injection into the next tier's system prompt.
 // Governing: ADR-0030, SPEC-0031 REQ-3
+func TestProcessServiceChecks(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	sid, err := database.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/dev/null", Status: "running",
+		StartedAt: "2026-02-15T10:00:00Z", Trigger: "scheduled",
+	})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+
+	m.processServiceChecks(sid, []ServiceCheck{
+		{Name: "Jellyfin", Status: "healthy", Detail: "200 OK"},
+		{Name: "caddy", Status: "unhealthy", Detail: "connection refused"},
+		{Name: "not/a service", Status: "healthy"},
+	})
+
+	statuses, err := database.ListServiceStatuses()
+	if err != nil {
+		t.Fatalf("ListServiceStatuses: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Service != "caddy" || statuses[0].Status != "down" ||
+		statuses[1].Service != "jellyfin" || statuses[1].Status != "healthy" {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+	checks, err := database.QueryHealthChecks("caddy", "2000-01-01T00:00:00Z", "2100-01-01T00:00:00Z", 10)
+	if err != nil || len(checks) != 1 {
+		t.Fatalf("expected one caddy check, got %+v (err %v)", checks, err)
+	}
+	if checks[0].CheckType != "service" || checks[0].ErrorDetail == nil || *checks[0].ErrorDetail != "connection refused" {
+		t.Errorf("unexpected caddy check %+v", checks[0])
+	}
+}
+
 func TestBuildStructuredEscalationContext(t *testing.T) {
 	resp := &AgentResponse{
 		Summary: "Jellyfin is down after Docker restart",
@@ -1066,3 +1123,184 @@ func TestResultEventCapturedUnderContention(t *testing.T) {
 		})
 	}
 }
+
+// --- Hypervisor context ---
+
+func TestBuildHypervisorContext_Disabled(t *testing.T) {
+	m, _ := testManagerWithDB(t)
+	if got := m.buildHypervisorContext(context.Background(), 1); got != "" {
+		t.Errorf("expected empty context when proxmox is not configured, got %q", got)
+	}
+}
+
+func TestBuildHypervisorContext_RecordsHealthChecks(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[
+			{"vmid":100,"name":"docker-host","node":"pve1","type":"qemu","status":"running","maxmem":1024,"mem":256},
+			{"vmid":101,"name":"nas","node":"pve1","type":"qemu","status":"stopped"}
+		]}`))
+	}))
+	defer srv.Close()
+	m.proxmox = proxmox.New(srv.URL, "ops@pve!claude", "secret")
+
+	sessionID, err := database.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/dev/null", Status: "running",
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+
+	got := m.buildHypervisorContext(context.Background(), sessionID)
+	if !strings.Contains(got, "## Hypervisor Inventory (Proxmox, 2 guests)") {
+		t.Errorf("expected inventory header, got:\n%s", got)
+	}
+
+	checks, err := database.QueryHealthChecks("nas", "", "9999", 10)
+	if err != nil {
+		t.Fatalf("QueryHealthChecks: %v", err)
+	}
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 health check for nas, got %d", len(checks))
+	}
+	if checks[0].CheckType != "hypervisor" || checks[0].Status != "down" {
+		t.Errorf("unexpected health check %+v", checks[0])
+	}
+}
+
+// --- Log context ---
+
+type stubLogSource struct{}
+
+func (stubLogSource) Name() string { return "stub" }
+
+func (stubLogSource) Fetch(_ context.Context, service string, _ time.Time, _ int) ([]string, error) {
+	return []string{service + ": connection refused"}, nil
+}
+
+func TestBuildLogContext(t *testing.T) {
+	m, _ := testManagerWithDB(t)
+	if got := m.buildLogContext(context.Background(), []string{"nginx"}); got != "" {
+		t.Errorf("expected empty context when no log source is configured, got %q", got)
+	}
+
+	m.logs = logsource.NewFetcher(stubLogSource{}, time.Minute, 10)
+	if got := m.buildLogContext(context.Background(), nil); got != "" {
+		t.Errorf("expected empty context with no services, got %q", got)
+	}
+	got := m.buildLogContext(context.Background(), []string{"nginx"})
+	if !strings.Contains(got, "## Recent Error Logs") || !strings.Contains(got, "nginx: connection refused") {
+		t.Errorf("unexpected log context:\n%s", got)
+	}
+}
+
+func TestTriggerPulse(t *testing.T) {
+	m, _ := testManager(t)
+
+	if !m.TriggerPulse([]string{"jellyfin"}, "## Tier 0 Pulse Alert") {
+		t.Fatal("expected pulse trigger to be queued")
+	}
+	if m.TriggerPulse([]string{"caddy"}, "") {
+		t.Fatal("expected second pulse trigger to be rejected while one is queued")
+	}
+	req := <-m.pulseCh
+	if len(req.services) != 1 || req.services[0] != "jellyfin" || req.detail != "## Tier 0 Pulse Alert" {
+		t.Errorf("unexpected pulse request %+v", req)
+	}
+
+	m.mu.Lock()
+	m.running = true
+	m.mu.Unlock()
+	if m.TriggerPulse([]string{"jellyfin"}, "") {
+		t.Fatal("expected pulse trigger to be rejected while a session is running")
+	}
+}
+
+// TestServiceNamesNormalized verifies that agent-reported service names are
+// case-folded and mapped through the configured aliases before they are
+// recorded, and that invalid names are dropped.
+func TestServiceNamesNormalized(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	aliases, err := servicename.ParseAliases("jellyfin-container=jellyfin,jf=jellyfin")
+	if err != nil {
+		t.Fatalf("ParseAliases: %v", err)
+	}
+	m.services = servicename.New(aliases, database)
+
+	sid, err := database.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/dev/null", Status: "running",
+		StartedAt: "2026-02-15T10:00:00Z", Trigger: "scheduled",
+	})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+
+	m.processStructuredEvents(sid, []AgentEvent{
+		{Level: "warning", Service: "Jellyfin-Container", Message: "slow"},
+		{Level: "info", Service: "not/a service", Message: "odd name"},
+	})
+	jf := "JF"
+	m.upsertMemory(sid, 1, parsedMemory{Category: "timing", Service: &jf, Observation: "Takes 60s to start"})
+	m.insertCooldown(sid, 2, parsedCooldown{ActionType: "restart", Service: "Jellyfin", Success: true, Message: "restarted"})
+
+	names, err := database.ListServiceNames()
+	if err != nil {
+		t.Fatalf("ListServiceNames: %v", err)
+	}
+	if len(names) != 1 || names[0] != (db.ServiceNameCount{Service: "jellyfin", Rows: 3}) {
+		t.Errorf("service names = %+v, want only jellyfin with 3 records", names)
+	}
+	events, err := database.ListEvents(10, 0, db.EventFilter{})
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	for _, e := range events {
+		if e.Message == "odd name" && e.Service != nil {
+			t.Errorf("invalid service name kept: %q", *e.Service)
+		}
+	}
+}
+
+// TestSessionPublishesTypedEvents verifies that a run publishes its raw
+// NDJSON, formatted HTML, and lifecycle events on the one event bus.
+func TestSessionPublishesTypedEvents(t *testing.T) {
+	m, _ := testManager(t)
+	m.runner = &pipeRunner{
+		events: []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"All services healthy."}]}}`,
+			`{"type":"result","result":"Done.","is_error":false,"total_cost_usd":0.01,"num_turns":1,"duration_ms":1000}`,
+		},
+		resultIdx: 2,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := m.runOnce(ctx, "", "scheduled"); err != nil {
+		t.Fatalf("runOnce: %v", err)
+	}
+	sessions, err := m.db.ListSessions(1, 0)
+	if err != nil || len(sessions) == 0 {
+		t.Fatalf("ListSessions: %v", err)
+	}
+
+	count := make(map[hub.Kind]int)
+	var lifecycle []string
+	ch, _ := m.hub.Subscribe(int(sessions[0].ID), hub.All)
+	for e := range ch {
+		count[e.Kind]++
+		if e.Kind == hub.Lifecycle {
+			lifecycle = append(lifecycle, e.Data)
+		}
+	}
+	if count[hub.Raw] != 3 {
+		t.Errorf("expected 3 raw events, got %d", count[hub.Raw])
+	}
+	if count[hub.Formatted] == 0 {
+		t.Error("expected formatted events")
+	}
+	if strings.Join(lifecycle, ",") != "running,completed" {
+		t.Errorf("expected lifecycle running,completed, got %v", lifecycle)
+	}
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// streamMarkdown renders assistant text in the live activity log. Raw HTML
+// in the model's output is shown escaped rather than dropped, so the terminal
+// never injects markup but still displays what the model wrote.
+var streamMarkdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(
+		renderer.WithNodeRenderers(util.Prioritized(escapedHTMLRenderer{}, 100)),
+	),
+)
+
+// renderMarkdownHTML converts assistant markdown (tables, code fences, lists)
+// to sanitized HTML, falling back to escaped text.
+func renderMarkdownHTML(md string) string {
+	var buf bytes.Buffer
+	if err := streamMarkdown.Convert([]byte(md), &buf); err != nil {
+		return htmlEscape(md)
+	}
+	return buf.String()
+}
+
+// escapedHTMLRenderer overrides goldmark's raw HTML rendering to escape it.
+type escapedHTMLRenderer struct{}
+
+func (escapedHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHTMLBlock, renderEscapedHTMLBlock)
+	reg.Register(ast.KindRawHTML, renderEscapedRawHTML)
+}
+
+func renderEscapedHTMLBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.HTMLBlock)
+	if entering {
+		_, _ = w.WriteString("<p>")
+		for i := 0; i < n.Lines().Len(); i++ {
+			line := n.Lines().At(i)
+			_, _ = w.WriteString(htmlEscape(string(line.Value(source))))
+		}
+	} else {
+		if n.HasClosure() {
+			_, _ = w.WriteString(htmlEscape(string(n.ClosureLine.Value(source))))
+		}
+		_, _ = w.WriteString("</p>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func renderEscapedRawHTML(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n := node.(*ast.RawHTML)
+		for i := 0; i < n.Segments.Len(); i++ {
+			segment := n.Segments.At(i)
+			_, _ = w.WriteString(htmlEscape(string(segment.Value(source))))
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,95 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// markerFormats are the markers the supervisor parses from assistant text,
+// with the expressions that accept them and their format for messages.
+var markerFormats = []struct {
+	kind   string // event, memory, cooldown, or ticket
+	prefix string
+	re     *regexp.Regexp
+	format string
+}{
+	{"event", "[EVENT", eventMarkerRe, "[EVENT:level] or [EVENT:level:service] followed by a message"},
+	{"memory", "[MEMORY", memoryMarkerRe, "[MEMORY:category] or [MEMORY:category:service] followed by the observation"},
+	{"cooldown", "[COOLDOWN", cooldownMarkerRe, "[COOLDOWN:restart|redeployment:service] success|failure — message"},
+	{"ticket", "[TICKET", ticketMarkerRe, "[TICKET:github|gitea|jira:id], e.g. [TICKET:github:owner/repo#42] or [TICKET:jira:OPS-7]"},
+}
+
+// markerLikeRe finds text that looks like an attempt at a marker: an
+// opening bracket followed by EVENT, MEMORY, COOLDOWN, or TICKET in any
+// case.
+var markerLikeRe = regexp.MustCompile(`(?i)\[\s*(event|memory|cooldown|ticket)\b`)
+
+// parseWarningTextMax bounds the line stored with a parse warning.
+const parseWarningTextMax = 500
+
+// parseWarning is marker-like assistant text the marker parsers drop.
+type parseWarning struct {
+	Marker string // event, memory, cooldown, or ticket
+	Text   string
+	Reason string
+}
+
+// findMalformedMarkers returns the text in an assistant text block that
+// looks like a marker but is not in a format the marker parsers accept,
+// e.g. "[EVENT: success]" or "[COOLDOWN restart:jellyfin]". A mention
+// quoted in backticks is not an attempt at a marker and is skipped.
+func findMalformedMarkers(text string) []parseWarning {
+	var out []parseWarning
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		for _, loc := range markerLikeRe.FindAllStringSubmatchIndex(line, -1) {
+			if loc[0] > 0 && line[loc[0]-1] == '`' {
+				continue
+			}
+			kind := strings.ToLower(line[loc[2]:loc[3]])
+			for _, f := range markerFormats {
+				if f.kind != kind {
+					continue
+				}
+				if m := f.re.FindStringIndex(line[loc[0]:]); m != nil && m[0] == 0 {
+					continue
+				}
+				out = append(out, parseWarning{
+					Marker: kind,
+					Text:   truncateString(line, parseWarningTextMax),
+					Reason: "expected " + f.format,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// saveParseWarnings records the malformed markers found in a session's
+// output. With structured output, events and memories come from the
+// response schema rather than text markers, so only cooldown and ticket
+// markers, which are always read from text, are recorded.
+func (m *Manager) saveParseWarnings(sessionID int64, warnings []parseWarning, structured bool) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	seen := make(map[parseWarning]bool)
+	for _, w := range warnings {
+		if seen[w] || (structured && w.Marker != "cooldown" && w.Marker != "ticket") {
+			continue
+		}
+		seen[w] = true
+		if _, err := m.db.InsertParseWarning(&db.ParseWarning{
+			SessionID: sessionID,
+			Marker:    w.Marker,
+			Text:      w.Text,
+			Reason:    w.Reason,
+			CreatedAt: now,
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFindMalformedMarkers(t *testing.T) {
+	text := strings.Join([]string{
+		"[EVENT:info] Jellyfin is healthy",
+		"[EVENT: success] Sonarr restarted",
+		"  [COOLDOWN restart:sonarr] success — restarted",
+		"[COOLDOWN:restart:sonarr] success — restarted",
+		"[memory:Timing:jellyfin] takes 60s to start",
+		"Markers like `[EVENT:...]` are not needed with the schema.",
+		"Both [MEMORY:timing] fine and [Memory] broken",
+		"Tracked in [TICKET:github:ops/homelab#42].",
+		"[Ticket: jira OPS-7]",
+	}, "\n")
+	var got []string
+	for _, w := range findMalformedMarkers(text) {
+		got = append(got, w.Marker+" "+w.Text)
+	}
+	want := []string{
+		"event [EVENT: success] Sonarr restarted",
+		"cooldown [COOLDOWN restart:sonarr] success — restarted",
+		"memory [memory:Timing:jellyfin] takes 60s to start",
+		"memory Both [MEMORY:timing] fine and [Memory] broken",
+		"ticket [Ticket: jira OPS-7]",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findMalformedMarkers =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRunTierRecordsParseWarnings(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.runner = &pipeRunner{
+		events: []string{
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"[EVENT: success] Sonarr restarted\n[EVENT:info] Radarr healthy\n[COOLDOWN restart:sonarr] success"}]}}`,
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"[EVENT: success] Sonarr restarted"}]}}`,
+			`{"type":"result","result":"done","is_error":false}`,
+		},
+		resultIdx: 2,
+	}
+	id, _, err := m.runTier(context.Background(), 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	warnings, err := database.ListParseWarnings(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 || warnings[0].Marker != "event" || warnings[1].Marker != "cooldown" {
+		t.Fatalf("expected one event and one cooldown warning, got %+v", warnings)
+	}
+	if !strings.Contains(warnings[0].Reason, "[EVENT:level]") {
+		t.Errorf("unexpected reason %q", warnings[0].Reason)
+	}
+	counts, err := database.CountParseWarnings([]int64{id, id + 1})
+	if err != nil || counts[id] != 2 || counts[id+1] != 0 {
+		t.Errorf("CountParseWarnings = %v, %v", counts, err)
+	}
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// memoryCategories returns the configured allowed memory categories, or
+// nil when any category is allowed.
+func (m *Manager) memoryCategories() []string {
+	var out []string
+	for _, c := range strings.Split(m.cfg.MemoryCategories, ",") {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// memoryCategory normalizes a memory's category against the allowed list.
+// In strict mode, a memory whose category matches none is recorded as a
+// parse warning and ok is false; otherwise its category is kept as is.
+func (m *Manager) memoryCategory(sessionID int64, pm parsedMemory) (category string, ok bool) {
+	allowed := m.memoryCategories()
+	if len(allowed) == 0 {
+		return pm.Category, true
+	}
+	if c, found := normalizeMemoryCategory(pm.Category, allowed); found {
+		return c, true
+	}
+	if !m.cfg.MemoryStrict {
+		return pm.Category, true
+	}
+	marker := "[MEMORY:" + pm.Category
+	if pm.Service != nil {
+		marker += ":" + *pm.Service
+	}
+	m.saveParseWarnings(sessionID, []parseWarning{{
+		Marker: "memory",
+		Text:   truncateString(marker+"] "+pm.Observation, parseWarningTextMax),
+		Reason: fmt.Sprintf("unknown category %q (allowed: %s); memory not stored", pm.Category, strings.Join(allowed, ", ")),
+	}}, false)
+	return "", false
+}
+
+// normalizeMemoryCategory maps category to one of allowed: exactly, as a
+// plural ("dependencies"), or as the one allowed category within a small
+// edit distance ("behaviour", "behvior"). found is false when none, or
+// more than one equally close, matches.
+func normalizeMemoryCategory(category string, allowed []string) (normalized string, found bool) {
+	c := strings.ToLower(strings.TrimSpace(category))
+	if c == "" {
+		return "", false
+	}
+	candidates := []string{c}
+	if strings.HasSuffix(c, "ies") {
+		candidates = append(candidates, strings.TrimSuffix(c, "ies")+"y")
+	} else if strings.HasSuffix(c, "s") {
+		candidates = append(candidates, strings.TrimSuffix(c, "s"))
+	}
+	for _, cand := range candidates {
+		for _, a := range allowed {
+			if cand == a {
+				return a, true
+			}
+		}
+	}
+
+	best, bestDist, tie := "", -1, false
+	for _, a := range allowed {
+		limit := max(1, len(a)/4)
+		for _, cand := range candidates {
+			d := editDistance(cand, a)
+			if d > limit {
+				continue
+			}
+			switch {
+			case bestDist < 0 || d < bestDist:
+				best, bestDist, tie = a, d, false
+			case d == bestDist && a != best:
+				tie = true
+			}
+		}
+	}
+	if bestDist < 0 || tie {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
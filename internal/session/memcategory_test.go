@@ -0,0 +1,74 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestNormalizeMemoryCategory(t *testing.T) {
+	allowed := []string{"timing", "dependency", "behavior", "remediation", "maintenance"}
+	for in, want := range map[string]string{
+		"timing":       "timing",
+		" Timing ":     "timing",
+		"behaviour":    "behavior",
+		"behvior":      "behavior",
+		"dependencies": "dependency",
+		"timings":      "timing",
+		"maintainance": "maintenance",
+		"remediaton":   "remediation",
+		"security":     "",
+		"cost":         "",
+		"":             "",
+	} {
+		got, ok := normalizeMemoryCategory(in, allowed)
+		if got != want || ok != (want != "") {
+			t.Errorf("normalizeMemoryCategory(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	// Equally close to two allowed categories is ambiguous.
+	if got, ok := normalizeMemoryCategory("bat", []string{"cat", "hat"}); ok {
+		t.Errorf("expected an ambiguous match to fail, got %q", got)
+	}
+}
+
+func TestUpsertMemoryStrictCategories(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.MemoryCategories = "timing, behavior"
+	sid, err := database.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/dev/null", Status: "running",
+		StartedAt: "2026-02-15T10:00:00Z", Trigger: "scheduled",
+	})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+	svc := "jellyfin"
+
+	// A misspelled category is normalized.
+	m.upsertMemory(sid, 1, parsedMemory{Category: "behvior", Service: &svc, Observation: "Restarts cleanly"})
+	if mem, _ := database.FindSimilarMemory(&svc, "behavior"); mem == nil {
+		t.Error("expected the memory stored as behavior")
+	}
+
+	// An unknown category is kept outside strict mode...
+	m.upsertMemory(sid, 1, parsedMemory{Category: "security", Service: &svc, Observation: "Exposes port 8096"})
+	if mem, _ := database.FindSimilarMemory(&svc, "security"); mem == nil {
+		t.Error("expected the memory stored outside strict mode")
+	}
+
+	// ...and rejected as a parse warning in it.
+	m.cfg.MemoryStrict = true
+	m.upsertMemory(sid, 1, parsedMemory{Category: "cost", Service: &svc, Observation: "Transcoding is expensive"})
+	if mem, _ := database.FindSimilarMemory(&svc, "cost"); mem != nil {
+		t.Errorf("expected the memory rejected, got %+v", mem)
+	}
+	warnings, err := database.ListParseWarnings(sid)
+	if err != nil || len(warnings) != 1 {
+		t.Fatalf("ListParseWarnings = %+v, %v", warnings, err)
+	}
+	w := warnings[0]
+	if w.Marker != "memory" || w.Text != "[MEMORY:cost:jellyfin] Transcoding is expensive" || !strings.Contains(w.Reason, `unknown category "cost" (allowed: timing, behavior)`) {
+		t.Errorf("unexpected warning %+v", w)
+	}
+}
@@ -1,6 +1,8 @@
 package session
 
 import (
+	"context"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -8,6 +10,7 @@ import (
 	"github.com/joestump/claude-ops/internal/config"
 	"github.com/joestump/claude-ops/internal/db"
 	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/servicename"
 )
 
 func testManagerWithDB(t *testing.T) (*Manager, *db.DB) {
@@ -24,6 +27,10 @@ func testManagerWithDB(t *testing.T) (*Manager, *db.DB) {
 		AllowedTools: "Bash,Read",
 		DryRun:       true,
 		MemoryBudget: 2000,
+
+		MemoryReinforce:  0.1,
+		MemoryContradict: 0.1,
+		MemoryDecay:      0.1,
 	}
 	database, err := db.Open(filepath.Join(cfg.StateDir, "test.db"))
 	if err != nil {
@@ -158,7 +165,7 @@ func TestBuildMemoryContext(t *testing.T) {
 		Confidence: 0.6, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
 	})
 
-	got := m.buildMemoryContext()
+	got := m.buildMemoryContext(nil, 0)
 
 	if !strings.Contains(got, "## Operational Memory") {
 		t.Errorf("missing header in:\n%s", got)
@@ -185,7 +192,7 @@ func TestBuildMemoryContext(t *testing.T) {
 
 func TestBuildMemoryContext_Empty(t *testing.T) {
 	m, _ := testManagerWithDB(t)
-	got := m.buildMemoryContext()
+	got := m.buildMemoryContext(nil, 0)
 	if got != "" {
 		t.Errorf("expected empty string for no memories, got %q", got)
 	}
@@ -210,7 +217,7 @@ func TestBuildMemoryContext_RespectsTokenBudget(t *testing.T) {
 		})
 	}
 
-	got := m.buildMemoryContext()
+	got := m.buildMemoryContext(nil, 0)
 
 	bodyStart := strings.Index(got, "\n### ")
 	if bodyStart == -1 {
@@ -237,7 +244,7 @@ func TestBuildMemoryContext_ZeroBudget(t *testing.T) {
 		Confidence: 0.9, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
 	})
 
-	got := m.buildMemoryContext()
+	got := m.buildMemoryContext(nil, 0)
 	if got != "" {
 		t.Errorf("expected empty string for zero budget, got %q", got)
 	}
@@ -257,7 +264,7 @@ func TestBuildMemoryContext_GroupsByService(t *testing.T) {
 		Confidence: 0.8, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
 	})
 
-	got := m.buildMemoryContext()
+	got := m.buildMemoryContext(nil, 0)
 
 	count := strings.Count(got, "### jellyfin")
 	if count != 1 {
@@ -271,6 +278,114 @@ func TestBuildMemoryContext_GroupsByService(t *testing.T) {
 	}
 }
 
+func TestBuildMemoryContext_ReviewStatus(t *testing.T) {
+	m, database := testManagerWithDB(t)
+
+	now := "2026-02-15T10:00:00Z"
+	svc := "caddy"
+	_, _ = database.InsertMemory(&db.Memory{
+		Service: &svc, Category: "behavior", Observation: "Reload on config change",
+		Confidence: 0.8, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	})
+	_, _ = database.InsertMemory(&db.Memory{
+		Service: &svc, Category: "timing", Observation: "Certificates renew at 3am",
+		Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+		ReviewStatus: db.MemoryUnverified,
+	})
+
+	got := m.buildMemoryContext(nil, 0)
+	if !strings.Contains(got, "Certificates renew at 3am (confidence: 0.7, unverified)") {
+		t.Errorf("expected unverified memory to be labelled, got:\n%s", got)
+	}
+	if strings.Contains(got, "Reload on config change (confidence: 0.8, unverified)") {
+		t.Errorf("verified memory should not be labelled unverified")
+	}
+
+	m.cfg.MemoryVerifiedOnly = true
+	got = m.buildMemoryContext(nil, 0)
+	if strings.Contains(got, "Certificates renew at 3am") {
+		t.Errorf("expected unverified memory to be excluded, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Reload on config change") {
+		t.Errorf("expected verified memory to be included, got:\n%s", got)
+	}
+}
+
+func TestBuildMemoryContext_ServiceOutsideGlobalTop(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.MemoryBudget = 100000
+
+	now := "2026-02-15T10:00:00Z"
+	caddy, jellyfin := "caddy", "jellyfin"
+	for i := 0; i < memoryFetchLimit+10; i++ {
+		_, _ = database.InsertMemory(&db.Memory{
+			Service: &caddy, Category: "behavior", Observation: fmt.Sprintf("caddy note %d", i),
+			Confidence: 0.95, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+		})
+	}
+	_, _ = database.InsertMemory(&db.Memory{
+		Service: &jellyfin, Category: "timing", Observation: "Takes 60s to start",
+		Confidence: 0.5, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	})
+
+	if got := m.buildMemoryContext([]string{"jellyfin"}, 0); !strings.Contains(got, "Takes 60s to start") {
+		t.Errorf("scoped context is missing jellyfin's memory:\n%.300s", got)
+	}
+	got := m.buildMemoryContext(nil, 3)
+	if !strings.Contains(got, "Takes 60s to start") || strings.Count(got, "caddy note") != 3 {
+		t.Errorf("per-service context should hold 3 caddy notes and jellyfin's memory, got:\n%s", got)
+	}
+}
+
+func TestBuildMemoryContext_ScopedToServices(t *testing.T) {
+	m, database := testManagerWithDB(t)
+
+	now := "2026-02-15T10:00:00Z"
+	jellyfin, caddy := "jellyfin", "caddy"
+	_, _ = database.InsertMemory(&db.Memory{
+		Service: &caddy, Category: "behavior", Observation: "Reload on config change",
+		Confidence: 0.9, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	})
+	_, _ = database.InsertMemory(&db.Memory{
+		Service: &jellyfin, Category: "timing", Observation: "Takes 60s to start",
+		Confidence: 0.8, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	})
+	_, _ = database.InsertMemory(&db.Memory{
+		Service: nil, Category: "remediation", Observation: "DNS flaps during WireGuard reconnects",
+		Confidence: 0.95, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	})
+
+	got := m.buildMemoryContext([]string{"Jellyfin"}, 0)
+	if strings.Contains(got, "### caddy") {
+		t.Errorf("expected unaffected service to be excluded, got:\n%s", got)
+	}
+	jf, gen := strings.Index(got, "### jellyfin"), strings.Index(got, "### general")
+	if jf == -1 || gen == -1 || jf > gen {
+		t.Errorf("expected affected service before general memories, got:\n%s", got)
+	}
+}
+
+func TestBuildMemoryContext_PerServiceCap(t *testing.T) {
+	m, database := testManagerWithDB(t)
+
+	now := "2026-02-15T10:00:00Z"
+	svc := "jellyfin"
+	for i, conf := range []float64{0.5, 0.9, 0.7} {
+		_, _ = database.InsertMemory(&db.Memory{
+			Service: &svc, Category: "behavior", Observation: fmt.Sprintf("observation %d", i),
+			Confidence: conf, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+		})
+	}
+
+	got := m.buildMemoryContext(nil, 2)
+	if n := strings.Count(got, "- [behavior]"); n != 2 {
+		t.Fatalf("expected 2 memories, got %d in:\n%s", n, got)
+	}
+	if strings.Contains(got, "observation 0") {
+		t.Errorf("expected lowest-confidence memory to be dropped, got:\n%s", got)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // upsertMemory
 // ---------------------------------------------------------------------------
@@ -306,6 +421,9 @@ func TestUpsertMemory_NewMemory(t *testing.T) {
 	if mem.Confidence != 0.7 {
 		t.Errorf("confidence = %f, want 0.7", mem.Confidence)
 	}
+	if mem.ReviewStatus != db.MemoryUnverified {
+		t.Errorf("review status = %q, want unverified", mem.ReviewStatus)
+	}
 }
 
 func TestUpsertMemory_Reinforce(t *testing.T) {
@@ -423,3 +541,96 @@ func TestUpsertMemory_GeneralMemory(t *testing.T) {
 		t.Errorf("service = %v, want nil", mem.Service)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// tombstones
+// ---------------------------------------------------------------------------
+
+func TestSimilarObservation(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Takes 60s to start", "takes 60s to start.", true},
+		{"Restart fixes the 502 errors", "restart fixes the 502 errors quickly", true},
+		{"Takes 60s to start", "Needs a DB migration before start", false},
+		{"", "anything", false},
+	}
+	for _, tt := range tests {
+		if got := similarObservation(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarObservation(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUpsertMemory_SuppressedByTombstone(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.MemoryTombstoneDays = 30
+
+	svc := "nginx"
+	now := "2026-02-15T10:00:00Z"
+	id, err := database.InsertMemory(&db.Memory{
+		Service: &svc, Category: "behavior", Observation: "Restart fixes 502s",
+		Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	})
+	if err != nil {
+		t.Fatalf("insert memory: %v", err)
+	}
+	if err := database.DeleteMemory(id); err != nil {
+		t.Fatalf("delete memory: %v", err)
+	}
+
+	m.upsertMemory(1, 1, parsedMemory{Category: "remediation", Service: &svc, Observation: "restart fixes 502s!"})
+
+	if mems, _ := database.ListMemories(&svc, nil, nil, 10, 0); len(mems) != 0 {
+		t.Fatalf("expected re-learned memory to be suppressed, got %+v", mems)
+	}
+	tombs, _ := database.ListTombstonedMemories(10)
+	if len(tombs) != 1 || tombs[0].SuppressedCount != 1 {
+		t.Fatalf("expected suppression to be counted on the tombstone, got %+v", tombs)
+	}
+
+	// A different service is not affected by the tombstone.
+	other := "caddy"
+	m.upsertMemory(1, 1, parsedMemory{Category: "behavior", Service: &other, Observation: "Restart fixes 502s"})
+	if mems, _ := database.ListMemories(&other, nil, nil, 10, 0); len(mems) != 1 {
+		t.Fatalf("expected memory for another service to be inserted, got %d", len(mems))
+	}
+
+	// Disabling tombstones allows re-learning.
+	m.cfg.MemoryTombstoneDays = 0
+	m.upsertMemory(1, 1, parsedMemory{Category: "behavior", Service: &svc, Observation: "Restart fixes 502s"})
+	if mems, _ := database.ListMemories(&svc, nil, nil, 10, 0); len(mems) != 1 {
+		t.Fatalf("expected memory to be inserted with tombstones disabled, got %d", len(mems))
+	}
+}
+
+func TestRunTierMemoryContextMatchesAliases(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	aliases, err := servicename.ParseAliases("jf=jellyfin")
+	if err != nil {
+		t.Fatalf("ParseAliases: %v", err)
+	}
+	m.services = servicename.New(aliases, database)
+	m.runner = &pipeRunner{events: []string{
+		`{"type":"result","subtype":"success","result":"ok","total_cost_usd":0.01,"num_turns":1,"duration_ms":100}`,
+	}}
+	now := "2026-02-15T10:00:00Z"
+	jellyfin := "jellyfin"
+	_, _ = database.InsertMemory(&db.Memory{
+		Service: &jellyfin, Category: "timing", Observation: "Takes 60s to start",
+		Confidence: 0.9, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	})
+
+	sessionID, _, err := m.runTier(context.Background(), 2, "sonnet", "/dev/null", nil, "", []string{"JF"}, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	sess, err := database.GetSession(sessionID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if inv := ParseInvocation(sess.Invocation); inv == nil || !strings.Contains(inv.SystemPrompt, "Takes 60s to start") {
+		t.Errorf("expected the aliased service's memory in the system prompt, got %+v", inv)
+	}
+}
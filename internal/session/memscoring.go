@@ -0,0 +1,85 @@
+package session
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// Fixed points of the memory scoring model.
+const (
+	memoryInitialConfidence = 0.7
+	memoryDeactivateBelow   = 0.3
+	memoryDecayGraceDays    = 30
+)
+
+// MemoryScoring is the effective memory scoring model: the configured
+// parameters and the per-category overrides.
+type MemoryScoring struct {
+	Default    db.MemoryScore            `json:"default"`
+	Categories map[string]db.MemoryScore `json:"categories"`
+	// InitialConfidence, DeactivateBelow, and DecayGraceDays are fixed.
+	InitialConfidence float64 `json:"initial_confidence"`
+	DeactivateBelow   float64 `json:"deactivate_below"`
+	DecayGraceDays    int     `json:"decay_grace_days"`
+}
+
+// For returns the scoring parameters for memories in category.
+func (s MemoryScoring) For(category string) db.MemoryScore {
+	if score, ok := s.Categories[category]; ok {
+		return score
+	}
+	return s.Default
+}
+
+// NewMemoryScoring builds the scoring model from the configuration.
+// CLAUDEOPS_MEMORY_SCORING overrides parameters per category as a
+// semicolon-separated list of category:param=value,... entries, e.g.
+// "timing:decay=0.2;architecture:decay=0.05,reinforce=0.15". Parameters an
+// entry leaves out keep their configured value. Malformed entries, and
+// negative values, are skipped.
+func NewMemoryScoring(cfg *config.Config) MemoryScoring {
+	s := MemoryScoring{
+		Default: db.MemoryScore{
+			Reinforce:  cfg.MemoryReinforce,
+			Contradict: cfg.MemoryContradict,
+			Decay:      cfg.MemoryDecay,
+		},
+		Categories:        make(map[string]db.MemoryScore),
+		InitialConfidence: memoryInitialConfidence,
+		DeactivateBelow:   memoryDeactivateBelow,
+		DecayGraceDays:    memoryDecayGraceDays,
+	}
+	for _, part := range strings.Split(cfg.MemoryScoring, ";") {
+		category, params, ok := strings.Cut(strings.TrimSpace(part), ":")
+		category = strings.ToLower(strings.TrimSpace(category))
+		if !ok || category == "" {
+			continue
+		}
+		score, valid := s.Default, true
+		for _, p := range strings.Split(params, ",") {
+			name, value, ok := strings.Cut(p, "=")
+			v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if !ok || err != nil || v < 0 {
+				valid = false
+				break
+			}
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "reinforce":
+				score.Reinforce = v
+			case "contradict":
+				score.Contradict = v
+			case "decay":
+				score.Decay = v
+			default:
+				valid = false
+			}
+		}
+		if valid {
+			s.Categories[category] = score
+		}
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestNewMemoryScoring(t *testing.T) {
+	s := NewMemoryScoring(&config.Config{
+		MemoryReinforce:  0.1,
+		MemoryContradict: 0.15,
+		MemoryDecay:      0.1,
+		MemoryScoring:    "Timing:decay=0.2,reinforce=0.05; architecture:decay=0.02;bad:decay=-1;worse:speed=2;nocolon",
+	})
+	if got, want := s.For("timing"), (db.MemoryScore{Reinforce: 0.05, Contradict: 0.15, Decay: 0.2}); got != want {
+		t.Errorf("timing = %+v, want %+v", got, want)
+	}
+	if got := s.For("architecture"); got.Decay != 0.02 || got.Reinforce != 0.1 {
+		t.Errorf("architecture = %+v", got)
+	}
+	if got := s.For("dependency"); got != s.Default {
+		t.Errorf("dependency = %+v, want the default", got)
+	}
+	if len(s.Categories) != 2 {
+		t.Errorf("malformed overrides were kept: %v", s.Categories)
+	}
+}
+
+func TestUpsertMemoryRecordsScoring(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.MemoryScoring = "timing:reinforce=0.2"
+	sid, _ := database.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/dev/null", Status: "running",
+		StartedAt: "2026-02-15T10:00:00Z", Trigger: "scheduled",
+	})
+	svc := "jellyfin"
+	pm := parsedMemory{Category: "timing", Service: &svc, Observation: "Takes 60s to start"}
+	m.upsertMemory(sid, 1, pm)
+	m.upsertMemory(sid, 1, pm)
+
+	mem, _ := database.FindSimilarMemory(&svc, "timing")
+	if mem == nil {
+		t.Fatal("expected memory")
+	}
+	if diff := mem.Confidence - 0.9; diff < -0.01 || diff > 0.01 {
+		t.Errorf("confidence = %f, want ~0.9", mem.Confidence)
+	}
+	revs, err := database.ListMemoryRevisions(mem.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 || revs[0].Change != db.MemoryCreated || revs[1].Change != db.MemoryReinforced {
+		t.Fatalf("revisions = %+v", revs)
+	}
+	if r := revs[1]; r.Score.Reinforce != 0.2 || r.ConfidenceBefore == nil || *r.ConfidenceBefore != 0.7 || *r.SessionID != sid {
+		t.Errorf("reinforcement revision = %+v", r)
+	}
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// outcomeSignals is what a finished session left behind that decides its
+// outcome.
+type outcomeSignals struct {
+	status   string // final session status
+	reported bool   // the agent produced a response
+	issues   bool   // the agent reported warning or critical events or unhealthy services
+	escalate bool   // the agent asked to escalate
+	// actions are the remediations the session recorded with cooldown markers.
+	actions []db.CooldownAction
+}
+
+// classifyOutcome decides a finished session's outcome. A remediation that
+// failed, or after which the agent still asked to escalate, failed. A
+// session that did not complete is inconclusive, as is one that completed
+// without reporting anything.
+func classifyOutcome(s outcomeSignals) string {
+	failed := false
+	for _, a := range s.actions {
+		if !a.Success {
+			failed = true
+		}
+	}
+	switch {
+	case failed:
+		return db.SessionRemediationFailed
+	case s.status != "completed":
+		return db.SessionInconclusive
+	case len(s.actions) > 0 && s.escalate:
+		return db.SessionRemediationFailed
+	case len(s.actions) > 0:
+		return db.SessionRemediated
+	case s.issues || s.escalate:
+		return db.SessionIssuesObserved
+	case !s.reported:
+		return db.SessionInconclusive
+	}
+	return db.SessionHealthy
+}
+
+// recordOutcome classifies a finished session at tier and stores its
+// outcome. Escalation is read from the structured response, or from the
+// handoff file when there is none; the chain reads and removes the handoff
+// after this. Handing back to a lower tier is not escalating.
+func (m *Manager) recordOutcome(sessionID int64, tier int, status string, resp *AgentResponse, events []parsedEvent, reported bool) {
+	s := outcomeSignals{status: status, reported: reported}
+	if resp != nil {
+		s.escalate = resp.Escalation.Needed && !isHandBack(resp.Escalation.RecommendedTier, tier)
+		events = nil
+		for _, e := range resp.Events {
+			events = append(events, parsedEvent{Level: e.Level})
+		}
+		for _, sc := range resp.ServicesChecked {
+			if healthStatus(sc.Status) != "healthy" {
+				s.issues = true
+			}
+		}
+	} else if h, err := ReadHandoff(m.cfg.StateDir); err == nil && h != nil {
+		s.escalate = !isHandBack(h.RecommendedTier, tier)
+	}
+	for _, e := range events {
+		if level := normalizeEventLevel(e.Level); level == "warning" || level == "critical" {
+			s.issues = true
+		}
+	}
+	actions, err := m.db.ListCooldownActionsForSessions([]int64{sessionID})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: list remediations for outcome: %v\n", sessionID, err)
+	}
+	s.actions = actions
+
+	outcome := classifyOutcome(s)
+	if err := m.db.UpdateSessionOutcome(sessionID, outcome); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: store outcome: %v\n", sessionID, err)
+	}
+}
+
+// markRemediationFailed records that a remediation session's fix did not
+// hold.
+func (m *Manager) markRemediationFailed(sessionID int64) {
+	if err := m.db.UpdateSessionOutcome(sessionID, db.SessionRemediationFailed); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: store outcome: %v\n", sessionID, err)
+	}
+}
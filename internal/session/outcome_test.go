@@ -0,0 +1,86 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestClassifyOutcome(t *testing.T) {
+	restart := db.CooldownAction{Service: "jellyfin", ActionType: "restart", Success: true}
+	failedRestart := db.CooldownAction{Service: "jellyfin", ActionType: "restart"}
+	for name, tc := range map[string]struct {
+		signals outcomeSignals
+		want    string
+	}{
+		"all healthy":             {outcomeSignals{status: "completed", reported: true}, db.SessionHealthy},
+		"warning event":           {outcomeSignals{status: "completed", reported: true, issues: true}, db.SessionIssuesObserved},
+		"asked to escalate":       {outcomeSignals{status: "completed", reported: true, escalate: true}, db.SessionIssuesObserved},
+		"restarted":               {outcomeSignals{status: "completed", reported: true, issues: true, actions: []db.CooldownAction{restart}}, db.SessionRemediated},
+		"restart failed":          {outcomeSignals{status: "completed", reported: true, actions: []db.CooldownAction{restart, failedRestart}}, db.SessionRemediationFailed},
+		"restarted but escalated": {outcomeSignals{status: "completed", reported: true, escalate: true, actions: []db.CooldownAction{restart}}, db.SessionRemediationFailed},
+		"timed out":               {outcomeSignals{status: "timed_out", actions: []db.CooldownAction{restart}}, db.SessionInconclusive},
+		"timed out after failure": {outcomeSignals{status: "timed_out", actions: []db.CooldownAction{failedRestart}}, db.SessionRemediationFailed},
+		"no response":             {outcomeSignals{status: "completed"}, db.SessionInconclusive},
+	} {
+		if got := classifyOutcome(tc.signals); got != tc.want {
+			t.Errorf("%s: outcome = %s, want %s", name, got, tc.want)
+		}
+	}
+}
+
+func TestRecordOutcome(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	start := func() int64 {
+		id, err := database.InsertSession(&db.Session{Tier: 1, Model: "haiku", PromptFile: "/dev/null", Status: "completed", StartedAt: time.Now().UTC().Format(time.RFC3339), Trigger: "scheduled"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	outcome := func(id int64) string {
+		s, _ := database.GetSession(id)
+		if s.Outcome == nil {
+			return ""
+		}
+		return *s.Outcome
+	}
+
+	healthy := start()
+	m.recordOutcome(healthy, 1, "completed", &AgentResponse{ServicesChecked: []ServiceCheck{{Name: "jellyfin", Status: "healthy"}}}, []parsedEvent{{Level: "critical"}}, true)
+	if got := outcome(healthy); got != db.SessionHealthy {
+		t.Errorf("structured all-healthy response: %s (markers are ignored when there is structured output)", got)
+	}
+
+	degraded := start()
+	m.recordOutcome(degraded, 1, "completed", &AgentResponse{ServicesChecked: []ServiceCheck{{Name: "jellyfin", Status: "degraded"}}}, nil, true)
+	if got := outcome(degraded); got != db.SessionIssuesObserved {
+		t.Errorf("degraded service: %s", got)
+	}
+
+	handoff := start()
+	if err := os.WriteFile(filepath.Join(m.cfg.StateDir, handoffFileName), []byte(`{"schema_version":1,"recommended_tier":2}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m.recordOutcome(handoff, 1, "completed", nil, []parsedEvent{{Level: "info"}}, true)
+	if got := outcome(handoff); got != db.SessionIssuesObserved {
+		t.Errorf("handoff written: %s", got)
+	}
+
+	remediated := start()
+	sid := remediated
+	if _, err := database.InsertCooldownAction(&db.CooldownAction{Service: "jellyfin", ActionType: "restart", Timestamp: time.Now().UTC().Format(time.RFC3339), Success: true, Tier: 3, SessionID: &sid}); err != nil {
+		t.Fatal(err)
+	}
+	m.recordOutcome(remediated, 1, "completed", &AgentResponse{}, nil, true)
+	if got := outcome(remediated); got != db.SessionRemediated {
+		t.Errorf("successful restart: %s", got)
+	}
+	m.markRemediationFailed(remediated)
+	if got := outcome(remediated); got != db.SessionRemediationFailed {
+		t.Errorf("after failed verification: %s", got)
+	}
+}
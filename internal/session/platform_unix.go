@@ -0,0 +1,20 @@
+//go:build !windows
+
+package session
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// isolateProcess starts cmd in its own process group.
+// Governing: SPEC-0008 REQ-7 — process group isolation for signal forwarding.
+func isolateProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// removeFile removes path.
+func removeFile(path string) error {
+	return os.Remove(path)
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package session
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// isolateProcess starts cmd in its own process group, so a console Ctrl+C
+// reaches the supervisor without also killing the session mid-drain.
+func isolateProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION, which syscall does not
+// name.
+const errorSharingViolation syscall.Errno = 32
+
+// removeFile removes path. Windows refuses to delete a file another process
+// still has open, such as the agent that just wrote it or a virus scanner
+// inspecting it, so a sharing violation is retried for a short while.
+func removeFile(path string) error {
+	var err error
+	for range 10 {
+		err = os.Remove(path)
+		if !errors.Is(err, errorSharingViolation) && !os.IsPermission(err) {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return err
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/policy"
+)
+
+// checkEscalationPolicy evaluates the policy for escalating sessionID from
+// fromTier to toTier. It returns the tier to escalate to, which a cap rule
+// may lower, or 0 if the escalation is denied, and the message explaining a
+// denial or cap.
+func (m *Manager) checkEscalationPolicy(sessionID int64, fromTier, toTier int, trigger, escalationCtx string, services []string) (int, string) {
+	if m.Policy == nil {
+		return toTier, ""
+	}
+	in := m.policyInput(sessionID)
+	in.Escalation = &policy.Escalation{
+		FromTier: fromTier,
+		ToTier:   toTier,
+		Trigger:  trigger,
+		Context:  escalationCtx,
+		Services: services,
+	}
+	d := m.Policy.Evaluate(policy.PointEscalation, in)
+	m.recordPolicyResults(sessionID, policy.PointEscalation, d)
+
+	tier, level, msg := escalationPolicyOutcome(d, fromTier, toTier)
+	if tier > 0 {
+		if reason := m.deniedCooldown(sessionID); reason != "" {
+			tier, level = 0, "warning"
+			msg = fmt.Sprintf("Escalation to tier %d denied by policy %s, for a cooldown action this session took", toTier, reason)
+		}
+	}
+	if msg != "" {
+		m.emitEscalationEventLevel(sessionID, level, msg)
+	}
+	return tier, msg
+}
+
+// escalationPolicyOutcome applies an escalation policy decision. It returns
+// the tier to escalate to, or 0 if the escalation is denied, and when the
+// decision changed the escalation, the event level and message saying why.
+func escalationPolicyOutcome(d policy.Decision, fromTier, toTier int) (tier int, level, msg string) {
+	if d.Deny || (d.MaxTier > 0 && d.MaxTier <= fromTier) {
+		return 0, "warning", fmt.Sprintf("Escalation to tier %d denied by policy %s", toTier, d.Reason)
+	}
+	if d.MaxTier > 0 && d.MaxTier < toTier {
+		return d.MaxTier, "info", fmt.Sprintf("Escalation capped at tier %d (requested tier %d) by policy %s", d.MaxTier, toTier, d.Reason)
+	}
+	return toTier, "", ""
+}
+
+// checkCooldownPolicy evaluates the policy for a cooldown action reported
+// by the agent. The agent has already taken the action, so it is recorded
+// either way; a deny stops the session from escalating further instead.
+func (m *Manager) checkCooldownPolicy(sessionID int64, tier int, pc parsedCooldown) {
+	if m.Policy == nil {
+		return
+	}
+	recent, err := m.db.CheckCooldown(pc.Service, pc.ActionType, 24*time.Hour)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: policy cooldown count: %v\n", sessionID, err)
+	}
+	in := m.policyInput(sessionID)
+	in.Cooldown = &policy.Cooldown{
+		Service:     pc.Service,
+		Action:      pc.ActionType,
+		Tier:        tier,
+		Success:     pc.Success,
+		RecentCount: recent,
+	}
+	d := m.Policy.Evaluate(policy.PointCooldown, in)
+	m.recordPolicyResults(sessionID, policy.PointCooldown, d)
+	if d.Deny {
+		m.emitEscalationEventLevel(sessionID, "warning",
+			fmt.Sprintf("Cooldown action %s on %s denied by policy %s; the session will not escalate further", pc.ActionType, pc.Service, d.Reason))
+	}
+}
+
+// deniedCooldown returns the rule, with its message, that denied a cooldown
+// action of sessionID, or "" if none did.
+func (m *Manager) deniedCooldown(sessionID int64) string {
+	evals, err := m.db.ListPolicyEvaluations(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: policy evaluations: %v\n", sessionID, err)
+		return ""
+	}
+	for _, e := range evals {
+		if e.Point == policy.PointCooldown && e.Matched && e.Action == policy.ActionDeny {
+			return e.Detail
+		}
+	}
+	return ""
+}
+
+// policyInput gathers the service catalog and spend for a policy
+// evaluation. Lookup failures are logged and leave that part empty.
+func (m *Manager) policyInput(sessionID int64) policy.Input {
+	return PolicyInput(m.db, sessionID)
+}
+
+// PolicyInput gathers the service catalog and spend for a policy evaluation
+// of sessionID, for callers outside the Manager that take actions a policy
+// governs. Lookup failures are logged and leave that part empty.
+func PolicyInput(database *db.DB, sessionID int64) policy.Input {
+	in := policy.Input{Services: make(map[string]policy.Service), Now: time.Now()}
+
+	statuses, err := database.ListServiceStatuses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: policy service catalog: %v\n", sessionID, err)
+	}
+	for _, s := range statuses {
+		svc := policy.Service{Status: s.Status, CheckCount: s.CheckCount}
+		if s.LastCheck != nil {
+			svc.LastCheck = *s.LastCheck
+		}
+		in.Services[s.Service] = svc
+	}
+
+	in.Budget = chainSpend(database, sessionID, in.Now)
+	return in
+}
+
+// chainSpend totals the cost of sessionID's escalation chain and of all
+// sessions started in the 24 hours before now. Lookup failures are logged
+// and leave that total zero.
+func chainSpend(database *db.DB, sessionID int64, now time.Time) policy.Budget {
+	var b policy.Budget
+	chain, err := database.GetEscalationChain(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: chain cost: %v\n", sessionID, err)
+	}
+	for _, s := range chain {
+		if s.CostUSD != nil {
+			b.ChainCostUSD += *s.CostUSD
+		}
+	}
+	since := now.UTC().Add(-24 * time.Hour).Format(time.RFC3339)
+	if b.Cost24hUSD, err = database.SessionCostSince(since); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: daily cost: %v\n", sessionID, err)
+	}
+	return b
+}
+
+// recordPolicyResults stores each rule's outcome on the session. A rule
+// that failed to evaluate is also reported as a warning event.
+func (m *Manager) recordPolicyResults(sessionID int64, point string, d policy.Decision) {
+	SavePolicyResults(m.db, sessionID, point, d)
+	for _, r := range d.Results {
+		if r.Action == "error" {
+			m.emitEscalationEventLevel(sessionID, "warning",
+				fmt.Sprintf("Policy rule %s failed to evaluate and was skipped: %s", r.Rule, r.Detail))
+		}
+	}
+}
+
+// SavePolicyResults stores each rule's outcome of a policy decision on the
+// session it was evaluated for.
+func SavePolicyResults(database *db.DB, sessionID int64, point string, d policy.Decision) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, r := range d.Results {
+		pe := &db.PolicyEvaluation{
+			SessionID: sessionID,
+			Point:     point,
+			Rule:      r.Rule,
+			Matched:   r.Matched,
+			Action:    r.Action,
+			Detail:    r.Detail,
+			CreatedAt: now,
+		}
+		if _, err := database.InsertPolicyEvaluation(pe); err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: record policy evaluation: %v\n", sessionID, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/policy"
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestPolicyCapsEscalationAndDeniesCooldown(t *testing.T) {
+	engine, err := policy.Parse([]byte(`
+rules:
+  - name: no-tier3
+    when: escalation
+    expr: escalation.to_tier == 3
+    action: cap
+    max_tier: 2
+    message: remediation needs a human
+  - name: protect-jellyfin
+    when: cooldown
+    expr: cooldown.service == "jellyfin"
+    action: deny
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Result("Jellyfin is down.", testkit.Escalate("jellyfin returns 502", "jellyfin")),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Text("[COOLDOWN:restart:jellyfin] success — restarted the container"),
+			testkit.Result("Still failing.", testkit.Escalate("jellyfin still returns 502", "jellyfin")),
+		}},
+	)
+
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.cfg.Tier3Prompt = "/dev/null"
+	m.runner = runner
+	m.Policy = engine
+
+	rootID := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	if calls := runner.Calls(); len(calls) != 2 {
+		t.Fatalf("expected tier 3 to be denied after tier 2, got %d sessions", len(calls))
+	}
+	if n, err := database.CheckCooldown("jellyfin", "restart", time.Hour); err != nil || n != 1 {
+		t.Errorf("expected the denied cooldown action to be recorded, got %d (%v)", n, err)
+	}
+
+	evals, err := database.ListPolicyEvaluations(rootID)
+	if err != nil || len(evals) != 1 || evals[0].Matched {
+		t.Errorf("tier 1 evaluations = %+v (%v)", evals, err)
+	}
+	children, err := database.GetChildSessions(rootID)
+	if err != nil || len(children) != 1 {
+		t.Fatalf("GetChildSessions: %+v (%v)", children, err)
+	}
+	evals, err = database.ListPolicyEvaluations(children[0].ID)
+	if err != nil || len(evals) != 2 {
+		t.Fatalf("tier 2 evaluations = %+v (%v)", evals, err)
+	}
+	if evals[0].Point != policy.PointCooldown || evals[0].Action != policy.ActionDeny ||
+		evals[1].Point != policy.PointEscalation || evals[1].Action != policy.ActionCap {
+		t.Errorf("unexpected tier 2 evaluations %+v", evals)
+	}
+
+	events, err := database.ListEvents(10, 0, db.EventFilter{})
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	var denied, cooldownDenied bool
+	for _, e := range events {
+		denied = denied || strings.Contains(e.Message, "Escalation to tier 3 denied by policy no-tier3")
+		cooldownDenied = cooldownDenied || strings.Contains(e.Message, "restart on jellyfin denied by policy protect-jellyfin")
+	}
+	if !denied || !cooldownDenied {
+		t.Errorf("expected policy events, got %+v", events)
+	}
+}
+
+func TestPolicyDeniedCooldownBlocksEscalation(t *testing.T) {
+	engine, err := policy.Parse([]byte(`
+rules:
+  - name: restart-loop
+    when: cooldown
+    expr: cooldown.action == "restart"
+    action: deny
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Text("[COOLDOWN:restart:jellyfin] success — restarted the container"),
+			testkit.Result("Still failing.", testkit.Escalate("jellyfin still returns 502", "jellyfin")),
+		}},
+		testkit.Script{Events: []testkit.Event{testkit.Result("Fixed.", nil)}},
+	)
+
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.runner = runner
+	m.Policy = engine
+
+	m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	if calls := runner.Calls(); len(calls) != 1 {
+		t.Errorf("expected no escalation after a denied cooldown action, got %d sessions", len(calls))
+	}
+	if n, err := database.CheckCooldown("jellyfin", "restart", time.Hour); err != nil || n != 1 {
+		t.Errorf("expected the restart to be recorded, got %d (%v)", n, err)
+	}
+}
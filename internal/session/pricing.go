@@ -0,0 +1,105 @@
+package session
+
+import (
+	"strconv"
+	"strings"
+)
+
+// tokenUsage is the usage block of a result event.
+type tokenUsage struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+func (u *tokenUsage) total() int64 {
+	if u == nil {
+		return 0
+	}
+	return u.InputTokens + u.OutputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+// ModelPrice is a synthetic per-token rate for models whose name contains
+// Match. Rates are USD per million tokens.
+type ModelPrice struct {
+	Match      string // lower-case substring of the model name
+	Input      float64
+	Output     float64
+	CacheWrite float64
+	CacheRead  float64
+}
+
+// ParsePricing parses CLAUDEOPS_SYNTHETIC_PRICING, a semicolon-separated
+// list of model=input/output[/cache_write/cache_read] rates in USD per
+// million tokens, e.g. "haiku=1/5;sonnet=3/15;opus=15/75". Cache rates
+// default to 1.25x and 0.1x the input rate. Malformed entries are skipped.
+func ParsePricing(spec string) []ModelPrice {
+	var prices []ModelPrice
+	for _, part := range strings.Split(spec, ";") {
+		match, rates, ok := strings.Cut(strings.TrimSpace(part), "=")
+		match = strings.ToLower(strings.TrimSpace(match))
+		if !ok || match == "" {
+			continue
+		}
+		fields := strings.Split(rates, "/")
+		if len(fields) != 2 && len(fields) != 4 {
+			continue
+		}
+		vals := make([]float64, len(fields))
+		valid := true
+		for i, f := range fields {
+			v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+			if err != nil || v < 0 {
+				valid = false
+				break
+			}
+			vals[i] = v
+		}
+		if !valid {
+			continue
+		}
+		p := ModelPrice{Match: match, Input: vals[0], Output: vals[1], CacheWrite: vals[0] * 1.25, CacheRead: vals[0] * 0.1}
+		if len(vals) == 4 {
+			p.CacheWrite, p.CacheRead = vals[2], vals[3]
+		}
+		prices = append(prices, p)
+	}
+	return prices
+}
+
+// estimateCost prices usage for model with the longest matching entry. It
+// returns false when no entry matches or there is no usage to price.
+func estimateCost(prices []ModelPrice, model string, usage *tokenUsage) (float64, bool) {
+	if usage.total() == 0 {
+		return 0, false
+	}
+	model = strings.ToLower(model)
+	var best *ModelPrice
+	for i := range prices {
+		if strings.Contains(model, prices[i].Match) && (best == nil || len(prices[i].Match) > len(best.Match)) {
+			best = &prices[i]
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+	cost := float64(usage.InputTokens)*best.Input +
+		float64(usage.OutputTokens)*best.Output +
+		float64(usage.CacheCreationInputTokens)*best.CacheWrite +
+		float64(usage.CacheReadInputTokens)*best.CacheRead
+	return cost / 1e6, true
+}
+
+// resultCost is the cost to record for a result event: the CLI's reported
+// cost, or a synthetic estimate from the pricing table when the CLI reports
+// zero (as on a Claude subscription) but token usage is present.
+func (m *Manager) resultCost(model string, evt *streamEvent) (cost float64, synthetic bool) {
+	if evt.TotalCostUSD > 0 {
+		return evt.TotalCostUSD, false
+	}
+	if est, ok := estimateCost(m.pricing, model, evt.Usage); ok {
+		return est, true
+	}
+	return evt.TotalCostUSD, false
+}
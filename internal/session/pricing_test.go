@@ -0,0 +1,97 @@
+package session
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestParsePricing(t *testing.T) {
+	prices := ParsePricing(" haiku=1/5 ; Opus=15/75/18.75/1.5;bad;sonnet=3;x=a/b;=1/2")
+	if len(prices) != 2 {
+		t.Fatalf("expected 2 prices, got %+v", prices)
+	}
+	if p := prices[0]; p.Match != "haiku" || p.Input != 1 || p.Output != 5 || p.CacheWrite != 1.25 || p.CacheRead != 0.1 {
+		t.Errorf("unexpected haiku price %+v", p)
+	}
+	if p := prices[1]; p.Match != "opus" || p.CacheWrite != 18.75 || p.CacheRead != 1.5 {
+		t.Errorf("unexpected opus price %+v", p)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	prices := ParsePricing("sonnet=3/15;sonnet-4-5=4/20")
+	usage := &tokenUsage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadInputTokens: 1_000_000}
+
+	cost, ok := estimateCost(prices, "claude-sonnet-4-20250514", usage)
+	if !ok || math.Abs(cost-(3+1.5+0.3)) > 1e-9 {
+		t.Errorf("sonnet: got %v, %v", cost, ok)
+	}
+	// The longest match wins.
+	cost, ok = estimateCost(prices, "claude-sonnet-4-5-20250929", usage)
+	if !ok || math.Abs(cost-(4+2+0.4)) > 1e-9 {
+		t.Errorf("sonnet-4-5: got %v, %v", cost, ok)
+	}
+	if _, ok := estimateCost(prices, "claude-haiku-4-5", usage); ok {
+		t.Error("unpriced model should not be estimated")
+	}
+	if _, ok := estimateCost(prices, "sonnet", &tokenUsage{}); ok {
+		t.Error("zero usage should not be estimated")
+	}
+	if _, ok := estimateCost(prices, "sonnet", nil); ok {
+		t.Error("missing usage should not be estimated")
+	}
+}
+
+func TestSubscriptionSessionGetsSyntheticCost(t *testing.T) {
+	result := `{"type":"result","subtype":"success","result":"All healthy.","total_cost_usd":0,"num_turns":2,"duration_ms":900,` +
+		`"usage":{"input_tokens":200000,"output_tokens":10000}}`
+	runner := testkit.NewRunner(testkit.Script{Events: []testkit.Event{
+		testkit.Init("claude-haiku-4-5-20251001"),
+		testkit.Raw(result),
+	}})
+
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 1
+	m.runner = runner
+	m.pricing = ParsePricing("haiku=1/5")
+
+	id := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	s, err := database.GetSession(id)
+	if err != nil || s == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if !s.CostSynthetic {
+		t.Error("expected cost to be flagged synthetic")
+	}
+	if s.CostUSD == nil || math.Abs(*s.CostUSD-0.25) > 1e-9 {
+		t.Errorf("expected synthetic cost 0.25, got %v", s.CostUSD)
+	}
+}
+
+func TestReportedCostIsNotSynthetic(t *testing.T) {
+	runner := testkit.NewRunner(testkit.Script{Events: []testkit.Event{
+		testkit.Init("claude-haiku-4-5-20251001"),
+		testkit.Result("All healthy.", nil),
+	}})
+
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 1
+	m.runner = runner
+	m.pricing = ParsePricing("haiku=1/5")
+
+	id := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	s, err := database.GetSession(id)
+	if err != nil || s == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if s.CostSynthetic || s.CostUSD == nil || *s.CostUSD != 0.01 {
+		t.Errorf("expected reported cost 0.01, got %v (synthetic %v)", s.CostUSD, s.CostSynthetic)
+	}
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// PromptRule selects a specialized Tier 2 investigation prompt when the
+// escalation context mentions any of its keywords.
+type PromptRule struct {
+	Prompt   string   // file name, resolved relative to the default Tier 2 prompt's directory
+	Keywords []string // lower-case substrings matched against the escalation context
+}
+
+// Path resolves the rule's prompt against the default Tier 2 prompt.
+func (r PromptRule) Path(defaultPrompt string) string {
+	if filepath.IsAbs(r.Prompt) {
+		return r.Prompt
+	}
+	return filepath.Join(filepath.Dir(defaultPrompt), r.Prompt)
+}
+
+// ParsePromptRules parses CLAUDEOPS_TIER2_PROMPT_RULES, a semicolon-separated
+// list of prompt=keyword|keyword rules, e.g.
+// "db-investigate.md=database|postgres;network-investigate.md=dns|network".
+// Malformed rules are skipped.
+func ParsePromptRules(spec string) []PromptRule {
+	var rules []PromptRule
+	for _, part := range strings.Split(spec, ";") {
+		prompt, kws, ok := strings.Cut(strings.TrimSpace(part), "=")
+		prompt = strings.TrimSpace(prompt)
+		if !ok || prompt == "" {
+			continue
+		}
+		rule := PromptRule{Prompt: prompt}
+		for _, kw := range strings.Split(kws, "|") {
+			if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" {
+				rule.Keywords = append(rule.Keywords, kw)
+			}
+		}
+		if len(rule.Keywords) > 0 {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// selectPrompt picks the prompt for an escalated tier from the escalation
+// context. The rule matching the most distinct keywords wins (earlier rules
+// win ties); rules whose prompt file does not exist are ignored. Returns
+// defaultPrompt and no matches when no rule applies.
+func selectPrompt(defaultPrompt string, rules []PromptRule, escalationCtx string) (string, []string) {
+	text := strings.ToLower(escalationCtx)
+	best, bestPath := []string(nil), defaultPrompt
+	for _, r := range rules {
+		var matched []string
+		for _, kw := range r.Keywords {
+			if strings.Contains(text, kw) {
+				matched = append(matched, kw)
+			}
+		}
+		if len(matched) <= len(best) {
+			continue
+		}
+		path := r.Path(defaultPrompt)
+		if PromptSource(path) == PromptSourceMissing {
+			continue
+		}
+		best, bestPath = matched, path
+	}
+	return bestPath, best
+}
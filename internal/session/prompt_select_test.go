@@ -0,0 +1,50 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePromptRules(t *testing.T) {
+	rules := ParsePromptRules(" db.md=Database| postgres ;bad;empty=;net.md=dns")
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %+v", rules)
+	}
+	if rules[0].Prompt != "db.md" || len(rules[0].Keywords) != 2 || rules[0].Keywords[0] != "database" {
+		t.Errorf("unexpected first rule %+v", rules[0])
+	}
+	if rules[1].Prompt != "net.md" || rules[1].Keywords[0] != "dns" {
+		t.Errorf("unexpected second rule %+v", rules[1])
+	}
+}
+
+func TestSelectPrompt(t *testing.T) {
+	dir := t.TempDir()
+	def := filepath.Join(dir, "tier2-investigate.md")
+	for _, name := range []string{"tier2-investigate.md", "db-investigate.md", "network-investigate.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# prompt"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rules := ParsePromptRules("db-investigate.md=database|postgres;network-investigate.md=dns|nxdomain;missing.md=nginx|caddy|proxy")
+
+	cases := []struct {
+		name    string
+		ctx     string
+		want    string
+		matched int
+	}{
+		{"database", "- **nextcloud** (http): down — database connection refused (postgres)", "db-investigate.md", 2},
+		{"network", "- **sonarr** (dns): down — NXDOMAIN for sonarr.home", "network-investigate.md", 2},
+		{"most matches wins", "DNS lookups for the database host fail with NXDOMAIN", "network-investigate.md", 2},
+		{"missing prompt file ignored", "nginx and caddy proxy errors", "tier2-investigate.md", 0},
+		{"no match", "- **jellyfin** (http): down — 502", "tier2-investigate.md", 0},
+	}
+	for _, tc := range cases {
+		got, matched := selectPrompt(def, rules, tc.ctx)
+		if filepath.Base(got) != tc.want || len(matched) != tc.matched {
+			t.Errorf("%s: got %s %v, want %s with %d matches", tc.name, got, matched, tc.want, tc.matched)
+		}
+	}
+}
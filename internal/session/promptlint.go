@@ -0,0 +1,66 @@
+package session
+
+import "strings"
+
+// Prompt lint severities. An error makes the prompt unusable; a warning
+// flags something sessions are likely to get wrong.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// PromptLint is one problem LintPrompt found.
+type PromptLint struct {
+	Severity string
+	Line     int // 1-based; 0 when the problem is not tied to a line
+	Message  string
+}
+
+// LintPrompt checks a tier prompt for the sections sessions depend on.
+// Prompts with a "## Base Instructions" section, such as the specialized
+// Tier 2 prompts and the verification prompt, build on another prompt and
+// are only checked for malformed markers.
+//
+//   - Tier 1 and 2 prompts must tell the agent how to escalate and hand off
+//     to the next tier.
+//   - Tier 2 and 3 prompts must remind the agent to emit [COOLDOWN:...]
+//     markers after remediating, or cooldowns go untracked.
+//   - Tier prompts should describe the response schema.
+//   - Example markers at the start of a line must be in the format the
+//     supervisor parses; a malformed example teaches the agent markers
+//     that are silently dropped.
+func LintPrompt(tier int, content string) []PromptLint {
+	if strings.TrimSpace(content) == "" {
+		return []PromptLint{{Severity: LintError, Message: "The prompt is empty."}}
+	}
+	var out []PromptLint
+	lower := strings.ToLower(content)
+	standalone := !strings.Contains(lower, "## base instructions")
+
+	if standalone && tier < 3 && !strings.Contains(lower, "escalat") {
+		out = append(out, PromptLint{Severity: LintWarning,
+			Message: "No handoff instructions: the prompt never says when or how to escalate to the next tier."})
+	}
+	if standalone && tier >= 2 && !strings.Contains(content, "[COOLDOWN:") {
+		out = append(out, PromptLint{Severity: LintWarning,
+			Message: "No cooldown marker reminder: remediations are only tracked when the agent emits [COOLDOWN:...] markers."})
+	}
+	if standalone && !strings.Contains(lower, "## response schema") {
+		out = append(out, PromptLint{Severity: LintWarning,
+			Message: `No "## Response Schema" section describing the structured response.`})
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		for _, m := range markerFormats {
+			if !strings.HasPrefix(line, m.prefix) {
+				continue
+			}
+			if loc := m.re.FindStringIndex(line); loc == nil || loc[0] != 0 {
+				out = append(out, PromptLint{Severity: LintWarning, Line: i + 1,
+					Message: "Malformed marker example; the supervisor expects " + m.format + "."})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/prompts"
+)
+
+// Where a configured prompt is read from, as reported by PromptSource.
+const (
+	PromptSourceFile     = "file"
+	PromptSourceEmbedded = "embedded"
+	PromptSourceMissing  = "missing"
+	PromptSourceDatabase = "database"
+)
+
+// PromptStoreDB keeps prompts edited on the dashboard in the database rather
+// than writing them to the prompt file (CLAUDEOPS_PROMPT_STORE=db).
+const PromptStoreDB = "db"
+
+// UsesPromptDB reports whether store selects the database prompt store.
+func UsesPromptDB(store string) bool {
+	return strings.EqualFold(strings.TrimSpace(store), PromptStoreDB)
+}
+
+// StoredPrompt returns the newest version of the prompt at path saved from
+// the dashboard when the database prompt store is in use, or nil when the
+// prompt is read from its file.
+func StoredPrompt(database *db.DB, store, path string) (*db.PromptVersion, error) {
+	if database == nil || !UsesPromptDB(store) {
+		return nil, nil
+	}
+	return database.LatestPromptVersion(path)
+}
+
+// ReadPrompt returns the prompt a session runs for path: its stored version
+// with the database prompt store, otherwise the file or the embedded
+// default.
+func ReadPrompt(database *db.DB, store, path string) (string, error) {
+	v, err := StoredPrompt(database, store, path)
+	if err != nil {
+		return "", err
+	}
+	if v != nil {
+		return v.Content, nil
+	}
+	return readPrompt(path)
+}
+
+// PromptSource reports where the prompt configured at path is read from: the
+// file itself, or the default embedded in the binary when the file does not
+// exist.
+func PromptSource(path string) string {
+	if _, err := os.Stat(path); err == nil {
+		return PromptSourceFile
+	}
+	if _, ok := prompts.Default(path); ok {
+		return PromptSourceEmbedded
+	}
+	return PromptSourceMissing
+}
+
+// readPrompt returns the prompt at path, falling back to the embedded
+// default of the same name when the file does not exist.
+func readPrompt(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return string(data), nil
+	}
+	if os.IsNotExist(err) {
+		if data, ok := prompts.Default(path); ok {
+			fmt.Printf("Prompt file %s not found, using the embedded default\n", path)
+			return string(data), nil
+		}
+	}
+	return "", err
+}
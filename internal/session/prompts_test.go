@@ -0,0 +1,102 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/prompts"
+)
+
+func TestReadPromptFallsBackToEmbedded(t *testing.T) {
+	dir := t.TempDir()
+
+	onDisk := filepath.Join(dir, "tier1-observe.md")
+	if err := os.WriteFile(onDisk, []byte("custom observe prompt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readPrompt(onDisk); err != nil || got != "custom observe prompt" {
+		t.Errorf("readPrompt(file) = %q, %v", got, err)
+	}
+	if src := PromptSource(onDisk); src != PromptSourceFile {
+		t.Errorf("PromptSource(file) = %s", src)
+	}
+
+	missing := filepath.Join(dir, "tier2-investigate.md")
+	got, err := readPrompt(missing)
+	if err != nil || !strings.Contains(got, "Tier 2") {
+		t.Errorf("expected embedded Tier 2 prompt, got %.60q, %v", got, err)
+	}
+	if src := PromptSource(missing); src != PromptSourceEmbedded {
+		t.Errorf("PromptSource(missing) = %s", src)
+	}
+
+	unknown := filepath.Join(dir, "custom.md")
+	if _, err := readPrompt(unknown); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error for a prompt with no default, got %v", err)
+	}
+	if src := PromptSource(unknown); src != PromptSourceMissing {
+		t.Errorf("PromptSource(unknown) = %s", src)
+	}
+}
+
+func TestReadPromptFromDatabaseStore(t *testing.T) {
+	_, database := testManagerWithDB(t)
+	path := filepath.Join(t.TempDir(), "tier1-observe.md")
+	if err := os.WriteFile(path, []byte("from file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.InsertPromptVersion(&db.PromptVersion{Path: path, Content: "from db", SavedAt: "2026-01-01T00:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadPrompt(database, "file", path); err != nil || got != "from file" {
+		t.Errorf("file store: ReadPrompt = %q, %v", got, err)
+	}
+	if got, err := ReadPrompt(database, "db", path); err != nil || got != "from db" {
+		t.Errorf("db store: ReadPrompt = %q, %v", got, err)
+	}
+	if got, err := ReadPrompt(database, "db", path+".other"); err == nil {
+		t.Errorf("expected a missing prompt with no stored version, got %q", got)
+	}
+}
+
+func TestLintPrompt(t *testing.T) {
+	// The shipped prompts lint clean.
+	for tier, names := range map[int][]string{
+		1: {"tier1-observe.md", "verify.md"},
+		2: {"tier2-investigate.md", "db-investigate.md", "network-investigate.md"},
+		3: {"tier3-remediate.md"},
+	} {
+		for _, name := range names {
+			data, ok := prompts.Default(name)
+			if !ok {
+				t.Fatalf("no embedded %s", name)
+			}
+			if lint := LintPrompt(tier, string(data)); len(lint) != 0 {
+				t.Errorf("%s: unexpected lint %+v", name, lint)
+			}
+		}
+	}
+
+	if lint := LintPrompt(1, "  \n"); len(lint) != 1 || lint[0].Severity != LintError {
+		t.Errorf("empty prompt: %+v", lint)
+	}
+
+	prompt := "# Tier 2\n\nInvestigate.\n\n```\n[COOLDOWN restart:jellyfin] success\n[EVENT:warning] fine\n```\n"
+	var got []string
+	for _, l := range LintPrompt(2, prompt) {
+		got = append(got, l.Severity+":"+strconv.Itoa(l.Line)+":"+strings.SplitN(l.Message, ":", 2)[0])
+	}
+	want := []string{
+		"warning:0:No handoff instructions",
+		"warning:0:No cooldown marker reminder",
+		`warning:0:No "## Response Schema" section describing the structured response.`,
+		"warning:6:Malformed marker example; the supervisor expects [COOLDOWN",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("lint =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
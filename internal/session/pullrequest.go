@@ -0,0 +1,104 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// githubAPIURL is the GitHub REST API base, replaced in tests.
+var githubAPIURL = "https://api.github.com"
+
+// openPullRequest opens a pull request from head into base on the
+// repository that remote points at. Like the git-pr skill, GitHub is
+// reached with GITHUB_TOKEN and Gitea at GITEA_URL with GITEA_TOKEN.
+// Returns the pull request's URL.
+func openPullRequest(ctx context.Context, remote, base, head, title, body string) (string, error) {
+	host, owner, repo, err := parseRemote(remote)
+	if err != nil {
+		return "", err
+	}
+	payload := map[string]string{"title": title, "body": body, "head": head, "base": base}
+
+	if giteaURL := strings.TrimRight(os.Getenv("GITEA_URL"), "/"); giteaURL != "" {
+		if u, err := url.Parse(giteaURL); err == nil && strings.EqualFold(u.Hostname(), host) {
+			token := os.Getenv("GITEA_TOKEN")
+			if token == "" {
+				return "", fmt.Errorf("no pull request: GITEA_TOKEN is not set")
+			}
+			return createPR(ctx, giteaURL+"/api/v1/repos/"+owner+"/"+repo+"/pulls", "token "+token, payload)
+		}
+	}
+	if strings.EqualFold(host, "github.com") {
+		token := os.Getenv("GITHUB_TOKEN")
+		if token == "" {
+			return "", fmt.Errorf("no pull request: GITHUB_TOKEN is not set")
+		}
+		return createPR(ctx, githubAPIURL+"/repos/"+owner+"/"+repo+"/pulls", "Bearer "+token, payload)
+	}
+	return "", fmt.Errorf("no pull request: %s is neither github.com nor GITEA_URL", host)
+}
+
+// parseRemote extracts the host, owner, and repository name from a git
+// remote URL: https://host/owner/repo.git, ssh://git@host:2222/owner/repo.git,
+// or the scp-like git@host:owner/repo.git.
+func parseRemote(remote string) (host, owner, repo string, err error) {
+	var path string
+	if strings.Contains(remote, "://") {
+		u, perr := url.Parse(remote)
+		if perr != nil {
+			return "", "", "", fmt.Errorf("parse remote: %w", perr)
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at, rest, ok := strings.Cut(remote, ":"); ok && !strings.Contains(at, "/") {
+		host, path = at, rest
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	}
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(path, ".git"), "/"), "/")
+	if host == "" || len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", "", fmt.Errorf("no pull request: cannot tell the repository from remote %q", remote)
+	}
+	return host, parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// createPR POSTs a pull request and returns its html_url.
+func createPR(ctx context.Context, endpoint, auth string, payload map[string]string) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("open pull request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("open pull request: status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	var pr struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(respBody, &pr); err != nil || pr.HTMLURL == "" {
+		return "", fmt.Errorf("open pull request: unexpected response")
+	}
+	return pr.HTMLURL, nil
+}
@@ -0,0 +1,129 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/seal"
+)
+
+// RecoverOrphanedSessions finalizes sessions left "running" by a supervisor
+// that crashed mid-session. Each is marked "interrupted", keeps whatever
+// result the CLI wrote to its log before the crash, has its log compressed
+// like that of a session that ended, has the file changes it
+// made captured for approval when it was sandboxed, and gets a warning
+// event. A self-test drill the crash cut off is marked failed. Call it at
+// startup, before any session can run.
+func (m *Manager) RecoverOrphanedSessions() {
+	defer m.recoverOrphanedDrills()
+	sessions, err := m.db.ListRunningSessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "list running sessions: %v\n", err)
+		return
+	}
+	for _, s := range sessions {
+		endedAt := time.Now().UTC()
+		salvaged := false
+		if s.LogFile != nil {
+			if info, err := os.Stat(*s.LogFile); err == nil {
+				endedAt = info.ModTime().UTC()
+			}
+			evt, err := lastResultEvent(*s.LogFile, m.db.Sealer())
+			if err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "salvage session %d: %v\n", s.ID, err)
+			}
+			if evt != nil {
+				cost, synthetic := m.resultCost(s.Model, evt)
+				if err := m.db.UpdateSessionResult(s.ID, evt.Result, cost, evt.NumTurns, evt.DurationMs); err != nil {
+					fmt.Fprintf(os.Stderr, "store salvaged result %d: %v\n", s.ID, err)
+				} else {
+					salvaged = true
+					if synthetic {
+						if err := m.db.UpdateSessionCostSynthetic(s.ID, true); err != nil {
+							fmt.Fprintf(os.Stderr, "flag synthetic cost %d: %v\n", s.ID, err)
+						}
+					}
+				}
+			}
+		}
+
+		ended := endedAt.Format(time.RFC3339)
+		if err := m.db.UpdateSession(s.ID, "interrupted", &ended, nil, s.LogFile); err != nil {
+			fmt.Fprintf(os.Stderr, "mark session %d interrupted: %v\n", s.ID, err)
+			continue
+		}
+		if err := m.db.UpdateSessionOutcome(s.ID, db.SessionInconclusive); err != nil {
+			fmt.Fprintf(os.Stderr, "mark session %d inconclusive: %v\n", s.ID, err)
+		}
+
+		if s.LogFile != nil && !strings.HasSuffix(*s.LogFile, compressedLogSuffix) {
+			if _, err := os.Stat(*s.LogFile); err == nil {
+				m.compressSessionLog(s.ID, *s.LogFile)
+			}
+		}
+		m.captureSandbox(s.ID)
+
+		msg := fmt.Sprintf("Session #%d (tier %d) was still running when the supervisor stopped unexpectedly; marked interrupted", s.ID, s.Tier)
+		if salvaged {
+			msg += " (result recovered from its log)"
+		}
+		m.emitEscalationEventLevel(s.ID, "warning", msg)
+		fmt.Println(msg)
+	}
+}
+
+// recoverOrphanedDrills marks the drills left "running" by a supervisor that
+// crashed mid-drill as failed, with a warning event for the canary.
+func (m *Manager) recoverOrphanedDrills() {
+	for {
+		d, err := m.db.GetRunningDrill()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "get running drill: %v\n", err)
+			return
+		}
+		if d == nil {
+			return
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		detail := "the supervisor stopped while the drill was running"
+		d.Status, d.Detail, d.EndedAt = "failed", &detail, &now
+		if err := m.db.UpdateDrill(d); err != nil {
+			fmt.Fprintf(os.Stderr, "mark drill %d failed: %v\n", d.ID, err)
+			return
+		}
+		msg := fmt.Sprintf("Self-test drill #%d failed: %s", d.ID, detail)
+		svc := canaryService
+		if err := m.insertEvent(&db.Event{Level: "warning", Service: &svc, Message: msg, CreatedAt: now}); err != nil {
+			fmt.Fprintf(os.Stderr, "insert drill event %d: %v\n", d.ID, err)
+		}
+		fmt.Println(msg)
+	}
+}
+
+// lastResultEvent returns the last "result" event in a session log, or nil
+// if the session never got that far. sealer opens the lines of an encrypted
+// log.
+func lastResultEvent(logPath string, sealer *seal.Sealer) (*streamEvent, error) {
+	f, err := OpenLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	var result *streamEvent
+	scanner := NewLogScanner(f)
+	for scanner.Scan() {
+		_, raw, _ := ParseTimestampedLogLine(sealer.OpenOr(scanner.Text()))
+		var evt streamEvent
+		// A crash can leave a partial last line; skip anything unparseable.
+		if json.Unmarshal([]byte(raw), &evt) != nil || evt.Type != "result" {
+			continue
+		}
+		result = &evt
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestRecoverOrphanedSessions(t *testing.T) {
+	m, database := testManagerWithDB(t)
+
+	logPath := filepath.Join(t.TempDir(), "session.log")
+	log := strings.Join([]string{
+		"2026-02-15T10:00:00Z\t" + `{"type":"system","subtype":"init"}`,
+		"2026-02-15T10:00:01Z\t" + `{"type":"result","result":"All services healthy.","total_cost_usd":0.12,"num_turns":4,"duration_ms":9000}`,
+		"2026-02-15T10:00:02Z\t" + `{"type":"assistant","message":{"con`, // cut off by the crash
+	}, "\n")
+	if err := os.WriteFile(logPath, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	salvageable, err := database.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/dev/null", Status: "running",
+		StartedAt: "2026-02-15T10:00:00Z", Trigger: "scheduled", LogFile: &logPath,
+	})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+	missingLog := filepath.Join(t.TempDir(), "missing.log")
+	lost, err := database.InsertSession(&db.Session{
+		Tier: 2, Model: "sonnet", PromptFile: "/dev/null", Status: "running",
+		StartedAt: "2026-02-15T11:00:00Z", Trigger: "escalation", LogFile: &missingLog,
+	})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+
+	m.RecoverOrphanedSessions()
+
+	s, err := database.GetSession(salvageable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != "interrupted" || s.EndedAt == nil {
+		t.Errorf("expected interrupted with end time, got %s %v", s.Status, s.EndedAt)
+	}
+	if s.Response == nil || *s.Response != "All services healthy." || s.CostUSD == nil || *s.CostUSD != 0.12 || s.NumTurns == nil || *s.NumTurns != 4 {
+		t.Errorf("expected salvaged result, got %+v", s)
+	}
+	if s.LogFile == nil || *s.LogFile != logPath+compressedLogSuffix {
+		t.Errorf("expected the log to be compressed, got %v", s.LogFile)
+	} else if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Errorf("expected the uncompressed log to be removed, got %v", err)
+	}
+
+	s, err = database.GetSession(lost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != "interrupted" || s.Response != nil {
+		t.Errorf("expected interrupted without result, got %s %v", s.Status, s.Response)
+	}
+
+	events, err := database.ListEvents(10, 0, db.EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected a warning per session, got %d", len(events))
+	}
+	for _, e := range events {
+		if e.Level != "warning" {
+			t.Errorf("expected warning, got %s: %s", e.Level, e.Message)
+		}
+		if *e.SessionID == salvageable && !strings.Contains(e.Message, "result recovered") {
+			t.Errorf("expected salvage noted in %q", e.Message)
+		}
+	}
+
+	if running, _ := database.ListRunningSessions(); len(running) != 0 {
+		t.Errorf("expected no running sessions left, got %d", len(running))
+	}
+}
+
+func TestRecoverOrphanedDrills(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	drillID, err := database.InsertDrill(&db.Drill{Status: "running", StartedAt: "2026-02-15T10:00:00Z"})
+	if err != nil {
+		t.Fatalf("InsertDrill: %v", err)
+	}
+
+	m.RecoverOrphanedSessions()
+
+	d, err := database.GetDrill(drillID)
+	if err != nil || d == nil {
+		t.Fatalf("GetDrill: %v", err)
+	}
+	if d.Status != "failed" || d.EndedAt == nil || d.Detail == nil || !strings.Contains(*d.Detail, "supervisor stopped") {
+		t.Errorf("expected the drill marked failed, got %+v", d)
+	}
+	if running, _ := database.GetRunningDrill(); running != nil {
+		t.Errorf("expected no running drill left, got %+v", running)
+	}
+	events, _ := database.ListEvents(10, 0, db.EventFilter{})
+	if len(events) != 1 || events[0].Level != "warning" || !strings.Contains(events[0].Message, fmt.Sprintf("drill #%d failed", drillID)) {
+		t.Errorf("events = %+v", events)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
 )
 
 // Governing: SPEC-0014 REQ "Log Redaction of Credential Values" — replaces BROWSER_CRED_* values with [REDACTED:...] placeholders
@@ -33,29 +36,66 @@ func NewRedactionFilter() *RedactionFilter {
 		if !strings.HasPrefix(name, "BROWSER_CRED_") {
 			continue
 		}
-		if len(value) < 4 {
-			fmt.Fprintf(os.Stderr, "warning: %s value is shorter than 4 characters; false-positive redaction risk\n", name)
-		}
-		rf.replacements[value] = "[REDACTED:" + name + "]"
-		// Also add URL-encoded variant if it differs from the raw value.
-		encoded := url.QueryEscape(value)
-		if encoded != value {
-			rf.replacements[encoded] = "[REDACTED:" + name + ":urlencoded]"
-		}
+		rf.add(value, name)
 	}
 	return rf
 }
 
+// add registers the value of the variable name for redaction. Values
+// shorter than 4 characters trigger a warning about false-positive risk.
+func (rf *RedactionFilter) add(value, name string) {
+	if len(value) < 4 {
+		fmt.Fprintf(os.Stderr, "warning: %s value is shorter than 4 characters; false-positive redaction risk\n", name)
+	}
+	rf.replacements[value] = "[REDACTED:" + name + "]"
+	// Also add URL-encoded variant if it differs from the raw value.
+	encoded := url.QueryEscape(value)
+	if encoded != value {
+		rf.replacements[encoded] = "[REDACTED:" + name + ":urlencoded]"
+	}
+}
+
 // Redact replaces all known credential values in input with their
 // [REDACTED:...] placeholders. If no BROWSER_CRED_* variables were found
 // at construction time, this is a no-op passthrough.
 func (rf *RedactionFilter) Redact(input string) string {
+	return rf.RedactCount(input, nil)
+}
+
+// RedactCount is Redact that also adds the number of values it replaced to
+// counts (if not nil), keyed by rule: the name inside the placeholder, e.g.
+// "BROWSER_CRED_SONARR_PASS" or "BROWSER_CRED_SONARR_PASS:urlencoded".
+func (rf *RedactionFilter) RedactCount(input string, counts map[string]int) string {
 	if len(rf.replacements) == 0 {
 		return input
 	}
 	result := input
 	for value, placeholder := range rf.replacements {
+		n := strings.Count(result, value)
+		if n == 0 {
+			continue
+		}
 		result = strings.ReplaceAll(result, value, placeholder)
+		if counts != nil {
+			counts[strings.TrimSuffix(strings.TrimPrefix(placeholder, "[REDACTED:"), "]")] += n
+		}
 	}
 	return result
 }
+
+// saveRedactions records how many values each rule redacted from a
+// session's output, so a sudden rise, a sign that something is leaking
+// credentials into tool output, shows up on the session and in the stats.
+func (m *Manager) saveRedactions(sessionID int64, counts map[string]int) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for rule, n := range counts {
+		if _, err := m.db.InsertSessionRedaction(&db.SessionRedaction{
+			SessionID: sessionID,
+			Rule:      rule,
+			Count:     n,
+			CreatedAt: now,
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+		}
+	}
+}
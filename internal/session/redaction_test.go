@@ -1,8 +1,12 @@
 package session
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/testkit"
 )
 
 func TestRedactionFilter_RawCredential(t *testing.T) {
@@ -83,3 +87,75 @@ func TestRedactionFilter_MultipleCredentials(t *testing.T) {
 		t.Errorf("expected pass placeholder, got: %s", got)
 	}
 }
+
+func TestRedactionFilter_Count(t *testing.T) {
+	t.Setenv("BROWSER_CRED_SONARR_PASS", "p@ssw0rd")
+
+	rf := NewRedactionFilter()
+	counts := make(map[string]int)
+	rf.RedactCount("p@ssw0rd and p@ssw0rd", counts)
+	rf.RedactCount("/login?pass=p%40ssw0rd", counts)
+	rf.RedactCount("nothing here", counts)
+
+	if counts["BROWSER_CRED_SONARR_PASS"] != 2 || counts["BROWSER_CRED_SONARR_PASS:urlencoded"] != 1 || len(counts) != 2 {
+		t.Errorf("counts = %v", counts)
+	}
+}
+
+func TestRunTierSavesRedactions(t *testing.T) {
+	t.Setenv("BROWSER_CRED_SONARR_PASS", "s3cretP@ss")
+	m, database := testManagerWithDB(t)
+	m.redactor = NewRedactionFilter()
+	m.runner = &pipeRunner{
+		events: []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"type":"user","message":{"content":[{"type":"tool_result","content":"password=s3cretP@ss"}]}}`,
+			`{"type":"result","result":"Logged in with s3cretP@ss.","is_error":false}`,
+		},
+		resultIdx: 2,
+	}
+
+	id, _, err := m.runTier(context.Background(), 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	redactions, err := database.ListSessionRedactions(id)
+	if err != nil {
+		t.Fatalf("ListSessionRedactions: %v", err)
+	}
+	if len(redactions) != 1 || redactions[0].Rule != "BROWSER_CRED_SONARR_PASS" || redactions[0].Count != 2 {
+		t.Errorf("redactions = %+v", redactions)
+	}
+}
+
+func TestTimedOutSessionSavesRedactions(t *testing.T) {
+	t.Setenv("BROWSER_CRED_SONARR_PASS", "s3cretP@ss")
+	m, database := testManagerWithDB(t)
+	m.redactor = NewRedactionFilter()
+	m.runner = testkit.NewRunner(testkit.Script{Events: []testkit.Event{
+		testkit.Init("haiku"),
+		testkit.ToolResult("t1", "password=s3cretP@ss"),
+		testkit.Text("Still working.").After(10 * time.Second),
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	id, _, err := m.runTier(ctx, 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err == nil {
+		t.Fatal("expected the session to time out")
+	}
+	s, _ := database.GetSession(id)
+	if s == nil || s.Status != "timed_out" {
+		t.Fatalf("session = %+v, want timed_out", s)
+	}
+	if s.LogFile == nil || !strings.HasSuffix(*s.LogFile, compressedLogSuffix) {
+		t.Errorf("expected the timed out session's log to be compressed, got %v", s.LogFile)
+	}
+	redactions, err := database.ListSessionRedactions(id)
+	if err != nil {
+		t.Fatalf("ListSessionRedactions: %v", err)
+	}
+	if len(redactions) != 1 || redactions[0].Count != 1 {
+		t.Errorf("redactions = %+v", redactions)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"io"
 	"os"
 	"os/exec"
-	"syscall"
 )
 
 // ProcessRunner abstracts the spawning of a Claude CLI subprocess so that
@@ -14,8 +13,11 @@ import (
 // Governing: SPEC-0008 REQ-7 — subprocess lifecycle management (startup, completion, crash handling).
 // Governing: ADR-0023 "AllowedTools-Based Tier Enforcement" — disallowedTools param for command-prefix blocklisting.
 // Governing: ADR-0030, SPEC-0031 REQ-4 — schemaPath param for --json-schema structured output.
+// dir is the working directory for the tier (empty inherits the supervisor's),
+// and env holds NAME=value pairs added to the process environment, which
+// otherwise is the supervisor's without its secrets (see childEnv).
 type ProcessRunner interface {
-	Start(ctx context.Context, model string, promptContent string, allowedTools string, disallowedTools string, appendSystemPrompt string, schemaPath string) (stdout io.ReadCloser, wait func() error, err error)
+	Start(ctx context.Context, model string, promptContent string, allowedTools string, disallowedTools string, appendSystemPrompt string, schemaPath string, dir string, env []string) (stdout io.ReadCloser, wait func() error, err error)
 }
 
 // CLIRunner implements ProcessRunner by spawning the real `claude` CLI binary.
@@ -30,7 +32,29 @@ type CLIRunner struct{}
 // Governing: SPEC-0008 REQ-5 "CLI subprocess creation"
 // — passes model, prompt content, allowed tools, disallowed tools, schema path,
 // and system prompt arguments matching the entrypoint.sh invocation pattern via os/exec.Command.
-func (r *CLIRunner) Start(ctx context.Context, model string, promptContent string, allowedTools string, disallowedTools string, appendSystemPrompt string, schemaPath string) (io.ReadCloser, func() error, error) {
+func (r *CLIRunner) Start(ctx context.Context, model string, promptContent string, allowedTools string, disallowedTools string, appendSystemPrompt string, schemaPath string, dir string, env []string) (io.ReadCloser, func() error, error) {
+	args := cliArgs(model, promptContent, allowedTools, disallowedTools, appendSystemPrompt, schemaPath)
+	cmd := exec.CommandContext(ctx, "claude", args...)
+	isolateProcess(cmd)
+	cmd.Stderr = os.Stderr
+	cmd.Dir = dir
+	cmd.Env = childEnv(os.Environ(), env)
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	return stdoutPipe, cmd.Wait, nil
+}
+
+// cliArgs returns the claude CLI arguments for a session. It is shared by
+// CLIRunner and the session's recorded invocation.
+func cliArgs(model, promptContent, allowedTools, disallowedTools, appendSystemPrompt, schemaPath string) []string {
 	// Governing: SPEC-0010 REQ-5 "Tool filtering via --allowedTools"
 	// Governing: ADR-0023 "AllowedTools-Based Tier Enforcement"
 	// — enforces tool restrictions at CLI runtime via --allowedTools whitelist
@@ -62,19 +86,5 @@ func (r *CLIRunner) Start(ctx context.Context, model string, promptContent strin
 	// extra directive is needed to suppress coding-assistant behaviour. We only inject
 	// the dynamic environment context (env vars, memory, handoff) here.
 	args = append(args, "--append-system-prompt", "Environment: "+appendSystemPrompt)
-
-	cmd := exec.CommandContext(ctx, "claude", args...)
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true} // Governing: SPEC-0008 REQ-7 — process group isolation for signal forwarding.
-	cmd.Stderr = os.Stderr
-
-	stdoutPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return nil, nil, err
-	}
-
-	return stdoutPipe, cmd.Wait, nil
+	return args
 }
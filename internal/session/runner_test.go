@@ -13,7 +13,7 @@ type mockRunner struct {
 	err    error
 }
 
-func (m *mockRunner) Start(ctx context.Context, model string, promptContent string, allowedTools string, disallowedTools string, appendSystemPrompt string, schemaPath string) (io.ReadCloser, func() error, error) {
+func (m *mockRunner) Start(ctx context.Context, model string, promptContent string, allowedTools string, disallowedTools string, appendSystemPrompt string, schemaPath string, _ string, _ []string) (io.ReadCloser, func() error, error) {
 	if m.err != nil {
 		return nil, nil, m.err
 	}
@@ -23,7 +23,7 @@ func (m *mockRunner) Start(ctx context.Context, model string, promptContent stri
 
 func TestMockRunnerReturnsOutput(t *testing.T) {
 	runner := &mockRunner{output: `{"type":"system","subtype":"init"}` + "\n"}
-	stdout, wait, err := runner.Start(context.Background(), "haiku", "check health", "Bash,Read", "", "env=test", "")
+	stdout, wait, err := runner.Start(context.Background(), "haiku", "check health", "Bash,Read", "", "env=test", "", "", nil)
 	if err != nil {
 		t.Fatalf("Start: %v", err)
 	}
@@ -43,7 +43,7 @@ func TestMockRunnerReturnsOutput(t *testing.T) {
 
 func TestMockRunnerStartError(t *testing.T) {
 	runner := &mockRunner{err: io.ErrClosedPipe}
-	_, _, err := runner.Start(context.Background(), "haiku", "check health", "Bash", "", "env=test", "")
+	_, _, err := runner.Start(context.Background(), "haiku", "check health", "Bash", "", "env=test", "", "", nil)
 	if err == nil {
 		t.Fatal("expected error from Start")
 	}
@@ -74,6 +74,8 @@ func TestMockRunnerAcceptsSchemaPath(t *testing.T) {
 		"",
 		"env=test",
 		"/app/schemas/agent-response.json",
+		"",
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("Start with schemaPath: %v", err)
@@ -106,6 +108,8 @@ func TestMockRunnerEmptySchemaPath(t *testing.T) {
 		"",
 		"env=test",
 		"", // empty schemaPath — should skip --json-schema
+		"",
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("Start with empty schemaPath: %v", err)
@@ -132,7 +136,7 @@ type argCapturingRunner struct {
 	capturedSchemaPath string
 }
 
-func (a *argCapturingRunner) Start(_ context.Context, _ string, _ string, _ string, _ string, _ string, schemaPath string) (io.ReadCloser, func() error, error) {
+func (a *argCapturingRunner) Start(_ context.Context, _ string, _ string, _ string, _ string, _ string, schemaPath string, _ string, _ []string) (io.ReadCloser, func() error, error) {
 	a.capturedSchemaPath = schemaPath
 	r := io.NopCloser(strings.NewReader(`{"type":"result","result":"done","is_error":false}` + "\n"))
 	return r, func() error { return nil }, nil
@@ -170,6 +174,8 @@ func TestSchemaPathPassedToRunner(t *testing.T) {
 				"",
 				"env=test",
 				tc.schemaPath,
+				"",
+				nil,
 			)
 			if err != nil {
 				t.Fatalf("Start: %v", err)
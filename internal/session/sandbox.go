@@ -0,0 +1,87 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/sandbox"
+)
+
+// sandboxed reports whether a tier's file changes are captured as a
+// proposal rather than left in place. Tier 1 is read-only and dry runs make
+// no changes.
+func (m *Manager) sandboxed(tier int) bool {
+	return m.cfg.Sandbox && !m.cfg.DryRun && tier >= 2
+}
+
+// sandboxRoots returns the directories a sandboxed session's changes are
+// captured from: the repos directory and the tier's working directory.
+func (m *Manager) sandboxRoots(tier int) []string {
+	roots := []string{m.cfg.ReposDir}
+	if dir := m.tierDir(tier); dir != "" {
+		roots = append(roots, dir)
+	}
+	return roots
+}
+
+// sandboxContext tells a sandboxed agent that its file changes are a
+// proposal.
+func (m *Manager) sandboxContext(tier int) string {
+	return fmt.Sprintf("## Sandbox\n\nFile changes under %s are captured as a proposal when this session ends and are only applied once an operator approves them. Make the changes you recommend, and explain each one in your report.",
+		strings.Join(m.sandboxRoots(tier), ", "))
+}
+
+// sandboxDir is where a session's sandbox snapshot is kept. It is on disk
+// so a session interrupted by a crash is captured on restart.
+func (m *Manager) sandboxDir(sessionID int64) string {
+	return filepath.Join(m.cfg.StateDir, "sandbox", fmt.Sprintf("session-%d", sessionID))
+}
+
+// startSandbox snapshots the sandboxed directories before a session runs.
+func (m *Manager) startSandbox(sessionID int64, tier int) error {
+	_, err := sandbox.Take(m.sandboxDir(sessionID), m.sandboxRoots(tier)...)
+	return err
+}
+
+// captureSandbox restores the files a sandboxed session changed and
+// records the changes as a pending change report. Sessions without a
+// snapshot are skipped.
+func (m *Manager) captureSandbox(sessionID int64) {
+	snap, err := sandbox.Load(m.sandboxDir(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: sandbox: %v\n", sessionID, err)
+		return
+	}
+
+	changes, err := snap.Capture()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: sandbox: %v\n", sessionID, err)
+	}
+	if len(changes) == 0 {
+		return
+	}
+	report := &db.ChangeReport{SessionID: sessionID, CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+	for _, c := range changes {
+		report.Files = append(report.Files, db.ChangeFile{
+			Path:       c.Path,
+			Kind:       c.Kind,
+			Mode:       uint32(c.Mode),
+			OldContent: c.Old,
+			NewContent: c.New,
+			Diff:       m.redactor.Redact(sandbox.Diff(c)),
+		})
+	}
+	if _, err := m.db.InsertChangeReport(report); err != nil {
+		fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+		return
+	}
+	fmt.Printf("[session %d] Captured %d proposed file change(s) for approval\n", sessionID, len(changes))
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// editingRunner edits a file as the agent would, then streams a result.
+type editingRunner struct {
+	pipeRunner
+	edit func()
+}
+
+func (r *editingRunner) Start(ctx context.Context, model, prompt, allowed, disallowed, appendSystemPrompt, schemaPath, dir string, env []string) (io.ReadCloser, func() error, error) {
+	r.edit()
+	return r.pipeRunner.Start(ctx, model, prompt, allowed, disallowed, appendSystemPrompt, schemaPath, dir, env)
+}
+
+func TestSandboxCapturesChanges(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.Sandbox = true
+	compose := filepath.Join(m.cfg.ReposDir, "infra", "compose.yaml")
+	if err := os.MkdirAll(filepath.Dir(compose), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(compose, []byte("image: jellyfin:10.8\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m.runner = &editingRunner{
+		pipeRunner: pipeRunner{events: []string{`{"type":"result","result":"Pinned jellyfin.","is_error":false}`}},
+		edit: func() {
+			if err := os.WriteFile(compose, []byte("image: jellyfin:10.9\n"), 0o644); err != nil {
+				t.Error(err)
+			}
+		},
+	}
+
+	id, _, err := m.runTier(context.Background(), 3, "opus", "/dev/null", nil, "", nil, "manual", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	if b, _ := os.ReadFile(compose); string(b) != "image: jellyfin:10.8\n" {
+		t.Errorf("expected compose.yaml restored, got %q", b)
+	}
+	report, err := database.GetSessionChangeReport(id)
+	if err != nil || report == nil || len(report.Files) != 1 {
+		t.Fatalf("GetSessionChangeReport = %+v, %v", report, err)
+	}
+	f := report.Files[0]
+	if report.Status != "pending" || f.Path != compose || f.Kind != "modified" || !strings.Contains(f.Diff, "+image: jellyfin:10.9") {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(m.cfg.StateDir, "sandbox", "session-1")); !os.IsNotExist(err) {
+		t.Errorf("expected the snapshot removed, got %v", err)
+	}
+
+	sess, _ := database.GetSession(id)
+	if inv := ParseInvocation(sess.Invocation); inv == nil || !strings.Contains(inv.SystemPrompt, "## Sandbox") {
+		t.Error("expected the agent told it runs in the sandbox")
+	}
+
+	// Tier 1 is never sandboxed.
+	if m.sandboxed(1) {
+		t.Error("expected Tier 1 unsandboxed")
+	}
+}
+
+func TestRecoverCapturesSandboxOfCrashedSession(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.Sandbox = true
+	compose := filepath.Join(m.cfg.ReposDir, "compose.yaml")
+	if err := os.WriteFile(compose, []byte("image: jellyfin:10.8\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	id, err := database.InsertSession(&db.Session{
+		Tier: 3, Model: "opus", PromptFile: "/dev/null", Status: "running",
+		StartedAt: "2026-02-15T10:00:00Z", Trigger: "escalation",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.startSandbox(id, 3); err != nil {
+		t.Fatal(err)
+	}
+	// The agent edits the file, then the supervisor crashes.
+	if err := os.WriteFile(compose, []byte("image: jellyfin:10.9\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m.RecoverOrphanedSessions()
+
+	if b, _ := os.ReadFile(compose); string(b) != "image: jellyfin:10.8\n" {
+		t.Errorf("expected compose.yaml restored, got %q", b)
+	}
+	report, err := database.GetSessionChangeReport(id)
+	if err != nil || report == nil || len(report.Files) != 1 || report.Files[0].Kind != "modified" {
+		t.Fatalf("GetSessionChangeReport = %+v, %v", report, err)
+	}
+	if _, err := os.Stat(m.sandboxDir(id)); !os.IsNotExist(err) {
+		t.Errorf("expected the snapshot removed, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// nextScheduledRun returns when the scheduled run after one that finished
+// at t is due.
+func (m *Manager) nextScheduledRun(t time.Time) time.Time {
+	if m.Scheduler == nil {
+		return t.Add(time.Duration(m.cfg.Interval) * time.Second)
+	}
+	return m.Scheduler.Next(t)
+}
+
+// observeScheduled feeds the outcome of the scheduled run whose first
+// session is sessionID to the adaptive interval: a run that found everything
+// healthy, or was skipped because recent sessions had, counts toward
+// stretching it, and any other outcome tightens it.
+func (m *Manager) observeScheduled(sessionID int64) {
+	if m.Scheduler == nil || !m.Scheduler.Adaptive() || sessionID == 0 {
+		return
+	}
+	sess, err := m.db.GetSession(sessionID)
+	if err != nil || sess == nil {
+		fmt.Fprintf(os.Stderr, "adaptive interval: load session %d: %v\n", sessionID, err)
+		return
+	}
+	healthy := sess.Status == "skipped" || (sess.Outcome != nil && *sess.Outcome == db.SessionHealthy)
+	detail := fmt.Sprintf("session #%d was %s", sessionID, sess.Status)
+	if sess.Outcome != nil {
+		detail = fmt.Sprintf("session #%d found %s", sessionID, *sess.Outcome)
+	}
+	m.Scheduler.Observe(time.Now(), healthy, detail)
+	if st := m.Scheduler.AdaptiveState(); st != nil {
+		fmt.Printf("[%s] Adaptive interval %ds: %s\n", time.Now().UTC().Format(time.RFC3339), st.IntervalSeconds, st.Reason)
+	}
+}
+
+// setNextRun records when the next scheduled run is due; the zero time
+// means the manager is not waiting for one.
+func (m *Manager) setNextRun(t time.Time) {
+	m.mu.Lock()
+	m.nextRun = t
+	m.mu.Unlock()
+}
+
+// NextRun returns when the next scheduled run is due. ok is false while no
+// scheduled run is pending: before the first run finishes, while a scheduled
+// chain runs, and after shutdown begins.
+func (m *Manager) NextRun() (next time.Time, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nextRun, !m.nextRun.IsZero()
+}
+
+// RunNow ends the wait for the next scheduled run so it starts immediately,
+// or as soon as the ad-hoc session currently running finishes. It does not
+// wait for the run to start.
+func (m *Manager) RunNow() error {
+	if m.Draining() {
+		return fmt.Errorf("shutting down")
+	}
+	if _, ok := m.NextRun(); !ok {
+		return fmt.Errorf("no scheduled run is pending")
+	}
+	select {
+	case m.runNowCh <- struct{}{}:
+		return nil
+	default:
+		return fmt.Errorf("run already requested")
+	}
+}
@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/scheduler"
+)
+
+func TestRunNowEndsIntervalWait(t *testing.T) {
+	m, _ := testManager(t)
+	m.cfg.Interval = 3600
+
+	if _, ok := m.NextRun(); ok {
+		t.Fatal("expected no next run before the wait starts")
+	}
+	if err := m.RunNow(); err == nil {
+		t.Fatal("expected run-now to be refused while no run is pending")
+	}
+
+	done := make(chan bool, 1)
+	start := time.Now()
+	go func() { done <- m.waitForInterval(context.Background()) }()
+
+	var next time.Time
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		var ok bool
+		if next, ok = m.NextRun(); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("next run never reported")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if d := next.Sub(start); d < 3599*time.Second || d > 3601*time.Second {
+		t.Errorf("next run is %s after the wait started, want the interval", d)
+	}
+
+	if err := m.RunNow(); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("expected the wait to end with a scheduled run")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunNow did not end the wait")
+	}
+	if _, ok := m.NextRun(); ok {
+		t.Error("expected next run to clear once the wait ends")
+	}
+}
+
+func TestObserveScheduled(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.Interval, m.cfg.IntervalMax, m.cfg.AdaptiveRuns = 3600, 4*3600, 1
+	sched, err := scheduler.New(m.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Scheduler = sched
+	insert := func(status, outcome string) int64 {
+		id, err := database.InsertSession(&db.Session{Tier: 1, Model: "haiku", PromptFile: "/p.md", Status: status, StartedAt: time.Now().UTC().Format(time.RFC3339), Trigger: "scheduled"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if outcome != "" {
+			if err := database.UpdateSessionOutcome(id, outcome); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return id
+	}
+
+	m.observeScheduled(insert("completed", db.SessionHealthy))
+	m.observeScheduled(insert("skipped", ""))
+	if got := sched.Interval(); got != 4*time.Hour {
+		t.Errorf("after healthy and skipped runs: %s, want 4h", got)
+	}
+	escalated := insert("escalated", db.SessionIssuesObserved)
+	m.observeScheduled(escalated)
+	if st := sched.AdaptiveState(); st.IntervalSeconds != 7200 || !strings.HasPrefix(st.Reason, fmt.Sprintf("session #%d found issues-observed", escalated)) {
+		t.Errorf("after an escalated run: %+v", st)
+	}
+	m.observeScheduled(0)
+	if got := sched.Interval(); got != 2*time.Hour {
+		t.Errorf("a run that never started changed the interval to %s", got)
+	}
+}
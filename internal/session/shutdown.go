@@ -0,0 +1,145 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// InterruptedChainKey is the config key holding the escalation chain a
+// shutdown interrupted, as JSON. It is empty when there is none.
+const InterruptedChainKey = "interrupted_chain"
+
+// ChainStart describes where an escalation chain begins: the first tier and
+// the context it is given.
+type ChainStart struct {
+	Tier       int      `json:"tier"`
+	Trigger    string   `json:"trigger"`
+	Prompt     *string  `json:"prompt,omitempty"`      // ad-hoc prompt, used at tier 1
+	PromptFile string   `json:"prompt_file,omitempty"` // replaces the tier's prompt file
+	Context    string   `json:"context,omitempty"`
+	Services   []string `json:"services,omitempty"`
+	ParentID   *int64   `json:"parent_id,omitempty"`
+	// ApprovalID is the approval request that cleared a two-person
+	// remediation, so the approval gate does not hold it again.
+	ApprovalID int64 `json:"approval_id,omitempty"`
+}
+
+// InterruptedChain is an escalation chain that a shutdown stopped before it
+// finished. Resume continues it at the tier it did not get to run; Origin
+// reruns it from the beginning.
+type InterruptedChain struct {
+	SessionID     int64      `json:"session_id"` // last session the chain ran
+	InterruptedAt string     `json:"interrupted_at"`
+	Origin        ChainStart `json:"origin"`
+	Resume        ChainStart `json:"resume"`
+}
+
+// ReadInterruptedChain returns the chain recorded by the last shutdown, or nil
+// if there is none.
+func ReadInterruptedChain(database *db.DB) (*InterruptedChain, error) {
+	raw, err := database.GetConfig(InterruptedChainKey, "")
+	if err != nil || raw == "" {
+		return nil, err
+	}
+	var c InterruptedChain
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", InterruptedChainKey, err)
+	}
+	return &c, nil
+}
+
+// ClearInterruptedChain forgets the chain recorded by the last shutdown.
+func ClearInterruptedChain(database *db.DB) error {
+	return database.SetConfig(InterruptedChainKey, "")
+}
+
+// Drain stops the manager from starting anything new: the scheduler loop
+// returns once the in-flight tier finishes, an escalation chain stops before
+// its next tier, and triggers are refused. The caller bounds the wait by
+// cancelling Run's context.
+func (m *Manager) Drain() {
+	m.drainOnce.Do(func() { close(m.drainCh) })
+}
+
+// Draining reports whether Drain has been called.
+func (m *Manager) Draining() bool {
+	select {
+	case <-m.drainCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResumeInterruptedChain queues the chain recorded by the last shutdown,
+// continuing at the tier it stopped before, or rerunning it from the start
+// when rerun is set. The record is cleared once queued. It does not wait for
+// the chain to start.
+func (m *Manager) ResumeInterruptedChain(rerun bool) error {
+	if m.Draining() {
+		return fmt.Errorf("shutting down")
+	}
+	c, err := ReadInterruptedChain(m.db)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return fmt.Errorf("no interrupted chain")
+	}
+	if m.IsRunning() {
+		return fmt.Errorf("session already running")
+	}
+	start := c.Resume
+	if rerun {
+		start = c.Origin
+	}
+	select {
+	case m.resumeCh <- start:
+	default:
+		return fmt.Errorf("trigger queue full")
+	}
+	return ClearInterruptedChain(m.db)
+}
+
+// ReportInterruptedChain logs a chain left behind by the previous shutdown
+// so the operator knows it can be resumed from the dashboard.
+func (m *Manager) ReportInterruptedChain() {
+	c, err := ReadInterruptedChain(m.db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read interrupted chain: %v\n", err)
+		return
+	}
+	if c == nil {
+		return
+	}
+	fmt.Printf("Shutdown at %s interrupted an escalation chain at tier %d (session #%d); resume or rerun it from the dashboard.\n",
+		c.InterruptedAt, c.Resume.Tier, c.SessionID)
+}
+
+// saveInterruptedChain records a chain that the drain stopped at resume.
+// Drills are not recorded: an abandoned drill is reported as failed instead.
+func (m *Manager) saveInterruptedChain(sessionID int64, origin, resume ChainStart) {
+	if origin.Trigger == "drill" {
+		return
+	}
+	data, err := json.Marshal(InterruptedChain{
+		SessionID:     sessionID,
+		InterruptedAt: time.Now().UTC().Format(time.RFC3339),
+		Origin:        origin,
+		Resume:        resume,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "marshal interrupted chain: %v\n", err)
+		return
+	}
+	if err := m.db.SetConfig(InterruptedChainKey, string(data)); err != nil {
+		fmt.Fprintf(os.Stderr, "set %s: %v\n", InterruptedChainKey, err)
+		return
+	}
+	m.emitEscalationEventLevel(sessionID, "warning", fmt.Sprintf(
+		"Shutdown interrupted the escalation chain at tier %d; resume or rerun it from the dashboard after restart", resume.Tier))
+}
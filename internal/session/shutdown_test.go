@@ -0,0 +1,92 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/hub"
+)
+
+func TestRunChainStopsWhenDraining(t *testing.T) {
+	escalating := `{"type":"result","result":"Jellyfin down.","is_error":false,"structured_output":{"summary":"jellyfin down","events":[],"memories":[],"escalation":{"needed":true,"reason":"jellyfin down"},"services_checked":[{"name":"jellyfin","status":"down"}]}}`
+	runner := &pipeRunner{
+		events:    []string{`{"type":"system","subtype":"init"}`, escalating},
+		resultIdx: 1,
+	}
+
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 2
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.runner = runner
+	// Shutdown begins while tier 1 is in flight.
+	m.PreSessionHook = func() error {
+		m.Drain()
+		return nil
+	}
+
+	rootID := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	sessions, err := database.ListSessions(10, 0)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("expected only tier 1 to run, got %d sessions (%v)", len(sessions), err)
+	}
+	c, err := ReadInterruptedChain(database)
+	if err != nil || c == nil {
+		t.Fatalf("ReadInterruptedChain: %+v %v", c, err)
+	}
+	if c.SessionID != rootID || c.Origin.Tier != 1 || c.Origin.Trigger != "scheduled" {
+		t.Errorf("unexpected chain %+v", c)
+	}
+	if c.Resume.Tier != 2 || c.Resume.Trigger != "escalation" || c.Resume.ParentID == nil || *c.Resume.ParentID != rootID {
+		t.Errorf("unexpected resume point %+v", c.Resume)
+	}
+	if len(c.Resume.Services) != 1 || c.Resume.Services[0] != "jellyfin" || c.Resume.Context == "" {
+		t.Errorf("resume point lost the handoff: %+v", c.Resume)
+	}
+
+	if err := m.ResumeInterruptedChain(false); err == nil {
+		t.Error("expected resume to be refused while draining")
+	}
+
+	// After restart, resuming runs tier 2 as a child of the interrupted session.
+	restarted := New(m.cfg, database, hub.New(), runner)
+	if err := restarted.ResumeInterruptedChain(false); err != nil {
+		t.Fatalf("ResumeInterruptedChain: %v", err)
+	}
+	if c, _ := ReadInterruptedChain(database); c != nil {
+		t.Errorf("expected record to be cleared, got %+v", c)
+	}
+	restarted.runChain(context.Background(), <-restarted.resumeCh)
+
+	sessions, err = database.ListSessions(10, 0)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("expected resumed tier 2 session, got %d sessions (%v)", len(sessions), err)
+	}
+	resumed := sessions[0]
+	if resumed.ID == rootID {
+		resumed = sessions[1]
+	}
+	if resumed.Tier != 2 || resumed.ParentSessionID == nil || *resumed.ParentSessionID != rootID {
+		t.Errorf("unexpected resumed session %+v", resumed)
+	}
+}
+
+func TestDrainRefusesTriggers(t *testing.T) {
+	m, _ := testManager(t)
+	m.Drain()
+	m.Drain() // idempotent
+
+	if !m.Draining() {
+		t.Fatal("expected Draining after Drain")
+	}
+	if _, err := m.TriggerAdHoc("check jellyfin", 1, "manual"); err == nil {
+		t.Error("expected ad-hoc trigger to be refused")
+	}
+	if m.TriggerPulse([]string{"jellyfin"}, "probe failed") {
+		t.Error("expected pulse trigger to be refused")
+	}
+	if m.waitForInterval(context.Background()) {
+		t.Error("expected interval wait to end when draining")
+	}
+}
@@ -0,0 +1,247 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/policy"
+)
+
+// SimulationRequest is a hypothetical handoff: a tier asking the supervisor
+// to escalate for some services.
+type SimulationRequest struct {
+	// FromTier is the tier that would write the handoff (default 1).
+	FromTier         int      `json:"from_tier"`
+	RecommendedTier  int      `json:"recommended_tier"`
+	ServicesAffected []string `json:"services_affected"`
+	// Context stands in for the handoff's findings, which policy rules and
+	// Tier 2 prompt selection look at.
+	Context string `json:"context"`
+	// Trigger is the chain's trigger (default "scheduled").
+	Trigger string `json:"trigger"`
+}
+
+// Simulation outcomes.
+const (
+	SimulationEscalate = "escalate" // the next tier would run
+	SimulationApproval = "approval" // held for two-person approval
+	SimulationBlocked  = "blocked"  // the chain would stop here
+	SimulationVerify   = "verify"   // handed back to a lower tier to verify
+)
+
+// Simulation is what the supervisor would do with a handoff, given the
+// current config, policy, and cooldown state. Nothing is run or recorded.
+type Simulation struct {
+	Outcome string `json:"outcome"`
+	// Tier, Model, and Prompt describe the session that would run next; they
+	// are empty when the escalation is blocked.
+	Tier   int    `json:"tier,omitempty"`
+	Model  string `json:"model,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+	// Steps are the supervisor's checks in the order it makes them.
+	Steps         []SimulationStep  `json:"steps"`
+	PolicyResults []SimulatedPolicy `json:"policy_results"`
+	Actions       []SimulatedAction `json:"actions"`
+	Cost24hUSD    float64           `json:"cost_24h_usd"`
+}
+
+// SimulationStep is one check the supervisor makes before escalating.
+type SimulationStep struct {
+	Check   string `json:"check"`   // handoff, dry_run, tier_skip, policy, escalation_cooldown, prompt, approval, shutdown
+	Outcome string `json:"outcome"` // pass, cap, block, hold, info
+	Detail  string `json:"detail"`
+}
+
+// SimulatedPolicy is one policy rule's result in a simulation.
+type SimulatedPolicy struct {
+	Point   string `json:"point"`
+	Service string `json:"service,omitempty"` // cooldown rules only
+	Rule    string `json:"rule"`
+	Matched bool   `json:"matched"`
+	Action  string `json:"action"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// SimulatedAction is whether the escalated session could remediate an
+// affected service: the cooldown budget left and the cooldown policy.
+type SimulatedAction struct {
+	Service string         `json:"service"`
+	Action  string         `json:"action"`
+	Allowed bool           `json:"allowed"`
+	Budget  CooldownBudget `json:"budget"`
+	Detail  string         `json:"detail,omitempty"`
+}
+
+// Simulate works out what the supervisor would do if a tier handed off req,
+// following the same checks as a real escalation. It reads state but runs no
+// session and records nothing.
+func (m *Manager) Simulate(req SimulationRequest) (*Simulation, error) {
+	if req.FromTier == 0 {
+		req.FromTier = 1
+	}
+	if req.Trigger == "" {
+		req.Trigger = "scheduled"
+	}
+	sim := &Simulation{Outcome: SimulationBlocked, Steps: []SimulationStep{}, PolicyResults: []SimulatedPolicy{}, Actions: []SimulatedAction{}}
+	step := func(check, outcome, detail string) {
+		sim.Steps = append(sim.Steps, SimulationStep{Check: check, Outcome: outcome, Detail: detail})
+	}
+
+	in := m.policyInput(0)
+	sim.Cost24hUSD = in.Budget.Cost24hUSD
+
+	if isHandBack(req.RecommendedTier, req.FromTier) {
+		return m.simulateHandBack(sim, req, step), nil
+	}
+
+	h := &Handoff{SchemaVersion: 1, RecommendedTier: req.RecommendedTier, ServicesAffected: req.ServicesAffected}
+	if err := ValidateHandoff(h, m.cfg.MaxTier); err != nil {
+		step("handoff", "block", fmt.Sprintf("Escalation blocked: invalid handoff from tier %d — %v", req.FromTier, err))
+		return sim, nil
+	}
+	step("handoff", "pass", fmt.Sprintf("Tier %d recommended for %s", req.RecommendedTier, strings.Join(req.ServicesAffected, ", ")))
+
+	if m.cfg.DryRun {
+		step("dry_run", "block", fmt.Sprintf("Escalation suppressed (dry run): would have escalated to tier %d", req.RecommendedTier))
+		return sim, nil
+	}
+
+	tier := req.RecommendedTier
+	if t, msg := tierSkipOutcome(m.cfg.NoTierSkip, req.FromTier, tier); msg != "" {
+		tier = t
+		step("tier_skip", "cap", msg)
+	}
+	if m.Policy != nil {
+		in.Escalation = &policy.Escalation{
+			FromTier: req.FromTier,
+			ToTier:   tier,
+			Trigger:  req.Trigger,
+			Context:  req.Context,
+			Services: req.ServicesAffected,
+		}
+		d := m.Policy.Evaluate(policy.PointEscalation, in)
+		sim.addPolicyResults(policy.PointEscalation, "", d)
+		var msg string
+		tier, _, msg = escalationPolicyOutcome(d, req.FromTier, tier)
+		switch {
+		case tier == 0:
+			step("policy", "block", msg)
+			return sim, nil
+		case msg != "":
+			step("policy", "cap", msg)
+		default:
+			step("policy", "pass", fmt.Sprintf("Escalation allowed (%d rules evaluated)", len(d.Results)))
+		}
+	}
+
+	if req.Trigger != "drill" {
+		repeats, err := m.repeatEscalations(0, req.ServicesAffected, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if len(repeats) > 0 {
+			step("escalation_cooldown", "block", repeatEscalationMessage(tier, m.cfg.EscalationCooldown, repeats))
+			return sim, nil
+		}
+	}
+
+	if msg := tierSkipMessage(req.FromTier, tier); msg != "" {
+		step("tier_skip", "info", msg)
+	}
+	sim.Tier = tier
+	sim.Model = map[int]string{1: m.cfg.Tier1Model, 2: m.cfg.Tier2Model, 3: m.cfg.Tier3Model}[tier]
+	sim.Prompt = m.cfg.Tier3Prompt
+	if tier == 2 {
+		sim.Prompt = m.cfg.Tier2Prompt
+		if p, matched := selectPrompt(m.cfg.Tier2Prompt, m.promptRules, req.Context); len(matched) > 0 {
+			sim.Prompt = p
+			step("prompt", "info", fmt.Sprintf("Tier 2 prompt %s selected (matched: %s)", filepath.Base(p), strings.Join(matched, ", ")))
+		}
+	}
+
+	if err := m.simulateActions(sim, in, tier, req.ServicesAffected); err != nil {
+		return nil, err
+	}
+
+	sim.Outcome = SimulationEscalate
+	if tier == 3 {
+		if gated := m.twoPersonServices(context.Background(), req.ServicesAffected); len(gated) > 0 {
+			sim.Outcome = SimulationApproval
+			step("approval", "hold", fmt.Sprintf("Tier 3 remediation of %s needs approval from two operators", strings.Join(gated, ", ")))
+		}
+	}
+	if m.Draining() {
+		step("shutdown", "hold", "The supervisor is shutting down; the chain would be saved to resume after restart")
+	}
+	return sim, nil
+}
+
+// simulateHandBack works out what the supervisor would do if a tier handed
+// back to a lower one: run a verification session there, which no policy,
+// cooldown, or approval gates.
+func (m *Manager) simulateHandBack(sim *Simulation, req SimulationRequest, step func(check, outcome, detail string)) *Simulation {
+	if len(req.ServicesAffected) == 0 {
+		step("handoff", "block", fmt.Sprintf("Hand-back blocked: tier %d handed back to tier %d without naming any services", req.FromTier, req.RecommendedTier))
+		return sim
+	}
+	step("handoff", "pass", fmt.Sprintf("Tier %d handed back to tier %d to verify %s", req.FromTier, req.RecommendedTier, strings.Join(req.ServicesAffected, ", ")))
+	if m.cfg.DryRun {
+		step("dry_run", "block", fmt.Sprintf("Hand-back suppressed (dry run): would have handed back to tier %d", req.RecommendedTier))
+		return sim
+	}
+	sim.Outcome = SimulationVerify
+	sim.Tier, sim.Model = m.verifyTier(req.RecommendedTier)
+	sim.Prompt = m.cfg.VerifyPrompt
+	if m.Draining() {
+		step("shutdown", "hold", "The supervisor is shutting down; the verification would not run")
+	}
+	return sim
+}
+
+// simulateActions fills in, for each affected service, the cooldown budget
+// left for each remediation action and whether the cooldown policy would
+// let the session at tier record it.
+func (m *Manager) simulateActions(sim *Simulation, in policy.Input, tier int, services []string) error {
+	budgets, err := m.serviceCooldownBudgets(services)
+	if err != nil {
+		return err
+	}
+	for _, budget := range budgets {
+		a := SimulatedAction{Service: budget.Service, Action: budget.Action, Allowed: budget.Remaining > 0, Budget: budget}
+		if !a.Allowed {
+			a.Detail = fmt.Sprintf("%d of %d %s actions used in the last %d hours", budget.Used, budget.Limit, budget.Action, budget.WindowHours)
+		}
+		if m.Policy != nil {
+			count, err := m.db.CheckCooldown(budget.Service, budget.Action, 24*time.Hour)
+			if err != nil {
+				return err
+			}
+			in.Escalation = nil
+			in.Cooldown = &policy.Cooldown{Service: budget.Service, Action: budget.Action, Tier: tier, Success: true, RecentCount: count}
+			d := m.Policy.Evaluate(policy.PointCooldown, in)
+			sim.addPolicyResults(policy.PointCooldown, budget.Service, d)
+			if d.Deny {
+				a.Allowed = false
+				a.Detail = "denied by policy " + d.Reason
+			}
+		}
+		sim.Actions = append(sim.Actions, a)
+	}
+	return nil
+}
+
+func (sim *Simulation) addPolicyResults(point, service string, d policy.Decision) {
+	for _, r := range d.Results {
+		sim.PolicyResults = append(sim.PolicyResults, SimulatedPolicy{
+			Point:   point,
+			Service: service,
+			Rule:    r.Rule,
+			Matched: r.Matched,
+			Action:  r.Action,
+			Detail:  r.Detail,
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/policy"
+)
+
+func TestSimulateCapsAndReportsBudgets(t *testing.T) {
+	engine, err := policy.Parse([]byte(`
+rules:
+  - name: no-tier3
+    when: escalation
+    expr: escalation.to_tier == 3
+    action: cap
+    max_tier: 2
+  - name: no-redeploy
+    when: cooldown
+    expr: cooldown.action == "redeployment"
+    action: deny
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier2Prompt = "/prompts/tier2-investigate.md"
+	m.promptRules = ParsePromptRules("db-investigate.md=postgres")
+	m.Policy = engine
+	now := time.Now().UTC().Format(time.RFC3339)
+	for range 2 {
+		if _, err := database.InsertCooldownAction(&db.CooldownAction{Service: "postgres", ActionType: "restart", Timestamp: now, Success: true, Tier: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sim, err := m.Simulate(SimulationRequest{RecommendedTier: 3, ServicesAffected: []string{"postgres"}, Context: "postgres deadlock"})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if sim.Outcome != SimulationEscalate || sim.Tier != 2 || sim.Model != "sonnet" || sim.Prompt != "/prompts/db-investigate.md" {
+		t.Errorf("unexpected simulation %+v", sim)
+	}
+	var checks []string
+	for _, s := range sim.Steps {
+		checks = append(checks, s.Check+":"+s.Outcome)
+	}
+	if got := strings.Join(checks, " "); got != "handoff:pass policy:cap prompt:info" {
+		t.Errorf("steps = %s", got)
+	}
+	if len(sim.Actions) != 2 {
+		t.Fatalf("expected restart and redeployment actions, got %+v", sim.Actions)
+	}
+	if restart := sim.Actions[0]; restart.Allowed || restart.Budget.Used != 2 || restart.Budget.Remaining != 0 {
+		t.Errorf("restart = %+v, want exhausted budget", restart)
+	}
+	if redeploy := sim.Actions[1]; redeploy.Allowed || !strings.Contains(redeploy.Detail, "no-redeploy") || redeploy.Budget.Remaining != 1 {
+		t.Errorf("redeployment = %+v, want denied by policy", redeploy)
+	}
+
+	// Nothing is recorded.
+	if events, _ := database.ListEvents(10, 0, db.EventFilter{}); len(events) != 0 {
+		t.Errorf("simulation emitted events: %+v", events)
+	}
+}
+
+func TestSimulateBlocksAndHolds(t *testing.T) {
+	m, _ := testManagerWithDB(t)
+	m.cfg.MaxTier = 3
+	m.cfg.TwoPersonServices = "vault"
+
+	sim, err := m.Simulate(SimulationRequest{RecommendedTier: 3, ServicesAffected: []string{"vault"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Outcome != SimulationBlocked || sim.Steps[len(sim.Steps)-1].Check != "dry_run" {
+		t.Errorf("dry run: %+v", sim)
+	}
+
+	m.cfg.DryRun = false
+	sim, err = m.Simulate(SimulationRequest{RecommendedTier: 3, ServicesAffected: []string{"vault"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Outcome != SimulationApproval || sim.Tier != 3 {
+		t.Errorf("two-person service: %+v", sim)
+	}
+
+	m.cfg.MaxTier = 2
+	sim, err = m.Simulate(SimulationRequest{RecommendedTier: 3, ServicesAffected: []string{"vault"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Outcome != SimulationBlocked || !strings.Contains(sim.Steps[0].Detail, "exceeds max_tier 2") {
+		t.Errorf("max tier: %+v", sim)
+	}
+}
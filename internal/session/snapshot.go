@@ -0,0 +1,219 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// stateSnapshotFileName is the well-known file name for the state snapshot.
+const stateSnapshotFileName = "state-snapshot.json"
+
+const (
+	// snapshotMemoryLimit bounds the memories in a snapshot.
+	snapshotMemoryLimit = 200
+
+	// snapshotCriticalLimit bounds the critical events in a snapshot.
+	snapshotCriticalLimit = 20
+
+	// snapshotCriticalWindow is how far back critical events are included.
+	snapshotCriticalWindow = 24 * time.Hour
+)
+
+// cooldownBudgets are the remediation limits the agent is held to: at most
+// 2 restarts per service in 4 hours and 1 redeployment in 24 hours.
+var cooldownBudgets = []struct {
+	action string
+	limit  int
+	window time.Duration
+}{
+	{"restart", 2, 4 * time.Hour},
+	{"redeployment", 1, 24 * time.Hour},
+}
+
+// serviceCooldownBudgets returns the budget each of services has left for
+// each remediation action, by action and then service.
+func (m *Manager) serviceCooldownBudgets(services []string) ([]CooldownBudget, error) {
+	var out []CooldownBudget
+	for _, b := range cooldownBudgets {
+		recent, err := m.db.ListRecentCooldowns(b.window)
+		if err != nil {
+			return nil, err
+		}
+		for _, svc := range services {
+			budget := CooldownBudget{Service: svc, Action: b.action, Limit: b.limit, WindowHours: int(b.window.Hours())}
+			for _, c := range recent {
+				if c.ActionType == b.action && strings.EqualFold(c.Service, svc) {
+					budget.Used, budget.LastAction = c.Count, c.LastAction
+				}
+			}
+			budget.Remaining = max(0, b.limit-budget.Used)
+			out = append(out, budget)
+		}
+	}
+	return out, nil
+}
+
+// StateSnapshot is a read-only copy of the supervisor's state, written to
+// $CLAUDEOPS_STATE_DIR/state-snapshot.json before each session so the agent
+// reads one file instead of querying the database.
+type StateSnapshot struct {
+	SchemaVersion int    `json:"schema_version"`
+	GeneratedAt   string `json:"generated_at"`
+	// Memories are the active memories by service; "general" holds those
+	// without one.
+	Memories map[string][]SnapshotMemory `json:"memories"`
+	// Cooldowns lists the services with remediation actions in the budget
+	// windows. A service not listed has its full budget.
+	Cooldowns       []CooldownBudget   `json:"cooldowns"`
+	OpenIncidents   []SnapshotIncident `json:"open_incidents"`
+	RecentCriticals []SnapshotEvent    `json:"recent_criticals"`
+}
+
+// SnapshotMemory is an active memory in a state snapshot.
+type SnapshotMemory struct {
+	ID          int64   `json:"id"`
+	Category    string  `json:"category"`
+	Observation string  `json:"observation"`
+	Confidence  float64 `json:"confidence"`
+	Unverified  bool    `json:"unverified,omitempty"`
+}
+
+// CooldownBudget is how much of a remediation budget a service has left.
+type CooldownBudget struct {
+	Service     string `json:"service"`
+	Action      string `json:"action"`
+	Used        int    `json:"used"`
+	Limit       int    `json:"limit"`
+	Remaining   int    `json:"remaining"`
+	WindowHours int    `json:"window_hours"`
+	LastAction  string `json:"last_action"`
+}
+
+// SnapshotIncident is an incident still open with a paging provider.
+type SnapshotIncident struct {
+	Service   string `json:"service"`
+	Provider  string `json:"provider"`
+	Summary   string `json:"summary"`
+	SessionID *int64 `json:"session_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// SnapshotEvent is a recent critical event.
+type SnapshotEvent struct {
+	Service   string `json:"service,omitempty"`
+	Message   string `json:"message"`
+	SessionID *int64 `json:"session_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// buildStateSnapshot gathers the snapshot from the database.
+func (m *Manager) buildStateSnapshot(now time.Time) (*StateSnapshot, error) {
+	snap := &StateSnapshot{
+		SchemaVersion:   1,
+		GeneratedAt:     now.UTC().Format(time.RFC3339),
+		Memories:        make(map[string][]SnapshotMemory),
+		Cooldowns:       []CooldownBudget{},
+		OpenIncidents:   []SnapshotIncident{},
+		RecentCriticals: []SnapshotEvent{},
+	}
+
+	memories, err := m.db.GetActiveMemories(snapshotMemoryLimit, m.cfg.MemoryVerifiedOnly)
+	if err != nil {
+		return nil, err
+	}
+	for _, mem := range memories {
+		key := "general"
+		if mem.Service != nil {
+			key = *mem.Service
+		}
+		snap.Memories[key] = append(snap.Memories[key], SnapshotMemory{
+			ID:          mem.ID,
+			Category:    mem.Category,
+			Observation: mem.Observation,
+			Confidence:  mem.Confidence,
+			Unverified:  mem.ReviewStatus == db.MemoryUnverified,
+		})
+	}
+
+	for _, b := range cooldownBudgets {
+		recent, err := m.db.ListRecentCooldowns(b.window)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range recent {
+			if c.ActionType != b.action {
+				continue
+			}
+			snap.Cooldowns = append(snap.Cooldowns, CooldownBudget{
+				Service:     c.Service,
+				Action:      b.action,
+				Used:        c.Count,
+				Limit:       b.limit,
+				Remaining:   max(0, b.limit-c.Count),
+				WindowHours: int(b.window.Hours()),
+				LastAction:  c.LastAction,
+			})
+		}
+	}
+
+	incidents, err := m.db.ListTriggeredPagerIncidents()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range incidents {
+		snap.OpenIncidents = append(snap.OpenIncidents, SnapshotIncident{
+			Service:   p.Service,
+			Provider:  p.Provider,
+			Summary:   p.Summary,
+			SessionID: p.SessionID,
+			CreatedAt: p.CreatedAt,
+		})
+	}
+
+	critical := "critical"
+	events, err := m.db.ListEvents(snapshotCriticalLimit, 0, db.EventFilter{
+		Level: &critical,
+		Since: now.UTC().Add(-snapshotCriticalWindow).Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		se := SnapshotEvent{Message: e.Message, SessionID: e.SessionID, CreatedAt: e.CreatedAt}
+		if e.Service != nil {
+			se.Service = *e.Service
+		}
+		snap.RecentCriticals = append(snap.RecentCriticals, se)
+	}
+	return snap, nil
+}
+
+// writeStateSnapshot writes the state snapshot into the state dir and
+// returns its path. The file is replaced atomically so an agent never reads
+// a partial snapshot.
+func (m *Manager) writeStateSnapshot() (string, error) {
+	snap, err := m.buildStateSnapshot(time.Now())
+	if err != nil {
+		return "", fmt.Errorf("build state snapshot: %w", err)
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal state snapshot: %w", err)
+	}
+	path := filepath.Join(m.cfg.StateDir, stateSnapshotFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("write state snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("write state snapshot: %w", err)
+	}
+	return path, nil
+}
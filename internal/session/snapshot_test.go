@@ -0,0 +1,125 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestWriteStateSnapshot(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	now := time.Now().UTC()
+	ts := func(ago time.Duration) string { return now.Add(-ago).Format(time.RFC3339) }
+
+	svc := "jellyfin"
+	for _, mem := range []*db.Memory{
+		{Service: &svc, Category: "timing", Observation: "Takes 60s to start", Confidence: 0.9, Active: true, ReviewStatus: db.MemoryVerified},
+		{Category: "behavior", Observation: "DNS flaps at night", Confidence: 0.6, Active: true, ReviewStatus: db.MemoryUnverified},
+		{Service: &svc, Category: "behavior", Observation: "Inactive", Confidence: 0.9, Active: false},
+	} {
+		mem.CreatedAt, mem.UpdatedAt = ts(0), ts(0)
+		if _, err := database.InsertMemory(mem); err != nil {
+			t.Fatalf("InsertMemory: %v", err)
+		}
+	}
+	for _, a := range []*db.CooldownAction{
+		{Service: "jellyfin", ActionType: "restart", Timestamp: ts(time.Hour), Success: true, Tier: 2},
+		{Service: "jellyfin", ActionType: "restart", Timestamp: ts(5 * time.Hour), Success: true, Tier: 2},
+		{Service: "sonarr", ActionType: "redeployment", Timestamp: ts(10 * time.Hour), Success: false, Tier: 3},
+	} {
+		if _, err := database.InsertCooldownAction(a); err != nil {
+			t.Fatalf("InsertCooldownAction: %v", err)
+		}
+	}
+	if _, err := database.InsertPagerIncident(&db.PagerIncident{
+		Provider: "pagerduty", DedupKey: "k1", Service: "sonarr", Summary: "sonarr down", CreatedAt: ts(time.Hour),
+	}); err != nil {
+		t.Fatalf("InsertPagerIncident: %v", err)
+	}
+	for _, e := range []*db.Event{
+		{Level: "critical", Service: &svc, Message: "jellyfin down", CreatedAt: ts(time.Hour)},
+		{Level: "critical", Message: "old outage", CreatedAt: ts(48 * time.Hour)},
+		{Level: "warning", Service: &svc, Message: "slow", CreatedAt: ts(time.Hour)},
+	} {
+		if _, err := database.InsertEvent(e); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	path, err := m.writeStateSnapshot()
+	if err != nil {
+		t.Fatalf("writeStateSnapshot: %v", err)
+	}
+	if path != filepath.Join(m.cfg.StateDir, stateSnapshotFileName) {
+		t.Errorf("path = %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	var snap StateSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatalf("parse snapshot: %v", err)
+	}
+
+	if snap.SchemaVersion != 1 {
+		t.Errorf("schema_version = %d", snap.SchemaVersion)
+	}
+	if got := snap.Memories["jellyfin"]; len(got) != 1 || got[0].Observation != "Takes 60s to start" || got[0].Unverified {
+		t.Errorf("jellyfin memories = %+v", got)
+	}
+	if got := snap.Memories["general"]; len(got) != 1 || !got[0].Unverified {
+		t.Errorf("general memories = %+v", got)
+	}
+
+	budgets := make(map[string]CooldownBudget)
+	for _, b := range snap.Cooldowns {
+		budgets[b.Service+"/"+b.Action] = b
+	}
+	if b := budgets["jellyfin/restart"]; b.Used != 1 || b.Remaining != 1 || b.Limit != 2 || b.WindowHours != 4 {
+		t.Errorf("jellyfin restart budget = %+v", b)
+	}
+	if b := budgets["sonarr/redeployment"]; b.Used != 1 || b.Remaining != 0 {
+		t.Errorf("sonarr redeployment budget = %+v", b)
+	}
+	if len(snap.Cooldowns) != 2 {
+		t.Errorf("cooldowns = %+v, want 2", snap.Cooldowns)
+	}
+
+	if len(snap.OpenIncidents) != 1 || snap.OpenIncidents[0].Service != "sonarr" {
+		t.Errorf("open incidents = %+v", snap.OpenIncidents)
+	}
+	if len(snap.RecentCriticals) != 1 || snap.RecentCriticals[0].Message != "jellyfin down" {
+		t.Errorf("recent criticals = %+v", snap.RecentCriticals)
+	}
+}
+
+func TestRunTierReferencesStateSnapshot(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.runner = &pipeRunner{events: []string{
+		`{"type":"result","subtype":"success","result":"ok","total_cost_usd":0.01,"num_turns":1,"duration_ms":100}`,
+	}}
+
+	sessionID, _, err := m.runTier(context.Background(), 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	path := filepath.Join(m.cfg.StateDir, stateSnapshotFileName)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+	sess, err := database.GetSession(sessionID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	inv := ParseInvocation(sess.Invocation)
+	if inv == nil || !strings.Contains(inv.SystemPrompt, "CLAUDEOPS_STATE_SNAPSHOT="+path) {
+		t.Errorf("expected env context to reference the snapshot, got %+v", inv)
+	}
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSplitToolCalls is how many of the split session's most recent tool
+	// calls are listed in the continuation's handoff.
+	maxSplitToolCalls = 20
+
+	// splitRequestFile, in the state directory, tells a Tier 3 session
+	// the supervisor is splitting it. The prompt has the agent check for
+	// it between steps.
+	splitRequestFile = "split-request"
+
+	// splitHandoffFile, in the state directory, is where the agent writes
+	// its own handoff for the continuation session.
+	splitHandoffFile = "split-handoff.md"
+)
+
+// splitGracePeriod is how long a session asked to split has to write its
+// handoff and exit before the supervisor stops it. A variable so tests can
+// shorten it.
+var splitGracePeriod = 2 * time.Minute
+
+// splitTracker watches a Tier 3 session's stream for the turn or context
+// limit at which it is split into a continuation session. A split waits for
+// a tool boundary, so a remediation command is never cut off mid-flight.
+type splitTracker struct {
+	maxTurns   int // 0 = no turn limit
+	contextPct int // 0 = no context limit
+
+	turns     int
+	lastMsgID string
+	pending   map[string]bool // tool_use IDs still waiting for a result
+	toolCalls []string        // recent tool calls, oldest first
+	reason    string          // why the split was made, set once it is due
+}
+
+func newSplitTracker(maxTurns, contextPct int) *splitTracker {
+	return &splitTracker{maxTurns: maxTurns, contextPct: contextPct, pending: make(map[string]bool)}
+}
+
+func (s *splitTracker) enabled() bool {
+	return s.maxTurns > 0 || s.contextPct > 0
+}
+
+// observe follows one stream event. It returns true once, at the first tool
+// boundary after a limit is reached.
+func (s *splitTracker) observe(evt *streamEvent, ctx *contextTracker) bool {
+	switch evt.Type {
+	case "assistant":
+		if evt.Message.ID == "" || evt.Message.ID != s.lastMsgID {
+			s.turns++
+		}
+		s.lastMsgID = evt.Message.ID
+		for _, b := range evt.Message.Content {
+			if b.Type == "tool_use" {
+				s.pending[b.ID] = true
+				s.toolCalls = append(s.toolCalls, describeToolCall(b))
+				if len(s.toolCalls) > maxSplitToolCalls {
+					s.toolCalls = s.toolCalls[1:]
+				}
+			}
+		}
+	case "user":
+		for _, b := range evt.Message.Content {
+			if b.Type == "tool_result" {
+				delete(s.pending, b.ToolUseID)
+			}
+		}
+	default:
+		return false
+	}
+	if s.reason != "" || !s.enabled() || len(s.pending) > 0 {
+		return false
+	}
+	switch {
+	case s.maxTurns > 0 && s.turns >= s.maxTurns:
+		s.reason = fmt.Sprintf("reached %d turns", s.turns)
+	case s.contextPct > 0 && ctx.max*100 >= ctx.window*int64(s.contextPct):
+		s.reason = fmt.Sprintf("context window %d%% full", ctx.max*100/ctx.window)
+	default:
+		return false
+	}
+	return true
+}
+
+// describeToolCall is a one-line summary of a tool call, e.g.
+// "Bash: docker restart jellyfin".
+func describeToolCall(b contentBlock) string {
+	var input map[string]any
+	_ = json.Unmarshal(b.Input, &input)
+	for _, key := range []string{"command", "file_path", "url", "pattern"} {
+		if v, ok := input[key].(string); ok && v != "" {
+			return b.Name + ": " + truncateString(strings.Join(strings.Fields(v), " "), 200)
+		}
+	}
+	return b.Name
+}
+
+// requestSplit asks the agent to wrap up by writing the split request
+// file, and stops the session if it is still running after
+// splitGracePeriod. The returned timer is nil when the request could not
+// be written and the session was stopped at once.
+func (m *Manager) requestSplit(reason string, stop context.CancelFunc) *time.Timer {
+	path := filepath.Join(m.cfg.StateDir, splitRequestFile)
+	if err := os.WriteFile(path, []byte(reason+"\n"), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "split: write %s: %v\n", path, err)
+		stop()
+		return nil
+	}
+	return time.AfterFunc(splitGracePeriod, stop)
+}
+
+// takeSplitHandoff removes the split request file and returns the handoff
+// the agent wrote, if any, removing it too.
+func (m *Manager) takeSplitHandoff() string {
+	_ = os.Remove(filepath.Join(m.cfg.StateDir, splitRequestFile))
+	path := filepath.Join(m.cfg.StateDir, splitHandoffFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "split: read %s: %v\n", path, err)
+		}
+		return ""
+	}
+	_ = os.Remove(path)
+	return strings.TrimSpace(string(data))
+}
+
+// splitError is returned by runTier when the supervisor split the session.
+// It carries what the continuation session needs to pick up the work:
+// the handoff the agent wrote when asked to split, or, when it did not
+// write one in time, what the supervisor saw of its transcript.
+type splitError struct {
+	reason    string
+	turns     int
+	handoff   string
+	lastText  string
+	toolCalls []string
+}
+
+func (e *splitError) Error() string {
+	return "session split: " + e.reason
+}
+
+// continuationContext is the handoff context for the session continuing
+// the split session sessionID. baseContext is the context the chain's
+// first session at this tier was given.
+func (e *splitError) continuationContext(sessionID int64, baseContext string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Continuation of session #%d\n\n", sessionID)
+	fmt.Fprintf(&b, "The supervisor split session #%d after %d turns (%s) so the work can continue with a fresh context. "+
+		"Re-check the current state before acting and do not repeat steps that already succeeded.\n", sessionID, e.turns, e.reason)
+	if e.handoff != "" {
+		fmt.Fprintf(&b, "\n### Handoff from the previous session\n\n%s\n", e.handoff)
+	} else if e.lastText != "" {
+		fmt.Fprintf(&b, "\n### Last report from the previous session\n\n%s\n", e.lastText)
+	}
+	if e.handoff == "" && len(e.toolCalls) > 0 {
+		b.WriteString("\n### Most recent tool calls, oldest first\n\n")
+		for _, c := range e.toolCalls {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+	if baseContext != "" {
+		fmt.Fprintf(&b, "\n### Original context\n\n%s\n", strings.TrimRight(baseContext, "\n"))
+	}
+	return b.String()
+}
@@ -0,0 +1,221 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestSplitTrackerWaitsForToolBoundary(t *testing.T) {
+	s := newSplitTracker(2, 0)
+	ctx := newContextTracker("opus", 0)
+	toolUse := func(id, cmd string) *streamEvent {
+		evt := &streamEvent{Type: "assistant"}
+		evt.Message.Content = []contentBlock{{Type: "tool_use", ID: id, Name: "Bash", Input: []byte(`{"command":"` + cmd + `"}`)}}
+		return evt
+	}
+	toolResult := func(id string) *streamEvent {
+		evt := &streamEvent{Type: "user"}
+		evt.Message.Content = []contentBlock{{Type: "tool_result", ToolUseID: id}}
+		return evt
+	}
+
+	if s.observe(toolUse("t1", "docker ps"), ctx) || s.observe(toolResult("t1"), ctx) {
+		t.Fatal("split before the turn limit")
+	}
+	if s.observe(toolUse("t2", "docker restart jellyfin"), ctx) {
+		t.Fatal("split while a tool call was in flight")
+	}
+	if !s.observe(toolResult("t2"), ctx) {
+		t.Fatal("expected a split at the tool boundary after 2 turns")
+	}
+	if s.observe(toolResult("t2"), ctx) {
+		t.Error("split should be reported once")
+	}
+	if s.reason != "reached 2 turns" {
+		t.Errorf("unexpected reason %q", s.reason)
+	}
+	if len(s.toolCalls) != 2 || s.toolCalls[1] != "Bash: docker restart jellyfin" {
+		t.Errorf("unexpected tool calls %v", s.toolCalls)
+	}
+}
+
+func TestSplitTrackerContextLimit(t *testing.T) {
+	s := newSplitTracker(0, 90)
+	ctx := newContextTracker("opus", 0)
+	ctx.observe(&tokenUsage{InputTokens: 185_000})
+	if !s.observe(&streamEvent{Type: "assistant"}, ctx) {
+		t.Fatal("expected a split at 92% context")
+	}
+	if !strings.Contains(s.reason, "92%") {
+		t.Errorf("unexpected reason %q", s.reason)
+	}
+}
+
+func TestLongTier3SessionContinues(t *testing.T) {
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Init("opus"),
+			testkit.ToolUse("t1", "Bash", map[string]string{"command": "docker ps"}),
+			testkit.ToolResult("t1", "jellyfin exited"),
+			testkit.Text("Jellyfin has exited; restarting it."),
+			testkit.ToolUse("t2", "Bash", map[string]string{"command": "docker restart jellyfin"}),
+			testkit.ToolResult("t2", "jellyfin"),
+			testkit.Text("Still working.").After(5 * time.Second),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Init("opus"),
+			testkit.Result("Jellyfin is healthy again.", testkit.Healthy("jellyfin restarted", "jellyfin")),
+		}},
+	)
+
+	shortenSplitGracePeriod(t)
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier3Prompt = "/dev/null"
+	m.cfg.SplitTurns = 3
+	m.cfg.MaxSplits = 1
+	m.runner = runner
+
+	rootID := m.runEscalationChain(context.Background(), "manual", nil, 3, "jellyfin is down", []string{"jellyfin"})
+
+	calls := runner.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected a continuation session, got %d CLI calls", len(calls))
+	}
+	cont := calls[1].AppendSystemPrompt
+	for _, want := range []string{"Continuation of session #", "reached 3 turns", "Bash: docker restart jellyfin", "restarting it", "jellyfin is down"} {
+		if !strings.Contains(cont, want) {
+			t.Errorf("continuation context missing %q", want)
+		}
+	}
+
+	root, err := database.GetSession(rootID)
+	if err != nil || root == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if root.Status != "continued" {
+		t.Errorf("expected split session to be continued, got %q", root.Status)
+	}
+	sessions, err := database.ListSessions(10, 0)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("expected two sessions, got %d (%v)", len(sessions), err)
+	}
+	for _, s := range sessions {
+		if s.ID == rootID {
+			continue
+		}
+		if s.Tier != 3 || s.Trigger != "continuation" || s.ParentSessionID == nil || *s.ParentSessionID != rootID || s.Status != "completed" {
+			t.Errorf("unexpected continuation session %+v", s)
+		}
+	}
+}
+
+func TestSplitLimitStopsContinuations(t *testing.T) {
+	runner := testkit.NewRunner(testkit.Script{Events: []testkit.Event{
+		testkit.Init("opus"),
+		testkit.ToolUse("t1", "Bash", map[string]string{"command": "docker ps"}),
+		testkit.ToolResult("t1", "ok"),
+		testkit.Text("Still working.").After(5 * time.Second),
+	}})
+
+	shortenSplitGracePeriod(t)
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier3Prompt = "/dev/null"
+	m.cfg.SplitTurns = 1
+	m.cfg.MaxSplits = 1
+	m.runner = runner
+
+	m.runEscalationChain(context.Background(), "manual", nil, 3, "", nil)
+
+	if n := len(runner.Calls()); n != 2 {
+		t.Fatalf("expected the split session and one continuation, got %d CLI calls", n)
+	}
+	sessions, err := database.ListSessions(10, 0)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	for _, s := range sessions {
+		if s.Status != "continued" {
+			t.Errorf("session %d: expected continued, got %q", s.ID, s.Status)
+		}
+	}
+}
+
+// shortenSplitGracePeriod makes a split session that does not write its
+// handoff get stopped at once.
+func shortenSplitGracePeriod(t *testing.T) {
+	t.Helper()
+	prev := splitGracePeriod
+	splitGracePeriod = 10 * time.Millisecond
+	t.Cleanup(func() { splitGracePeriod = prev })
+}
+
+func TestSplitSessionWritesItsOwnHandoff(t *testing.T) {
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Init("opus"),
+			testkit.ToolUse("t1", "Bash", map[string]string{"command": "docker restart jellyfin"}),
+			testkit.ToolResult("t1", "jellyfin"),
+			// The agent sees the split request, writes its handoff, and ends.
+			testkit.Text("Handing off.").After(500 * time.Millisecond),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Init("opus"),
+			testkit.Result("Jellyfin is healthy again.", testkit.Healthy("jellyfin restarted", "jellyfin")),
+		}},
+	)
+
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	m.cfg.Tier3Prompt = "/dev/null"
+	m.cfg.SplitTurns = 1
+	m.cfg.MaxSplits = 1
+	m.runner = runner
+
+	// Stand in for the agent: answer the split request with a handoff.
+	go func() {
+		for range 100 {
+			if _, err := os.Stat(filepath.Join(m.cfg.StateDir, splitRequestFile)); err == nil {
+				_ = os.WriteFile(filepath.Join(m.cfg.StateDir, splitHandoffFile), []byte("Restarted jellyfin; verify its web UI next.\n"), 0o644)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	rootID := m.runEscalationChain(context.Background(), "manual", nil, 3, "jellyfin is down", []string{"jellyfin"})
+
+	calls := runner.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected a continuation session, got %d CLI calls", len(calls))
+	}
+	cont := calls[1].AppendSystemPrompt
+	if !strings.Contains(cont, "Handoff from the previous session") || !strings.Contains(cont, "verify its web UI next") {
+		t.Errorf("continuation context missing the agent's handoff:\n%s", cont)
+	}
+	if strings.Contains(cont, "Most recent tool calls") {
+		t.Errorf("continuation context should not fall back to the transcript:\n%s", cont)
+	}
+	root, err := database.GetSession(rootID)
+	if err != nil || root == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if root.Status != "continued" {
+		t.Errorf("expected split session to be continued, got %q", root.Status)
+	}
+	for _, name := range []string{splitRequestFile, splitHandoffFile} {
+		if _, err := os.Stat(filepath.Join(m.cfg.StateDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s left in the state directory", name)
+		}
+	}
+}
@@ -2,11 +2,22 @@ package session
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/anthropics/anthropic-sdk-go"
 )
 
+// errNoAPIKey is returned by summarizeResponse when no Anthropic API
+// credential is set, as when the CLI runs on a subscription login.
+var errNoAPIKey = errors.New("no ANTHROPIC_API_KEY for the summary model")
+
 // Governing: SPEC-0021 REQ "Session Summary Generation"
 const summarizeSystemPrompt = "You are a concise technical summarizer. Summarize the following infrastructure monitoring session output in 2-5 sentences. Focus on: what was checked, what issues were found (if any), and what actions were taken. Be specific about service names and outcomes."
 
@@ -17,16 +28,25 @@ const summarizeSystemPrompt = "You are a concise technical summarizer. Summarize
 //
 // Governing: SPEC-0021 REQ "Session Summary Generation"
 func summarizeResponse(ctx context.Context, response string, model string) (string, error) {
+	return summarizeText(ctx, summarizeSystemPrompt, response, model)
+}
+
+// summarizeText asks the summary model to summarize text as instructed by
+// system.
+func summarizeText(ctx context.Context, system, text, model string) (string, error) {
+	if os.Getenv("ANTHROPIC_API_KEY") == "" && os.Getenv("ANTHROPIC_AUTH_TOKEN") == "" {
+		return "", errNoAPIKey
+	}
 	client := anthropic.NewClient()
 
 	msg, err := client.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     anthropic.Model(model),
 		MaxTokens: 300,
 		System: []anthropic.TextBlockParam{
-			{Text: summarizeSystemPrompt},
+			{Text: system},
 		},
 		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(response)),
+			anthropic.NewUserMessage(anthropic.NewTextBlock(text)),
 		},
 	})
 	if err != nil {
@@ -42,3 +62,150 @@ func summarizeResponse(ctx context.Context, response string, model string) (stri
 
 	return "", fmt.Errorf("no text block in response")
 }
+
+// summaryCacheSize bounds the summaries kept for reuse.
+const summaryCacheSize = 128
+
+// summaryCache holds recent summaries keyed by summaryKey, so a response
+// that differs from an earlier one only in timestamps and measurements, such
+// as the same "all healthy" report from one scheduled check to the next,
+// reuses its summary instead of calling the summary model again.
+type summaryCache struct {
+	mu    sync.Mutex
+	items map[string]string
+	order []string // keys, oldest first
+}
+
+func newSummaryCache() *summaryCache {
+	return &summaryCache{items: make(map[string]string)}
+}
+
+func (c *summaryCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.items[key]
+	return s, ok
+}
+
+// put stores a summary, evicting the oldest once the cache is full.
+func (c *summaryCache) put(key, summary string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.items[key] = summary
+	for len(c.order) > summaryCacheSize {
+		delete(c.items, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+var (
+	// timestampRe matches dates and times, e.g. 2026-02-15T10:00:00Z,
+	// 2026-02-15, and 10:00:03.
+	timestampRe = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?|\b\d{1,2}:\d{2}(?::\d{2})?\b`)
+	// sessionRefRe matches session and issue numbers, e.g. #123.
+	sessionRefRe = regexp.MustCompile(`#\d+`)
+	// hexIDRe matches container IDs, commit hashes, and other hex IDs of
+	// at least 12 digits.
+	hexIDRe = regexp.MustCompile(`(?i)\b[0-9a-f]{12,}\b`)
+)
+
+// summaryKey returns the cache key for summarizing response with model: a
+// hash of the response with timestamps, session numbers, and hex IDs
+// replaced, whitespace collapsed, and case folded. Every other number, such
+// as an HTTP status code, a count, or a measurement like disk usage or
+// latency, is kept, as the summary may quote it or turn on it.
+func summaryKey(response, model string) string {
+	norm := timestampRe.ReplaceAllString(response, "<time>")
+	norm = sessionRefRe.ReplaceAllString(norm, "#<n>")
+	norm = hexIDRe.ReplaceAllString(norm, "<id>")
+	norm = strings.ToLower(strings.Join(strings.Fields(norm), " "))
+	sum := sha256.Sum256([]byte(model + "\x00" + norm))
+	return hex.EncodeToString(sum[:])
+}
+
+// summarizeCached summarizes a session response with the summary model,
+// reusing the summary of an earlier response that only differed in
+// timestamps and IDs. When the model fails or no API key is set,
+// it returns a local summary (see localSummary) along with the error;
+// local summaries are not cached, so the next response tries the model
+// again.
+func (m *Manager) summarizeCached(ctx context.Context, response string) (string, error) {
+	key := summaryKey(response, m.cfg.SummaryModel)
+	if s, ok := m.summaries.get(key); ok {
+		return s, nil
+	}
+	summary, err := m.summarize(ctx, response, m.cfg.SummaryModel)
+	if err != nil || summary == "" {
+		return localSummary(response), err
+	}
+	m.summaries.put(key, summary)
+	return summary, nil
+}
+
+const (
+	// localSummaryLines bounds the event and cooldown lines in a local
+	// summary, and localSummaryMax its length.
+	localSummaryLines = 8
+	localSummaryMax   = 1500
+)
+
+// localSummary builds a summary of a response without the summary model,
+// so the TL;DR page does not show a whole response when the model is
+// unavailable: the response's first heading, then its event markers
+// (warnings and critical events first) and cooldown markers as a list. A
+// response with none of those is summarized by its first paragraph.
+func localSummary(response string) string {
+	var heading string
+	for _, line := range strings.Split(response, "\n") {
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, "#") {
+			heading = strings.TrimSpace(strings.TrimLeft(t, "#"))
+			break
+		}
+	}
+
+	var urgent, rest []string
+	for _, e := range parseEventMarkers(response) {
+		line := e.Level
+		if e.Service != nil {
+			line += " (" + *e.Service + ")"
+		}
+		line += ": " + e.Message
+		if e.Level == "info" {
+			rest = append(rest, line)
+		} else {
+			urgent = append(urgent, line)
+		}
+	}
+	for _, c := range parseCooldownMarkers(response) {
+		result := "success"
+		if !c.Success {
+			result = "failure"
+		}
+		rest = append(rest, fmt.Sprintf("%s %s: %s — %s", c.ActionType, c.Service, result, c.Message))
+	}
+	lines := append(urgent, rest...)
+	omitted := 0
+	if len(lines) > localSummaryLines {
+		omitted = len(lines) - localSummaryLines
+		lines = lines[:localSummaryLines]
+	}
+
+	var b strings.Builder
+	if heading != "" {
+		b.WriteString("**" + heading + "**\n\n")
+	}
+	if len(lines) == 0 {
+		para, _ := demoSummary(context.Background(), response, "")
+		b.WriteString(truncateString(para, localSummaryMax))
+	}
+	for _, line := range lines {
+		b.WriteString("- " + truncateString(line, 200) + "\n")
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "- and %d more\n", omitted)
+	}
+	return truncatePreserve(strings.TrimSpace(b.String()), localSummaryMax)
+}
@@ -1,6 +1,10 @@
 package session
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -58,3 +62,114 @@ func equalFoldASCII(a, b string) bool {
 	}
 	return true
 }
+
+func TestSummaryKey(t *testing.T) {
+	base := "## Health Check 2026-02-15T10:00:00Z\nAll 12 services healthy. jellyfin HTTP 200 in 123ms, disk 71% used. Session #41, container 3f2a9c81b7d4."
+	for _, tc := range []struct {
+		name     string
+		response string
+		model    string
+		same     bool
+	}{
+		{"timestamps and IDs", "## Health Check 2026-02-15T11:00:03Z\nAll 12 services healthy.  jellyfin HTTP 200 in 123ms, disk 71% used. Session #42, container 9b1e07d2c6a5.", "haiku", true},
+		{"measurement", "## Health Check 2026-02-15T10:00:00Z\nAll 12 services healthy. jellyfin HTTP 200 in 123ms, disk 97% used. Session #41, container 3f2a9c81b7d4.", "haiku", false},
+		{"duration", "## Health Check 2026-02-15T10:00:00Z\nAll 12 services healthy. jellyfin HTTP 200 in 9500ms, disk 71% used. Session #41, container 3f2a9c81b7d4.", "haiku", false},
+		{"case and whitespace", "## health check 2026-02-15T10:00:00Z\n\nall 12 services healthy. Jellyfin HTTP 200 in 123ms, disk 71% used. session #41, container 3f2a9c81b7d4.", "haiku", true},
+		{"status code", "## Health Check 2026-02-15T10:00:00Z\nAll 12 services healthy. jellyfin HTTP 502 in 123ms, disk 71% used. Session #41, container 3f2a9c81b7d4.", "haiku", false},
+		{"count", "## Health Check 2026-02-15T10:00:00Z\nAll 11 services healthy. jellyfin HTTP 200 in 123ms, disk 71% used. Session #41, container 3f2a9c81b7d4.", "haiku", false},
+		{"model", base, "sonnet", false},
+	} {
+		if got := summaryKey(tc.response, tc.model) == summaryKey(base, "haiku"); got != tc.same {
+			t.Errorf("%s: same key = %v, want %v", tc.name, got, tc.same)
+		}
+	}
+}
+
+func TestSummarizeCached(t *testing.T) {
+	m, _ := testManagerWithDB(t)
+	calls := 0
+	m.summarize = func(_ context.Context, response, _ string) (string, error) {
+		calls++
+		if strings.Contains(response, "fail") {
+			return "", errors.New("rate limited")
+		}
+		return fmt.Sprintf("summary %d", calls), nil
+	}
+
+	for i, response := range []string{"All healthy at 10:00 (session #41).", "All healthy at 11:00 (session #42)."} {
+		if s, err := m.summarizeCached(context.Background(), response); err != nil || s != "summary 1" {
+			t.Errorf("response %d: summary = %q, %v", i, s, err)
+		}
+	}
+	if s, _ := m.summarizeCached(context.Background(), "sonarr is down"); s != "summary 2" {
+		t.Errorf("different response: summary = %q", s)
+	}
+	if calls != 2 {
+		t.Errorf("summary model called %d times, want 2", calls)
+	}
+
+	// Failures fall back to a local summary, which is not cached.
+	for range 2 {
+		s, err := m.summarizeCached(context.Background(), "# Report\n\nThe summary will fail.")
+		if err == nil || s != "**Report**\n\nThe summary will fail." {
+			t.Errorf("summary = %q, %v; want the local summary and the error", s, err)
+		}
+	}
+	if calls != 4 {
+		t.Errorf("summary model called %d times, want 4", calls)
+	}
+
+	c := newSummaryCache()
+	for i := range summaryCacheSize + 1 {
+		c.put(fmt.Sprint(i), "s")
+	}
+	if _, ok := c.get("0"); ok || len(c.items) != summaryCacheSize {
+		t.Errorf("cache kept %d entries, including the oldest: %v", len(c.items), ok)
+	}
+}
+
+func TestLocalSummary(t *testing.T) {
+	response := strings.Join([]string{
+		"## Health Check Report",
+		"",
+		"Checked 14 services across 3 hosts. " + strings.Repeat("Lots of detail. ", 200),
+		"[EVENT:info:jellyfin] HTTP 200 in 120ms",
+		"[EVENT:critical:sonarr] Container exited with code 137",
+		"[COOLDOWN:restart:sonarr] failure — OOM killed again after restart",
+		"[EVENT:warning] Disk at 91% on ie01",
+	}, "\n")
+	got := localSummary(response)
+	want := strings.Join([]string{
+		"**Health Check Report**",
+		"",
+		"- critical (sonarr): Container exited with code 137",
+		"- warning: Disk at 91% on ie01",
+		"- info (jellyfin): HTTP 200 in 120ms",
+		"- restart sonarr: failure — OOM killed again after restart",
+	}, "\n")
+	if got != want {
+		t.Errorf("localSummary =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := localSummary("# Report\n\nAll services healthy.\n\n| a | b |"); got != "**Report**\n\nAll services healthy." {
+		t.Errorf("localSummary without markers = %q", got)
+	}
+	var many []string
+	for i := range localSummaryLines + 3 {
+		many = append(many, fmt.Sprintf("[EVENT:info:svc%d] ok", i))
+	}
+	if got := localSummary(strings.Join(many, "\n")); !strings.HasSuffix(got, "- and 3 more") {
+		t.Errorf("localSummary with many events = %q", got)
+	}
+	if got := localSummary(strings.Repeat("word ", 2000)); len(got) > localSummaryMax+3 {
+		t.Errorf("localSummary is %d bytes", len(got))
+	}
+}
+
+func TestSummarizeResponseWithoutAPIKey(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("ANTHROPIC_AUTH_TOKEN", "")
+	if _, err := summarizeResponse(context.Background(), "All healthy.", "claude-haiku-4-5"); !errors.Is(err, errNoAPIKey) {
+		t.Errorf("summarizeResponse error = %v, want errNoAPIKey", err)
+	}
+}
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestScriptedEscalationChain(t *testing.T) {
+	runner := testkit.NewRunner(
+		testkit.Script{Events: []testkit.Event{
+			testkit.Init("haiku"),
+			testkit.ToolUse("t1", "Bash", map[string]string{"command": "curl -s http://jellyfin:8096/health"}),
+			testkit.ToolResult("t1", "502 Bad Gateway"),
+			testkit.Result("Jellyfin is down.", testkit.Escalate("jellyfin returns 502", "jellyfin")),
+		}},
+		testkit.Script{Events: []testkit.Event{
+			testkit.Init("sonnet"),
+			testkit.Text("Restarting jellyfin."),
+			testkit.Result("Restarted jellyfin.", testkit.Healthy("jellyfin restarted", "jellyfin")),
+		}},
+	)
+
+	m, database := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 2
+	m.cfg.Tier2Prompt = "/dev/null"
+	m.runner = runner
+
+	rootID := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+	calls := runner.Calls()
+	if len(calls) != 2 || calls[0].Model != "haiku" || calls[1].Model != "sonnet" {
+		t.Fatalf("unexpected CLI calls %+v", calls)
+	}
+	if !strings.Contains(calls[1].AppendSystemPrompt, "jellyfin") {
+		t.Errorf("tier 2 was not handed the escalation context: %q", calls[1].AppendSystemPrompt)
+	}
+
+	sessions, err := database.ListSessions(10, 0)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("expected two sessions, got %d (%v)", len(sessions), err)
+	}
+	for _, s := range sessions {
+		if s.ID == rootID {
+			continue
+		}
+		if s.Tier != 2 || s.ParentSessionID == nil || *s.ParentSessionID != rootID {
+			t.Errorf("unexpected escalated session %+v", s)
+		}
+	}
+}
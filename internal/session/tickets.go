@@ -0,0 +1,380 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/tickets"
+)
+
+// --- Ticket markers ---
+
+// ticketMarkerRe matches [TICKET:provider:id] markers in assistant text,
+// e.g. [TICKET:github:ops/homelab#42] or [TICKET:jira:OPS-7]. Like cooldown
+// markers they are always read from text, and may appear mid-sentence.
+var ticketMarkerRe = regexp.MustCompile(`\[TICKET:(github|gitea|jira):([^\]\s]+)\]`)
+
+// linkTicketMarkers links a session to the tickets its text references.
+func (m *Manager) linkTicketMarkers(sessionID int64, text string) {
+	for _, match := range ticketMarkerRe.FindAllStringSubmatch(text, -1) {
+		ref, err := tickets.Parse(match[1], match[2])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: ticket marker: %v\n", sessionID, err)
+			continue
+		}
+		if _, err := m.db.InsertSessionTicket(&db.SessionTicket{
+			SessionID: sessionID,
+			Provider:  ref.Provider,
+			TicketID:  ref.ID,
+			URL:       ref.URL(),
+			Source:    db.TicketSourceMarker,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "session %d: %v\n", sessionID, err)
+		}
+	}
+}
+
+// --- Opening tickets for unresolved incidents ---
+
+// ticketQueueSize bounds the issues waiting to be opened.
+const ticketQueueSize = 64
+
+// TicketOpener implements Hooks, opening an issue for incidents the
+// escalation chain could not resolve: a chain whose top session asked to
+// escalate above the maximum tier, a Tier 3 session that failed or timed
+// out, and a remediation that did not hold. The issue describes the chain
+// (its summary, a timeline, the remediations attempted, and links to the
+// session logs), is linked to the session, and is recorded on the open
+// paging incidents of its services. No issue is opened for a session that
+// is already linked to one, or for services that each have an issue open
+// since they were last found healthy.
+type TicketOpener struct {
+	NopHooks
+
+	cfg     *config.Config
+	db      *db.DB
+	tracker *tickets.Tracker
+	queue   chan func(context.Context)
+}
+
+// NewTicketOpener returns a TicketOpener that opens issues with tracker.
+func NewTicketOpener(cfg *config.Config, database *db.DB, tracker *tickets.Tracker) *TicketOpener {
+	return &TicketOpener{cfg: cfg, db: database, tracker: tracker, queue: make(chan func(context.Context), ticketQueueSize)}
+}
+
+// Run opens queued issues until ctx is cancelled.
+func (o *TicketOpener) Run(ctx context.Context) {
+	log.Printf("tickets: opening issues for unresolved incidents in %s", o.tracker)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-o.queue:
+			job(ctx)
+		}
+	}
+}
+
+func (o *TicketOpener) enqueue(job func(context.Context)) {
+	select {
+	case o.queue <- job:
+	default:
+		log.Printf("tickets: queue full, dropping job")
+	}
+}
+
+// unresolved is an incident to open an issue for.
+type unresolved struct {
+	sessionID int64 // the session the issue is linked to
+	services  []string
+	title     string
+	headline  string // the first line of the issue: why it was opened
+}
+
+// OnSessionEnd opens an issue for a Tier 3 session that failed or timed
+// out. A finished session that found services healthy releases their
+// issues, so a later failure opens another.
+func (o *TicketOpener) OnSessionEnd(s *db.Session) {
+	if s.Status == "completed" || s.Status == "escalated" {
+		id := s.ID
+		o.enqueue(func(context.Context) { o.closeHealthy(id) })
+	}
+	if s.Tier != 3 || (s.Status != "failed" && s.Status != "timed_out") || s.Trigger == "drill" {
+		return
+	}
+	var services []string
+	if s.Services != nil {
+		for _, svc := range strings.Split(*s.Services, ",") {
+			if svc = strings.TrimSpace(svc); svc != "" {
+				services = append(services, svc)
+			}
+		}
+	}
+	status := strings.ReplaceAll(s.Status, "_", " ")
+	o.enqueueOpen(unresolved{
+		sessionID: s.ID,
+		services:  services,
+		title:     fmt.Sprintf("Claude Ops: Tier 3 remediation %s for %s", status, serviceList(services)),
+		headline:  fmt.Sprintf("Tier 3 remediation session #%d %s without resolving the incident. It needs a human.", s.ID, status),
+	})
+}
+
+// OnEscalation opens an issue when the top of the chain asks to escalate
+// above the maximum tier, leaving its services unhealthy.
+func (o *TicketOpener) OnEscalation(e Escalation) {
+	if e.ToTier <= o.cfg.MaxTier {
+		return
+	}
+	o.enqueueOpen(unresolved{
+		sessionID: e.SessionID,
+		services:  e.Services,
+		title:     fmt.Sprintf("Claude Ops: %s still unhealthy at the maximum tier", serviceList(e.Services)),
+		headline: fmt.Sprintf("Tier %d session #%d asked to escalate to Tier %d, above the maximum tier %d, so the incident was left unresolved. It needs a human.",
+			e.FromTier, e.SessionID, e.ToTier, o.cfg.MaxTier),
+	})
+}
+
+// OnVerification opens an issue, linked to the remediation session, when
+// services are still unhealthy after it, and releases the issues of those
+// it found healthy.
+func (o *TicketOpener) OnVerification(v Verification) {
+	var healthy []string
+	for _, svc := range v.Services {
+		if !slices.Contains(v.Unhealthy, svc) {
+			healthy = append(healthy, svc)
+		}
+	}
+	if len(healthy) > 0 {
+		o.enqueue(func(context.Context) { o.close(healthy) })
+	}
+	if len(v.Unhealthy) == 0 {
+		return
+	}
+	o.enqueueOpen(unresolved{
+		sessionID: v.RemediationID,
+		services:  v.Unhealthy,
+		title:     fmt.Sprintf("Claude Ops: remediation did not hold for %s", serviceList(v.Unhealthy)),
+		headline: fmt.Sprintf("Verification session #%d found %s still unhealthy after Tier 3 remediation session #%d. It needs a human.",
+			v.SessionID, serviceList(v.Unhealthy), v.RemediationID),
+	})
+}
+
+func (o *TicketOpener) enqueueOpen(u unresolved) {
+	o.enqueue(func(ctx context.Context) { o.open(ctx, u) })
+}
+
+func serviceList(services []string) string {
+	if len(services) == 0 {
+		return "unknown services"
+	}
+	return strings.Join(services, ", ")
+}
+
+func (o *TicketOpener) open(ctx context.Context, u unresolved) {
+	chain, err := o.db.GetEscalationChain(u.sessionID)
+	if err != nil || len(chain) == 0 {
+		log.Printf("tickets: session %d: %v", u.sessionID, err)
+		return
+	}
+	if chain[0].Trigger == "drill" {
+		return
+	}
+	linked, err := o.db.ListSessionTickets(u.sessionID)
+	if err != nil {
+		log.Printf("tickets: %v", err)
+		return
+	}
+	if len(linked) > 0 {
+		return
+	}
+	if o.hasIssue(u.services) {
+		return
+	}
+
+	ref, url, err := o.tracker.Open(ctx, u.title, o.issueBody(u.headline, chain))
+	if err != nil {
+		log.Printf("tickets: session %d: %v", u.sessionID, err)
+		return
+	}
+	if _, err := o.db.InsertSessionTicket(&db.SessionTicket{
+		SessionID: u.sessionID,
+		Provider:  ref.Provider,
+		TicketID:  ref.ID,
+		URL:       url,
+		Source:    db.TicketSourceAuto,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		log.Printf("tickets: %v", err)
+	}
+	for _, svc := range u.services {
+		if _, err := o.db.InsertServiceTicket(&db.ServiceTicket{
+			Service:   svc,
+			Provider:  ref.Provider,
+			TicketID:  ref.ID,
+			URL:       url,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		}); err != nil {
+			log.Printf("tickets: %v", err)
+		}
+		if url == "" {
+			continue
+		}
+		if err := o.db.SetPagerIncidentIssueURL(svc, url); err != nil {
+			log.Printf("tickets: %v", err)
+		}
+	}
+}
+
+// hasIssue reports whether every service has an issue open since it was
+// last found healthy.
+func (o *TicketOpener) hasIssue(services []string) bool {
+	if len(services) == 0 {
+		return false
+	}
+	for _, svc := range services {
+		open, err := o.db.HasOpenServiceTicket(svc)
+		if err != nil {
+			log.Printf("tickets: %v", err)
+			return false
+		}
+		if !open {
+			return false
+		}
+	}
+	return true
+}
+
+// closeHealthy releases the issues of services whose latest check was made by
+// session sessionID and found them healthy.
+func (o *TicketOpener) closeHealthy(sessionID int64) {
+	open, err := o.db.ListOpenServiceTickets()
+	if err != nil {
+		log.Printf("tickets: %v", err)
+		return
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	var healthy []string
+	for _, t := range open {
+		if slices.Contains(healthy, t.Service) {
+			continue
+		}
+		checks, err := o.db.QueryHealthChecks(t.Service, "", now, 1)
+		if err != nil {
+			log.Printf("tickets: %v", err)
+			continue
+		}
+		if len(checks) == 1 && checks[0].Status == "healthy" && checks[0].SessionID != nil && *checks[0].SessionID == sessionID {
+			healthy = append(healthy, t.Service)
+		}
+	}
+	o.close(healthy)
+}
+
+// close releases the open issues of services found healthy. The issues
+// themselves are left for a human to close in the tracker.
+func (o *TicketOpener) close(services []string) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, svc := range services {
+		if err := o.db.CloseServiceTickets(svc, now); err != nil {
+			log.Printf("tickets: %v", err)
+		}
+	}
+}
+
+// issueBody describes an unresolved escalation chain, root first, for an
+// issue: its summary, a timeline of its sessions and warning and critical
+// events, the remediations it attempted, and links to each session's log.
+func (o *TicketOpener) issueBody(headline string, chain []db.Session) string {
+	ids := make([]int64, len(chain))
+	for i, s := range chain {
+		ids[i] = s.ID
+	}
+	leaf := chain[len(chain)-1]
+
+	var b strings.Builder
+	b.WriteString(headline + "\n\n## Summary\n\n")
+	switch {
+	case leaf.Summary != nil && *leaf.Summary != "":
+		b.WriteString(*leaf.Summary)
+	case leaf.Response != nil && *leaf.Response != "":
+		b.WriteString(truncateString(*leaf.Response, 2000))
+	default:
+		b.WriteString("No summary was recorded.")
+	}
+
+	type entry struct{ at, text string }
+	var timeline []entry
+	for _, s := range chain {
+		timeline = append(timeline, entry{s.StartedAt, fmt.Sprintf("Tier %d session #%d started (%s, %s)", s.Tier, s.ID, s.Model, s.Trigger)})
+		if s.EndedAt != nil {
+			timeline = append(timeline, entry{*s.EndedAt, fmt.Sprintf("Tier %d session #%d ended: %s", s.Tier, s.ID, strings.ReplaceAll(s.Status, "_", " "))})
+		}
+	}
+	events, err := o.db.ListEventsForSessions(ids)
+	if err != nil {
+		log.Printf("tickets: %v", err)
+	}
+	for _, e := range events {
+		if e.Level != "warning" && e.Level != "critical" {
+			continue
+		}
+		text := e.Level + ": " + e.Message
+		if e.Service != nil && *e.Service != "" {
+			text = e.Level + ": " + *e.Service + ": " + e.Message
+		}
+		timeline = append(timeline, entry{e.CreatedAt, text})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].at < timeline[j].at })
+	b.WriteString("\n\n## Timeline\n\n")
+	for _, e := range timeline {
+		fmt.Fprintf(&b, "- %s %s\n", e.at, e.text)
+	}
+
+	b.WriteString("\n## Attempted remediations\n\n")
+	actions, err := o.db.ListCooldownActionsForSessions(ids)
+	if err != nil {
+		log.Printf("tickets: %v", err)
+	}
+	if len(actions) == 0 {
+		b.WriteString("None were recorded.\n")
+	}
+	for _, a := range actions {
+		result := "succeeded"
+		if !a.Success {
+			result = "failed"
+		}
+		line := fmt.Sprintf("- %s %s %s (Tier %d", a.Timestamp, a.ActionType, a.Service, a.Tier)
+		if a.SessionID != nil {
+			line += fmt.Sprintf(", session #%d", *a.SessionID)
+		}
+		line += "): " + result
+		if a.Error != nil && *a.Error != "" {
+			line += ": " + *a.Error
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n## Logs\n\n")
+	base := strings.TrimRight(o.cfg.DashboardURL, "/")
+	for _, s := range chain {
+		switch {
+		case base != "":
+			fmt.Fprintf(&b, "- Tier %d session #%d: %s/sessions/%d\n", s.Tier, s.ID, base, s.ID)
+		case s.LogFile != nil && *s.LogFile != "":
+			fmt.Fprintf(&b, "- Tier %d session #%d: `%s`\n", s.Tier, s.ID, *s.LogFile)
+		default:
+			fmt.Fprintf(&b, "- Tier %d session #%d\n", s.Tier, s.ID)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/tickets"
+)
+
+func TestLinkTicketMarkers(t *testing.T) {
+	t.Setenv("JIRA_URL", "")
+	m, database := testManagerWithDB(t)
+	sid, err := database.InsertSession(&db.Session{
+		Tier: 2, Model: "sonnet", PromptFile: "/dev/null", Status: "running",
+		StartedAt: "2026-02-15T10:00:00Z", Trigger: "scheduled",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.linkTicketMarkers(sid, "This is [TICKET:github:ops/homelab#42] again, see also [TICKET:jira:ops-7].")
+	m.linkTicketMarkers(sid, "[TICKET:github:ops/homelab#42] [TICKET:gitea:not-an-issue]")
+
+	linked, err := database.ListSessionTickets(sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(linked) != 2 {
+		t.Fatalf("expected 2 linked tickets, got %+v", linked)
+	}
+	if linked[0].URL != "https://github.com/ops/homelab/issues/42" || linked[0].Source != db.TicketSourceMarker {
+		t.Errorf("github ticket = %+v", linked[0])
+	}
+	if linked[1].TicketID != "OPS-7" || linked[1].URL != "" {
+		t.Errorf("jira ticket without JIRA_URL = %+v", linked[1])
+	}
+}
+
+func TestTicketOpener(t *testing.T) {
+	type issue struct{ Title, Body string }
+	var opened []issue
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/ops/homelab/issues" || r.Header.Get("Authorization") != "token gitea-token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var req issue
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		opened = append(opened, req)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"number": %d, "html_url": "https://gitea.example/ops/homelab/issues/%d"}`, len(opened), len(opened))
+	}))
+	defer srv.Close()
+	t.Setenv("GITEA_URL", srv.URL)
+	t.Setenv("GITEA_TOKEN", "gitea-token")
+	tracker, err := tickets.NewTracker("gitea:ops/homelab")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, database := testManagerWithDB(t)
+	m.cfg.MaxTier = 3
+	m.cfg.DashboardURL = "https://ops.example/"
+	o := NewTicketOpener(m.cfg, database, tracker)
+	services := "sonarr"
+	insert := func(tier int, status, trigger string, parent *int64) *db.Session {
+		ended := "2026-02-15T10:05:00Z"
+		s := &db.Session{
+			Tier: tier, Model: "opus", PromptFile: "/dev/null", Status: status, Services: &services,
+			StartedAt: fmt.Sprintf("2026-02-15T10:0%d:00Z", tier), EndedAt: &ended, Trigger: trigger, ParentSessionID: parent,
+		}
+		id, err := database.InsertSession(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.ID = id
+		return s
+	}
+	drain := func() {
+		for {
+			select {
+			case job := <-o.queue:
+				job(context.Background())
+			default:
+				return
+			}
+		}
+	}
+
+	root := insert(1, "escalated", "scheduled", nil)
+	failed := insert(3, "failed", "escalation", &root.ID)
+	svc := "sonarr"
+	if _, err := database.InsertEvent(&db.Event{SessionID: &root.ID, Level: "critical", Service: &svc, Message: "HTTP 502", CreatedAt: "2026-02-15T10:01:30Z"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.InsertCooldownAction(&db.CooldownAction{Service: "sonarr", ActionType: "restart", Timestamp: "2026-02-15T10:04:00Z", Tier: 3, SessionID: &failed.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.InsertPagerIncident(&db.PagerIncident{
+		Provider: "pagerduty", DedupKey: "claude-ops/sonarr", Service: "sonarr", SessionID: &failed.ID,
+		Summary: "sonarr: Tier 3 remediation failed", CreatedAt: "2026-02-15T10:05:00Z",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	o.OnSessionEnd(failed)
+	o.OnSessionEnd(insert(3, "completed", "scheduled", nil))
+	o.OnSessionEnd(insert(3, "timed_out", "drill", nil))
+	drain()
+	if len(opened) != 1 || opened[0].Title != "Claude Ops: Tier 3 remediation failed for sonarr" {
+		t.Fatalf("opened = %+v", opened)
+	}
+	for _, want := range []string{
+		"## Timeline", "2026-02-15T10:01:30Z critical: sonarr: HTTP 502", "Tier 3 session #" + fmt.Sprint(failed.ID) + " ended: failed",
+		"## Attempted remediations", "restart sonarr (Tier 3, session #" + fmt.Sprint(failed.ID) + "): failed",
+		fmt.Sprintf("https://ops.example/sessions/%d", root.ID),
+	} {
+		if !strings.Contains(opened[0].Body, want) {
+			t.Errorf("issue body missing %q:\n%s", want, opened[0].Body)
+		}
+	}
+	linked, err := database.ListSessionTickets(failed.ID)
+	if err != nil || len(linked) != 1 {
+		t.Fatalf("linked = %+v, %v", linked, err)
+	}
+	if linked[0].TicketID != "ops/homelab#1" || linked[0].Source != db.TicketSourceAuto || linked[0].URL != "https://gitea.example/ops/homelab/issues/1" {
+		t.Errorf("linked ticket = %+v", linked[0])
+	}
+	incidents, _ := database.ListOpenPagerIncidents("sonarr")
+	if len(incidents) != 1 || incidents[0].IssueURL == nil || *incidents[0].IssueURL != linked[0].URL {
+		t.Errorf("incident issue = %+v", incidents)
+	}
+
+	// Neither the same session nor a service whose open incident has an
+	// issue gets another.
+	o.OnVerification(Verification{RemediationID: failed.ID, Unhealthy: []string{"sonarr"}})
+	held := insert(3, "completed", "scheduled", nil)
+	o.OnVerification(Verification{RemediationID: held.ID, Unhealthy: []string{"sonarr"}})
+	drain()
+	if len(opened) != 1 {
+		t.Errorf("opened a second issue: %+v", opened)
+	}
+
+	// A chain that asks to go above the maximum tier is left unresolved.
+	m.cfg.MaxTier = 2
+	top := insert(2, "escalated", "scheduled", nil)
+	o.OnEscalation(Escalation{SessionID: top.ID, FromTier: 2, ToTier: 2, Services: []string{"radarr"}})
+	o.OnEscalation(Escalation{SessionID: top.ID, FromTier: 2, ToTier: 3, Services: []string{"radarr"}})
+	drain()
+	if len(opened) != 2 || opened[1].Title != "Claude Ops: radarr still unhealthy at the maximum tier" ||
+		!strings.Contains(opened[1].Body, "above the maximum tier 2") {
+		t.Errorf("opened = %+v", opened)
+	}
+
+	// Without a paging incident, radarr's open issue still keeps another
+	// chain from opening a second one, until a session finds it healthy.
+	again := insert(2, "escalated", "scheduled", nil)
+	o.OnEscalation(Escalation{SessionID: again.ID, FromTier: 2, ToTier: 3, Services: []string{"radarr"}})
+	drain()
+	if len(opened) != 2 {
+		t.Fatalf("opened a second issue for radarr: %+v", opened)
+	}
+	healthy := insert(1, "completed", "scheduled", nil)
+	if _, err := database.InsertHealthCheck(&db.HealthCheck{SessionID: &healthy.ID, Service: "radarr", CheckType: "service", Status: "healthy", CheckedAt: "2026-02-15T11:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	o.OnSessionEnd(healthy)
+	later := insert(2, "escalated", "scheduled", nil)
+	o.OnEscalation(Escalation{SessionID: later.ID, FromTier: 2, ToTier: 3, Services: []string{"radarr"}})
+	drain()
+	if len(opened) != 3 {
+		t.Errorf("expected a new issue after radarr recovered and failed again, got %+v", opened)
+	}
+}
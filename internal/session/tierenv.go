@@ -0,0 +1,107 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// supervisorSecrets are the supervisor's own credentials, left out of every
+// CLI process's environment: the agent has no use for them, and a prompt
+// injection could otherwise read them with a shell tool.
+var supervisorSecrets = map[string]bool{
+	"CLAUDEOPS_ENCRYPTION_KEY":        true,
+	"CLAUDEOPS_ENCRYPTION_KEY_FILE":   true,
+	"CLAUDEOPS_S3_ACCESS_KEY":         true,
+	"CLAUDEOPS_S3_SECRET_KEY":         true,
+	"CLAUDEOPS_PAGERDUTY_ROUTING_KEY": true,
+	"CLAUDEOPS_OPSGENIE_API_KEY":      true,
+	"CLAUDEOPS_PROXMOX_TOKEN_SECRET":  true,
+	"CLAUDEOPS_TLS_KEY":               true,
+}
+
+// tierEnvSetting matches the CLAUDEOPS_TIER<n>_ENV settings, which hold
+// every tier's extra environment, including the tiers above the one
+// running.
+var tierEnvSetting = regexp.MustCompile(`^CLAUDEOPS_TIER[0-9]+_ENV$`)
+
+// secretValuePrefix marks a tier environment value as secret.
+const secretValuePrefix = "secret:"
+
+// envName matches a valid environment variable name.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvVar is an environment variable added to one tier's CLI process.
+type EnvVar struct {
+	Name  string
+	Value string
+	// Secret values are left out of the recorded invocation and redacted
+	// from session output.
+	Secret bool
+}
+
+// ParseTierEnv parses a CLAUDEOPS_TIER<n>_ENV value, a semicolon-separated
+// list of NAME=value pairs, e.g.
+// "ANSIBLE_CONFIG=/repos/infra/ansible.cfg;VAULT_TOKEN=secret:s.abc123".
+// A value prefixed with "secret:" is secret, as is any variable whose name
+// marks it as one (KEY, TOKEN, SECRET, PASSWORD, CRED). Malformed entries
+// are skipped.
+func ParseTierEnv(spec string) []EnvVar {
+	var vars []EnvVar
+	for _, part := range strings.Split(spec, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envName.MatchString(name) {
+			continue
+		}
+		v := EnvVar{Name: name, Value: value, Secret: isSecretEnv(strings.ToUpper(name))}
+		if rest, ok := strings.CutPrefix(value, secretValuePrefix); ok {
+			v.Value, v.Secret = rest, true
+		}
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+// loadTierEnv parses the per-tier environment from the config and registers
+// secret values with the redactor.
+func (m *Manager) loadTierEnv() {
+	for tier, spec := range map[int]string{1: m.cfg.Tier1Env, 2: m.cfg.Tier2Env, 3: m.cfg.Tier3Env} {
+		vars := ParseTierEnv(spec)
+		for _, v := range vars {
+			if v.Secret && v.Value != "" {
+				m.redactor.add(v.Value, v.Name)
+			}
+		}
+		m.tierEnv[tier] = vars
+	}
+}
+
+// tierEnvList returns the extra environment for a tier's CLI process as
+// NAME=value strings.
+func (m *Manager) tierEnvList(tier int) []string {
+	vars := m.tierEnv[tier]
+	if len(vars) == 0 {
+		return nil
+	}
+	env := make([]string, len(vars))
+	for i, v := range vars {
+		env[i] = v.Name + "=" + v.Value
+	}
+	return env
+}
+
+// childEnv returns the environment of a tier's CLI process: environ (the
+// supervisor's) without the supervisor's secrets or any tier's
+// CLAUDEOPS_TIER<n>_ENV setting, plus the tier's own extra NAME=value
+// pairs.
+func childEnv(environ, extra []string) []string {
+	env := make([]string, 0, len(environ)+len(extra))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if supervisorSecrets[name] || tierEnvSetting.MatchString(name) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env, extra...)
+}
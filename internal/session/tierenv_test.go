@@ -0,0 +1,103 @@
+package session
+
+import (
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTierEnv(t *testing.T) {
+	vars := ParseTierEnv(" ANSIBLE_CONFIG=/repos/infra/ansible.cfg ; VAULT_PASS=secret:hunter22;API_TOKEN=abcd1234;bad name=x;=y;NOEQUALS;EMPTY=")
+	want := []EnvVar{
+		{Name: "ANSIBLE_CONFIG", Value: "/repos/infra/ansible.cfg"},
+		{Name: "VAULT_PASS", Value: "hunter22", Secret: true},
+		{Name: "API_TOKEN", Value: "abcd1234", Secret: true},
+		{Name: "EMPTY", Value: ""},
+	}
+	if !slices.Equal(vars, want) {
+		t.Errorf("ParseTierEnv = %+v, want %+v", vars, want)
+	}
+}
+
+// envCapturingRunner records the working directory and extra environment
+// each session starts with.
+type envCapturingRunner struct {
+	pipeRunner
+	dirs []string
+	env  [][]string
+}
+
+func (r *envCapturingRunner) Start(ctx context.Context, model, prompt, allowed, disallowed, appendSystemPrompt, schemaPath, dir string, env []string) (io.ReadCloser, func() error, error) {
+	r.dirs = append(r.dirs, dir)
+	r.env = append(r.env, env)
+	return r.pipeRunner.Start(ctx, model, prompt, allowed, disallowed, appendSystemPrompt, schemaPath, dir, env)
+}
+
+func TestTierEnvPassedToRunnerAndRedacted(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	m.cfg.Tier1Env = "ANSIBLE_CONFIG=/repos/infra/ansible.cfg;VAULT_PASS=secret:hunter22"
+	m.cfg.Tier3Env = "ONLY_TIER3=yes"
+	m.redactor = NewRedactionFilter()
+	m.loadTierEnv()
+	runner := &envCapturingRunner{pipeRunner: pipeRunner{
+		events: []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"vault password is hunter22"}]}}`,
+			`{"type":"result","result":"Done with hunter22.","is_error":false}`,
+		},
+		resultIdx: 2,
+	}}
+	m.runner = runner
+
+	id, _, err := m.runTier(context.Background(), 1, "haiku", "/dev/null", nil, "", nil, "scheduled", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	if len(runner.env) != 1 || !slices.Equal(runner.env[0], []string{"ANSIBLE_CONFIG=/repos/infra/ansible.cfg", "VAULT_PASS=hunter22"}) {
+		t.Errorf("unexpected runner env %v", runner.env)
+	}
+
+	sess, err := database.GetSession(id)
+	if err != nil || sess == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	inv := ParseInvocation(sess.Invocation)
+	if inv == nil || inv.Env["ANSIBLE_CONFIG"] != "/repos/infra/ansible.cfg" || inv.Env["VAULT_PASS"] != "[REDACTED]" {
+		t.Errorf("unexpected invocation env %+v", inv)
+	}
+	if _, ok := inv.Env["ONLY_TIER3"]; ok {
+		t.Errorf("expected Tier 3 env left out of a Tier 1 invocation, got %v", inv.Env)
+	}
+	if sess.Response == nil || strings.Contains(*sess.Response, "hunter22") || !strings.Contains(*sess.Response, "[REDACTED:VAULT_PASS]") {
+		t.Errorf("expected secret redacted from response, got %v", sess.Response)
+	}
+}
+
+func TestChildEnvHidesOtherTiersAndSupervisorSecrets(t *testing.T) {
+	t.Setenv("CLAUDEOPS_TIER1_ENV", "ANSIBLE_CONFIG=/repos/infra/ansible.cfg")
+	t.Setenv("CLAUDEOPS_TIER3_ENV", "ROOT_PASS=secret:tier3-only")
+	t.Setenv("CLAUDEOPS_S3_SECRET_KEY", "s3-secret")
+	t.Setenv("CLAUDEOPS_ENCRYPTION_KEY", "enc-key")
+	t.Setenv("CLAUDEOPS_INTERVAL", "3600")
+	m, _ := testManager(t)
+	m.cfg.Tier1Env = os.Getenv("CLAUDEOPS_TIER1_ENV")
+	m.cfg.Tier3Env = os.Getenv("CLAUDEOPS_TIER3_ENV")
+	m.loadTierEnv()
+
+	env := childEnv(os.Environ(), m.tierEnvList(1))
+	for _, kv := range env {
+		for _, hidden := range []string{"tier3-only", "s3-secret", "enc-key", "CLAUDEOPS_TIER"} {
+			if strings.Contains(kv, hidden) {
+				t.Errorf("Tier 1 environment contains %q: %s", hidden, kv)
+			}
+		}
+	}
+	for _, want := range []string{"ANSIBLE_CONFIG=/repos/infra/ansible.cfg", "CLAUDEOPS_INTERVAL=3600"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("expected %s in Tier 1 environment", want)
+		}
+	}
+}
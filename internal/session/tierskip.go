@@ -0,0 +1,95 @@
+package session
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// skippedTiers returns the tiers an escalation from fromTier to toTier
+// passes over, e.g. [2] for 1 to 3.
+func skippedTiers(fromTier, toTier int) []int {
+	var tiers []int
+	for t := fromTier + 1; t < toTier; t++ {
+		tiers = append(tiers, t)
+	}
+	return tiers
+}
+
+// tierList formats tiers as "tier 2" or "tiers 2, 3".
+func tierList(tiers []int) string {
+	s := make([]string, len(tiers))
+	for i, t := range tiers {
+		s[i] = strconv.Itoa(t)
+	}
+	if len(tiers) == 1 {
+		return "tier " + s[0]
+	}
+	return "tiers " + strings.Join(s, ", ")
+}
+
+// tierSkipOutcome applies cfg.NoTierSkip to an escalation from fromTier to
+// toTier. It returns the tier to escalate to and, when a skip was not
+// allowed, a message saying so.
+func tierSkipOutcome(noSkip bool, fromTier, toTier int) (int, string) {
+	if !noSkip || toTier <= fromTier+1 {
+		return toTier, ""
+	}
+	return fromTier + 1, fmt.Sprintf("Tier skip not allowed: escalating to tier %d instead of tier %d, which would skip %s",
+		fromTier+1, toTier, tierList(skippedTiers(fromTier, toTier)))
+}
+
+// checkTierSkip lowers an escalation that skips a tier to the next tier
+// when skipping is not allowed, with an event saying so. It returns the
+// tier to escalate to and the message, if any.
+func (m *Manager) checkTierSkip(sessionID int64, fromTier, toTier int) (int, string) {
+	tier, msg := tierSkipOutcome(m.cfg.NoTierSkip, fromTier, toTier)
+	if msg != "" {
+		m.emitEscalationEventLevel(sessionID, "info", msg)
+	}
+	return tier, msg
+}
+
+// tierSkipMessage describes an escalation from fromTier to toTier that
+// skips a tier, or returns "" when it does not.
+func tierSkipMessage(fromTier, toTier int) string {
+	skipped := skippedTiers(fromTier, toTier)
+	if len(skipped) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Tier %d escalated directly to tier %d, skipping %s", fromTier, toTier, tierList(skipped))
+}
+
+// sessionSkippedTiers returns the tiers skipped between the parent session
+// and a tier-tier session escalated from it, comma-separated, or nil when
+// none were.
+func (m *Manager) sessionSkippedTiers(parentSessionID *int64, tier int) *string {
+	if parentSessionID == nil {
+		return nil
+	}
+	parent, err := m.db.GetSession(*parentSessionID)
+	if err != nil || parent == nil {
+		return nil
+	}
+	skipped := skippedTiers(parent.Tier, tier)
+	if len(skipped) == 0 {
+		return nil
+	}
+	s := make([]string, len(skipped))
+	for i, t := range skipped {
+		s[i] = strconv.Itoa(t)
+	}
+	joined := strings.Join(s, ",")
+	return &joined
+}
+
+// joinReasons joins the non-empty reasons with "; ".
+func joinReasons(reasons []string) string {
+	var kept []string
+	for _, r := range reasons {
+		if r != "" {
+			kept = append(kept, r)
+		}
+	}
+	return strings.Join(kept, "; ")
+}
@@ -0,0 +1,94 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/testkit"
+)
+
+func TestTierSkip(t *testing.T) {
+	for _, noSkip := range []bool{false, true} {
+		runner := testkit.NewRunner(
+			testkit.Script{Events: []testkit.Event{
+				testkit.Result("Caddy is down with the known bad reload.", testkit.EscalateTo(3, "caddy config reload failure, fix in memory", "caddy")),
+			}},
+			testkit.Script{Events: []testkit.Event{
+				testkit.Result("Restored the previous caddy config.", testkit.Healthy("caddy restored", "caddy")),
+			}},
+		)
+		m, database := testManagerWithDB(t)
+		m.cfg.DryRun = false
+		m.cfg.MaxTier = 3
+		m.cfg.NoTierSkip = noSkip
+		m.cfg.Tier2Prompt = "/dev/null"
+		m.cfg.Tier3Prompt = "/dev/null"
+		m.runner = runner
+
+		rootID := m.runEscalationChain(context.Background(), "scheduled", nil, 1, "", nil)
+
+		calls := runner.Calls()
+		wantModel := "opus"
+		if noSkip {
+			wantModel = "sonnet"
+		}
+		if len(calls) != 2 || calls[1].Model != wantModel {
+			t.Fatalf("noSkip=%t: unexpected CLI calls %+v", noSkip, calls)
+		}
+		d, err := database.GetEscalationDecision(rootID)
+		if err != nil || d == nil {
+			t.Fatalf("noSkip=%t: decision %+v (%v)", noSkip, d, err)
+		}
+		children, err := database.GetChildSessions(rootID)
+		if err != nil || len(children) != 1 {
+			t.Fatalf("noSkip=%t: GetChildSessions: %+v (%v)", noSkip, children, err)
+		}
+		child := children[0]
+		if noSkip {
+			if d.RequestedTier != 3 || d.Tier != 2 || !strings.Contains(d.Reason, "Tier skip not allowed") {
+				t.Errorf("skip not capped: %+v", d)
+			}
+			if child.Tier != 2 || child.SkippedTiers != nil {
+				t.Errorf("capped child = %+v", child)
+			}
+			continue
+		}
+		if d.Outcome != DecisionEscalated || d.Tier != 3 || d.Reason != "Tier 1 escalated directly to tier 3, skipping tier 2" {
+			t.Errorf("skip decision = %+v", d)
+		}
+		if child.Tier != 3 || child.SkippedTiers == nil || *child.SkippedTiers != "2" {
+			t.Errorf("skipping child = %+v", child)
+		}
+	}
+}
+
+func TestSimulateTierSkip(t *testing.T) {
+	m, _ := testManagerWithDB(t)
+	m.cfg.DryRun = false
+	m.cfg.MaxTier = 3
+	sim, err := m.Simulate(SimulationRequest{RecommendedTier: 3, ServicesAffected: []string{"caddy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Tier != 3 || !hasStep(sim, "tier_skip", "info") {
+		t.Errorf("skip simulation = %+v", sim)
+	}
+
+	m.cfg.NoTierSkip = true
+	if sim, err = m.Simulate(SimulationRequest{RecommendedTier: 3, ServicesAffected: []string{"caddy"}}); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Tier != 2 || !hasStep(sim, "tier_skip", "cap") {
+		t.Errorf("capped skip simulation = %+v", sim)
+	}
+}
+
+func hasStep(sim *Simulation, check, outcome string) bool {
+	for _, s := range sim.Steps {
+		if s.Check == check && s.Outcome == outcome {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// verifyRequest carries a Tier 3 remediation that is due for verification.
+type verifyRequest struct {
+	remediationID int64
+	services      []string
+	// tier is the tier the verification runs at (Tier 1 when 0). When the
+	// remediation session handed back to a lower tier, handBackTier is its
+	// own tier and findings what it handed back.
+	tier         int
+	handBackTier int
+	findings     string
+}
+
+// scheduleVerification queues an observation-only verification session for
+// services VerifyDelay minutes after the Tier 3 session remediationID
+// completed. The request is picked up by the interval wait like a pulse
+// trigger; if it fires while a chain is running it waits for that chain.
+func (m *Manager) scheduleVerification(remediationID int64, services []string) {
+	if m.cfg.VerifyDelay <= 0 || len(services) == 0 {
+		return
+	}
+	delay := time.Duration(m.cfg.VerifyDelay) * time.Minute
+	m.emitEscalationEventLevel(remediationID, "info", fmt.Sprintf("Verification of %s scheduled in %s",
+		strings.Join(services, ", "), delay))
+
+	req := verifyRequest{remediationID: remediationID, services: services}
+	time.AfterFunc(delay, func() {
+		select {
+		case m.verifyCh <- req:
+		default:
+			fmt.Fprintf(os.Stderr, "verification of session %d dropped: queue full\n", remediationID)
+		}
+	})
+}
+
+// runVerification runs the verification session as a child of the
+// remediation session. When a verified service is still unhealthy, the
+// remediation session is marked "reopened" and a notification is sent; the
+// supervisor never starts a second remediation on its own.
+func (m *Manager) runVerification(ctx context.Context, req verifyRequest) {
+	m.beginChain(req.services)
+	defer m.endChain()
+	// The verification result completes the remediation chain's summary.
+	if sessionID := m.verify(ctx, req); sessionID != 0 {
+		m.recordChainSummary(ctx, sessionID)
+	}
+}
+
+// verify runs the verification session for req and handles its result (see
+// runVerification). It returns the session's ID, or 0 if none was started.
+func (m *Manager) verify(ctx context.Context, req verifyRequest) int64 {
+	parentID := req.remediationID
+	tier, model := m.verifyTier(req.tier)
+	sessionID, agentResp, err := m.runTier(ctx, tier, model, m.cfg.VerifyPrompt, &parentID,
+		m.buildVerifyContext(req), req.services, "verify", nil)
+	// A verification session never escalates; discard any handoff it wrote.
+	_ = DeleteHandoff(m.cfg.StateDir)
+	if err != nil {
+		fmt.Printf("[%s] ERROR: verification of session %d failed: %v\n",
+			time.Now().UTC().Format(time.RFC3339), req.remediationID, err)
+		return sessionID
+	}
+	if sessionID == 0 {
+		return 0
+	}
+	if agentResp == nil {
+		m.emitEscalationEventLevel(sessionID, "warning", "Verification inconclusive: no structured output from the verification session")
+		return sessionID
+	}
+
+	unhealthy := unverifiedServices(agentResp, req.services)
+	m.runHooks("OnVerification", func(h Hooks) {
+		h.OnVerification(Verification{
+			SessionID:     sessionID,
+			RemediationID: req.remediationID,
+			Services:      req.services,
+			Unhealthy:     unhealthy,
+		})
+	})
+	if len(unhealthy) == 0 {
+		m.emitEscalationEventLevel(sessionID, "info", fmt.Sprintf("Remediation verified: %s healthy",
+			strings.Join(req.services, ", ")))
+		return sessionID
+	}
+
+	if err := m.db.UpdateSessionStatus(req.remediationID, "reopened"); err != nil {
+		fmt.Fprintf(os.Stderr, "update reopened status for session %d: %v\n", req.remediationID, err)
+	}
+	m.markRemediationFailed(req.remediationID)
+	msg := fmt.Sprintf("Remediation did not hold: %s still unhealthy %d minutes after Tier 3 session #%d",
+		strings.Join(unhealthy, ", "), m.cfg.VerifyDelay, req.remediationID)
+	if req.handBackTier != 0 {
+		msg = fmt.Sprintf("Remediation did not hold: %s still unhealthy after Tier %d session #%d handed back",
+			strings.Join(unhealthy, ", "), req.handBackTier, req.remediationID)
+	}
+	m.emitEscalationEvent(sessionID, msg)
+
+	var body strings.Builder
+	body.WriteString(msg + "\n")
+	for _, sc := range agentResp.ServicesChecked {
+		if sc.Status != "healthy" && sc.Detail != "" {
+			fmt.Fprintf(&body, "\n%s (%s): %s", sc.Name, sc.Status, sc.Detail)
+		}
+	}
+	if err := m.notify(ctx, "Claude Ops: Remediation did not hold — "+strings.Join(unhealthy, ", "), body.String()); err != nil {
+		fmt.Fprintf(os.Stderr, "verification notify: %v\n", err)
+	}
+	return sessionID
+}
+
+// buildVerifyContext renders the verification scope for the session prompt.
+func (m *Manager) buildVerifyContext(req verifyRequest) string {
+	var b strings.Builder
+	if req.handBackTier != 0 {
+		b.WriteString("## Hand-Back Verification\n\n")
+		fmt.Fprintf(&b, "Tier %d session #%d worked on the following services and handed back to you to verify its work. Verify that each is healthy now; do not remediate:\n\n",
+			req.handBackTier, req.remediationID)
+		for _, svc := range req.services {
+			fmt.Fprintf(&b, "- %s\n", svc)
+		}
+		if req.findings != "" {
+			b.WriteString("\n### What it reported\n\n" + req.findings + "\n")
+		}
+		return b.String()
+	}
+	b.WriteString("## Post-Remediation Verification\n\n")
+	fmt.Fprintf(&b, "Tier 3 session #%d remediated the following services about %d minutes ago. Verify that each is healthy now:\n\n",
+		req.remediationID, m.cfg.VerifyDelay)
+	for _, svc := range req.services {
+		fmt.Fprintf(&b, "- %s\n", svc)
+	}
+	return b.String()
+}
+
+// verifyTier returns the tier a verification requested at tier runs at,
+// Tier 1 unless Tier 2 was asked for, and its model.
+func (m *Manager) verifyTier(tier int) (int, string) {
+	if tier == 2 {
+		return 2, m.cfg.Tier2Model
+	}
+	return 1, m.cfg.VerifyModel
+}
+
+// remediatedServices returns the services a Tier 3 session worked on: the
+// services named in its handoff, or, for a chain started directly at Tier 3,
+// the services it reported checking.
+func remediatedServices(handoffServices []string, resp *AgentResponse) []string {
+	if len(handoffServices) > 0 || resp == nil {
+		return handoffServices
+	}
+	var services []string
+	for _, sc := range resp.ServicesChecked {
+		services = append(services, sc.Name)
+	}
+	return services
+}
+
+// unverifiedServices returns the scoped services the verification session
+// did not report as healthy. A service missing from services_checked counts
+// as unhealthy, as does every scoped service when the agent asked to escalate
+// without naming any.
+func unverifiedServices(resp *AgentResponse, services []string) []string {
+	status := make(map[string]string, len(resp.ServicesChecked))
+	for _, sc := range resp.ServicesChecked {
+		status[strings.ToLower(sc.Name)] = sc.Status
+	}
+	var unhealthy []string
+	for _, svc := range services {
+		if status[strings.ToLower(svc)] != "healthy" {
+			unhealthy = append(unhealthy, svc)
+		}
+	}
+	if len(unhealthy) == 0 && resp.Escalation.Needed {
+		return services
+	}
+	return unhealthy
+}
+
+// Notify sends a supervisor notification to the configured Apprise URLs.
+func (m *Manager) Notify(ctx context.Context, title, body string) error {
+	return m.notify(ctx, title, body)
+}
+
+// notifyApprise sends a notification with the apprise CLI to the configured
+// URLs. It is a no-op when no URLs are configured or in dry-run mode.
+func (m *Manager) notifyApprise(ctx context.Context, title, body string) error {
+	if m.cfg.AppriseURLs == "" || m.cfg.DryRun {
+		return nil
+	}
+	out, err := exec.CommandContext(ctx, "apprise", "-t", title, "-b", body, m.cfg.AppriseURLs).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("apprise: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package session
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestUnverifiedServices(t *testing.T) {
+	resp := &AgentResponse{ServicesChecked: []ServiceCheck{
+		{Name: "Jellyfin", Status: "healthy"},
+		{Name: "caddy", Status: "down"},
+	}}
+	got := unverifiedServices(resp, []string{"jellyfin", "caddy", "postgres"})
+	if want := []string{"caddy", "postgres"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unverifiedServices = %v, want %v", got, want)
+	}
+
+	resp = &AgentResponse{
+		ServicesChecked: []ServiceCheck{{Name: "jellyfin", Status: "healthy"}},
+		Escalation:      AgentEscalation{Needed: true},
+	}
+	if got := unverifiedServices(resp, []string{"jellyfin"}); !reflect.DeepEqual(got, []string{"jellyfin"}) {
+		t.Errorf("expected escalation request to count as unhealthy, got %v", got)
+	}
+}
+
+func TestRemediatedServices(t *testing.T) {
+	resp := &AgentResponse{ServicesChecked: []ServiceCheck{{Name: "caddy", Status: "healthy"}}}
+	if got := remediatedServices([]string{"jellyfin"}, resp); !reflect.DeepEqual(got, []string{"jellyfin"}) {
+		t.Errorf("expected handoff services, got %v", got)
+	}
+	if got := remediatedServices(nil, resp); !reflect.DeepEqual(got, []string{"caddy"}) {
+		t.Errorf("expected checked services, got %v", got)
+	}
+	if got := remediatedServices(nil, nil); got != nil {
+		t.Errorf("expected no services, got %v", got)
+	}
+}
+
+func TestRunVerification(t *testing.T) {
+	tests := []struct {
+		name                  string
+		status                string
+		wantRemediationStatus string
+		wantNotify            bool
+	}{
+		{"held", "healthy", "completed", false},
+		{"reopened", "down", "reopened", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, database := testManagerWithDB(t)
+			m.cfg.VerifyDelay = 15
+			m.cfg.VerifyModel = "haiku"
+			m.cfg.VerifyPrompt = "/dev/null"
+			var notified []string
+			m.notify = func(_ context.Context, title, _ string) error {
+				notified = append(notified, title)
+				return nil
+			}
+			m.runner = &pipeRunner{
+				events: []string{
+					`{"type":"system","subtype":"init"}`,
+					`{"type":"result","result":"Checked.","is_error":false,"structured_output":{"summary":"checked","events":[],"escalation":{"needed":false},"services_checked":[{"name":"jellyfin","status":"` + tt.status + `","detail":"HTTP 502"}]}}`,
+				},
+				resultIdx: 1,
+			}
+
+			remediationID, err := database.InsertSession(&db.Session{
+				Tier: 3, Model: "opus", PromptFile: "/dev/null", Status: "completed",
+				StartedAt: time.Now().UTC().Format(time.RFC3339), Trigger: "escalation",
+			})
+			if err != nil {
+				t.Fatalf("insert session: %v", err)
+			}
+
+			m.runVerification(context.Background(), verifyRequest{remediationID: remediationID, services: []string{"jellyfin"}})
+
+			children, err := database.GetChildSessions(remediationID)
+			if err != nil || len(children) != 1 {
+				t.Fatalf("expected one verification session linked to the remediation, got %d (err %v)", len(children), err)
+			}
+			s := children[0]
+			if s.Trigger != "verify" || s.Tier != 1 {
+				t.Errorf("unexpected verification session %+v", s)
+			}
+			if s.Status != "completed" {
+				t.Errorf("verification status = %q, want completed", s.Status)
+			}
+			remediation, err := database.GetSession(remediationID)
+			if err != nil || remediation == nil {
+				t.Fatalf("GetSession: %v", err)
+			}
+			if remediation.Status != tt.wantRemediationStatus {
+				t.Errorf("remediation status = %q, want %q", remediation.Status, tt.wantRemediationStatus)
+			}
+			if tt.wantNotify != (len(notified) == 1) {
+				t.Errorf("notifications = %v, want notify=%v", notified, tt.wantNotify)
+			}
+			if tt.wantNotify && !strings.Contains(notified[0], "jellyfin") {
+				t.Errorf("expected service in notification title, got %q", notified[0])
+			}
+		})
+	}
+}
+
+func TestScheduleVerification_Disabled(t *testing.T) {
+	m, _ := testManager(t)
+	m.scheduleVerification(1, []string{"jellyfin"})
+	select {
+	case req := <-m.verifyCh:
+		t.Fatalf("expected no verification when VerifyDelay is 0, got %+v", req)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
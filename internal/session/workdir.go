@@ -0,0 +1,67 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitTimeout bounds the git lookup made as a session starts.
+const gitTimeout = 5 * time.Second
+
+// tierDir returns the configured working directory for a tier's CLI
+// process, or "" to inherit the supervisor's.
+func (m *Manager) tierDir(tier int) string {
+	switch tier {
+	case 1:
+		return m.cfg.Tier1Dir
+	case 2:
+		return m.cfg.Tier2Dir
+	case 3:
+		return m.cfg.Tier3Dir
+	}
+	return ""
+}
+
+// checkTierDirs warns about configured working directories that do not
+// exist; sessions of that tier fail to start until they do.
+func (m *Manager) checkTierDirs() {
+	for tier := 1; tier <= 3; tier++ {
+		dir := m.tierDir(tier)
+		if dir == "" {
+			continue
+		}
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "warning: Tier %d working directory %s is not a directory\n", tier, dir)
+		}
+	}
+}
+
+// resolveWorkDir returns the absolute directory a session started in dir
+// runs in, and the commit checked out there, or "" when it is not a git
+// repository.
+func resolveWorkDir(ctx context.Context, dir string) (abs, sha string) {
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	if a, err := filepath.Abs(dir); err == nil {
+		dir = a
+	}
+	return dir, gitHead(ctx, dir)
+}
+
+// gitHead returns the commit checked out in dir, or "" when dir is not in a
+// git repository or git is unavailable.
+func gitHead(ctx context.Context, dir string) string {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,65 @@
+package session
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+// gitRepo creates a git repository with one commit and returns its path and
+// HEAD commit.
+func gitRepo(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir, gitHead(context.Background(), dir)
+}
+
+func TestTierDirRecordedOnSession(t *testing.T) {
+	m, database := testManagerWithDB(t)
+	repo, sha := gitRepo(t)
+	if len(sha) != 40 {
+		t.Fatalf("unexpected HEAD %q", sha)
+	}
+	m.cfg.Tier2Dir = repo
+	runner := &envCapturingRunner{pipeRunner: pipeRunner{
+		events:    []string{`{"type":"result","result":"Done.","is_error":false}`},
+		resultIdx: 0,
+	}}
+	m.runner = runner
+
+	id, _, err := m.runTier(context.Background(), 2, "sonnet", "/dev/null", nil, "", nil, "manual", nil)
+	if err != nil {
+		t.Fatalf("runTier: %v", err)
+	}
+	if len(runner.dirs) != 1 || runner.dirs[0] != repo {
+		t.Errorf("expected the CLI started in %s, got %v", repo, runner.dirs)
+	}
+	sess, err := database.GetSession(id)
+	if err != nil || sess == nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if sess.WorkDir == nil || *sess.WorkDir != repo || sess.GitSHA == nil || *sess.GitSHA != sha {
+		t.Errorf("expected work dir %s at %s, got %v at %v", repo, sha, sess.WorkDir, sess.GitSHA)
+	}
+
+	// Other tiers inherit the supervisor's directory; outside a git
+	// repository no commit is recorded.
+	plain := t.TempDir()
+	if dir, sha := resolveWorkDir(context.Background(), plain); dir != plain || sha != "" {
+		t.Errorf("resolveWorkDir(%s) = %q, %q", plain, dir, sha)
+	}
+	if m.tierDir(1) != "" {
+		t.Errorf("expected no Tier 1 directory, got %q", m.tierDir(1))
+	}
+}
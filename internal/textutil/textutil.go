@@ -0,0 +1,107 @@
+// Package textutil shortens text for display, storage, and notification
+// payloads without producing invalid UTF-8 or splitting a user-perceived
+// character: a base letter and its combining marks, an emoji and its skin
+// tone or variation selector, a ZWJ emoji sequence, a flag (a pair of
+// regional indicators), or a CRLF pair.
+package textutil
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ellipsis is appended by Truncate when it shortens text.
+const Ellipsis = "…"
+
+// Cut returns the longest prefix of s of at most n runes that ends on a
+// character boundary, and whether s was shortened.
+func Cut(s string, n int) (string, bool) {
+	i, count := 0, 0
+	for i < len(s) && count < n {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		count++
+	}
+	if i >= len(s) {
+		return s, false
+	}
+	return s[:boundary(s, i)], true
+}
+
+// CutBytes is Cut with the limit in bytes, for storage caps.
+func CutBytes(s string, n int) (string, bool) {
+	if len(s) <= n {
+		return s, false
+	}
+	if n < 0 {
+		n = 0
+	}
+	i := n
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:boundary(s, i)], true
+}
+
+// Truncate shortens s to at most n runes, ending in Ellipsis when it was
+// cut, for fields with a hard length cap.
+func Truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	cut, _ := Cut(s, n-1)
+	return cut + Ellipsis
+}
+
+// boundary moves the byte offset i, which is on a rune boundary, back until
+// it does not fall inside a character.
+func boundary(s string, i int) int {
+	for i > 0 && i < len(s) {
+		next, _ := utf8.DecodeRuneInString(s[i:])
+		prev, size := utf8.DecodeLastRuneInString(s[:i])
+		switch {
+		case extends(next), prev == zwj, prev == '\r' && next == '\n':
+			i -= size
+		case isRegional(next) && isRegional(prev) && regionalRun(s[:i])%2 == 1:
+			// prev opens a flag that next completes.
+			i -= size
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+const zwj = '\u200d'
+
+// extends reports whether r attaches to the character before it.
+func extends(r rune) bool {
+	switch {
+	case r == zwj,
+		r >= 0xFE00 && r <= 0xFE0F, // variation selectors
+		r >= 0xE0100 && r <= 0xE01EF,
+		r >= 0x1F3FB && r <= 0x1F3FF, // emoji skin tone modifiers
+		r >= 0xE0020 && r <= 0xE007F: // tags, as in subdivision flags
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+func isRegional(r rune) bool { return r >= 0x1F1E6 && r <= 0x1F1FF }
+
+// regionalRun counts the regional indicators that end s.
+func regionalRun(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if !isRegional(r) {
+			break
+		}
+		n++
+		s = s[:len(s)-size]
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package textutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCut(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    string
+		n    int
+		want string
+		cut  bool
+	}{
+		{"short", "hello", 10, "hello", false},
+		{"exact", "hello", 5, "hello", false},
+		{"ascii", "hello world", 5, "hello", true},
+		{"cjk", "世界你好", 2, "世界", true},
+		{"combining mark", "cafe\u0301 au lait", 4, "caf", true},
+		{"skin tone", "hi 👋🏽 there", 4, "hi ", true},
+		{"variation selector", "ok ❤\ufe0f yes", 4, "ok ", true},
+		{"zwj sequence", "a👩\u200d💻b", 2, "a", true},
+		{"zwj sequence end", "a👩\u200d💻b", 4, "a👩\u200d💻", true},
+		{"flag", "🇯🇵🇫🇷x", 1, "", true},
+		{"second flag", "🇯🇵🇫🇷x", 3, "🇯🇵", true},
+		{"whole flags", "🇯🇵🇫🇷x", 4, "🇯🇵🇫🇷", true},
+		{"crlf", "ab\r\ncd", 3, "ab", true},
+		{"devanagari", "नमस्ते", 3, "नम", true},
+		{"zero", "abc", 0, "", true},
+	} {
+		got, cut := Cut(tc.s, tc.n)
+		if got != tc.want || cut != tc.cut {
+			t.Errorf("%s: Cut(%q, %d) = %q, %v; want %q, %v", tc.name, tc.s, tc.n, got, cut, tc.want, tc.cut)
+		}
+	}
+}
+
+func TestCutBytes(t *testing.T) {
+	s := "ab世界👋🏽"
+	for n, want := range map[int]string{0: "", 3: "ab", 4: "ab", 5: "ab世", 8: "ab世界", 12: "ab世界", 16: s, 99: s} {
+		if got, _ := CutBytes(s, n); got != want {
+			t.Errorf("CutBytes(%q, %d) = %q, want %q", s, n, got, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("hello", 5); got != "hello" {
+		t.Errorf("Truncate short = %q", got)
+	}
+	if got := Truncate("hello world", 6); got != "hello…" {
+		t.Errorf("Truncate = %q", got)
+	}
+	if got := Truncate("abc", 0); got != "" {
+		t.Errorf("Truncate to 0 = %q", got)
+	}
+	// Every cut of mixed text stays valid UTF-8 within the limit.
+	s := strings.Repeat("Ré👩\u200d💻🇯🇵世é ", 20)
+	for n := 1; n < utf8.RuneCountInString(s); n++ {
+		got := Truncate(s, n)
+		if !utf8.ValidString(got) || utf8.RuneCountInString(got) > n {
+			t.Fatalf("Truncate(s, %d) = %q", n, got)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Package tickets links sessions to issues in external trackers: GitHub and
+// Gitea issues, referenced as owner/repo#123, and Jira issues, referenced by
+// key (OPS-123). It validates references, builds their links, and opens
+// issues for incidents the agent could not resolve.
+//
+// Like pull requests, GitHub is reached with GITHUB_TOKEN and Gitea at
+// GITEA_URL with GITEA_TOKEN. Jira is at JIRA_URL, authenticated with
+// JIRA_EMAIL and JIRA_TOKEN.
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Providers.
+const (
+	GitHub = "github"
+	Gitea  = "gitea"
+	Jira   = "jira"
+)
+
+// githubURL and githubAPIURL are GitHub's web and REST API bases, replaced
+// in tests.
+var (
+	githubURL    = "https://github.com"
+	githubAPIURL = "https://api.github.com"
+)
+
+var (
+	// issueRe matches owner/repo#123.
+	issueRe = regexp.MustCompile(`^([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)#([0-9]+)$`)
+	// jiraKeyRe matches a Jira issue key, PROJ-123.
+	jiraKeyRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-[0-9]+$`)
+	// repoRe matches owner/repo.
+	repoRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	// projectRe matches a Jira project key.
+	projectRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+)
+
+// Ref is a reference to an issue in a tracker.
+type Ref struct {
+	Provider string
+	ID       string
+}
+
+// Parse validates a reference to an issue: owner/repo#123 for GitHub and
+// Gitea, a key like OPS-123 for Jira.
+func Parse(provider, id string) (Ref, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	id = strings.TrimSpace(id)
+	switch provider {
+	case GitHub, Gitea:
+		if !issueRe.MatchString(id) {
+			return Ref{}, fmt.Errorf("%s issue %q is not in the form owner/repo#123", provider, id)
+		}
+	case Jira:
+		id = strings.ToUpper(id)
+		if !jiraKeyRe.MatchString(id) {
+			return Ref{}, fmt.Errorf("jira issue %q is not a key like OPS-123", id)
+		}
+	default:
+		return Ref{}, fmt.Errorf("unknown ticket provider %q (want github, gitea, or jira)", provider)
+	}
+	return Ref{Provider: provider, ID: id}, nil
+}
+
+// URL returns the issue's web page, or "" for Gitea and Jira issues when
+// GITEA_URL or JIRA_URL is not set.
+func (r Ref) URL() string {
+	switch r.Provider {
+	case GitHub, Gitea:
+		m := issueRe.FindStringSubmatch(r.ID)
+		if m == nil {
+			return ""
+		}
+		base := githubURL
+		if r.Provider == Gitea {
+			if base = strings.TrimRight(os.Getenv("GITEA_URL"), "/"); base == "" {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%s/%s/%s/issues/%s", base, m[1], m[2], m[3])
+	case Jira:
+		if base := strings.TrimRight(os.Getenv("JIRA_URL"), "/"); base != "" {
+			return base + "/browse/" + r.ID
+		}
+	}
+	return ""
+}
+
+// Tracker opens issues in one project: a GitHub or Gitea repository or a
+// Jira project.
+type Tracker struct {
+	provider string
+	project  string // owner/repo, or the Jira project key
+	client   *http.Client
+}
+
+// NewTracker returns a Tracker for project, given as github:owner/repo,
+// gitea:owner/repo, or jira:PROJ (CLAUDEOPS_TICKET_PROJECT). Returns nil
+// when project is empty, and an error when it is malformed or the
+// provider's credentials are not set.
+func NewTracker(project string) (*Tracker, error) {
+	if project == "" {
+		return nil, nil
+	}
+	provider, name, ok := strings.Cut(project, ":")
+	if !ok {
+		return nil, fmt.Errorf("ticket project %q is not provider:project", project)
+	}
+	t := &Tracker{provider: strings.ToLower(provider), project: name, client: &http.Client{Timeout: 30 * time.Second}}
+	var missing []string
+	switch t.provider {
+	case GitHub:
+		if !repoRe.MatchString(name) {
+			return nil, fmt.Errorf("ticket project %q: want github:owner/repo", project)
+		}
+		missing = unset("GITHUB_TOKEN")
+	case Gitea:
+		if !repoRe.MatchString(name) {
+			return nil, fmt.Errorf("ticket project %q: want gitea:owner/repo", project)
+		}
+		missing = unset("GITEA_URL", "GITEA_TOKEN")
+	case Jira:
+		if !projectRe.MatchString(name) {
+			return nil, fmt.Errorf("ticket project %q: want jira:PROJ with an uppercase project key", project)
+		}
+		missing = unset("JIRA_URL", "JIRA_EMAIL", "JIRA_TOKEN")
+	default:
+		return nil, fmt.Errorf("ticket project %q: unknown provider %q (want github, gitea, or jira)", project, provider)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("ticket project %q: %s not set", project, strings.Join(missing, " and "))
+	}
+	return t, nil
+}
+
+func unset(names ...string) []string {
+	var out []string
+	for _, n := range names {
+		if os.Getenv(n) == "" {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// String returns the tracker's project as configured.
+func (t *Tracker) String() string { return t.provider + ":" + t.project }
+
+// Open opens an issue and returns its reference and web page.
+func (t *Tracker) Open(ctx context.Context, title, body string) (Ref, string, error) {
+	switch t.provider {
+	case Jira:
+		base := strings.TrimRight(os.Getenv("JIRA_URL"), "/")
+		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(os.Getenv("JIRA_EMAIL")+":"+os.Getenv("JIRA_TOKEN")))
+		var created struct {
+			Key string `json:"key"`
+		}
+		err := t.post(ctx, base+"/rest/api/2/issue", auth, map[string]any{
+			"fields": map[string]any{
+				"project":     map[string]string{"key": t.project},
+				"summary":     title,
+				"description": body,
+				"issuetype":   map[string]string{"name": "Task"},
+			},
+		}, &created)
+		if err != nil {
+			return Ref{}, "", err
+		}
+		if created.Key == "" {
+			return Ref{}, "", errors.New("open jira issue: unexpected response")
+		}
+		ref := Ref{Provider: Jira, ID: created.Key}
+		return ref, base + "/browse/" + created.Key, nil
+	default:
+		endpoint, auth := githubAPIURL+"/repos/"+t.project+"/issues", "Bearer "+os.Getenv("GITHUB_TOKEN")
+		if t.provider == Gitea {
+			endpoint = strings.TrimRight(os.Getenv("GITEA_URL"), "/") + "/api/v1/repos/" + t.project + "/issues"
+			auth = "token " + os.Getenv("GITEA_TOKEN")
+		}
+		var created struct {
+			Number  int    `json:"number"`
+			HTMLURL string `json:"html_url"`
+		}
+		if err := t.post(ctx, endpoint, auth, map[string]string{"title": title, "body": body}, &created); err != nil {
+			return Ref{}, "", err
+		}
+		if created.Number == 0 {
+			return Ref{}, "", fmt.Errorf("open %s issue: unexpected response", t.provider)
+		}
+		ref := Ref{Provider: t.provider, ID: fmt.Sprintf("%s#%d", t.project, created.Number)}
+		return ref, created.HTMLURL, nil
+	}
+}
+
+// post POSTs payload as JSON and decodes the response into out.
+func (t *Tracker) post(ctx context.Context, endpoint, auth string, payload, out any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("open %s issue: %w", t.provider, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("open %s issue: status %s: %s", t.provider, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("open %s issue: %w", t.provider, err)
+	}
+	return nil
+}
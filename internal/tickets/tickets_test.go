@@ -0,0 +1,147 @@
+package tickets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		provider, id string
+		want         Ref
+		wantErr      string
+	}{
+		{provider: "github", id: "ops/homelab#42", want: Ref{GitHub, "ops/homelab#42"}},
+		{provider: " GitHub ", id: " ops/home.lab#1 ", want: Ref{GitHub, "ops/home.lab#1"}},
+		{provider: "gitea", id: "ops/homelab#7", want: Ref{Gitea, "ops/homelab#7"}},
+		{provider: "jira", id: "ops-123", want: Ref{Jira, "OPS-123"}},
+		{provider: "github", id: "homelab#42", wantErr: "owner/repo#123"},
+		{provider: "gitea", id: "ops/homelab#abc", wantErr: "owner/repo#123"},
+		{provider: "jira", id: "123", wantErr: "OPS-123"},
+		{provider: "linear", id: "OPS-1", wantErr: "unknown ticket provider"},
+	} {
+		got, err := Parse(tc.provider, tc.id)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Parse(%q, %q) error = %v, want %q", tc.provider, tc.id, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("Parse(%q, %q) = %+v, %v", tc.provider, tc.id, got, err)
+		}
+	}
+}
+
+func TestRefURL(t *testing.T) {
+	t.Setenv("GITEA_URL", "")
+	t.Setenv("JIRA_URL", "")
+	if got := (Ref{GitHub, "ops/homelab#42"}).URL(); got != "https://github.com/ops/homelab/issues/42" {
+		t.Errorf("github URL = %q", got)
+	}
+	if got := (Ref{Gitea, "ops/homelab#7"}).URL(); got != "" {
+		t.Errorf("gitea URL without GITEA_URL = %q", got)
+	}
+	if got := (Ref{Jira, "OPS-1"}).URL(); got != "" {
+		t.Errorf("jira URL without JIRA_URL = %q", got)
+	}
+
+	t.Setenv("GITEA_URL", "https://gitea.example/")
+	t.Setenv("JIRA_URL", "https://example.atlassian.net")
+	if got := (Ref{Gitea, "ops/homelab#7"}).URL(); got != "https://gitea.example/ops/homelab/issues/7" {
+		t.Errorf("gitea URL = %q", got)
+	}
+	if got := (Ref{Jira, "OPS-1"}).URL(); got != "https://example.atlassian.net/browse/OPS-1" {
+		t.Errorf("jira URL = %q", got)
+	}
+}
+
+func TestNewTracker(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("JIRA_URL", "https://example.atlassian.net")
+	t.Setenv("JIRA_EMAIL", "ops@example.com")
+	t.Setenv("JIRA_TOKEN", "")
+
+	if tr, err := NewTracker(""); tr != nil || err != nil {
+		t.Errorf("NewTracker(\"\") = %v, %v", tr, err)
+	}
+	for project, want := range map[string]string{
+		"ops/homelab":        "provider:project",
+		"github:homelab":     "github:owner/repo",
+		"jira:ops":           "uppercase",
+		"linear:OPS":         "unknown provider",
+		"github:ops/homelab": "GITHUB_TOKEN not set",
+		"jira:OPS":           "JIRA_TOKEN not set",
+	} {
+		if _, err := NewTracker(project); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("NewTracker(%q) error = %v, want %q", project, err, want)
+		}
+	}
+
+	t.Setenv("JIRA_TOKEN", "token")
+	tr, err := NewTracker("jira:OPS")
+	if err != nil || tr.String() != "jira:OPS" {
+		t.Errorf("NewTracker(jira:OPS) = %v, %v", tr, err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.URL.Path == "/repos/ops/homelab/issues" && r.Header.Get("Authorization") == "Bearer gh-token":
+			if body["title"] != "Sonarr is down" {
+				http.Error(w, "missing title", http.StatusUnprocessableEntity)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number": 12, "html_url": "https://github.com/ops/homelab/issues/12"}`))
+		case r.URL.Path == "/rest/api/2/issue" && strings.HasPrefix(r.Header.Get("Authorization"), "Basic "):
+			fields, _ := body["fields"].(map[string]any)
+			if fields["summary"] != "Sonarr is down" {
+				http.Error(w, "missing summary", http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "10001", "key": "OPS-8"}`))
+		default:
+			http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+	defer func(old string) { githubAPIURL = old }(githubAPIURL)
+	githubAPIURL = srv.URL
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	t.Setenv("JIRA_URL", srv.URL)
+	t.Setenv("JIRA_EMAIL", "ops@example.com")
+	t.Setenv("JIRA_TOKEN", "token")
+
+	tr, err := NewTracker("github:ops/homelab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, url, err := tr.Open(context.Background(), "Sonarr is down", "It needs a human.")
+	if err != nil || ref != (Ref{GitHub, "ops/homelab#12"}) || url != "https://github.com/ops/homelab/issues/12" {
+		t.Errorf("github Open = %+v, %q, %v", ref, url, err)
+	}
+
+	tr, err = NewTracker("jira:OPS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, url, err = tr.Open(context.Background(), "Sonarr is down", "It needs a human.")
+	if err != nil || ref != (Ref{Jira, "OPS-8"}) || url != srv.URL+"/browse/OPS-8" {
+		t.Errorf("jira Open = %+v, %q, %v", ref, url, err)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "expired")
+	tr, _ = NewTracker("github:ops/homelab")
+	if _, _, err := tr.Open(context.Background(), "Sonarr is down", ""); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("Open with bad credentials error = %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/servicename"
+)
+
+// registerAdminRoutes wires the database maintenance and service name merge
+// pages.
+func (s *Server) registerAdminRoutes() {
+	s.mux.HandleFunc("GET /admin/db", s.handleAdminDB)
+	s.mux.HandleFunc("POST /admin/db/vacuum", s.handleAdminDBVacuum)
+	s.mux.HandleFunc("GET /admin/services", s.handleAdminServices)
+	s.mux.HandleFunc("POST /admin/services/merge", s.handleAdminServicesMerge)
+}
+
+// adminDBPageData is the template data for admin_db.html.
+type adminDBPageData struct {
+	Stats      *db.Stats
+	Migrations []db.MigrationRecord
+	Vacuumed   bool
+	Reclaimed  int64 // bytes the last VACUUM freed from the file and WAL
+	Error      string
+}
+
+// handleAdminDB renders database file sizes, table and index sizes, and
+// migration history.
+func (s *Server) handleAdminDB(w http.ResponseWriter, r *http.Request) {
+	s.renderAdminDB(w, r, adminDBPageData{})
+}
+
+// handleAdminDBVacuum runs VACUUM and re-renders the page with the space
+// reclaimed.
+func (s *Server) handleAdminDBVacuum(w http.ResponseWriter, r *http.Request) {
+	var data adminDBPageData
+	before, err := s.db.Stats()
+	if err == nil {
+		err = s.db.Vacuum()
+	}
+	if err != nil {
+		log.Printf("handleAdminDBVacuum: %v", err)
+		data.Error = "VACUUM failed: " + err.Error()
+		s.renderAdminDB(w, r, data)
+		return
+	}
+	data.Vacuumed = true
+	if after, err := s.db.Stats(); err == nil {
+		data.Stats = after
+		data.Reclaimed = max(0, before.FileSize+before.WALSize-after.FileSize-after.WALSize)
+	}
+	s.renderAdminDB(w, r, data)
+}
+
+func (s *Server) renderAdminDB(w http.ResponseWriter, r *http.Request, data adminDBPageData) {
+	var err error
+	if data.Stats == nil {
+		data.Stats, err = s.db.Stats()
+	}
+	if err == nil {
+		data.Migrations, err = s.db.ListMigrations()
+	}
+	if err != nil {
+		log.Printf("handleAdminDB: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	s.render(w, r, "admin_db.html", data)
+}
+
+// serviceNameGroup is a canonical service name and the recorded names that
+// normalize to it.
+type serviceNameGroup struct {
+	Canonical string
+	Rows      int64                 // records under the canonical name
+	Variants  []db.ServiceNameCount // other names to merge into it
+}
+
+// adminServicesPageData is the template data for admin_services.html.
+type adminServicesPageData struct {
+	Groups  []serviceNameGroup
+	Invalid []db.ServiceNameCount // names that are not valid service names
+	Names   []db.ServiceNameCount // every recorded name, for the merge form
+	Aliases []db.ServiceAlias
+	Merged  string
+	Error   string
+}
+
+// fragmentedServices groups the recorded service names by canonical name and
+// returns the groups with names to merge.
+func (s *Server) fragmentedServices(names []db.ServiceNameCount) ([]serviceNameGroup, []db.ServiceNameCount) {
+	byName := make(map[string]*serviceNameGroup)
+	var invalid []db.ServiceNameCount
+	for _, n := range names {
+		canonical, ok := s.services.Normalize(n.Service)
+		if !ok {
+			invalid = append(invalid, n)
+			continue
+		}
+		g := byName[canonical]
+		if g == nil {
+			g = &serviceNameGroup{Canonical: canonical}
+			byName[canonical] = g
+		}
+		if n.Service == canonical {
+			g.Rows = n.Rows
+		} else {
+			g.Variants = append(g.Variants, n)
+		}
+	}
+	var groups []serviceNameGroup
+	for _, g := range byName {
+		if len(g.Variants) > 0 {
+			groups = append(groups, *g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Canonical < groups[j].Canonical })
+	return groups, invalid
+}
+
+// handleAdminServices lists service names recorded under more than one
+// spelling.
+func (s *Server) handleAdminServices(w http.ResponseWriter, r *http.Request) {
+	s.renderAdminServices(w, r, "", "")
+}
+
+// handleAdminServicesMerge merges one service into another when the "from"
+// and "to" form values are set, keeping "from" as an alias of "to".
+// Otherwise it merges the variants of one canonical service (the "service"
+// form value), or of every fragmented service when that is empty.
+func (s *Server) handleAdminServicesMerge(w http.ResponseWriter, r *http.Request) {
+	by := s.approverName(r)
+	now := time.Now().UTC().Format(time.RFC3339)
+	if from := strings.TrimSpace(r.FormValue("from")); from != "" {
+		to, ok := s.services.Normalize(r.FormValue("to"))
+		if !ok {
+			s.renderAdminServices(w, r, "", fmt.Sprintf("%q is not a valid service name.", r.FormValue("to")))
+			return
+		}
+		if from == to {
+			s.renderAdminServices(w, r, "", "Choose two different services.")
+			return
+		}
+		alias := servicename.Fold(from)
+		if alias == to {
+			alias = ""
+		}
+		n, err := s.db.MergeService(from, to, alias, by, now)
+		if err != nil {
+			log.Printf("handleAdminServicesMerge: %v", err)
+			s.renderAdminServices(w, r, "", fmt.Sprintf("Merging %s into %s failed: %v", from, to, err))
+			return
+		}
+		s.renderAdminServices(w, r, fmt.Sprintf("Merged %s into %s, %d record(s).", from, to, n), "")
+		return
+	}
+
+	names, err := s.db.ListServiceNames()
+	if err != nil {
+		log.Printf("handleAdminServicesMerge: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	groups, _ := s.fragmentedServices(names)
+	only := r.FormValue("service")
+	var merged int
+	var rows int64
+	for _, g := range groups {
+		if only != "" && g.Canonical != only {
+			continue
+		}
+		for _, v := range g.Variants {
+			// Variants already normalize to the canonical name, so no
+			// alias is kept.
+			n, err := s.db.MergeService(v.Service, g.Canonical, "", by, now)
+			if err != nil {
+				log.Printf("handleAdminServicesMerge: %v", err)
+				s.renderAdminServices(w, r, "", fmt.Sprintf("Merging %s into %s failed: %v", v.Service, g.Canonical, err))
+				return
+			}
+			merged++
+			rows += n
+		}
+	}
+	s.renderAdminServices(w, r, fmt.Sprintf("Merged %d name(s), %d record(s).", merged, rows), "")
+}
+
+func (s *Server) renderAdminServices(w http.ResponseWriter, r *http.Request, merged, errMsg string) {
+	names, err := s.db.ListServiceNames()
+	var aliases []db.ServiceAlias
+	if err == nil {
+		aliases, err = s.db.ListServiceAliases()
+	}
+	if err != nil {
+		log.Printf("handleAdminServices: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	groups, invalid := s.fragmentedServices(names)
+	s.render(w, r, "admin_services.html", adminServicesPageData{
+		Groups:  groups,
+		Invalid: invalid,
+		Names:   names,
+		Aliases: aliases,
+		Merged:  merged,
+		Error:   errMsg,
+	})
+}
@@ -0,0 +1,114 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestAdminDBPage(t *testing.T) {
+	e := newTestEnv(t)
+	insertTestSession(t, e, "completed")
+
+	w := getPage(e, "/admin/db")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"VACUUM now", "sessions", "idx_sessions_status", "status, started_at", "Migrations"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/db/vacuum", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "VACUUM finished") {
+		t.Errorf("vacuum: got %d:\n%s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdminServicesMerge(t *testing.T) {
+	e := newTestEnv(t)
+	now := "2026-10-01T00:00:00Z"
+	for _, name := range []string{"Jellyfin", "jellyfin", "Caddy", "caddy", "bad/name"} {
+		svc := name
+		if _, err := e.srv.db.InsertEvent(&db.Event{Level: "info", Service: &svc, Message: "x", CreatedAt: now}); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	body := getPage(e, "/admin/services").Body.String()
+	for _, want := range []string{"Jellyfin", "Caddy", "bad/name", "Merge all"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/admin/services/merge", strings.NewReader("service=jellyfin"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "Merged 1 name(s), 1 record(s).") {
+		t.Errorf("merge jellyfin:\n%s", w.Body.String())
+	}
+	names, _ := e.srv.db.ListServiceNames()
+	got := make(map[string]int64)
+	for _, n := range names {
+		got[n.Service] = n.Rows
+	}
+	// Two events plus the merge's own event.
+	if got["jellyfin"] != 3 || got["Jellyfin"] != 0 || got["Caddy"] != 1 {
+		t.Errorf("after merging jellyfin: %v", got)
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/services/merge", nil))
+	if !strings.Contains(w.Body.String(), "No fragmented service names.") {
+		t.Errorf("merge all:\n%s", w.Body.String())
+	}
+}
+
+func TestAdminServicesMergeTwoServices(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.cfg.TrustedProxies = "192.0.2.0/24" // httptest requests come from 192.0.2.1
+	svc := "jellyfin-container"
+	if _, err := e.srv.db.InsertEvent(&db.Event{Level: "warning", Service: &svc, Message: "slow", CreatedAt: "2026-10-01T00:00:00Z"}); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/admin/services/merge", strings.NewReader("from=jellyfin-container&to=Jellyfin"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Remote-User", "alice")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	body := w.Body.String()
+	if !strings.Contains(body, "Merged jellyfin-container into jellyfin, 1 record(s).") {
+		t.Fatalf("merge:\n%s", body)
+	}
+	if !strings.Contains(body, "Merged aliases") || !strings.Contains(body, "alice") {
+		t.Errorf("expected the alias listed with its operator:\n%s", body)
+	}
+
+	if got, _ := e.srv.db.GetServiceAlias("jellyfin-container"); got != "jellyfin" {
+		t.Errorf("alias = %q, want jellyfin", got)
+	}
+	if got, _ := e.srv.services.Normalize("Jellyfin-Container"); got != "jellyfin" {
+		t.Errorf("Normalize after merge = %q, want jellyfin", got)
+	}
+	events, _ := e.srv.db.ListEvents(10, 0, db.EventFilter{})
+	if len(events) != 2 || events[0].Message != `Merged service "jellyfin-container" into "jellyfin" (1 record) by alice` {
+		t.Errorf("expected the merge logged as an event, got %+v", events)
+	}
+
+	req = httptest.NewRequest("POST", "/admin/services/merge", strings.NewReader("from=jellyfin&to=jellyfin"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "Choose two different services.") {
+		t.Errorf("expected an error merging a service into itself")
+	}
+}
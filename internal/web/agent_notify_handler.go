@@ -0,0 +1,48 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// registerAgentNotifyRoutes wires the endpoint the agent sends its notifications
+// to while a notification digest is configured (also on the agent
+// listener).
+func (s *Server) registerAgentNotifyRoutes() {
+	s.handleAgent("POST /api/v1/notify", s.handleAPIAgentNotify)
+}
+
+// appriseNotification is the body Apprise POSTs to a json:// URL. body is
+// accepted in place of message for callers using curl.
+type appriseNotification struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Body    string `json:"body"`
+}
+
+// handleAPIAgentNotify passes a notification the agent sent through the
+// notification digest, which delivers it to CLAUDEOPS_APPRISE_URLS.
+func (s *Server) handleAPIAgentNotify(w http.ResponseWriter, r *http.Request) {
+	if s.agentNotify == nil {
+		writeError(w, http.StatusServiceUnavailable, "notification digest is not configured")
+		return
+	}
+	var n appriseNotification
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&n); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	body := n.Message
+	if body == "" {
+		body = n.Body
+	}
+	title := strings.TrimSpace(n.Title)
+	if title == "" && strings.TrimSpace(body) == "" {
+		writeError(w, http.StatusBadRequest, "title or message is required")
+		return
+	}
+	s.agentNotify(title, body)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+}
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAgentNotify(t *testing.T) {
+	e := newTestEnv(t)
+	post := func(body string) int {
+		req := httptest.NewRequest("POST", "/api/v1/notify", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		e.srv.agentMux.ServeHTTP(w, req)
+		return w.Code
+	}
+	const apprise = `{"version":"1.0","title":"Claude Ops: Auto-remediated jellyfin","message":"restarted","type":"success"}`
+
+	if code := post(apprise); code != http.StatusServiceUnavailable {
+		t.Errorf("without a digest: expected 503, got %d", code)
+	}
+
+	var got []string
+	e.srv.agentNotify = func(title, body string) { got = append(got, title+": "+body) }
+	if code := post(apprise); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	if code := post(`{"title":"[DRILL] canary","body":"restarted"}`); code != http.StatusAccepted {
+		t.Fatalf("curl body: expected 202, got %d", code)
+	}
+	if code := post(`{}`); code != http.StatusBadRequest {
+		t.Errorf("empty notification: expected 400, got %d", code)
+	}
+	if len(got) != 2 || got[0] != "Claude Ops: Auto-remediated jellyfin: restarted" || got[1] != "[DRILL] canary: restarted" {
+		t.Errorf("notifications = %v", got)
+	}
+}
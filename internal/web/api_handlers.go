@@ -2,6 +2,7 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
 )
 
 // --- JSON Helpers ---
@@ -24,8 +26,17 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 }
 
 // Governing: SPEC-0017 REQ-17 "Error Response Format" — consistent {"error": "<message>"} JSON
+// The request ID is included so a caller can quote it when reporting a
+// failure; server errors are logged under it.
 func writeError(w http.ResponseWriter, status int, message string) {
-	writeJSON(w, status, map[string]string{"error": message})
+	body := map[string]string{"error": message}
+	if id := w.Header().Get(requestIDHeader); id != "" {
+		body["request_id"] = id
+		if status >= http.StatusInternalServerError {
+			log.Printf("request %s: %d %s", id, status, message)
+		}
+	}
+	writeJSON(w, status, body)
 }
 
 // Governing: SPEC-0017 REQ-2 "JSON Content Type" — rejects non-JSON request bodies with 415
@@ -81,7 +92,7 @@ func (s *Server) handleAPIStats(w http.ResponseWriter, r *http.Request) {
 
 	resp := APIStatsResponse{
 		Stats:           toAPIStats(stats),
-		NextRun:         time.Now().UTC().Add(time.Duration(s.cfg.Interval) * time.Second).Format(time.RFC3339),
+		NextRun:         s.nextRunEstimate().Format(time.RFC3339),
 		IntervalSeconds: s.cfg.Interval,
 	}
 
@@ -107,14 +118,29 @@ func (s *Server) handleAPIListSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessions, err := s.db.ListSessions(limit, offset)
+	sq, err := parseSessionQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := sq.filter(time.Now())
+	if rid := r.URL.Query().Get("request_id"); rid != "" {
+		// A request starts few sessions; list them all.
+		filter.RequestID = &rid
+		limit, offset = -1, 0
+	}
+	sessions, err := s.db.FindSessions(filter, sq.Sort, sq.asc(), limit, offset)
 	if err != nil {
 		log.Printf("handleAPIListSessions: %v", err)
 		writeError(w, http.StatusInternalServerError, "database error")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, APISessionsResponse{Sessions: toAPISessions(sessions)})
+	apiSessions := toAPISessions(sessions)
+	for i := range apiSessions {
+		s.withLiveCost(&apiSessions[i])
+	}
+	writeJSON(w, http.StatusOK, APISessionsResponse{Sessions: apiSessions})
 }
 
 // Governing: SPEC-0017 REQ-4 "Session Detail Endpoint" — GET /api/v1/sessions/{id} with chain details
@@ -139,6 +165,7 @@ func (s *Server) handleAPIGetSession(w http.ResponseWriter, r *http.Request) {
 
 	apiSess := toAPISession(*sess)
 	apiSess.Response = sess.Response
+	s.withLiveCost(&apiSess)
 
 	// Load parent session.
 	if sess.ParentSessionID != nil {
@@ -190,6 +217,12 @@ func (s *Server) handleAPIGetSession(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if linked, err := s.db.ListSessionTickets(sess.ID); err != nil {
+		log.Printf("handleAPIGetSession: %v", err)
+	} else if len(linked) > 0 {
+		apiSess.Integrations = toAPITickets(linked)
+	}
+
 	writeJSON(w, http.StatusOK, apiSess)
 }
 
@@ -216,11 +249,30 @@ func (s *Server) handleAPITriggerSession(w http.ResponseWriter, r *http.Request)
 	if startTier < 1 || startTier > 3 {
 		startTier = 1
 	}
-	sessionID, err := s.mgr.TriggerAdHoc(prompt, startTier, "api")
+
+	// A retried request gets the session the first one triggered.
+	claim, sessionID, err := s.idempotency.claim(r, "trigger", req)
 	if err != nil {
-		writeError(w, http.StatusConflict, err.Error())
+		writeError(w, idempotencyStatus(err), err.Error())
 		return
 	}
+	if sessionID != 0 {
+		w.Header().Set(idempotencyReplayedHeader, "true")
+	} else {
+		sessionID, err = s.mgr.TriggerAdHoc(prompt, startTier, "api")
+		if errors.Is(err, session.ErrQueued) {
+			s.followQueued(r, claim, err)
+			writeJSON(w, http.StatusAccepted, map[string]any{"status": "queued", "message": err.Error()})
+			return
+		}
+		if err != nil {
+			claim.release()
+			s.writeTriggerConflict(w, err)
+			return
+		}
+		claim.complete(sessionID)
+		s.tagSession(r, sessionID)
+	}
 
 	sess, err := s.db.GetSession(sessionID)
 	if err != nil || sess == nil {
@@ -240,16 +292,13 @@ func (s *Server) handleAPIListEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var level *string
-	if v := r.URL.Query().Get("level"); v != "" {
-		level = &v
-	}
-	var service *string
-	if v := r.URL.Query().Get("service"); v != "" {
-		service = &v
+	eq, err := parseEventQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	events, err := s.db.ListEvents(limit, offset, level, service)
+	events, err := s.db.ListEvents(limit, offset, eq.filter(time.Now()))
 	if err != nil {
 		log.Printf("handleAPIListEvents: %v", err)
 		writeError(w, http.StatusInternalServerError, "database error")
@@ -276,8 +325,16 @@ func (s *Server) handleAPIListMemories(w http.ResponseWriter, r *http.Request) {
 	if v := r.URL.Query().Get("category"); v != "" {
 		category = &v
 	}
+	var reviewStatus *string
+	if v := r.URL.Query().Get("review_status"); v != "" {
+		if !db.ValidReviewStatus(v) {
+			writeError(w, http.StatusBadRequest, "review_status must be one of unverified, verified, rejected")
+			return
+		}
+		reviewStatus = &v
+	}
 
-	memories, err := s.db.ListMemories(service, category, limit, offset)
+	memories, err := s.db.ListMemories(service, category, reviewStatus, limit, offset)
 	if err != nil {
 		log.Printf("handleAPIListMemories: %v", err)
 		writeError(w, http.StatusInternalServerError, "database error")
@@ -380,12 +437,23 @@ func (s *Server) handleAPIUpdateMemory(w http.ResponseWriter, r *http.Request) {
 	if req.Active != nil {
 		active = *req.Active
 	}
+	if req.ReviewStatus != nil && !db.ValidReviewStatus(*req.ReviewStatus) {
+		writeError(w, http.StatusBadRequest, "review_status must be one of unverified, verified, rejected")
+		return
+	}
 
 	if err := s.db.UpdateMemory(id, observation, confidence, active); err != nil {
 		log.Printf("handleAPIUpdateMemory: %v", err)
 		writeError(w, http.StatusInternalServerError, "database error")
 		return
 	}
+	if req.ReviewStatus != nil && *req.ReviewStatus != existing.ReviewStatus {
+		if err := s.db.SetMemoryReviewStatus(id, *req.ReviewStatus); err != nil {
+			log.Printf("handleAPIUpdateMemory: %v", err)
+			writeError(w, http.StatusInternalServerError, "database error")
+			return
+		}
+	}
 
 	updated, err := s.db.GetMemory(id)
 	if err != nil || updated == nil {
@@ -396,6 +464,54 @@ func (s *Server) handleAPIUpdateMemory(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, toAPIMemory(*updated))
 }
 
+// handleAPIListRejectedMemories returns deleted and rejected memories (tombstones),
+// most recently tombstoned first.
+func (s *Server) handleAPIListRejectedMemories(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := parseLimitOffset(r, 200)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	memories, err := s.db.ListTombstonedMemories(limit)
+	if err != nil {
+		log.Printf("handleAPIListRejectedMemories: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIMemoriesResponse{Memories: toAPIMemories(memories)})
+}
+
+// handleAPIRestoreMemory clears a memory's deletion or rejection and returns it
+// to the review queue.
+func (s *Server) handleAPIRestoreMemory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid memory ID")
+		return
+	}
+
+	if err := s.db.RestoreMemory(id); err != nil {
+		log.Printf("handleAPIRestoreMemory: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+
+	restored, err := s.db.GetMemory(id)
+	if err != nil {
+		log.Printf("handleAPIRestoreMemory: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if restored == nil {
+		writeError(w, http.StatusNotFound, "memory not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAPIMemory(*restored))
+}
+
 // Governing: SPEC-0017 REQ-10 "Memory Delete Endpoint" — DELETE /api/v1/memories/{id}
 // handleAPIDeleteMemory deletes a memory by ID.
 func (s *Server) handleAPIDeleteMemory(w http.ResponseWriter, r *http.Request) {
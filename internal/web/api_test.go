@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
 )
 
 // --- Health Endpoint ---
@@ -311,6 +312,7 @@ func TestAPIGetSessionWithEscalationChain(t *testing.T) {
 		Status: "completed", StartedAt: now, Trigger: "scheduled",
 		ParentSessionID: &parentID,
 	})
+	_ = e.srv.db.UpdateSessionChainSummary(parentID, "Tier 1 found sonarr down")
 
 	// Get parent: should have child sessions.
 	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/sessions/%d", parentID), nil)
@@ -328,6 +330,9 @@ func TestAPIGetSessionWithEscalationChain(t *testing.T) {
 	if parentResp.ChainCost == nil {
 		t.Fatal("expected chain cost to be set")
 	}
+	if parentResp.ChainSummary == nil || *parentResp.ChainSummary != "Tier 1 found sonarr down" {
+		t.Errorf("expected the chain summary on the root, got %v", parentResp.ChainSummary)
+	}
 
 	// Get child: should have parent session.
 	req2 := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/sessions/%d", childID), nil)
@@ -381,6 +386,39 @@ func TestAPITriggerSessionConflict(t *testing.T) {
 	}
 }
 
+func TestAPITriggerSessionServiceConflict(t *testing.T) {
+	e := newTestEnvWithTrigger(t, &mockTrigger{nextErr: &session.ServiceConflictError{SessionID: 41, Services: []string{"jellyfin"}}})
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/sessions/trigger", strings.NewReader(`{"prompt": "why is jellyfin down?"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post()
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", w.Code)
+	}
+	var body struct {
+		Error      string   `json:"error"`
+		SessionID  int64    `json:"session_id"`
+		SessionURL string   `json:"session_url"`
+		Services   []string `json:"services"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.SessionID != 41 || body.SessionURL != "/sessions/41" || len(body.Services) != 1 || body.Error != "session #41 is already working on jellyfin" {
+		t.Errorf("conflict body = %+v", body)
+	}
+
+	e.trigger.nextErr = session.ErrQueued
+	if w := post(); w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), `"queued"`) {
+		t.Errorf("queued: %d %s", w.Code, w.Body.String())
+	}
+}
+
 func TestAPITriggerSessionMissingPrompt(t *testing.T) {
 	e := newTestEnv(t)
 	body := `{}`
@@ -723,6 +761,40 @@ func TestAPIDeleteMemoryNotFound(t *testing.T) {
 	}
 }
 
+func TestAPIRejectedMemoriesAndRestore(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	id, _ := e.srv.db.InsertMemory(&db.Memory{
+		Category: "behavior", Observation: "wrong guess",
+		Confidence: 0.5, Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1,
+	})
+	_ = e.srv.db.DeleteMemory(id)
+
+	req := httptest.NewRequest("GET", "/api/v1/memories/rejected", nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp APIMemoriesResponse
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Memories) != 1 || resp.Memories[0].DeletedAt == nil {
+		t.Fatalf("expected 1 deleted memory, got %+v", resp.Memories)
+	}
+
+	req = httptest.NewRequest("POST", fmt.Sprintf("/api/v1/memories/%d/restore", id), nil)
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var restored APIMemory
+	_ = json.NewDecoder(w.Body).Decode(&restored)
+	if restored.ReviewStatus != db.MemoryUnverified || !restored.Active {
+		t.Fatalf("expected restored memory to be active and unverified, got %+v", restored)
+	}
+}
+
 // --- Cooldowns Endpoint ---
 
 func TestAPIListCooldownsEmpty(t *testing.T) {
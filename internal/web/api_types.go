@@ -1,6 +1,10 @@
 package web
 
 import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
 	"github.com/joestump/claude-ops/internal/db"
 )
 
@@ -32,23 +36,47 @@ type APICooldownsResponse struct {
 // Governing: SPEC-0017 REQ-3 "Sessions List Endpoint", REQ-4 "Session Detail Endpoint"
 // APISession is the JSON representation of a session.
 type APISession struct {
-	ID              int64        `json:"id"`
-	Tier            int          `json:"tier"`
-	Model           string       `json:"model"`
-	Status          string       `json:"status"`
-	StartedAt       string       `json:"started_at"`
-	EndedAt         *string      `json:"ended_at"`
-	ExitCode        *int         `json:"exit_code"`
-	CostUSD         *float64     `json:"cost_usd"`
-	NumTurns        *int         `json:"num_turns"`
-	DurationMs      *int64       `json:"duration_ms"`
-	Trigger         string       `json:"trigger"`
-	PromptText      *string      `json:"prompt_text"`
-	ParentSessionID *int64       `json:"parent_session_id"`
-	Response        *string      `json:"response,omitempty"`
-	ParentSession   *APISession  `json:"parent_session,omitempty"`
-	ChildSessions   []APISession `json:"child_sessions,omitempty"`
-	ChainCost       *float64     `json:"chain_cost,omitempty"`
+	ID              int64             `json:"id"`
+	Tier            int               `json:"tier"`
+	Model           string            `json:"model"`
+	Status          string            `json:"status"`
+	StartedAt       string            `json:"started_at"`
+	EndedAt         *string           `json:"ended_at"`
+	ExitCode        *int              `json:"exit_code"`
+	CostUSD         *float64          `json:"cost_usd"`
+	CostSynthetic   bool              `json:"cost_synthetic"`
+	CostLive        bool              `json:"cost_live"`
+	MaxContext      *int64            `json:"max_context_tokens"`
+	NumTurns        *int              `json:"num_turns"`
+	DurationMs      *int64            `json:"duration_ms"`
+	Trigger         string            `json:"trigger"`
+	PromptText      *string           `json:"prompt_text"`
+	PromptFile      string            `json:"prompt_file"`
+	ParentSessionID *int64            `json:"parent_session_id"`
+	ClientUser      *string           `json:"client_user"`
+	ClientMetadata  map[string]string `json:"client_metadata,omitempty"`
+	WorkDir         *string           `json:"work_dir"`
+	GitSHA          *string           `json:"git_sha"`
+	RequestID       *string           `json:"request_id"`
+	Outcome         *string           `json:"outcome"`
+	ChainSummary    *string           `json:"chain_summary"`
+	SkippedTiers    []int             `json:"skipped_tiers,omitempty"`
+	Response        *string           `json:"response,omitempty"`
+	ParentSession   *APISession       `json:"parent_session,omitempty"`
+	ChildSessions   []APISession      `json:"child_sessions,omitempty"`
+	ChainCost       *float64          `json:"chain_cost,omitempty"`
+	Integrations    []APITicket       `json:"integrations,omitempty"`
+}
+
+// APITicket is the JSON representation of a session's link to an issue in
+// an external tracker.
+type APITicket struct {
+	ID        int64  `json:"id"`
+	Provider  string `json:"provider"`
+	Ref       string `json:"ref"`
+	URL       string `json:"url,omitempty"`
+	Source    string `json:"source"`
+	CreatedAt string `json:"created_at"`
 }
 
 // Governing: SPEC-0017 REQ-6 "Events List Endpoint"
@@ -65,16 +93,21 @@ type APIEvent struct {
 // Governing: SPEC-0017 REQ-7 "Memories List Endpoint", REQ-8 "Memory Create Endpoint", REQ-9 "Memory Update Endpoint"
 // APIMemory is the JSON representation of a memory.
 type APIMemory struct {
-	ID          int64   `json:"id"`
-	Service     *string `json:"service"`
-	Category    string  `json:"category"`
-	Observation string  `json:"observation"`
-	Confidence  float64 `json:"confidence"`
-	Active      bool    `json:"active"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
-	SessionID   *int64  `json:"session_id"`
-	Tier        int     `json:"tier"`
+	ID           int64   `json:"id"`
+	Service      *string `json:"service"`
+	Category     string  `json:"category"`
+	Observation  string  `json:"observation"`
+	Confidence   float64 `json:"confidence"`
+	Active       bool    `json:"active"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+	SessionID    *int64  `json:"session_id"`
+	Tier         int     `json:"tier"`
+	ReviewStatus string  `json:"review_status"`
+	// Set for deleted or rejected memories (see GET /api/v1/memories/rejected).
+	DeletedAt       *string `json:"deleted_at,omitempty"`
+	TombstonedAt    *string `json:"tombstoned_at,omitempty"`
+	SuppressedCount int     `json:"suppressed_count"`
 }
 
 // Governing: SPEC-0017 REQ-11 "Cooldowns List Endpoint"
@@ -121,6 +154,7 @@ type APIStats struct {
 	TotalCostUSD   float64 `json:"total_cost_usd"`
 	ActiveMemories int     `json:"active_memories"`
 	CriticalEvents int     `json:"critical_events"`
+	Redactions     int     `json:"redactions"`
 	AvgDurationMs  int64   `json:"avg_duration_ms"`
 }
 
@@ -161,6 +195,16 @@ type APIUpdateMemoryRequest struct {
 	Observation *string  `json:"observation"`
 	Confidence  *float64 `json:"confidence"`
 	Active      *bool    `json:"active"`
+	// ReviewStatus approves ("verified") or rejects ("rejected") a memory.
+	ReviewStatus *string `json:"review_status"`
+}
+
+// APILinkTicketRequest is the JSON body for POST
+// /api/v1/sessions/{id}/integrations.
+type APILinkTicketRequest struct {
+	Provider string `json:"provider"`
+	Ref      string `json:"ref"`
+	URL      string `json:"url"` // optional; built from the reference when empty
 }
 
 // APIUpdateConfigRequest is the JSON body for PUT /api/v1/config.
@@ -175,7 +219,7 @@ type APIUpdateConfigRequest struct {
 // --- Conversion Functions ---
 
 func toAPISession(s db.Session) APISession {
-	return APISession{
+	out := APISession{
 		ID:              s.ID,
 		Tier:            s.Tier,
 		Model:           s.Model,
@@ -184,12 +228,32 @@ func toAPISession(s db.Session) APISession {
 		EndedAt:         s.EndedAt,
 		ExitCode:        s.ExitCode,
 		CostUSD:         s.CostUSD,
+		CostSynthetic:   s.CostSynthetic,
+		MaxContext:      s.MaxContext,
 		NumTurns:        s.NumTurns,
 		DurationMs:      s.DurationMs,
 		Trigger:         s.Trigger,
 		PromptText:      s.PromptText,
+		PromptFile:      s.PromptFile,
 		ParentSessionID: s.ParentSessionID,
+		ClientUser:      s.ClientUser,
+		WorkDir:         s.WorkDir,
+		GitSHA:          s.GitSHA,
+		RequestID:       s.RequestID,
+		Outcome:         s.Outcome,
+		ChainSummary:    s.ChainSummary,
+	}
+	if s.ClientMetadata != nil {
+		_ = json.Unmarshal([]byte(*s.ClientMetadata), &out.ClientMetadata)
 	}
+	if s.SkippedTiers != nil {
+		for _, t := range strings.Split(*s.SkippedTiers, ",") {
+			if tier, err := strconv.Atoi(t); err == nil {
+				out.SkippedTiers = append(out.SkippedTiers, tier)
+			}
+		}
+	}
+	return out
 }
 
 func toAPISessions(sessions []db.Session) []APISession {
@@ -200,6 +264,25 @@ func toAPISessions(sessions []db.Session) []APISession {
 	return out
 }
 
+func toAPITicket(t db.SessionTicket) APITicket {
+	return APITicket{
+		ID:        t.ID,
+		Provider:  t.Provider,
+		Ref:       t.TicketID,
+		URL:       t.URL,
+		Source:    t.Source,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+func toAPITickets(tickets []db.SessionTicket) []APITicket {
+	out := make([]APITicket, len(tickets))
+	for i, t := range tickets {
+		out[i] = toAPITicket(t)
+	}
+	return out
+}
+
 func toAPIEvent(e db.Event) APIEvent {
 	return APIEvent{
 		ID:        e.ID,
@@ -221,16 +304,20 @@ func toAPIEvents(events []db.Event) []APIEvent {
 
 func toAPIMemory(m db.Memory) APIMemory {
 	return APIMemory{
-		ID:          m.ID,
-		Service:     m.Service,
-		Category:    m.Category,
-		Observation: m.Observation,
-		Confidence:  m.Confidence,
-		Active:      m.Active,
-		CreatedAt:   m.CreatedAt,
-		UpdatedAt:   m.UpdatedAt,
-		SessionID:   m.SessionID,
-		Tier:        m.Tier,
+		ID:              m.ID,
+		Service:         m.Service,
+		Category:        m.Category,
+		Observation:     m.Observation,
+		Confidence:      m.Confidence,
+		Active:          m.Active,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+		SessionID:       m.SessionID,
+		Tier:            m.Tier,
+		ReviewStatus:    m.ReviewStatus,
+		DeletedAt:       m.DeletedAt,
+		TombstonedAt:    m.TombstonedAt,
+		SuppressedCount: m.SuppressedCount,
 	}
 }
 
@@ -268,6 +355,7 @@ func toAPIStats(s *db.DashboardStats) APIStats {
 		TotalCostUSD:   s.TotalCostUSD,
 		ActiveMemories: s.ActiveMemories,
 		CriticalEvents: s.CriticalEvents,
+		Redactions:     s.Redactions,
 		AvgDurationMs:  s.AvgDurationMs,
 	}
 }
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// approverHeaders carry the signed-in user when the dashboard sits behind an
+// authenticating reverse proxy. They take precedence over the form's name,
+// but only on requests from a proxy listed in CLAUDEOPS_TRUSTED_PROXIES:
+// anyone who reaches the dashboard directly could set them.
+var approverHeaders = []string{"Remote-User", "X-Forwarded-User"}
+
+// pendingApprovals returns the open two-person approval requests.
+func (s *Server) pendingApprovals() []db.ApprovalRequest {
+	requests, err := s.db.ListApprovalRequests("pending", 20)
+	if err != nil {
+		log.Printf("pendingApprovals: %v", err)
+		return nil
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	open := requests[:0]
+	for _, ar := range requests {
+		if ar.ExpiresAt > now {
+			open = append(open, ar)
+		}
+	}
+	return open
+}
+
+// fromTrustedProxy reports whether r came from a proxy listed in
+// CLAUDEOPS_TRUSTED_PROXIES. Requests over a Unix socket have no IP address
+// and are trusted when "unix" is listed.
+func (s *Server) fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	addr, addrErr := netip.ParseAddr(host)
+	for _, entry := range strings.Split(s.cfg.TrustedProxies, ",") {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+		case entry == "unix":
+			if err != nil {
+				return true
+			}
+		case addrErr != nil:
+		case strings.Contains(entry, "/"):
+			if p, err := netip.ParsePrefix(entry); err == nil && p.Contains(addr.Unmap()) {
+				return true
+			}
+		default:
+			if a, err := netip.ParseAddr(entry); err == nil && a == addr.Unmap() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// operator returns the operator a trusted authenticating proxy signed in,
+// or "" when there is none.
+func (s *Server) operator(r *http.Request) string {
+	if !s.fromTrustedProxy(r) {
+		return ""
+	}
+	for _, h := range approverHeaders {
+		if v := strings.TrimSpace(r.Header.Get(h)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// approverName identifies the operator behind a request: the signed-in
+// operator, else the name typed into the form.
+func (s *Server) approverName(r *http.Request) string {
+	if name := s.operator(r); name != "" {
+		return name
+	}
+	return strings.TrimSpace(r.FormValue("approver"))
+}
+
+// handleApproval approves or rejects a held Tier 3 remediation, then
+// returns to the dashboard. Approving needs an operator signed in through a
+// trusted proxy, since a typed name would let one person approve twice.
+func (s *Server) handleApproval(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid approval request ID", http.StatusBadRequest)
+		return
+	}
+	if s.approve == nil || s.reject == nil {
+		http.Error(w, "approvals are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	approver := s.approverName(r)
+	switch r.PathValue("action") {
+	case "approve":
+		approver = s.operator(r)
+		if approver == "" {
+			http.Error(w, "two-person approval needs an operator signed in through an authenticating proxy listed in CLAUDEOPS_TRUSTED_PROXIES", http.StatusForbidden)
+			return
+		}
+		err = s.approve(id, approver)
+	case "reject":
+		err = s.reject(id, approver)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
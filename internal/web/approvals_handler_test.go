@@ -0,0 +1,120 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestApprovalsBanner(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC()
+	insert := func(expires time.Time) int64 {
+		id, err := e.srv.db.InsertApprovalRequest(&db.ApprovalRequest{
+			SessionID: 4, Tier: 3, Services: "postgres", Reason: "replica is corrupt", Chain: `{"tier":3}`, Required: 2,
+			CreatedAt: now.Format(time.RFC3339), ExpiresAt: expires.Format(time.RFC3339),
+		})
+		if err != nil {
+			t.Fatalf("InsertApprovalRequest: %v", err)
+		}
+		return id
+	}
+	stale := insert(now.Add(-time.Minute))
+	id := insert(now.Add(time.Hour))
+	if _, err := e.srv.db.AddApproval(id, "alice", now.Format(time.RFC3339)); err != nil {
+		t.Fatalf("AddApproval: %v", err)
+	}
+
+	body := getPage(e, "/").Body.String()
+	for _, want := range []string{"Tier 3 remediation of postgres needs approval", "replica is corrupt", "Approvals: 1 of 2", "alice", "/approvals/" + itoa(id) + "/approve"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in dashboard", want)
+		}
+	}
+	if strings.Contains(body, "/approvals/"+itoa(stale)+"/approve") {
+		t.Error("expected the expired request to be hidden")
+	}
+}
+
+func TestApprovalActions(t *testing.T) {
+	e := newTestEnv(t)
+	var calls []string
+	record := func(action string) func(int64, string) error {
+		return func(id int64, approver string) error {
+			calls = append(calls, action+":"+itoa(id)+":"+approver)
+			return nil
+		}
+	}
+	e.srv.approve, e.srv.reject = record("approve"), record("reject")
+	e.srv.cfg.TrustedProxies = "10.0.0.0/8, unix"
+	post := func(path, remoteAddr, remoteUser string, form url.Values) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = remoteAddr
+		if remoteUser != "" {
+			req.Header.Set("Remote-User", remoteUser)
+		}
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A proxy-authenticated user approves; the typed name is ignored.
+	if code := post("/approvals/5/approve", "10.1.2.3:4567", "bob", url.Values{"approver": {"mallory"}}); code != http.StatusSeeOther {
+		t.Fatalf("approve through the proxy: status %d", code)
+	}
+	// So does one on the dashboard's Unix socket.
+	if code := post("/approvals/5/approve", "@", "dave", nil); code != http.StatusSeeOther {
+		t.Fatalf("approve over the socket: status %d", code)
+	}
+	// A typed name alone cannot approve.
+	if code := post("/approvals/5/approve", "192.0.2.1:1234", "", url.Values{"approver": {"bob"}}); code != http.StatusForbidden {
+		t.Errorf("approve with a typed name: status %d, want 403", code)
+	}
+	// Rejecting with a typed name is allowed.
+	if code := post("/approvals/5/reject", "192.0.2.1:1234", "", url.Values{"approver": {"carol"}}); code != http.StatusSeeOther {
+		t.Fatalf("reject: status %d", code)
+	}
+
+	if len(calls) != 3 || calls[0] != "approve:5:bob" || calls[1] != "approve:5:dave" || calls[2] != "reject:5:carol" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+	if w := postForm(e, "/approvals/5/bogus", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown action: status %d, want 404", w.Code)
+	}
+	if w := postForm(e, "/approvals/x/approve", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("bad ID: status %d, want 400", w.Code)
+	}
+}
+
+func TestApprovalIgnoresSpoofedIdentity(t *testing.T) {
+	e := newTestEnv(t)
+	var calls []string
+	e.srv.approve = func(id int64, approver string) error {
+		calls = append(calls, approver)
+		return nil
+	}
+	e.srv.reject = func(int64, string) error { return nil }
+	e.srv.cfg.TrustedProxies = "10.0.0.1"
+
+	// One operator posing as two by setting the proxy headers directly.
+	for _, spoof := range []struct{ header, user string }{{"Remote-User", "alice"}, {"X-Forwarded-User", "bob"}} {
+		req := httptest.NewRequest("POST", "/approvals/5/approve", strings.NewReader("approver="+spoof.user))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(spoof.header, spoof.user)
+		req.RemoteAddr = "10.0.0.2:5555"
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s from an untrusted address: status %d, want 403", spoof.header, w.Code)
+		}
+	}
+	if len(calls) != 0 {
+		t.Errorf("spoofed approvals were recorded: %v", calls)
+	}
+}
@@ -0,0 +1,47 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/joestump/claude-ops/internal/config"
+)
+
+// requiredStatic are the static files the layout and PWA manifest link to.
+var requiredStatic = []string{"style.css", "favicon.svg", "logo.svg", "manifest.json", "sw.js", "icon-192.svg", "icon-512.svg"}
+
+// CheckAssets verifies that the dashboard templates embedded in the binary
+// parse and that the static files they link to are embedded, so a broken
+// build fails `claudeops doctor` rather than the first page load.
+func CheckAssets() (err error) {
+	pages, err := fs.Glob(templateFS, "templates/*.html")
+	if err != nil {
+		return fmt.Errorf("list templates: %w", err)
+	}
+	if len(pages) == 0 {
+		return errors.New("no dashboard templates are embedded")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse templates: %v", r)
+		}
+	}()
+	s := &Server{cfg: &config.Config{}}
+	s.parseTemplates()
+
+	var errs []error
+	for _, page := range pages {
+		if s.tmpl.Lookup(path.Base(page)) == nil {
+			errs = append(errs, fmt.Errorf("template %s is not defined", path.Base(page)))
+		}
+	}
+	for _, name := range requiredStatic {
+		if _, err := fs.Stat(staticFS, "static/"+name); err != nil {
+			errs = append(errs, fmt.Errorf("static file %s is not embedded", name))
+		}
+	}
+	return errors.Join(errs...)
+}
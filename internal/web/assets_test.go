@@ -0,0 +1,9 @@
+package web
+
+import "testing"
+
+func TestCheckAssets(t *testing.T) {
+	if err := CheckAssets(); err != nil {
+		t.Fatalf("CheckAssets: %v", err)
+	}
+}
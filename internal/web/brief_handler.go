@@ -0,0 +1,152 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// registerBriefRoutes wires the spoken status brief.
+func (s *Server) registerBriefRoutes() {
+	s.mux.HandleFunc("GET /api/v1/brief", s.handleAPIBrief)
+}
+
+// APIBrief is the JSON payload for GET /api/v1/brief.
+type APIBrief struct {
+	Brief       string `json:"brief"`
+	GeneratedAt string `json:"generated_at"`
+}
+
+// handleAPIBrief returns a short status paragraph meant to be read aloud by a
+// voice assistant. It is regenerated at most every CLAUDEOPS_BRIEF_MINUTES;
+// ?format=text returns the paragraph alone as plain text. Unauthenticated,
+// mirroring GET /api/v1/stats.
+func (s *Server) handleAPIBrief(w http.ResponseWriter, r *http.Request) {
+	brief, err := s.cachedBrief(time.Now().UTC())
+	if err != nil {
+		log.Printf("handleAPIBrief: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = fmt.Fprintln(w, brief.Brief)
+		return
+	}
+	writeJSON(w, http.StatusOK, brief)
+}
+
+// cachedBrief returns the cached brief, regenerating it once it is older
+// than the configured number of minutes.
+func (s *Server) cachedBrief(now time.Time) (APIBrief, error) {
+	s.briefMu.Lock()
+	defer s.briefMu.Unlock()
+	if s.brief != nil && now.Sub(s.briefAt) < time.Duration(s.cfg.BriefMinutes)*time.Minute {
+		return *s.brief, nil
+	}
+	text, err := s.composeBrief(now)
+	if err != nil {
+		return APIBrief{}, err
+	}
+	s.brief = &APIBrief{Brief: text, GeneratedAt: now.Format(time.RFC3339)}
+	s.briefAt = now
+	return *s.brief, nil
+}
+
+// composeBrief describes current health, ongoing incidents, and the last run
+// in plain sentences without symbols or timestamps, so it reads well aloud.
+func (s *Server) composeBrief(now time.Time) (string, error) {
+	var sentences []string
+
+	statuses, err := s.db.ListServiceStatuses()
+	if err != nil {
+		return "", err
+	}
+	var unhealthy []string
+	for _, st := range statuses {
+		if st.Status != "healthy" {
+			unhealthy = append(unhealthy, st.Service+" is "+st.Status)
+		}
+	}
+	switch {
+	case len(statuses) == 0:
+		sentences = append(sentences, "No services have been checked yet.")
+	case len(unhealthy) == 0 && len(statuses) == 1:
+		sentences = append(sentences, statuses[0].Service+" is healthy.")
+	case len(unhealthy) == 0:
+		sentences = append(sentences, fmt.Sprintf("All %d services are healthy.", len(statuses)))
+	default:
+		sentences = append(sentences, fmt.Sprintf("%d of %d services need attention: %s.",
+			len(unhealthy), len(statuses), spokenList(unhealthy)))
+	}
+
+	running, err := s.db.RunningSession()
+	if err != nil {
+		return "", err
+	}
+	if running != nil {
+		sentences = append(sentences, fmt.Sprintf("A tier %d session started %s and is still running.",
+			running.Tier, spokenAgo(now, running.StartedAt)))
+	}
+	stats, err := s.db.GetDashboardStats()
+	if err != nil {
+		return "", err
+	}
+	switch stats.CriticalEvents {
+	case 0:
+		sentences = append(sentences, "There were no critical events in the last 24 hours.")
+	case 1:
+		sentences = append(sentences, "There was 1 critical event in the last 24 hours.")
+	default:
+		sentences = append(sentences, fmt.Sprintf("There were %d critical events in the last 24 hours.", stats.CriticalEvents))
+	}
+
+	last, err := s.db.LatestEndedSession()
+	if err != nil {
+		return "", err
+	}
+	if last == nil {
+		sentences = append(sentences, "No run has finished yet.")
+	} else {
+		sentences = append(sentences, fmt.Sprintf("The last run finished %s and %s.",
+			spokenAgo(now, *last.EndedAt), strings.ReplaceAll(last.Status, "_", " ")))
+	}
+	return strings.Join(sentences, " "), nil
+}
+
+// spokenAgo describes how long before now the RFC3339 timestamp at was, in
+// words: "just now", "5 minutes ago", "2 hours ago", "3 days ago".
+func spokenAgo(now time.Time, at string) string {
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return "at an unknown time"
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute") + " ago"
+	case d < 48*time.Hour:
+		return plural(int(d/time.Hour), "hour") + " ago"
+	}
+	return plural(int(d/(24*time.Hour)), "day") + " ago"
+}
+
+// plural formats n with the singular or plural form of noun.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// spokenList joins items as "a, b and c".
+func spokenList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
@@ -0,0 +1,91 @@
+package web
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestAPIBrief(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC()
+
+	var brief APIBrief
+	if err := json.NewDecoder(getPage(e, "/api/v1/brief").Body).Decode(&brief); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if want := "No services have been checked yet. There were no critical events in the last 24 hours. No run has finished yet."; brief.Brief != want {
+		t.Errorf("unexpected empty brief %q", brief.Brief)
+	}
+
+	for svc, status := range map[string]string{"jellyfin": "down", "nginx": "degraded", "postgres": "healthy"} {
+		if _, err := e.srv.db.InsertHealthCheck(&db.HealthCheck{Service: svc, CheckType: "http", Status: status, CheckedAt: now.Format(time.RFC3339)}); err != nil {
+			t.Fatalf("InsertHealthCheck: %v", err)
+		}
+	}
+	svc := "jellyfin"
+	if _, err := e.srv.db.InsertEvent(&db.Event{Level: "critical", Service: &svc, Message: "down", CreatedAt: now.Format(time.RFC3339)}); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+	id := insertTestSession(t, e, "failed")
+	ended := now.Add(-20 * time.Minute).Format(time.RFC3339)
+	if err := e.srv.db.UpdateSession(id, "failed", &ended, nil, nil); err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+	insertTestSession(t, e, "running")
+
+	w := getPage(e, "/api/v1/brief?format=text")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected text/plain, got %q", ct)
+	}
+	text := w.Body.String()
+	for _, want := range []string{
+		"2 of 3 services need attention: jellyfin is down and nginx is degraded.",
+		"A tier 1 session started just now and is still running.",
+		"There was 1 critical event in the last 24 hours.",
+		"The last run finished 20 minutes ago and failed.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("brief missing %q: %s", want, text)
+		}
+	}
+}
+
+func TestAPIBriefCached(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.cfg.BriefMinutes = 5
+
+	first := getPage(e, "/api/v1/brief?format=text").Body.String()
+	if _, err := e.srv.db.InsertHealthCheck(&db.HealthCheck{Service: "nginx", CheckType: "http", Status: "healthy", CheckedAt: time.Now().UTC().Format(time.RFC3339)}); err != nil {
+		t.Fatalf("InsertHealthCheck: %v", err)
+	}
+	if second := getPage(e, "/api/v1/brief?format=text").Body.String(); second != first {
+		t.Errorf("expected the cached brief, got %q", second)
+	}
+
+	brief, err := e.srv.cachedBrief(time.Now().UTC().Add(6 * time.Minute))
+	if err != nil {
+		t.Fatalf("cachedBrief: %v", err)
+	}
+	if !strings.HasPrefix(brief.Brief, "nginx is healthy.") {
+		t.Errorf("expected a regenerated brief, got %q", brief.Brief)
+	}
+}
+
+func TestSpokenAgo(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for d, want := range map[time.Duration]string{
+		10 * time.Second: "just now",
+		time.Minute:      "1 minute ago",
+		45 * time.Minute: "45 minutes ago",
+		3 * time.Hour:    "3 hours ago",
+		72 * time.Hour:   "3 days ago",
+	} {
+		if got := spokenAgo(now, now.Add(-d).Format(time.RFC3339)); got != want {
+			t.Errorf("spokenAgo(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
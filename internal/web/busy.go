@@ -29,7 +29,7 @@ func generateBusyResponse(ctx context.Context, database *db.DB, apiKey string) s
 	}
 
 	// Gather recent events from this session for context.
-	events, _ := database.ListEvents(10, 0, nil, nil)
+	events, _ := database.ListEvents(10, 0, db.EventFilter{})
 
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "You are Claude Ops, an infrastructure monitoring agent currently running a Tier %d monitoring session (session #%d, started %s).\n", session.Tier, session.ID, session.StartedAt)
@@ -0,0 +1,187 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// handleChainStream opens an SSE connection that follows a session's
+// escalation chain. When the session ends and the chain starts another, the
+// same connection goes on with that session's formatted lines, after a
+// "navigate" event (the session's page URL), a "tier" event (the chain
+// event, as JSON), and a marker line linking to it, so the session page does
+// not have to reload between tiers. The page of a session that has already
+// finished uses the "navigate" event to open the next one. A "done" event
+// ends the stream when the chain finishes. A session no chain stream
+// started is streamed on its own, as by handleSessionStream.
+func (s *Server) handleChainStream(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	// As for a single session, discourage the browser from reconnecting
+	// and replaying the buffers before the page reloads.
+	_, _ = fmt.Fprintf(w, "retry: 30000\n\n")
+	flusher.Flush()
+
+	if s.hub == nil {
+		_, _ = fmt.Fprintf(w, "data: [session %d] SSE hub not connected\n\n", id)
+		flusher.Flush()
+		return
+	}
+
+	ctx := r.Context()
+	replayed, chain, unsubscribeChain := s.subscribeChain(id)
+	defer unsubscribeChain()
+	if chain == nil {
+		ch, unsubscribe := s.hub.Subscribe(int(id), hub.Formatted)
+		defer unsubscribe()
+		if streamFormatted(ctx, w, flusher, ch) {
+			_, _ = fmt.Fprintf(w, "event: done\ndata: session complete\n\n")
+			flusher.Flush()
+		}
+		return
+	}
+
+	// follow handles one chain event, streaming the session it started,
+	// and reports whether to go on. Sessions the chain started before id
+	// are skipped; the session page links to them.
+	var last session.ChainEvent
+	follow := func(data string) bool {
+		var ce session.ChainEvent
+		if err := json.Unmarshal([]byte(data), &ce); err != nil {
+			log.Printf("handleChainStream: session %d: %v", id, err)
+			return true
+		}
+		prev := last
+		last = ce
+		switch {
+		case ce.Type == session.ChainEnded:
+			return false
+		case ce.Type != session.ChainSessionStarted || ce.SessionID < id:
+			return true
+		}
+		if ce.SessionID != id {
+			_, _ = fmt.Fprintf(w, "event: navigate\ndata: /sessions/%d\n\n", ce.SessionID)
+			_, _ = fmt.Fprintf(w, "event: tier\ndata: %s\n\n", data)
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", chainMarkerHTML(prev, ce))
+			flusher.Flush()
+		}
+		ch, unsubscribe := s.hub.Subscribe(int(ce.SessionID), hub.Formatted)
+		defer unsubscribe()
+		return streamFormatted(ctx, w, flusher, ch)
+	}
+
+	done := func() {
+		if ctx.Err() == nil {
+			_, _ = fmt.Fprintf(w, "event: done\ndata: chain complete\n\n")
+			flusher.Flush()
+		}
+	}
+	for _, evt := range replayed {
+		if !follow(evt.Data) {
+			done()
+			return
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-chain:
+			if !ok || !follow(evt.Data) {
+				done()
+				return
+			}
+		}
+	}
+}
+
+// subscribeChain subscribes to the stream of the chain that started
+// session id. That is the stream of the run id started in, which is keyed
+// by the run's first session: id itself or one of its ancestors (a
+// verification run starts a new stream for a session whose parent is the
+// tip of an earlier chain). It returns the stream's buffered events, which
+// include id's start, and its channel, or a nil channel when no chain
+// stream started id.
+func (s *Server) subscribeChain(id int64) ([]hub.Event, <-chan hub.Event, func()) {
+	roots := []int64{id}
+	ancestors, err := s.db.GetEscalationChain(id)
+	if err != nil {
+		log.Printf("subscribeChain: session %d: %v", id, err)
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if ancestors[i].ID != id {
+			roots = append(roots, ancestors[i].ID)
+		}
+	}
+	for _, root := range roots {
+		ch, unsubscribe := s.hub.Subscribe(hub.ChainID(root), hub.Lifecycle)
+		replayed := make([]hub.Event, len(ch))
+		for i := range replayed {
+			replayed[i] = <-ch
+		}
+		for _, evt := range replayed {
+			var ce session.ChainEvent
+			if json.Unmarshal([]byte(evt.Data), &ce) == nil && ce.Type == session.ChainSessionStarted && ce.SessionID == id {
+				return replayed, ch, unsubscribe
+			}
+		}
+		unsubscribe()
+	}
+	return nil, nil, func() {}
+}
+
+// chainLive reports whether the chain that started session id is still
+// running, i.e. its stream has not ended.
+func (s *Server) chainLive(id int64) bool {
+	if s.hub == nil {
+		return false
+	}
+	replayed, ch, unsubscribe := s.subscribeChain(id)
+	defer unsubscribe()
+	if ch == nil {
+		return false
+	}
+	for _, evt := range replayed {
+		var ce session.ChainEvent
+		if json.Unmarshal([]byte(evt.Data), &ce) == nil && ce.Type == session.ChainEnded {
+			return false
+		}
+	}
+	return true
+}
+
+// chainMarkerHTML is the terminal line marking the start of a chain's next
+// session, saying how the chain got there from the event before it.
+func chainMarkerHTML(prev, started session.ChainEvent) string {
+	how := "Continued in"
+	switch prev.Type {
+	case session.ChainEscalated:
+		how = "Escalated to"
+	case session.ChainHandedBack:
+		how = "Handed back to"
+	}
+	return fmt.Sprintf(`<div class="chain-marker" data-session-id="%d">%s Tier %d (%s) &middot; <a href="/sessions/%d">session #%d</a></div>`,
+		started.SessionID, how, started.Tier, html.EscapeString(started.Model), started.SessionID, started.SessionID)
+}
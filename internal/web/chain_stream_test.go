@@ -0,0 +1,104 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func TestChainStreamFollowsChain(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	insert := func(tier int, parent *int64) int64 {
+		id, err := e.srv.db.InsertSession(&db.Session{Tier: tier, Model: "haiku", PromptFile: "/p.md", Status: "completed", StartedAt: now, ParentSessionID: parent})
+		if err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		return id
+	}
+	root := insert(1, nil)
+	t2 := insert(2, &root)
+	lone := insert(1, nil)
+
+	chainEvent := func(ev session.ChainEvent) {
+		data, _ := json.Marshal(ev)
+		e.hub.Publish(hub.ChainID(root), hub.Lifecycle, string(data))
+	}
+	chainEvent(session.ChainEvent{Type: session.ChainSessionStarted, SessionID: root, Tier: 1, Model: "haiku"})
+	e.hub.Publish(int(root), hub.Formatted, "postgres is down")
+	e.hub.Close(int(root))
+	chainEvent(session.ChainEvent{Type: session.ChainEscalated, SessionID: root, FromTier: 1, Tier: 2, Model: "sonnet"})
+	chainEvent(session.ChainEvent{Type: session.ChainSessionStarted, SessionID: t2, Tier: 2, Model: "sonnet"})
+	e.hub.Publish(int(t2), hub.Formatted, "restarted postgres")
+	e.hub.Close(int(t2))
+	chainEvent(session.ChainEvent{Type: session.ChainEnded, Sessions: []int64{root, t2}})
+	e.hub.Close(hub.ChainID(root))
+	e.hub.Publish(int(lone), hub.Formatted, "all healthy")
+	e.hub.Close(int(lone))
+
+	stream := func(id int64) string {
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/sessions/%d/chain/stream", id), nil))
+		if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("session %d: Content-Type = %q", id, ct)
+		}
+		return w.Body.String()
+	}
+
+	body := stream(root)
+	down := strings.Index(body, "data: postgres is down")
+	marker := strings.Index(body, fmt.Sprintf(`Escalated to Tier 2 (sonnet) &middot; <a href="/sessions/%d">`, t2))
+	restarted := strings.Index(body, "data: restarted postgres")
+	navigate := strings.Index(body, fmt.Sprintf("event: navigate\ndata: /sessions/%d\n", t2))
+	if down < 0 || navigate < down || marker < navigate || restarted < marker || !strings.Contains(body, "event: tier\n") {
+		t.Errorf("root stream did not follow the chain in order:\n%s", body)
+	}
+	if !strings.HasSuffix(body, "event: done\ndata: chain complete\n\n") {
+		t.Errorf("root stream did not end with done:\n%s", body)
+	}
+
+	// A later session of the chain streams from its own start.
+	body = stream(t2)
+	if strings.Contains(body, "postgres is down") || strings.Contains(body, "event: tier") || !strings.Contains(body, "data: restarted postgres") {
+		t.Errorf("tier 2 stream:\n%s", body)
+	}
+
+	// A session no chain started is streamed on its own.
+	body = stream(lone)
+	if !strings.Contains(body, "data: all healthy") || !strings.HasSuffix(body, "event: done\ndata: session complete\n\n") {
+		t.Errorf("lone session stream:\n%s", body)
+	}
+}
+
+func TestSessionPageFollowsLiveChain(t *testing.T) {
+	e := newTestEnv(t)
+	root := insertTestSession(t, e, "escalated")
+	chainEvent := func(ev session.ChainEvent) {
+		data, _ := json.Marshal(ev)
+		e.hub.Publish(hub.ChainID(root), hub.Lifecycle, string(data))
+	}
+	page := func() string {
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/sessions/%d", root), nil))
+		return w.Body.String()
+	}
+
+	chainEvent(session.ChainEvent{Type: session.ChainSessionStarted, SessionID: root, Tier: 1, Model: "haiku"})
+	chainEvent(session.ChainEvent{Type: session.ChainEscalated, SessionID: root, FromTier: 1, Tier: 2, Model: "sonnet"})
+	if body := page(); !strings.Contains(body, `id="chain-follow"`) || !strings.Contains(body, fmt.Sprintf("/sessions/%d/chain/stream", root)) {
+		t.Error("finished session of a running chain does not follow the chain")
+	}
+
+	chainEvent(session.ChainEvent{Type: session.ChainEnded, Sessions: []int64{root}})
+	e.hub.Close(hub.ChainID(root))
+	if strings.Contains(page(), `id="chain-follow"`) {
+		t.Error("session page follows a chain that has ended")
+	}
+}
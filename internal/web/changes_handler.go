@@ -0,0 +1,80 @@
+package web
+
+import (
+	"io/fs"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/sandbox"
+)
+
+// pendingChangeReports returns the change reports awaiting approval.
+func (s *Server) pendingChangeReports() []db.ChangeReport {
+	reports, err := s.db.ListPendingChangeReports()
+	if err != nil {
+		log.Printf("pendingChangeReports: %v", err)
+		return nil
+	}
+	return reports
+}
+
+// handleChangeReport applies or discards the file changes a sandboxed
+// session proposed, then returns to the session. The decision is recorded
+// before the files are written, so two operators deciding at once cannot
+// both apply. Applying writes nothing if any file has changed since the
+// session ran; the report goes back to pending with the reason.
+func (s *Server) handleChangeReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+	report, err := s.db.GetSessionChangeReport(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if report.Status != db.ChangesPending {
+		http.Error(w, "changes were already "+report.Status, http.StatusConflict)
+		return
+	}
+
+	var status string
+	switch r.PathValue("action") {
+	case "apply":
+		status = db.ChangesApplied
+	case "discard":
+		status = db.ChangesDiscarded
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	ok, err := s.db.DecideChangeReport(report.ID, status, s.approverName(r), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "changes were already decided", http.StatusConflict)
+		return
+	}
+	if status == db.ChangesApplied {
+		changes := make([]sandbox.Change, len(report.Files))
+		for i, f := range report.Files {
+			changes[i] = sandbox.Change{Path: f.Path, Kind: f.Kind, Old: f.OldContent, New: f.NewContent, Mode: fs.FileMode(f.Mode)}
+		}
+		if err := sandbox.Apply(changes); err != nil {
+			if dbErr := s.db.ReopenChangeReport(report.ID, err.Error()); dbErr != nil {
+				log.Printf("handleChangeReport: %v", dbErr)
+			}
+		}
+	}
+	http.Redirect(w, r, "/sessions/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func insertChangeReport(t *testing.T, e *testEnv, path string) int64 {
+	t.Helper()
+	id := insertTestSession(t, e, "completed")
+	if _, err := e.srv.db.InsertChangeReport(&db.ChangeReport{
+		SessionID: id,
+		CreatedAt: "2026-10-01T00:00:00Z",
+		Files: []db.ChangeFile{{
+			Path: path, Kind: "modified", Mode: 0o644,
+			OldContent: []byte("image: jellyfin:10.8\n"), NewContent: []byte("image: jellyfin:10.9\n"),
+			Diff: "-image: jellyfin:10.8\n+image: jellyfin:10.9\n",
+		}},
+	}); err != nil {
+		t.Fatalf("InsertChangeReport: %v", err)
+	}
+	return id
+}
+
+func TestChangeReportApply(t *testing.T) {
+	e := newTestEnv(t)
+	path := filepath.Join(t.TempDir(), "compose.yaml")
+	if err := os.WriteFile(path, []byte("image: jellyfin:10.8\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	id := insertChangeReport(t, e, path)
+
+	if body := getPage(e, "/").Body.String(); !strings.Contains(body, fmt.Sprintf("Session #%d proposes changes to 1 files", id)) {
+		t.Error("expected the pending change report on the dashboard")
+	}
+	body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String()
+	for _, want := range []string{"Proposed Changes", "awaiting approval", path, fmt.Sprintf("/sessions/%d/changes/apply", id)} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+
+	if w := postForm(e, fmt.Sprintf("/sessions/%d/changes/apply", id), url.Values{"approver": {"alice"}}); w.Code != http.StatusSeeOther {
+		t.Fatalf("apply: status %d: %s", w.Code, w.Body)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "image: jellyfin:10.9\n" {
+		t.Errorf("expected the change applied, got %q", b)
+	}
+	report, _ := e.srv.db.GetSessionChangeReport(id)
+	if report.Status != db.ChangesApplied || report.DecidedBy == nil || *report.DecidedBy != "alice" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if w := postForm(e, fmt.Sprintf("/sessions/%d/changes/discard", id), nil); w.Code != http.StatusConflict {
+		t.Errorf("expected a decided report to conflict, got %d", w.Code)
+	}
+}
+
+func TestChangeReportConflictStaysPending(t *testing.T) {
+	e := newTestEnv(t)
+	path := filepath.Join(t.TempDir(), "compose.yaml")
+	if err := os.WriteFile(path, []byte("image: jellyfin:11\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	id := insertChangeReport(t, e, path)
+
+	if w := postForm(e, fmt.Sprintf("/sessions/%d/changes/apply", id), nil); w.Code != http.StatusSeeOther {
+		t.Fatalf("apply: status %d", w.Code)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "image: jellyfin:11\n" {
+		t.Errorf("expected the file left alone, got %q", b)
+	}
+	report, _ := e.srv.db.GetSessionChangeReport(id)
+	if report.Status != db.ChangesPending || report.Error == nil {
+		t.Fatalf("expected the report pending with an error, got %+v", report)
+	}
+	if body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String(); !strings.Contains(body, "Could not apply") {
+		t.Error("expected the apply error on the session page")
+	}
+
+	if w := postForm(e, fmt.Sprintf("/sessions/%d/changes/discard", id), nil); w.Code != http.StatusSeeOther {
+		t.Fatalf("discard: status %d", w.Code)
+	}
+	if report, _ = e.srv.db.GetSessionChangeReport(id); report.Status != db.ChangesDiscarded {
+		t.Errorf("expected discarded, got %s", report.Status)
+	}
+}
@@ -1,14 +1,18 @@
 package web
 
 import (
+	"context"
 	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/session"
 )
 
 // Governing: SPEC-0024 REQ-1 (Endpoint Registration), REQ-2 (Authentication), ADR-0020
@@ -67,6 +71,12 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	clientUser, clientMeta, err := chatClient(req)
+	if err != nil {
+		writeChatError(w, http.StatusBadRequest, err.Error(), "invalid_request_error", "invalid_request")
+		return
+	}
+
 	// Governing: SPEC-0024 REQ-3 (model field maps to starting tier), ADR-0020 (Tier Selection)
 	startTier := modelToTier(req.Model)
 
@@ -82,49 +92,51 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	// Governing: SPEC-0024 REQ-10 — id starts with recognizable prefix
 	requestID := fmt.Sprintf("chatcmpl-%s", uuid.New().String())
 
-	// Governing: SPEC-0024 REQ-4 — trigger ad-hoc session via existing session manager
-	sessionID, err := s.mgr.TriggerAdHoc(prompt, startTier, "api")
+	// Questions the database can answer are served in milliseconds without
+	// starting a session; only commands trigger one.
+	if name := chatIntent(req, prompt); name != "" {
+		answer, err := chatAnswers[name](s, prompt)
+		if err != nil {
+			log.Printf("chat: %s: %v", name, err)
+			writeChatError(w, http.StatusInternalServerError, "Failed to answer "+name, "server_error", "internal_error")
+			return
+		}
+		writeChatText(w, req.Stream, requestID, responseModel, answer+"\n\n"+cachedNote)
+		return
+	}
+	if toolChoiceNone(req) {
+		writeChatText(w, req.Stream, requestID, responseModel, noToolsAnswer)
+		return
+	}
+
+	// A retried request follows the session the first one triggered
+	// instead of starting another.
+	claim, sessionID, err := s.idempotency.claim(r, "chat", req)
 	if err != nil {
-		// Session already running — generate a first-person LLM busy response
-		// instead of a bare 429 so conversational clients get a useful reply.
-		busyMsg := generateBusyResponse(r.Context(), s.db, os.Getenv("ANTHROPIC_API_KEY"))
-		if req.Stream {
-			flusher, ok := w.(http.Flusher)
-			if !ok {
-				writeChatError(w, http.StatusTooManyRequests, busyMsg, "rate_limit_error", "rate_limit_exceeded")
-				return
+		writeChatError(w, idempotencyStatus(err), err.Error(), "invalid_request_error", "idempotency_key")
+		return
+	}
+	if sessionID != 0 {
+		w.Header().Set(idempotencyReplayedHeader, "true")
+	} else {
+		// Governing: SPEC-0024 REQ-4 — trigger ad-hoc session via existing session manager
+		sessionID, err = s.mgr.TriggerAdHoc(prompt, startTier, "api")
+		if err != nil {
+			s.followQueued(r, claim, err)
+			// Session already running — generate a first-person LLM busy response
+			// instead of a bare 429 so conversational clients get a useful reply.
+			busyMsg := s.triggerBusyMessage(r.Context(), err)
+			writeChatText(w, req.Stream, requestID, responseModel, busyMsg)
+			return
+		}
+		claim.complete(sessionID)
+		s.tagSession(r, sessionID)
+
+		if clientUser != nil || clientMeta != nil {
+			if err := s.db.UpdateSessionClient(sessionID, clientUser, clientMeta); err != nil {
+				log.Printf("chat: %v", err)
 			}
-			w.Header().Set("Content-Type", "text/event-stream")
-			w.Header().Set("Cache-Control", "no-cache")
-			w.Header().Set("Connection", "keep-alive")
-			w.Header().Set("X-Accel-Buffering", "no")
-			sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
-				ID: requestID, Object: "chat.completion.chunk", Model: responseModel,
-				Choices: []Choice{{Index: 0, Delta: Delta{Role: "assistant"}}},
-			})
-			sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
-				ID: requestID, Object: "chat.completion.chunk", Model: responseModel,
-				Choices: []Choice{{Index: 0, Delta: Delta{Content: busyMsg}}},
-			})
-			sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
-				ID: requestID, Object: "chat.completion.chunk", Model: responseModel,
-				Choices: []Choice{{Index: 0, Delta: Delta{}, FinishReason: "stop"}},
-			})
-			fmt.Fprintf(w, "data: [DONE]\n\n") //nolint:errcheck
-			flusher.Flush()
-		} else {
-			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode(ChatCompletion{
-				ID: requestID, Object: "chat.completion", Model: responseModel,
-				Choices: []CompletionChoice{{
-					Index:        0,
-					Message:      ChatMessage{Role: "assistant", Content: busyMsg},
-					FinishReason: "stop",
-				}},
-				Usage: ChatUsage{},
-			})
 		}
-		return
 	}
 
 	if req.Stream {
@@ -134,6 +146,82 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Limits on client metadata, matching the OpenAI API.
+const (
+	maxClientUser          = 256
+	maxClientMetadataKeys  = 16
+	maxClientMetadataKey   = 64
+	maxClientMetadataValue = 512
+)
+
+// chatClient validates the request's user and metadata fields and returns
+// them as stored on the session: nil when absent, metadata as a JSON object.
+func chatClient(req ChatRequest) (user, metadata *string, err error) {
+	if u := strings.TrimSpace(req.User); u != "" {
+		if len(u) > maxClientUser {
+			return nil, nil, fmt.Errorf("user must be at most %d characters", maxClientUser)
+		}
+		user = &u
+	}
+	if len(req.Metadata) == 0 {
+		return user, nil, nil
+	}
+	if len(req.Metadata) > maxClientMetadataKeys {
+		return nil, nil, fmt.Errorf("metadata may have at most %d keys", maxClientMetadataKeys)
+	}
+	for k, v := range req.Metadata {
+		if k == "" || len(k) > maxClientMetadataKey {
+			return nil, nil, fmt.Errorf("metadata keys must be 1 to %d characters", maxClientMetadataKey)
+		}
+		if len(v) > maxClientMetadataValue {
+			return nil, nil, fmt.Errorf("metadata value for %q must be at most %d characters", k, maxClientMetadataValue)
+		}
+	}
+	data, err := json.Marshal(req.Metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode metadata: %w", err)
+	}
+	m := string(data)
+	return user, &m, nil
+}
+
+// writeChatText writes a complete assistant reply that needs no session, as
+// SSE chunks when stream is set and the writer can flush, or as a single
+// completion otherwise.
+func writeChatText(w http.ResponseWriter, stream bool, requestID, model, text string) {
+	if flusher, ok := w.(http.Flusher); ok && stream {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
+			ID: requestID, Object: "chat.completion.chunk", Model: model,
+			Choices: []Choice{{Index: 0, Delta: Delta{Role: "assistant"}}},
+		})
+		sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
+			ID: requestID, Object: "chat.completion.chunk", Model: model,
+			Choices: []Choice{{Index: 0, Delta: Delta{Content: text}}},
+		})
+		sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
+			ID: requestID, Object: "chat.completion.chunk", Model: model,
+			Choices: []Choice{{Index: 0, Delta: Delta{}, FinishReason: "stop"}},
+		})
+		fmt.Fprintf(w, "data: [DONE]\n\n") //nolint:errcheck
+		flusher.Flush()
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ChatCompletion{
+		ID: requestID, Object: "chat.completion", Model: model,
+		Choices: []CompletionChoice{{
+			Index:        0,
+			Message:      ChatMessage{Role: "assistant", Content: text},
+			FinishReason: "stop",
+		}},
+		Usage: ChatUsage{},
+	})
+}
+
 // handleChatStream implements SSE streaming for stream:true requests.
 // Governing: SPEC-0024 REQ-5 (Streaming Response), ADR-0020
 func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request, sessionID int64, requestID string, model string) {
@@ -143,7 +231,7 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request, sessio
 		return
 	}
 
-	if s.rawHub == nil {
+	if s.hub == nil {
 		writeChatError(w, http.StatusInternalServerError, "Streaming not available", "server_error", "internal_error")
 		return
 	}
@@ -153,9 +241,6 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request, sessio
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
 
-	ch, unsubscribe := s.rawHub.Subscribe(int(sessionID))
-	defer unsubscribe()
-
 	// Governing: SPEC-0024 REQ-5 — send role indicator in first chunk
 	sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
 		ID:     requestID,
@@ -169,40 +254,130 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request, sessio
 
 	ctx := r.Context()
 	var toolCallIndex int
+	finish := func() {
+		sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
+			ID:     requestID,
+			Object: "chat.completion.chunk",
+			Model:  model,
+			Choices: []Choice{{
+				Index:        0,
+				Delta:        Delta{},
+				FinishReason: "stop",
+			}},
+		})
+		_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
+		flusher.Flush()
+	}
+
+	// The supervisor publishes each session and escalation of the chain
+	// the session starts on the chain's stream; it has already published
+	// the session itself by the time the chat request has its ID. Without
+	// a chain stream, only the session is streamed.
+	chain, unsubscribeChain := s.hub.Subscribe(hub.ChainID(sessionID), hub.Lifecycle)
+	defer unsubscribeChain()
+	if len(chain) == 0 {
+		ch, unsubscribe := s.hub.Subscribe(int(sessionID), hub.Raw)
+		defer unsubscribe()
+		if s.streamChatSession(ctx, w, flusher, ch, requestID, model, &toolCallIndex, false) {
+			finish()
+		}
+		return
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case raw, ok := <-ch:
+		case evt, ok := <-chain:
 			if !ok {
-				// Session ended — send finish chunk and [DONE]
+				finish()
+				return
+			}
+			var ce session.ChainEvent
+			if err := json.Unmarshal([]byte(evt.Data), &ce); err != nil {
+				continue
+			}
+			if ce.Type == session.ChainSessionStarted {
+				sessCh, unsubscribeSession := s.hub.Subscribe(int(ce.SessionID), hub.Raw)
+				done := s.streamChatSession(ctx, w, flusher, sessCh, requestID, model, &toolCallIndex, true)
+				unsubscribeSession()
+				if !done {
+					return
+				}
+				continue
+			}
+			if text := s.chainEventText(ce); text != "" {
 				sendSSEChunk(w, flusher, requestID, ChatCompletionChunk{
-					ID:     requestID,
-					Object: "chat.completion.chunk",
-					Model:  model,
-					Choices: []Choice{{
-						Index:        0,
-						Delta:        Delta{},
-						FinishReason: "stop",
-					}},
+					ID:      requestID,
+					Object:  "chat.completion.chunk",
+					Model:   model,
+					Choices: []Choice{{Index: 0, Delta: Delta{Content: text}}},
 				})
-				_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
-				flusher.Flush()
+			}
+			if ce.Type == session.ChainEnded {
+				finish()
 				return
 			}
+		}
+	}
+}
 
-			chunks := s.rawEventToChunks(raw, requestID, model, &toolCallIndex)
-			for _, chunk := range chunks {
+// streamChatSession streams a session's raw events as chat chunks until the
+// session ends, returning false if the request was cancelled first. When
+// following a chain, a session's result does not finish the response.
+func (s *Server) streamChatSession(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, ch <-chan hub.Event, requestID, model string, toolCallIndex *int, chain bool) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case evt, ok := <-ch:
+			if !ok {
+				return true
+			}
+			for _, chunk := range s.rawEventToChunks(evt.Data, requestID, model, toolCallIndex) {
+				if chain && chunk.Choices[0].FinishReason != "" {
+					if chunk.Choices[0].Delta.Content == "" {
+						continue
+					}
+					chunk.Choices[0].FinishReason = ""
+				}
 				sendSSEChunk(w, flusher, requestID, chunk)
 			}
 		}
 	}
 }
 
+// chainEventText is the assistant text announcing a step of the chain a
+// chat request follows: an escalation, a hand-back, or, when the chain ends,
+// its cost and sessions.
+func (s *Server) chainEventText(e session.ChainEvent) string {
+	what := strings.Join(e.Services, ", ")
+	switch e.Type {
+	case session.ChainEscalated:
+		verb := map[int]string{2: "investigate", 3: "remediate"}[e.Tier]
+		if what == "" {
+			what = "the issue"
+		}
+		return fmt.Sprintf("\n\nEscalating to Tier %d (%s) to %s %s…\n\n", e.Tier, e.Model, verb, what)
+	case session.ChainHandedBack:
+		return fmt.Sprintf("\n\nHanding back to Tier %d (%s) to verify %s…\n\n", e.Tier, e.Model, what)
+	case session.ChainEnded:
+		if len(e.Sessions) < 2 {
+			return ""
+		}
+		links := make([]string, len(e.Sessions))
+		for i, id := range e.Sessions {
+			links[i] = fmt.Sprintf("[#%d](%s)", id, s.sessionURL(id))
+		}
+		return fmt.Sprintf("\n\n---\nChain cost: $%.2f · Sessions: %s\n", e.CostUSD, strings.Join(links, ", "))
+	}
+	return ""
+}
+
 // handleChatSync implements the synchronous response for stream:false requests.
 // Governing: SPEC-0024 REQ-6 (Synchronous Response), ADR-0020
 func (s *Server) handleChatSync(w http.ResponseWriter, r *http.Request, sessionID int64, requestID string, model string) {
-	if s.rawHub == nil {
+	if s.hub == nil {
 		// Fallback: return a minimal response with session ID
 		w.Header().Set("Content-Type", "application/json")
 		resp := ChatCompletion{
@@ -223,7 +398,7 @@ func (s *Server) handleChatSync(w http.ResponseWriter, r *http.Request, sessionI
 		return
 	}
 
-	ch, unsubscribe := s.rawHub.Subscribe(int(sessionID))
+	ch, unsubscribe := s.hub.Subscribe(int(sessionID), hub.Raw)
 	defer unsubscribe()
 
 	// Collect all assistant text content until the session ends.
@@ -235,11 +410,11 @@ loop:
 		case <-ctx.Done():
 			writeChatError(w, http.StatusInternalServerError, "Request cancelled", "server_error", "request_cancelled")
 			return
-		case raw, ok := <-ch:
+		case evt, ok := <-ch:
 			if !ok {
 				break loop
 			}
-			text := extractAssistantText(raw)
+			text := extractAssistantText(evt.Data)
 			if text != "" {
 				contentParts = append(contentParts, text)
 			}
@@ -8,6 +8,10 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/session"
 )
 
 // Governing: SPEC-0024 REQ-8 (Models Endpoint), ADR-0020
@@ -137,7 +141,7 @@ func closeRawHubOnTrigger(e *testEnv) func(int64) {
 	return func(id int64) {
 		// Small delay to ensure the handler has time to subscribe.
 		time.Sleep(10 * time.Millisecond)
-		e.rawHub.Close(int(id))
+		e.hub.Close(int(id))
 	}
 }
 
@@ -216,7 +220,7 @@ func TestChatCompletionsSessionConflict(t *testing.T) {
 	e := newTestEnvWithTrigger(t, trigger)
 	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
 
-	body := `{"model":"claude-ops","messages":[{"role":"user","content":"status"}]}`
+	body := `{"model":"claude-ops","messages":[{"role":"user","content":"restart jellyfin"}]}`
 	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
 	req.Header.Set("Authorization", "Bearer key")
 	w := httptest.NewRecorder()
@@ -388,7 +392,7 @@ func TestChatCompletionsBusyResponseContent(t *testing.T) {
 	// Ensure no API key so fallback message is used.
 	t.Setenv("ANTHROPIC_API_KEY", "")
 
-	body := `{"model":"claude-ops","messages":[{"role":"user","content":"status"}]}`
+	body := `{"model":"claude-ops","messages":[{"role":"user","content":"check jellyfin"}]}`
 	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
 	req.Header.Set("Authorization", "Bearer key")
 	w := httptest.NewRecorder()
@@ -560,11 +564,11 @@ func TestChatStreamingSSEFormat(t *testing.T) {
 	// Publish assistant text and a result event, then close.
 	trigger.onTrigger = func(id int64) {
 		time.Sleep(10 * time.Millisecond)
-		e.rawHub.Publish(int(id), `{"type":"assistant","message":{"content":[{"type":"text","text":"Hello world"}]}}`)
+		e.hub.Publish(int(id), hub.Raw, `{"type":"assistant","message":{"content":[{"type":"text","text":"Hello world"}]}}`)
 		time.Sleep(5 * time.Millisecond)
-		e.rawHub.Publish(int(id), `{"type":"result","result":"done","is_error":false}`)
+		e.hub.Publish(int(id), hub.Raw, `{"type":"result","result":"done","is_error":false}`)
 		time.Sleep(5 * time.Millisecond)
-		e.rawHub.Close(int(id))
+		e.hub.Close(int(id))
 	}
 
 	body := `{"model":"claude-ops","messages":[{"role":"user","content":"hello"}],"stream":true}`
@@ -637,9 +641,9 @@ func TestChatStreamingToolCallDelta(t *testing.T) {
 
 	trigger.onTrigger = func(id int64) {
 		time.Sleep(10 * time.Millisecond)
-		e.rawHub.Publish(int(id), `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash","input":{"command":"docker ps"}}]}}`)
+		e.hub.Publish(int(id), hub.Raw, `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash","input":{"command":"docker ps"}}]}}`)
 		time.Sleep(5 * time.Millisecond)
-		e.rawHub.Close(int(id))
+		e.hub.Close(int(id))
 	}
 
 	body := `{"model":"claude-ops","messages":[{"role":"user","content":"check"}],"stream":true}`
@@ -690,11 +694,11 @@ func TestChatSyncResponseCollectsText(t *testing.T) {
 
 	trigger.onTrigger = func(id int64) {
 		time.Sleep(10 * time.Millisecond)
-		e.rawHub.Publish(int(id), `{"type":"assistant","message":{"content":[{"type":"text","text":"Hello "}]}}`)
-		e.rawHub.Publish(int(id), `{"type":"assistant","message":{"content":[{"type":"text","text":"world"}]}}`)
-		e.rawHub.Publish(int(id), `{"type":"result","result":"All done.","is_error":false}`)
+		e.hub.Publish(int(id), hub.Raw, `{"type":"assistant","message":{"content":[{"type":"text","text":"Hello "}]}}`)
+		e.hub.Publish(int(id), hub.Raw, `{"type":"assistant","message":{"content":[{"type":"text","text":"world"}]}}`)
+		e.hub.Publish(int(id), hub.Raw, `{"type":"result","result":"All done.","is_error":false}`)
 		time.Sleep(5 * time.Millisecond)
-		e.rawHub.Close(int(id))
+		e.hub.Close(int(id))
 	}
 
 	body := `{"model":"claude-ops","messages":[{"role":"user","content":"hello"}]}`
@@ -944,7 +948,7 @@ func TestChatCompletionsDefaultModelTriggersWithStartTier1(t *testing.T) {
 	trigger.onTrigger = closeRawHubOnTrigger(e)
 	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
 
-	body := `{"model":"claude-ops","messages":[{"role":"user","content":"status"}]}`
+	body := `{"model":"claude-ops","messages":[{"role":"user","content":"check jellyfin"}]}`
 	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
 	req.Header.Set("Authorization", "Bearer key")
 	w := httptest.NewRecorder()
@@ -958,6 +962,52 @@ func TestChatCompletionsDefaultModelTriggersWithStartTier1(t *testing.T) {
 	}
 }
 
+// TestChatStreamingFollowsChain verifies that a streamed chat follows the
+// escalation chain its session starts, announcing each escalation and
+// closing with the chain's cost and sessions.
+func TestChatStreamingFollowsChain(t *testing.T) {
+	trigger := &mockTrigger{nextID: 42}
+	e := newTestEnvWithTrigger(t, trigger)
+	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
+
+	chainEvent := func(ev session.ChainEvent) {
+		data, _ := json.Marshal(ev)
+		e.hub.Publish(hub.ChainID(42), hub.Lifecycle, string(data))
+	}
+	chainEvent(session.ChainEvent{Type: session.ChainSessionStarted, SessionID: 42, Tier: 1, Model: "haiku"})
+	e.hub.Publish(42, hub.Raw, `{"type":"assistant","message":{"content":[{"type":"text","text":"Postgres is down."}]}}`)
+	e.hub.Publish(42, hub.Raw, `{"type":"result","result":"done","is_error":false}`)
+	e.hub.Close(42)
+	chainEvent(session.ChainEvent{Type: session.ChainEscalated, SessionID: 42, FromTier: 1, Tier: 2, Model: "sonnet", Services: []string{"postgres"}})
+	chainEvent(session.ChainEvent{Type: session.ChainSessionStarted, SessionID: 43, Tier: 2, Model: "sonnet"})
+	e.hub.Publish(43, hub.Raw, `{"type":"assistant","message":{"content":[{"type":"text","text":"Restarted postgres."}]}}`)
+	e.hub.Close(43)
+	chainEvent(session.ChainEvent{Type: session.ChainEnded, Sessions: []int64{42, 43}, CostUSD: 0.42})
+	e.hub.Close(hub.ChainID(42))
+
+	body := `{"model":"claude-ops","messages":[{"role":"user","content":"check postgres"}],"stream":true}`
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer key")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+
+	var content strings.Builder
+	stops := 0
+	for _, c := range parseSSEChunks(t, w.Body.String()) {
+		content.WriteString(c.Choices[0].Delta.Content)
+		if c.Choices[0].FinishReason != "" {
+			stops++
+		}
+	}
+	want := "Postgres is down.\n\nEscalating to Tier 2 (sonnet) to investigate postgres…\n\nRestarted postgres.\n\n---\nChain cost: $0.42 · Sessions: [#42](/sessions/42), [#43](/sessions/43)\n"
+	if content.String() != want {
+		t.Errorf("content = %q, want %q", content.String(), want)
+	}
+	if stops != 1 || !strings.HasSuffix(w.Body.String(), "data: [DONE]\n\n") {
+		t.Errorf("expected one finish chunk and [DONE], got %d finish chunks:\n%s", stops, w.Body.String())
+	}
+}
+
 // parseSSEChunks extracts ChatCompletionChunk objects from SSE output.
 func parseSSEChunks(t *testing.T, body string) []ChatCompletionChunk {
 	t.Helper()
@@ -979,3 +1029,212 @@ func parseSSEChunks(t *testing.T, body string) []ChatCompletionChunk {
 	}
 	return chunks
 }
+
+func TestChatCompletionsStoresClientLabels(t *testing.T) {
+	trigger := &mockTrigger{}
+	e := newTestEnvWithTrigger(t, trigger)
+	trigger.nextID = insertTestSession(t, e, "running")
+	trigger.onTrigger = closeRawHubOnTrigger(e)
+	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
+
+	body := `{"model":"claude-ops","messages":[{"role":"user","content":"check jellyfin"}],` +
+		`"user":"alice","metadata":{"client":"open-webui","conversation_id":"c-42"}}`
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer key")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", w.Code, w.Body.String())
+	}
+
+	sess, err := e.srv.db.GetSession(trigger.nextID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if sess.ClientUser == nil || *sess.ClientUser != "alice" {
+		t.Errorf("expected client user alice, got %v", sess.ClientUser)
+	}
+	if sess.ClientMetadata == nil || *sess.ClientMetadata != `{"client":"open-webui","conversation_id":"c-42"}` {
+		t.Errorf("unexpected client metadata %v", sess.ClientMetadata)
+	}
+
+	page := getPage(e, fmt.Sprintf("/sessions/%d", trigger.nextID)).Body.String()
+	for _, want := range []string{"Client User", "alice", "Client Metadata", "conversation_id", "open-webui"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("session page missing %q", want)
+		}
+	}
+}
+
+func TestChatCompletionsRejectsInvalidMetadata(t *testing.T) {
+	trigger := &mockTrigger{nextID: 1}
+	e := newTestEnvWithTrigger(t, trigger)
+	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
+
+	meta := make(map[string]string)
+	for i := range 17 {
+		meta[fmt.Sprintf("k%d", i)] = "v"
+	}
+	tooMany, _ := json.Marshal(meta)
+	for name, extra := range map[string]string{
+		"too many keys": `"metadata":` + string(tooMany),
+		"long key":      `"metadata":{"` + strings.Repeat("k", 65) + `":"v"}`,
+		"long value":    `"metadata":{"k":"` + strings.Repeat("v", 513) + `"}`,
+		"long user":     `"user":"` + strings.Repeat("u", 257) + `"`,
+	} {
+		body := `{"messages":[{"role":"user","content":"hi"}],` + extra + `}`
+		req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer key")
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+	if trigger.lastPrompt != "" {
+		t.Errorf("invalid requests should not trigger a session, got prompt %q", trigger.lastPrompt)
+	}
+}
+
+const getStatusTool = `"tools":[{"type":"function","function":{"name":"get_status","description":"Service health","parameters":{"type":"object","properties":{}}}}]`
+
+func postChat(e *testEnv, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer key")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestChatQuickStatusAnsweredWithoutSession(t *testing.T) {
+	trigger := &mockTrigger{nextID: 1}
+	e := newTestEnvWithTrigger(t, trigger)
+	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
+	for svc, status := range map[string]string{"jellyfin": "down", "nginx": "healthy"} {
+		if _, err := e.srv.db.InsertHealthCheck(&db.HealthCheck{Service: svc, CheckType: "http", Status: status, CheckedAt: "2026-10-01T00:00:00Z"}); err != nil {
+			t.Fatalf("InsertHealthCheck: %v", err)
+		}
+	}
+
+	w := postChat(e, `{"messages":[{"role":"user","content":"What's the status?"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", w.Code, w.Body.String())
+	}
+	var resp ChatCompletion
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := "1 of 2 services need attention:\n- jellyfin: down (last checked 2026-10-01T00:00:00Z)\n\n" + cachedNote
+	if got := resp.Choices[0].Message.Content; got != want {
+		t.Errorf("unexpected answer %q", got)
+	}
+
+	w = postChat(e, `{"stream":true,"messages":[{"role":"user","content":"is nginx up?"}],`+getStatusTool+`}`)
+	var text string
+	for _, c := range parseSSEChunks(t, w.Body.String()) {
+		text += c.Choices[0].Delta.Content
+	}
+	if text != "nginx is healthy (last checked 2026-10-01T00:00:00Z).\n\n"+cachedNote {
+		t.Errorf("unexpected streamed answer %q", text)
+	}
+
+	if trigger.lastPrompt != "" {
+		t.Errorf("status questions should not trigger a session, got prompt %q", trigger.lastPrompt)
+	}
+}
+
+func TestChatQuickLastRunAnswer(t *testing.T) {
+	trigger := &mockTrigger{nextID: 1}
+	e := newTestEnvWithTrigger(t, trigger)
+	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
+
+	ask := func() string {
+		t.Helper()
+		var resp ChatCompletion
+		_ = json.NewDecoder(postChat(e, `{"messages":[{"role":"user","content":"what did the last run find?"}]}`).Body).Decode(&resp)
+		return resp.Choices[0].Message.Content
+	}
+	if got := ask(); got != "No session has finished yet.\n\n"+cachedNote {
+		t.Errorf("unexpected answer %q", got)
+	}
+
+	id := insertTestSession(t, e, "completed")
+	ended := "2026-10-01T02:00:00Z"
+	if err := e.srv.db.UpdateSession(id, "completed", &ended, nil, nil); err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+	if err := e.srv.db.UpdateSessionSummary(id, "All services healthy; pruned old images."); err != nil {
+		t.Fatalf("UpdateSessionSummary: %v", err)
+	}
+	if got := ask(); !strings.Contains(got, fmt.Sprintf("Session #%d", id)) || !strings.Contains(got, "pruned old images") {
+		t.Errorf("unexpected answer %q", got)
+	}
+	if trigger.lastPrompt != "" {
+		t.Errorf("last-run questions should not trigger a session, got prompt %q", trigger.lastPrompt)
+	}
+}
+
+func TestChatCommandsTriggerSession(t *testing.T) {
+	for name, prompt := range map[string]string{
+		"needs action":  "is jellyfin down? restart it",
+		"asks why":      "why was the last run so expensive",
+		"command":       "clean up old docker images",
+		"long question": "is " + strings.Repeat("everything ", 12) + "up",
+	} {
+		t.Run(name, func(t *testing.T) {
+			trigger := &mockTrigger{nextID: 1}
+			e := newTestEnvWithTrigger(t, trigger)
+			trigger.onTrigger = closeRawHubOnTrigger(e)
+			t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
+			if w := postChat(e, `{"messages":[{"role":"user","content":"`+prompt+`"}],`+getStatusTool+`}`); w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", w.Code)
+			}
+			if trigger.lastPrompt != prompt {
+				t.Errorf("expected a session for %q, got prompt %q", prompt, trigger.lastPrompt)
+			}
+		})
+	}
+}
+
+func TestChatToolForcedByToolChoice(t *testing.T) {
+	trigger := &mockTrigger{nextID: 1}
+	e := newTestEnvWithTrigger(t, trigger)
+	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
+
+	w := postChat(e, `{"messages":[{"role":"user","content":"anything to worry about tonight"}],`+
+		`"tool_choice":{"type":"function","function":{"name":"get_status"}},`+getStatusTool+`}`)
+	var resp ChatCompletion
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if got := resp.Choices[0].Message.Content; got != "No health checks have been recorded yet.\n\n"+cachedNote {
+		t.Errorf("unexpected answer %q", got)
+	}
+	if trigger.lastPrompt != "" {
+		t.Errorf("forced tool should not trigger a session, got prompt %q", trigger.lastPrompt)
+	}
+}
+
+func TestChatToolChoiceNoneStartsNoSession(t *testing.T) {
+	trigger := &mockTrigger{nextID: 1}
+	e := newTestEnvWithTrigger(t, trigger)
+	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "key")
+
+	w := postChat(e, `{"messages":[{"role":"user","content":"clean up old docker images"}],"tool_choice":"none"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp ChatCompletion
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if got := resp.Choices[0].Message.Content; got != noToolsAnswer || resp.Choices[0].FinishReason != "stop" {
+		t.Errorf("unexpected completion %+v", resp.Choices[0])
+	}
+	if trigger.lastPrompt != "" {
+		t.Errorf("tool_choice none should not trigger a session, got prompt %q", trigger.lastPrompt)
+	}
+
+	// Questions the database answers are still answered.
+	w = postChat(e, `{"messages":[{"role":"user","content":"what's the status?"}],"tool_choice":"none"}`)
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if got := resp.Choices[0].Message.Content; !strings.HasSuffix(got, cachedNote) {
+		t.Errorf("unexpected answer %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/joestump/claude-ops/internal/textutil"
+)
+
+// chatAnswers are the questions Claude Ops answers itself, straight from the
+// database, so they cost no session. The keys double as the function names a
+// client may offer in tools.
+var chatAnswers = map[string]func(s *Server, prompt string) (string, error){
+	"get_status":   (*Server).statusAnswer,
+	"get_last_run": (*Server).lastRunAnswer,
+}
+
+// cachedNote ends every answer served without a session.
+const cachedNote = "(answered from cache, no new session started)"
+
+// noToolsAnswer replies to a command sent with tool_choice "none".
+const noToolsAnswer = `No session was started: tool_choice is "none", and a session investigates by running tools. ` +
+	"Send the request without it to start one."
+
+var (
+	// statusQuestion matches short questions about service health.
+	statusQuestion = regexp.MustCompile(`(?i)^\s*(what('?s| is)( the)?( current)? status|status\b|how (are|is) .{1,40} (doing|looking)|(is|are) .{1,40} (up|down|healthy|ok|okay|running)\b)`)
+
+	// lastRunQuestion matches short questions about the latest session.
+	lastRunQuestion = regexp.MustCompile(`(?i)^\s*(what|how|show|tell|summari[sz]e|any)\b.{0,30}\b(last|latest|previous|most recent) (run|session|check)`)
+
+	// actionWords mark a prompt that needs an investigation, not a lookup.
+	actionWords = regexp.MustCompile(`(?i)\b(restart|fix|why|investigate|redeploy|deploy|rollback|roll back|repair|debug|diagnose)\b`)
+)
+
+const (
+	// maxQuickQuestion bounds the prompts treated as quick questions.
+	maxQuickQuestion = 100
+
+	// maxLastRunResponse bounds the response quoted when the last session
+	// has no summary.
+	maxLastRunResponse = 1000
+)
+
+// chatIntent returns the answer for a question the database can serve, or
+// "" when the request is a command that needs a session. A function the
+// client offers in tools can be forced with tool_choice; otherwise the
+// prompt alone decides.
+func chatIntent(req ChatRequest, prompt string) string {
+	var forced struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if len(req.ToolChoice) > 0 && json.Unmarshal(req.ToolChoice, &forced) == nil {
+		for _, t := range req.Tools {
+			if t.Type == "function" && t.Function.Name == forced.Function.Name && chatAnswers[t.Function.Name] != nil {
+				return t.Function.Name
+			}
+		}
+	}
+
+	if len(prompt) > maxQuickQuestion || actionWords.MatchString(prompt) {
+		return ""
+	}
+	switch {
+	case statusQuestion.MatchString(prompt):
+		return "get_status"
+	case lastRunQuestion.MatchString(prompt):
+		return "get_last_run"
+	}
+	return ""
+}
+
+// toolChoiceNone reports whether the request set tool_choice to "none",
+// forbidding tool calls. Every session calls tools, so such a request only
+// gets the answers chatIntent serves from the database.
+func toolChoiceNone(req ChatRequest) bool {
+	var choice string
+	return len(req.ToolChoice) > 0 && json.Unmarshal(req.ToolChoice, &choice) == nil && choice == "none"
+}
+
+// statusAnswer describes the latest health check of each service, or only of
+// the services the prompt names.
+func (s *Server) statusAnswer(prompt string) (string, error) {
+	statuses, err := s.db.ListServiceStatuses()
+	if err != nil {
+		return "", err
+	}
+	if len(statuses) == 0 {
+		return "No health checks have been recorded yet.", nil
+	}
+
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(prompt), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.'
+	}) {
+		words[strings.TrimRight(w, ".")] = true
+	}
+	lastCheck := func(at *string) string {
+		if at == nil {
+			return "never checked"
+		}
+		return "last checked " + *at
+	}
+
+	var b strings.Builder
+	for _, st := range statuses {
+		if words[strings.ToLower(st.Service)] {
+			fmt.Fprintf(&b, "%s is %s (%s).\n", st.Service, st.Status, lastCheck(st.LastCheck))
+		}
+	}
+	if b.Len() > 0 {
+		return strings.TrimRight(b.String(), "\n"), nil
+	}
+
+	var unhealthy []string
+	for _, st := range statuses {
+		if st.Status != "healthy" {
+			unhealthy = append(unhealthy, fmt.Sprintf("- %s: %s (%s)", st.Service, st.Status, lastCheck(st.LastCheck)))
+		}
+	}
+	if len(unhealthy) == 0 {
+		return fmt.Sprintf("All %d services are healthy.", len(statuses)), nil
+	}
+	return fmt.Sprintf("%d of %d services need attention:\n%s", len(unhealthy), len(statuses), strings.Join(unhealthy, "\n")), nil
+}
+
+// lastRunAnswer summarises the most recent session that has ended.
+func (s *Server) lastRunAnswer(_ string) (string, error) {
+	sess, err := s.db.LatestEndedSession()
+	if err != nil {
+		return "", err
+	}
+	if sess == nil {
+		return "No session has finished yet.", nil
+	}
+	found := "No summary was recorded."
+	switch {
+	case sess.Summary != nil && *sess.Summary != "":
+		found = *sess.Summary
+	case sess.Response != nil && *sess.Response != "":
+		if cut, ok := textutil.Cut(*sess.Response, maxLastRunResponse); ok {
+			found = cut + textutil.Ellipsis
+		} else {
+			found = cut
+		}
+	}
+	return fmt.Sprintf("Session #%d (Tier %d, %s, ended %s):\n%s", sess.ID, sess.Tier, sess.Status, *sess.EndedAt, found), nil
+}
@@ -0,0 +1,130 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// estimateSamples is how many recent sessions an estimate averages over.
+const estimateSamples = 50
+
+// runEstimate is the expected cost and duration of an ad-hoc session. It is
+// the template data for the "runEstimate" block in the Run Now modal.
+type runEstimate struct {
+	Tier         int // 0 for auto routing: the worst case across tiers
+	Samples      int
+	CostUSD      float64
+	DurationMs   int64
+	Threshold    float64
+	NeedsConfirm bool
+}
+
+// estimateRun estimates a session starting at tier (0 for auto routing) from
+// the history of that tier's model. The averages are scaled by how the
+// prompt's length compares with earlier ad-hoc prompts, since longer asks
+// tend to run longer.
+func (s *Server) estimateRun(tier int, prompt string) (runEstimate, error) {
+	if tier != 0 {
+		return s.estimateTier(tier, len(strings.TrimSpace(prompt)))
+	}
+	var worst runEstimate
+	for t := 1; t <= s.cfg.MaxTier; t++ {
+		est, err := s.estimateTier(t, len(strings.TrimSpace(prompt)))
+		if err != nil {
+			return runEstimate{}, err
+		}
+		if est.Samples > 0 && (worst.Samples == 0 || est.CostUSD > worst.CostUSD) {
+			worst = est
+		}
+	}
+	worst.Tier = 0
+	worst.Threshold = s.cfg.ConfirmCostThreshold
+	return worst, nil
+}
+
+func (s *Server) estimateTier(tier, promptLen int) (runEstimate, error) {
+	est := runEstimate{Tier: tier, Threshold: s.cfg.ConfirmCostThreshold}
+	st, err := s.db.GetSessionCostStats(tier, s.tierModel(tier), estimateSamples)
+	if err != nil {
+		return est, err
+	}
+	if st.Samples == 0 {
+		// The tier's model changed; fall back to the tier's history.
+		if st, err = s.db.GetSessionCostStats(tier, "", estimateSamples); err != nil {
+			return est, err
+		}
+	}
+	if st.Samples == 0 {
+		return est, nil
+	}
+	factor := promptLengthFactor(promptLen, st.AvgPromptChars)
+	est.Samples = st.Samples
+	est.CostUSD = st.AvgCostUSD * factor
+	est.DurationMs = int64(float64(st.AvgDurationMs) * factor)
+	est.NeedsConfirm = est.Threshold > 0 && est.CostUSD > est.Threshold
+	return est, nil
+}
+
+// promptLengthFactor compares a prompt's length with the historical average,
+// clamped to [0.5, 2] so one unusually short or long prompt cannot swing the
+// estimate too far. Returns 1 when there is nothing to compare.
+func promptLengthFactor(promptLen int, avgChars float64) float64 {
+	if promptLen == 0 || avgChars == 0 {
+		return 1
+	}
+	f := float64(promptLen) / avgChars
+	return min(max(f, 0.5), 2)
+}
+
+func (s *Server) tierModel(tier int) string {
+	switch tier {
+	case 2:
+		return s.cfg.Tier2Model
+	case 3:
+		return s.cfg.Tier3Model
+	default:
+		return s.cfg.Tier1Model
+	}
+}
+
+// handleRunEstimate renders the estimate shown in the Run Now modal as the
+// operator edits the prompt and tier.
+func (s *Server) handleRunEstimate(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	tier, _ := strconv.Atoi(r.FormValue("tier")) // "auto" → 0
+	if tier < 0 || tier > 3 {
+		tier = 0
+	}
+	est, err := s.estimateRun(tier, r.FormValue("prompt"))
+	if err != nil {
+		log.Printf("handleRunEstimate: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates(r).ExecuteTemplate(w, "runEstimate", est); err != nil {
+		http.Error(w, "template error", http.StatusInternalServerError)
+	}
+}
+
+// confirmError returns a non-empty message when an ad-hoc session at tier
+// needs the operator's confirmation and the form does not carry it.
+func (s *Server) confirmError(r *http.Request, tier int, prompt string) string {
+	if s.cfg.ConfirmCostThreshold <= 0 || r.FormValue("confirm") != "" {
+		return ""
+	}
+	est, err := s.estimateRun(tier, prompt)
+	if err != nil {
+		// Estimates are advisory; never block a run on a database error.
+		log.Printf("estimate run: %v", err)
+		return ""
+	}
+	if !est.NeedsConfirm {
+		return ""
+	}
+	return fmt.Sprintf("Estimated cost $%.2f exceeds the $%.2f confirmation threshold. Tick the confirmation box to run.",
+		est.CostUSD, est.Threshold)
+}
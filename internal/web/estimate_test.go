@@ -0,0 +1,104 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func insertCostedSession(t *testing.T, e *testEnv, tier int, model string, cost float64, ms int64) {
+	t.Helper()
+	now := time.Now().UTC().Format(time.RFC3339)
+	id, err := e.srv.db.InsertSession(&db.Session{Tier: tier, Model: model, PromptFile: "/tmp/test.md", Status: "running", StartedAt: now})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+	if err := e.srv.db.UpdateSessionResult(id, "done", cost, 5, ms); err != nil {
+		t.Fatalf("update session result: %v", err)
+	}
+	if err := e.srv.db.UpdateSession(id, "completed", &now, nil, nil); err != nil {
+		t.Fatalf("update session: %v", err)
+	}
+}
+
+func postForm(e *testEnv, path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestRunEstimate(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.cfg.ConfirmCostThreshold = 1.0
+
+	w := postForm(e, "/sessions/estimate", url.Values{"prompt": {"check jellyfin"}, "tier": {"2"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "No cost history yet") {
+		t.Fatalf("expected no history, got %d: %s", w.Code, w.Body.String())
+	}
+
+	insertCostedSession(t, e, 1, "haiku", 0.02, 10000)
+	insertCostedSession(t, e, 2, "sonnet", 0.40, 60000)
+	insertCostedSession(t, e, 3, "opus", 2.50, 300000)
+
+	w = postForm(e, "/sessions/estimate", url.Values{"prompt": {"check jellyfin"}, "tier": {"2"}})
+	body := w.Body.String()
+	if !strings.Contains(body, "~$0.4000") || !strings.Contains(body, "~1m0s") || strings.Contains(body, `name="confirm"`) {
+		t.Errorf("unexpected tier 2 estimate:\n%s", body)
+	}
+
+	w = postForm(e, "/sessions/estimate", url.Values{"prompt": {"check jellyfin"}, "tier": {"auto"}})
+	body = w.Body.String()
+	if !strings.Contains(body, "worst case") || !strings.Contains(body, "~$2.5000") || !strings.Contains(body, `name="confirm"`) {
+		t.Errorf("expected worst-case estimate with confirmation:\n%s", body)
+	}
+}
+
+func TestTriggerSessionRequiresConfirmation(t *testing.T) {
+	e := newTestEnvWithTrigger(t, &mockTrigger{nextID: 7})
+	e.srv.cfg.ConfirmCostThreshold = 1.0
+	insertCostedSession(t, e, 3, "opus", 2.50, 300000)
+
+	w := postForm(e, "/sessions/trigger", url.Values{"prompt": {"rebuild the NAS"}, "tier": {"3"}})
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "confirmation threshold") {
+		t.Fatalf("expected 409 without confirmation, got %d: %s", w.Code, w.Body.String())
+	}
+	if e.trigger.lastPrompt != "" {
+		t.Fatal("session should not have been triggered")
+	}
+
+	w = postForm(e, "/sessions/trigger", url.Values{"prompt": {"rebuild the NAS"}, "tier": {"3"}, "confirm": {"1"}})
+	if w.Code != http.StatusSeeOther || e.trigger.lastStartTier != 3 {
+		t.Fatalf("expected session triggered with confirmation, got %d (tier %d)", w.Code, e.trigger.lastStartTier)
+	}
+
+	// Tier 1 is under the threshold and needs no confirmation.
+	w = postForm(e, "/sessions/trigger", url.Values{"prompt": {"check jellyfin"}, "tier": {"1"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected tier 1 run without confirmation, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPromptLengthFactor(t *testing.T) {
+	for _, tc := range []struct {
+		promptLen int
+		avg       float64
+		want      float64
+	}{
+		{0, 100, 1},
+		{100, 0, 1},
+		{150, 100, 1.5},
+		{1000, 100, 2},
+		{10, 100, 0.5},
+	} {
+		if got := promptLengthFactor(tc.promptLen, tc.avg); got != tc.want {
+			t.Errorf("promptLengthFactor(%d, %v) = %v, want %v", tc.promptLen, tc.avg, got, tc.want)
+		}
+	}
+}
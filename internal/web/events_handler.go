@@ -0,0 +1,275 @@
+package web
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+const (
+	// eventsPageSize is how many events the events page shows at a time.
+	eventsPageSize = 100
+	// eventsCSVLimit bounds a CSV export.
+	eventsCSVLimit = 10000
+)
+
+// eventLevels are the event levels in display order.
+var eventLevels = []string{"critical", "warning", "info"}
+
+// eventRanges maps the events page's time range choices to how far back they
+// reach. The empty range means all time.
+var eventRanges = []struct {
+	Value string
+	Label string
+	Age   time.Duration
+}{
+	{"1h", "Last hour", time.Hour},
+	{"24h", "Last 24 hours", 24 * time.Hour},
+	{"7d", "Last 7 days", 7 * 24 * time.Hour},
+	{"30d", "Last 30 days", 30 * 24 * time.Hour},
+}
+
+// eventQuery is the events filter as given in the query string.
+type eventQuery struct {
+	Level   string
+	Service string
+	Range   string // one of eventRanges, or "" for all time
+	Since   string // RFC3339
+	Until   string // RFC3339
+}
+
+// parseEventQuery reads the level, service, range, since, and until query
+// parameters shared by the events page, its CSV export, and the API.
+func parseEventQuery(r *http.Request) (eventQuery, error) {
+	q := r.URL.Query()
+	eq := eventQuery{
+		Level:   q.Get("level"),
+		Service: q.Get("service"),
+		Range:   q.Get("range"),
+		Since:   q.Get("since"),
+		Until:   q.Get("until"),
+	}
+	if eq.Range != "" && rangeAge(eq.Range) == 0 {
+		return eq, fmt.Errorf("range must be one of 1h, 24h, 7d, 30d")
+	}
+	for name, v := range map[string]string{"since": eq.Since, "until": eq.Until} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return eq, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+		}
+	}
+	return eq, nil
+}
+
+func rangeAge(value string) time.Duration {
+	for _, r := range eventRanges {
+		if r.Value == value {
+			return r.Age
+		}
+	}
+	return 0
+}
+
+// filter converts the query to a db.EventFilter. A range and an explicit
+// since combine to the later of the two.
+func (eq eventQuery) filter(now time.Time) db.EventFilter {
+	var f db.EventFilter
+	if eq.Level != "" {
+		f.Level = &eq.Level
+	}
+	if eq.Service != "" {
+		f.Service = &eq.Service
+	}
+	if eq.Since != "" {
+		f.Since = normalizeTimestamp(eq.Since)
+	}
+	if age := rangeAge(eq.Range); age > 0 {
+		if since := now.Add(-age).UTC().Format(time.RFC3339); since > f.Since {
+			f.Since = since
+		}
+	}
+	if eq.Until != "" {
+		f.Until = normalizeTimestamp(eq.Until)
+	}
+	return f
+}
+
+// normalizeTimestamp converts an RFC3339 timestamp to UTC so it compares
+// correctly with the stored created_at values.
+func normalizeTimestamp(v string) string {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return v
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// url returns path with the query's filters, replacing the level with level
+// and adding offset when it is non-zero.
+func (eq eventQuery) url(path, level string, offset int) string {
+	v := url.Values{}
+	for key, val := range map[string]string{
+		"level": level, "service": eq.Service, "range": eq.Range, "since": eq.Since, "until": eq.Until,
+	} {
+		if val != "" {
+			v.Set(key, val)
+		}
+	}
+	if offset > 0 {
+		v.Set("offset", strconv.Itoa(offset))
+	}
+	if len(v) == 0 {
+		return path
+	}
+	return path + "?" + v.Encode()
+}
+
+// eventChip is a per-level count on the events page. Clicking it filters to
+// that level, or clears the level filter when it is already active.
+type eventChip struct {
+	Level  string // "" for all levels
+	Count  int
+	URL    string
+	Active bool
+}
+
+// eventsPageData is the template data for events.html.
+type eventsPageData struct {
+	Events     []EventView
+	Query      eventQuery
+	Services   []string
+	Ranges     []struct{ Value, Label string }
+	Chips      []eventChip
+	Total      int
+	Offset     int
+	PageStart  int // 1-based position of the first event shown
+	PageEnd    int
+	RefreshURL string
+	PrevURL    string
+	NextURL    string
+	CSVURL     string
+}
+
+// handleEvents renders the events feed.
+// Governing: SPEC-0013 "Events Page" — reverse-chronological events with HTMX polling
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	eq, err := parseEventQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	offset = max(offset, 0)
+	filter := eq.filter(time.Now())
+
+	events, err := s.db.ListEvents(eventsPageSize, offset, filter)
+	if err != nil {
+		log.Printf("handleEvents: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	counts, err := s.db.CountEventsByLevel(filter)
+	if err != nil {
+		log.Printf("handleEvents: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	services, err := s.db.ListEventServices()
+	if err != nil {
+		log.Printf("handleEvents: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	data := eventsPageData{
+		Events:     ToEventViews(events),
+		Query:      eq,
+		Services:   services,
+		Offset:     offset,
+		PageStart:  offset + 1,
+		PageEnd:    offset + len(events),
+		RefreshURL: eq.url("/events", eq.Level, offset),
+		CSVURL:     eq.url("/events.csv", eq.Level, 0),
+	}
+	for _, rg := range eventRanges {
+		data.Ranges = append(data.Ranges, struct{ Value, Label string }{rg.Value, rg.Label})
+	}
+	all := 0
+	for _, n := range counts {
+		all += n
+	}
+	data.Chips = append(data.Chips, eventChip{Count: all, URL: eq.url("/events", "", 0), Active: eq.Level == ""})
+	for _, level := range eventLevels {
+		chip := eventChip{Level: level, Count: counts[level], URL: eq.url("/events", level, 0), Active: eq.Level == level}
+		if chip.Active {
+			chip.URL = eq.url("/events", "", 0)
+		}
+		data.Chips = append(data.Chips, chip)
+	}
+	data.Total = all
+	if eq.Level != "" {
+		data.Total = counts[eq.Level]
+	}
+	if offset > 0 {
+		data.PrevURL = eq.url("/events", eq.Level, max(offset-eventsPageSize, 0))
+	}
+	if data.PageEnd < data.Total {
+		data.NextURL = eq.url("/events", eq.Level, data.PageEnd)
+	}
+
+	s.render(w, r, "events.html", data)
+}
+
+// handleEventsCSV exports the events matching the events page's filters as
+// CSV, newest first. Cells are escaped with csvCell, as messages come from
+// the agent and the services it reads.
+func (s *Server) handleEventsCSV(w http.ResponseWriter, r *http.Request) {
+	eq, err := parseEventQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events, err := s.db.ListEvents(eventsCSVLimit, 0, eq.filter(time.Now()))
+	if err != nil {
+		log.Printf("handleEventsCSV: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="events.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "created_at", "level", "service", "session_id", "message"})
+	for _, e := range events {
+		var service, sessionID string
+		if e.Service != nil {
+			service = *e.Service
+		}
+		if e.SessionID != nil {
+			sessionID = strconv.FormatInt(*e.SessionID, 10)
+		}
+		_ = cw.Write([]string{strconv.FormatInt(e.ID, 10), e.CreatedAt, csvCell(e.Level), csvCell(service), sessionID, csvCell(e.Message)})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Printf("handleEventsCSV: %v", err)
+	}
+}
+
+// csvCell keeps a spreadsheet from reading a cell as a formula: text
+// starting with =, +, -, @, a tab, or a carriage return is prefixed with '.
+func csvCell(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
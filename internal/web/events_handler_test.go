@@ -0,0 +1,122 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func insertTestEvents(t *testing.T, e *testEnv) {
+	t.Helper()
+	now := time.Now().UTC()
+	caddy, jellyfin := "caddy", "jellyfin"
+	for _, ev := range []db.Event{
+		{Level: "critical", Service: &caddy, Message: "caddy down, old", CreatedAt: now.Add(-48 * time.Hour).Format(time.RFC3339)},
+		{Level: "info", Service: &caddy, Message: "caddy healthy", CreatedAt: now.Format(time.RFC3339)},
+		{Level: "warning", Service: &jellyfin, Message: "jellyfin slow, \"buffering\"", CreatedAt: now.Format(time.RFC3339)},
+	} {
+		if _, err := e.srv.db.InsertEvent(&ev); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+}
+
+func getPage(e *testEnv, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func TestEventsPageFilters(t *testing.T) {
+	e := newTestEnv(t)
+	insertTestEvents(t, e)
+
+	w := getPage(e, "/events?service=caddy&range=24h")
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(body, "caddy healthy") || strings.Contains(body, "caddy down, old") || strings.Contains(body, "jellyfin slow") {
+		t.Errorf("expected only recent caddy events:\n%s", body)
+	}
+	if !strings.Contains(body, "info &middot; 1") || !strings.Contains(body, "critical &middot; 0") {
+		t.Errorf("expected per-level count chips:\n%s", body)
+	}
+	if !strings.Contains(body, `/events.csv?range=24h&amp;service=caddy`) {
+		t.Errorf("expected CSV link to carry the filters:\n%s", body)
+	}
+
+	if w := getPage(e, "/events?range=1y"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown range, got %d", w.Code)
+	}
+}
+
+func TestEventsPagePagination(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC()
+	for i := range eventsPageSize + 5 {
+		ts := now.Add(time.Duration(i) * time.Second).Format(time.RFC3339)
+		if _, err := e.srv.db.InsertEvent(&db.Event{Level: "info", Message: fmt.Sprintf("event-%03d", i), CreatedAt: ts}); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	body := getPage(e, "/events").Body.String()
+	if !strings.Contains(body, "1&ndash;100 of 105") || !strings.Contains(body, `href="/events?offset=100"`) {
+		t.Errorf("expected first page with an Older link:\n%s", body)
+	}
+	body = getPage(e, "/events?offset=100").Body.String()
+	if !strings.Contains(body, "101&ndash;105 of 105") || !strings.Contains(body, "event-000") || strings.Contains(body, "Older") {
+		t.Errorf("expected last page without an Older link:\n%s", body)
+	}
+}
+
+func TestEventsCSV(t *testing.T) {
+	e := newTestEnv(t)
+	insertTestEvents(t, e)
+
+	w := getPage(e, "/events.csv?service=jellyfin")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != 2 || records[0][2] != "level" || records[1][5] != `jellyfin slow, "buffering"` {
+		t.Errorf("unexpected CSV %v", records)
+	}
+	// A message read as a formula by a spreadsheet is escaped.
+	svc := "radarr"
+	if _, err := e.srv.db.InsertEvent(&db.Event{Level: "warning", Service: &svc, Message: `=HYPERLINK("http://evil.example","x")`,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339)}); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+	records, err = csv.NewReader(getPage(e, "/events.csv?service=radarr").Body).ReadAll()
+	if err != nil || len(records) != 2 || records[1][5] != `'=HYPERLINK("http://evil.example","x")` {
+		t.Errorf("unexpected CSV %v (%v)", records, err)
+	}
+}
+
+func TestAPIListEventsTimeRange(t *testing.T) {
+	e := newTestEnv(t)
+	insertTestEvents(t, e)
+
+	since := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+	w := getPage(e, "/api/v1/events?since="+since)
+	var resp APIEventsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || len(resp.Events) != 2 {
+		t.Fatalf("expected 2 recent events, got %+v (err %v)", resp.Events, err)
+	}
+
+	if w := getPage(e, "/api/v1/events?until=yesterday"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid until, got %d", w.Code)
+	}
+}
@@ -0,0 +1,203 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/offload"
+	"github.com/joestump/claude-ops/internal/seal"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// ExportManifest describes a static snapshot written by ExportSnapshot. It is
+// saved as manifest.json next to the data files.
+type ExportManifest struct {
+	Version     string `json:"version"`
+	GeneratedAt string `json:"generated_at"`
+	Sessions    int    `json:"sessions"`
+	Events      int    `json:"events"`
+	Memories    int    `json:"memories"`
+	Logs        int    `json:"logs"`
+	// MissingLogs lists sessions whose log file could not be read.
+	MissingLogs []int64 `json:"missing_logs,omitempty"`
+}
+
+// exportSession is a session as written to sessions.json: the API
+// representation plus the fields only the dashboard shows, and the session's
+// log path relative to the export directory.
+type exportSession struct {
+	APISession
+	Summary *string `json:"summary"`
+	LogFile *string `json:"log_file"`
+}
+
+// ExportSnapshot writes a self-contained static export of the database to
+// dir for offline analysis: sessions.json, events.json, and memories.json in
+// the API's JSON shapes, session logs copied into logs/, a manifest.json, and
+// an index.html that needs no server. Logs moved to object storage are
+// fetched through logs. dir is created if needed and must be empty so a
+// snapshot never mixes with an older one.
+func ExportSnapshot(database *db.DB, logs *offload.Offloader, dir string) (*ExportManifest, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("export directory %s is not empty", dir)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "logs"), 0o755); err != nil {
+		return nil, fmt.Errorf("create export directory: %w", err)
+	}
+
+	// SQLite treats a negative LIMIT as no limit.
+	sessions, err := database.ListSessions(-1, 0)
+	if err != nil {
+		return nil, err
+	}
+	events, err := database.ListEvents(-1, 0, db.EventFilter{})
+	if err != nil {
+		return nil, err
+	}
+	memories, err := database.ListMemories(nil, nil, nil, -1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &ExportManifest{
+		Version:     config.Version,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Sessions:    len(sessions),
+		Events:      len(events),
+		Memories:    len(memories),
+	}
+	out := make([]exportSession, len(sessions))
+	for i, s := range sessions {
+		out[i] = exportSession{APISession: toAPISession(s), Summary: s.Summary}
+		out[i].Response = s.Response
+		if s.LogFile == nil || *s.LogFile == "" {
+			continue
+		}
+		rel := filepath.Join("logs", strings.TrimSuffix(filepath.Base(*s.LogFile), ".gz"))
+		if err := copyLog(logs, database.Sealer(), &s, filepath.Join(dir, rel)); err != nil {
+			if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, offload.ErrNotConfigured) {
+				return nil, fmt.Errorf("copy log for session %d: %w", s.ID, err)
+			}
+			manifest.MissingLogs = append(manifest.MissingLogs, s.ID)
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		out[i].LogFile = &rel
+		manifest.Logs++
+	}
+
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"sessions.json", map[string]any{"sessions": out}},
+		{"events.json", APIEventsResponse{Events: toAPIEvents(events)}},
+		{"memories.json", APIMemoriesResponse{Memories: toAPIMemories(memories)}},
+		{"manifest.json", manifest},
+	}
+	for _, f := range files {
+		if err := writeExportJSON(filepath.Join(dir, f.name), f.v); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeExportIndex(filepath.Join(dir, "index.html"), manifest, out); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeExportJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// copyLog copies a session log in plaintext, fetching it from object
+// storage when it was offloaded, decompressing it, and opening sealed
+// lines: a snapshot is for reading without the deployment.
+func copyLog(logs *offload.Offloader, sealer *seal.Sealer, s *db.Session, dst string) error {
+	in, err := logs.Open(context.Background(), s)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if sealer == nil {
+		_, err = io.Copy(out, in)
+	} else {
+		scanner := session.NewLogScanner(in)
+		for scanner.Scan() && err == nil {
+			_, err = fmt.Fprintln(out, sealer.OpenOr(scanner.Text()))
+		}
+		if err == nil {
+			err = scanner.Err()
+		}
+	}
+	if err != nil {
+		out.Close() //nolint:errcheck
+		return err
+	}
+	return out.Close()
+}
+
+// exportIndexTmpl is the export's index.html. It is standalone (no CDN
+// assets) so it opens from a bug report attachment without network access.
+var exportIndexTmpl = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Claude Ops snapshot {{.Manifest.GeneratedAt}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .3rem .6rem; border-bottom: 1px solid #ddd; vertical-align: top; }
+.muted { color: #777; }
+</style>
+</head>
+<body>
+<h1>Claude Ops snapshot</h1>
+<p class="muted">Version {{.Manifest.Version}}, generated {{.Manifest.GeneratedAt}}.
+{{.Manifest.Sessions}} sessions, {{.Manifest.Events}} events, {{.Manifest.Memories}} memories.</p>
+<p><a href="sessions.json">sessions.json</a> &middot; <a href="events.json">events.json</a> &middot; <a href="memories.json">memories.json</a> &middot; <a href="manifest.json">manifest.json</a></p>
+<table>
+<tr><th>ID</th><th>Tier</th><th>Status</th><th>Trigger</th><th>Started</th><th>Parent</th><th>Summary</th><th>Log</th></tr>
+{{range .Sessions}}<tr>
+<td>{{.ID}}</td><td>{{.Tier}}</td><td>{{.Status}}</td><td>{{.Trigger}}</td><td>{{.StartedAt}}</td>
+<td>{{with .ParentSessionID}}{{.}}{{end}}</td>
+<td>{{with .Summary}}{{.}}{{end}}</td>
+<td>{{with .LogFile}}<a href="{{.}}">log</a>{{else}}<span class="muted">none</span>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func writeExportIndex(path string, manifest *ExportManifest, sessions []exportSession) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("write index.html: %w", err)
+	}
+	if err := exportIndexTmpl.Execute(f, map[string]any{"Manifest": manifest, "Sessions": sessions}); err != nil {
+		f.Close() //nolint:errcheck
+		return fmt.Errorf("render index.html: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestExportSnapshot(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	logPath := filepath.Join(t.TempDir(), "claudeops-20260101-000000.log")
+	if err := os.WriteFile(logPath, []byte("session log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(t.TempDir(), "gone.log")
+	summary := "jellyfin <restarted>"
+	logged, err := e.srv.db.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/tmp/tier1.md", Status: "completed",
+		StartedAt: now, LogFile: &logPath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.srv.db.UpdateSessionSummary(logged, summary); err != nil {
+		t.Fatal(err)
+	}
+	unlogged, err := e.srv.db.InsertSession(&db.Session{
+		Tier: 2, Model: "sonnet", PromptFile: "/tmp/tier2.md", Status: "failed",
+		StartedAt: now, LogFile: &missing, ParentSessionID: &logged,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := "jellyfin"
+	if _, err := e.srv.db.InsertEvent(&db.Event{SessionID: &logged, Level: "critical", Service: &svc, Message: "down", CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.srv.db.InsertMemory(&db.Memory{Service: &svc, Category: "timing", Observation: "slow start", Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "snap")
+	manifest, err := ExportSnapshot(e.srv.db, nil, dir)
+	if err != nil {
+		t.Fatalf("ExportSnapshot: %v", err)
+	}
+	if manifest.Sessions != 2 || manifest.Events != 1 || manifest.Memories != 1 || manifest.Logs != 1 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if len(manifest.MissingLogs) != 1 || manifest.MissingLogs[0] != unlogged {
+		t.Errorf("missing logs = %v, want [%d]", manifest.MissingLogs, unlogged)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "sessions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sessions struct {
+		Sessions []struct {
+			ID      int64   `json:"id"`
+			Summary *string `json:"summary"`
+			LogFile *string `json:"log_file"`
+		} `json:"sessions"`
+	}
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sessions.Sessions {
+		switch s.ID {
+		case logged:
+			if s.LogFile == nil || *s.LogFile != "logs/claudeops-20260101-000000.log" || s.Summary == nil || *s.Summary != summary {
+				t.Errorf("logged session = %+v", s)
+			}
+		case unlogged:
+			if s.LogFile != nil {
+				t.Errorf("missing log should not be referenced, got %q", *s.LogFile)
+			}
+		}
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "logs", "claudeops-20260101-000000.log")); err != nil || string(got) != "session log\n" {
+		t.Errorf("copied log = %q, %v", got, err)
+	}
+	for _, name := range []string{"events.json", "memories.json", "manifest.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "jellyfin &lt;restarted&gt;") || !strings.Contains(string(index), `href="logs/claudeops-20260101-000000.log"`) {
+		t.Errorf("index.html missing session row:\n%s", index)
+	}
+
+	if _, err := ExportSnapshot(e.srv.db, nil, dir); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("second export into %s: err = %v, want not empty", dir, err)
+	}
+}
@@ -0,0 +1,175 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+const (
+	// correctionConfidence is the confidence of the memory a thumbs down
+	// creates: the operator's word outranks anything the agent learned.
+	correctionConfidence = 0.95
+
+	// feedbackRecentLimit bounds the feedback listed on the feedback page.
+	feedbackRecentLimit = 50
+)
+
+// registerFeedbackRoutes wires session feedback and the feedback summary page.
+func (s *Server) registerFeedbackRoutes() {
+	s.mux.HandleFunc("POST /sessions/{id}/feedback", s.handleSessionFeedback)
+	s.mux.HandleFunc("GET /feedback", s.handleFeedback)
+}
+
+// sessionFeedbackData is the sessionFeedback template's data.
+type sessionFeedbackData struct {
+	SessionID int64
+	Feedback  []db.SessionFeedback
+	Error     string
+}
+
+// sessionFeedback loads a session's feedback for its page.
+func (s *Server) sessionFeedback(sessionID int64) sessionFeedbackData {
+	data := sessionFeedbackData{SessionID: sessionID}
+	feedback, err := s.db.ListSessionFeedback(sessionID)
+	if err != nil {
+		log.Printf("sessionFeedback: %v", err)
+	}
+	data.Feedback = feedback
+	return data
+}
+
+// handleSessionFeedback records a thumbs up or down on a session. A thumbs
+// down needs a comment, which becomes a high-confidence memory so later
+// sessions avoid the flagged approach.
+func (s *Server) handleSessionFeedback(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad form data", http.StatusBadRequest)
+		return
+	}
+	var rating int
+	switch r.FormValue("rating") {
+	case "up":
+		rating = 1
+	case "down":
+		rating = -1
+	default:
+		http.Error(w, "rating must be up or down", http.StatusBadRequest)
+		return
+	}
+
+	sess, err := s.db.GetSession(id)
+	if err != nil {
+		log.Printf("handleSessionFeedback: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if sess == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	comment := strings.TrimSpace(r.FormValue("comment"))
+	if rating < 0 && comment == "" {
+		data := s.sessionFeedback(id)
+		data.Error = "Say what was wrong: the comment is saved as a memory that steers later sessions."
+		s.renderFeedback(w, r, data)
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	f := &db.SessionFeedback{SessionID: id, Rating: rating, CreatedAt: now}
+	if comment != "" {
+		f.Comment = &comment
+	}
+	if by := s.approverName(r); by != "" {
+		f.Operator = &by
+	}
+	var correction *db.Memory
+	if rating < 0 {
+		correction = correctiveMemory(sess, comment, now)
+	}
+	if _, err := s.db.InsertSessionFeedback(f, correction); err != nil {
+		log.Printf("handleSessionFeedback: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	s.renderFeedback(w, r, s.sessionFeedback(id))
+}
+
+// correctiveMemory builds the memory a thumbs down on sess creates. It is
+// scoped to the session's service when the session was about exactly one.
+func correctiveMemory(sess *db.Session, comment, now string) *db.Memory {
+	sid := sess.ID
+	m := &db.Memory{
+		Category:    "remediation",
+		Observation: fmt.Sprintf("Operator flagged session #%d's approach as wrong: %s", sess.ID, comment),
+		Confidence:  correctionConfidence,
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		SessionID:   &sid,
+		Tier:        0,
+	}
+	if sess.Services != nil && *sess.Services != "" && !strings.Contains(*sess.Services, ",") {
+		svc := *sess.Services
+		m.Service = &svc
+		m.Observation = fmt.Sprintf("Operator flagged session #%d's approach to %s as wrong: %s", sess.ID, svc, comment)
+	}
+	return m
+}
+
+func (s *Server) renderFeedback(w http.ResponseWriter, r *http.Request, data sessionFeedbackData) {
+	if err := s.templates(r).ExecuteTemplate(w, "sessionFeedback", data); err != nil {
+		log.Printf("template error: %v", err)
+	}
+}
+
+// feedbackPeriod is one column of the feedback page's summary.
+type feedbackPeriod struct {
+	Label string
+	Stats *db.FeedbackStats
+}
+
+// handleFeedback summarises how often sessions needed correction and lists
+// recent feedback.
+func (s *Server) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	var periods []feedbackPeriod
+	for _, p := range []struct {
+		label string
+		since string
+	}{
+		{"Last 7 days", now.AddDate(0, 0, -7).Format(time.RFC3339)},
+		{"Last 30 days", now.AddDate(0, 0, -30).Format(time.RFC3339)},
+		{"All time", ""},
+	} {
+		st, err := s.db.GetFeedbackStats(p.since)
+		if err != nil {
+			log.Printf("handleFeedback: %v", err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		periods = append(periods, feedbackPeriod{Label: p.label, Stats: st})
+	}
+	recent, err := s.db.ListRecentFeedback(feedbackRecentLimit)
+	if err != nil {
+		log.Printf("handleFeedback: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	s.render(w, r, "feedback.html", struct {
+		Periods []feedbackPeriod
+		Recent  []db.SessionFeedback
+	}{periods, recent})
+}
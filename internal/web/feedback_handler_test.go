@@ -0,0 +1,88 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func postFeedback(e *testEnv, id int64, form url.Values) *httptest.ResponseRecorder {
+	e.srv.cfg.TrustedProxies = "192.0.2.0/24" // httptest requests come from 192.0.2.1
+	req := httptest.NewRequest("POST", fmt.Sprintf("/sessions/%d/feedback", id), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Remote-User", "alice")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestSessionFeedback(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+	ended := time.Now().UTC().Format(time.RFC3339)
+	if err := e.srv.db.UpdateSession(id, "completed", &ended, nil, nil); err != nil {
+		t.Fatalf("update session: %v", err)
+	}
+	if err := e.srv.db.UpdateSessionServices(id, []string{"postgres"}); err != nil {
+		t.Fatalf("update session services: %v", err)
+	}
+
+	if body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String(); !strings.Contains(body, fmt.Sprintf(`hx-post="/sessions/%d/feedback"`, id)) {
+		t.Fatal("session page missing the feedback form")
+	}
+
+	if w := postFeedback(e, id, url.Values{"rating": {"sideways"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid rating, got %d", w.Code)
+	}
+	w := postFeedback(e, id, url.Values{"rating": {"down"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Say what was wrong") {
+		t.Fatalf("expected a comment to be required, got %d:\n%s", w.Code, w.Body.String())
+	}
+
+	w = postFeedback(e, id, url.Values{"rating": {"down"}, "comment": {"restarting postgres hid the full disk"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "restarting postgres hid the full disk") {
+		t.Fatalf("expected the feedback listed, got %d:\n%s", w.Code, w.Body.String())
+	}
+	postFeedback(e, id, url.Values{"rating": {"up"}})
+
+	feedback, err := e.srv.db.ListSessionFeedback(id)
+	if err != nil || len(feedback) != 2 {
+		t.Fatalf("expected 2 feedback entries, got %+v (err %v)", feedback, err)
+	}
+	down := feedback[0]
+	if down.Rating != -1 || down.Operator == nil || *down.Operator != "alice" || down.MemoryID == nil {
+		t.Fatalf("unexpected thumbs down %+v", down)
+	}
+	mem, err := e.srv.db.GetMemory(*down.MemoryID)
+	if err != nil || mem == nil {
+		t.Fatalf("get corrective memory: %v", err)
+	}
+	want := fmt.Sprintf("Operator flagged session #%d's approach to postgres as wrong: restarting postgres hid the full disk", id)
+	if mem.Observation != want || mem.Service == nil || *mem.Service != "postgres" || mem.Confidence != correctionConfidence {
+		t.Errorf("unexpected corrective memory %+v", mem)
+	}
+	if feedback[1].Rating != 1 || feedback[1].MemoryID != nil {
+		t.Errorf("thumbs up should not create a memory: %+v", feedback[1])
+	}
+
+	body := getPage(e, "/feedback").Body.String()
+	for _, want := range []string{"Last 7 days", "100% corrected", "restarting postgres hid the full disk", "alice"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feedback page missing %q", want)
+		}
+	}
+}
+
+func TestCorrectiveMemoryWithoutSingleService(t *testing.T) {
+	services := "caddy,jellyfin"
+	m := correctiveMemory(&db.Session{ID: 7, Services: &services}, "wrong order", "2026-10-01T00:00:00Z")
+	if m.Service != nil || m.Observation != "Operator flagged session #7's approach as wrong: wrong order" {
+		t.Errorf("unexpected memory %+v", m)
+	}
+}
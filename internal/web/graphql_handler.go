@@ -0,0 +1,345 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/graphql"
+)
+
+const (
+	// graphQLMaxLimit caps the limit argument of list queries.
+	graphQLMaxLimit = 500
+
+	// graphQLMaxBody bounds the size of a GraphQL request.
+	graphQLMaxBody = 1 << 20
+)
+
+// handleGraphQL runs a read-only GraphQL query, POSTed as JSON
+// ({"query", "operationName", "variables"}) or passed in the query string
+// of a GET. An invalid query is a 400; a query that fails while resolving
+// returns data: null with the error.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodPost {
+		if !requireJSON(w, r) {
+			return
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphQLMaxBody)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+	} else {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	resp := s.gql.Execute(r.Context(), req)
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	} else if len(resp.Errors) > 0 {
+		log.Printf("handleGraphQL: %s", resp.Errors[0].Message)
+	}
+	writeJSON(w, status, resp)
+}
+
+// handleGraphQLSchema returns the GraphQL schema in SDL, for client code
+// generators and alternative frontends.
+func (s *Server) handleGraphQLSchema(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(s.gql.SDL()))
+}
+
+// graphQLSchema builds the GraphQL schema. Field names mirror the db
+// package models in camelCase; relations between records (a session's
+// children, events, memories, and remediation actions, and the session an
+// event or memory came from) load for all records in a level of the query
+// with one database query.
+func (s *Server) graphQLSchema() *graphql.Schema {
+	session := &graphql.Object{Name: "Session", Description: "A run of the agent at one tier.", Fields: []*graphql.Field{
+		graphql.Prop("id", "Int!", "", func(p any) any { return p.(db.Session).ID }),
+		graphql.Prop("tier", "Int!", "", func(p any) any { return p.(db.Session).Tier }),
+		graphql.Prop("model", "String!", "", func(p any) any { return p.(db.Session).Model }),
+		graphql.Prop("status", "String!", "running, completed, failed, timed_out, escalated, continued, reopened, or interrupted.", func(p any) any { return p.(db.Session).Status }),
+		graphql.Prop("trigger", "String!", "", func(p any) any { return p.(db.Session).Trigger }),
+		graphql.Prop("promptFile", "String!", "", func(p any) any { return p.(db.Session).PromptFile }),
+		graphql.Prop("promptText", "String", "Prompt of an ad-hoc session.", func(p any) any { return p.(db.Session).PromptText }),
+		graphql.Prop("startedAt", "String!", "", func(p any) any { return p.(db.Session).StartedAt }),
+		graphql.Prop("endedAt", "String", "", func(p any) any { return p.(db.Session).EndedAt }),
+		graphql.Prop("exitCode", "Int", "", func(p any) any { return p.(db.Session).ExitCode }),
+		graphql.Prop("costUsd", "Float", "", func(p any) any { return p.(db.Session).CostUSD }),
+		graphql.Prop("costSynthetic", "Boolean!", "Whether costUsd is estimated from token usage.", func(p any) any { return p.(db.Session).CostSynthetic }),
+		graphql.Prop("numTurns", "Int", "", func(p any) any { return p.(db.Session).NumTurns }),
+		graphql.Prop("durationMs", "Int", "", func(p any) any { return p.(db.Session).DurationMs }),
+		graphql.Prop("maxContextTokens", "Int", "", func(p any) any { return p.(db.Session).MaxContext }),
+		graphql.Prop("services", "[String!]!", "Services the session was scoped to.", func(p any) any {
+			out := []string{}
+			if svc := p.(db.Session).Services; svc != nil && *svc != "" {
+				out = strings.Split(*svc, ",")
+			}
+			return out
+		}),
+		graphql.Prop("workDir", "String", "", func(p any) any { return p.(db.Session).WorkDir }),
+		graphql.Prop("gitSha", "String", "", func(p any) any { return p.(db.Session).GitSHA }),
+		graphql.Prop("summary", "String", "", func(p any) any { return p.(db.Session).Summary }),
+		graphql.Prop("response", "String", "Final markdown response.", func(p any) any { return p.(db.Session).Response }),
+		graphql.Prop("parentSessionId", "Int", "", func(p any) any { return p.(db.Session).ParentSessionID }),
+		{Name: "parent", Type: "Session", Description: "Session this one was escalated from.",
+			Resolve: func(_ context.Context, parents []any, _ graphql.Args) ([]any, error) {
+				return s.loadSessions(parents, func(p any) *int64 { return p.(db.Session).ParentSessionID })
+			}},
+		{Name: "children", Type: "[Session!]!", Description: "Sessions escalated from this one.",
+			Resolve: func(_ context.Context, parents []any, _ graphql.Args) ([]any, error) {
+				children, err := s.db.ListChildSessionsOf(sessionIDs(parents))
+				if err != nil {
+					return nil, err
+				}
+				return groupBySession(parents, children, func(c db.Session) *int64 { return c.ParentSessionID }), nil
+			}},
+		{Name: "events", Type: "[Event!]!", Description: "Events the session recorded, oldest first.",
+			Resolve: func(_ context.Context, parents []any, _ graphql.Args) ([]any, error) {
+				events, err := s.db.ListEventsForSessions(sessionIDs(parents))
+				if err != nil {
+					return nil, err
+				}
+				return groupBySession(parents, events, func(e db.Event) *int64 { return e.SessionID }), nil
+			}},
+		{Name: "memories", Type: "[Memory!]!", Description: "Memories the session recorded.",
+			Resolve: func(_ context.Context, parents []any, _ graphql.Args) ([]any, error) {
+				memories, err := s.db.ListMemoriesForSessions(sessionIDs(parents))
+				if err != nil {
+					return nil, err
+				}
+				return groupBySession(parents, memories, func(m db.Memory) *int64 { return m.SessionID }), nil
+			}},
+		{Name: "cooldownActions", Type: "[CooldownAction!]!", Description: "Remediation actions the session recorded, oldest first.",
+			Resolve: func(_ context.Context, parents []any, _ graphql.Args) ([]any, error) {
+				actions, err := s.db.ListCooldownActionsForSessions(sessionIDs(parents))
+				if err != nil {
+					return nil, err
+				}
+				return groupBySession(parents, actions, func(a db.CooldownAction) *int64 { return a.SessionID }), nil
+			}},
+	}}
+
+	event := &graphql.Object{Name: "Event", Fields: []*graphql.Field{
+		graphql.Prop("id", "Int!", "", func(p any) any { return p.(db.Event).ID }),
+		graphql.Prop("level", "String!", "info, warning, or critical.", func(p any) any { return p.(db.Event).Level }),
+		graphql.Prop("service", "String", "", func(p any) any { return p.(db.Event).Service }),
+		graphql.Prop("message", "String!", "", func(p any) any { return p.(db.Event).Message }),
+		graphql.Prop("createdAt", "String!", "", func(p any) any { return p.(db.Event).CreatedAt }),
+		graphql.Prop("sessionId", "Int", "", func(p any) any { return p.(db.Event).SessionID }),
+		{Name: "session", Type: "Session", Resolve: func(_ context.Context, parents []any, _ graphql.Args) ([]any, error) {
+			return s.loadSessions(parents, func(p any) *int64 { return p.(db.Event).SessionID })
+		}},
+	}}
+
+	memory := &graphql.Object{Name: "Memory", Fields: []*graphql.Field{
+		graphql.Prop("id", "Int!", "", func(p any) any { return p.(db.Memory).ID }),
+		graphql.Prop("service", "String", "", func(p any) any { return p.(db.Memory).Service }),
+		graphql.Prop("category", "String!", "", func(p any) any { return p.(db.Memory).Category }),
+		graphql.Prop("observation", "String!", "", func(p any) any { return p.(db.Memory).Observation }),
+		graphql.Prop("confidence", "Float!", "", func(p any) any { return p.(db.Memory).Confidence }),
+		graphql.Prop("active", "Boolean!", "", func(p any) any { return p.(db.Memory).Active }),
+		graphql.Prop("reviewStatus", "String!", "unverified, verified, or rejected.", func(p any) any { return p.(db.Memory).ReviewStatus }),
+		graphql.Prop("tier", "Int!", "", func(p any) any { return p.(db.Memory).Tier }),
+		graphql.Prop("createdAt", "String!", "", func(p any) any { return p.(db.Memory).CreatedAt }),
+		graphql.Prop("updatedAt", "String!", "", func(p any) any { return p.(db.Memory).UpdatedAt }),
+		graphql.Prop("sessionId", "Int", "", func(p any) any { return p.(db.Memory).SessionID }),
+		{Name: "session", Type: "Session", Resolve: func(_ context.Context, parents []any, _ graphql.Args) ([]any, error) {
+			return s.loadSessions(parents, func(p any) *int64 { return p.(db.Memory).SessionID })
+		}},
+	}}
+
+	action := &graphql.Object{Name: "CooldownAction", Description: "A remediation action counted against a service's cooldown.", Fields: []*graphql.Field{
+		graphql.Prop("id", "Int!", "", func(p any) any { return p.(db.CooldownAction).ID }),
+		graphql.Prop("service", "String!", "", func(p any) any { return p.(db.CooldownAction).Service }),
+		graphql.Prop("actionType", "String!", "", func(p any) any { return p.(db.CooldownAction).ActionType }),
+		graphql.Prop("timestamp", "String!", "", func(p any) any { return p.(db.CooldownAction).Timestamp }),
+		graphql.Prop("success", "Boolean!", "", func(p any) any { return p.(db.CooldownAction).Success }),
+		graphql.Prop("tier", "Int!", "", func(p any) any { return p.(db.CooldownAction).Tier }),
+		graphql.Prop("error", "String", "", func(p any) any { return p.(db.CooldownAction).Error }),
+		graphql.Prop("outcome", "String", "effective or ineffective once scored.", func(p any) any { return p.(db.CooldownAction).Outcome }),
+		graphql.Prop("outcomeReason", "String", "", func(p any) any { return p.(db.CooldownAction).OutcomeReason }),
+		graphql.Prop("sessionId", "Int", "", func(p any) any { return p.(db.CooldownAction).SessionID }),
+		{Name: "session", Type: "Session", Resolve: func(_ context.Context, parents []any, _ graphql.Args) ([]any, error) {
+			return s.loadSessions(parents, func(p any) *int64 { return p.(db.CooldownAction).SessionID })
+		}},
+	}}
+
+	cooldown := &graphql.Object{Name: "Cooldown", Description: "Remediation actions on a service within a window.", Fields: []*graphql.Field{
+		graphql.Prop("service", "String!", "", func(p any) any { return p.(db.RecentCooldown).Service }),
+		graphql.Prop("actionType", "String!", "", func(p any) any { return p.(db.RecentCooldown).ActionType }),
+		graphql.Prop("count", "Int!", "", func(p any) any { return p.(db.RecentCooldown).Count }),
+		graphql.Prop("lastAction", "String!", "", func(p any) any { return p.(db.RecentCooldown).LastAction }),
+	}}
+
+	pageArgs := []graphql.Arg{
+		{Name: "limit", Type: "Int", Default: 50, Description: "At most 500."},
+		{Name: "offset", Type: "Int", Default: 0},
+	}
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{Name: "sessions", Type: "[Session!]!", Description: "Sessions, newest first.", Args: pageArgs,
+			Resolve: func(_ context.Context, _ []any, args graphql.Args) ([]any, error) {
+				sessions, err := s.db.ListSessions(graphQLLimit(args), max(0, args.Int("offset")))
+				return rootList(sessions, err)
+			}},
+		{Name: "session", Type: "Session", Args: []graphql.Arg{{Name: "id", Type: "Int!"}},
+			Resolve: func(_ context.Context, _ []any, args graphql.Args) ([]any, error) {
+				sess, err := s.db.GetSession(int64(args.Int("id")))
+				if err != nil || sess == nil {
+					return []any{nil}, err
+				}
+				return []any{*sess}, nil
+			}},
+		{Name: "events", Type: "[Event!]!", Description: "Events, newest first.",
+			Args: append([]graphql.Arg{
+				{Name: "level", Type: "String"},
+				{Name: "service", Type: "String"},
+				{Name: "since", Type: "String", Description: "RFC3339 timestamp."},
+				{Name: "until", Type: "String", Description: "RFC3339 timestamp."},
+			}, pageArgs...),
+			Resolve: func(_ context.Context, _ []any, args graphql.Args) ([]any, error) {
+				filter := db.EventFilter{Since: args.String("since"), Until: args.String("until")}
+				if args.Has("level") {
+					level := args.String("level")
+					filter.Level = &level
+				}
+				if args.Has("service") {
+					svc := args.String("service")
+					filter.Service = &svc
+				}
+				events, err := s.db.ListEvents(graphQLLimit(args), max(0, args.Int("offset")), filter)
+				return rootList(events, err)
+			}},
+		{Name: "memories", Type: "[Memory!]!", Description: "Memories, other than deleted ones, by confidence.",
+			Args: append([]graphql.Arg{
+				{Name: "service", Type: "String"},
+				{Name: "category", Type: "String"},
+				{Name: "reviewStatus", Type: "String"},
+			}, pageArgs...),
+			Resolve: func(_ context.Context, _ []any, args graphql.Args) ([]any, error) {
+				var service, category, status *string
+				if args.Has("service") {
+					v := args.String("service")
+					service = &v
+				}
+				if args.Has("category") {
+					v := args.String("category")
+					category = &v
+				}
+				if args.Has("reviewStatus") {
+					v := args.String("reviewStatus")
+					status = &v
+				}
+				memories, err := s.db.ListMemories(service, category, status, graphQLLimit(args), max(0, args.Int("offset")))
+				return rootList(memories, err)
+			}},
+		{Name: "cooldowns", Type: "[Cooldown!]!", Description: "Remediation actions per service and type in the last hours, most recent first.",
+			Args: []graphql.Arg{{Name: "hours", Type: "Int", Default: 24}},
+			Resolve: func(_ context.Context, _ []any, args graphql.Args) ([]any, error) {
+				cooldowns, err := s.db.ListRecentCooldowns(time.Duration(max(1, args.Int("hours"))) * time.Hour)
+				return rootList(cooldowns, err)
+			}},
+	}}
+
+	schema, err := graphql.NewSchema(query, session, event, memory, action, cooldown)
+	if err != nil {
+		// The schema is static, so this is a programming error.
+		panic("graphql schema: " + err.Error())
+	}
+	return schema
+}
+
+// graphQLLimit returns the limit argument, capped at graphQLMaxLimit.
+func graphQLLimit(args graphql.Args) int {
+	return min(max(0, args.Int("limit")), graphQLMaxLimit)
+}
+
+// rootList returns items as the value of a root list field.
+func rootList[T any](items []T, err error) ([]any, error) {
+	if err != nil {
+		return nil, err
+	}
+	list := make([]any, len(items))
+	for i, item := range items {
+		list[i] = item
+	}
+	return []any{list}, nil
+}
+
+// sessionIDs returns the IDs of parent sessions.
+func sessionIDs(parents []any) []int64 {
+	ids := make([]int64, len(parents))
+	for i, p := range parents {
+		ids[i] = p.(db.Session).ID
+	}
+	return ids
+}
+
+// groupBySession returns, for each parent session, the items that belong to
+// it, keeping their order.
+func groupBySession[T any](parents []any, items []T, sessionOf func(T) *int64) []any {
+	byID := make(map[int64][]any)
+	for _, item := range items {
+		if id := sessionOf(item); id != nil {
+			byID[*id] = append(byID[*id], item)
+		}
+	}
+	out := make([]any, len(parents))
+	for i, p := range parents {
+		list := byID[p.(db.Session).ID]
+		if list == nil {
+			list = []any{}
+		}
+		out[i] = list
+	}
+	return out
+}
+
+// loadSessions returns the session each parent refers to, loading them all
+// with one query.
+func (s *Server) loadSessions(parents []any, idOf func(any) *int64) ([]any, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, p := range parents {
+		if id := idOf(p); id != nil && !seen[*id] {
+			seen[*id] = true
+			ids = append(ids, *id)
+		}
+	}
+	sessions, err := s.db.GetSessionsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]db.Session, len(sessions))
+	for _, sess := range sessions {
+		byID[sess.ID] = sess
+	}
+	out := make([]any, len(parents))
+	for i, p := range parents {
+		if id := idOf(p); id != nil {
+			if sess, ok := byID[*id]; ok {
+				out[i] = sess
+			}
+		}
+	}
+	return out, nil
+}
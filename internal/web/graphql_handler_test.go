@@ -0,0 +1,146 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func postGraphQL(e *testEnv, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestGraphQLSessionChain(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	parentID := insertTestSession(t, e, "escalated")
+	childID, err := e.srv.db.InsertSession(&db.Session{
+		Tier: 2, Model: "sonnet", PromptFile: "/tmp/tier2.md", Status: "completed",
+		StartedAt: now, ParentSessionID: &parentID,
+	})
+	if err != nil {
+		t.Fatalf("insert child session: %v", err)
+	}
+	svc := "jellyfin"
+	for _, ev := range []*db.Event{
+		{SessionID: &parentID, Level: "critical", Service: &svc, Message: "jellyfin down", CreatedAt: now},
+		{SessionID: &childID, Level: "info", Service: &svc, Message: "jellyfin restarted", CreatedAt: now},
+	} {
+		if _, err := e.srv.db.InsertEvent(ev); err != nil {
+			t.Fatalf("insert event: %v", err)
+		}
+	}
+	if _, err := e.srv.db.InsertCooldownAction(&db.CooldownAction{
+		Service: svc, ActionType: "restart", Timestamp: now, Success: true, Tier: 2, SessionID: &childID,
+	}); err != nil {
+		t.Fatalf("insert cooldown action: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"query": `query Chain($id: Int!) {
+			session(id: $id) {
+				id status
+				events { message }
+				children { tier parent { id } events { level message } cooldownActions { actionType success } }
+			}
+		}`,
+		"variables": map[string]any{"id": parentID},
+	})
+	w := postGraphQL(e, string(body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Session struct {
+				ID       int64
+				Status   string
+				Events   []struct{ Message string }
+				Children []struct {
+					Tier   int
+					Parent struct{ ID int64 }
+					Events []struct {
+						Level   string
+						Message string
+					}
+					CooldownActions []struct {
+						ActionType string
+						Success    bool
+					}
+				}
+			}
+		}
+		Errors []struct{ Message string }
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	sess := resp.Data.Session
+	if sess.ID != parentID || sess.Status != "escalated" || len(sess.Events) != 1 || sess.Events[0].Message != "jellyfin down" {
+		t.Errorf("unexpected session %+v", sess)
+	}
+	if len(sess.Children) != 1 {
+		t.Fatalf("expected 1 child, got %+v", sess.Children)
+	}
+	child := sess.Children[0]
+	if child.Tier != 2 || child.Parent.ID != parentID {
+		t.Errorf("unexpected child %+v", child)
+	}
+	if len(child.Events) != 1 || child.Events[0].Message != "jellyfin restarted" {
+		t.Errorf("unexpected child events %+v", child.Events)
+	}
+	if len(child.CooldownActions) != 1 || child.CooldownActions[0].ActionType != "restart" || !child.CooldownActions[0].Success {
+		t.Errorf("unexpected child cooldown actions %+v", child.CooldownActions)
+	}
+}
+
+func TestGraphQLGetAndErrors(t *testing.T) {
+	e := newTestEnv(t)
+	insertTestSession(t, e, "completed")
+
+	w := getPage(e, "/api/v1/graphql?query="+url.QueryEscape(`{ sessions(limit: 5) { status } }`))
+	if w.Code != http.StatusOK || w.Body.String() != `{"data":{"sessions":[{"status":"completed"}]}}`+"\n" {
+		t.Errorf("GET: %d %s", w.Code, w.Body.String())
+	}
+
+	w = postGraphQL(e, `{"query": "{ sessions { nope } }"}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `cannot query field \"nope\"`) {
+		t.Errorf("invalid field: %d %s", w.Code, w.Body.String())
+	}
+
+	w = postGraphQL(e, `{"query": "mutation { sessions { id } }"}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "read-only") {
+		t.Errorf("mutation: %d %s", w.Code, w.Body.String())
+	}
+
+	w = getPage(e, "/api/v1/graphql")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing query: expected 400, got %d", w.Code)
+	}
+}
+
+func TestGraphQLSchemaSDL(t *testing.T) {
+	e := newTestEnv(t)
+	w := getPage(e, "/api/v1/graphql/schema")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	for _, want := range []string{"type Query {", "type Session {", "children: [Session!]!", "events(level: String"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("schema missing %q", want)
+		}
+	}
+}
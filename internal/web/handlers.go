@@ -1,10 +1,10 @@
 package web
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
@@ -16,6 +16,8 @@ import (
 	"time"
 
 	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/scheduler"
 	"github.com/joestump/claude-ops/internal/session"
 )
 
@@ -64,20 +66,21 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var activityEvents []db.Event
-	if evts, err := s.db.ListEvents(50, 0, nil, nil); err != nil {
+	if evts, err := s.db.ListEvents(50, 0, db.EventFilter{}); err != nil {
 		log.Printf("handleIndex: ListEvents: %v", err)
 	} else {
 		activityEvents = evts
 	}
 
 	var activityMemories []db.Memory
-	if mems, err := s.db.ListMemories(nil, nil, 15, 0); err != nil {
+	if mems, err := s.db.ListMemories(nil, nil, nil, 15, 0); err != nil {
 		log.Printf("handleIndex: ListMemories: %v", err)
 	} else {
 		activityMemories = mems
 	}
 
 	activity := buildActivityFeed(activitySessions, activityEvents, activityMemories)
+	_, waiting := s.scheduledRun()
 
 	data := struct {
 		Stats       *db.DashboardStats
@@ -85,14 +88,24 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		LastSummary *SessionView
 		Activity    []ActivityItem
 		NextRun     time.Time
+		Waiting     bool
 		Interval    int
+		Adaptive    *scheduler.AdaptiveState
+		Interrupted *session.InterruptedChain
+		Approvals   []db.ApprovalRequest
+		Changes     []db.ChangeReport
 	}{
 		Stats:       stats,
 		LastSession: lastSession,
 		LastSummary: lastSummary,
 		Activity:    activity,
-		NextRun:     time.Now().UTC().Add(time.Duration(s.cfg.Interval) * time.Second),
+		NextRun:     s.nextRunEstimate(),
+		Waiting:     waiting,
 		Interval:    s.cfg.Interval,
+		Adaptive:    s.adaptiveInterval(),
+		Interrupted: s.interruptedChain(),
+		Approvals:   s.pendingApprovals(),
+		Changes:     s.pendingChangeReports(),
 	}
 
 	s.render(w, r, "index.html", data)
@@ -101,7 +114,22 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 // handleSessions renders the session list.
 // Governing: SPEC-0013 "Real-Time Sessions List" — serves polling endpoint for HTMX auto-refresh
 func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
-	sessions, err := s.db.ListSessions(50, 0)
+	sq, err := parseSessionQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	offset = max(offset, 0)
+	filter := sq.filter(time.Now())
+
+	sessions, err := s.db.FindSessions(filter, sq.Sort, sq.asc(), sessionsPageSize, offset)
+	if err != nil {
+		log.Printf("handleSessions: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	total, err := s.db.CountSessions(filter)
 	if err != nil {
 		log.Printf("handleSessions: %v", err)
 		http.Error(w, "database error", http.StatusInternalServerError)
@@ -160,7 +188,28 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Pass 4: propagate chain tip status to all chain members for left-border coloring.
+	// Flag sessions whose output had markers the parser dropped or
+	// credential values redacted.
+	ids := make([]int64, len(views))
+	for i, v := range views {
+		ids[i] = v.ID
+	}
+	if counts, err := s.db.CountParseWarnings(ids); err != nil {
+		log.Printf("handleSessions: %v", err)
+	} else {
+		for i := range views {
+			views[i].ParseWarnings = counts[views[i].ID]
+		}
+	}
+	if counts, err := s.db.CountSessionRedactions(ids); err != nil {
+		log.Printf("handleSessions: %v", err)
+	} else {
+		for i := range views {
+			views[i].Redactions = counts[views[i].ID]
+		}
+	}
+
+	// Pass 4: propagate chain tip outcome (or status, while it has none) to all chain members for left-border coloring.
 	viewIdx := make(map[int64]int, len(views))
 	for i, v := range views {
 		viewIdx[v.ID] = i
@@ -168,6 +217,9 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 	for i := range views {
 		if views[i].IsChainTip {
 			status := views[i].Status
+			if views[i].Outcome != "" {
+				status = views[i].Outcome
+			}
 			views[i].ChainOutcome = status
 			pid := views[i].ParentSessionID
 			for pid != nil {
@@ -181,10 +233,27 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	data := struct {
-		Sessions []SessionView
-	}{
-		Sessions: views,
+	data := sessionsPageData{
+		Sessions:   views,
+		Query:      sq,
+		Tiers:      []string{"1", "2", "3"},
+		Statuses:   sessionStatuses,
+		Triggers:   sessionTriggers,
+		Outcomes:   db.SessionOutcomes,
+		Total:      total,
+		Offset:     offset,
+		PageStart:  offset + 1,
+		PageEnd:    offset + len(views),
+		RefreshURL: sq.url("/sessions", offset),
+	}
+	for _, rg := range eventRanges {
+		data.Ranges = append(data.Ranges, struct{ Value, Label string }{rg.Value, rg.Label})
+	}
+	if offset > 0 {
+		data.PrevURL = sq.url("/sessions", max(offset-sessionsPageSize, 0))
+	}
+	if data.PageEnd < total {
+		data.NextURL = sq.url("/sessions", data.PageEnd)
 	}
 
 	s.render(w, r, "sessions.html", data)
@@ -213,6 +282,13 @@ func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	view := ToSessionView(*sess)
+	if sess.Status == "running" {
+		if l := s.runningSession(sess.ID); l != nil {
+			cost := l.CostUSD
+			view.CostUSD = &cost
+			view.CostLive = true
+		}
+	}
 
 	// Load parent session if this session was escalated.
 	// Governing: SPEC-0016 REQ "Dashboard Escalation Chain Display" — parent/child links and chain cost
@@ -264,9 +340,16 @@ func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 			view.ChainCost = total
+			if root := chain[0]; root.ChainSummary != nil {
+				view.ChainSummary = *root.ChainSummary
+			}
 		}
 	}
 
+	if sess.Status != "running" {
+		view.ChainLive = s.chainLive(sess.ID)
+	}
+
 	// Governing: SPEC-0011 "Log File Formatting on Read Path" — format NDJSON log line-by-line.
 	// Read and format log file contents if available.
 	// Log files contain timestamped NDJSON from --output-format stream-json.
@@ -275,14 +358,14 @@ func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
 	// Governing: SPEC-0011 "Log File Formatting on Read Path" — line-by-line formatting via scanner
 	var output string
 	if sess.LogFile != nil && *sess.LogFile != "" {
-		if f, err := os.Open(*sess.LogFile); err == nil {
+		if f, err := s.logs.Open(r.Context(), sess); err == nil {
 			var lines []string
-			var lineNum int
-			scanner := bufio.NewScanner(f)
-			scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
+			var lineNum, logLine int
+			scanner := session.NewLogScanner(f)
 			for scanner.Scan() {
-				ts, raw, hasTS := session.ParseTimestampedLogLine(scanner.Text())
-				formatted := session.FormatStreamEventHTML(raw)
+				logLine++
+				ts, raw, hasTS := session.ParseTimestampedLogLine(s.db.Sealer().OpenOr(scanner.Text()))
+				formatted := session.FormatStreamEventHTMLAt(raw, sess.ID, logLine)
 				if formatted != "" {
 					lineNum++
 					tsStr := ""
@@ -299,17 +382,165 @@ func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Stream events the parser dropped; the page warns once they pass the
+	// configured threshold so parser gaps after CLI upgrades are visible.
+	diagnostics, err := s.db.ListStreamDiagnostics(sess.ID)
+	if err != nil {
+		log.Printf("handleSession: %v", err)
+	}
+	dropped := 0
+	for _, d := range diagnostics {
+		dropped += d.Count
+	}
+
+	// Credential values the redaction filter replaced in the output, by rule.
+	redactions, err := s.db.ListSessionRedactions(sess.ID)
+	if err != nil {
+		log.Printf("handleSession: %v", err)
+	}
+	redacted := 0
+	for _, r := range redactions {
+		redacted += r.Count
+	}
+
+	policyEvals, err := s.db.ListPolicyEvaluations(sess.ID)
+	if err != nil {
+		log.Printf("handleSession: %v", err)
+	}
+
+	incidents, err := s.db.ListSessionPagerIncidents(sess.ID)
+	if err != nil {
+		log.Printf("handleSession: %v", err)
+	}
+
+	commits, err := s.db.ListSessionCommits(sess.ID)
+	if err != nil {
+		log.Printf("handleSession: %v", err)
+	}
+
+	linked, err := s.db.ListSessionTickets(sess.ID)
+	if err != nil {
+		log.Printf("handleSession: %v", err)
+	}
+
+	changes, err := s.db.GetSessionChangeReport(sess.ID)
+	if err != nil {
+		log.Printf("handleSession: %v", err)
+	}
+	if err := s.logs.RestoreArtifacts(r.Context(), sess, commits, changes); err != nil {
+		log.Printf("handleSession: session %d artifacts: %v", sess.ID, err)
+	}
+
+	parseWarnings, err := s.db.ListParseWarnings(sess.ID)
+	if err != nil {
+		log.Printf("handleSession: %v", err)
+	}
+
+	var decision *DecisionView
+	if d, err := s.db.GetEscalationDecision(sess.ID); err != nil {
+		log.Printf("handleSession: %v", err)
+	} else if d != nil {
+		v := ToDecisionView(*d)
+		decision = &v
+	}
+
 	tmplData := struct {
-		Session SessionView
-		Output  template.HTML
+		Session     SessionView
+		Output      template.HTML
+		Invocation  *session.Invocation
+		Diagnostics []db.StreamDiagnostic
+		Dropped     int
+		DropWarn    bool
+		ContextWarn bool
+		Redactions  []db.SessionRedaction
+		Redacted    int
+		Decision    *DecisionView
+		Policy      []db.PolicyEvaluation
+		Incidents   []db.PagerIncident
+		Commits     []db.SessionCommit
+		Tickets     []db.SessionTicket
+		Changes     *db.ChangeReport
+		Warnings    []db.ParseWarning
+		Feedback    sessionFeedbackData
 	}{
-		Session: view,
-		Output:  template.HTML(output),
+		Session:     view,
+		Output:      template.HTML(output),
+		Invocation:  session.ParseInvocation(sess.Invocation),
+		Diagnostics: diagnostics,
+		Dropped:     dropped,
+		DropWarn:    dropped > s.cfg.StreamDropWarn,
+		ContextWarn: s.cfg.ContextWarnPercent > 0 && view.MaxContextPct >= int64(s.cfg.ContextWarnPercent),
+		Redactions:  redactions,
+		Redacted:    redacted,
+		Decision:    decision,
+		Policy:      policyEvals,
+		Incidents:   incidents,
+		Commits:     commits,
+		Tickets:     linked,
+		Changes:     changes,
+		Warnings:    parseWarnings,
+		Feedback:    s.sessionFeedback(sess.ID),
 	}
 
 	s.render(w, r, "session.html", tmplData)
 }
 
+// handleSessionLogLine returns the full tool result from one line of a
+// session's log, replacing the truncated block in the activity log.
+func (s *Server) handleSessionLogLine(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+	line, err := strconv.Atoi(r.PathValue("line"))
+	if err != nil || line < 1 {
+		http.Error(w, "invalid line number", http.StatusBadRequest)
+		return
+	}
+
+	sess, err := s.db.GetSession(id)
+	if err != nil {
+		log.Printf("handleSessionLogLine: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if sess == nil || sess.LogFile == nil || *sess.LogFile == "" {
+		http.Error(w, "session log not found", http.StatusNotFound)
+		return
+	}
+
+	f, err := s.logs.Open(r.Context(), sess)
+	if errors.Is(err, os.ErrNotExist) {
+		http.Error(w, "log line not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("handleSessionLogLine: %v", err)
+		http.Error(w, "error reading log", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close() //nolint:errcheck
+	text, err := session.ReadLogLineFrom(f, *sess.LogFile, line)
+	if err != nil {
+		if errors.Is(err, session.ErrLogLineNotFound) || errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "log line not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("handleSessionLogLine: %v", err)
+		http.Error(w, "error reading log", http.StatusInternalServerError)
+		return
+	}
+	_, raw, _ := session.ParseTimestampedLogLine(s.db.Sealer().OpenOr(text))
+	html := session.FullToolResultHTML(raw)
+	if html == "" {
+		http.Error(w, "line is not a tool result", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(html))
+}
+
 // handleSessionStream opens an SSE connection for a running session.
 // Governing: SPEC-0008 REQ-3 — HTMX-Based Interactivity (hx-ext="sse" for real-time streaming)
 // Governing: SPEC-0008 REQ-10 — session view real-time streaming via SSE.
@@ -346,45 +577,32 @@ func (s *Server) handleSessionStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ch, unsubscribe := s.hub.Subscribe(id)
+	ch, unsubscribe := s.hub.Subscribe(id, hub.Formatted)
 	defer unsubscribe()
 
-	ctx := r.Context()
+	if streamFormatted(r.Context(), w, flusher, ch) {
+		_, _ = fmt.Fprintf(w, "event: done\ndata: session complete\n\n")
+		flusher.Flush()
+	}
+}
+
+// streamFormatted writes a session's formatted lines as SSE messages until
+// the session ends, returning false if the request was cancelled first.
+func streamFormatted(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, ch <-chan hub.Event) bool {
 	for {
 		select {
 		case <-ctx.Done():
-			return
-		case line, ok := <-ch:
+			return false
+		case evt, ok := <-ch:
 			if !ok {
-				_, _ = fmt.Fprintf(w, "event: done\ndata: session complete\n\n")
-				flusher.Flush()
-				return
+				return true
 			}
-			_, _ = fmt.Fprintf(w, "data: %s\n\n", line)
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", evt.Data)
 			flusher.Flush()
 		}
 	}
 }
 
-// handleEvents renders the events feed.
-// Governing: SPEC-0013 "Events Page" — reverse-chronological events with HTMX polling
-func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
-	events, err := s.db.ListEvents(100, 0, nil, nil)
-	if err != nil {
-		log.Printf("handleEvents: %v", err)
-		http.Error(w, "database error", http.StatusInternalServerError)
-		return
-	}
-
-	data := struct {
-		Events []EventView
-	}{
-		Events: ToEventViews(events),
-	}
-
-	s.render(w, r, "events.html", data)
-}
-
 // cooldownJSONState mirrors the agent-managed cooldown.json schema.
 // Governing: SPEC-0007 REQ-13 (Cooldown State Data Model)
 type cooldownJSONState struct {
@@ -529,19 +747,59 @@ func (s *Server) handleMemories(w http.ResponseWriter, r *http.Request) {
 		categoryFilter = &v
 	}
 
-	memories, err := s.db.ListMemories(serviceFilter, categoryFilter, 200, 0)
+	memories, err := s.db.ListMemories(serviceFilter, categoryFilter, nil, 200, 0)
+	if err != nil {
+		log.Printf("handleMemories: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	unverified := db.MemoryUnverified
+	pending, err := s.db.ListMemories(serviceFilter, categoryFilter, &unverified, 200, 0)
+	if err != nil {
+		log.Printf("handleMemories: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	pendingIDs := make([]int64, len(pending))
+	for i, m := range pending {
+		pendingIDs[i] = m.ID
+	}
+	suggested, err := s.db.SuggestionEventCounts(pendingIDs)
+	if err != nil {
+		log.Printf("handleMemories: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	tombstoned, err := s.db.ListTombstonedMemories(100)
 	if err != nil {
 		log.Printf("handleMemories: %v", err)
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
 
+	categories, err := s.db.RegisteredMemoryCategories()
+	if err != nil {
+		log.Printf("handleMemories: categories: %v", err)
+	}
+
 	data := struct {
-		Memories []MemoryView
-		Service  string
-		Category string
+		Memories   []MemoryView
+		Pending    []MemoryView
+		Tombstoned []MemoryView
+		Categories []string
+		Service    string
+		Category   string
 	}{
-		Memories: ToMemoryViews(memories),
+		Memories:   ToMemoryViews(memories),
+		Pending:    ToMemoryViews(pending),
+		Tombstoned: ToMemoryViews(tombstoned),
+		Categories: categories,
+	}
+	for i := range data.Pending {
+		data.Pending[i].SuggestedFrom = suggested[data.Pending[i].ID]
 	}
 	if serviceFilter != nil {
 		data.Service = *serviceFilter
@@ -636,7 +894,102 @@ func (s *Server) handleMemoryUpdate(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/memories", http.StatusSeeOther)
 }
 
-// Governing: SPEC-0015 "Dashboard Memory CRUD" — operator permanently deletes a memory
+// handleMemoryApprove handles POST /memories/{id}/approve. An optional
+// "observation" form value lets the operator correct the text while approving.
+func (s *Server) handleMemoryApprove(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid memory ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad form data", http.StatusBadRequest)
+		return
+	}
+
+	if observation := strings.TrimSpace(r.FormValue("observation")); observation != "" {
+		existing, err := s.db.GetMemory(id)
+		if err != nil {
+			log.Printf("handleMemoryApprove: %v", err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		if existing == nil {
+			http.Error(w, "memory not found", http.StatusNotFound)
+			return
+		}
+		if observation != existing.Observation {
+			if err := s.db.UpdateMemory(id, observation, existing.Confidence, existing.Active); err != nil {
+				log.Printf("handleMemoryApprove: %v", err)
+				http.Error(w, "database error", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if err := s.db.SetMemoryReviewStatus(id, db.MemoryVerified); err != nil {
+		log.Printf("handleMemoryApprove: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/memories", http.StatusSeeOther)
+}
+
+// handleMemoryReject handles POST /memories/{id}/reject.
+func (s *Server) handleMemoryReject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid memory ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.SetMemoryReviewStatus(id, db.MemoryRejected); err != nil {
+		log.Printf("handleMemoryReject: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/memories", http.StatusSeeOther)
+}
+
+// handleMemoryRestore handles POST /memories/{id}/restore, returning a deleted
+// or rejected memory to the review queue.
+func (s *Server) handleMemoryRestore(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid memory ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.RestoreMemory(id); err != nil {
+		log.Printf("handleMemoryRestore: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/memories", http.StatusSeeOther)
+}
+
+// handleMemoryPurge handles POST /memories/{id}/purge, permanently removing a
+// memory and its tombstone.
+func (s *Server) handleMemoryPurge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid memory ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.PurgeMemory(id); err != nil {
+		log.Printf("handleMemoryPurge: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/memories", http.StatusSeeOther)
+}
+
+// Governing: SPEC-0015 "Dashboard Memory CRUD" — operator deletes a memory (soft delete with tombstone)
 // handleMemoryDelete handles POST /memories/{id}/delete.
 func (s *Server) handleMemoryDelete(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
@@ -742,13 +1095,30 @@ func (s *Server) handleTriggerSession(w http.ResponseWriter, r *http.Request) {
 		log.Printf("handleTriggerSession: LLM routed %q → tier %d", prompt, startTier)
 	}
 
+	if msg := s.confirmError(r, startTier, prompt); msg != "" {
+		http.Error(w, msg, http.StatusConflict)
+		return
+	}
+
 	sessionID, err := s.mgr.TriggerAdHoc(prompt, startTier, "manual")
-	if err != nil {
+	target := fmt.Sprintf("/sessions/%d", sessionID)
+	var conflict *session.ServiceConflictError
+	switch {
+	case errors.As(err, &conflict):
+		http.Error(w, fmt.Sprintf("%v: %s", err, s.sessionURL(conflict.SessionID)), http.StatusConflict)
+		return
+	case errors.Is(err, session.ErrQueued):
+		// The session starts when the running chain ends.
+		s.followQueued(r, nil, err)
+		target = "/sessions"
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusConflict)
 		return
+	default:
+		s.tagSession(r, sessionID)
 	}
+	s.recordPrompt(prompt, r.FormValue("favorite") != "")
 
-	target := fmt.Sprintf("/sessions/%d", sessionID)
 	if r.Header.Get("HX-Request") != "" {
 		w.Header().Set("HX-Redirect", target)
 		w.WriteHeader(http.StatusNoContent)
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// historyWeeks is how many weeks the activity heatmap covers, ending with
+	// the current week.
+	historyWeeks = 53
+	// historyDayEventLimit bounds the events listed for a selected day.
+	historyDayEventLimit = 500
+	dayFormat            = "2006-01-02"
+)
+
+// registerHistoryRoutes wires the activity heatmap page.
+func (s *Server) registerHistoryRoutes() {
+	s.mux.HandleFunc("GET /history", s.handleHistory)
+}
+
+// historyDay is one cell of the activity heatmap.
+type historyDay struct {
+	Date     string // YYYY-MM-DD (UTC)
+	Sessions int
+	CostUSD  float64
+	MaxLevel string
+	Class    string // background color: hue from MaxLevel, shade from CostUSD
+	Future   bool
+	Selected bool
+}
+
+// historyWeek is one column of the heatmap, Sunday first.
+type historyWeek struct {
+	Month string // month abbreviation when the week starts a month
+	Days  [7]historyDay
+}
+
+// historyPageData is the template data for history.html.
+type historyPageData struct {
+	Weeks      []historyWeek
+	Sessions   int
+	CostUSD    float64
+	ActiveDays int
+
+	// The selected day, when one was clicked.
+	Day         string
+	DaySessions []SessionView
+	DayEvents   []EventView
+	DayCostUSD  float64
+	EventsURL   string
+}
+
+// handleHistory renders a year of activity as a heatmap. ?day=YYYY-MM-DD
+// lists that day's sessions and events below it.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -int(today.Weekday())-7*(historyWeeks-1))
+	end := today.AddDate(0, 0, 1)
+
+	var selected time.Time
+	if v := r.URL.Query().Get("day"); v != "" {
+		t, err := time.Parse(dayFormat, v)
+		if err != nil {
+			http.Error(w, "day must be YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		selected = t
+	}
+
+	activity, err := s.db.GetDailyActivity(start.Format(time.RFC3339), end.Format(time.RFC3339))
+	if err != nil {
+		log.Printf("handleHistory: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	var data historyPageData
+	byDay := make(map[string]historyDay, len(activity))
+	maxCost := 0.0
+	for _, a := range activity {
+		byDay[a.Day] = historyDay{Date: a.Day, Sessions: a.Sessions, CostUSD: a.CostUSD, MaxLevel: a.MaxLevel}
+		maxCost = max(maxCost, a.CostUSD)
+		data.Sessions += a.Sessions
+		data.CostUSD += a.CostUSD
+		data.ActiveDays++
+	}
+
+	for wk := range historyWeeks {
+		var week historyWeek
+		for wd := range 7 {
+			t := start.AddDate(0, 0, 7*wk+wd)
+			key := t.Format(dayFormat)
+			d, ok := byDay[key]
+			if !ok {
+				d = historyDay{Date: key}
+			}
+			d.Future = t.After(today)
+			d.Selected = !selected.IsZero() && t.Equal(selected)
+			d.Class = heatClass(d, maxCost)
+			if (t.Day() == 1 || wk == 0 && wd == 0) && !d.Future {
+				week.Month = t.Format("Jan")
+			}
+			week.Days[wd] = d
+		}
+		data.Weeks = append(data.Weeks, week)
+	}
+
+	if !selected.IsZero() {
+		since := selected.Format(time.RFC3339)
+		until := selected.AddDate(0, 0, 1).Format(time.RFC3339)
+		sessions, err := s.db.ListSessionsBetween(since, until)
+		if err != nil {
+			log.Printf("handleHistory: %v", err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		events, err := s.db.ListEvents(historyDayEventLimit, 0, eventQuery{Since: since, Until: until}.filter(time.Now()))
+		if err != nil {
+			log.Printf("handleHistory: %v", err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		data.Day = selected.Format(dayFormat)
+		data.DaySessions = ToSessionViews(sessions)
+		data.DayEvents = ToEventViews(events)
+		for _, sess := range sessions {
+			if sess.CostUSD != nil {
+				data.DayCostUSD += *sess.CostUSD
+			}
+		}
+		data.EventsURL = "/events?" + url.Values{"since": {since}, "until": {until}}.Encode()
+	}
+
+	s.render(w, r, "history.html", data)
+}
+
+// heatClass colors a heatmap cell: the hue is the day's most severe event
+// level (green when nothing worse than info) and the shade is its cost
+// relative to the costliest day.
+func heatClass(d historyDay, maxCost float64) string {
+	if d.Future {
+		return "invisible"
+	}
+	if d.Sessions == 0 && d.MaxLevel == "" {
+		return "bg-surface"
+	}
+	hue := "green"
+	switch d.MaxLevel {
+	case "critical":
+		hue = "red"
+	case "warning":
+		hue = "yellow"
+	}
+	shade := 300
+	if maxCost > 0 && d.CostUSD > 0 {
+		shade = [...]int{300, 400, 500, 600, 700}[int(4*d.CostUSD/maxCost)]
+	}
+	return fmt.Sprintf("bg-%s-%d", hue, shade)
+}
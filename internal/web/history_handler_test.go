@@ -0,0 +1,59 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestHistoryPage(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC()
+	today := now.Format(dayFormat)
+	insertCostedSession(t, e, 2, "sonnet", 0.40, 60000)
+	if _, err := e.srv.db.InsertEvent(&db.Event{Level: "critical", Message: "caddy down", CreatedAt: now.Format(time.RFC3339)}); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+
+	w := getPage(e, "/history")
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(body, `title="`+today+`: 1 session, $0.4000, worst event critical"`) || !strings.Contains(body, "bg-red-700") {
+		t.Errorf("expected today's cell to be red at full shade:\n%s", body)
+	}
+	if strings.Contains(body, "caddy down") {
+		t.Error("expected no day details without ?day")
+	}
+
+	body = getPage(e, "/history?day="+today).Body.String()
+	if !strings.Contains(body, "caddy down") || !strings.Contains(body, `href="/sessions/1"`) || !strings.Contains(body, "ring-2") {
+		t.Errorf("expected the day's sessions and events:\n%s", body)
+	}
+
+	if w := getPage(e, "/history?day=yesterday"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid day, got %d", w.Code)
+	}
+}
+
+func TestHeatClass(t *testing.T) {
+	for _, tc := range []struct {
+		day  historyDay
+		want string
+	}{
+		{historyDay{}, "bg-surface"},
+		{historyDay{Future: true, Sessions: 1}, "invisible"},
+		{historyDay{Sessions: 3}, "bg-green-300"},
+		{historyDay{Sessions: 1, CostUSD: 1, MaxLevel: "info"}, "bg-green-700"},
+		{historyDay{Sessions: 1, CostUSD: 0.5, MaxLevel: "warning"}, "bg-yellow-500"},
+		{historyDay{MaxLevel: "critical"}, "bg-red-300"},
+	} {
+		if got := heatClass(tc.day, 1); got != tc.want {
+			t.Errorf("heatClass(%+v) = %q, want %q", tc.day, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// idempotencyHeader lets a client retry a request that triggers a session
+// without triggering a second one.
+const idempotencyHeader = "Idempotency-Key"
+
+// idempotencyReplayedHeader marks a response to a retried request, which
+// refers to the session the first request triggered.
+const idempotencyReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyWindow is how long a key is remembered after its request
+// triggered a session.
+const idempotencyWindow = 24 * time.Hour
+
+// maxIdempotencyKeyLen bounds the key a client can send.
+const maxIdempotencyKeyLen = 255
+
+var (
+	errIdempotencyInProgress = errors.New("a request with this Idempotency-Key is still in progress")
+	errIdempotencyMismatch   = errors.New("this Idempotency-Key was used with a different request body")
+	errIdempotencyKeyTooLong = errors.New("Idempotency-Key must be at most 255 characters")
+)
+
+// idempotencyCache remembers which session each Idempotency-Key triggered.
+// It is in memory, so keys are forgotten on restart.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	now     func() time.Time
+}
+
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	sessionID   int64 // 0 while the first request is in progress
+	expires     time.Time
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: make(map[string]*idempotencyEntry), now: time.Now}
+}
+
+// idempotencyClaim is a request's hold on its Idempotency-Key. The handler
+// must call complete once the request has triggered a session, and release
+// otherwise, so a retry can trigger one.
+type idempotencyClaim struct {
+	cache *idempotencyCache
+	key   string
+	done  bool
+}
+
+// claim looks up the key a request sent to endpoint with body. A key seen
+// before returns the session its first request triggered. A new key
+// returns a claim, and a request without one returns neither.
+func (c *idempotencyCache) claim(r *http.Request, endpoint string, body any) (*idempotencyClaim, int64, error) {
+	key := r.Header.Get(idempotencyHeader)
+	if key == "" {
+		return nil, 0, nil
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return nil, 0, errIdempotencyKeyTooLong
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	fingerprint := sha256.Sum256(data)
+	key = endpoint + "\x00" + key
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if e, ok := c.entries[key]; ok {
+		switch {
+		case e.fingerprint != fingerprint:
+			return nil, 0, errIdempotencyMismatch
+		case e.sessionID == 0:
+			return nil, 0, errIdempotencyInProgress
+		}
+		return nil, e.sessionID, nil
+	}
+	c.entries[key] = &idempotencyEntry{fingerprint: fingerprint, expires: now.Add(idempotencyWindow)}
+	return &idempotencyClaim{cache: c, key: key}, 0, nil
+}
+
+// complete records the session the request triggered. It is a no-op on a
+// nil claim.
+func (cl *idempotencyClaim) complete(sessionID int64) {
+	if cl == nil || cl.done {
+		return
+	}
+	cl.done = true
+	cl.cache.mu.Lock()
+	defer cl.cache.mu.Unlock()
+	if e, ok := cl.cache.entries[cl.key]; ok {
+		e.sessionID = sessionID
+		e.expires = cl.cache.now().Add(idempotencyWindow)
+	}
+}
+
+// release forgets the key of a request that did not trigger a session. It
+// is a no-op after complete or on a nil claim.
+func (cl *idempotencyClaim) release() {
+	if cl == nil || cl.done {
+		return
+	}
+	cl.done = true
+	cl.cache.mu.Lock()
+	defer cl.cache.mu.Unlock()
+	delete(cl.cache.entries, cl.key)
+}
+
+// idempotencyStatus is the HTTP status for a claim error.
+func idempotencyStatus(err error) int {
+	switch {
+	case errors.Is(err, errIdempotencyInProgress):
+		return http.StatusConflict
+	case errors.Is(err, errIdempotencyMismatch):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
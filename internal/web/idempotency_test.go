@@ -0,0 +1,167 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func TestTriggerIdempotencyKey(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "running")
+	trigger := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/sessions/trigger", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(idempotencyHeader, key)
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		return w
+	}
+	body := `{"prompt":"check postgres"}`
+
+	// A failed trigger does not use up the key.
+	if w := trigger("k1", body); w.Code != http.StatusConflict {
+		t.Fatalf("busy trigger: %d %s", w.Code, w.Body.String())
+	}
+	e.trigger.nextErr, e.trigger.nextID = nil, id
+	w := trigger("k1", body)
+	if w.Code != http.StatusCreated || w.Header().Get(idempotencyReplayedHeader) != "" {
+		t.Fatalf("first trigger: %d %v", w.Code, w.Header())
+	}
+
+	// The retry returns the same session without triggering another.
+	e.trigger.nextErr = errors.New("session already running")
+	w = trigger("k1", body)
+	var sess APISession
+	_ = json.NewDecoder(w.Body).Decode(&sess)
+	if w.Code != http.StatusCreated || sess.ID != id || w.Header().Get(idempotencyReplayedHeader) != "true" {
+		t.Errorf("retry: %d session %d, headers %v", w.Code, sess.ID, w.Header())
+	}
+
+	if w := trigger("k1", `{"prompt":"check redis"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused with another body: %d %s", w.Code, w.Body.String())
+	}
+	if w := trigger(strings.Repeat("k", maxIdempotencyKeyLen+1), body); w.Code != http.StatusBadRequest {
+		t.Errorf("overlong key: %d", w.Code)
+	}
+}
+
+func TestQueuedTriggerKeepsKeyAndRequestID(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "running")
+	started := make(chan int64, 1)
+	e.trigger.nextErr = &session.QueuedError{Started: started}
+	trigger := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/sessions/trigger", strings.NewReader(`{"prompt":"check postgres"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(idempotencyHeader, "k1")
+		req.Header.Set(requestIDHeader, "ci-run-7")
+		w := httptest.NewRecorder()
+		e.srv.server.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := trigger(); w.Code != http.StatusAccepted {
+		t.Fatalf("queued trigger: %d %s", w.Code, w.Body.String())
+	}
+	if w := trigger(); w.Code != http.StatusConflict {
+		t.Errorf("retry while queued: %d %s", w.Code, w.Body.String())
+	}
+
+	started <- id
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sess, err := e.srv.db.GetSession(id)
+		if err == nil && sess.RequestID != nil && *sess.RequestID == "ci-run-7" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queued session was not tagged with its request ID: %+v", sess)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	w := trigger()
+	var sess APISession
+	_ = json.NewDecoder(w.Body).Decode(&sess)
+	if w.Code != http.StatusCreated || sess.ID != id || w.Header().Get(idempotencyReplayedHeader) != "true" {
+		t.Errorf("retry after start: %d session %d, headers %v", w.Code, sess.ID, w.Header())
+	}
+}
+
+func TestIdempotencyCache(t *testing.T) {
+	c := newIdempotencyCache()
+	clock := time.Now()
+	c.now = func() time.Time { return clock }
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set(idempotencyHeader, "k")
+
+	if cl, id, err := c.claim(httptest.NewRequest("POST", "/", nil), "trigger", "a"); cl != nil || id != 0 || err != nil {
+		t.Errorf("request without a key: %v %d %v", cl, id, err)
+	}
+
+	cl, _, err := c.claim(req, "trigger", "a")
+	if err != nil || cl == nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if _, _, err := c.claim(req, "trigger", "a"); !errors.Is(err, errIdempotencyInProgress) {
+		t.Errorf("concurrent retry: %v", err)
+	}
+	if cl, _, err := c.claim(req, "chat", "a"); err != nil || cl == nil {
+		t.Errorf("same key on another endpoint: %v", err)
+	}
+	cl.complete(7)
+	cl.release() // no-op after complete
+	if _, id, err := c.claim(req, "trigger", "a"); id != 7 || err != nil {
+		t.Errorf("retry: %d %v", id, err)
+	}
+
+	clock = clock.Add(idempotencyWindow + time.Second)
+	if cl, id, err := c.claim(req, "trigger", "a"); cl == nil || id != 0 || err != nil {
+		t.Errorf("after the window: %v %d %v", cl, id, err)
+	}
+}
+
+func TestChatIdempotencyKey(t *testing.T) {
+	trigger := &mockTrigger{nextID: 3}
+	e := newTestEnvWithTrigger(t, trigger)
+	trigger.onTrigger = func(id int64) {
+		time.Sleep(10 * time.Millisecond)
+		e.hub.Publish(int(id), hub.Raw, `{"type":"assistant","message":{"content":[{"type":"text","text":"Restarted jellyfin."}]}}`)
+		e.hub.Close(int(id))
+	}
+	t.Setenv("CLAUDEOPS_CHAT_API_KEY", "valid-key")
+
+	chat := func() *httptest.ResponseRecorder {
+		body := `{"model":"claude-ops","messages":[{"role":"user","content":"restart jellyfin"}]}`
+		req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid-key")
+		req.Header.Set(idempotencyHeader, "retry-me")
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		return w
+	}
+	if w := chat(); w.Code != http.StatusOK {
+		t.Fatalf("first request: %d %s", w.Code, w.Body.String())
+	}
+
+	trigger.nextErr = errors.New("session already running")
+	trigger.lastPrompt = ""
+	w := chat()
+	var resp ChatCompletion
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if trigger.lastPrompt != "" {
+		t.Error("retry triggered another session")
+	}
+	if w.Header().Get(idempotencyReplayedHeader) != "true" || len(resp.Choices) != 1 || resp.Choices[0].Message.Content != "Restarted jellyfin." {
+		t.Errorf("retry did not follow the original session: %v %+v", w.Header(), resp)
+	}
+}
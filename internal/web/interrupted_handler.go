@@ -0,0 +1,43 @@
+package web
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// interruptedChain returns the escalation chain the last shutdown cut
+// short, or nil if there is none.
+func (s *Server) interruptedChain() *session.InterruptedChain {
+	c, err := session.ReadInterruptedChain(s.db)
+	if err != nil {
+		log.Printf("interruptedChain: %v", err)
+		return nil
+	}
+	return c
+}
+
+// handleInterruptedChain resumes, reruns, or discards the escalation chain
+// the last shutdown interrupted, then returns to the dashboard.
+func (s *Server) handleInterruptedChain(w http.ResponseWriter, r *http.Request) {
+	var err error
+	switch r.PathValue("action") {
+	case "resume", "rerun":
+		if s.chainResume == nil {
+			http.Error(w, "resuming chains is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		err = s.chainResume(r.PathValue("action") == "rerun")
+	case "discard":
+		err = session.ClearInterruptedChain(s.db)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func TestInterruptedChainBanner(t *testing.T) {
+	e := newTestEnv(t)
+	if body := getPage(e, "/").Body.String(); strings.Contains(body, "/chains/interrupted/resume") {
+		t.Fatal("expected no banner without an interrupted chain")
+	}
+
+	if err := e.srv.db.SetConfig(session.InterruptedChainKey,
+		`{"session_id":7,"interrupted_at":"2026-01-02T03:04:05Z","origin":{"tier":1,"trigger":"scheduled"},"resume":{"tier":2,"trigger":"escalation","parent_id":7}}`); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+	body := getPage(e, "/").Body.String()
+	for _, want := range []string{"interrupted an escalation chain at tier 2", `href="/sessions/7"`, "Resume at tier 2", "Rerun from tier 1"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in dashboard", want)
+		}
+	}
+
+	var resumed []bool
+	e.srv.chainResume = func(rerun bool) error {
+		resumed = append(resumed, rerun)
+		return nil
+	}
+	if w := postForm(e, "/chains/interrupted/rerun", nil); w.Code != http.StatusSeeOther {
+		t.Fatalf("rerun: status %d: %s", w.Code, w.Body.String())
+	}
+	if len(resumed) != 1 || !resumed[0] {
+		t.Errorf("expected one rerun, got %v", resumed)
+	}
+
+	if w := postForm(e, "/chains/interrupted/discard", nil); w.Code != http.StatusSeeOther {
+		t.Fatalf("discard: status %d", w.Code)
+	}
+	if c, err := session.ReadInterruptedChain(e.srv.db); err != nil || c != nil {
+		t.Errorf("expected record cleared, got %+v %v", c, err)
+	}
+	if w := postForm(e, "/chains/interrupted/bogus", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown action: status %d, want 404", w.Code)
+	}
+}
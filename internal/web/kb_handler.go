@@ -0,0 +1,175 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// registerKBRoutes wires the knowledge base pages and API onto the server mux.
+func (s *Server) registerKBRoutes() {
+	s.mux.HandleFunc("GET /kb", s.handleKB)
+	s.mux.HandleFunc("GET /kb/{service}", s.handleKBArticle)
+	s.mux.HandleFunc("GET /api/v1/kb", s.handleAPIListKBArticles)
+	s.mux.HandleFunc("GET /api/v1/kb/{service}", s.handleAPIGetKBArticle)
+}
+
+// KBArticleView is a template-friendly representation of a db.KBArticle.
+type KBArticleView struct {
+	Service     string
+	Version     int
+	Content     string
+	MemoryCount int
+	Model       string
+	CreatedAt   time.Time
+}
+
+// ToKBArticleView converts a db.KBArticle to a KBArticleView.
+func ToKBArticleView(a db.KBArticle) KBArticleView {
+	v := KBArticleView{
+		Service:     a.Service,
+		Version:     a.Version,
+		Content:     a.Content,
+		MemoryCount: a.MemoryCount,
+		Model:       a.Model,
+	}
+	if t, err := time.Parse(timeFormat, a.CreatedAt); err == nil {
+		v.CreatedAt = t
+	}
+	return v
+}
+
+// APIKBArticle is the JSON representation of a runbook article version.
+type APIKBArticle struct {
+	Service     string `json:"service"`
+	Version     int    `json:"version"`
+	Content     string `json:"content,omitempty"`
+	MemoryCount int    `json:"memory_count"`
+	Model       string `json:"model"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// APIKBArticlesResponse wraps the article list for GET /api/v1/kb.
+type APIKBArticlesResponse struct {
+	Articles []APIKBArticle `json:"articles"`
+}
+
+// kbPageData is the template data for kb.html. Article is nil on the index.
+type kbPageData struct {
+	Articles []KBArticleView
+	Article  *KBArticleView
+	Versions []KBArticleView
+	Enabled  bool
+}
+
+func toAPIKBArticle(a db.KBArticle, withContent bool) APIKBArticle {
+	out := APIKBArticle{
+		Service:     a.Service,
+		Version:     a.Version,
+		MemoryCount: a.MemoryCount,
+		Model:       a.Model,
+		CreatedAt:   a.CreatedAt,
+	}
+	if withContent {
+		out.Content = a.Content
+	}
+	return out
+}
+
+// handleKB renders the knowledge base index: the latest article per service.
+func (s *Server) handleKB(w http.ResponseWriter, r *http.Request) {
+	articles, err := s.db.ListKBArticles()
+	if err != nil {
+		log.Printf("handleKB: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	views := make([]KBArticleView, len(articles))
+	for i, a := range articles {
+		views[i] = ToKBArticleView(a)
+	}
+
+	data := kbPageData{
+		Articles: views,
+		Enabled:  s.cfg.KBInterval > 0,
+	}
+	s.render(w, r, "kb.html", data)
+}
+
+// handleKBArticle renders one service's runbook article. ?version=N selects
+// an earlier version; the default is the latest.
+func (s *Server) handleKBArticle(w http.ResponseWriter, r *http.Request) {
+	service := r.PathValue("service")
+	version, _ := strconv.Atoi(r.URL.Query().Get("version"))
+
+	article, err := s.db.GetKBArticle(service, version)
+	if err != nil {
+		log.Printf("handleKBArticle: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if article == nil {
+		http.Error(w, "article not found", http.StatusNotFound)
+		return
+	}
+	versions, err := s.db.ListKBArticleVersions(service)
+	if err != nil {
+		log.Printf("handleKBArticle: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	view := ToKBArticleView(*article)
+	data := kbPageData{
+		Article: &view,
+		Enabled: s.cfg.KBInterval > 0,
+	}
+	for _, v := range versions {
+		data.Versions = append(data.Versions, ToKBArticleView(v))
+	}
+	s.render(w, r, "kb.html", data)
+}
+
+// handleAPIListKBArticles returns the latest article version per service,
+// without content.
+func (s *Server) handleAPIListKBArticles(w http.ResponseWriter, r *http.Request) {
+	articles, err := s.db.ListKBArticles()
+	if err != nil {
+		log.Printf("handleAPIListKBArticles: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	out := make([]APIKBArticle, len(articles))
+	for i, a := range articles {
+		out[i] = toAPIKBArticle(a, false)
+	}
+	writeJSON(w, http.StatusOK, APIKBArticlesResponse{Articles: out})
+}
+
+// handleAPIGetKBArticle returns a service's runbook article (latest, or
+// ?version=N) including its markdown content.
+func (s *Server) handleAPIGetKBArticle(w http.ResponseWriter, r *http.Request) {
+	version := 0
+	if v := r.URL.Query().Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid version")
+			return
+		}
+		version = n
+	}
+	article, err := s.db.GetKBArticle(r.PathValue("service"), version)
+	if err != nil {
+		log.Printf("handleAPIGetKBArticle: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if article == nil {
+		writeError(w, http.StatusNotFound, "article not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, toAPIKBArticle(*article, true))
+}
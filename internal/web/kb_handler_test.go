@@ -0,0 +1,95 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func insertKBArticle(t *testing.T, e *testEnv, service, content string) {
+	t.Helper()
+	if _, err := e.srv.db.InsertKBArticle(&db.KBArticle{
+		Service: service, Content: content, MemoryCount: 2, SourceHash: content,
+		Model: "haiku", CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		t.Fatalf("InsertKBArticle: %v", err)
+	}
+}
+
+func TestKBPages(t *testing.T) {
+	e := newTestEnv(t)
+
+	req := httptest.NewRequest("GET", "/kb", nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "No runbook articles yet") {
+		t.Fatalf("expected empty knowledge base, got %d: %s", w.Code, w.Body.String())
+	}
+
+	insertKBArticle(t, e, "jellyfin", "# jellyfin\n\n## Timing\n\nTakes 60s to start.\n")
+	insertKBArticle(t, e, "jellyfin", "# jellyfin\n\n## Timing\n\nTakes 90s to start.\n")
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/kb", nil))
+	if !strings.Contains(w.Body.String(), `href="/kb/jellyfin"`) {
+		t.Errorf("expected article link on index:\n%s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/kb/jellyfin", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "<h2>Timing</h2>") || !strings.Contains(body, "Takes 90s") {
+		t.Fatalf("expected latest article rendered, got %d:\n%s", w.Code, body)
+	}
+	if !strings.Contains(body, "?version=1") {
+		t.Errorf("expected link to previous version:\n%s", body)
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/kb/jellyfin?version=1", nil))
+	if !strings.Contains(w.Body.String(), "Takes 60s") {
+		t.Errorf("expected version 1 content:\n%s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/kb/plex", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown service, got %d", w.Code)
+	}
+}
+
+func TestAPIKB(t *testing.T) {
+	e := newTestEnv(t)
+	insertKBArticle(t, e, "caddy", "# caddy\n")
+
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/kb", nil))
+	var list APIKBArticlesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Articles) != 1 || list.Articles[0].Service != "caddy" || list.Articles[0].Content != "" {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/kb/caddy", nil))
+	var a APIKBArticle
+	if err := json.Unmarshal(w.Body.Bytes(), &a); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if a.Version != 1 || a.Content != "# caddy\n" {
+		t.Fatalf("unexpected article %+v", a)
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/kb/caddy?version=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid version, got %d", w.Code)
+	}
+}
@@ -0,0 +1,102 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// listenFDsStart is the first file descriptor systemd passes to an
+// activated service (SD_LISTEN_FDS_START).
+var listenFDsStart = 3
+
+// listen opens the dashboard's listener: the socket systemd passed through
+// socket activation, else the Unix socket at CLAUDEOPS_DASHBOARD_SOCKET,
+// else the TCP port. It also returns the address, for the log.
+func (s *Server) listen() (net.Listener, string, error) {
+	ln, err := systemdListener()
+	if err != nil {
+		return nil, "", fmt.Errorf("systemd socket: %w", err)
+	}
+	if ln != nil {
+		return ln, fmt.Sprintf("%s (systemd socket)", ln.Addr()), nil
+	}
+	if path := s.cfg.DashboardSocket; path != "" {
+		ln, err := listenUnix(path)
+		if err != nil {
+			return nil, "", err
+		}
+		return ln, path, nil
+	}
+	ln, err = net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return nil, "", err
+	}
+	return ln, s.server.Addr, nil
+}
+
+// handleAgent registers an API route the agent calls back into on both the
+// dashboard and the loopback-only agent listener.
+func (s *Server) handleAgent(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+	s.agentMux.HandleFunc(pattern, handler)
+}
+
+// SystemdActivated reports whether systemd passed this process a socket to
+// serve the dashboard on.
+func SystemdActivated() bool {
+	pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	n, _ := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	return pid == os.Getpid() && n >= 1
+}
+
+// systemdListener returns the first socket passed by systemd socket
+// activation, or nil when the process was not socket-activated. The
+// LISTEN_* variables are cleared so the agent processes do not inherit
+// them.
+func systemdListener() (net.Listener, error) {
+	if !SystemdActivated() {
+		return nil, nil
+	}
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	// FileListener duplicates the descriptor, so the original is closed.
+	f := os.NewFile(uintptr(listenFDsStart), "LISTEN_FD_"+strconv.Itoa(listenFDsStart))
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// listenUnix listens on the Unix socket at path, replacing a stale socket
+// left by an unclean exit. The socket is readable and writable by the
+// owner and group only, so access is granted through group membership
+// (e.g. the reverse proxy's user).
+func listenUnix(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("dashboard socket %s exists and is not a socket", path)
+		}
+		if c, err := net.Dial("unix", path); err == nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("dashboard socket %s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale dashboard socket: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o660); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// socketDir returns a short temporary directory: Unix socket paths are
+// limited to about 100 bytes, which t.TempDir() can exceed.
+func socketDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "claudeops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func TestStartOnUnixSocket(t *testing.T) {
+	e := newTestEnv(t)
+	path := filepath.Join(socketDir(t), "dashboard.sock")
+	e.srv.cfg.DashboardSocket = path
+
+	// A socket left behind by an unclean exit is replaced.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- e.srv.Start() }()
+	t.Cleanup(func() {
+		_ = e.srv.Shutdown(context.Background())
+		<-done
+	})
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	var resp *http.Response
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if resp, err = client.Get("http://localhost/api/v1/live"); err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("GET over the socket: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET over the socket: %d %s", resp.StatusCode, body)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o660 {
+		t.Errorf("socket mode: %v %v", fi.Mode(), err)
+	}
+
+	// A socket that is in use is left alone.
+	if _, err := listenUnix(path); err == nil {
+		t.Error("listened on a socket in use by the running server")
+	}
+}
+
+func TestListenUnixRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(socketDir(t), "dashboard.sock")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenUnix(path); err == nil {
+		t.Fatal("replaced a regular file with the socket")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "data" {
+		t.Error("regular file was modified")
+	}
+}
+
+func TestAgentListenerServesOnlyAgentRoutes(t *testing.T) {
+	e := newTestEnv(t)
+	for _, tc := range []struct {
+		method, path string
+		agent        bool
+	}{
+		{"GET", "/selftest/canary", true},
+		{"POST", "/api/v1/selftest/notify", true},
+		{"GET", "/api/v1/hypervisor/guests", true},
+		{"POST", "/api/v1/hypervisor/guests/100/reboot", true},
+		{"GET", "/api/v1/config", false},
+		{"POST", "/api/v1/sessions/trigger", false},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.path, nil)
+		if _, pattern := e.srv.agentMux.Handler(req); (pattern != "") != tc.agent {
+			t.Errorf("%s %s on the agent listener: pattern %q, want served=%v", tc.method, tc.path, pattern, tc.agent)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//go:build unix
+
+package web
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestListenSystemdSocket(t *testing.T) {
+	passed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer passed.Close()
+	f, err := passed.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	// listen takes ownership of the descriptor, as it does of systemd's.
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := listenFDsStart
+	listenFDsStart = fd
+	t.Cleanup(func() { listenFDsStart = orig })
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+
+	e := newTestEnv(t)
+	e.srv.cfg.DashboardSocket = filepath.Join(socketDir(t), "unused.sock")
+	ln, addr, err := e.srv.listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if ln.Addr().String() != passed.Addr().String() {
+		t.Errorf("listening on %s, want the passed socket %s (%s)", ln.Addr(), passed.Addr(), addr)
+	}
+	if os.Getenv("LISTEN_FDS") != "" || os.Getenv("LISTEN_PID") != "" {
+		t.Error("LISTEN_* variables left for child processes")
+	}
+	if SystemdActivated() {
+		t.Error("still reported as socket-activated")
+	}
+
+	// Without activation the configured Unix socket is used.
+	ln2, addr, err := e.srv.listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln2.Close()
+	if addr != e.srv.cfg.DashboardSocket {
+		t.Errorf("listening on %s, want the configured socket", addr)
+	}
+}
@@ -0,0 +1,130 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// APILiveIncident is the JSON payload for GET /api/v1/live. Incident is
+// null when no running session is an incident.
+type APILiveIncident struct {
+	Incident *APIIncident `json:"incident"`
+}
+
+// APIIncident is a running session that escalated from a lower tier or
+// reported a critical event.
+type APIIncident struct {
+	SessionID      int64    `json:"session_id"`
+	Tier           int      `json:"tier"`
+	Trigger        string   `json:"trigger"`
+	Services       []string `json:"services"`
+	StartedAt      string   `json:"started_at"`
+	ElapsedSeconds int      `json:"elapsed_seconds"`
+	CostUSD        float64  `json:"cost_usd"`
+	Critical       bool     `json:"critical"`
+	// Message is the banner text, e.g. "Tier 3 remediation in progress for
+	// postgres — started 12m ago, est. cost so far $1.20".
+	Message string `json:"message"`
+}
+
+// liveIncident returns the running session if it is an incident.
+func (s *Server) liveIncident() *session.LiveSession {
+	if s.live == nil {
+		return nil
+	}
+	if l := s.live(); l != nil && l.Incident() {
+		return l
+	}
+	return nil
+}
+
+// runningSession returns the running session's progress if it is session
+// id.
+func (s *Server) runningSession(id int64) *session.LiveSession {
+	if s.live == nil {
+		return nil
+	}
+	if l := s.live(); l != nil && l.SessionID == id {
+		return l
+	}
+	return nil
+}
+
+// withLiveCost fills in the running cost of a session still in progress,
+// which is only recorded once it finishes.
+func (s *Server) withLiveCost(sess *APISession) {
+	if sess.Status != "running" {
+		return
+	}
+	if l := s.runningSession(sess.ID); l != nil {
+		cost := l.CostUSD
+		sess.CostUSD = &cost
+		sess.CostLive = true
+	}
+}
+
+// tierWork names what a tier does, for the banner.
+func tierWork(tier int) string {
+	switch tier {
+	case 1:
+		return "check"
+	case 2:
+		return "investigation"
+	case 3:
+		return "remediation"
+	}
+	return "session"
+}
+
+func toAPIIncident(l *session.LiveSession, now time.Time) *APIIncident {
+	elapsed := now.Sub(l.StartedAt)
+	services := l.Services
+	if services == nil {
+		services = []string{}
+	}
+	msg := fmt.Sprintf("Tier %d %s in progress", l.Tier, tierWork(l.Tier))
+	if len(services) > 0 {
+		msg += " for " + strings.Join(services, ", ")
+	}
+	msg += fmt.Sprintf(" — started %s, est. cost so far $%.2f", shortAgo(elapsed), l.CostUSD)
+	if l.Critical {
+		msg += " (critical event reported)"
+	}
+	return &APIIncident{
+		SessionID:      l.SessionID,
+		Tier:           l.Tier,
+		Trigger:        l.Trigger,
+		Services:       services,
+		StartedAt:      l.StartedAt.UTC().Format(time.RFC3339),
+		ElapsedSeconds: int(elapsed / time.Second),
+		CostUSD:        l.CostUSD,
+		Critical:       l.Critical,
+		Message:        msg,
+	}
+}
+
+// shortAgo formats an elapsed time for the banner: "just now", "12m ago",
+// "1h 5m ago".
+func shortAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%dh %dm ago", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// handleAPILive reports the running incident for the dashboard's live
+// incident banner. It is cheap enough to poll every few seconds.
+func (s *Server) handleAPILive(w http.ResponseWriter, r *http.Request) {
+	var resp APILiveIncident
+	if l := s.liveIncident(); l != nil {
+		resp.Incident = toAPIIncident(l, time.Now())
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
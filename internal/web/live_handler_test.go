@@ -0,0 +1,92 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func TestAPILive(t *testing.T) {
+	e := newTestEnv(t)
+
+	decode := func() APILiveIncident {
+		t.Helper()
+		var resp APILiveIncident
+		if err := json.NewDecoder(getPage(e, "/api/v1/live").Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if resp := decode(); resp.Incident != nil {
+		t.Errorf("without a manager: %+v", resp.Incident)
+	}
+
+	live := &session.LiveSession{SessionID: 42, Tier: 2, Trigger: "scheduled", StartedAt: time.Now()}
+	e.srv.live = func() *session.LiveSession { return live }
+	if resp := decode(); resp.Incident != nil {
+		t.Errorf("routine scheduled session reported as an incident: %+v", resp.Incident)
+	}
+
+	live = &session.LiveSession{
+		SessionID: 42,
+		Tier:      3,
+		Trigger:   "escalation",
+		Services:  []string{"postgres"},
+		StartedAt: time.Now().Add(-12*time.Minute - 30*time.Second),
+		CostUSD:   1.2,
+	}
+	inc := decode().Incident
+	if inc == nil {
+		t.Fatal("escalated session not reported")
+	}
+	if want := "Tier 3 remediation in progress for postgres — started 12m ago, est. cost so far $1.20"; inc.Message != want {
+		t.Errorf("message = %q, want %q", inc.Message, want)
+	}
+	if inc.SessionID != 42 || inc.ElapsedSeconds < 750 || inc.CostUSD != 1.2 {
+		t.Errorf("incident = %+v", inc)
+	}
+
+	live = &session.LiveSession{SessionID: 43, Tier: 1, Trigger: "scheduled", StartedAt: time.Now().Add(-75 * time.Minute), Critical: true}
+	inc = decode().Incident
+	if inc == nil || !strings.HasSuffix(inc.Message, "started 1h 15m ago, est. cost so far $0.00 (critical event reported)") {
+		t.Errorf("critical session: %+v", inc)
+	}
+
+	if body := getPage(e, "/").Body.String(); !strings.Contains(body, `id="live-incident"`) {
+		t.Error("layout has no live incident banner")
+	}
+}
+
+func TestLiveCostOnRunningSession(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "running")
+	live := &session.LiveSession{SessionID: id, Tier: 1, Trigger: "scheduled", StartedAt: time.Now(), CostUSD: 0.4321}
+	e.srv.live = func() *session.LiveSession { return live }
+
+	var sess APISession
+	if err := json.NewDecoder(getPage(e, fmt.Sprintf("/api/v1/sessions/%d", id)).Body).Decode(&sess); err != nil {
+		t.Fatal(err)
+	}
+	if !sess.CostLive || sess.CostUSD == nil || *sess.CostUSD != 0.4321 {
+		t.Errorf("running session cost = %v (live %v)", sess.CostUSD, sess.CostLive)
+	}
+	var list APISessionsResponse
+	_ = json.NewDecoder(getPage(e, "/api/v1/sessions").Body).Decode(&list)
+	if len(list.Sessions) != 1 || !list.Sessions[0].CostLive {
+		t.Errorf("sessions list = %+v", list.Sessions)
+	}
+	if body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String(); !strings.Contains(body, `id="live-cost"`) || !strings.Contains(body, "~$0.4321 so far") {
+		t.Error("session page does not show the running cost")
+	}
+
+	// Another session's progress is not this session's cost.
+	live = &session.LiveSession{SessionID: id + 1, StartedAt: time.Now(), CostUSD: 9}
+	_ = json.NewDecoder(getPage(e, fmt.Sprintf("/api/v1/sessions/%d", id)).Body).Decode(&sess)
+	if sess.CostLive {
+		t.Errorf("session reported another session's cost: %+v", sess)
+	}
+}
@@ -0,0 +1,161 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func (s *Server) registerMemoryBulkRoutes() {
+	s.mux.HandleFunc("POST /api/v1/memories/bulk", s.handleAPIBulkMemories)
+}
+
+// APIMemoryBulkRequest is the body of POST /api/v1/memories/bulk.
+type APIMemoryBulkRequest struct {
+	// Action is "deactivate", "delete", or "retag".
+	Action string             `json:"action"`
+	Filter APIMemoryFilter    `json:"filter"`
+	Set    *APIMemoryRetagSet `json:"set,omitempty"`
+	// DryRun lists the memories the action would change without changing
+	// them.
+	DryRun bool `json:"dry_run"`
+}
+
+// APIMemoryFilter selects memories. At least one field must be set, so a
+// bulk action cannot reach every memory by accident.
+type APIMemoryFilter struct {
+	Service       *string  `json:"service,omitempty"`
+	Category      *string  `json:"category,omitempty"`
+	Active        *bool    `json:"active,omitempty"`
+	MinConfidence *float64 `json:"min_confidence,omitempty"`
+	MaxConfidence *float64 `json:"max_confidence,omitempty"`
+	// OlderThanDays and NewerThanDays bound the memory's age.
+	OlderThanDays *int `json:"older_than_days,omitempty"`
+	NewerThanDays *int `json:"newer_than_days,omitempty"`
+}
+
+// APIMemoryRetagSet is what a retag changes. An empty service makes the
+// memories general.
+type APIMemoryRetagSet struct {
+	Category *string `json:"category,omitempty"`
+	Service  *string `json:"service,omitempty"`
+}
+
+// APIMemoryBulkResponse reports a bulk action. Memories lists what a dry
+// run would change.
+type APIMemoryBulkResponse struct {
+	Action   string      `json:"action"`
+	DryRun   bool        `json:"dry_run"`
+	Affected int64       `json:"affected"`
+	Memories []APIMemory `json:"memories,omitempty"`
+}
+
+// toMemoryFilter validates f and converts it relative to now.
+func (f APIMemoryFilter) toMemoryFilter(now time.Time) (db.MemoryFilter, string) {
+	out := db.MemoryFilter{
+		Service:       f.Service,
+		Category:      f.Category,
+		Active:        f.Active,
+		MinConfidence: f.MinConfidence,
+		MaxConfidence: f.MaxConfidence,
+	}
+	for _, c := range []*float64{f.MinConfidence, f.MaxConfidence} {
+		if c != nil && (*c < 0 || *c > 1) {
+			return out, "confidence bounds must be between 0 and 1"
+		}
+	}
+	if f.MinConfidence != nil && f.MaxConfidence != nil && *f.MinConfidence > *f.MaxConfidence {
+		return out, "min_confidence must not exceed max_confidence"
+	}
+	if d := f.OlderThanDays; d != nil {
+		if *d < 0 {
+			return out, "older_than_days must be non-negative"
+		}
+		out.CreatedBefore = now.AddDate(0, 0, -*d).Format(time.RFC3339)
+	}
+	if d := f.NewerThanDays; d != nil {
+		if *d < 0 {
+			return out, "newer_than_days must be non-negative"
+		}
+		out.CreatedAfter = now.AddDate(0, 0, -*d).Format(time.RFC3339)
+	}
+	if out.Empty() {
+		return out, "filter must set at least one of service, category, active, min_confidence, max_confidence, older_than_days, or newer_than_days"
+	}
+	return out, ""
+}
+
+// handleAPIBulkMemories deactivates, deletes, or retags every memory
+// matching a filter, or with dry_run previews which ones it would.
+func (s *Server) handleAPIBulkMemories(w http.ResponseWriter, r *http.Request) {
+	if !requireJSON(w, r) {
+		return
+	}
+	var req APIMemoryBulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	filter, problem := req.Filter.toMemoryFilter(time.Now().UTC())
+	if problem != "" {
+		writeError(w, http.StatusBadRequest, problem)
+		return
+	}
+
+	var apply func() (int64, error)
+	switch req.Action {
+	case "deactivate":
+		apply = func() (int64, error) { return s.db.DeactivateMemories(filter) }
+		// Only active memories are changed, so only they are previewed.
+		if filter.Active == nil {
+			active := true
+			filter.Active = &active
+		}
+	case "delete":
+		apply = func() (int64, error) { return s.db.DeleteMemories(filter) }
+	case "retag":
+		if req.Set == nil || (req.Set.Category == nil && req.Set.Service == nil) {
+			writeError(w, http.StatusBadRequest, "retag needs set.category or set.service")
+			return
+		}
+		if req.Set.Category != nil && strings.TrimSpace(*req.Set.Category) == "" {
+			writeError(w, http.StatusBadRequest, "set.category must not be empty")
+			return
+		}
+		apply = func() (int64, error) { return s.db.RetagMemories(filter, req.Set.Category, req.Set.Service) }
+	default:
+		writeError(w, http.StatusBadRequest, "action must be deactivate, delete, or retag")
+		return
+	}
+
+	resp := APIMemoryBulkResponse{Action: req.Action, DryRun: req.DryRun}
+	if req.DryRun {
+		memories, err := s.db.FindMemories(filter)
+		if err != nil {
+			log.Printf("handleAPIBulkMemories: %v", err)
+			writeError(w, http.StatusInternalServerError, "database error")
+			return
+		}
+		resp.Affected = int64(len(memories))
+		resp.Memories = make([]APIMemory, 0, len(memories))
+		for _, m := range memories {
+			resp.Memories = append(resp.Memories, toAPIMemory(m))
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	n, err := apply()
+	if err != nil {
+		log.Printf("handleAPIBulkMemories: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	log.Printf("memories: bulk %s changed %d", req.Action, n)
+	resp.Affected = n
+	writeJSON(w, http.StatusOK, resp)
+}
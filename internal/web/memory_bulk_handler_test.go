@@ -0,0 +1,76 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestAPIBulkMemories(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC()
+	insert := func(service, category string, confidence float64, age time.Duration) int64 {
+		created := now.Add(-age).Format(time.RFC3339)
+		id, err := e.srv.db.InsertMemory(&db.Memory{Service: &service, Category: category, Observation: "obs", Confidence: confidence, Active: true, CreatedAt: created, UpdatedAt: created})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	noisy := insert("jellyfin", "timing", 0.4, 2*24*time.Hour)
+	insert("jellyfin", "timing", 0.4, 20*24*time.Hour)
+	insert("jellyfin", "timing", 0.9, time.Hour)
+
+	bulk := func(body string) (int, APIMemoryBulkResponse) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/v1/memories/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		var resp APIMemoryBulkResponse
+		_ = json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp
+	}
+
+	filter := `"filter":{"service":"jellyfin","max_confidence":0.5,"newer_than_days":7}`
+	code, resp := bulk(`{"action":"delete","dry_run":true,` + filter + `}`)
+	if code != http.StatusOK || resp.Affected != 1 || len(resp.Memories) != 1 || resp.Memories[0].ID != noisy {
+		t.Fatalf("dry run: %d %+v", code, resp)
+	}
+	if m, _ := e.srv.db.GetMemory(noisy); m == nil {
+		t.Fatal("dry run deleted the memory")
+	}
+
+	if code, resp = bulk(`{"action":"retag","set":{"category":"flaky"},` + filter + `}`); code != http.StatusOK || resp.Affected != 1 {
+		t.Fatalf("retag: %d %+v", code, resp)
+	}
+	if code, resp = bulk(`{"action":"deactivate","filter":{"category":"flaky"}}`); code != http.StatusOK || resp.Affected != 1 || resp.Memories != nil {
+		t.Fatalf("deactivate: %d %+v", code, resp)
+	}
+	if m, _ := e.srv.db.GetMemory(noisy); m.Category != "flaky" || m.Active {
+		t.Errorf("memory after retag and deactivate: %+v", m)
+	}
+	if _, resp = bulk(`{"action":"deactivate","dry_run":true,"filter":{"category":"flaky"}}`); resp.Affected != 0 {
+		t.Errorf("deactivate preview lists inactive memories: %+v", resp)
+	}
+	if code, resp = bulk(`{"action":"delete","filter":{"older_than_days":7}}`); code != http.StatusOK || resp.Affected != 1 {
+		t.Fatalf("delete: %d %+v", code, resp)
+	}
+
+	for name, body := range map[string]string{
+		"no filter":        `{"action":"delete","filter":{}}`,
+		"unknown action":   `{"action":"purge","filter":{"category":"flaky"}}`,
+		"retag to nothing": `{"action":"retag","filter":{"category":"flaky"}}`,
+		"inverted range":   `{"action":"delete","filter":{"min_confidence":0.8,"max_confidence":0.2}}`,
+		"negative age":     `{"action":"delete","filter":{"older_than_days":-1}}`,
+	} {
+		if code, _ := bulk(body); code != http.StatusBadRequest {
+			t.Errorf("%s: %d", name, code)
+		}
+	}
+}
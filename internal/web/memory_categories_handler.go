@@ -0,0 +1,297 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func (s *Server) registerMemoryCategoryRoutes() {
+	s.mux.HandleFunc("GET /memories/categories", s.handleMemoryCategories)
+	s.mux.HandleFunc("POST /memories/categories", s.handleMemoryCategorySave)
+	s.mux.HandleFunc("POST /memories/categories/merge", s.handleMemoryCategoriesMerge)
+	s.mux.HandleFunc("POST /memories/categories/{name}/rename", s.handleMemoryCategoryRename)
+	s.mux.HandleFunc("POST /memories/categories/{name}/delete", s.handleMemoryCategoryDelete)
+
+	s.mux.HandleFunc("GET /api/v1/memories/categories", s.handleAPIListMemoryCategories)
+	s.mux.HandleFunc("POST /api/v1/memories/categories/merge", s.handleAPIMergeMemoryCategories)
+	s.mux.HandleFunc("PUT /api/v1/memories/categories/{name}", s.handleAPIPutMemoryCategory)
+	s.mux.HandleFunc("DELETE /api/v1/memories/categories/{name}", s.handleAPIDeleteMemoryCategory)
+	s.mux.HandleFunc("POST /api/v1/memories/categories/{name}/rename", s.handleAPIRenameMemoryCategory)
+}
+
+// memoryCategoryName matches the categories a [MEMORY:category] marker can
+// carry, so a renamed or merged category stays one the agent can write.
+var memoryCategoryName = regexp.MustCompile(`^[a-z]+$`)
+
+// validMemoryCategory returns a problem with name, or "".
+func validMemoryCategory(name string) string {
+	if !memoryCategoryName.MatchString(name) {
+		return fmt.Sprintf("%q is not a valid category: use lowercase letters only", name)
+	}
+	return ""
+}
+
+// memoryCategoriesPageData is the template data for memory_categories.html.
+type memoryCategoriesPageData struct {
+	Categories []db.MemoryCategory
+	Done       string
+	Error      string
+}
+
+// handleMemoryCategories lists memory categories with their statistics.
+func (s *Server) handleMemoryCategories(w http.ResponseWriter, r *http.Request) {
+	s.renderMemoryCategories(w, r, "", "")
+}
+
+// handleMemoryCategorySave registers a category or updates its description.
+func (s *Server) handleMemoryCategorySave(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(strings.TrimSpace(r.FormValue("name")))
+	if problem := validMemoryCategory(name); problem != "" {
+		s.renderMemoryCategories(w, r, "", problem)
+		return
+	}
+	if err := s.db.UpsertMemoryCategory(name, strings.TrimSpace(r.FormValue("description"))); err != nil {
+		log.Printf("handleMemoryCategorySave: %v", err)
+		s.renderMemoryCategories(w, r, "", fmt.Sprintf("Saving %s failed: %v", name, err))
+		return
+	}
+	s.renderMemoryCategories(w, r, fmt.Sprintf("Saved %s.", name), "")
+}
+
+// handleMemoryCategoryRename renames a category on every memory filed
+// under it.
+func (s *Server) handleMemoryCategoryRename(w http.ResponseWriter, r *http.Request) {
+	from := r.PathValue("name")
+	to := strings.ToLower(strings.TrimSpace(r.FormValue("to")))
+	if problem := validMemoryCategory(to); problem != "" {
+		s.renderMemoryCategories(w, r, "", problem)
+		return
+	}
+	n, err := s.db.RenameMemoryCategory(from, to)
+	switch {
+	case errors.Is(err, db.ErrMemoryCategoryExists):
+		s.renderMemoryCategories(w, r, "", fmt.Sprintf("%s already exists; merge %s into it instead.", to, from))
+		return
+	case err != nil:
+		log.Printf("handleMemoryCategoryRename: %v", err)
+		s.renderMemoryCategories(w, r, "", fmt.Sprintf("Renaming %s failed: %v", from, err))
+		return
+	}
+	s.renderMemoryCategories(w, r, fmt.Sprintf("Renamed %s to %s, %d memory(ies).", from, to, n), "")
+}
+
+// handleMemoryCategoriesMerge moves the memories of one category into
+// another.
+func (s *Server) handleMemoryCategoriesMerge(w http.ResponseWriter, r *http.Request) {
+	from := strings.TrimSpace(r.FormValue("from"))
+	into := strings.ToLower(strings.TrimSpace(r.FormValue("into")))
+	if problem := validMemoryCategory(into); problem != "" {
+		s.renderMemoryCategories(w, r, "", problem)
+		return
+	}
+	if from == "" || from == into {
+		s.renderMemoryCategories(w, r, "", "Choose two different categories.")
+		return
+	}
+	n, err := s.db.MergeMemoryCategories([]string{from}, into)
+	if err != nil {
+		log.Printf("handleMemoryCategoriesMerge: %v", err)
+		s.renderMemoryCategories(w, r, "", fmt.Sprintf("Merging %s into %s failed: %v", from, into, err))
+		return
+	}
+	s.renderMemoryCategories(w, r, fmt.Sprintf("Merged %s into %s, %d memory(ies).", from, into, n), "")
+}
+
+// handleMemoryCategoryDelete removes an unused category from the registry.
+func (s *Server) handleMemoryCategoryDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	err := s.db.DeleteMemoryCategory(name)
+	switch {
+	case errors.Is(err, db.ErrMemoryCategoryInUse):
+		s.renderMemoryCategories(w, r, "", fmt.Sprintf("%s still has memories; merge it into another category instead.", name))
+		return
+	case err != nil:
+		log.Printf("handleMemoryCategoryDelete: %v", err)
+		s.renderMemoryCategories(w, r, "", fmt.Sprintf("Deleting %s failed: %v", name, err))
+		return
+	}
+	s.renderMemoryCategories(w, r, fmt.Sprintf("Deleted %s.", name), "")
+}
+
+func (s *Server) renderMemoryCategories(w http.ResponseWriter, r *http.Request, done, errMsg string) {
+	categories, err := s.db.ListMemoryCategories()
+	if err != nil {
+		log.Printf("handleMemoryCategories: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	s.render(w, r, "memory_categories.html", memoryCategoriesPageData{
+		Categories: categories,
+		Done:       done,
+		Error:      errMsg,
+	})
+}
+
+// APIMemoryCategory is a memory category with statistics over its
+// undeleted memories.
+type APIMemoryCategory struct {
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	Registered    bool    `json:"registered"`
+	Count         int     `json:"count"`
+	Active        int     `json:"active"`
+	AvgConfidence float64 `json:"avg_confidence"`
+	// LastReinforced is when one of its memories was last reinforced.
+	LastReinforced *string `json:"last_reinforced"`
+}
+
+// APIMemoryCategoriesResponse is the body of GET /api/v1/memories/categories.
+type APIMemoryCategoriesResponse struct {
+	Categories []APIMemoryCategory `json:"categories"`
+}
+
+// APIMemoryCategoryChange reports how many memories a rename or merge moved.
+type APIMemoryCategoryChange struct {
+	Category string `json:"category"`
+	Moved    int64  `json:"moved"`
+}
+
+func toAPIMemoryCategory(c db.MemoryCategory) APIMemoryCategory {
+	return APIMemoryCategory{
+		Name:           c.Name,
+		Description:    c.Description,
+		Registered:     c.Registered,
+		Count:          c.Count,
+		Active:         c.Active,
+		AvgConfidence:  c.AvgConfidence,
+		LastReinforced: c.LastReinforced,
+	}
+}
+
+// handleAPIListMemoryCategories lists memory categories with their
+// statistics.
+func (s *Server) handleAPIListMemoryCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := s.db.ListMemoryCategories()
+	if err != nil {
+		log.Printf("handleAPIListMemoryCategories: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	resp := APIMemoryCategoriesResponse{Categories: make([]APIMemoryCategory, 0, len(categories))}
+	for _, c := range categories {
+		resp.Categories = append(resp.Categories, toAPIMemoryCategory(c))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleAPIPutMemoryCategory registers a category or updates its
+// description.
+func (s *Server) handleAPIPutMemoryCategory(w http.ResponseWriter, r *http.Request) {
+	if !requireJSON(w, r) {
+		return
+	}
+	name := r.PathValue("name")
+	if problem := validMemoryCategory(name); problem != "" {
+		writeError(w, http.StatusBadRequest, problem)
+		return
+	}
+	var req struct {
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if err := s.db.UpsertMemoryCategory(name, strings.TrimSpace(req.Description)); err != nil {
+		log.Printf("handleAPIPutMemoryCategory: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAPIDeleteMemoryCategory removes an unused category from the
+// registry.
+func (s *Server) handleAPIDeleteMemoryCategory(w http.ResponseWriter, r *http.Request) {
+	err := s.db.DeleteMemoryCategory(r.PathValue("name"))
+	switch {
+	case errors.Is(err, db.ErrMemoryCategoryInUse):
+		writeError(w, http.StatusConflict, "category still has memories; merge it into another category")
+		return
+	case err != nil:
+		log.Printf("handleAPIDeleteMemoryCategory: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAPIRenameMemoryCategory renames a category on every memory filed
+// under it.
+func (s *Server) handleAPIRenameMemoryCategory(w http.ResponseWriter, r *http.Request) {
+	if !requireJSON(w, r) {
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if problem := validMemoryCategory(req.Name); problem != "" {
+		writeError(w, http.StatusBadRequest, problem)
+		return
+	}
+	n, err := s.db.RenameMemoryCategory(r.PathValue("name"), req.Name)
+	switch {
+	case errors.Is(err, db.ErrMemoryCategoryExists):
+		writeError(w, http.StatusConflict, "category already exists; merge into it instead")
+		return
+	case err != nil:
+		log.Printf("handleAPIRenameMemoryCategory: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	log.Printf("memories: renamed category %s to %s, %d memories", r.PathValue("name"), req.Name, n)
+	writeJSON(w, http.StatusOK, APIMemoryCategoryChange{Category: req.Name, Moved: n})
+}
+
+// handleAPIMergeMemoryCategories moves the memories of one or more
+// categories into another.
+func (s *Server) handleAPIMergeMemoryCategories(w http.ResponseWriter, r *http.Request) {
+	if !requireJSON(w, r) {
+		return
+	}
+	var req struct {
+		From []string `json:"from"`
+		Into string   `json:"into"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if problem := validMemoryCategory(req.Into); problem != "" {
+		writeError(w, http.StatusBadRequest, problem)
+		return
+	}
+	if len(req.From) == 0 {
+		writeError(w, http.StatusBadRequest, "from must list at least one category")
+		return
+	}
+	n, err := s.db.MergeMemoryCategories(req.From, req.Into)
+	if err != nil {
+		log.Printf("handleAPIMergeMemoryCategories: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	log.Printf("memories: merged categories %s into %s, %d memories", strings.Join(req.From, ", "), req.Into, n)
+	writeJSON(w, http.StatusOK, APIMemoryCategoryChange{Category: req.Into, Moved: n})
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestMemoryCategoriesPage(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := e.srv.db.InsertMemory(&db.Memory{Category: "quirk", Observation: "obs", Confidence: 0.6, Active: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := getPage(e, "/memories/categories").Body.String()
+	for _, want := range []string{"quirk", "(unregistered)", "maintenance", "60%"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+
+	post := func(path, form string) string {
+		t.Helper()
+		req := httptest.NewRequest("POST", path, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s: %d", path, w.Code)
+		}
+		return w.Body.String()
+	}
+	if body := post("/memories/categories/quirk/rename", "to=timing"); !strings.Contains(body, "merge quirk into it instead") {
+		t.Errorf("rename onto existing category not refused")
+	}
+	if body := post("/memories/categories/merge", "from=quirk&into=behavior"); !strings.Contains(body, "Merged quirk into behavior, 1 memory(ies).") {
+		t.Errorf("merge: %s", body)
+	}
+	if body := post("/memories/categories/behavior/delete", ""); !strings.Contains(body, "still has memories") {
+		t.Errorf("deleted a category in use")
+	}
+	if body := post("/memories/categories", "name=Bad-Name"); !strings.Contains(body, "not a valid category") {
+		t.Errorf("invalid name accepted")
+	}
+}
+
+func TestAPIMemoryCategories(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := e.srv.db.InsertMemory(&db.Memory{Category: "flaky", Observation: "obs", Confidence: 0.5, Active: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("PUT", "/api/v1/memories/categories/capacity", `{"description":"Disk and memory limits"}`); w.Code != http.StatusNoContent {
+		t.Fatalf("put: %d %s", w.Code, w.Body.String())
+	}
+	w := do("POST", "/api/v1/memories/categories/flaky/rename", `{"name":"intermittent"}`)
+	var change APIMemoryCategoryChange
+	_ = json.NewDecoder(w.Body).Decode(&change)
+	if w.Code != http.StatusOK || change.Moved != 1 {
+		t.Fatalf("rename: %d %+v", w.Code, change)
+	}
+	if w := do("POST", "/api/v1/memories/categories/intermittent/rename", `{"name":"timing"}`); w.Code != http.StatusConflict {
+		t.Errorf("rename onto existing category: %d", w.Code)
+	}
+	if w := do("POST", "/api/v1/memories/categories/merge", `{"from":["intermittent"],"into":"capacity"}`); w.Code != http.StatusOK {
+		t.Fatalf("merge: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("DELETE", "/api/v1/memories/categories/capacity", ""); w.Code != http.StatusConflict {
+		t.Errorf("delete category in use: %d", w.Code)
+	}
+	if w := do("DELETE", "/api/v1/memories/categories/maintenance", ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete unused category: %d", w.Code)
+	}
+
+	w = do("GET", "/api/v1/memories/categories", "")
+	var list APIMemoryCategoriesResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range list.Categories {
+		names = append(names, c.Name)
+		if c.Name == "capacity" && (c.Count != 1 || c.Description != "Disk and memory limits") {
+			t.Errorf("capacity: %+v", c)
+		}
+	}
+	if got := strings.Join(names, ","); got != "behavior,capacity,dependency,remediation,timing" {
+		t.Errorf("categories: %s", got)
+	}
+}
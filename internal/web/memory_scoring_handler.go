@@ -0,0 +1,76 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func (s *Server) registerMemoryScoringRoutes() {
+	s.mux.HandleFunc("GET /api/v1/memories/scoring", s.handleAPIMemoryScoring)
+	s.mux.HandleFunc("GET /api/v1/memories/{id}/revisions", s.handleAPIMemoryRevisions)
+}
+
+// APIMemoryRevision is one confidence change the scoring model made to a
+// memory, with the parameters it used.
+type APIMemoryRevision struct {
+	ID               int64          `json:"id"`
+	SessionID        *int64         `json:"session_id"`
+	Change           string         `json:"change"`
+	ConfidenceBefore *float64       `json:"confidence_before"`
+	ConfidenceAfter  float64        `json:"confidence_after"`
+	Scoring          db.MemoryScore `json:"scoring"`
+	CreatedAt        string         `json:"created_at"`
+}
+
+// APIMemoryRevisionsResponse is the body of GET /api/v1/memories/{id}/revisions.
+type APIMemoryRevisionsResponse struct {
+	Revisions []APIMemoryRevision `json:"revisions"`
+}
+
+// handleAPIMemoryScoring returns the effective memory scoring parameters.
+func (s *Server) handleAPIMemoryScoring(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, session.NewMemoryScoring(s.cfg))
+}
+
+// handleAPIMemoryRevisions lists a memory's confidence changes, oldest
+// first.
+func (s *Server) handleAPIMemoryRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid memory ID")
+		return
+	}
+	existing, err := s.db.GetMemory(id)
+	if err != nil {
+		log.Printf("handleAPIMemoryRevisions: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if existing == nil {
+		writeError(w, http.StatusNotFound, "memory not found")
+		return
+	}
+	revisions, err := s.db.ListMemoryRevisions(id)
+	if err != nil {
+		log.Printf("handleAPIMemoryRevisions: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	resp := APIMemoryRevisionsResponse{Revisions: make([]APIMemoryRevision, 0, len(revisions))}
+	for _, rev := range revisions {
+		resp.Revisions = append(resp.Revisions, APIMemoryRevision{
+			ID:               rev.ID,
+			SessionID:        rev.SessionID,
+			Change:           rev.Change,
+			ConfidenceBefore: rev.ConfidenceBefore,
+			ConfidenceAfter:  rev.ConfidenceAfter,
+			Scoring:          rev.Score,
+			CreatedAt:        rev.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
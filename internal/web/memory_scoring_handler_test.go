@@ -0,0 +1,53 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func TestAPIMemoryScoring(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.cfg.MemoryReinforce, e.srv.cfg.MemoryContradict, e.srv.cfg.MemoryDecay = 0.1, 0.1, 0.1
+	e.srv.cfg.MemoryScoring = "timing:decay=0.25"
+
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/memories/scoring", nil))
+	var scoring session.MemoryScoring
+	if err := json.NewDecoder(w.Body).Decode(&scoring); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || scoring.Default.Decay != 0.1 || scoring.Categories["timing"].Decay != 0.25 || scoring.InitialConfidence != 0.7 {
+		t.Errorf("scoring: %d %+v", w.Code, scoring)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	id, err := e.srv.db.InsertMemory(&db.Memory{Category: "timing", Observation: "obs", Confidence: 0.7, Active: true, CreatedAt: now, UpdatedAt: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.srv.db.InsertMemoryRevision(&db.MemoryRevision{MemoryID: id, Change: db.MemoryCreated, ConfidenceAfter: 0.7, Score: scoring.For("timing")}); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/api/v1/memories/%d/revisions", id), nil))
+	var revs APIMemoryRevisionsResponse
+	if err := json.NewDecoder(w.Body).Decode(&revs); err != nil {
+		t.Fatal(err)
+	}
+	if len(revs.Revisions) != 1 || revs.Revisions[0].Scoring.Decay != 0.25 || revs.Revisions[0].ConfidenceBefore != nil {
+		t.Errorf("revisions: %+v", revs)
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/memories/999/revisions", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown memory: %d", w.Code)
+	}
+}
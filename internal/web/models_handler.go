@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/joestump/claude-ops/internal/models"
+	"github.com/joestump/claude-ops/internal/session"
 )
 
 // Governing: SPEC-0035 REQ "Upstream Model Query" — the upstream gateway and its
@@ -87,6 +88,8 @@ type configPageData struct {
 	// operator can see which endpoint discovery is sourced from. Empty when the
 	// Anthropic default is in use (no gateway configured).
 	UpstreamBaseURL string
+	// CLIVersion is the `claude --version` output recorded at startup.
+	CLIVersion string
 }
 
 // buildConfigPageData assembles the config-page template payload, including the
@@ -96,6 +99,7 @@ type configPageData struct {
 // Governing: SPEC-0035 REQ "Configuration UI Model Selection", REQ "Graceful Degradation".
 func (s *Server) buildConfigPageData(r *http.Request, saved bool) configPageData {
 	disc := s.discoverer.Available(r.Context())
+	cliVersion, _ := s.db.GetConfig(session.CLIVersionKey, "")
 	return configPageData{
 		Interval:              s.cfg.Interval,
 		Tier1Model:            s.cfg.Tier1Model,
@@ -112,6 +116,7 @@ func (s *Server) buildConfigPageData(r *http.Request, saved bool) configPageData
 		AvailableModels:       disc.Models,
 		DiscoveryAvailable:    disc.Available,
 		UpstreamBaseURL:       upstreamBaseURL(),
+		CLIVersion:            cliVersion,
 	}
 }
 
@@ -131,7 +136,7 @@ func (s *Server) handleConfigModelsRefresh(w http.ResponseWriter, r *http.Reques
 		DiscoveryAvailable: disc.Available,
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.tmpl.ExecuteTemplate(w, "modelFields", data); err != nil {
+	if err := s.templates(r).ExecuteTemplate(w, "modelFields", data); err != nil {
 		http.Error(w, "template error", http.StatusInternalServerError)
 	}
 }
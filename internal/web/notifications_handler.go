@@ -0,0 +1,127 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// notificationPollInterval is how often the notification stream checks the
+// database for new alerts. A variable so tests can shorten it.
+var notificationPollInterval = 5 * time.Second
+
+// notification is one browser notification sent on the stream.
+type notification struct {
+	Kind  string `json:"kind"` // "critical_event", "pending_review", or "live_incident"
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+	Tag   string `json:"tag"` // lets the browser replace rather than stack repeats
+}
+
+// handleNotificationStream is an SSE feed of alerts for an open dashboard
+// tab: new critical events, memories awaiting review, and running sessions
+// that become incidents (see the live incident banner). It only reports
+// what happens after the client connects, so reconnects do not replay.
+func (s *Server) handleNotificationStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	lastID, err := s.db.LatestEventID()
+	if err != nil {
+		log.Printf("handleNotificationStream: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	pending, err := s.db.CountMemoriesByReviewStatus(db.MemoryUnverified)
+	if err != nil {
+		log.Printf("handleNotificationStream: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	var incidentID int64
+	if l := s.liveIncident(); l != nil {
+		incidentID = l.SessionID
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	_, _ = fmt.Fprintf(w, "retry: 10000\n\n")
+	flusher.Flush()
+
+	send := func(n notification) {
+		data, _ := json.Marshal(n)
+		_, _ = fmt.Fprintf(w, "event: notification\ndata: %s\n\n", data)
+	}
+
+	ticker := time.NewTicker(notificationPollInterval)
+	defer ticker.Stop()
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		events, err := s.db.ListEventsAfter(lastID, "critical", 20)
+		if err != nil {
+			log.Printf("handleNotificationStream: %v", err)
+		}
+		for _, e := range events {
+			lastID = e.ID
+			title := "Critical event"
+			if e.Service != nil {
+				title += ": " + *e.Service
+			}
+			send(notification{
+				Kind:  "critical_event",
+				Title: title,
+				Body:  e.Message,
+				URL:   "/events?level=critical",
+				Tag:   fmt.Sprintf("event-%d", e.ID),
+			})
+		}
+
+		if n, err := s.db.CountMemoriesByReviewStatus(db.MemoryUnverified); err != nil {
+			log.Printf("handleNotificationStream: %v", err)
+		} else {
+			if n > pending {
+				send(notification{
+					Kind:  "pending_review",
+					Title: "Memories awaiting review",
+					Body:  fmt.Sprintf("%d memories are waiting for your approval", n),
+					URL:   "/memories",
+					Tag:   "pending-review",
+				})
+			}
+			pending = n
+		}
+
+		if l := s.liveIncident(); l != nil && l.SessionID != incidentID {
+			incidentID = l.SessionID
+			inc := toAPIIncident(l, time.Now())
+			send(notification{
+				Kind:  "live_incident",
+				Title: fmt.Sprintf("Tier %d %s started", inc.Tier, tierWork(inc.Tier)),
+				Body:  inc.Message,
+				URL:   fmt.Sprintf("/sessions/%d", inc.SessionID),
+				Tag:   fmt.Sprintf("incident-%d", inc.SessionID),
+			})
+		}
+
+		// A comment line doubles as a keepalive and detects closed clients.
+		_, _ = fmt.Fprintf(w, ": ping\n\n")
+		flusher.Flush()
+	}
+}
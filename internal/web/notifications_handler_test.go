@@ -0,0 +1,102 @@
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func TestNotificationStream(t *testing.T) {
+	orig := notificationPollInterval
+	notificationPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { notificationPollInterval = orig })
+
+	e := newTestEnv(t)
+	var live atomic.Pointer[session.LiveSession]
+	e.srv.live = live.Load
+	now := time.Now().UTC().Format(time.RFC3339)
+	// Existing alerts are not replayed to a new client.
+	if _, err := e.srv.db.InsertEvent(&db.Event{Level: "critical", Message: "old outage", CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(e.srv.mux)
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/api/v1/notifications/stream")
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("expected retry line first, got %q", line)
+	}
+
+	svc := "postgres"
+	for _, ev := range []db.Event{
+		{Level: "warning", Message: "slow", CreatedAt: now},
+		{Level: "critical", Service: &svc, Message: "postgres is down", CreatedAt: now},
+	} {
+		if _, err := e.srv.db.InsertEvent(&ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := e.srv.db.InsertMemory(&db.Memory{
+		Category: "behavior", Observation: "new", Confidence: 0.5, Active: true,
+		CreatedAt: now, UpdatedAt: now, Tier: 1, ReviewStatus: db.MemoryUnverified,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	live.Store(&session.LiveSession{SessionID: 7, Tier: 3, Trigger: "escalation", Services: []string{"postgres"}, StartedAt: time.Now()})
+
+	var got []notification
+	deadline := time.After(5 * time.Second)
+	for len(got) < 3 {
+		lineCh := make(chan string, 1)
+		go func() {
+			line, _ := reader.ReadString('\n')
+			lineCh <- line
+		}()
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for notifications, got %+v", got)
+		case line := <-lineCh:
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var n notification
+				if err := json.Unmarshal([]byte(data), &n); err != nil {
+					t.Fatalf("bad notification %q: %v", data, err)
+				}
+				got = append(got, n)
+			}
+		}
+	}
+
+	if got[0].Kind != "critical_event" || got[0].Title != "Critical event: postgres" || got[0].Body != "postgres is down" {
+		t.Errorf("unexpected first notification %+v", got[0])
+	}
+	if got[1].Kind != "pending_review" || got[1].URL != "/memories" {
+		t.Errorf("unexpected second notification %+v", got[1])
+	}
+	if got[2].Kind != "live_incident" || got[2].Title != "Tier 3 remediation started" || got[2].URL != "/sessions/7" {
+		t.Errorf("unexpected third notification %+v", got[2])
+	}
+}
+
+func TestLayoutHasNotificationToggle(t *testing.T) {
+	e := newTestEnv(t)
+	body := getPage(e, "/").Body.String()
+	if !strings.Contains(body, "data-notify-toggle") || !strings.Contains(body, "/api/v1/notifications/stream") {
+		t.Error("expected the notifications toggle and stream wiring in the layout")
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/joestump/claude-ops/internal/hub"
 )
 
 // handleOllamaVersion handles GET /api/version.
@@ -132,9 +134,10 @@ func (s *Server) ollamaDispatch(w http.ResponseWriter, r *http.Request, generate
 	startTier := modelToTier(req.Model)
 	sessionID, err := s.mgr.TriggerAdHoc(prompt, startTier, "api")
 	if err != nil {
+		s.followQueued(r, nil, err)
 		// Session already running — generate a first-person LLM busy response
 		// and return it in the appropriate Ollama format.
-		busyMsg := generateBusyResponse(r.Context(), s.db, os.Getenv("ANTHROPIC_API_KEY"))
+		busyMsg := s.triggerBusyMessage(r.Context(), err)
 		wantStream := req.Stream == nil || *req.Stream
 		modelName := req.Model
 		if modelName == "" {
@@ -167,6 +170,7 @@ func (s *Server) ollamaDispatch(w http.ResponseWriter, r *http.Request, generate
 		_ = json.NewEncoder(w).Encode(resp)
 		return
 	}
+	s.tagSession(r, sessionID)
 
 	// Ollama streams NDJSON by default; stream:false returns a single JSON object.
 	wantStream := req.Stream == nil || *req.Stream
@@ -185,7 +189,7 @@ func (s *Server) ollamaDispatch(w http.ResponseWriter, r *http.Request, generate
 // ollamaStream writes Ollama NDJSON streaming chunks until the session ends.
 func (s *Server) ollamaStream(w http.ResponseWriter, r *http.Request, sessionID int64, model string, generateStyle bool) {
 	flusher, ok := w.(http.Flusher)
-	if !ok || s.rawHub == nil {
+	if !ok || s.hub == nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "streaming not supported"})
@@ -194,7 +198,7 @@ func (s *Server) ollamaStream(w http.ResponseWriter, r *http.Request, sessionID
 
 	w.Header().Set("Content-Type", "application/x-ndjson")
 
-	ch, unsubscribe := s.rawHub.Subscribe(int(sessionID))
+	ch, unsubscribe := s.hub.Subscribe(int(sessionID), hub.Raw)
 	defer unsubscribe()
 
 	ctx := r.Context()
@@ -202,13 +206,13 @@ func (s *Server) ollamaStream(w http.ResponseWriter, r *http.Request, sessionID
 		select {
 		case <-ctx.Done():
 			return
-		case raw, ok := <-ch:
+		case evt, ok := <-ch:
 			if !ok {
 				// Session ended — send done marker.
 				s.writeOllamaChunk(w, flusher, model, "", true, generateStyle)
 				return
 			}
-			text := extractAssistantText(raw)
+			text := extractAssistantText(evt.Data)
 			if text != "" {
 				s.writeOllamaChunk(w, flusher, model, text, false, generateStyle)
 			}
@@ -247,7 +251,7 @@ func (s *Server) writeOllamaChunk(w http.ResponseWriter, flusher http.Flusher, m
 
 // ollamaSync collects all output and returns a single Ollama response object.
 func (s *Server) ollamaSync(w http.ResponseWriter, r *http.Request, sessionID int64, model string, generateStyle bool) {
-	if s.rawHub == nil {
+	if s.hub == nil {
 		w.Header().Set("Content-Type", "application/json")
 		resp := map[string]any{
 			"model":      model,
@@ -260,7 +264,7 @@ func (s *Server) ollamaSync(w http.ResponseWriter, r *http.Request, sessionID in
 		return
 	}
 
-	ch, unsubscribe := s.rawHub.Subscribe(int(sessionID))
+	ch, unsubscribe := s.hub.Subscribe(int(sessionID), hub.Raw)
 	defer unsubscribe()
 
 	var parts []string
@@ -270,11 +274,11 @@ loop:
 		select {
 		case <-ctx.Done():
 			return
-		case raw, ok := <-ch:
+		case evt, ok := <-ch:
 			if !ok {
 				break loop
 			}
-			text := extractAssistantText(raw)
+			text := extractAssistantText(evt.Data)
 			if text != "" {
 				parts = append(parts, text)
 			}
@@ -1,5 +1,7 @@
 package web
 
+import "encoding/json"
+
 // Governing: SPEC-0024 REQ-1 (Endpoint Registration), REQ-7 (Error Response Format), ADR-0020
 // OpenAI-compatible Go structs for the /v1/chat/completions endpoint.
 
@@ -8,6 +10,27 @@ type ChatRequest struct {
 	Model    string        `json:"model"`
 	Messages []ChatMessage `json:"messages"`
 	Stream   bool          `json:"stream"`
+	// User and Metadata identify the calling client; they are stored on the
+	// triggered session and do not affect the prompt.
+	User     string            `json:"user,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Tools and ToolChoice describe the functions the client offers. Only the
+	// built-in functions in chatAnswers are honoured; the rest are ignored.
+	Tools      []ChatTool      `json:"tools,omitempty"`
+	ToolChoice json.RawMessage `json:"tool_choice,omitempty"`
+}
+
+// ChatTool is a tool definition offered by the client.
+type ChatTool struct {
+	Type     string           `json:"type"`
+	Function ChatToolFunction `json:"function"`
+}
+
+// ChatToolFunction describes a function the client offers.
+type ChatToolFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
 }
 
 // ChatMessage represents a single message in the OpenAI messages array.
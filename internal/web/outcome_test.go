@@ -0,0 +1,54 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestSessionsOutcomeFilter(t *testing.T) {
+	e := newTestEnv(t)
+	root := insertTestSession(t, e, "escalated")
+	now := time.Now().UTC().Format(time.RFC3339)
+	tip, err := e.srv.db.InsertSession(&db.Session{Tier: 3, Model: "opus", PromptFile: "/tmp/test.md", Status: "completed", StartedAt: now, ParentSessionID: &root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := insertTestSession(t, e, "completed")
+	_ = e.srv.db.UpdateSessionOutcome(root, db.SessionIssuesObserved)
+	_ = e.srv.db.UpdateSessionOutcome(tip, db.SessionRemediationFailed)
+	_ = e.srv.db.UpdateSessionOutcome(other, db.SessionHealthy)
+
+	body := getPage(e, "/sessions").Body.String()
+	if strings.Count(body, "chain-dot-down") != 2 {
+		t.Error("chain not colored by its tip's outcome")
+	}
+	body = getPage(e, "/sessions?outcome=healthy").Body.String()
+	if !strings.Contains(body, fmt.Sprintf(`href="/sessions/%d"`, other)) || strings.Contains(body, fmt.Sprintf(`href="/sessions/%d"`, tip)) {
+		t.Error("sessions page not filtered by outcome")
+	}
+	if w := getPage(e, "/sessions?outcome=great"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown outcome: %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/sessions?outcome=remediation-failed", nil))
+	var list APISessionsResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Sessions) != 1 || list.Sessions[0].ID != tip || *list.Sessions[0].Outcome != db.SessionRemediationFailed {
+		t.Errorf("API sessions by outcome: %+v", list.Sessions)
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/sessions?outcome=great", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("API unknown outcome: %d", w.Code)
+	}
+}
@@ -0,0 +1,188 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// promptVersionLimit bounds the versions listed on the prompts page.
+const promptVersionLimit = 50
+
+// promptEditorData is the template data for prompts.html.
+type promptEditorData struct {
+	Prompts  []APIPromptSource
+	Selected APIPromptSource
+	Content  string
+	Lint     []session.PromptLint
+	Versions []db.PromptVersion
+	Viewing  *db.PromptVersion // an older version loaded into the editor
+	StoreDB  bool
+	Saved    bool
+	Error    string
+}
+
+// lookupPromptSource finds a configured prompt by its name on the prompts
+// page.
+func (s *Server) lookupPromptSource(name string) (APIPromptSource, []APIPromptSource, bool) {
+	sources := s.promptSources()
+	for _, src := range sources {
+		if src.Name == name {
+			return src, sources, true
+		}
+	}
+	return APIPromptSource{}, sources, false
+}
+
+// handlePrompts renders the prompt editor for the prompt named by ?name=
+// (Tier 1 by default). ?version= loads an older version into the editor.
+func (s *Server) handlePrompts(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "tier1"
+	}
+	src, sources, ok := s.lookupPromptSource(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	data := promptEditorData{Prompts: sources, Selected: src}
+	if id, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64); err == nil {
+		v, err := s.db.GetPromptVersion(id)
+		if err != nil {
+			log.Printf("handlePrompts: %v", err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		if v == nil || v.Path != src.Path {
+			http.NotFound(w, r)
+			return
+		}
+		data.Viewing = v
+		data.Content = v.Content
+	} else if content, err := session.ReadPrompt(s.db, s.cfg.PromptStore, src.Path); err == nil {
+		data.Content = content
+	}
+	s.renderPromptEditor(w, r, data)
+}
+
+// renderPromptEditor fills in the lint results and version history and
+// renders prompts.html.
+func (s *Server) renderPromptEditor(w http.ResponseWriter, r *http.Request, data promptEditorData) {
+	versions, err := s.db.ListPromptVersions(data.Selected.Path, promptVersionLimit)
+	if err != nil {
+		log.Printf("renderPromptEditor: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	data.Versions = versions
+	data.Lint = session.LintPrompt(data.Selected.Tier, data.Content)
+	data.StoreDB = session.UsesPromptDB(s.cfg.PromptStore)
+	s.render(w, r, "prompts.html", data)
+}
+
+// handlePromptLint lints the editor's content as it is typed and renders
+// the findings.
+func (s *Server) handlePromptLint(w http.ResponseWriter, r *http.Request) {
+	tier, _ := strconv.Atoi(r.FormValue("tier"))
+	lint := session.LintPrompt(tier, normalizePrompt(r.FormValue("content")))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates(r).ExecuteTemplate(w, "promptLint", lint); err != nil {
+		http.Error(w, "template error", http.StatusInternalServerError)
+	}
+}
+
+// handlePromptSave saves the editor's content. A prompt with lint errors
+// is not saved; warnings are shown but do not block saving.
+func (s *Server) handlePromptSave(w http.ResponseWriter, r *http.Request) {
+	src, sources, ok := s.lookupPromptSource(r.PathValue("name"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	data := promptEditorData{Prompts: sources, Selected: src, Content: normalizePrompt(r.FormValue("content"))}
+	for _, l := range session.LintPrompt(src.Tier, data.Content) {
+		if l.Severity == session.LintError {
+			data.Error = "Not saved: " + l.Message
+			s.renderPromptEditor(w, r, data)
+			return
+		}
+	}
+	if err := s.savePrompt(src, data.Content, s.approverName(r), nil); err != nil {
+		log.Printf("handlePromptSave: %v", err)
+		data.Error = "Could not save: " + err.Error()
+		s.renderPromptEditor(w, r, data)
+		return
+	}
+	data.Saved = true
+	data.Selected, data.Prompts, _ = s.lookupPromptSource(src.Name)
+	s.renderPromptEditor(w, r, data)
+}
+
+// handlePromptRestore saves an older version of a prompt as its newest.
+func (s *Server) handlePromptRestore(w http.ResponseWriter, r *http.Request) {
+	src, sources, ok := s.lookupPromptSource(r.PathValue("name"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid version ID", http.StatusBadRequest)
+		return
+	}
+	v, err := s.db.GetPromptVersion(id)
+	if err != nil {
+		log.Printf("handlePromptRestore: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if v == nil || v.Path != src.Path {
+		http.NotFound(w, r)
+		return
+	}
+	data := promptEditorData{Prompts: sources, Selected: src, Content: v.Content}
+	note := fmt.Sprintf("restored from version %d", v.ID)
+	if err := s.savePrompt(src, v.Content, s.approverName(r), &note); err != nil {
+		log.Printf("handlePromptRestore: %v", err)
+		data.Error = "Could not restore: " + err.Error()
+		s.renderPromptEditor(w, r, data)
+		return
+	}
+	data.Saved = true
+	data.Selected, data.Prompts, _ = s.lookupPromptSource(src.Name)
+	s.renderPromptEditor(w, r, data)
+}
+
+// savePrompt persists content as the prompt src: to its file with the file
+// prompt store, then as a new version, which the database prompt store runs.
+func (s *Server) savePrompt(src APIPromptSource, content, by string, note *string) error {
+	if !session.UsesPromptDB(s.cfg.PromptStore) {
+		if err := os.MkdirAll(filepath.Dir(src.Path), 0o755); err != nil {
+			return fmt.Errorf("create prompt dir: %w", err)
+		}
+		if err := os.WriteFile(src.Path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("write prompt: %w", err)
+		}
+	}
+	v := &db.PromptVersion{Path: src.Path, Content: content, Note: note, SavedAt: time.Now().UTC().Format(time.RFC3339)}
+	if by != "" {
+		v.SavedBy = &by
+	}
+	_, err := s.db.InsertPromptVersion(v)
+	return err
+}
+
+// normalizePrompt converts the CRLF line endings browsers submit textareas
+// with.
+func normalizePrompt(content string) string {
+	return strings.ReplaceAll(content, "\r\n", "\n")
+}
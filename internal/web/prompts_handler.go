@@ -0,0 +1,218 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+const (
+	// promptHistoryLimit bounds the prompts offered on the Run Now form and
+	// returned by the API.
+	promptHistoryLimit = 25
+	// promptHistoryKeep is how many non-favorite prompts are retained.
+	promptHistoryKeep = 100
+)
+
+// registerPromptRoutes wires the Run Now prompt history and its API, and the
+// tier prompt editor.
+func (s *Server) registerPromptRoutes() {
+	s.mux.HandleFunc("GET /sessions/prompts", s.handlePromptHistory)
+	s.mux.HandleFunc("GET /api/v1/prompts", s.handleAPIListPrompts)
+	s.mux.HandleFunc("PUT /api/v1/prompts/{id}", s.handleAPIUpdatePrompt)
+	s.mux.HandleFunc("DELETE /api/v1/prompts/{id}", s.handleAPIDeletePrompt)
+	s.mux.HandleFunc("GET /api/v1/prompt-sources", s.handleAPIPromptSources)
+	s.mux.HandleFunc("GET /prompts", s.handlePrompts)
+	s.mux.HandleFunc("POST /prompts/lint", s.handlePromptLint)
+	s.mux.HandleFunc("POST /prompts/{name}", s.handlePromptSave)
+	s.mux.HandleFunc("POST /prompts/{name}/versions/{id}/restore", s.handlePromptRestore)
+}
+
+// APIPrompt is the JSON representation of a prompt history entry.
+type APIPrompt struct {
+	ID         int64  `json:"id"`
+	Prompt     string `json:"prompt"`
+	Favorite   bool   `json:"favorite"`
+	UseCount   int    `json:"use_count"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
+// APIPromptsResponse wraps the prompt history for GET /api/v1/prompts.
+type APIPromptsResponse struct {
+	Prompts []APIPrompt `json:"prompts"`
+}
+
+// APIUpdatePromptRequest is the JSON body for PUT /api/v1/prompts/{id}.
+type APIUpdatePromptRequest struct {
+	Favorite *bool `json:"favorite"`
+}
+
+func toAPIPrompt(p db.PromptHistory) APIPrompt {
+	return APIPrompt{
+		ID:         p.ID,
+		Prompt:     p.Prompt,
+		Favorite:   p.Favorite,
+		UseCount:   p.UseCount,
+		LastUsedAt: p.LastUsedAt,
+	}
+}
+
+// promptHistoryData is the template data for the "promptHistory" block.
+type promptHistoryData struct {
+	Favorites []db.PromptHistory
+	Recent    []db.PromptHistory
+}
+
+// recordPrompt adds an ad-hoc prompt to the history. Failures are logged:
+// the session has already started.
+func (s *Server) recordPrompt(prompt string, favorite bool) {
+	if err := s.db.RecordPrompt(prompt, favorite, promptHistoryKeep); err != nil {
+		log.Printf("record prompt: %v", err)
+	}
+}
+
+// handlePromptHistory renders the favorites/recent picker on the Run Now form.
+func (s *Server) handlePromptHistory(w http.ResponseWriter, r *http.Request) {
+	prompts, err := s.db.ListPromptHistory(promptHistoryLimit)
+	if err != nil {
+		log.Printf("handlePromptHistory: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	var data promptHistoryData
+	for _, p := range prompts {
+		if p.Favorite {
+			data.Favorites = append(data.Favorites, p)
+		} else {
+			data.Recent = append(data.Recent, p)
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates(r).ExecuteTemplate(w, "promptHistory", data); err != nil {
+		http.Error(w, "template error", http.StatusInternalServerError)
+	}
+}
+
+// handleAPIListPrompts returns the prompt history, favorites first.
+func (s *Server) handleAPIListPrompts(w http.ResponseWriter, r *http.Request) {
+	prompts, err := s.db.ListPromptHistory(promptHistoryLimit)
+	if err != nil {
+		log.Printf("handleAPIListPrompts: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	out := make([]APIPrompt, len(prompts))
+	for i, p := range prompts {
+		out[i] = toAPIPrompt(p)
+	}
+	writeJSON(w, http.StatusOK, APIPromptsResponse{Prompts: out})
+}
+
+// handleAPIUpdatePrompt marks or unmarks a prompt as a favorite.
+func (s *Server) handleAPIUpdatePrompt(w http.ResponseWriter, r *http.Request) {
+	if !requireJSON(w, r) {
+		return
+	}
+	p, ok := s.lookupPrompt(w, r)
+	if !ok {
+		return
+	}
+	var req APIUpdatePromptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Favorite != nil {
+		if err := s.db.SetPromptFavorite(p.ID, *req.Favorite); err != nil {
+			log.Printf("handleAPIUpdatePrompt: %v", err)
+			writeError(w, http.StatusInternalServerError, "database error")
+			return
+		}
+		p.Favorite = *req.Favorite
+	}
+	writeJSON(w, http.StatusOK, toAPIPrompt(*p))
+}
+
+// handleAPIDeletePrompt removes a prompt from the history.
+func (s *Server) handleAPIDeletePrompt(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.lookupPrompt(w, r)
+	if !ok {
+		return
+	}
+	if err := s.db.DeletePromptHistory(p.ID); err != nil {
+		log.Printf("handleAPIDeletePrompt: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookupPrompt resolves the {id} path value, writing the error response and
+// returning false when it is invalid or unknown.
+func (s *Server) lookupPrompt(w http.ResponseWriter, r *http.Request) (*db.PromptHistory, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid prompt ID")
+		return nil, false
+	}
+	p, err := s.db.GetPromptHistory(id)
+	if err != nil {
+		log.Printf("lookupPrompt: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return nil, false
+	}
+	if p == nil {
+		writeError(w, http.StatusNotFound, "prompt not found")
+		return nil, false
+	}
+	return p, true
+}
+
+// APIPromptSource reports where a configured agent prompt is read from.
+type APIPromptSource struct {
+	Name   string `json:"name"`
+	Tier   int    `json:"tier"`
+	Path   string `json:"path"`
+	Source string `json:"source"` // file, embedded, database, or missing
+}
+
+// APIPromptSourcesResponse wraps the prompt sources for GET /api/v1/prompt-sources.
+type APIPromptSourcesResponse struct {
+	Prompts []APIPromptSource `json:"prompts"`
+}
+
+// promptSources lists each tier's prompt, the specialized Tier 2 prompts,
+// and the verification prompt, with whether the configured file, the
+// default embedded in the binary, or a version saved from the prompt editor
+// is used.
+func (s *Server) promptSources() []APIPromptSource {
+	source := func(name string, tier int, path string) APIPromptSource {
+		src := session.PromptSource(path)
+		if v, err := session.StoredPrompt(s.db, s.cfg.PromptStore, path); err != nil {
+			log.Printf("promptSources: %v", err)
+		} else if v != nil {
+			src = session.PromptSourceDatabase
+		}
+		return APIPromptSource{Name: name, Tier: tier, Path: path, Source: src}
+	}
+	out := []APIPromptSource{
+		source("tier1", 1, s.cfg.Prompt),
+		source("tier2", 2, s.cfg.Tier2Prompt),
+	}
+	for _, rule := range session.ParsePromptRules(s.cfg.Tier2PromptRules) {
+		out = append(out, source(rule.Prompt, 2, rule.Path(s.cfg.Tier2Prompt)))
+	}
+	return append(out,
+		source("tier3", 3, s.cfg.Tier3Prompt),
+		source("verify", 1, s.cfg.VerifyPrompt),
+	)
+}
+
+// handleAPIPromptSources reports where each agent prompt is read from.
+func (s *Server) handleAPIPromptSources(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, APIPromptSourcesResponse{Prompts: s.promptSources()})
+}
@@ -0,0 +1,191 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTriggerSessionRecordsPrompt(t *testing.T) {
+	e := newTestEnvWithTrigger(t, &mockTrigger{nextID: 7})
+
+	postForm(e, "/sessions/trigger", url.Values{"prompt": {"check jellyfin"}, "tier": {"1"}})
+	postForm(e, "/sessions/trigger", url.Values{"prompt": {"restart caddy"}, "tier": {"1"}, "favorite": {"1"}})
+
+	prompts, err := e.srv.db.ListPromptHistory(10)
+	if err != nil || len(prompts) != 2 {
+		t.Fatalf("ListPromptHistory: %+v %v", prompts, err)
+	}
+	if prompts[0].Prompt != "restart caddy" || !prompts[0].Favorite {
+		t.Errorf("expected favorite first, got %+v", prompts[0])
+	}
+
+	req := httptest.NewRequest("GET", "/sessions/prompts", nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	body := w.Body.String()
+	if !strings.Contains(body, "Favorites") || !strings.Contains(body, `value="check jellyfin"`) {
+		t.Errorf("unexpected prompt picker:\n%s", body)
+	}
+}
+
+func TestAPIPrompts(t *testing.T) {
+	e := newTestEnv(t)
+	if err := e.srv.db.RecordPrompt("check jellyfin", false, 10); err != nil {
+		t.Fatalf("RecordPrompt: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/prompts", nil))
+	var list APIPromptsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list.Prompts) != 1 {
+		t.Fatalf("expected one prompt, got %s (err %v)", w.Body.String(), err)
+	}
+	id := list.Prompts[0].ID
+
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/prompts/%d", id), strings.NewReader(`{"favorite":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	var p APIPrompt
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &p) != nil || !p.Favorite {
+		t.Fatalf("expected favorite prompt, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/prompts/%d", id), nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/prompts/%d", id), nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for deleted prompt, got %d", w.Code)
+	}
+}
+
+func TestAPIPromptSources(t *testing.T) {
+	e := newTestEnv(t)
+	dir := t.TempDir()
+	e.srv.cfg.Prompt = filepath.Join(dir, "tier1-observe.md")
+	if err := os.WriteFile(e.srv.cfg.Prompt, []byte("observe"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e.srv.cfg.Tier2Prompt = filepath.Join(dir, "tier2-investigate.md")
+	e.srv.cfg.Tier2PromptRules = "db-investigate.md=postgres"
+	e.srv.cfg.Tier3Prompt = filepath.Join(dir, "custom-remediate.md")
+	e.srv.cfg.VerifyPrompt = filepath.Join(dir, "verify.md")
+
+	w := getPage(e, "/api/v1/prompt-sources")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var resp APIPromptSourcesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, p := range resp.Prompts {
+		got[p.Name] = p.Source
+	}
+	want := map[string]string{
+		"tier1":             "file",
+		"tier2":             "embedded",
+		"db-investigate.md": "embedded",
+		"tier3":             "missing", // no embedded prompt of that name
+		"verify":            "embedded",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("sources = %v, want %v", got, want)
+	}
+}
+
+func TestPromptEditor(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.cfg.TrustedProxies = "192.0.2.0/24" // httptest requests come from 192.0.2.1
+	dir := t.TempDir()
+	e.srv.cfg.Prompt = filepath.Join(dir, "tier1-observe.md")
+	e.srv.cfg.Tier2Prompt = filepath.Join(dir, "tier2-investigate.md")
+	e.srv.cfg.Tier3Prompt = filepath.Join(dir, "tier3-remediate.md")
+	e.srv.cfg.VerifyPrompt = filepath.Join(dir, "verify.md")
+
+	// The embedded default is shown, and lints clean.
+	w := getPage(e, "/prompts?name=tier2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); !strings.Contains(body, "Tier 2") || !strings.Contains(body, "No problems found.") {
+		t.Errorf("expected the embedded Tier 2 prompt, linting clean:\n%s", body)
+	}
+	if w := getPage(e, "/prompts?name=nope"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown prompt: status %d", w.Code)
+	}
+
+	// Live linting flags a malformed marker.
+	w = postForm(e, "/prompts/lint", url.Values{"tier": {"1"}, "content": {"Escalate.\r\n[EVENT warning] disk\r\n"}})
+	if body := w.Body.String(); !strings.Contains(body, "line 2") || !strings.Contains(body, "Malformed marker") {
+		t.Errorf("expected a marker warning:\n%s", body)
+	}
+
+	// An empty prompt is not saved.
+	w = postForm(e, "/prompts/tier1", url.Values{"content": {""}})
+	if !strings.Contains(w.Body.String(), "Not saved") {
+		t.Errorf("expected the empty prompt to be rejected:\n%s", w.Body.String())
+	}
+	if _, err := os.Stat(e.srv.cfg.Prompt); !os.IsNotExist(err) {
+		t.Errorf("empty prompt was written: %v", err)
+	}
+
+	// With the file store, saving writes the file and records a version.
+	for _, content := range []string{"first\r\nversion", "second version"} {
+		req := httptest.NewRequest("POST", "/prompts/tier1", strings.NewReader(url.Values{"content": {content}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Remote-User", "alice")
+		w = httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		if !strings.Contains(w.Body.String(), "Saved.") {
+			t.Fatalf("save: %s", w.Body.String())
+		}
+	}
+	if data, _ := os.ReadFile(e.srv.cfg.Prompt); string(data) != "second version" {
+		t.Errorf("prompt file = %q", data)
+	}
+	versions, err := e.srv.db.ListPromptVersions(e.srv.cfg.Prompt, 10)
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("versions = %+v, %v", versions, err)
+	}
+	if versions[1].Content != "first\nversion" || versions[1].SavedBy == nil || *versions[1].SavedBy != "alice" {
+		t.Errorf("unexpected first version %+v", versions[1])
+	}
+
+	// Restoring saves the older version as the newest.
+	first := fmt.Sprint(versions[1].ID)
+	w = postForm(e, "/prompts/tier1/versions/"+first+"/restore", nil)
+	if !strings.Contains(w.Body.String(), "Saved.") {
+		t.Fatalf("restore: %s", w.Body.String())
+	}
+	if data, _ := os.ReadFile(e.srv.cfg.Prompt); string(data) != "first\nversion" {
+		t.Errorf("restored prompt file = %q", data)
+	}
+	if w := postForm(e, "/prompts/tier2/versions/"+first+"/restore", nil); w.Code != http.StatusNotFound {
+		t.Errorf("restoring another prompt's version: status %d", w.Code)
+	}
+
+	// With the database store, saving leaves the file alone and the stored
+	// version becomes the source.
+	e.srv.cfg.PromptStore = "db"
+	postForm(e, "/prompts/tier1", url.Values{"content": {"from the database"}})
+	if data, _ := os.ReadFile(e.srv.cfg.Prompt); string(data) != "first\nversion" {
+		t.Errorf("db store wrote the prompt file: %q", data)
+	}
+	w = getPage(e, "/prompts?name=tier1")
+	if body := w.Body.String(); !strings.Contains(body, "from the database") || !strings.Contains(body, "read from database") {
+		t.Errorf("expected the stored version:\n%s", body)
+	}
+}
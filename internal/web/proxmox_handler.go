@@ -0,0 +1,270 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/policy"
+	"github.com/joestump/claude-ops/internal/proxmox"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// registerHypervisorRoutes wires the Proxmox inventory and guest power action
+// endpoints onto the server mux and, as the hypervisor skill calls them, the
+// agent listener.
+func (s *Server) registerHypervisorRoutes() {
+	s.handleAgent("GET /api/v1/hypervisor/guests", s.handleAPIListGuests)
+	s.handleAgent("POST /api/v1/hypervisor/guests/{vmid}/{action}", s.handleAPIGuestAction)
+}
+
+// APIGuest is the JSON representation of a Proxmox VM or LXC guest.
+type APIGuest struct {
+	VMID    int     `json:"vmid"`
+	Name    string  `json:"name"`
+	Service string  `json:"service"`
+	Node    string  `json:"node"`
+	Type    string  `json:"type"`
+	Status  string  `json:"status"`
+	Uptime  int64   `json:"uptime_seconds"`
+	CPU     float64 `json:"cpu"`
+	Mem     int64   `json:"mem_bytes"`
+	MaxMem  int64   `json:"maxmem_bytes"`
+}
+
+// APIGuestsResponse wraps the guest inventory for GET /api/v1/hypervisor/guests.
+type APIGuestsResponse struct {
+	Guests []APIGuest `json:"guests"`
+}
+
+// APIGuestActionRequest is the JSON body for POST /api/v1/hypervisor/guests/{vmid}/{action}.
+// SessionID identifies the agent session requesting the action; it must be a
+// running Tier 2+ session.
+type APIGuestActionRequest struct {
+	SessionID int64  `json:"session_id"`
+	Reason    string `json:"reason"`
+}
+
+// APIGuestActionResponse is returned after a guest power action is accepted.
+type APIGuestActionResponse struct {
+	Guest  APIGuest `json:"guest"`
+	Action string   `json:"action"`
+	TaskID string   `json:"task_id,omitempty"`
+	DryRun bool     `json:"dry_run"`
+}
+
+// handleAPIListGuests returns the current Proxmox guest inventory.
+func (s *Server) handleAPIListGuests(w http.ResponseWriter, r *http.Request) {
+	if s.hypervisor == nil {
+		writeError(w, http.StatusServiceUnavailable, "proxmox integration is not configured")
+		return
+	}
+	guests, err := s.hypervisor.Inventory(r.Context())
+	if err != nil {
+		log.Printf("handleAPIListGuests: %v", err)
+		writeError(w, http.StatusBadGateway, "proxmox API error")
+		return
+	}
+	out := make([]APIGuest, len(guests))
+	for i, g := range guests {
+		out[i] = toAPIGuest(g)
+	}
+	writeJSON(w, http.StatusOK, APIGuestsResponse{Guests: out})
+}
+
+// handleAPIGuestAction performs a guarded power action on a Proxmox guest.
+// The action is only allowed for a running Tier 2+ session, is suppressed in
+// dry-run mode, and is subject to the per-guest limits in proxmox.Limits and
+// to the cooldown policy rules, whose results are recorded on the session.
+// Every attempt that reaches the hypervisor is recorded as a cooldown action.
+func (s *Server) handleAPIGuestAction(w http.ResponseWriter, r *http.Request) {
+	if s.hypervisor == nil {
+		writeError(w, http.StatusServiceUnavailable, "proxmox integration is not configured")
+		return
+	}
+	if !requireJSON(w, r) {
+		return
+	}
+
+	vmid, err := strconv.Atoi(r.PathValue("vmid"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid vmid")
+		return
+	}
+	action := r.PathValue("action")
+	if !proxmox.ValidAction(action) {
+		writeError(w, http.StatusBadRequest, "action must be one of start, stop, reboot")
+		return
+	}
+
+	var req APIGuestActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	sess, err := s.db.GetSession(req.SessionID)
+	if err != nil {
+		log.Printf("handleAPIGuestAction: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if sess == nil || sess.Status != "running" {
+		writeError(w, http.StatusForbidden, "guest actions require a running session")
+		return
+	}
+	if sess.Tier < 2 {
+		writeError(w, http.StatusForbidden, "guest actions require Tier 2+")
+		return
+	}
+
+	guest, err := s.hypervisor.FindGuest(r.Context(), vmid)
+	if err != nil {
+		log.Printf("handleAPIGuestAction: %v", err)
+		writeError(w, http.StatusBadGateway, "proxmox API error")
+		return
+	}
+	if guest == nil {
+		writeError(w, http.StatusNotFound, "guest not found")
+		return
+	}
+
+	service := guest.ServiceName()
+	// Hold the target's lock from the cooldown check until the action is
+	// recorded, so concurrent requests cannot all pass the check.
+	mu, _ := s.guestLocks.LoadOrStore(service, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	actionType := proxmox.CooldownActionType(action)
+	limit := proxmox.Limits[action]
+	count, err := s.db.CheckCooldown(service, actionType, limit.Window)
+	if err != nil {
+		log.Printf("handleAPIGuestAction: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if count >= limit.Max {
+		writeError(w, http.StatusTooManyRequests,
+			fmt.Sprintf("cooldown: %s already performed %d time(s) on %s in the last %s", action, count, service, limit.Window))
+		return
+	}
+
+	if reason := s.deniedGuestAction(sess, service, actionType); reason != "" {
+		s.recordGuestEvent(sess.ID, service, "warning",
+			fmt.Sprintf("Cooldown action %s on %s denied by policy %s", actionType, service, reason))
+		writeError(w, http.StatusForbidden, "denied by policy "+reason)
+		return
+	}
+
+	resp := APIGuestActionResponse{Guest: toAPIGuest(*guest), Action: action}
+	if s.cfg.DryRun {
+		resp.DryRun = true
+		s.recordGuestEvent(sess.ID, service, "info",
+			fmt.Sprintf("DRY RUN: would %s %s %d on %s — %s", action, guest.Type, guest.VMID, guest.Node, req.Reason))
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	upid, actErr := s.hypervisor.Action(r.Context(), *guest, action)
+	sid := sess.ID
+	rec := &db.CooldownAction{
+		Service:    service,
+		ActionType: actionType,
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Success:    actErr == nil,
+		Tier:       sess.Tier,
+		SessionID:  &sid,
+	}
+	if actErr != nil {
+		msg := actErr.Error()
+		rec.Error = &msg
+	}
+	if _, err := s.db.InsertCooldownAction(rec); err != nil {
+		log.Printf("handleAPIGuestAction: record cooldown: %v", err)
+	}
+
+	if actErr != nil {
+		log.Printf("handleAPIGuestAction: %v", actErr)
+		s.recordGuestEvent(sess.ID, service, "critical",
+			fmt.Sprintf("Failed to %s %s %d on %s: %v", action, guest.Type, guest.VMID, guest.Node, actErr))
+		writeError(w, http.StatusBadGateway, "proxmox action failed")
+		return
+	}
+
+	s.recordGuestEvent(sess.ID, service, "warning",
+		fmt.Sprintf("Requested %s of %s %d on %s — %s", action, guest.Type, guest.VMID, guest.Node, req.Reason))
+	resp.TaskID = upid
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// deniedGuestAction evaluates the cooldown policy for a guest action the
+// session asked for, recording the results on the session. It returns the
+// rule, with its message, that denied the action, or "" if none did.
+func (s *Server) deniedGuestAction(sess *db.Session, service, actionType string) string {
+	if s.policy == nil {
+		return ""
+	}
+	recent, err := s.db.CheckCooldown(service, actionType, 24*time.Hour)
+	if err != nil {
+		log.Printf("deniedGuestAction: policy cooldown count: %v", err)
+	}
+	in := session.PolicyInput(s.db, sess.ID)
+	in.Cooldown = &policy.Cooldown{
+		Service: service,
+		Action:  actionType,
+		Tier:    sess.Tier,
+		// The action has not been taken yet; rules see it as the attempt
+		// it would record if it succeeds.
+		Success:     true,
+		RecentCount: recent,
+	}
+	d := s.policy.Evaluate(policy.PointCooldown, in)
+	session.SavePolicyResults(s.db, sess.ID, policy.PointCooldown, d)
+	for _, r := range d.Results {
+		if r.Action == "error" {
+			s.recordGuestEvent(sess.ID, service, "warning",
+				fmt.Sprintf("Policy rule %s failed to evaluate and was skipped: %s", r.Rule, r.Detail))
+		}
+	}
+	if !d.Deny {
+		return ""
+	}
+	return d.Reason
+}
+
+// recordGuestEvent logs an event for a guest power action against the
+// requesting session so it appears in the events feed.
+func (s *Server) recordGuestEvent(sessionID int64, service, level, message string) {
+	sid := sessionID
+	svc := service
+	if _, err := s.db.InsertEvent(&db.Event{
+		SessionID: &sid,
+		Level:     level,
+		Service:   &svc,
+		Message:   message,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		log.Printf("recordGuestEvent: %v", err)
+	}
+}
+
+func toAPIGuest(g proxmox.Guest) APIGuest {
+	return APIGuest{
+		VMID:    g.VMID,
+		Name:    g.Name,
+		Service: g.ServiceName(),
+		Node:    g.Node,
+		Type:    g.Type,
+		Status:  g.Status,
+		Uptime:  g.Uptime,
+		CPU:     g.CPU,
+		Mem:     g.Mem,
+		MaxMem:  g.MaxMem,
+	}
+}
@@ -0,0 +1,252 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/policy"
+	"github.com/joestump/claude-ops/internal/proxmox"
+)
+
+// stubProxmox returns a Proxmox client backed by an httptest server with a
+// single running VM (vmid 100, "docker-host"). actions counts status POSTs.
+func stubProxmox(t *testing.T) (*proxmox.Client, *int32) {
+	t.Helper()
+	var actions int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			atomic.AddInt32(&actions, 1)
+			_, _ = w.Write([]byte(`{"data":"UPID:pve1:reboot"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"vmid":100,"name":"docker-host","node":"pve1","type":"qemu","status":"running"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return proxmox.New(srv.URL, "ops@pve!claude", "secret"), &actions
+}
+
+// insertTierSession creates a session at the given tier and status.
+func insertTierSession(t *testing.T, e *testEnv, tier int, status string) int64 {
+	t.Helper()
+	id, err := e.srv.db.InsertSession(&db.Session{
+		Tier:       tier,
+		Model:      "sonnet",
+		PromptFile: "/tmp/test.md",
+		Status:     status,
+		StartedAt:  time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+	return id
+}
+
+func postGuestAction(e *testEnv, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestHypervisorGuests_NotConfigured(t *testing.T) {
+	e := newTestEnv(t)
+	req := httptest.NewRequest("GET", "/api/v1/hypervisor/guests", nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", w.Code)
+	}
+}
+
+func TestHypervisorGuests_List(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.hypervisor, _ = stubProxmox(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/hypervisor/guests", nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp APIGuestsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Guests) != 1 || resp.Guests[0].Service != "docker-host" {
+		t.Fatalf("unexpected guests %+v", resp.Guests)
+	}
+}
+
+func TestGuestAction_RequiresTier2(t *testing.T) {
+	e := newTestEnv(t)
+	var actions *int32
+	e.srv.hypervisor, actions = stubProxmox(t)
+	id := insertTierSession(t, e, 1, "running")
+
+	w := postGuestAction(e, "/api/v1/hypervisor/guests/100/reboot", fmt.Sprintf(`{"session_id":%d}`, id))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+	if atomic.LoadInt32(actions) != 0 {
+		t.Fatal("no action should reach the hypervisor")
+	}
+}
+
+func TestGuestAction_RejectsUnknownAction(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.hypervisor, _ = stubProxmox(t)
+	id := insertTierSession(t, e, 2, "running")
+
+	w := postGuestAction(e, "/api/v1/hypervisor/guests/100/destroy", fmt.Sprintf(`{"session_id":%d}`, id))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestGuestAction_DryRun(t *testing.T) {
+	e := newTestEnv(t)
+	var actions *int32
+	e.srv.hypervisor, actions = stubProxmox(t)
+	e.srv.cfg.DryRun = true
+	id := insertTierSession(t, e, 2, "running")
+
+	w := postGuestAction(e, "/api/v1/hypervisor/guests/100/reboot", fmt.Sprintf(`{"session_id":%d}`, id))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp APIGuestActionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.DryRun {
+		t.Error("expected dry_run=true")
+	}
+	if atomic.LoadInt32(actions) != 0 {
+		t.Fatal("dry run must not reach the hypervisor")
+	}
+}
+
+func TestGuestAction_RecordsCooldownAndEnforcesLimit(t *testing.T) {
+	e := newTestEnv(t)
+	var actions *int32
+	e.srv.hypervisor, actions = stubProxmox(t)
+	id := insertTierSession(t, e, 2, "running")
+
+	w := postGuestAction(e, "/api/v1/hypervisor/guests/100/reboot", fmt.Sprintf(`{"session_id":%d,"reason":"wedged"}`, id))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp APIGuestActionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.TaskID != "UPID:pve1:reboot" {
+		t.Errorf("unexpected task id %q", resp.TaskID)
+	}
+
+	count, err := e.srv.db.CheckCooldown("docker-host", "vm_reboot", time.Hour)
+	if err != nil {
+		t.Fatalf("CheckCooldown: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 recorded vm_reboot, got %d", count)
+	}
+
+	// Reboot is limited to once per 4 hours.
+	w = postGuestAction(e, "/api/v1/hypervisor/guests/100/reboot", fmt.Sprintf(`{"session_id":%d}`, id))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if atomic.LoadInt32(actions) != 1 {
+		t.Fatalf("expected exactly 1 action to reach the hypervisor, got %d", atomic.LoadInt32(actions))
+	}
+}
+
+func TestGuestAction_ConcurrentRequestsRespectCooldown(t *testing.T) {
+	e := newTestEnv(t)
+	var actions int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			atomic.AddInt32(&actions, 1)
+			time.Sleep(50 * time.Millisecond) // a slow hypervisor widens the window
+			_, _ = w.Write([]byte(`{"data":"UPID:pve1:reboot"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"vmid":100,"name":"docker-host","node":"pve1","type":"qemu","status":"running"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	e.srv.hypervisor = proxmox.New(srv.URL, "ops@pve!claude", "secret")
+	id := insertTierSession(t, e, 2, "running")
+
+	var wg sync.WaitGroup
+	codes := make([]int, 5)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = postGuestAction(e, "/api/v1/hypervisor/guests/100/reboot", fmt.Sprintf(`{"session_id":%d}`, id)).Code
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&actions); n != 1 {
+		t.Errorf("expected exactly 1 reboot to reach the hypervisor, got %d (codes %v)", n, codes)
+	}
+	accepted := 0
+	for _, c := range codes {
+		if c == http.StatusAccepted {
+			accepted++
+		} else if c != http.StatusTooManyRequests {
+			t.Errorf("unexpected status %d", c)
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("expected 1 accepted request, got %d (codes %v)", accepted, codes)
+	}
+}
+
+func TestGuestAction_DeniedByCooldownPolicy(t *testing.T) {
+	e := newTestEnv(t)
+	var actions *int32
+	e.srv.hypervisor, actions = stubProxmox(t)
+	engine, err := policy.Parse([]byte(`
+rules:
+  - name: no-vm-reboots
+    when: cooldown
+    expr: cooldown.action == "vm_reboot" && cooldown.service == "docker-host"
+    action: deny
+    message: reboot the docker host by hand
+`))
+	if err != nil {
+		t.Fatalf("parse policy: %v", err)
+	}
+	e.srv.policy = engine
+	id := insertTierSession(t, e, 2, "running")
+
+	w := postGuestAction(e, "/api/v1/hypervisor/guests/100/reboot", fmt.Sprintf(`{"session_id":%d,"reason":"wedged"}`, id))
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "no-vm-reboots") {
+		t.Fatalf("expected 403 naming the rule, got %d: %s", w.Code, w.Body.String())
+	}
+	if n := atomic.LoadInt32(actions); n != 0 {
+		t.Errorf("expected no action to reach the hypervisor, got %d", n)
+	}
+	if count, _ := e.srv.db.CheckCooldown("docker-host", "vm_reboot", time.Hour); count != 0 {
+		t.Errorf("expected no recorded cooldown action, got %d", count)
+	}
+	evals, err := e.srv.db.ListPolicyEvaluations(id)
+	if err != nil {
+		t.Fatalf("ListPolicyEvaluations: %v", err)
+	}
+	if len(evals) != 1 || evals[0].Point != policy.PointCooldown || !evals[0].Matched || evals[0].Action != policy.ActionDeny {
+		t.Errorf("unexpected policy evaluations %+v", evals)
+	}
+}
@@ -0,0 +1,47 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+)
+
+// readOnlySafe lists the non-GET routes that change nothing, which stay
+// available on a read-only dashboard. The canary endpoints are exercised by
+// the agent during a drill rather than by dashboard users.
+var readOnlySafe = map[string]bool{
+	"POST /api/v1/graphql":                 true,
+	"POST /api/v1/simulate":                true,
+	"POST /sessions/estimate":              true,
+	"POST /prompts/lint":                   true,
+	"POST /api/v1/selftest/canary/restart": true,
+	"POST /api/v1/selftest/notify":         true,
+}
+
+// readOnlyGuard rejects requests to mutating routes while
+// CLAUDEOPS_READONLY_UI is set. It checks the route pattern the request
+// would be dispatched to, so a route added later is read-only unless it is
+// a GET or listed in readOnlySafe.
+func (s *Server) readOnlyGuard(next *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.cfg.ReadOnlyUI {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, pattern := next.Handler(r); pattern == "" || readOnlySafe[pattern] {
+			// Unmatched requests get the mux's own 404 or 405.
+			next.ServeHTTP(w, r)
+			return
+		}
+		const msg = "the dashboard is read-only"
+		if strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/v1/") {
+			writeError(w, http.StatusForbidden, msg)
+			return
+		}
+		http.Error(w, msg, http.StatusForbidden)
+	})
+}
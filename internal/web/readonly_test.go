@@ -0,0 +1,94 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyUI(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.cfg.ReadOnlyUI = true
+	handler := e.srv.server.Handler
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, tt := range []struct{ method, path string }{
+		{"POST", "/config"},
+		{"PUT", "/api/v1/config"},
+		{"POST", "/sessions/trigger"},
+		{"POST", "/api/v1/sessions/trigger"},
+		{"POST", "/memories"},
+		{"DELETE", "/api/v1/memories/1"},
+		{"POST", "/v1/chat/completions"},
+		{"POST", "/api/chat"},
+		{"POST", "/api/v1/webhook"},
+	} {
+		if w := serve(tt.method, tt.path, ""); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: expected 403, got %d", tt.method, tt.path, w.Code)
+		}
+	}
+	if w := serve("PUT", "/api/v1/config", ""); !strings.Contains(w.Body.String(), `"error"`) {
+		t.Errorf("API rejection is not JSON: %s", w.Body.String())
+	}
+
+	if w := serve("POST", "/api/v1/graphql", `{"query":"{ sessions { id } }"}`); w.Code == http.StatusForbidden {
+		t.Error("read-only GraphQL query was rejected")
+	}
+	if w := serve("POST", "/no/such/route", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown route: expected 404, got %d", w.Code)
+	}
+
+	w := serve("GET", "/", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /: expected 200, got %d", w.Code)
+	}
+	if body := w.Body.String(); strings.Contains(body, `id="run-modal"`) || !strings.Contains(body, "Read-only dashboard") {
+		t.Error("index still offers Run Now or lacks the read-only notice")
+	}
+	if body := serve("GET", "/memories", "").Body.String(); strings.Contains(body, "Save Memory") {
+		t.Error("memories page still offers Add Memory")
+	}
+	if body := serve("GET", "/config", "").Body.String(); strings.Contains(body, "Save Configuration") {
+		t.Error("config page still offers Save Configuration")
+	}
+
+	e.srv.cfg.ReadOnlyUI = false
+	if w := serve("POST", "/api/v1/memories", `{}`); w.Code == http.StatusForbidden {
+		t.Error("writable dashboard rejected a mutation")
+	}
+	if body := serve("GET", "/", "").Body.String(); !strings.Contains(body, `id="run-modal"`) {
+		t.Error("writable dashboard hides Run Now")
+	}
+}
+
+func TestReadOnlyUIAllowsAgentCallbacks(t *testing.T) {
+	e := newTestEnv(t)
+	cfg := *e.srv.cfg
+	cfg.AgentPort = 8081
+	cfg.ReadOnlyUI = true
+	srv := New(&cfg, nil, e.srv.db, nil)
+
+	serve := func(handler http.Handler) int {
+		req := httptest.NewRequest("POST", "/api/v1/hypervisor/guests/100/reboot", strings.NewReader(`{"session_id":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := serve(srv.server.Handler); code != http.StatusForbidden {
+		t.Errorf("dashboard: expected 403, got %d", code)
+	}
+	if code := serve(srv.agent.Handler); code == http.StatusForbidden {
+		t.Error("agent listener rejected a hypervisor action on a read-only dashboard")
+	}
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// remediationRecentLimit bounds the scored actions listed.
+const remediationRecentLimit = 50
+
+// registerRemediationRoutes wires the remediation outcome page.
+func (s *Server) registerRemediationRoutes() {
+	s.mux.HandleFunc("GET /remediations", s.handleRemediations)
+}
+
+// remediationOutcome is one scored action in the recent outcomes list.
+type remediationOutcome struct {
+	Timestamp  string
+	Service    string
+	ActionType string
+	SessionID  *int64
+	Effective  bool
+	Reason     string
+}
+
+// remediationsPageData is the template data for remediations.html.
+type remediationsPageData struct {
+	Services []db.RemediationScore // per service and action type
+	Actions  []db.RemediationScore // per action type, Service empty
+	Recent   []remediationOutcome
+}
+
+// handleRemediations renders how often each remediation worked, per service
+// and action type and per action type overall.
+func (s *Server) handleRemediations(w http.ResponseWriter, r *http.Request) {
+	scores, err := s.db.ListRemediationScores()
+	if err != nil {
+		log.Printf("handleRemediations: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	recent, err := s.db.ListScoredCooldownActions(remediationRecentLimit)
+	if err != nil {
+		log.Printf("handleRemediations: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	data := remediationsPageData{Services: scores, Actions: scoresByAction(scores)}
+	for _, a := range recent {
+		o := remediationOutcome{
+			Timestamp:  a.Timestamp,
+			Service:    a.Service,
+			ActionType: a.ActionType,
+			SessionID:  a.SessionID,
+			Effective:  a.Outcome != nil && *a.Outcome == db.OutcomeEffective,
+		}
+		if a.OutcomeReason != nil {
+			o.Reason = *a.OutcomeReason
+		}
+		data.Recent = append(data.Recent, o)
+	}
+	s.render(w, r, "remediations.html", data)
+}
+
+// scoresByAction sums per-service scores into one row per action type,
+// most attempted first.
+func scoresByAction(scores []db.RemediationScore) []db.RemediationScore {
+	index := make(map[string]int)
+	var out []db.RemediationScore
+	for _, sc := range scores {
+		i, ok := index[sc.ActionType]
+		if !ok {
+			i = len(out)
+			index[sc.ActionType] = i
+			out = append(out, db.RemediationScore{ActionType: sc.ActionType})
+		}
+		a := &out[i]
+		a.Attempts += sc.Attempts
+		a.Effective += sc.Effective
+		a.Ineffective += sc.Ineffective
+		a.Pending += sc.Pending
+		a.LastAction = max(a.LastAction, sc.LastAction)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Attempts > out[j].Attempts })
+	return out
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestRemediationsPage(t *testing.T) {
+	e := newTestEnv(t)
+
+	w := getPage(e, "/remediations")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "No remediations recorded yet") {
+		t.Fatalf("expected empty page, got %d:\n%s", w.Code, w.Body.String())
+	}
+
+	for i, ts := range []string{"2026-10-01T01:00:00Z", "2026-10-01T05:00:00Z", "2026-10-01T09:00:00Z"} {
+		id, err := e.srv.db.InsertCooldownAction(&db.CooldownAction{Service: "jellyfin", ActionType: "restart", Timestamp: ts, Success: true, Tier: 2})
+		if err != nil {
+			t.Fatalf("insert cooldown action: %v", err)
+		}
+		outcome, reason := db.OutcomeEffective, "no critical events for 6 hours"
+		if i == 0 {
+			outcome, reason = db.OutcomeIneffective, "remediated again (restart)"
+		}
+		if i < 2 {
+			if err := e.srv.db.ScoreCooldownAction(id, outcome, reason, ts); err != nil {
+				t.Fatalf("score: %v", err)
+			}
+		}
+	}
+
+	body := getPage(e, "/remediations").Body.String()
+	for _, want := range []string{"What Actually Works", "jellyfin", "50%", "remediated again (restart)", "didn't work"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+}
+
+func TestScoresByAction(t *testing.T) {
+	got := scoresByAction([]db.RemediationScore{
+		{Service: "caddy", ActionType: "redeployment", Attempts: 1, Effective: 1, LastAction: "b"},
+		{Service: "caddy", ActionType: "restart", Attempts: 2, Ineffective: 2, LastAction: "c"},
+		{Service: "jellyfin", ActionType: "restart", Attempts: 3, Effective: 2, Pending: 1, LastAction: "a"},
+	})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 actions, got %+v", got)
+	}
+	want := db.RemediationScore{ActionType: "restart", Attempts: 5, Effective: 2, Ineffective: 2, Pending: 1, LastAction: "c"}
+	if got[0] != want {
+		t.Errorf("restart = %+v, want %+v", got[0], want)
+	}
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/report"
+)
+
+// registerReportRoutes wires the weekly trend report page.
+func (s *Server) registerReportRoutes() {
+	s.mux.HandleFunc("GET /reports/weekly", s.handleWeeklyReport)
+}
+
+// reportMetric is one row of the weekly report's comparison table.
+type reportMetric struct {
+	Label  string
+	This   string
+	Last   string
+	Change string
+}
+
+// weeklyReportPageData is the template data for report_weekly.html.
+type weeklyReportPageData struct {
+	Since    string // start of the current week
+	Until    string
+	Metrics  []reportMetric
+	Services []report.ServiceTrend
+}
+
+// handleWeeklyReport compares the last seven days with the seven before.
+func (s *Server) handleWeeklyReport(w http.ResponseWriter, r *http.Request) {
+	rep, err := report.Weekly(s.db, time.Now())
+	if err != nil {
+		log.Printf("handleWeeklyReport: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	this, last := rep.This, rep.Last
+	duration := func(ms int64) string {
+		return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+	}
+	data := weeklyReportPageData{
+		Since:    rep.GeneratedAt.Add(-7 * 24 * time.Hour).Format("Jan 2"),
+		Until:    rep.GeneratedAt.Format("Jan 2"),
+		Services: rep.Services,
+		Metrics: []reportMetric{
+			{"Sessions", fmt.Sprint(this.Sessions), fmt.Sprint(last.Sessions),
+				report.Delta(float64(this.Sessions), float64(last.Sessions))},
+			{"Escalations", fmt.Sprint(this.Escalations), fmt.Sprint(last.Escalations),
+				report.Delta(float64(this.Escalations), float64(last.Escalations))},
+			{"Mean session cost", fmt.Sprintf("$%.2f", this.MeanCostUSD), fmt.Sprintf("$%.2f", last.MeanCostUSD),
+				report.Delta(this.MeanCostUSD, last.MeanCostUSD)},
+			{"Mean session duration", duration(this.MeanDurationMs), duration(last.MeanDurationMs),
+				report.Delta(float64(this.MeanDurationMs), float64(last.MeanDurationMs))},
+			{"New memories", fmt.Sprint(this.NewMemories), fmt.Sprint(last.NewMemories),
+				report.Delta(float64(this.NewMemories), float64(last.NewMemories))},
+			{"Decayed memories", fmt.Sprint(this.DecayedMemories), fmt.Sprint(last.DecayedMemories),
+				report.Delta(float64(this.DecayedMemories), float64(last.DecayedMemories))},
+			{"Remediation success", report.Rate(this.RemediationSuccessRate(), this.Remediations),
+				report.Rate(last.RemediationSuccessRate(), last.Remediations), ""},
+			{"Operator corrections", fmt.Sprint(this.Corrections), fmt.Sprint(last.Corrections),
+				report.Delta(float64(this.Corrections), float64(last.Corrections))},
+		},
+	}
+	s.render(w, r, "report_weekly.html", data)
+}
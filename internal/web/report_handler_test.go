@@ -0,0 +1,38 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestWeeklyReportPage(t *testing.T) {
+	e := newTestEnv(t)
+
+	w := getPage(e, "/reports/weekly")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "No escalations in either week") {
+		t.Errorf("expected empty escalations message:\n%s", w.Body.String())
+	}
+
+	id, err := e.srv.db.InsertSession(&db.Session{Tier: 2, Model: "sonnet", PromptFile: "/p", Status: "completed",
+		StartedAt: time.Now().UTC().Add(-time.Hour).Format(time.RFC3339), Trigger: "escalation"})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+	if err := e.srv.db.UpdateSessionServices(id, []string{"jellyfin"}); err != nil {
+		t.Fatalf("update session services: %v", err)
+	}
+
+	body := getPage(e, "/reports/weekly").Body.String()
+	for _, want := range []string{"Weekly Report", "Mean session cost", "Remediation success", "jellyfin"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// requestIDHeader carries the request ID in both directions: a caller may
+// supply its own, and every response echoes the one used.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds a caller-supplied request ID.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// withRequestID assigns every request an ID: the caller's X-Request-ID when
+// it is a sensible token, otherwise a random one. The ID is set on the
+// response before the handler runs, so writeError and handlers that only
+// have the ResponseWriter can still report it.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// validRequestID accepts IDs of letters, digits, and "-_.:", which covers
+// UUIDs and the IDs proxies such as Traefik and nginx generate, and keeps
+// anything that could forge log lines out.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestIDOf returns the ID withRequestID assigned to r, or "".
+func requestIDOf(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// tagSession records on session id the request that triggered it, so the
+// caller can find the session by its request ID.
+func (s *Server) tagSession(r *http.Request, id int64) {
+	s.tagSessionRequest(requestIDOf(r), id)
+}
+
+// tagSessionRequest records request ID rid on session id.
+func (s *Server) tagSessionRequest(rid string, id int64) {
+	if rid == "" {
+		return
+	}
+	log.Printf("request %s triggered session %d", rid, id)
+	if err := s.db.UpdateSessionRequestID(id, rid); err != nil {
+		log.Printf("tagSession: %v", err)
+	}
+}
+
+// followQueued completes claim and tags the session of a request that
+// TriggerAdHoc queued once the session starts, as the handler has returned
+// by then. It releases claim if the request was not queued or its session
+// never starts. claim may be nil.
+func (s *Server) followQueued(r *http.Request, claim *idempotencyClaim, err error) {
+	var queued *session.QueuedError
+	if !errors.As(err, &queued) {
+		claim.release()
+		return
+	}
+	rid := requestIDOf(r)
+	go func() {
+		id := <-queued.Started
+		if id == 0 {
+			claim.release()
+			return
+		}
+		claim.complete(id)
+		s.tagSessionRequest(rid, id)
+	}()
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDOnErrors(t *testing.T) {
+	e := newTestEnv(t)
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/sessions/999", nil)
+		if id != "" {
+			req.Header.Set(requestIDHeader, id)
+		}
+		w := httptest.NewRecorder()
+		e.srv.server.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("proxy-7f3a:1")
+	if got := w.Header().Get(requestIDHeader); got != "proxy-7f3a:1" {
+		t.Errorf("caller's request ID not echoed: %q", got)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusNotFound || body["request_id"] != "proxy-7f3a:1" {
+		t.Errorf("error response: %d %v", w.Code, body)
+	}
+
+	for _, bad := range []string{"", "two words", "forged\nlog line", strings.Repeat("a", maxRequestIDLen+1)} {
+		got := get(bad).Header().Get(requestIDHeader)
+		if got == "" || got == bad || !validRequestID(got) {
+			t.Errorf("request ID for %q: %q", bad, got)
+		}
+	}
+	if a, b := get("").Header().Get(requestIDHeader), get("").Header().Get(requestIDHeader); a == b {
+		t.Errorf("generated request IDs repeat: %s", a)
+	}
+}
+
+func TestRequestIDStoredOnTriggeredSession(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "running")
+	e.trigger.nextErr = nil
+	e.trigger.nextID = id
+
+	req := httptest.NewRequest("POST", "/api/v1/sessions/trigger", strings.NewReader(`{"prompt":"check postgres"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestIDHeader, "ci-run-42")
+	w := httptest.NewRecorder()
+	e.srv.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("trigger: %d %s", w.Code, w.Body.String())
+	}
+	var created APISession
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.RequestID == nil || *created.RequestID != "ci-run-42" {
+		t.Errorf("triggered session request ID: %v", created.RequestID)
+	}
+
+	insertTestSession(t, e, "completed")
+	w = httptest.NewRecorder()
+	e.srv.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/sessions?request_id=ci-run-42", nil))
+	var list APISessionsResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Sessions) != 1 || list.Sessions[0].ID != id {
+		t.Errorf("sessions for request: %+v", list.Sessions)
+	}
+}
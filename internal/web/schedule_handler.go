@@ -0,0 +1,129 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/scheduler"
+)
+
+// registerScheduleRoutes wires the next-run status endpoint and the
+// dashboard and API routes that start the next scheduled run early.
+func (s *Server) registerScheduleRoutes() {
+	s.mux.HandleFunc("POST /schedule/run-now", s.handleRunNow)
+	s.mux.HandleFunc("GET /api/v1/schedule", s.handleAPISchedule)
+	s.mux.HandleFunc("POST /api/v1/schedule/run-now", s.handleAPIRunNow)
+}
+
+var errRunNowUnavailable = errors.New("starting the scheduled run early is not available")
+
+// APISchedule is the JSON payload for GET /api/v1/schedule.
+type APISchedule struct {
+	// Waiting is true while the manager sleeps until NextRun; NextRun is
+	// null and RemainingSeconds 0 otherwise.
+	Waiting          bool    `json:"waiting"`
+	NextRun          *string `json:"next_run"`
+	RemainingSeconds int     `json:"remaining_seconds"`
+	IntervalSeconds  int     `json:"interval_seconds"`
+	// Cron is the cron expression runs follow instead of the interval
+	// ("" when they follow the interval).
+	Cron          string `json:"cron"`
+	JitterSeconds int    `json:"jitter_seconds"`
+	Running       bool   `json:"running"`
+	// Adaptive is the adaptive interval's state, or null when the interval
+	// is fixed.
+	Adaptive *scheduler.AdaptiveState `json:"adaptive"`
+}
+
+// scheduledRun returns when the manager's next scheduled run is due, and
+// false when it is not waiting for one.
+func (s *Server) scheduledRun() (time.Time, bool) {
+	if s.nextRun == nil {
+		return time.Time{}, false
+	}
+	return s.nextRun()
+}
+
+// adaptiveInterval returns the adaptive interval's state, or nil when the
+// interval is fixed.
+func (s *Server) adaptiveInterval() *scheduler.AdaptiveState {
+	if s.adaptive == nil {
+		return nil
+	}
+	return s.adaptive()
+}
+
+// nextRunEstimate returns the next scheduled run. While the manager is not
+// waiting for one (e.g. mid-run) it is estimated, without jitter, as the
+// cron schedule's next fire time or one (possibly adaptive) interval from
+// now.
+func (s *Server) nextRunEstimate() time.Time {
+	if next, ok := s.scheduledRun(); ok {
+		return next.UTC()
+	}
+	now := time.Now()
+	if s.cfg.Schedule != "" {
+		if c, err := scheduler.ParseCron(s.cfg.Schedule); err == nil {
+			if next := c.Next(now); !next.IsZero() {
+				return next.UTC()
+			}
+		}
+	}
+	interval := s.cfg.Interval
+	if a := s.adaptiveInterval(); a != nil {
+		interval = a.IntervalSeconds
+	}
+	return now.UTC().Add(time.Duration(interval) * time.Second)
+}
+
+// handleAPISchedule reports the manager's actual next scheduled run and the
+// time remaining until it.
+func (s *Server) handleAPISchedule(w http.ResponseWriter, r *http.Request) {
+	resp := APISchedule{
+		IntervalSeconds: s.cfg.Interval,
+		Cron:            s.cfg.Schedule,
+		JitterSeconds:   s.cfg.Jitter,
+		Running:         s.mgr.IsRunning(),
+		Adaptive:        s.adaptiveInterval(),
+	}
+	if resp.Adaptive != nil {
+		resp.IntervalSeconds = resp.Adaptive.IntervalSeconds
+	}
+	if next, ok := s.scheduledRun(); ok {
+		at := next.UTC().Format(time.RFC3339)
+		resp.Waiting = true
+		resp.NextRun = &at
+		if remaining := time.Until(next); remaining > 0 {
+			resp.RemainingSeconds = int(remaining.Round(time.Second) / time.Second)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) triggerRunNow() error {
+	if s.runNow == nil {
+		return errRunNowUnavailable
+	}
+	return s.runNow()
+}
+
+// handleAPIRunNow starts the next scheduled run instead of waiting for it.
+// It returns 202 without waiting for the run to start.
+func (s *Server) handleAPIRunNow(w http.ResponseWriter, r *http.Request) {
+	if err := s.triggerRunNow(); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+}
+
+// handleRunNow starts the next scheduled run from the dashboard, then
+// returns to it.
+func (s *Server) handleRunNow(w http.ResponseWriter, r *http.Request) {
+	if err := s.triggerRunNow(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/config"
+	"github.com/joestump/claude-ops/internal/scheduler"
+)
+
+func TestAPISchedule(t *testing.T) {
+	e := newTestEnv(t)
+
+	w := getPage(e, "/api/v1/schedule")
+	var idle APISchedule
+	_ = json.NewDecoder(w.Body).Decode(&idle)
+	if idle.Waiting || idle.NextRun != nil || idle.IntervalSeconds != e.srv.cfg.Interval {
+		t.Errorf("without a schedule: %+v", idle)
+	}
+	if body := getPage(e, "/").Body.String(); strings.Contains(body, `data-next-run="`) {
+		t.Error("index shows a countdown while no run is pending")
+	}
+
+	// Mid-run, the next run of a cron schedule is its next fire time.
+	e.srv.cfg.Schedule = "@daily"
+	w = getPage(e, "/api/v1/stats")
+	var midRun APIStatsResponse
+	_ = json.NewDecoder(w.Body).Decode(&midRun)
+	if next, _ := time.Parse(time.RFC3339, midRun.NextRun); next.Local().Hour() != 0 || next.Local().Minute() != 0 {
+		t.Errorf("stats next_run = %q, want the next midnight", midRun.NextRun)
+	}
+	w = getPage(e, "/api/v1/schedule")
+	_ = json.NewDecoder(w.Body).Decode(&idle)
+	if idle.Cron != "@daily" {
+		t.Errorf("cron = %q", idle.Cron)
+	}
+
+	next := time.Now().Add(90 * time.Second).UTC().Truncate(time.Second)
+	var runs int
+	e.srv.nextRun = func() (time.Time, bool) { return next, true }
+	e.srv.runNow = func() error {
+		runs++
+		if runs > 1 {
+			return errors.New("run already requested")
+		}
+		return nil
+	}
+
+	w = getPage(e, "/api/v1/schedule")
+	var sched APISchedule
+	_ = json.NewDecoder(w.Body).Decode(&sched)
+	if !sched.Waiting || sched.NextRun == nil || *sched.NextRun != next.Format(time.RFC3339) {
+		t.Errorf("schedule = %+v", sched)
+	}
+	if sched.RemainingSeconds < 85 || sched.RemainingSeconds > 90 {
+		t.Errorf("remaining = %d, want about 90", sched.RemainingSeconds)
+	}
+
+	w = getPage(e, "/api/v1/stats")
+	var stats APIStatsResponse
+	_ = json.NewDecoder(w.Body).Decode(&stats)
+	if stats.NextRun != next.Format(time.RFC3339) {
+		t.Errorf("stats next_run = %q, want the manager's %q", stats.NextRun, next.Format(time.RFC3339))
+	}
+	if body := getPage(e, "/").Body.String(); !strings.Contains(body, `data-next-run="`+next.Format(time.RFC3339)+`"`) || !strings.Contains(body, `action="/schedule/run-now"`) {
+		t.Error("index does not show the countdown and run-now button")
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/schedule/run-now", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/schedule/run-now", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("second run-now: expected 409, got %d", w.Code)
+	}
+}
+
+func TestAdaptiveIntervalShown(t *testing.T) {
+	e := newTestEnv(t)
+	if strings.Contains(getPage(e, "/").Body.String(), `id="adaptive-interval"`) {
+		t.Error("index shows an adaptive interval while the interval is fixed")
+	}
+
+	sched, err := scheduler.New(&config.Config{Interval: 3600, IntervalMax: 4 * 3600, AdaptiveRuns: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched.Observe(time.Now(), true, "")
+	e.srv.adaptive = sched.AdaptiveState
+
+	w := getPage(e, "/api/v1/schedule")
+	var resp APISchedule
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Adaptive == nil || resp.Adaptive.IntervalSeconds != 7200 || resp.IntervalSeconds != 7200 || resp.Adaptive.MaxSeconds != 4*3600 {
+		t.Errorf("schedule = %+v, adaptive = %+v", resp, resp.Adaptive)
+	}
+	body := getPage(e, "/").Body.String()
+	if !strings.Contains(body, `id="adaptive-interval"`) || !strings.Contains(body, "stretched from 1h to 2h") {
+		t.Error("index does not show the adaptive interval and why")
+	}
+}
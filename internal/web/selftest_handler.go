@@ -0,0 +1,224 @@
+package web
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// registerSelfTestRoutes wires the self-test drill pages, the canary
+// endpoints the agent exercises during a drill (also on the agent
+// listener), and the drill API.
+func (s *Server) registerSelfTestRoutes() {
+	s.mux.HandleFunc("GET /selftest", s.handleSelfTest)
+	s.mux.HandleFunc("POST /selftest/run", s.handleSelfTestRun)
+	s.handleAgent("GET /selftest/canary", s.handleCanaryHealth)
+	s.handleAgent("POST /api/v1/selftest/canary/restart", s.handleAPICanaryRestart)
+	s.handleAgent("POST /api/v1/selftest/notify", s.handleAPICanaryNotify)
+	s.mux.HandleFunc("GET /api/v1/selftest/drills", s.handleAPIListDrills)
+	s.mux.HandleFunc("POST /api/v1/selftest/drills", s.handleAPITriggerDrill)
+}
+
+// drillListLimit bounds the drill history shown and returned.
+const drillListLimit = 50
+
+var errDrillsUnavailable = errors.New("self-test drills are not available")
+
+// DrillView is a template-friendly representation of a db.Drill.
+type DrillView struct {
+	ID         int64
+	Status     string
+	SessionID  *int64
+	MaxTier    int
+	Detected   bool
+	Remediated bool
+	Notified   bool
+	Detail     string
+	StartedAt  time.Time
+	EndedAt    *time.Time
+}
+
+// ToDrillView converts a db.Drill to a DrillView.
+func ToDrillView(d db.Drill) DrillView {
+	v := DrillView{
+		ID:         d.ID,
+		Status:     d.Status,
+		SessionID:  d.SessionID,
+		MaxTier:    d.MaxTier,
+		Detected:   d.Detected,
+		Remediated: d.Remediated,
+		Notified:   d.Notified,
+	}
+	if d.Detail != nil {
+		v.Detail = *d.Detail
+	}
+	if t, err := time.Parse(timeFormat, d.StartedAt); err == nil {
+		v.StartedAt = t
+	}
+	if d.EndedAt != nil {
+		if t, err := time.Parse(timeFormat, *d.EndedAt); err == nil {
+			v.EndedAt = &t
+		}
+	}
+	return v
+}
+
+// APIDrill is the JSON representation of a self-test drill.
+type APIDrill struct {
+	ID         int64   `json:"id"`
+	Status     string  `json:"status"`
+	SessionID  *int64  `json:"session_id"`
+	MaxTier    int     `json:"max_tier"`
+	Detected   bool    `json:"detected"`
+	Remediated bool    `json:"remediated"`
+	Notified   bool    `json:"notified"`
+	Detail     *string `json:"detail"`
+	StartedAt  string  `json:"started_at"`
+	EndedAt    *string `json:"ended_at"`
+}
+
+// APIDrillsResponse wraps the drill list for GET /api/v1/selftest/drills.
+type APIDrillsResponse struct {
+	Drills []APIDrill `json:"drills"`
+}
+
+// selfTestPageData is the template data for selftest.html.
+type selfTestPageData struct {
+	Drills   []DrillView
+	Interval int // hours between scheduled drills; 0 when only manual drills run
+	Queued   bool
+	Error    string
+}
+
+func toAPIDrill(d db.Drill) APIDrill {
+	return APIDrill{
+		ID:         d.ID,
+		Status:     d.Status,
+		SessionID:  d.SessionID,
+		MaxTier:    d.MaxTier,
+		Detected:   d.Detected,
+		Remediated: d.Remediated,
+		Notified:   d.Notified,
+		Detail:     d.Detail,
+		StartedAt:  d.StartedAt,
+		EndedAt:    d.EndedAt,
+	}
+}
+
+// handleSelfTest renders the drill history.
+func (s *Server) handleSelfTest(w http.ResponseWriter, r *http.Request) {
+	s.renderSelfTest(w, r, false, "")
+}
+
+func (s *Server) renderSelfTest(w http.ResponseWriter, r *http.Request, queued bool, errMsg string) {
+	drills, err := s.db.ListDrills(drillListLimit)
+	if err != nil {
+		log.Printf("handleSelfTest: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	data := selfTestPageData{
+		Interval: s.cfg.SelfTestInterval,
+		Queued:   queued,
+		Error:    errMsg,
+	}
+	for _, d := range drills {
+		data.Drills = append(data.Drills, ToDrillView(d))
+	}
+	s.render(w, r, "selftest.html", data)
+}
+
+// handleSelfTestRun queues a drill from the dashboard and re-renders the page.
+func (s *Server) handleSelfTestRun(w http.ResponseWriter, r *http.Request) {
+	if err := s.triggerDrill(); err != nil {
+		s.renderSelfTest(w, r, false, "Could not start drill: "+err.Error())
+		return
+	}
+	s.renderSelfTest(w, r, true, "")
+}
+
+func (s *Server) triggerDrill() error {
+	if s.drillTrigger == nil {
+		return errDrillsUnavailable
+	}
+	return s.drillTrigger()
+}
+
+// handleCanaryHealth is the canary's health endpoint: 503 while a drill is
+// running and the canary has not been restarted, 200 otherwise.
+func (s *Server) handleCanaryHealth(w http.ResponseWriter, r *http.Request) {
+	d, err := s.db.GetRunningDrill()
+	if err != nil {
+		log.Printf("handleCanaryHealth: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if d != nil && !d.Remediated {
+		http.Error(w, "canary down (self-test drill in progress)", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = io.WriteString(w, "ok\n")
+}
+
+// handleAPICanaryRestart is the canary's remediation action.
+func (s *Server) handleAPICanaryRestart(w http.ResponseWriter, r *http.Request) {
+	s.markDrill(w, func(d *db.Drill) { d.Remediated = true })
+}
+
+// handleAPICanaryNotify receives the agent's drill notification (an Apprise
+// json:// URL pointing here). The payload is not inspected.
+func (s *Server) handleAPICanaryNotify(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(r.Body, 64<<10))
+	s.markDrill(w, func(d *db.Drill) { d.Notified = true })
+}
+
+// markDrill applies mark to the running drill. Returns 409 when no drill is
+// running.
+func (s *Server) markDrill(w http.ResponseWriter, mark func(*db.Drill)) {
+	d, err := s.db.GetRunningDrill()
+	if err != nil {
+		log.Printf("markDrill: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if d == nil {
+		writeError(w, http.StatusConflict, "no self-test drill running")
+		return
+	}
+	mark(d)
+	if err := s.db.UpdateDrill(d); err != nil {
+		log.Printf("markDrill: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	writeJSON(w, http.StatusOK, toAPIDrill(*d))
+}
+
+// handleAPIListDrills returns the most recent drills, newest first.
+func (s *Server) handleAPIListDrills(w http.ResponseWriter, r *http.Request) {
+	drills, err := s.db.ListDrills(drillListLimit)
+	if err != nil {
+		log.Printf("handleAPIListDrills: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	out := make([]APIDrill, len(drills))
+	for i, d := range drills {
+		out[i] = toAPIDrill(d)
+	}
+	writeJSON(w, http.StatusOK, APIDrillsResponse{Drills: out})
+}
+
+// handleAPITriggerDrill queues a drill. It returns 202 without waiting for
+// the drill to start.
+func (s *Server) handleAPITriggerDrill(w http.ResponseWriter, r *http.Request) {
+	if err := s.triggerDrill(); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+}
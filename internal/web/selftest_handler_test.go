@@ -0,0 +1,106 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestCanaryEndpoints(t *testing.T) {
+	e := newTestEnv(t)
+
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/selftest/canary", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected healthy canary outside a drill, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/selftest/canary/restart", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without a running drill, got %d", w.Code)
+	}
+
+	id, err := e.srv.db.InsertDrill(&db.Drill{Status: "running", StartedAt: time.Now().UTC().Format(time.RFC3339)})
+	if err != nil {
+		t.Fatalf("InsertDrill: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/selftest/canary", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected failing canary during a drill, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/selftest/canary/restart", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("restart: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/selftest/notify",
+		strings.NewReader(`{"title":"[DRILL] Auto-remediated claudeops-canary","message":"restarted","type":"info"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("notify: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/selftest/canary", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected canary healthy after restart, got %d", w.Code)
+	}
+	d, _ := e.srv.db.GetDrill(id)
+	if d == nil || !d.Remediated || !d.Notified || d.Status != "running" {
+		t.Errorf("unexpected drill %+v", d)
+	}
+}
+
+func TestSelfTestDrills(t *testing.T) {
+	e := newTestEnv(t)
+	var triggered int
+	e.srv.drillTrigger = func() error {
+		triggered++
+		if triggered > 1 {
+			return errors.New("drill already queued")
+		}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/selftest/drills", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("POST", "/selftest/run", nil))
+	if !strings.Contains(w.Body.String(), "Could not start drill: drill already queued") {
+		t.Errorf("expected error on the page:\n%s", w.Body.String())
+	}
+
+	detail := "no notification reached the canary"
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := e.srv.db.InsertDrill(&db.Drill{Status: "failed", MaxTier: 2, Detected: true, Remediated: true, Detail: &detail, StartedAt: now, EndedAt: &now}); err != nil {
+		t.Fatalf("InsertDrill: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/selftest", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), detail) {
+		t.Fatalf("expected drill on page, got %d:\n%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/selftest/drills", nil))
+	var resp APIDrillsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Drills) != 1 || resp.Drills[0].Status != "failed" || resp.Drills[0].Notified {
+		t.Errorf("unexpected drills %+v", resp.Drills)
+	}
+}
@@ -9,14 +9,27 @@ import (
 	"io/fs"
 	"log"
 	"net/http"
+	"net/url"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/joestump/claude-ops/api"
+	"github.com/joestump/claude-ops/internal/certs"
 	"github.com/joestump/claude-ops/internal/config"
 	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/graphql"
+	"github.com/joestump/claude-ops/internal/hub"
+	"github.com/joestump/claude-ops/internal/i18n"
 	"github.com/joestump/claude-ops/internal/models"
+	"github.com/joestump/claude-ops/internal/offload"
+	"github.com/joestump/claude-ops/internal/policy"
+	"github.com/joestump/claude-ops/internal/proxmox"
+	"github.com/joestump/claude-ops/internal/scheduler"
+	"github.com/joestump/claude-ops/internal/servicename"
+	"github.com/joestump/claude-ops/internal/session"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
 )
@@ -42,9 +55,10 @@ var templateFS embed.FS
 //go:embed static/*
 var staticFS embed.FS
 
-// SSEHub is the interface the web server uses to subscribe to session streams.
-type SSEHub interface {
-	Subscribe(sessionID int) (<-chan string, func())
+// EventBus is the interface the web server uses to subscribe to session
+// streams, each consumer filtering to the event kinds it needs.
+type EventBus interface {
+	Subscribe(sessionID int, kinds hub.Kind) (<-chan hub.Event, func())
 }
 
 // SessionTrigger is the interface for triggering ad-hoc sessions.
@@ -58,37 +72,151 @@ type SessionTrigger interface {
 // ServerOption configures optional Server features.
 type ServerOption func(*Server)
 
-// WithRawHub sets the raw NDJSON event hub for OpenAI streaming.
-// Governing: SPEC-0024 REQ-5 (Streaming Response), ADR-0020
-func WithRawHub(h SSEHub) ServerOption {
-	return func(s *Server) { s.rawHub = h }
+// WithDrillTrigger sets the function that queues a self-test drill.
+func WithDrillTrigger(fn func() error) ServerOption {
+	return func(s *Server) { s.drillTrigger = fn }
+}
+
+// WithChainResume sets the function that queues the escalation chain the
+// last shutdown interrupted, from where it stopped or, with rerun, from the
+// start.
+func WithChainResume(fn func(rerun bool) error) ServerOption {
+	return func(s *Server) { s.chainResume = fn }
+}
+
+// WithApprovals sets the functions that approve and reject held two-person
+// remediations on behalf of an approver.
+func WithApprovals(approve, reject func(id int64, approver string) error) ServerOption {
+	return func(s *Server) { s.approve, s.reject = approve, reject }
+}
+
+// WithSimulator sets the function that works out what the supervisor would
+// do with a hypothetical handoff.
+func WithSimulator(fn func(session.SimulationRequest) (*session.Simulation, error)) ServerOption {
+	return func(s *Server) { s.simulate = fn }
+}
+
+// WithSchedule sets the functions that report when the next scheduled run
+// is due and start it early.
+func WithSchedule(next func() (time.Time, bool), runNow func() error) ServerOption {
+	return func(s *Server) { s.nextRun, s.runNow = next, runNow }
+}
+
+// WithAdaptiveInterval sets the function that reports the adaptive
+// interval's state (nil when the interval is not adaptive).
+func WithAdaptiveInterval(fn func() *scheduler.AdaptiveState) ServerOption {
+	return func(s *Server) { s.adaptive = fn }
+}
+
+// WithLiveSession sets the function that reports the running session's
+// progress for the live incident banner.
+func WithLiveSession(fn func() *session.LiveSession) ServerOption {
+	return func(s *Server) { s.live = fn }
+}
+
+// WithOffload reads session logs moved to object storage through o.
+func WithOffload(o *offload.Offloader) ServerOption {
+	return func(s *Server) { s.logs = o }
+}
+
+// WithAgentNotify passes the notifications the agent sends to the notify
+// endpoint to fn, the notification digest (nil when it is disabled).
+func WithAgentNotify(fn func(title, body string)) ServerOption {
+	return func(s *Server) { s.agentNotify = fn }
+}
+
+// WithPolicy evaluates the operator's guardrail rules before cooldown-limited
+// actions the API takes itself, such as guest power actions.
+func WithPolicy(e *policy.Engine) ServerOption {
+	return func(s *Server) { s.policy = e }
+}
+
+// WithTLS serves the dashboard over HTTPS with the certificates from c.
+func WithTLS(c *certs.Manager) ServerOption {
+	return func(s *Server) { s.certs = c }
 }
 
 // Governing: SPEC-0008 REQ-2 (Web Server — HTTP on configurable port, default 8080)
 // Server is the HTTP server for the Claude Ops dashboard.
 type Server struct {
-	cfg    *config.Config
-	hub    SSEHub
-	rawHub SSEHub // Governing: SPEC-0024 REQ-5 — raw NDJSON event hub for OpenAI streaming
-	db     *db.DB
-	mgr    SessionTrigger
-	mux    *http.ServeMux
-	tmpl   *template.Template
-	server *http.Server
+	cfg  *config.Config
+	hub  EventBus // Governing: SPEC-0024 REQ-5 — also carries raw NDJSON for OpenAI streaming
+	db   *db.DB
+	mgr  SessionTrigger
+	mux  *http.ServeMux
+	tmpl *template.Template
+	// tmplByLang holds a template set per dashboard language, each with its
+	// own "t" translation func; tmpl is the default language's set.
+	tmplByLang map[string]*template.Template
+	server     *http.Server
 	// Governing: SPEC-0035 — discovers models from the upstream gateway (ANTHROPIC_BASE_URL).
 	discoverer *models.Discoverer
+	// hypervisor is the Proxmox client for guest inventory and power actions (nil when not configured).
+	hypervisor *proxmox.Client
+	// policy holds the operator's guardrail rules (nil allows everything).
+	policy *policy.Engine
+	// services canonicalises service names for the merge tool.
+	services *servicename.Normalizer
+	// drillTrigger queues a self-test drill (nil when drills are unavailable).
+	drillTrigger func() error
+	// agentNotify takes the agent's notifications (nil without a digest).
+	agentNotify func(title, body string)
+	// chainResume queues an interrupted escalation chain (nil when unavailable).
+	chainResume func(rerun bool) error
+	// approve and reject resolve two-person approval requests (nil when unavailable).
+	approve func(id int64, approver string) error
+	reject  func(id int64, approver string) error
+	// simulate dry-runs escalation decisions (nil when unavailable).
+	simulate func(session.SimulationRequest) (*session.Simulation, error)
+	// nextRun and runNow report and short-circuit the wait for the next
+	// scheduled run (nil when unavailable).
+	nextRun func() (time.Time, bool)
+	runNow  func() error
+	// adaptive reports the adaptive interval (nil when unavailable).
+	adaptive func() *scheduler.AdaptiveState
+	// live reports the running session's progress (nil when unavailable).
+	live func() *session.LiveSession
+	// logs opens session logs, including those moved to object storage
+	// (nil reads local logs only).
+	logs *offload.Offloader
+	// certs provides the HTTPS certificate (nil serves plain HTTP), and
+	// challenges answers ACME HTTP-01 challenges and redirects to HTTPS
+	// (nil when not needed).
+	certs      *certs.Manager
+	challenges *http.Server
+	// agentMux holds the routes the agent calls back into; agent serves
+	// them on the loopback-only CLAUDEOPS_AGENT_PORT (nil when it is 0).
+	// CLAUDEOPS_READONLY_UI does not apply there, as only the agent reaches it.
+	agentMux *http.ServeMux
+	agent    *http.Server
+	// briefMu guards brief, the cached spoken status brief, and briefAt, when
+	// it was generated.
+	briefMu sync.Mutex
+	brief   *APIBrief
+	briefAt time.Time
+	// gql is the read-only GraphQL schema served at /api/v1/graphql.
+	gql *graphql.Schema
+	// idempotency maps the Idempotency-Key of session-triggering requests
+	// to the session they triggered.
+	idempotency *idempotencyCache
+	// guestLocks holds a *sync.Mutex per guest action target, the service
+	// its cooldowns are recorded against.
+	guestLocks sync.Map
 }
 
-// New creates a new web server. Pass nil for hub if SSE streaming is not yet available.
-// rawHub is the raw NDJSON event hub for OpenAI streaming (may be nil).
-func New(cfg *config.Config, hub SSEHub, database *db.DB, mgr SessionTrigger, opts ...ServerOption) *Server {
+// New creates a new web server. Pass nil for bus if SSE streaming is not yet available.
+func New(cfg *config.Config, bus EventBus, database *db.DB, mgr SessionTrigger, opts ...ServerOption) *Server {
 	s := &Server{
 		cfg: cfg,
-		hub: hub,
+		hub: bus,
 		db:  database,
 		mgr: mgr,
 		mux: http.NewServeMux(),
+
+		agentMux:    http.NewServeMux(),
+		idempotency: newIdempotencyCache(),
 	}
+	s.gql = s.graphQLSchema()
 	for _, opt := range opts {
 		opt(s)
 	}
@@ -97,26 +225,90 @@ func New(cfg *config.Config, hub SSEHub, database *db.DB, mgr SessionTrigger, op
 	// resolve the upstream endpoint/credential lazily from the environment so
 	// changes are picked up without a restart and the key is never stored.
 	s.discoverer = models.New(upstreamBaseURL, upstreamAPIKey)
+	s.hypervisor = proxmox.FromConfig(cfg)
+	s.services = servicename.FromConfig(cfg, database)
 
 	s.parseTemplates()
 	s.registerRoutes()
 	s.registerModelRoutes()
+	s.registerHypervisorRoutes()
+	s.registerKBRoutes()
+	s.registerSelfTestRoutes()
+	s.registerAgentNotifyRoutes()
+	s.registerPromptRoutes()
+	s.registerHistoryRoutes()
+	s.registerAdminRoutes()
+	s.registerReportRoutes()
+	s.registerRemediationRoutes()
+	s.registerFeedbackRoutes()
+	s.registerBriefRoutes()
+	s.registerScheduleRoutes()
+	s.registerMemoryBulkRoutes()
+	s.registerMemoryCategoryRoutes()
+	s.registerMemoryScoringRoutes()
 
 	s.server = &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.DashboardPort),
-		Handler:      s.mux,
+		Handler:      withRequestID(s.readOnlyGuard(s.mux)),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 0, // SSE needs no write timeout
 		IdleTimeout:  60 * time.Second,
 	}
+	if cfg.AgentPort > 0 {
+		s.agent = &http.Server{
+			Addr:        fmt.Sprintf("127.0.0.1:%d", cfg.AgentPort),
+			Handler:     withRequestID(s.agentMux),
+			ReadTimeout: 15 * time.Second,
+			IdleTimeout: 60 * time.Second,
+		}
+	}
+	if s.certs != nil {
+		s.server.TLSConfig = s.certs.TLSConfig()
+		if port := s.certs.HTTPPort(); port > 0 {
+			s.challenges = &http.Server{
+				Addr:        fmt.Sprintf(":%d", port),
+				Handler:     s.certs.HTTPHandler(cfg.DashboardPort),
+				ReadTimeout: 15 * time.Second,
+				IdleTimeout: 60 * time.Second,
+			}
+		}
+	}
 
 	return s
 }
 
 // Start begins serving HTTP requests. It blocks until the server is shut down.
 func (s *Server) Start() error {
-	log.Printf("dashboard listening on %s", s.server.Addr)
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	ln, addr, err := s.listen()
+	if err != nil {
+		return err
+	}
+	if s.agent != nil {
+		go func() {
+			log.Printf("agent API listening on %s", s.agent.Addr)
+			if err := s.agent.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("agent API listener: %v", err)
+			}
+		}()
+	}
+	if s.certs == nil {
+		log.Printf("dashboard listening on %s", addr)
+		if err := s.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	}
+
+	if s.challenges != nil {
+		go func() {
+			log.Printf("ACME challenges and HTTPS redirect listening on %s", s.challenges.Addr)
+			if err := s.challenges.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("ACME challenge listener: %v", err)
+			}
+		}()
+	}
+	log.Printf("dashboard listening on %s (HTTPS)", addr)
+	if err := s.server.ServeTLS(ln, "", ""); err != nil && err != http.ErrServerClosed {
 		return err
 	}
 	return nil
@@ -124,11 +316,23 @@ func (s *Server) Start() error {
 
 // Shutdown gracefully stops the server.
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.challenges != nil {
+		if err := s.challenges.Shutdown(ctx); err != nil {
+			log.Printf("ACME challenge listener shutdown: %v", err)
+		}
+	}
+	if s.agent != nil {
+		if err := s.agent.Shutdown(ctx); err != nil {
+			log.Printf("agent API listener shutdown: %v", err)
+		}
+	}
 	return s.server.Shutdown(ctx)
 }
 
 func (s *Server) parseTemplates() {
 	funcMap := template.FuncMap{
+		// readOnly hides the controls that CLAUDEOPS_READONLY_UI disables.
+		"readOnly": func() bool { return s.cfg.ReadOnlyUI },
 		"fmtTime": func(t time.Time) string {
 			return t.Format("2006-01-02 15:04:05 UTC")
 		},
@@ -147,11 +351,11 @@ func (s *Server) parseTemplates() {
 		},
 		"statusClass": func(status string) string {
 			switch status {
-			case "healthy", "completed":
+			case "healthy", "completed", "passed", "remediated":
 				return "status-healthy"
-			case "degraded", "escalated":
+			case "degraded", "escalated", "continued", "interrupted", "issues-observed":
 				return "status-degraded"
-			case "down", "failed", "timed_out":
+			case "down", "failed", "timed_out", "reopened", "remediation-failed":
 				return "status-down"
 			case "running":
 				return "status-running"
@@ -163,11 +367,11 @@ func (s *Server) parseTemplates() {
 		},
 		"statusDot": func(status string) string {
 			switch status {
-			case "healthy", "completed":
+			case "healthy", "completed", "passed", "remediated":
 				return "dot-healthy"
-			case "degraded", "escalated":
+			case "degraded", "escalated", "continued", "interrupted", "issues-observed":
 				return "dot-degraded"
-			case "down", "failed", "timed_out":
+			case "down", "failed", "timed_out", "reopened", "remediation-failed":
 				return "dot-down"
 			case "running":
 				return "dot-running"
@@ -179,11 +383,11 @@ func (s *Server) parseTemplates() {
 		},
 		"statusText": func(status string) string {
 			switch status {
-			case "healthy", "completed":
+			case "healthy", "completed", "passed", "remediated":
 				return "text-green"
-			case "degraded", "escalated":
+			case "degraded", "escalated", "continued", "interrupted", "issues-observed":
 				return "text-yellow"
-			case "down", "failed", "timed_out":
+			case "down", "failed", "timed_out", "reopened", "remediation-failed":
 				return "text-red"
 			case "running":
 				return "text-blue"
@@ -203,6 +407,7 @@ func (s *Server) parseTemplates() {
 				return fmt.Sprintf("Tier %d", tier)
 			}
 		},
+		"baseName": filepath.Base,
 		"sub": func(a, b int) int {
 			return a - b
 		},
@@ -348,6 +553,34 @@ func (s *Server) parseTemplates() {
 			}
 			return d.Truncate(time.Second).String()
 		},
+		"fmtTokens": func(p *int64) string {
+			if p == nil {
+				return "--"
+			}
+			if *p < 1000 {
+				return fmt.Sprintf("%d tokens", *p)
+			}
+			return fmt.Sprintf("%.1fk tokens", float64(*p)/1000)
+		},
+		"fmtBytes": func(n int64) string {
+			switch {
+			case n >= 1<<30:
+				return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+			case n >= 1<<20:
+				return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+			case n >= 1<<10:
+				return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+			}
+			return fmt.Sprintf("%d B", n)
+		},
+		// fmtRate formats a 0–1 rate as a percentage, or "--" for a negative
+		// rate (nothing to rate yet).
+		"fmtRate": func(rate float64) string {
+			if rate < 0 {
+				return "--"
+			}
+			return fmt.Sprintf("%.0f%%", rate*100)
+		},
 		"fmtMsVal": func(ms int64) string {
 			if ms == 0 {
 				return "--"
@@ -373,9 +606,74 @@ func (s *Server) parseTemplates() {
 		},
 	}
 
-	s.tmpl = template.Must(
-		template.New("").Funcs(funcMap).ParseFS(templateFS, "templates/*.html"),
+	// Templates are parsed once, then cloned per language (before any
+	// execution, which html/template requires) to bind that language's
+	// translation funcs.
+	base := template.Must(
+		template.New("").Funcs(funcMap).Funcs(langFuncs(i18n.Default)).ParseFS(templateFS, "templates/*.html"),
 	)
+	s.tmplByLang = make(map[string]*template.Template)
+	for _, lang := range i18n.Languages() {
+		s.tmplByLang[lang] = template.Must(base.Clone()).Funcs(langFuncs(lang))
+	}
+	s.tmpl = s.tmplByLang[i18n.Default]
+}
+
+// langFuncs returns the template funcs that depend on the page language.
+func langFuncs(lang string) template.FuncMap {
+	return template.FuncMap{
+		// t translates a dashboard string: {{t "Sessions"}} or
+		// {{t "%d matches" .Count}}.
+		"t": func(msg string, args ...any) string {
+			return i18n.T(lang, msg, args...)
+		},
+	}
+}
+
+// langCookie remembers a language picked in the dashboard over the
+// browser's Accept-Language.
+const langCookie = "claudeops_lang"
+
+// requestLang returns the dashboard language for a request.
+func requestLang(r *http.Request) string {
+	if c, err := r.Cookie(langCookie); err == nil && i18n.Supported(c.Value) {
+		return c.Value
+	}
+	return i18n.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// templates returns the template set for the request's language.
+func (s *Server) templates(r *http.Request) *template.Template {
+	return s.tmplByLang[requestLang(r)]
+}
+
+// handleSetLanguage stores the picked language and returns to the page the
+// picker was used on.
+func (s *Server) handleSetLanguage(w http.ResponseWriter, r *http.Request) {
+	lang := r.PathValue("lang")
+	if !i18n.Supported(lang) {
+		http.Error(w, "unsupported language", http.StatusNotFound)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     langCookie,
+		Value:    lang,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		SameSite: http.SameSiteLaxMode,
+	})
+	target := "/"
+	if ref, err := url.Parse(r.Referer()); err == nil && ref.Host == r.Host && ref.Path != "" {
+		target = ref.RequestURI()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// languageOption is one entry in the layout's language picker.
+type languageOption struct {
+	Code   string
+	Name   string
+	Active bool
 }
 
 // Governing: SPEC-0008 REQ-14 — Static Asset Embedding (static files served from embed.FS)
@@ -405,16 +703,29 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("GET /sessions", s.handleSessions)
 	s.mux.HandleFunc("GET /sessions/{id}", s.handleSession)
 	s.mux.HandleFunc("GET /sessions/{id}/stream", s.handleSessionStream)
+	s.mux.HandleFunc("GET /sessions/{id}/chain/stream", s.handleChainStream)
+	s.mux.HandleFunc("GET /sessions/{id}/log/{line}", s.handleSessionLogLine)
+	s.mux.HandleFunc("GET /sessions/{id}/search", s.handleSessionSearch)
 	s.mux.HandleFunc("POST /sessions/{id}/stop", s.handleStopSession)
+	s.mux.HandleFunc("POST /chains/interrupted/{action}", s.handleInterruptedChain)
+	s.mux.HandleFunc("POST /approvals/{id}/{action}", s.handleApproval)
+	s.mux.HandleFunc("POST /sessions/{id}/changes/{action}", s.handleChangeReport)
 	s.mux.HandleFunc("GET /events", s.handleEvents)
+	s.mux.HandleFunc("GET /events.csv", s.handleEventsCSV)
 	s.mux.HandleFunc("GET /memories", s.handleMemories)
 	s.mux.HandleFunc("POST /memories", s.handleMemoryCreate)
 	s.mux.HandleFunc("POST /memories/{id}/update", s.handleMemoryUpdate)
 	s.mux.HandleFunc("POST /memories/{id}/delete", s.handleMemoryDelete)
+	s.mux.HandleFunc("POST /memories/{id}/approve", s.handleMemoryApprove)
+	s.mux.HandleFunc("POST /memories/{id}/reject", s.handleMemoryReject)
+	s.mux.HandleFunc("POST /memories/{id}/restore", s.handleMemoryRestore)
+	s.mux.HandleFunc("POST /memories/{id}/purge", s.handleMemoryPurge)
 	s.mux.HandleFunc("GET /cooldowns", s.handleCooldowns)
 	s.mux.HandleFunc("GET /config", s.handleConfigGet)
+	s.mux.HandleFunc("GET /lang/{lang}", s.handleSetLanguage)
 	s.mux.HandleFunc("POST /config", s.handleConfigPost)
 	s.mux.HandleFunc("POST /sessions/trigger", s.handleTriggerSession)
+	s.mux.HandleFunc("POST /sessions/estimate", s.handleRunEstimate)
 
 	// API v1
 	// Governing: SPEC-0017 REQ-1 "API Route Registration" — all /api/v1/ routes on same ServeMux
@@ -423,17 +734,31 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("GET /api/v1/health", s.handleAPIHealth)
 	// Governing: SPEC-0021 REQ "Dashboard Stats HUD" — TL;DR HUD metrics for external dashboards (e.g. Homepage)
 	s.mux.HandleFunc("GET /api/v1/stats", s.handleAPIStats)
+	// The live incident banner polls the running session's progress.
+	s.mux.HandleFunc("GET /api/v1/live", s.handleAPILive)
 	// Governing: SPEC-0017 REQ-3, REQ-4, REQ-5 — session list, detail, and trigger endpoints
 	s.mux.HandleFunc("GET /api/v1/sessions", s.handleAPIListSessions)
 	s.mux.HandleFunc("GET /api/v1/sessions/{id}", s.handleAPIGetSession)
 	s.mux.HandleFunc("POST /api/v1/sessions/trigger", s.handleAPITriggerSession)
+	// Links from a session to issues in GitHub, Gitea, or Jira.
+	s.mux.HandleFunc("GET /api/v1/sessions/{id}/integrations", s.handleAPIListTickets)
+	s.mux.HandleFunc("POST /api/v1/sessions/{id}/integrations", s.handleAPILinkTicket)
+	s.mux.HandleFunc("DELETE /api/v1/sessions/{id}/integrations/{ticket}", s.handleAPIUnlinkTicket)
 	// Governing: SPEC-0017 REQ-6 through REQ-11 — events, memories CRUD, and cooldowns endpoints
 	s.mux.HandleFunc("GET /api/v1/events", s.handleAPIListEvents)
+	s.mux.HandleFunc("GET /api/v1/notifications/stream", s.handleNotificationStream)
 	s.mux.HandleFunc("GET /api/v1/memories", s.handleAPIListMemories)
 	s.mux.HandleFunc("POST /api/v1/memories", s.handleAPICreateMemory)
 	s.mux.HandleFunc("PUT /api/v1/memories/{id}", s.handleAPIUpdateMemory)
 	s.mux.HandleFunc("DELETE /api/v1/memories/{id}", s.handleAPIDeleteMemory)
+	s.mux.HandleFunc("GET /api/v1/memories/rejected", s.handleAPIListRejectedMemories)
+	s.mux.HandleFunc("POST /api/v1/memories/{id}/restore", s.handleAPIRestoreMemory)
 	s.mux.HandleFunc("GET /api/v1/cooldowns", s.handleAPIListCooldowns)
+	// Read-only GraphQL over the same records, loading relations in batches.
+	s.mux.HandleFunc("GET /api/v1/graphql", s.handleGraphQL)
+	s.mux.HandleFunc("POST /api/v1/graphql", s.handleGraphQL)
+	s.mux.HandleFunc("GET /api/v1/graphql/schema", s.handleGraphQLSchema)
+	s.mux.HandleFunc("POST /api/v1/simulate", s.handleAPISimulate)
 	// Governing: SPEC-0017 REQ-12 "Config Get Endpoint", REQ-13 "Config Update Endpoint"
 	s.mux.HandleFunc("GET /api/v1/config", s.handleAPIGetConfig)
 	s.mux.HandleFunc("PUT /api/v1/config", s.handleAPIUpdateConfig)
@@ -467,10 +792,14 @@ func (s *Server) registerRoutes() {
 // Governing: SPEC-0008 REQ-3 — HTMX-Based Interactivity (partial rendering for HX-Request)
 func (s *Server) render(w http.ResponseWriter, r *http.Request, name string, data any) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	lang := requestLang(r)
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language, Cookie")
+	tmpl := s.tmplByLang[lang]
 
 	// Render the content template to a buffer.
 	var buf bytes.Buffer
-	if err := s.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
 		log.Printf("template %s: %v", name, err)
 		http.Error(w, "template error", http.StatusInternalServerError)
 		return
@@ -481,16 +810,26 @@ func (s *Server) render(w http.ResponseWriter, r *http.Request, name string, dat
 		return
 	}
 
+	var languages []languageOption
+	for _, code := range i18n.Languages() {
+		languages = append(languages, languageOption{Code: code, Name: i18n.Name(code), Active: code == lang})
+	}
 	layoutData := struct {
-		Page    string
-		Content template.HTML
-		Version string
+		Page      string
+		Content   template.HTML
+		Version   string
+		Lang      string
+		Languages []languageOption
+		Demo      bool
 	}{
-		Page:    name,
-		Content: template.HTML(buf.String()),
-		Version: config.Version,
+		Page:      name,
+		Content:   template.HTML(buf.String()),
+		Version:   config.Version,
+		Lang:      lang,
+		Languages: languages,
+		Demo:      s.cfg.Demo,
 	}
-	if err := s.tmpl.ExecuteTemplate(w, "layout.html", layoutData); err != nil {
+	if err := tmpl.ExecuteTemplate(w, "layout.html", layoutData); err != nil {
 		log.Printf("layout+%s: %v", name, err)
 		http.Error(w, "template error", http.StatusInternalServerError)
 	}
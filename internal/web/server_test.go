@@ -46,7 +46,6 @@ func (m *mockTrigger) Stop() bool      { return m.running }
 type testEnv struct {
 	srv     *Server
 	hub     *hub.Hub
-	rawHub  *hub.Hub
 	trigger *mockTrigger
 }
 
@@ -76,11 +75,9 @@ func newTestEnvWithTrigger(t *testing.T, trigger *mockTrigger) *testEnv {
 	}
 
 	h := hub.New()
-	rawHub := hub.New()
 	return &testEnv{
-		srv:     New(cfg, h, database, trigger, WithRawHub(rawHub)),
+		srv:     New(cfg, h, database, trigger),
 		hub:     h,
-		rawHub:  rawHub,
 		trigger: trigger,
 	}
 }
@@ -316,7 +313,7 @@ func TestSSEContentType(t *testing.T) {
 
 	// Publish then close the session so Subscribe returns a closed channel
 	// and the SSE handler finishes quickly.
-	e.hub.Publish(1, "test")
+	e.hub.Publish(1, hub.Formatted, "test")
 	e.hub.Close(1)
 
 	req := httptest.NewRequest("GET", "/sessions/1/stream", nil)
@@ -521,6 +518,20 @@ func TestSessionMetadataHiddenWhenNil(t *testing.T) {
 	}
 }
 
+func TestSessionMetadataShowsPromptFile(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "running")
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/sessions/%d", id), nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, `title="/tmp/test.md">test.md</div>`) {
+		t.Errorf("expected prompt file in session metadata:\n%s", body)
+	}
+}
+
 func TestSessionLogFileFormattedWithFormatStreamEvent(t *testing.T) {
 	e := newTestEnv(t)
 	id := insertTestSession(t, e, "completed")
@@ -786,6 +797,58 @@ func TestSessionsListShowsChainIndicator(t *testing.T) {
 	}
 }
 
+func TestSessionsListShowsChainSummary(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	rootID, _ := e.srv.db.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/tmp/t1.md",
+		Status: "escalated", StartedAt: now, Trigger: "scheduled",
+	})
+	childID, _ := e.srv.db.InsertSession(&db.Session{
+		Tier: 3, Model: "opus", PromptFile: "/tmp/t3.md",
+		Status: "completed", StartedAt: now, Trigger: "escalation",
+		ParentSessionID: &rootID,
+	})
+	summary := "Tier 1 found caddy down → Tier 3 restarted caddy, verified healthy"
+	if err := e.srv.db.UpdateSessionChainSummary(rootID, summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if body := getPage(e, "/sessions").Body.String(); strings.Count(body, summary) != 1 {
+		t.Error("sessions list should show the chain summary once, under the chain root")
+	}
+	if body := getPage(e, fmt.Sprintf("/sessions/%d", childID)).Body.String(); !strings.Contains(body, summary) {
+		t.Error("session page should show the chain summary for every chain member")
+	}
+}
+
+func TestSkippedTierShown(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	rootID, _ := e.srv.db.InsertSession(&db.Session{
+		Tier: 1, Model: "haiku", PromptFile: "/tmp/t1.md",
+		Status: "escalated", StartedAt: now, Trigger: "scheduled",
+	})
+	skipped := "2"
+	childID, _ := e.srv.db.InsertSession(&db.Session{
+		Tier: 3, Model: "opus", PromptFile: "/tmp/t3.md",
+		Status: "completed", StartedAt: now, Trigger: "escalation",
+		ParentSessionID: &rootID, SkippedTiers: &skipped,
+	})
+
+	if body := getPage(e, "/sessions").Body.String(); strings.Count(body, "skipped T2") != 1 {
+		t.Error("sessions list should mark the Tier 3 session that skipped tier 2")
+	}
+	for _, id := range []int64{rootID, childID} {
+		if body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String(); !strings.Contains(body, "skipped tier 2") {
+			t.Errorf("session %d page should mark the skip in the escalation chain", id)
+		}
+	}
+	if body := getPage(e, fmt.Sprintf("/api/v1/sessions/%d", childID)).Body.String(); !strings.Contains(body, `"skipped_tiers":[2]`) {
+		t.Errorf("API session lacks skipped_tiers: %s", body)
+	}
+}
+
 func TestMemoriesPageRenders(t *testing.T) {
 	e := newTestEnv(t)
 
@@ -900,7 +963,7 @@ func TestMemoryCreate(t *testing.T) {
 	}
 
 	// Verify memory was created.
-	memories, err := e.srv.db.ListMemories(nil, nil, 100, 0)
+	memories, err := e.srv.db.ListMemories(nil, nil, nil, 100, 0)
 	if err != nil {
 		t.Fatalf("ListMemories: %v", err)
 	}
@@ -952,6 +1015,79 @@ func TestMemoryDelete(t *testing.T) {
 	}
 }
 
+func TestMemoryReviewApproveAndReject(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	svc := "caddy"
+	insert := func(obs string) int64 {
+		id, err := e.srv.db.InsertMemory(&db.Memory{
+			Service: &svc, Category: "behavior", Observation: obs, Confidence: 0.7,
+			Active: true, CreatedAt: now, UpdatedAt: now, Tier: 1, ReviewStatus: db.MemoryUnverified,
+		})
+		if err != nil {
+			t.Fatalf("insert memory: %v", err)
+		}
+		return id
+	}
+	approveID := insert("Caddy reloads on SIGHUP")
+	rejectID := insert("Caddy needs a restart every day")
+
+	// The review queue lists unverified memories.
+	req := httptest.NewRequest("GET", "/memories", nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "Review Queue") {
+		t.Fatal("expected review queue on memories page")
+	}
+
+	// Approve with an edited observation.
+	form := url.Values{"observation": {"Caddy reloads config on SIGHUP"}}
+	req = httptest.NewRequest("POST", fmt.Sprintf("/memories/%d/approve", approveID), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("approve: expected 303, got %d", w.Code)
+	}
+	m, _ := e.srv.db.GetMemory(approveID)
+	if m.ReviewStatus != db.MemoryVerified || m.Observation != "Caddy reloads config on SIGHUP" {
+		t.Errorf("unexpected approved memory %+v", m)
+	}
+
+	req = httptest.NewRequest("POST", fmt.Sprintf("/memories/%d/reject", rejectID), nil)
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("reject: expected 303, got %d", w.Code)
+	}
+	m, _ = e.srv.db.GetMemory(rejectID)
+	if m.ReviewStatus != db.MemoryRejected || m.Active {
+		t.Errorf("unexpected rejected memory %+v", m)
+	}
+}
+
+func TestMemoryReviewSuggested(t *testing.T) {
+	e := newTestEnv(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	svc := "traefik"
+	if _, err := e.srv.db.InsertMemorySuggestion(&db.Memory{
+		Service: &svc, Category: "behavior", Observation: "traefik returns 502 after backend restarts",
+		Confidence: 0.5, Active: true, CreatedAt: now, UpdatedAt: now,
+	}, &db.MemorySuggestion{
+		Service: svc, Pattern: "# 502 returned traefik", EventCount: 4,
+		FirstSeen: now, LastSeen: now, CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("InsertMemorySuggestion: %v", err)
+	}
+
+	w := getPage(e, "/memories")
+	if !strings.Contains(w.Body.String(), "suggested from 4 recurring events") {
+		t.Error("expected suggested memory to show its event count in the review queue")
+	}
+}
+
 func TestMemoryUpdate(t *testing.T) {
 	e := newTestEnv(t)
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -1272,3 +1408,378 @@ func TestCooldownBadgeMissingResultNotRendered(t *testing.T) {
 		t.Error("cooldown marker without success/failure result should not produce a badge")
 	}
 }
+
+func TestSessionDetailShowsInvocation(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+	inv := `{"args":["--model","haiku","-p","<prompt: 12 bytes>"],"requested_model":"haiku","resolved_model":"claude-haiku-4-5-20251001","allowed_tools":"Bash,Read","append_system_prompt":"CLAUDEOPS_APPRISE_URLS=[REDACTED:CLAUDEOPS_APPRISE_URLS]","env":{"ANTHROPIC_API_KEY":"[REDACTED]"}}`
+	if err := e.srv.db.UpdateSessionInvocation(id, inv); err != nil {
+		t.Fatalf("UpdateSessionInvocation: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/sessions/%d", id), nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	for _, want := range []string{"Invocation", "claude-haiku-4-5-20251001", "ANTHROPIC_API_KEY", "[REDACTED:CLAUDEOPS_APPRISE_URLS]", "&#34;&lt;prompt: 12 bytes&gt;&#34;"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+}
+
+func TestSessionSyntheticCostMarkedEstimated(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+	if err := e.srv.db.UpdateSessionResult(id, "ok", 0.25, 2, 900); err != nil {
+		t.Fatalf("UpdateSessionResult: %v", err)
+	}
+	if err := e.srv.db.UpdateSessionCostSynthetic(id, true); err != nil {
+		t.Fatalf("UpdateSessionCostSynthetic: %v", err)
+	}
+
+	if body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String(); !strings.Contains(body, "(estimated)") {
+		t.Error("expected synthetic cost to be marked estimated on the session page")
+	}
+	if body := getPage(e, "/sessions").Body.String(); !strings.Contains(body, "~$0.2500") {
+		t.Error("expected synthetic cost to be marked with ~ in the sessions list")
+	}
+}
+
+func TestSessionDetailShowsMaxContext(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+	if err := e.srv.db.UpdateSessionMaxContext(id, 170_000); err != nil {
+		t.Fatalf("UpdateSessionMaxContext: %v", err)
+	}
+
+	e.srv.cfg.ContextWarnPercent = 80
+	body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String()
+	for _, want := range []string{"Max Context", "170.0k tokens (85%)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+	const warned = `text-yellow-400">170.0k`
+	if !strings.Contains(body, warned) {
+		t.Error("expected the context to be highlighted at CLAUDEOPS_CONTEXT_WARN_PERCENT=80")
+	}
+
+	e.srv.cfg.ContextWarnPercent = 90
+	if body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String(); strings.Contains(body, warned) {
+		t.Error("expected no highlight below CLAUDEOPS_CONTEXT_WARN_PERCENT=90")
+	}
+}
+
+func TestSessionDetailWarnsAboutDroppedEvents(t *testing.T) {
+	e := newTestEnv(t)
+	e.srv.cfg.StreamDropWarn = 5
+	id := insertTestSession(t, e, "completed")
+	get := func() string {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/sessions/%d", id), nil)
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	insert := func(eventType string, n int) {
+		if _, err := e.srv.db.InsertStreamDiagnostic(&db.StreamDiagnostic{
+			SessionID: id, EventType: eventType, Count: n, Sample: `{"type":"` + eventType + `"}`, CreatedAt: "2026-10-01T00:00:00Z",
+		}); err != nil {
+			t.Fatalf("InsertStreamDiagnostic: %v", err)
+		}
+	}
+
+	insert("assistant_delta", 3)
+	if body := get(); strings.Contains(body, "were not recognized") {
+		t.Error("expected no warning below the threshold")
+	}
+
+	insert("tool_progress", 4)
+	body := get()
+	for _, want := range []string{"7 stream events were not recognized", "tool_progress (4)", "assistant_delta (3)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+}
+
+func TestSessionDetailShowsPolicyEvaluations(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "escalated")
+	for _, pe := range []db.PolicyEvaluation{
+		{SessionID: id, Point: "escalation", Rule: "overnight", Matched: true, Action: "cap", Detail: "overnight (max tier 2)", CreatedAt: "2026-10-01T00:00:00Z"},
+		{SessionID: id, Point: "escalation", Rule: "budget", Action: "none", CreatedAt: "2026-10-01T00:00:00Z"},
+	} {
+		if _, err := e.srv.db.InsertPolicyEvaluation(&pe); err != nil {
+			t.Fatalf("InsertPolicyEvaluation: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/sessions/%d", id), nil)
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	body := w.Body.String()
+	for _, want := range []string{"overnight (max tier 2)", ">cap<", "budget", "not matched"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+}
+
+func TestSessionDetailShowsPagerIncidents(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "failed")
+	if _, err := e.srv.db.InsertPagerIncident(&db.PagerIncident{
+		Provider: "pagerduty", DedupKey: "claude-ops/jellyfin", Service: "jellyfin", SessionID: &id,
+		Summary: "jellyfin: Tier 3 remediation failed", CreatedAt: "2026-10-01T00:00:00Z",
+	}); err != nil {
+		t.Fatalf("InsertPagerIncident: %v", err)
+	}
+	if err := e.srv.db.SetPagerIncidentIssueURL("jellyfin", "https://github.com/ops/homelab/issues/9"); err != nil {
+		t.Fatalf("SetPagerIncidentIssueURL: %v", err)
+	}
+
+	body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String()
+	for _, want := range []string{"Paged Incidents", "PagerDuty", "claude-ops/jellyfin", ">triggered<", "Tier 3 remediation failed",
+		`href="https://github.com/ops/homelab/issues/9"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+}
+
+func TestSessionDetailShowsRepositoryChanges(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+	prURL := "https://github.com/acme/infra/pull/12"
+	if _, err := e.srv.db.InsertSessionCommit(&db.SessionCommit{
+		SessionID: id, Repo: "/repos/infra", Branch: fmt.Sprintf("claudeops/session-%d", id), BaseBranch: "main",
+		CommitSHA: "0123456789abcdef0123456789abcdef01234567", DiffStat: "compose.yaml | 2 +-",
+		Diff: "-image: jellyfin:10.8\n+image: jellyfin:10.9", Pushed: true, PRURL: &prURL, CreatedAt: "2026-10-01T00:00:00Z",
+	}); err != nil {
+		t.Fatalf("InsertSessionCommit: %v", err)
+	}
+
+	body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String()
+	for _, want := range []string{"Repository Changes", "/repos/infra", fmt.Sprintf("claudeops/session-%d", id), "0123456", "from main",
+		`href="` + prURL + `"`, "compose.yaml | 2 &#43;-", "&#43;image: jellyfin:10.9"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+}
+
+func TestSessionDetailShowsWorkDir(t *testing.T) {
+	e := newTestEnv(t)
+	dir, sha := "/repos/infra", "0123456789abcdef0123456789abcdef01234567"
+	id, err := e.srv.db.InsertSession(&db.Session{
+		Tier: 3, Model: "opus", PromptFile: "/tmp/test.md", Status: "completed",
+		StartedAt: time.Now().UTC().Format(time.RFC3339), WorkDir: &dir, GitSHA: &sha,
+	})
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+
+	body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String()
+	for _, want := range []string{"Working Dir", "/repos/infra", "@ 0123456", `title="` + sha + `"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+}
+
+func TestSessionParseWarnings(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+	if _, err := e.srv.db.InsertParseWarning(&db.ParseWarning{
+		SessionID: id, Marker: "event", Text: "[EVENT: success] Sonarr restarted",
+		Reason: "expected [EVENT:level]", CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		t.Fatalf("InsertParseWarning: %v", err)
+	}
+
+	body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String()
+	for _, want := range []string{"1 dropped marker<", "[EVENT: success] Sonarr restarted", "event: expected [EVENT:level]"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in session page", want)
+		}
+	}
+	if body := getPage(e, "/sessions").Body.String(); !strings.Contains(body, "&#9888; 1</span>") {
+		t.Error("expected a parse warning badge on the sessions list")
+	}
+}
+
+func TestSessionLogLineExpandsToolResult(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+
+	long := strings.Repeat("line of output ", 400)
+	logFile := filepath.Join(t.TempDir(), "session.log")
+	lines := []string{
+		`2026-10-01T00:00:00Z	{"type":"system","subtype":"init"}`,
+		`2026-10-01T00:00:01Z	{"type":"user","message":{"content":[{"type":"tool_result","content":"` + long + `"}]}}`,
+	}
+	if err := os.WriteFile(logFile, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("write log file: %v", err)
+	}
+	ended := time.Now().UTC().Format(time.RFC3339)
+	if err := e.srv.db.UpdateSession(id, "completed", &ended, nil, &logFile); err != nil {
+		t.Fatalf("update session: %v", err)
+	}
+
+	body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String()
+	if !strings.Contains(body, fmt.Sprintf(`hx-get="/sessions/%d/log/2"`, id)) {
+		t.Error("expected an expand control pointing at log line 2")
+	}
+
+	w := getPage(e, fmt.Sprintf("/sessions/%d/log/2", id))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), strings.TrimSpace(long)) {
+		t.Errorf("expected full tool result, got %d", w.Code)
+	}
+	for path, want := range map[string]int{
+		fmt.Sprintf("/sessions/%d/log/1", id): http.StatusNotFound, // not a tool result
+		fmt.Sprintf("/sessions/%d/log/9", id): http.StatusNotFound,
+		fmt.Sprintf("/sessions/%d/log/x", id): http.StatusBadRequest,
+		"/sessions/999/log/2":                 http.StatusNotFound,
+	} {
+		if w := getPage(e, path); w.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, w.Code, want)
+		}
+	}
+}
+
+func TestSessionSearch(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+
+	logFile := filepath.Join(t.TempDir(), "session.log")
+	var lines []string
+	lines = append(lines, `{"type":"system","subtype":"init"}`)
+	for i := 1; i <= 20; i++ {
+		text := fmt.Sprintf("step %d", i)
+		if i == 5 || i == 6 || i == 15 {
+			text += " nginx restart"
+		}
+		lines = append(lines, `{"type":"assistant","message":{"content":[{"type":"text","text":"`+text+`"}]}}`)
+		lines = append(lines, `{"type":"user","message":{"content":[]}}`) // not displayed
+	}
+	if err := os.WriteFile(logFile, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("write log file: %v", err)
+	}
+	ended := time.Now().UTC().Format(time.RFC3339)
+	if err := e.srv.db.UpdateSession(id, "completed", &ended, nil, &logFile); err != nil {
+		t.Fatalf("update session: %v", err)
+	}
+
+	if body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String(); !strings.Contains(body, fmt.Sprintf(`hx-get="/sessions/%d/search"`, id)) {
+		t.Error("expected a search box on the session page")
+	}
+
+	w := getPage(e, fmt.Sprintf("/sessions/%d/search?q=NGINX&context=1", id))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "3 matches") {
+		t.Error("expected 3 matches")
+	}
+	// Display line 1 is "session started"; step N is line N+1. Steps 5 and 6
+	// share one group (lines 5-8), step 15 has its own (lines 15-17).
+	for _, want := range []int{5, 6, 7, 8, 15, 16, 17} {
+		if !strings.Contains(body, fmt.Sprintf(`href="#L%d"`, want)) {
+			t.Errorf("expected line %d in results", want)
+		}
+	}
+	for _, unwanted := range []int{4, 9, 14, 18} {
+		if strings.Contains(body, fmt.Sprintf(`href="#L%d"`, unwanted)) {
+			t.Errorf("unexpected line %d in results", unwanted)
+		}
+	}
+	if n := strings.Count(body, "log-search-gap"); n != 1 {
+		t.Errorf("expected 2 groups separated by one gap, got %d gaps", n)
+	}
+	if n := strings.Count(body, "log-search-match"); n != 3 {
+		t.Errorf("expected 3 highlighted matches, got %d", n)
+	}
+
+	if body := getPage(e, fmt.Sprintf("/sessions/%d/search?q=kubernetes", id)).Body.String(); !strings.Contains(body, "No matches") {
+		t.Error("expected no matches message")
+	}
+	if w := getPage(e, fmt.Sprintf("/sessions/%d/search?q=x&context=-1", id)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for negative context, got %d", w.Code)
+	}
+	if w := getPage(e, "/sessions/999/search?q=x"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown session, got %d", w.Code)
+	}
+}
+
+func TestDashboardLanguage(t *testing.T) {
+	e := newTestEnv(t)
+	get := func(header, cookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/sessions", nil)
+		if header != "" {
+			req.Header.Set("Accept-Language", header)
+		}
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: langCookie, Value: cookie})
+		}
+		w := httptest.NewRecorder()
+		e.srv.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("es-ES,es;q=0.9", "")
+	body := w.Body.String()
+	if !strings.Contains(body, `<html lang="es"`) || !strings.Contains(body, "Sesiones") || !strings.Contains(body, "Ejecutar ahora") {
+		t.Error("expected the Spanish dashboard for Accept-Language es")
+	}
+	if w.Header().Get("Content-Language") != "es" {
+		t.Errorf("Content-Language = %q", w.Header().Get("Content-Language"))
+	}
+	if body := get("es", "en").Body.String(); !strings.Contains(body, `<html lang="en"`) || strings.Contains(body, "Sesiones") {
+		t.Error("expected the language cookie to override Accept-Language")
+	}
+	if body := get("de-DE", "").Body.String(); !strings.Contains(body, `<html lang="en"`) {
+		t.Error("expected English for an unsupported language")
+	}
+
+	// The picker stores the choice and returns to the page it was used on.
+	req := httptest.NewRequest("GET", "/lang/es", nil)
+	req.Header.Set("Referer", "http://example.com/events?level=critical")
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/events?level=critical" {
+		t.Errorf("expected redirect back to /events, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if c := w.Result().Cookies(); len(c) != 1 || c[0].Name != langCookie || c[0].Value != "es" {
+		t.Errorf("expected language cookie, got %+v", c)
+	}
+	if w := getPage(e, "/lang/xx"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unsupported language, got %d", w.Code)
+	}
+}
+
+func TestSessionEscalationDecisionPanel(t *testing.T) {
+	e := newTestEnv(t)
+	id := insertTestSession(t, e, "completed")
+	if body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String(); strings.Contains(body, "escalation-decision") {
+		t.Fatal("expected no decision panel without a decision")
+	}
+
+	if _, err := e.srv.db.InsertEscalationDecision(&db.EscalationDecision{
+		SessionID: id, FromTier: 1, RequestedTier: 3, Outcome: "policy_denied",
+		Reason: "Escalation to tier 3 denied by policy no-tier3", Source: "handoff", Services: "jellyfin",
+		Handoff:   "jellyfin returns 502",
+		Cooldowns: `[{"service":"jellyfin","action":"restart","used":2,"limit":2,"remaining":0,"window_hours":4}]`,
+		MaxTier:   3, CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		t.Fatalf("InsertEscalationDecision: %v", err)
+	}
+	body := getPage(e, fmt.Sprintf("/sessions/%d", id)).Body.String()
+	for _, want := range []string{"escalation-decision", "policy_denied", "denied by policy no-tier3", "tier 3 (handoff)", "2 of 2 used in 4h, 0 left", "jellyfin returns 502"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q on session page", want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+// sessionsPageSize is how many sessions the sessions page shows at a time.
+const sessionsPageSize = 50
+
+// sessionStatuses and sessionTriggers are the values the sessions filters
+// offer, in display order.
+var (
+	sessionStatuses = []string{"running", "completed", "failed", "timed_out", "escalated", "continued", "reopened", "interrupted", "skipped"}
+	sessionTriggers = []string{"scheduled", "manual", "escalation", "pulse", "verify", "drill", "continuation", "imported"}
+)
+
+// sessionSorts are the orders the sessions list can be sorted by.
+var sessionSorts = []string{db.SessionSortStarted, db.SessionSortCost, db.SessionSortDuration}
+
+// sessionQuery is the sessions filter and sort order as given in the query
+// string.
+type sessionQuery struct {
+	Tier    string
+	Status  string
+	Trigger string
+	Outcome string
+	Range   string // one of eventRanges, or "" for all time
+	Since   string // RFC3339 timestamp or YYYY-MM-DD
+	Until   string // RFC3339 timestamp or YYYY-MM-DD, which includes that day
+	MinCost string
+	Sort    string // one of sessionSorts, or "" for started_at
+	Order   string // "asc" or "desc"; "" is descending
+}
+
+// parseSessionQuery reads the tier, status, trigger, outcome, range, since,
+// until, min_cost, sort, and order query parameters shared by the sessions
+// page and the API.
+func parseSessionQuery(r *http.Request) (sessionQuery, error) {
+	q := r.URL.Query()
+	sq := sessionQuery{
+		Tier:    q.Get("tier"),
+		Status:  q.Get("status"),
+		Trigger: q.Get("trigger"),
+		Outcome: q.Get("outcome"),
+		Range:   q.Get("range"),
+		Since:   q.Get("since"),
+		Until:   q.Get("until"),
+		MinCost: q.Get("min_cost"),
+		Sort:    q.Get("sort"),
+		Order:   q.Get("order"),
+	}
+	if sq.Tier != "" && sq.Tier != "1" && sq.Tier != "2" && sq.Tier != "3" {
+		return sq, fmt.Errorf("tier must be 1, 2, or 3")
+	}
+	if sq.Status != "" && !slices.Contains(sessionStatuses, sq.Status) {
+		return sq, fmt.Errorf("status must be one of %s", strings.Join(sessionStatuses, ", "))
+	}
+	if sq.Trigger != "" && !slices.Contains(sessionTriggers, sq.Trigger) {
+		return sq, fmt.Errorf("trigger must be one of %s", strings.Join(sessionTriggers, ", "))
+	}
+	if sq.Outcome != "" && !slices.Contains(db.SessionOutcomes, sq.Outcome) {
+		return sq, fmt.Errorf("outcome must be one of %s", strings.Join(db.SessionOutcomes, ", "))
+	}
+	if sq.Range != "" && rangeAge(sq.Range) == 0 {
+		return sq, fmt.Errorf("range must be one of 1h, 24h, 7d, 30d")
+	}
+	for name, v := range map[string]string{"since": sq.Since, "until": sq.Until} {
+		if v == "" {
+			continue
+		}
+		if _, _, err := parseDateOrTime(v); err != nil {
+			return sq, fmt.Errorf("%s must be an RFC3339 timestamp or a YYYY-MM-DD date", name)
+		}
+	}
+	if sq.MinCost != "" {
+		if v, err := strconv.ParseFloat(sq.MinCost, 64); err != nil || v < 0 {
+			return sq, fmt.Errorf("min_cost must be a non-negative number")
+		}
+	}
+	if sq.Sort != "" && !slices.Contains(sessionSorts, sq.Sort) {
+		return sq, fmt.Errorf("sort must be one of %s", strings.Join(sessionSorts, ", "))
+	}
+	if sq.Order != "" && sq.Order != "asc" && sq.Order != "desc" {
+		return sq, fmt.Errorf("order must be asc or desc")
+	}
+	return sq, nil
+}
+
+// parseDateOrTime parses an RFC3339 timestamp or a YYYY-MM-DD date (UTC).
+// isDate reports which it was.
+func parseDateOrTime(v string) (t time.Time, isDate bool, err error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+// filter converts the query to a db.SessionFilter. A range and an explicit
+// since combine to the later of the two, and an until date includes the
+// whole day.
+func (sq sessionQuery) filter(now time.Time) db.SessionFilter {
+	var f db.SessionFilter
+	if tier, err := strconv.Atoi(sq.Tier); err == nil {
+		f.Tier = &tier
+	}
+	if sq.Status != "" {
+		f.Status = &sq.Status
+	}
+	if sq.Trigger != "" {
+		f.Trigger = &sq.Trigger
+	}
+	if sq.Outcome != "" {
+		f.Outcome = &sq.Outcome
+	}
+	if t, _, err := parseDateOrTime(sq.Since); err == nil {
+		f.Since = t.UTC().Format(time.RFC3339)
+	}
+	if age := rangeAge(sq.Range); age > 0 {
+		if since := now.Add(-age).UTC().Format(time.RFC3339); since > f.Since {
+			f.Since = since
+		}
+	}
+	if t, isDate, err := parseDateOrTime(sq.Until); err == nil {
+		if isDate {
+			t = t.AddDate(0, 0, 1)
+		}
+		f.Until = t.UTC().Format(time.RFC3339)
+	}
+	if v, err := strconv.ParseFloat(sq.MinCost, 64); err == nil {
+		f.MinCost = &v
+	}
+	return f
+}
+
+// Filtered reports whether the query filters sessions at all.
+func (sq sessionQuery) Filtered() bool {
+	return sq.Tier != "" || sq.Status != "" || sq.Trigger != "" || sq.Outcome != "" ||
+		sq.Range != "" || sq.Since != "" || sq.Until != "" || sq.MinCost != ""
+}
+
+// asc reports whether the query sorts in ascending order.
+func (sq sessionQuery) asc() bool {
+	return sq.Order == "asc"
+}
+
+// url returns path with the query's filters, sort order, and offset when it
+// is non-zero.
+func (sq sessionQuery) url(path string, offset int) string {
+	v := url.Values{}
+	for key, val := range map[string]string{
+		"tier": sq.Tier, "status": sq.Status, "trigger": sq.Trigger, "outcome": sq.Outcome,
+		"range": sq.Range, "since": sq.Since, "until": sq.Until, "min_cost": sq.MinCost,
+		"sort": sq.Sort, "order": sq.Order,
+	} {
+		if val != "" {
+			v.Set(key, val)
+		}
+	}
+	if offset > 0 {
+		v.Set("offset", strconv.Itoa(offset))
+	}
+	if len(v) == 0 {
+		return path
+	}
+	return path + "?" + v.Encode()
+}
+
+// SortURL returns the sessions page sorted by column, keeping the filters.
+// Sorting by the current column again reverses the order; a new column
+// sorts descending first.
+func (sq sessionQuery) SortURL(column string) string {
+	current := sq.Sort
+	if current == "" {
+		current = db.SessionSortStarted
+	}
+	order := ""
+	if column == current && !sq.asc() {
+		order = "asc"
+	}
+	sq.Sort, sq.Order = column, order
+	if column == db.SessionSortStarted {
+		sq.Sort = ""
+	}
+	return sq.url("/sessions", 0)
+}
+
+// SortMark returns the arrow shown next to the column the page is sorted
+// by, or "".
+func (sq sessionQuery) SortMark(column string) string {
+	current := sq.Sort
+	if current == "" {
+		current = db.SessionSortStarted
+	}
+	switch {
+	case column != current:
+		return ""
+	case sq.asc():
+		return "↑"
+	}
+	return "↓"
+}
+
+// sessionsPageData is the template data for sessions.html.
+type sessionsPageData struct {
+	Sessions   []SessionView
+	Query      sessionQuery
+	Tiers      []string
+	Statuses   []string
+	Triggers   []string
+	Outcomes   []string
+	Ranges     []struct{ Value, Label string }
+	Total      int
+	Offset     int
+	PageStart  int // 1-based position of the first session shown
+	PageEnd    int
+	RefreshURL string
+	PrevURL    string
+	NextURL    string
+}
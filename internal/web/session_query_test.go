@@ -0,0 +1,107 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+)
+
+func TestParseSessionQuery(t *testing.T) {
+	for _, q := range []string{"tier=4", "status=done", "trigger=cron", "outcome=great", "range=2y", "since=yesterday", "min_cost=-1", "sort=model", "order=up"} {
+		if _, err := parseSessionQuery(httptest.NewRequest("GET", "/sessions?"+q, nil)); err == nil {
+			t.Errorf("%s: accepted", q)
+		}
+	}
+
+	sq, err := parseSessionQuery(httptest.NewRequest("GET", "/sessions?tier=3&status=failed&since=2026-09-01&until=2026-09-30&min_cost=0.5&sort=cost", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := sq.filter(time.Now())
+	if *f.Tier != 3 || *f.Status != "failed" || *f.MinCost != 0.5 || f.Since != "2026-09-01T00:00:00Z" || f.Until != "2026-10-01T00:00:00Z" {
+		t.Errorf("filter = %+v", f)
+	}
+	if got := sq.SortURL("cost"); !strings.Contains(got, "order=asc") || !strings.Contains(got, "tier=3") {
+		t.Errorf("SortURL(cost) = %s, want ascending with filters kept", got)
+	}
+	if got := sq.SortURL("started_at"); strings.Contains(got, "sort=") || strings.Contains(got, "order=") {
+		t.Errorf("SortURL(started_at) = %s, want the default order", got)
+	}
+}
+
+func TestSessionsFilterAndSort(t *testing.T) {
+	e := newTestEnv(t)
+	insert := func(tier int, status, started string, cost float64) int64 {
+		id, err := e.srv.db.InsertSession(&db.Session{Tier: tier, Model: "sonnet", PromptFile: "/tmp/test.md", Status: status, StartedAt: started, Trigger: "scheduled"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.srv.db.UpdateSessionResult(id, "done", cost, 3, 60000); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	last := time.Now().UTC().AddDate(0, -1, 0)
+	day := func(d int) string {
+		return time.Date(last.Year(), last.Month(), d, 10, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+	monthStart := time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, time.UTC)
+	since, until := monthStart.Format(time.DateOnly), monthStart.AddDate(0, 1, -1).Format(time.DateOnly)
+
+	failed := insert(3, "failed", day(5), 1.25)
+	cheapFailed := insert(3, "failed", day(20), 0.05)
+	insert(1, "failed", day(12), 0.01)
+	insert(3, "completed", day(15), 3)
+	for i := 0; i < sessionsPageSize; i++ {
+		insert(1, "completed", time.Now().UTC().Format(time.RFC3339), 0.01)
+	}
+	link := func(id int64) string { return fmt.Sprintf(`href="/sessions/%d"`, id) }
+
+	body := getPage(e, "/sessions?tier=3&status=failed&since="+since+"&until="+until).Body.String()
+	if !strings.Contains(body, link(failed)) || !strings.Contains(body, link(cheapFailed)) || strings.Count(body, `href="/sessions/`) != 2 {
+		t.Error("sessions page not filtered to failed Tier 3 sessions last month")
+	}
+	body = getPage(e, "/sessions?tier=3&status=failed&sort=cost").Body.String()
+	if strings.Index(body, link(failed)) > strings.Index(body, link(cheapFailed)) {
+		t.Error("sessions not sorted by cost, most expensive first")
+	}
+	body = getPage(e, "/sessions?tier=3&min_cost=1&sort=cost&order=asc").Body.String()
+	if strings.Count(body, `href="/sessions/`) != 2 || strings.Contains(body, link(cheapFailed)) {
+		t.Error("min_cost not applied")
+	}
+	if !strings.Contains(getPage(e, "/sessions?trigger=drill").Body.String(), "No sessions match these filters.") {
+		t.Error("empty filtered list not explained")
+	}
+
+	body = getPage(e, "/sessions").Body.String()
+	if !strings.Contains(body, "1&ndash;50") || !strings.Contains(body, "of 54") || !strings.Contains(body, "offset=50") {
+		t.Error("sessions page not paginated")
+	}
+	if body := getPage(e, "/sessions?offset=50").Body.String(); strings.Count(body, `href="/sessions/`) != 4 {
+		t.Error("second page does not list the remaining sessions")
+	}
+	if w := getPage(e, "/sessions?tier=9"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid tier: %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/sessions?tier=3&status=failed&sort=cost&order=asc", nil))
+	var list APISessionsResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Sessions) != 2 || list.Sessions[0].ID != cheapFailed || list.Sessions[1].ID != failed {
+		t.Errorf("API filtered sessions: %+v", list.Sessions)
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/sessions?sort=model", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("API invalid sort: %d", w.Code)
+	}
+}
@@ -0,0 +1,165 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/seal"
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+const (
+	// logSearchMaxMatches caps the matches returned for one query.
+	logSearchMaxMatches = 200
+	// logSearchMaxContext caps the context lines requested around a match.
+	logSearchMaxContext = 10
+)
+
+// logSearchLine is one displayed activity log line in a search result.
+type logSearchLine struct {
+	Num   int // activity log line number (the #L anchor)
+	TS    string
+	HTML  template.HTML
+	Match bool
+}
+
+// logSearchData is the logSearchResults template's data.
+type logSearchData struct {
+	Query     string
+	Matches   int
+	Truncated bool
+	Groups    [][]logSearchLine
+}
+
+// handleSessionSearch greps a session's NDJSON log and returns the matching
+// activity log lines, with context, linked to their anchors on the session
+// page. Rendering the whole log and using the browser's find does not scale
+// to long sessions.
+func (s *Server) handleSessionSearch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	context := 2
+	if v := r.URL.Query().Get("context"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid context", http.StatusBadRequest)
+			return
+		}
+		context = min(n, logSearchMaxContext)
+	}
+
+	sess, err := s.db.GetSession(id)
+	if err != nil {
+		log.Printf("handleSessionSearch: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if sess == nil || sess.LogFile == nil || *sess.LogFile == "" {
+		http.Error(w, "session log not found", http.StatusNotFound)
+		return
+	}
+
+	data := logSearchData{Query: query}
+	if query != "" {
+		var f io.ReadCloser
+		f, err = s.logs.Open(r.Context(), sess)
+		if err == nil {
+			data, err = searchSessionLog(sess.ID, f, s.db.Sealer(), query, context)
+			_ = f.Close()
+		}
+		if err != nil {
+			log.Printf("handleSessionSearch: %v", err)
+			http.Error(w, "error reading log", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := s.templates(r).ExecuteTemplate(w, "logSearchResults", data); err != nil {
+		log.Printf("template error: %v", err)
+	}
+}
+
+// searchSessionLog scans the log once, numbering displayed lines exactly as
+// the session page does, and collects case-insensitive matches against the
+// raw NDJSON with up to context displayed lines either side. sealer opens
+// the lines of an encrypted log.
+func searchSessionLog(sessionID int64, f io.Reader, sealer *seal.Sealer, query string, context int) (logSearchData, error) {
+	data := logSearchData{Query: query}
+
+	needle := strings.ToLower(query)
+	var (
+		before    []logSearchLine // ring of preceding context lines
+		group     []logSearchLine
+		after     int // context lines still to add after the last match
+		lineNum   int
+		logLine   int
+		truncated bool
+	)
+	flush := func() {
+		if len(group) > 0 {
+			data.Groups = append(data.Groups, group)
+			group = nil
+		}
+	}
+
+	scanner := session.NewLogScanner(f)
+	for scanner.Scan() {
+		logLine++
+		ts, raw, hasTS := session.ParseTimestampedLogLine(sealer.OpenOr(scanner.Text()))
+		formatted := session.FormatStreamEventHTMLAt(raw, sessionID, logLine)
+		if formatted == "" {
+			continue
+		}
+		lineNum++
+		line := logSearchLine{Num: lineNum, HTML: template.HTML(formatted)}
+		if hasTS {
+			line.TS = ts.Format("15:04:05")
+		}
+
+		if !truncated && strings.Contains(strings.ToLower(raw), needle) {
+			if data.Matches == logSearchMaxMatches {
+				truncated = true
+			} else {
+				data.Matches++
+				line.Match = true
+				if after == 0 {
+					flush()
+					group = append(group, before...)
+				}
+				before = nil
+				group = append(group, line)
+				after = context
+				continue
+			}
+		}
+
+		if after > 0 {
+			group = append(group, line)
+			after--
+			continue
+		}
+		if truncated {
+			break
+		}
+		if context > 0 {
+			before = append(before, line)
+			if len(before) > context {
+				before = before[1:]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return data, fmt.Errorf("scan log: %w", err)
+	}
+	flush()
+	data.Truncated = truncated
+	return data, nil
+}
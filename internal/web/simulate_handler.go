@@ -0,0 +1,40 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// handleAPISimulate reports what the supervisor would do with a hypothetical
+// handoff (services affected, recommended tier) under the current config,
+// policy rules, and cooldown budgets, without running or recording
+// anything. Useful for trying policy changes before a real incident does.
+func (s *Server) handleAPISimulate(w http.ResponseWriter, r *http.Request) {
+	if !requireJSON(w, r) {
+		return
+	}
+	if s.simulate == nil {
+		writeError(w, http.StatusServiceUnavailable, "simulation is unavailable")
+		return
+	}
+	var req session.SimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.FromTier < 0 || req.FromTier > 2 {
+		writeError(w, http.StatusBadRequest, "from_tier must be 1 or 2")
+		return
+	}
+
+	sim, err := s.simulate(req)
+	if err != nil {
+		log.Printf("handleAPISimulate: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	writeJSON(w, http.StatusOK, sim)
+}
@@ -0,0 +1,47 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+func postSimulate(e *testEnv, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/v1/simulate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPISimulate(t *testing.T) {
+	e := newTestEnv(t)
+	if w := postSimulate(e, `{"recommended_tier": 2, "services_affected": ["jellyfin"]}`); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("without a simulator: expected 503, got %d", w.Code)
+	}
+
+	var got session.SimulationRequest
+	e.srv.simulate = func(req session.SimulationRequest) (*session.Simulation, error) {
+		got = req
+		return &session.Simulation{Outcome: session.SimulationEscalate, Tier: req.RecommendedTier}, nil
+	}
+	w := postSimulate(e, `{"recommended_tier": 3, "services_affected": ["jellyfin"], "context": "502s"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var sim session.Simulation
+	if err := json.NewDecoder(w.Body).Decode(&sim); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Outcome != "escalate" || sim.Tier != 3 || got.Context != "502s" || len(got.ServicesAffected) != 1 {
+		t.Errorf("simulation = %+v, request = %+v", sim, got)
+	}
+
+	if w := postSimulate(e, `{"from_tier": 3, "recommended_tier": 3}`); w.Code != http.StatusBadRequest {
+		t.Errorf("from_tier 3: expected 400, got %d", w.Code)
+	}
+}
@@ -42,7 +42,7 @@ self.addEventListener('fetch', (event) => {
   if (event.request.method !== 'GET') {
     return;
   }
-  if (url.pathname.match(/\/sessions\/\d+\/stream/)) {
+  if (url.pathname.match(/\/sessions\/\d+\/(chain\/)?stream/)) {
     return;
   }
 
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/tickets"
+)
+
+// ticketSession parses the {id} path value and loads the session, writing
+// an error and returning nil when it is invalid or does not exist.
+func (s *Server) ticketSession(w http.ResponseWriter, r *http.Request) *db.Session {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid session ID")
+		return nil
+	}
+	sess, err := s.db.GetSession(id)
+	if err != nil {
+		log.Printf("ticketSession: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return nil
+	}
+	if sess == nil {
+		writeError(w, http.StatusNotFound, "session not found")
+		return nil
+	}
+	return sess
+}
+
+// handleAPIListTickets returns the tickets a session is linked to.
+func (s *Server) handleAPIListTickets(w http.ResponseWriter, r *http.Request) {
+	sess := s.ticketSession(w, r)
+	if sess == nil {
+		return
+	}
+	linked, err := s.db.ListSessionTickets(sess.ID)
+	if err != nil {
+		log.Printf("handleAPIListTickets: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"integrations": toAPITickets(linked)})
+}
+
+// handleAPILinkTicket links a session to a GitHub or Gitea issue
+// (owner/repo#123) or a Jira issue (OPS-123).
+func (s *Server) handleAPILinkTicket(w http.ResponseWriter, r *http.Request) {
+	if !requireJSON(w, r) {
+		return
+	}
+	sess := s.ticketSession(w, r)
+	if sess == nil {
+		return
+	}
+
+	var req APILinkTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	ref, err := tickets.Parse(req.Provider, req.Ref)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	link := ref.URL()
+	if req.URL != "" {
+		if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			writeError(w, http.StatusBadRequest, "url must be an http or https URL")
+			return
+		}
+		link = req.URL
+	}
+
+	t := &db.SessionTicket{
+		SessionID: sess.ID,
+		Provider:  ref.Provider,
+		TicketID:  ref.ID,
+		URL:       link,
+		Source:    db.TicketSourceAPI,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	added, err := s.db.InsertSessionTicket(t)
+	if err != nil {
+		log.Printf("handleAPILinkTicket: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if !added {
+		writeError(w, http.StatusConflict, "session is already linked to "+ref.Provider+" "+ref.ID)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toAPITicket(*t))
+}
+
+// handleAPIUnlinkTicket removes a session's link to a ticket.
+func (s *Server) handleAPIUnlinkTicket(w http.ResponseWriter, r *http.Request) {
+	sess := s.ticketSession(w, r)
+	if sess == nil {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("ticket"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid ticket link ID")
+		return
+	}
+	removed, err := s.db.DeleteSessionTicket(sess.ID, id)
+	if err != nil {
+		log.Printf("handleAPIUnlinkTicket: %v", err)
+		writeError(w, http.StatusInternalServerError, "database error")
+		return
+	}
+	if !removed {
+		writeError(w, http.StatusNotFound, "ticket link not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postTicket(e *testEnv, sessionID int64, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/sessions/%d/integrations", sessionID), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPILinkTicket(t *testing.T) {
+	e := newTestEnv(t)
+	t.Setenv("JIRA_URL", "https://example.atlassian.net/")
+	id := insertTestSession(t, e, "completed")
+
+	w := postTicket(e, id, `{"provider": "github", "ref": "ops/homelab#42"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created APITicket
+	_ = json.NewDecoder(w.Body).Decode(&created)
+	if created.URL != "https://github.com/ops/homelab/issues/42" || created.Source != "api" {
+		t.Errorf("created = %+v", created)
+	}
+
+	if w := postTicket(e, id, `{"provider": "jira", "ref": "ops-7"}`); w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), "https://example.atlassian.net/browse/OPS-7") {
+		t.Errorf("jira link: %d %s", w.Code, w.Body.String())
+	}
+	if w := postTicket(e, id, `{"provider": "github", "ref": "ops/homelab#42"}`); w.Code != http.StatusConflict {
+		t.Errorf("duplicate link: expected 409, got %d", w.Code)
+	}
+	for _, body := range []string{
+		`{"provider": "github", "ref": "homelab#42"}`,
+		`{"provider": "linear", "ref": "OPS-1"}`,
+		`{"provider": "gitea", "ref": "ops/homelab#1", "url": "javascript:alert(1)"}`,
+	} {
+		if w := postTicket(e, id, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+	if w := postTicket(e, 99999, `{"provider": "jira", "ref": "OPS-1"}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing session: expected 404, got %d", w.Code)
+	}
+
+	w = getPage(e, fmt.Sprintf("/api/v1/sessions/%d", id))
+	var sess APISession
+	_ = json.NewDecoder(w.Body).Decode(&sess)
+	if len(sess.Integrations) != 2 || sess.Integrations[1].Ref != "OPS-7" {
+		t.Fatalf("integrations = %+v", sess.Integrations)
+	}
+
+	w = getPage(e, fmt.Sprintf("/sessions/%d", id))
+	if body := w.Body.String(); !strings.Contains(body, `href="https://github.com/ops/homelab/issues/42"`) || !strings.Contains(body, "OPS-7") {
+		t.Errorf("session page does not link the tickets")
+	}
+
+	req := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/sessions/%d/integrations/%d", id, created.ID), nil)
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	e.srv.mux.ServeHTTP(w, httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/sessions/%d/integrations/%d", id, created.ID), nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete: expected 404, got %d", w.Code)
+	}
+
+	w = getPage(e, fmt.Sprintf("/api/v1/sessions/%d/integrations", id))
+	var list struct {
+		Integrations []APITicket `json:"integrations"`
+	}
+	_ = json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Integrations) != 1 || list.Integrations[0].Provider != "jira" {
+		t.Errorf("integrations after unlink = %+v", list.Integrations)
+	}
+}
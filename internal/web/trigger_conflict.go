@@ -0,0 +1,58 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/joestump/claude-ops/internal/session"
+)
+
+// sessionURL links to a session's page, on CLAUDEOPS_DASHBOARD_URL when it
+// is set.
+func (s *Server) sessionURL(id int64) string {
+	base := ""
+	if s.cfg != nil {
+		base = strings.TrimRight(s.cfg.DashboardURL, "/")
+	}
+	return fmt.Sprintf("%s/sessions/%d", base, id)
+}
+
+// writeTriggerConflict writes the 409 for an ad-hoc trigger the session
+// manager rejected. When the prompt names services a running chain is
+// already working on, the body links to that chain's session.
+func (s *Server) writeTriggerConflict(w http.ResponseWriter, err error) {
+	var conflict *session.ServiceConflictError
+	if !errors.As(err, &conflict) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	body := map[string]any{
+		"error":       err.Error(),
+		"session_id":  conflict.SessionID,
+		"session_url": s.sessionURL(conflict.SessionID),
+		"services":    conflict.Services,
+	}
+	if id := w.Header().Get(requestIDHeader); id != "" {
+		body["request_id"] = id
+	}
+	writeJSON(w, http.StatusConflict, body)
+}
+
+// triggerBusyMessage is the reply to a chat request the session manager did
+// not start a session for: a pointer to the session already working on its
+// services, a note that it was queued, or the LLM busy response.
+func (s *Server) triggerBusyMessage(ctx context.Context, err error) string {
+	var conflict *session.ServiceConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return fmt.Sprintf("I'm already working on %s in session #%d: %s. Follow that session there; I won't start another one for the same services.",
+			strings.Join(conflict.Services, ", "), conflict.SessionID, s.sessionURL(conflict.SessionID))
+	case errors.Is(err, session.ErrQueued):
+		return "I'm finishing a monitoring session about other services. Your request is queued and will start as soon as that session ends."
+	}
+	return generateBusyResponse(ctx, s.db, os.Getenv("ANTHROPIC_API_KEY"))
+}
@@ -1,10 +1,13 @@
 package web
 
 import (
+	"encoding/json"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/joestump/claude-ops/internal/db"
+	"github.com/joestump/claude-ops/internal/session"
 )
 
 const timeFormat = time.RFC3339
@@ -19,6 +22,7 @@ type SessionView struct {
 	EndedAt    *time.Time
 	ExitCode   *int
 	LogFile    string
+	LogObject  string // object storage key of an offloaded log
 	Response   string
 	Summary    string
 	CostUSD    *float64
@@ -26,6 +30,39 @@ type SessionView struct {
 	DurationMs *int64
 	Trigger    string
 	PromptText string
+	// PromptFile is the prompt the session ran with, e.g. a specialized
+	// Tier 2 prompt selected from the handoff ("(ad-hoc)" for custom prompts).
+	PromptFile string
+	// ClientUser and ClientMetadata are what the chat client that triggered
+	// the session sent in the OpenAI "user" and "metadata" fields.
+	ClientUser     string
+	ClientMetadata map[string]string
+	// RequestID is the ID of the dashboard or API request that triggered
+	// the session.
+	RequestID string
+	// Outcome is what the finished session found and did: healthy,
+	// issues-observed, remediated, remediation-failed, or inconclusive.
+	Outcome string
+	// CostSynthetic marks CostUSD as estimated from token usage because the
+	// CLI reported no cost.
+	CostSynthetic bool
+	// CostLive marks CostUSD as the running cost of a session still in
+	// progress.
+	CostLive bool
+	// MaxContext is the largest context, in tokens, of any assistant turn,
+	// and MaxContextPct its share of the model's context window.
+	MaxContext    *int64
+	MaxContextPct int64
+	// WorkDir is the directory the CLI ran in, and GitSHA the commit
+	// checked out there when the session started.
+	WorkDir string
+	GitSHA  string
+	// ParseWarnings counts the markers in the session's output that were
+	// not in a format the supervisor parses (set on the sessions list).
+	ParseWarnings int
+	// Redactions counts the credential values redacted from the session's
+	// output (set on the sessions list).
+	Redactions int
 
 	// Escalation chain fields.
 	// Governing: SPEC-0016 REQ "Dashboard Escalation Chain Display", REQ "Per-Tier Cost Attribution"
@@ -37,7 +74,17 @@ type SessionView struct {
 	ChainLength     int
 	HasChildren     bool   // session escalated to a child above
 	IsChainTip      bool   // top session of an escalation chain
-	ChainOutcome    string // status of the chain tip (set on all chain members)
+	ChainOutcome    string // outcome of the chain tip, or its status while it has none (set on all chain members)
+	// ChainSummary summarizes the whole escalation chain, from the first
+	// tier to its verification (set on the chain root, and on every member
+	// on the session page).
+	ChainSummary string
+	// ChainLive is set on the session page when the session has finished
+	// but the chain it is part of is still running.
+	ChainLive bool
+	// SkippedTiers are the tiers the escalation to this session skipped,
+	// e.g. "2" for Tier 3 straight from Tier 1.
+	SkippedTiers string
 }
 
 // HealthCheckView is a template-friendly representation of a db.HealthCheck with parsed times.
@@ -100,6 +147,9 @@ func ToSessionView(s db.Session) SessionView {
 	if s.LogFile != nil {
 		v.LogFile = *s.LogFile
 	}
+	if s.LogObject != nil {
+		v.LogObject = *s.LogObject
+	}
 	if s.Response != nil {
 		v.Response = *s.Response
 	}
@@ -107,12 +157,42 @@ func ToSessionView(s db.Session) SessionView {
 		v.Summary = *s.Summary
 	}
 	v.CostUSD = s.CostUSD
+	v.CostSynthetic = s.CostSynthetic
+	if s.MaxContext != nil {
+		v.MaxContext = s.MaxContext
+		v.MaxContextPct = *s.MaxContext * 100 / session.ContextWindow(s.Model)
+	}
 	v.NumTurns = s.NumTurns
 	v.DurationMs = s.DurationMs
 	v.Trigger = s.Trigger
 	if s.PromptText != nil {
 		v.PromptText = *s.PromptText
 	}
+	v.PromptFile = s.PromptFile
+	if s.ClientUser != nil {
+		v.ClientUser = *s.ClientUser
+	}
+	if s.ClientMetadata != nil {
+		_ = json.Unmarshal([]byte(*s.ClientMetadata), &v.ClientMetadata)
+	}
+	if s.RequestID != nil {
+		v.RequestID = *s.RequestID
+	}
+	if s.Outcome != nil {
+		v.Outcome = *s.Outcome
+	}
+	if s.ChainSummary != nil {
+		v.ChainSummary = *s.ChainSummary
+	}
+	if s.SkippedTiers != nil {
+		v.SkippedTiers = *s.SkippedTiers
+	}
+	if s.WorkDir != nil {
+		v.WorkDir = *s.WorkDir
+	}
+	if s.GitSHA != nil {
+		v.GitSHA = *s.GitSHA
+	}
 	v.ParentSessionID = s.ParentSessionID
 	return v
 }
@@ -210,18 +290,36 @@ type MemoryView struct {
 	UpdatedAt   time.Time
 	SessionID   *int64
 	Tier        int
+	// ReviewStatus is unverified, verified, or rejected.
+	ReviewStatus string
+	// Deleted is true for soft-deleted memories; TombstonedAt is when the
+	// memory was deleted or rejected.
+	Deleted         bool
+	TombstonedAt    time.Time
+	SuppressedCount int
+	// SuggestedFrom is the number of recurring events the memory was
+	// suggested from, or 0 when the agent or an operator created it.
+	SuggestedFrom int
 }
 
 // ToMemoryView converts a db.Memory to a MemoryView.
 func ToMemoryView(m db.Memory) MemoryView {
 	v := MemoryView{
-		ID:          m.ID,
-		Category:    m.Category,
-		Observation: m.Observation,
-		Confidence:  m.Confidence,
-		Active:      m.Active,
-		SessionID:   m.SessionID,
-		Tier:        m.Tier,
+		ID:           m.ID,
+		Category:     m.Category,
+		Observation:  m.Observation,
+		Confidence:   m.Confidence,
+		Active:       m.Active,
+		SessionID:    m.SessionID,
+		Tier:         m.Tier,
+		ReviewStatus: m.ReviewStatus,
+	}
+	v.Deleted = m.DeletedAt != nil
+	v.SuppressedCount = m.SuppressedCount
+	if m.TombstonedAt != nil {
+		if t, err := time.Parse(timeFormat, *m.TombstonedAt); err == nil {
+			v.TombstonedAt = t
+		}
 	}
 	if m.Service != nil {
 		v.Service = *m.Service
@@ -290,8 +388,12 @@ func buildActivityFeed(sessions []db.Session, events []db.Event, memories []db.M
 				switch s.Status {
 				case "escalated":
 					icon = "↑"
-				case "failed", "timed_out":
+				case "continued":
+					icon = "→"
+				case "failed", "timed_out", "interrupted":
 					icon = "✗"
+				case "reopened":
+					icon = "↺"
 				}
 				if s.CostUSD != nil {
 					msg += " · $" + fmtFloat(*s.CostUSD, 4)
@@ -442,3 +544,21 @@ func ToCooldownViews(cooldowns []db.RecentCooldown) []CooldownView {
 	}
 	return views
 }
+
+// DecisionView is a template-friendly representation of a
+// db.EscalationDecision, with its cooldown budgets parsed.
+type DecisionView struct {
+	db.EscalationDecision
+	Services []string
+	Budgets  []session.CooldownBudget
+}
+
+// ToDecisionView converts a db.EscalationDecision to a DecisionView.
+func ToDecisionView(d db.EscalationDecision) DecisionView {
+	v := DecisionView{EscalationDecision: d}
+	if d.Services != "" {
+		v.Services = strings.Split(d.Services, ",")
+	}
+	_ = json.Unmarshal([]byte(d.Cooldowns), &v.Budgets)
+	return v
+}
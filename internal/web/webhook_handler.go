@@ -10,12 +10,15 @@ import (
 	"context"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
+
+	"github.com/joestump/claude-ops/internal/session"
 )
 
 // defaultWebhookSystemPrompt is the default synthesis prompt used to convert
@@ -132,6 +135,26 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	// Always return 202 regardless of busy state so upstream tools don't treat a
 	// non-2xx as a delivery failure and retry/alert on the webhook itself.
 	sessionID, err := s.mgr.TriggerAdHoc(prompt, startTier, "alert")
+	var conflict *session.ServiceConflictError
+	switch {
+	case errors.As(err, &conflict):
+		log.Printf("webhook: %v, alert acknowledged but not queued", err)
+		writeJSON(w, http.StatusAccepted, map[string]any{
+			"session_id":  nil,
+			"status":      "acknowledged",
+			"message":     err.Error() + "; this alert was received but not queued",
+			"session_url": s.sessionURL(conflict.SessionID),
+		})
+		return
+	case errors.Is(err, session.ErrQueued):
+		s.followQueued(r, nil, err)
+		writeJSON(w, http.StatusAccepted, map[string]any{
+			"session_id": nil,
+			"status":     "queued",
+			"message":    "a session about other services is in progress; this alert will start a session when it ends",
+		})
+		return
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "already running") || strings.Contains(err.Error(), "queue full") {
 			log.Printf("webhook: session already running, alert acknowledged but not queued")
@@ -145,6 +168,7 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	s.tagSession(r, sessionID)
 
 	// Governing: SPEC-0025 REQ "Response Format" — 202 Accepted with session_id, status, tier.
 	writeJSON(w, http.StatusAccepted, map[string]any{
@@ -0,0 +1,20 @@
+// Package prompts embeds the default agent prompts so the binary runs
+// without the prompt files installed.
+package prompts
+
+import (
+	"embed"
+	"path/filepath"
+)
+
+//go:embed *.md
+var fs embed.FS
+
+// Default returns the embedded prompt with the same file name as path.
+func Default(path string) ([]byte, bool) {
+	data, err := fs.ReadFile(filepath.Base(path))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,238 @@
+// Package testkit provides a scripted stand-in for the Claude CLI, for
+// end-to-end tests of the session pipeline: stream parsing, markers and
+// structured output, escalation, and SSE streaming.
+//
+// A Runner satisfies the session manager's ProcessRunner interface. Each
+// session it starts plays the next Script, writing its stream-json events
+// with the given delays:
+//
+//	r := testkit.NewRunner(
+//		testkit.Script{Events: []testkit.Event{
+//			testkit.Init("haiku"),
+//			testkit.Text("Checking jellyfin..."),
+//			testkit.Result("jellyfin is down", testkit.Escalate("HTTP 502", "jellyfin")),
+//		}},
+//		testkit.Script{Events: []testkit.Event{testkit.Result("fixed", nil)}},
+//	)
+//	mgr := session.New(cfg, database, hub.New(), r)
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event is one stream-json line, written Delay after the previous one.
+type Event struct {
+	Delay time.Duration
+	Line  string
+}
+
+// After returns a copy of e written d after the previous event.
+func (e Event) After(d time.Duration) Event {
+	e.Delay = d
+	return e
+}
+
+// Script is what one scripted CLI process does.
+type Script struct {
+	Events []Event
+	// Err is returned by the process's wait function, e.g. an
+	// *exec.ExitError for a failed run.
+	Err error
+	// HoldOpen keeps stdout open after the last event, as when a child
+	// process inherits the pipe, until the session's context is cancelled.
+	HoldOpen bool
+}
+
+// Call records the arguments of one Start.
+type Call struct {
+	Model              string
+	Prompt             string
+	AllowedTools       string
+	DisallowedTools    string
+	AppendSystemPrompt string
+	SchemaPath         string
+}
+
+// Runner plays one Script per started session, in order. Once the scripts
+// run out the last one repeats. It is safe for concurrent use.
+type Runner struct {
+	mu      sync.Mutex
+	scripts []Script
+	calls   []Call
+}
+
+// NewRunner returns a Runner playing scripts.
+func NewRunner(scripts ...Script) *Runner {
+	return &Runner{scripts: scripts}
+}
+
+// Calls returns the arguments of every Start so far.
+func (r *Runner) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Start plays the next script. The wait function returns once every event
+// has been written, with the script's Err, or with the context's error if
+// the session is cancelled first.
+func (r *Runner) Start(ctx context.Context, model string, promptContent string, allowedTools string, disallowedTools string, appendSystemPrompt string, schemaPath string, _ string, _ []string) (io.ReadCloser, func() error, error) {
+	r.mu.Lock()
+	if len(r.scripts) == 0 {
+		r.mu.Unlock()
+		return nil, nil, fmt.Errorf("testkit: no script for session %d", len(r.calls)+1)
+	}
+	script := r.scripts[min(len(r.calls), len(r.scripts)-1)]
+	r.calls = append(r.calls, Call{
+		Model:              model,
+		Prompt:             promptContent,
+		AllowedTools:       allowedTools,
+		DisallowedTools:    disallowedTools,
+		AppendSystemPrompt: appendSystemPrompt,
+		SchemaPath:         schemaPath,
+	})
+	r.mu.Unlock()
+
+	// An OS pipe buffers like the real CLI's stdout, so a script can finish
+	// writing before the session reads it.
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("testkit: pipe: %w", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		for _, e := range script.Events {
+			select {
+			case <-ctx.Done():
+				_ = pw.Close()
+				done <- ctx.Err()
+				return
+			case <-time.After(e.Delay):
+			}
+			if _, err := io.WriteString(pw, e.Line+"\n"); err != nil {
+				_ = pw.Close()
+				done <- err
+				return
+			}
+		}
+		done <- script.Err
+		if script.HoldOpen {
+			<-ctx.Done()
+		}
+		_ = pw.Close()
+	}()
+	return pr, func() error { return <-done }, nil
+}
+
+// Raw is an event with a literal line, e.g. malformed or unknown output.
+func Raw(line string) Event {
+	return Event{Line: line}
+}
+
+// Init is the CLI's system init event.
+func Init(model string) Event {
+	return jsonEvent(map[string]any{"type": "system", "subtype": "init", "model": model, "claude_code_version": "testkit"})
+}
+
+// Text is an assistant text block.
+func Text(text string) Event {
+	return assistant(map[string]any{"type": "text", "text": text})
+}
+
+// Thinking is an assistant extended thinking block.
+func Thinking(thinking string) Event {
+	return assistant(map[string]any{"type": "thinking", "thinking": thinking})
+}
+
+// ToolUse is an assistant tool call; input is marshalled as JSON.
+func ToolUse(id, name string, input any) Event {
+	return assistant(map[string]any{"type": "tool_use", "id": id, "name": name, "input": input})
+}
+
+// ToolResult is the result of the tool call with the given ID.
+func ToolResult(id, content string) Event {
+	return jsonEvent(map[string]any{
+		"type":    "user",
+		"message": map[string]any{"content": []any{map[string]any{"type": "tool_result", "tool_use_id": id, "content": content}}},
+	})
+}
+
+// Result is the final result event. structured is the agent's structured
+// output (see Escalate and Healthy), or nil for a text-only result.
+func Result(text string, structured any) Event {
+	evt := map[string]any{
+		"type":           "result",
+		"subtype":        "success",
+		"result":         text,
+		"total_cost_usd": 0.01,
+		"num_turns":      1,
+		"duration_ms":    1000,
+	}
+	if structured != nil {
+		evt["structured_output"] = structured
+	}
+	return jsonEvent(evt)
+}
+
+// Escalate is structured output reporting services down and requesting
+// escalation to the next tier.
+func Escalate(reason string, services ...string) map[string]any {
+	return structured(reason, "down", true, services)
+}
+
+// EscalateTo is Escalate asking for a specific tier, such as Tier 3
+// straight from Tier 1.
+func EscalateTo(tier int, reason string, services ...string) map[string]any {
+	out := Escalate(reason, services...)
+	out["escalation"].(map[string]any)["recommended_tier"] = tier
+	return out
+}
+
+// HandBack is Healthy handing back to a lower tier, such as Tier 1 from
+// Tier 3 after a remediation, to verify the services.
+func HandBack(tier int, summary string, services ...string) map[string]any {
+	out := Healthy(summary, services...)
+	out["escalation"] = map[string]any{"needed": true, "reason": summary, "recommended_tier": tier}
+	return out
+}
+
+// Healthy is structured output reporting services healthy.
+func Healthy(summary string, services ...string) map[string]any {
+	return structured(summary, "healthy", false, services)
+}
+
+func structured(summary, status string, escalate bool, services []string) map[string]any {
+	checked := make([]any, len(services))
+	for i, s := range services {
+		checked[i] = map[string]any{"name": s, "status": status}
+	}
+	escalation := map[string]any{"needed": escalate}
+	if escalate {
+		escalation["reason"] = summary
+	}
+	return map[string]any{
+		"summary":          summary,
+		"events":           []any{},
+		"escalation":       escalation,
+		"services_checked": checked,
+	}
+}
+
+func assistant(block map[string]any) Event {
+	return jsonEvent(map[string]any{"type": "assistant", "message": map[string]any{"content": []any{block}}})
+}
+
+func jsonEvent(v any) Event {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("testkit: marshal event: %v", err))
+	}
+	return Event{Line: string(data)}
+}
@@ -0,0 +1,115 @@
+package testkit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func readLines(t *testing.T, r *Runner, ctx context.Context) ([]string, error) {
+	t.Helper()
+	stdout, wait, err := r.Start(ctx, "haiku", "prompt", "Bash", "", "extra", "", "", nil)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	var lines []string
+	sc := bufio.NewScanner(stdout)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines, wait()
+}
+
+func TestRunnerPlaysScriptsInOrder(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	r := NewRunner(
+		Script{Events: []Event{Init("haiku"), Text("checking"), Result("done", Escalate("jellyfin down", "jellyfin"))}},
+		Script{Events: []Event{Raw("not json")}, Err: exitErr},
+	)
+
+	lines, err := readLines(t, r, context.Background())
+	if err != nil || len(lines) != 3 {
+		t.Fatalf("first session: %d lines, %v", len(lines), err)
+	}
+	var result struct {
+		Type             string `json:"type"`
+		StructuredOutput struct {
+			Escalation struct {
+				Needed bool `json:"needed"`
+			} `json:"escalation"`
+			ServicesChecked []struct {
+				Name string `json:"name"`
+			} `json:"services_checked"`
+		} `json:"structured_output"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &result); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if result.Type != "result" || !result.StructuredOutput.Escalation.Needed || len(result.StructuredOutput.ServicesChecked) != 1 {
+		t.Errorf("unexpected result event %s", lines[2])
+	}
+
+	for range 2 {
+		lines, err = readLines(t, r, context.Background())
+		if !errors.Is(err, exitErr) || len(lines) != 1 || lines[0] != "not json" {
+			t.Errorf("later session: %q, %v", lines, err)
+		}
+	}
+
+	calls := r.Calls()
+	if len(calls) != 3 || calls[0].Model != "haiku" || calls[0].AppendSystemPrompt != "extra" {
+		t.Errorf("unexpected calls %+v", calls)
+	}
+}
+
+func TestRunnerCancel(t *testing.T) {
+	r := NewRunner(Script{Events: []Event{Text("first"), Text("never").After(time.Hour)}})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	lines, err := readLines(t, r, ctx)
+	if !errors.Is(err, context.Canceled) || len(lines) != 1 {
+		t.Errorf("expected one line and cancellation, got %q, %v", lines, err)
+	}
+}
+
+func TestRunnerHoldOpen(t *testing.T) {
+	r := NewRunner(Script{Events: []Event{Text("only")}, HoldOpen: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	stdout, wait, err := r.Start(ctx, "haiku", "", "", "", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := wait(); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	sc := bufio.NewScanner(stdout)
+	if !sc.Scan() {
+		t.Fatal("expected the scripted line")
+	}
+	closed := make(chan struct{})
+	go func() {
+		sc.Scan()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("stdout closed before the context was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("stdout still open after cancel")
+	}
+}
+
+func TestRunnerWithoutScripts(t *testing.T) {
+	if _, _, err := NewRunner().Start(context.Background(), "", "", "", "", "", "", "", nil); err == nil {
+		t.Error("expected an error without scripts")
+	}
+}